@@ -13,11 +13,13 @@ ifneq ($(HAS_SERVER),)
 	mockgen -destination server/app/mocks/mock_job_once_scheduler.go github.com/mattermost/mattermost-plugin-playbooks/server/app JobOnceScheduler
 	mockgen -destination server/app/mocks/mock_playbook_service.go github.com/mattermost/mattermost-plugin-playbooks/server/app PlaybookService
 	mockgen -destination server/app/mocks/mock_playbook_store.go github.com/mattermost/mattermost-plugin-playbooks/server/app PlaybookStore
-	mockgen -destination server/app/mocks/mock_keywords_ignorer.go github.com/mattermost/mattermost-plugin-playbooks/server/app KeywordsThreadIgnorer
+	mockgen -destination server/app/mocks/mock_keywords_ignorer.go github.com/mattermost/mattermost-plugin-playbooks/server/app KeywordsIgnorer
 	mockgen -destination server/sqlstore/mocks/mock_kvapi.go github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore KVAPI
 	mockgen -destination server/sqlstore/mocks/mock_storeapi.go github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore StoreAPI
 	mockgen -destination server/sqlstore/mocks/mock_configurationapi.go github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore ConfigurationAPI
 	mockgen -destination server/app/mocks/mock_user_info_store.go github.com/mattermost/mattermost-plugin-playbooks/server/app UserInfoStore
+	mockgen -destination server/app/mocks/mock_audit_service.go github.com/mattermost/mattermost-plugin-playbooks/server/app AuditService
+	mockgen -destination server/app/mocks/mock_audit_store.go github.com/mattermost/mattermost-plugin-playbooks/server/app AuditStore
 endif
 
 ## Runs the redocly server.
@@ -36,9 +38,13 @@ tests-e2e/db-setup/mattermost.sql:
 		--exclude-table ir_incident \
 		--exclude-table ir_playbook \
 		--exclude-table ir_playbookmember \
+		--exclude-table ir_playbookmembergroup \
+		--exclude-table ir_playbookteam \
 		--exclude-table ir_statusposts \
 		--exclude-table ir_system \
 		--exclude-table ir_timelineevent \
 		--exclude-table ir_userinfo \
 		--exclude-table ir_viewedchannel \
+		--exclude-table ir_keywordsignore \
+		--exclude-table ir_auditlog \
 		mattermost_test > tests-e2e/db-setup/mattermost.sql
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Sources of the audited actions.
+const (
+	AuditSourceAPI          = "api"
+	AuditSourceSlashCommand = "slash_command"
+	AuditSourceDialog       = "dialog"
+)
+
+// Types of the targets of the audited actions.
+const (
+	AuditTargetPlaybook         = "playbook"
+	AuditTargetRun              = "run"
+	AuditTargetSettings         = "settings"
+	AuditTargetChecklistLibrary = "checklist_library"
+)
+
+// AuditActionPermissionDenied is the action of the attempts rejected for lack of permissions.
+// The attempted action is in the record details.
+const AuditActionPermissionDenied = "permission_denied"
+
+// AuditRecord is an entry of the audit log.
+type AuditRecord struct {
+	ID          string        `json:"id"`
+	CreateAt    int64         `json:"create_at"`
+	ActorUserID string        `json:"actor_user_id"`
+	Action      string        `json:"action"`
+	Source      string        `json:"source"`
+	TargetType  string        `json:"target_type"`
+	TargetID    string        `json:"target_id"`
+	TeamID      string        `json:"team_id"`
+	Details     string        `json:"details"`
+	Changes     []AuditChange `json:"changes"`
+}
+
+// AuditChange is the change of a field of the target of an audited action.
+type AuditChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+
+	// Omitted is true if the values were too long to be recorded. Only the name of the field
+	// is kept then.
+	Omitted bool `json:"omitted"`
+}
+
+// AuditListOptions specifies the optional parameters to the AuditService.List and
+// AuditService.Export methods.
+type AuditListOptions struct {
+	ActorUserID string `url:"actor_user_id,omitempty"`
+	Action      string `url:"action,omitempty"`
+	Source      string `url:"source,omitempty"`
+	TargetType  string `url:"target_type,omitempty"`
+	TargetID    string `url:"target_id,omitempty"`
+	TeamID      string `url:"team_id,omitempty"`
+
+	// Since and Until restrict the records to the actions done at or after Since, and before
+	// Until, in milliseconds since epoch.
+	Since int64 `url:"since,omitempty"`
+	Until int64 `url:"until,omitempty"`
+
+	// Cursor is the NextCursor of the previous page. When set, the page is ignored.
+	Cursor string `url:"cursor,omitempty"`
+
+	// SkipCount skips computing the total count.
+	SkipCount bool `url:"skip_count,omitempty"`
+}
+
+// GetAuditRecordsResults is a page of audit records, newest first.
+type GetAuditRecordsResults struct {
+	TotalCount int           `json:"total_count"`
+	PageCount  int           `json:"page_count"`
+	HasMore    bool          `json:"has_more"`
+	Items      []AuditRecord `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// AuditService handles communication with the audit log related methods. They are restricted
+// to system admins.
+type AuditService struct {
+	client *Client
+}
+
+// List the audit records matching the options, newest first.
+func (s *AuditService) List(ctx context.Context, page, perPage int, opts AuditListOptions) (*GetAuditRecordsResults, error) {
+	auditURL, err := addOptions("audit", opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+	auditURL, err = addPaginationOptions(auditURL, page, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pagination options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, auditURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	result := &GetAuditRecordsResults{}
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	resp.Body.Close()
+
+	return result, nil
+}
+
+// Export writes all the audit records matching the options to w as JSON lines, newest first.
+// The Cursor option is ignored.
+func (s *AuditService) Export(ctx context.Context, w io.Writer, opts AuditListOptions) error {
+	opts.Cursor = ""
+	auditURL, err := addOptions("audit/export", opts)
+	if err != nil {
+		return fmt.Errorf("failed to build options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, auditURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	if _, err = s.client.do(ctx, req, w); err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	return nil
+}
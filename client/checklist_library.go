@@ -0,0 +1,178 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// ChecklistLibraryEntry is a checklist of the library of a team, that the checklists of its
+// playbooks can reference.
+type ChecklistLibraryEntry struct {
+	ID          string          `json:"id"`
+	TeamID      string          `json:"team_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Items       []ChecklistItem `json:"items"`
+	Version     int             `json:"version"`
+	CreateAt    int64           `json:"create_at"`
+	UpdateAt    int64           `json:"update_at"`
+	DeleteAt    int64           `json:"delete_at"`
+}
+
+// ChecklistLibraryVersion is a version of the title and items of a checklist library entry.
+type ChecklistLibraryVersion struct {
+	EntryID  string          `json:"entry_id"`
+	Version  int             `json:"version"`
+	Title    string          `json:"title"`
+	Items    []ChecklistItem `json:"items"`
+	UserID   string          `json:"user_id"`
+	CreateAt int64           `json:"create_at"`
+}
+
+// ChecklistLibraryCreateOptions specifies the parameters for ChecklistLibraryService.Create.
+type ChecklistLibraryCreateOptions struct {
+	TeamID      string          `json:"team_id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Items       []ChecklistItem `json:"items"`
+}
+
+// ChecklistLibraryService handles communication with the checklist library related methods of
+// the Playbooks API.
+type ChecklistLibraryService struct {
+	client *Client
+}
+
+// List the entries of the checklist library of a team that are not archived.
+func (s *ChecklistLibraryService) List(ctx context.Context, teamID string) ([]ChecklistLibraryEntry, error) {
+	libraryURL, err := addOption("checklist-library", "team_id", teamID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, libraryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ChecklistLibraryEntry
+	resp, err := s.client.do(ctx, req, &entries)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return entries, nil
+}
+
+// Get a checklist library entry.
+func (s *ChecklistLibraryService) Get(ctx context.Context, entryID string) (*ChecklistLibraryEntry, error) {
+	entryURL := fmt.Sprintf("checklist-library/%s", entryID)
+	req, err := s.client.newRequest(http.MethodGet, entryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(ChecklistLibraryEntry)
+	resp, err := s.client.do(ctx, req, entry)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return entry, nil
+}
+
+// Create a checklist library entry, returning it with only its ID.
+func (s *ChecklistLibraryService) Create(ctx context.Context, opts ChecklistLibraryCreateOptions) (*ChecklistLibraryEntry, error) {
+	req, err := s.client.newRequest(http.MethodPost, "checklist-library", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := new(ChecklistLibraryEntry)
+	resp, err := s.client.do(ctx, req, entry)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("expected status code %d", http.StatusCreated)
+	}
+
+	return entry, nil
+}
+
+// Update the title, description and items of a checklist library entry, saving them as a new
+// version.
+func (s *ChecklistLibraryService) Update(ctx context.Context, entry ChecklistLibraryEntry) error {
+	entryURL := fmt.Sprintf("checklist-library/%s", entry.ID)
+	req, err := s.client.newRequest(http.MethodPut, entryURL, entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete archives a checklist library entry.
+func (s *ChecklistLibraryService) Delete(ctx context.Context, entryID string) error {
+	entryURL := fmt.Sprintf("checklist-library/%s", entryID)
+	req, err := s.client.newRequest(http.MethodDelete, entryURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Versions lists the versions of a checklist library entry, newest first.
+func (s *ChecklistLibraryService) Versions(ctx context.Context, entryID string) ([]ChecklistLibraryVersion, error) {
+	versionsURL := fmt.Sprintf("checklist-library/%s/versions", entryID)
+	req, err := s.client.newRequest(http.MethodGet, versionsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []ChecklistLibraryVersion
+	resp, err := s.client.do(ctx, req, &versions)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return versions, nil
+}
+
+// Version gets a version of a checklist library entry.
+func (s *ChecklistLibraryService) Version(ctx context.Context, entryID string, version int) (*ChecklistLibraryVersion, error) {
+	versionURL := fmt.Sprintf("checklist-library/%s/versions/%d", entryID, version)
+	req, err := s.client.newRequest(http.MethodGet, versionURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(ChecklistLibraryVersion)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return result, nil
+}
@@ -42,6 +42,10 @@ type Client struct {
 	Playbooks *PlaybooksService
 	// Settings is a collection of methods used to interact with settings.
 	Settings *SettingsService
+	// Audit is a collection of methods used to read the audit log.
+	Audit *AuditService
+	// ChecklistLibrary is a collection of methods used to interact with the checklist libraries.
+	ChecklistLibrary *ChecklistLibraryService
 }
 
 // New creates a new instance of Client using the configuration from the given Mattermost Client.
@@ -65,6 +69,8 @@ func newClient(mattermostSiteURL string, httpClient *http.Client) (*Client, erro
 	c.PlaybookRuns = &PlaybookRunService{c}
 	c.Playbooks = &PlaybooksService{c}
 	c.Settings = &SettingsService{c}
+	c.Audit = &AuditService{c}
+	c.ChecklistLibrary = &ChecklistLibraryService{c}
 	return c, nil
 }
 
@@ -0,0 +1,136 @@
+// Copyright (c) 2015-present Mattermost, Inc. All Rights Reserved.
+// See LICENSE.txt for license information.
+
+package client
+
+import (
+	"context"
+)
+
+// PlaybookRunIterator walks through all the playbook runs of a listing, fetching the pages with
+// cursors as needed. The runs created while iterating are neither skipped nor repeated.
+type PlaybookRunIterator struct {
+	service *PlaybookRunService
+	perPage int
+	opts    PlaybookRunListOptions
+
+	page    []PlaybookRun
+	current *PlaybookRun
+	done    bool
+	err     error
+}
+
+// Iterate returns an iterator over all the playbook runs matching the options, fetching perPage
+// of them at a time.
+func (s *PlaybookRunService) Iterate(perPage int, opts PlaybookRunListOptions) *PlaybookRunIterator {
+	opts.Cursor = ""
+	opts.SkipCount = true
+
+	return &PlaybookRunIterator{
+		service: s,
+		perPage: perPage,
+		opts:    opts,
+	}
+}
+
+// Next advances to the next playbook run, fetching the next page if needed. It returns false
+// when there are no more playbook runs or when the fetch failed: check Err.
+func (it *PlaybookRunIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+
+		result, err := it.service.List(ctx, 0, it.perPage, it.opts)
+		if err != nil {
+			it.err = err
+			continue
+		}
+
+		it.page = result.Items
+		it.opts.Cursor = result.NextCursor
+		it.done = !result.HasMore || result.NextCursor == ""
+	}
+
+	it.current = &it.page[0]
+	it.page = it.page[1:]
+
+	return true
+}
+
+// PlaybookRun returns the current playbook run, or nil before the first call to Next and after
+// the last one.
+func (it *PlaybookRunIterator) PlaybookRun() *PlaybookRun {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PlaybookRunIterator) Err() error {
+	return it.err
+}
+
+// PlaybookIterator walks through all the playbooks of a listing, fetching the pages with cursors
+// as needed. It is used like PlaybookRunIterator.
+type PlaybookIterator struct {
+	service *PlaybooksService
+	teamID  string
+	perPage int
+	opts    PlaybookListOptions
+
+	page    []Playbook
+	current *Playbook
+	done    bool
+	err     error
+}
+
+// Iterate returns an iterator over all the playbooks of the team matching the options, fetching
+// perPage of them at a time.
+func (s *PlaybooksService) Iterate(teamID string, perPage int, opts PlaybookListOptions) *PlaybookIterator {
+	opts.Cursor = ""
+	opts.SkipCount = true
+
+	return &PlaybookIterator{
+		service: s,
+		teamID:  teamID,
+		perPage: perPage,
+		opts:    opts,
+	}
+}
+
+// Next advances to the next playbook, fetching the next page if needed. It returns false when
+// there are no more playbooks or when the fetch failed: check Err.
+func (it *PlaybookIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.current = nil
+			return false
+		}
+
+		result, err := it.service.List(ctx, it.teamID, 0, it.perPage, it.opts)
+		if err != nil {
+			it.err = err
+			continue
+		}
+
+		it.page = result.Items
+		it.opts.Cursor = result.NextCursor
+		it.done = !result.HasMore || result.NextCursor == ""
+	}
+
+	it.current = &it.page[0]
+	it.page = it.page[1:]
+
+	return true
+}
+
+// Playbook returns the current playbook, or nil before the first call to Next and after the last
+// one.
+func (it *PlaybookIterator) Playbook() *Playbook {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *PlaybookIterator) Err() error {
+	return it.err
+}
@@ -2,27 +2,98 @@ package client
 
 // Playbook represents the planning before a playbook run is initiated.
 type Playbook struct {
-	ID                             string      `json:"id"`
-	Title                          string      `json:"title"`
-	Description                    string      `json:"description"`
-	TeamID                         string      `json:"team_id"`
-	CreatePublicPlaybookRun        bool        `json:"create_public_playbook_run"`
-	CreateAt                       int64       `json:"create_at"`
-	DeleteAt                       int64       `json:"delete_at"`
-	NumStages                      int64       `json:"num_stages"`
-	NumSteps                       int64       `json:"num_steps"`
-	Checklists                     []Checklist `json:"checklists"`
-	MemberIDs                      []string    `json:"member_ids"`
-	ReminderMessageTemplate        string      `json:"reminder_message_template"`
-	ReminderTimerDefaultSeconds    int64       `json:"reminder_timer_default_seconds"`
-	InvitedUserIDs                 []string    `json:"invited_user_ids"`
-	InvitedGroupIDs                []string    `json:"invited_group_ids"`
-	InvitedUsersEnabled            bool        `json:"invited_users_enabled"`
-	DefaultOwnerID                 string      `json:"default_owner_id"`
-	DefaultOwnerEnabled            bool        `json:"default_owner_enabled"`
-	BroadcastChannelIDs            []string    `json:"broadcast_channel_ids"`
-	BroadcastEnabled               bool        `json:"broadcast_enabled"`
-	ExportChannelOnFinishedEnabled bool        `json:"export_channel_on_finished_enabled"`
+	ID                             string                `json:"id"`
+	Title                          string                `json:"title"`
+	Description                    string                `json:"description"`
+	TeamID                         string                `json:"team_id"`
+	SharedTeamIDs                  []string              `json:"shared_team_ids"`
+	SharedWithAllTeams             bool                  `json:"shared_with_all_teams"`
+	CreatePublicPlaybookRun        bool                  `json:"create_public_playbook_run"`
+	CreateAt                       int64                 `json:"create_at"`
+	DeleteAt                       int64                 `json:"delete_at"`
+	NumStages                      int64                 `json:"num_stages"`
+	NumSteps                       int64                 `json:"num_steps"`
+	Checklists                     []Checklist           `json:"checklists"`
+	Members                        []PlaybookMember      `json:"members"`
+	MemberGroups                   []PlaybookMemberGroup `json:"member_groups"`
+	ReminderMessageTemplate        string                `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds    int64                 `json:"reminder_timer_default_seconds"`
+	InvitedUserIDs                 []string              `json:"invited_user_ids"`
+	InvitedGroupIDs                []string              `json:"invited_group_ids"`
+	InvitedUsersEnabled            bool                  `json:"invited_users_enabled"`
+	DefaultOwnerID                 string                `json:"default_owner_id"`
+	DefaultOwnerEnabled            bool                  `json:"default_owner_enabled"`
+	BroadcastChannelIDs            []string              `json:"broadcast_channel_ids"`
+	BroadcastEnabled               bool                  `json:"broadcast_enabled"`
+	ExportChannelOnFinishedEnabled bool                  `json:"export_channel_on_finished_enabled"`
+	RunPermissions                 RunPermissionPolicy   `json:"run_permissions"`
+	RunStatuses                    []RunStatus           `json:"run_statuses"`
+}
+
+// RunStatus is a stage the runs of a playbook go through, e.g. Investigating or Mitigated.
+type RunStatus struct {
+	Name string `json:"name"`
+
+	// Transitions are the names of the statuses a run can move to from this one. If empty, a run
+	// can only move to the next status in order.
+	Transitions []string `json:"transitions,omitempty"`
+
+	// Finished is true if moving a run to this status finishes it.
+	Finished bool `json:"finished"`
+}
+
+// Roles of the playbook members. Each role can do everything the lower ones can.
+const (
+	PlaybookRoleViewer = "viewer"
+	PlaybookRoleRunner = "runner"
+	PlaybookRoleEditor = "editor"
+	PlaybookRoleAdmin  = "admin"
+)
+
+// PlaybookMember is a user with a role on a playbook. A playbook without members is open to
+// everyone on its team.
+type PlaybookMember struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// Types of the groups of users that can be granted a role on a playbook.
+const (
+	PlaybookMemberGroupTypeGroup   = "group"
+	PlaybookMemberGroupTypeChannel = "channel"
+)
+
+// PlaybookMemberGroup grants a role on a playbook to every member of a user group or channel.
+type PlaybookMemberGroup struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Role string `json:"role"`
+}
+
+// Who a run permission rule allows to perform an action. System admins and the owner of the run
+// are always allowed.
+const (
+	RunPermissionAllowChannel      = "channel"
+	RunPermissionAllowParticipants = "participants"
+	RunPermissionAllowRole         = "role"
+	RunPermissionAllowOwner        = "owner"
+)
+
+// RunPermissionRule defines who may perform an action on the runs of a playbook. Role is only
+// used with RunPermissionAllowRole.
+type RunPermissionRule struct {
+	Allow string `json:"allow"`
+	Role  string `json:"role,omitempty"`
+}
+
+// RunPermissionPolicy holds the rules for the actions on the runs of a playbook. An empty rule
+// allows every member of the run channel.
+type RunPermissionPolicy struct {
+	Finish               RunPermissionRule `json:"finish"`
+	ChangeOwner          RunPermissionRule `json:"change_owner"`
+	EditChecklists       RunPermissionRule `json:"edit_checklists"`
+	PostStatusUpdate     RunPermissionRule `json:"post_status_update"`
+	PublishRetrospective RunPermissionRule `json:"publish_retrospective"`
 }
 
 // Checklist represents a checklist in a playbook
@@ -30,6 +101,11 @@ type Checklist struct {
 	ID    string          `json:"id"`
 	Title string          `json:"title"`
 	Items []ChecklistItem `json:"items"`
+
+	// LibraryID is the checklist library entry the checklist follows, if any, and
+	// LibraryVersion the version it is pinned to, or zero for the latest one.
+	LibraryID      string `json:"library_id,omitempty"`
+	LibraryVersion int    `json:"library_version,omitempty"`
 }
 
 // ChecklistItem represents an item in a checklist
@@ -45,26 +121,158 @@ type ChecklistItem struct {
 	Command                string `json:"command"`
 	CommandLastRun         int64  `json:"command_last_run"`
 	Description            string `json:"description"`
+	Required               bool   `json:"required"`
+	AutoRun                string `json:"auto_run"`
+	AutoRunOffsetSeconds   int64  `json:"auto_run_offset_seconds"`
+	AutoRunUserID          string `json:"auto_run_user_id"`
+
+	HTTPAction               ChecklistItemHTTPAction `json:"http_action"`
+	HTTPActionLastRun        int64                   `json:"http_action_last_run"`
+	HTTPActionLastStatusCode int                     `json:"http_action_last_status_code"`
+	HTTPActionLastResponse   string                  `json:"http_action_last_response"`
+
+	Approval            ChecklistItemApproval `json:"approval"`
+	ApprovalRequestedBy string                `json:"approval_requested_by"`
+	ApprovalRequestedAt int64                 `json:"approval_requested_at"`
+	ApprovedBy          []string              `json:"approved_by,omitempty"`
+}
+
+// ChecklistItemStatePendingApproval is the state of a checklist item checked off that waits for
+// the sign-off of its approvers.
+const ChecklistItemStatePendingApproval = "pending_approval"
+
+// ChecklistItemApproval designates who must sign off a checklist item, other than the user who
+// checked it off, before it's closed. RequiredApprovals 0 means 1.
+type ChecklistItemApproval struct {
+	ApproverIDs       []string `json:"approver_ids,omitempty"`
+	ApproverGroupIDs  []string `json:"approver_group_ids,omitempty"`
+	RequiredApprovals int      `json:"required_approvals"`
+}
+
+// ChecklistItemHTTPAction is an HTTP request that can be performed as part of a checklist item.
+// Its headers can use the {{secret.<name>}} placeholders configured by a system admin.
+type ChecklistItemHTTPAction struct {
+	Method              string                          `json:"method"`
+	URL                 string                          `json:"url"`
+	Headers             []ChecklistItemHTTPActionHeader `json:"headers"`
+	Body                string                          `json:"body"`
+	RequireConfirmation bool                            `json:"require_confirmation"`
+}
+
+// ChecklistItemHTTPActionHeader is a header of the HTTP action of a checklist item.
+type ChecklistItemHTTPActionHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
 }
 
+// ChecklistItemHTTPActionResult is the outcome of the HTTP action of a checklist item. StatusCode
+// is 0 if there was no response, and Response is then the error.
+type ChecklistItemHTTPActionResult struct {
+	StatusCode int    `json:"status_code"`
+	Response   string `json:"response"`
+}
+
+// When the command of a checklist item runs automatically.
+const (
+	ChecklistItemAutoRunOnRunStart        = "run_start"
+	ChecklistItemAutoRunAfterPreviousItem = "previous_item"
+	ChecklistItemAutoRunAtOffset          = "offset"
+)
+
 // PlaybookCreateOptions specifies the parameters for PlaybooksService.Create method.
 type PlaybookCreateOptions struct {
-	Title                       string      `json:"title"`
-	Description                 string      `json:"description"`
-	TeamID                      string      `json:"team_id"`
-	CreatePublicPlaybookRun     bool        `json:"create_public_playbook_run"`
-	Checklists                  []Checklist `json:"checklists"`
-	MemberIDs                   []string    `json:"member_ids"`
-	BroadcastChannelID          string      `json:"broadcast_channel_id"`
-	ReminderMessageTemplate     string      `json:"reminder_message_template"`
-	ReminderTimerDefaultSeconds int64       `json:"reminder_timer_default_seconds"`
-	InvitedUserIDs              []string    `json:"invited_user_ids"`
-	InvitedGroupIDs             []string    `json:"invited_group_ids"`
-	InviteUsersEnabled          bool        `json:"invite_users_enabled"`
-	DefaultOwnerID              string      `json:"default_owner_id"`
-	DefaultOwnerEnabled         bool        `json:"default_owner_enabled"`
-	BroadcastChannelIDs         []string    `json:"broadcast_channel_ids"`
-	BroadcastEnabled            bool        `json:"broadcast_enabled"`
+	Title                       string                `json:"title"`
+	Description                 string                `json:"description"`
+	TeamID                      string                `json:"team_id"`
+	SharedTeamIDs               []string              `json:"shared_team_ids"`
+	SharedWithAllTeams          bool                  `json:"shared_with_all_teams"`
+	CreatePublicPlaybookRun     bool                  `json:"create_public_playbook_run"`
+	Checklists                  []Checklist           `json:"checklists"`
+	Members                     []PlaybookMember      `json:"members"`
+	MemberGroups                []PlaybookMemberGroup `json:"member_groups"`
+	BroadcastChannelID          string                `json:"broadcast_channel_id"`
+	ReminderMessageTemplate     string                `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds int64                 `json:"reminder_timer_default_seconds"`
+	InvitedUserIDs              []string              `json:"invited_user_ids"`
+	InvitedGroupIDs             []string              `json:"invited_group_ids"`
+	InviteUsersEnabled          bool                  `json:"invite_users_enabled"`
+	DefaultOwnerID              string                `json:"default_owner_id"`
+	DefaultOwnerEnabled         bool                  `json:"default_owner_enabled"`
+	BroadcastChannelIDs         []string              `json:"broadcast_channel_ids"`
+	BroadcastEnabled            bool                  `json:"broadcast_enabled"`
+	RunPermissions              RunPermissionPolicy   `json:"run_permissions"`
+	RunStatuses                 []RunStatus           `json:"run_statuses"`
+}
+
+// PlaybookDuplicateOptions specifies the optional parameters to the
+// PlaybooksService.Duplicate method.
+type PlaybookDuplicateOptions struct {
+	// TeamID is the team of the copy, by default the team of the playbook. The broadcast
+	// channels, default owner, category, invited users and members are dropped when copying
+	// into another team.
+	TeamID string `json:"team_id"`
+
+	// Title is the title of the copy, by default "Copy of" the title of the playbook.
+	Title string `json:"title"`
+}
+
+// PlaybookImportMarkdownOptions specifies the parameters to the
+// PlaybooksService.ImportMarkdown method.
+type PlaybookImportMarkdownOptions struct {
+	// TeamID is the team of the new playbook.
+	TeamID string `json:"team_id"`
+
+	// Title is the title of the new playbook, by default the first heading of the document
+	// without tasks, or the title of the first checklist.
+	Title string `json:"title"`
+
+	// Markdown is the document: every heading starts a checklist, every task bullet is an item,
+	// the text indented under a task is its description, and inline code of the form `/command`
+	// is its command.
+	Markdown string `json:"markdown"`
+
+	// Restricted makes the user the only member, and admin, of the new playbook. By default it
+	// is open.
+	Restricted bool `json:"restricted"`
+
+	// DryRun parses the document without creating the playbook.
+	DryRun bool `json:"dry_run"`
+}
+
+// MarkdownImportIssue is a line of a Markdown document left out of the imported playbook.
+type MarkdownImportIssue struct {
+	Line    int    `json:"line"`
+	Text    string `json:"text"`
+	Message string `json:"message"`
+}
+
+// PlaybookImportMarkdownResult is the result of the PlaybooksService.ImportMarkdown method.
+type PlaybookImportMarkdownResult struct {
+	// ID is the ID of the new playbook, empty for a dry run.
+	ID         string                `json:"id"`
+	Title      string                `json:"title"`
+	Checklists []Checklist           `json:"checklists"`
+	Issues     []MarkdownImportIssue `json:"issues"`
+}
+
+// PlaybookLintIssue is a problem with the content of a playbook.
+type PlaybookLintIssue struct {
+	// Severity is "error", for issues that always prevent saving the playbook, or "warning",
+	// for issues that only do in strict mode.
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+}
+
+// PlaybookValidationResult is the result of the PlaybooksService.Validate method.
+type PlaybookValidationResult struct {
+	// Valid is true if the playbook can be saved.
+	Valid bool `json:"valid"`
+
+	// Strict is true if the server rejects playbooks with warnings.
+	Strict bool                `json:"strict"`
+	Issues []PlaybookLintIssue `json:"issues"`
 }
 
 // PlaybookListOptions specifies the optional parameters to the
@@ -72,6 +280,17 @@ type PlaybookCreateOptions struct {
 type PlaybookListOptions struct {
 	Sort      Sort          `url:"sort,omitempty"`
 	Direction SortDirection `url:"direction,omitempty"`
+
+	// Cursor continues the listing right after the last playbook of a previous page, as given
+	// by GetPlaybooksResults.NextCursor. When set, the page is ignored.
+	Cursor string `url:"cursor,omitempty"`
+
+	// SkipCount skips counting all the matching playbooks, leaving TotalCount and PageCount of
+	// the results at 0.
+	SkipCount bool `url:"skip_count,omitempty"`
+
+	// WithArchived includes the archived playbooks.
+	WithArchived bool `url:"with_archived,omitempty"`
 }
 
 type GetPlaybooksResults struct {
@@ -79,4 +298,7 @@ type GetPlaybooksResults struct {
 	PageCount  int        `json:"page_count"`
 	HasMore    bool       `json:"has_more"`
 	Items      []Playbook `json:"items"`
+
+	// NextCursor is the cursor to get the next page with, if HasMore.
+	NextCursor string `json:"next_cursor"`
 }
@@ -8,30 +8,33 @@ const Me = "me"
 
 // PlaybookRun represents a playbook run.
 type PlaybookRun struct {
-	ID                             string          `json:"id"`
-	Name                           string          `json:"name"`
-	Description                    string          `json:"description"`
-	OwnerUserID                    string          `json:"owner_user_id"`
-	ReporterUserID                 string          `json:"reporter_user_id"`
-	TeamID                         string          `json:"team_id"`
-	ChannelID                      string          `json:"channel_id"`
-	CreateAt                       int64           `json:"create_at"`
-	EndAt                          int64           `json:"end_at"`
-	DeleteAt                       int64           `json:"delete_at"`
-	ActiveStage                    int             `json:"active_stage"`
-	ActiveStageTitle               string          `json:"active_stage_title"`
-	PostID                         string          `json:"post_id"`
-	PlaybookID                     string          `json:"playbook_id"`
-	Checklists                     []Checklist     `json:"checklists"`
-	StatusPosts                    []StatusPost    `json:"status_posts"`
-	ReminderPostID                 string          `json:"reminder_post_id"`
-	PreviousReminder               time.Duration   `json:"previous_reminder"`
-	BroadcastChannelID             string          `json:"broadcast_channel_id"`
-	ReminderMessageTemplate        string          `json:"reminder_message_template"`
-	InvitedUserIDs                 []string        `json:"invited_user_ids"`
-	InvitedGroupIDs                []string        `json:"invited_group_ids"`
-	TimelineEvents                 []TimelineEvent `json:"timeline_events"`
-	ExportChannelOnFinishedEnabled bool            `json:"export_channel_on_finished_enabled"`
+	ID                             string            `json:"id"`
+	Name                           string            `json:"name"`
+	Description                    string            `json:"description"`
+	OwnerUserID                    string            `json:"owner_user_id"`
+	ReporterUserID                 string            `json:"reporter_user_id"`
+	TeamID                         string            `json:"team_id"`
+	ChannelID                      string            `json:"channel_id"`
+	CreateAt                       int64             `json:"create_at"`
+	EndAt                          int64             `json:"end_at"`
+	DeleteAt                       int64             `json:"delete_at"`
+	ActiveStage                    int               `json:"active_stage"`
+	ActiveStageTitle               string            `json:"active_stage_title"`
+	PostID                         string            `json:"post_id"`
+	PlaybookID                     string            `json:"playbook_id"`
+	Checklists                     []Checklist       `json:"checklists"`
+	StatusPosts                    []StatusPost      `json:"status_posts"`
+	ReminderPostID                 string            `json:"reminder_post_id"`
+	PreviousReminder               time.Duration     `json:"previous_reminder"`
+	BroadcastChannelID             string            `json:"broadcast_channel_id"`
+	ReminderMessageTemplate        string            `json:"reminder_message_template"`
+	InvitedUserIDs                 []string          `json:"invited_user_ids"`
+	InvitedGroupIDs                []string          `json:"invited_group_ids"`
+	TimelineEvents                 []TimelineEvent   `json:"timeline_events"`
+	ExportChannelOnFinishedEnabled bool              `json:"export_channel_on_finished_enabled"`
+	CustomData                     map[string]string `json:"custom_data"`
+	RunStatuses                    []RunStatus       `json:"run_statuses"`
+	CurrentRunStatus               string            `json:"current_run_status"`
 }
 
 // StatusPost is information added to the playbook run when selecting from the db and sent to the
@@ -55,12 +58,19 @@ type PlaybookRunMetadata struct {
 type TimelineEventType string
 
 const (
-	PlaybookRunCreated TimelineEventType = "incident_created"
-	TaskStateModified  TimelineEventType = "task_state_modified"
-	StatusUpdated      TimelineEventType = "status_updated"
-	OwnerChanged       TimelineEventType = "owner_changed"
-	AssigneeChanged    TimelineEventType = "assignee_changed"
-	RanSlashCommand    TimelineEventType = "ran_slash_command"
+	PlaybookRunCreated      TimelineEventType = "incident_created"
+	TaskStateModified       TimelineEventType = "task_state_modified"
+	StatusUpdated           TimelineEventType = "status_updated"
+	OwnerChanged            TimelineEventType = "owner_changed"
+	AssigneeChanged         TimelineEventType = "assignee_changed"
+	RanSlashCommand         TimelineEventType = "ran_slash_command"
+	SlashCommandFailed      TimelineEventType = "slash_command_failed"
+	RanHTTPAction           TimelineEventType = "ran_http_action"
+	RequiredItemsOverridden TimelineEventType = "required_items_overridden"
+	ApprovalRequested       TimelineEventType = "approval_requested"
+	ChecklistItemApproved   TimelineEventType = "item_approved"
+	ChecklistItemRejected   TimelineEventType = "item_rejected"
+	RunStatusChanged        TimelineEventType = "run_status_changed"
 )
 
 // TimelineEvent represents an event recorded to a playbook run's timeline.
@@ -86,6 +96,17 @@ type PlaybookRunCreateOptions struct {
 	Description string `json:"description"`
 	PostID      string `json:"post_id"`
 	PlaybookID  string `json:"playbook_id"`
+
+	// CustomData are the values of the {{custom.<key>}} placeholders of the checklists.
+	CustomData map[string]string `json:"custom_data,omitempty"`
+}
+
+// ChecklistItemCommandPreview is the slash command of a checklist item with its placeholders
+// resolved, as it would run now.
+type ChecklistItemCommandPreview struct {
+	Command          string   `json:"command"`
+	ResolvedCommand  string   `json:"resolved_command"`
+	UnknownVariables []string `json:"unknown_variables"`
 }
 
 // Sort enumerates the available fields we can sort on.
@@ -140,6 +161,14 @@ type PlaybookRunListOptions struct {
 	// TeamID filters playbook runs to those in the given team.
 	TeamID string `url:"team_id,omitempty"`
 
+	// Cursor continues the listing right after the last playbook run of a previous page, as
+	// given by GetPlaybookRunsResults.NextCursor. When set, the page is ignored.
+	Cursor string `url:"cursor,omitempty"`
+
+	// SkipCount skips counting all the matching playbook runs, leaving TotalCount and PageCount
+	// of the results at 0.
+	SkipCount bool `url:"skip_count,omitempty"`
+
 	Sort      Sort          `url:"sort,omitempty"`
 	Direction SortDirection `url:"direction,omitempty"`
 
@@ -176,6 +205,32 @@ type PlaybookRunListOptions struct {
 	// StartedLT filters playbook runs that were started before the unix time given (in millis).
 	// A value of 0 means the filter is ignored (which is the default).
 	StartedLT int64 `url:"started_lt,omitempty"`
+
+	// FinishedGTE filters playbook runs that were finished after (or equal) to the unix time given (in millis).
+	// A value of 0 means the filter is ignored (which is the default).
+	FinishedGTE int64 `url:"finished_gte,omitempty"`
+
+	// FinishedLT filters playbook runs that were finished before the unix time given (in millis).
+	// A value of 0 means the filter is ignored (which is the default).
+	FinishedLT int64 `url:"finished_lt,omitempty"`
+
+	// PlaybookIDs filters playbook runs that are derived from any of these playbook ids.
+	// Defaults to empty (no filter).
+	PlaybookIDs []string `url:"playbook_ids,omitempty"`
+
+	// ReporterID filters by reporter's Mattermost user ID. Defaults to blank (no filter). Specify "me" for current user.
+	ReporterID string `url:"reporter_user_id,omitempty"`
+
+	// AssigneeID filters playbook runs that have at least one checklist item assigned to this user.
+	// Defaults to blank (no filter). Specify "me" for current user.
+	AssigneeID string `url:"assignee_id,omitempty"`
+
+	// StatusUpdateOverdue filters playbook runs in progress whose status update is overdue.
+	StatusUpdateOverdue bool `url:"status_update_overdue,omitempty"`
+
+	// OpenTasksGTE filters playbook runs that have at least this number of checklist items that
+	// are not closed yet. A value of 0 means the filter is ignored (which is the default).
+	OpenTasksGTE int `url:"open_tasks_gte,omitempty"`
 }
 
 // PlaybookRunList contains the paginated result.
@@ -200,10 +255,91 @@ type GetPlaybookRunsResults struct {
 	HasMore    bool          `json:"has_more"`
 	Items      []PlaybookRun `json:"items"`
 	Disabled   bool          `json:"disabled"`
+
+	// NextCursor is the cursor to get the next page with, if HasMore.
+	NextCursor string `json:"next_cursor"`
+}
+
+// PlaybookRunSearchOptions specifies the optional parameters to the
+// PlaybookRunService.Search method.
+type PlaybookRunSearchOptions struct {
+	// TeamID limits the search to the given team. Defaults to all the teams of the user.
+	TeamID string `url:"team_id,omitempty"`
+}
+
+// PlaybookRunSearchMatch is a piece of a playbook run matching the search terms.
+type PlaybookRunSearchMatch struct {
+	// Source is where the match was found: name, description, status_update,
+	// timeline_event, checklist_item or retrospective.
+	Source string `json:"source"`
+
+	// SourceID identifies the matching status post, timeline event or checklist item.
+	SourceID string `json:"source_id"`
+
+	// Snippet is an excerpt of the matching text, with the matching words surrounded by **.
+	Snippet string `json:"snippet"`
+}
+
+// PlaybookRunSearchResult is a playbook run matching the search terms.
+type PlaybookRunSearchResult struct {
+	PlaybookRunID string                   `json:"playbook_run_id"`
+	Name          string                   `json:"name"`
+	TeamID        string                   `json:"team_id"`
+	CurrentStatus string                   `json:"current_status"`
+	CreateAt      int64                    `json:"create_at"`
+	Rank          float64                  `json:"rank"`
+	Matches       []PlaybookRunSearchMatch `json:"matches"`
+}
+
+// PlaybookRunSearchResults contains the paginated search results, most relevant first.
+type PlaybookRunSearchResults struct {
+	TotalCount int                       `json:"total_count"`
+	PageCount  int                       `json:"page_count"`
+	HasMore    bool                      `json:"has_more"`
+	Items      []PlaybookRunSearchResult `json:"items"`
 }
 
 // StatusUpdateOptions are the fields required to update a playbook run's status
 type StatusUpdateOptions struct {
 	Message           string `json:"message"`
 	ReminderInSeconds int64  `json:"reminder"`
+
+	// RunStatus, if not empty, is the custom status the run moves to with the update.
+	RunStatus string `json:"run_status,omitempty"`
+}
+
+// SaveAsPlaybookOptions specifies the optional parameters to the
+// PlaybookRunService.SaveAsPlaybook method.
+type SaveAsPlaybookOptions struct {
+	// Title is the title of the new playbook, by default the name of the run.
+	Title string `json:"title"`
+
+	// Restricted makes the user the only member, and admin, of the new playbook when the
+	// playbook of the run is open or missing. By default the new playbook is open then.
+	Restricted bool `json:"restricted"`
+}
+
+// Types of the changes between the checklists of a run and those of its playbook.
+const (
+	PlaybookChangeAddChecklist = "add_checklist"
+	PlaybookChangeAddItem      = "add_item"
+	PlaybookChangeRemoveItem   = "remove_item"
+	PlaybookChangeUpdateItem   = "update_item"
+)
+
+// PlaybookChange is a change made to the checklists during a run, that can be merged back into
+// its playbook.
+type PlaybookChange struct {
+	ID             string         `json:"id"`
+	Type           string         `json:"type"`
+	ChecklistTitle string         `json:"checklist_title"`
+	Checklist      *Checklist     `json:"checklist,omitempty"`
+	Item           *ChecklistItem `json:"item,omitempty"`
+	Before         *ChecklistItem `json:"before,omitempty"`
+}
+
+// AcceptPlaybookChangesOptions specifies the parameters for the
+// PlaybookRunService.AcceptPlaybookChanges method.
+type AcceptPlaybookChangesOptions struct {
+	ChangeIDs []string `json:"change_ids"`
 }
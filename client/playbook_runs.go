@@ -7,6 +7,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 // PlaybookRunService handles communication with the playbook run related
@@ -96,6 +97,34 @@ func (s *PlaybookRunService) List(ctx context.Context, page, perPage int, opts P
 	return result, nil
 }
 
+// Search the playbook runs whose name, description, status updates, timeline, checklist items
+// or retrospective match the terms, most relevant first.
+func (s *PlaybookRunService) Search(ctx context.Context, terms string, page, perPage int, opts PlaybookRunSearchOptions) (*PlaybookRunSearchResults, error) {
+	playbookRunURL := "runs/search?terms=" + url.QueryEscape(terms)
+	playbookRunURL, err := addOptions(playbookRunURL, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build options: %w", err)
+	}
+	playbookRunURL, err = addPaginationOptions(playbookRunURL, page, perPage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build pagination options: %w", err)
+	}
+
+	req, err := s.client.newRequest(http.MethodGet, playbookRunURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	result := &PlaybookRunSearchResults{}
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	resp.Body.Close()
+
+	return result, nil
+}
+
 // Create a playbook run.
 func (s *PlaybookRunService) Create(ctx context.Context, opts PlaybookRunCreateOptions) (*PlaybookRun, error) {
 	playbookRunURL := "runs"
@@ -151,3 +180,161 @@ func (s *PlaybookRunService) Finish(ctx context.Context, playbookRunID string) e
 
 	return nil
 }
+
+// ChangeRunStatus moves a playbook run to one of the custom statuses of its playbook it can move
+// to from its current one, finishing the run if the status is a finished one.
+func (s *PlaybookRunService) ChangeRunStatus(ctx context.Context, playbookRunID, status string) error {
+	runStatusURL := fmt.Sprintf("runs/%s/run-status", playbookRunID)
+	body := struct {
+		Status string `json:"status"`
+	}{status}
+	req, err := s.client.newRequest(http.MethodPost, runStatusURL, body)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FinishOverridingRequiredItems finishes a playbook run even though some of its required items
+// are not done. Only system admins can override them, and the override is recorded in the
+// timeline.
+func (s *PlaybookRunService) FinishOverridingRequiredItems(ctx context.Context, playbookRunID string) error {
+	finishURL := fmt.Sprintf("runs/%s/finish", playbookRunID)
+	body := struct {
+		OverrideRequiredItems bool `json:"override_required_items"`
+	}{true}
+	req, err := s.client.newRequest(http.MethodPut, finishURL, body)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SaveAsPlaybook creates a playbook with the checklists of a playbook run, returning it with
+// only its ID.
+func (s *PlaybookRunService) SaveAsPlaybook(ctx context.Context, playbookRunID string, opts SaveAsPlaybookOptions) (*Playbook, error) {
+	saveURL := fmt.Sprintf("runs/%s/save-as-playbook", playbookRunID)
+	req, err := s.client.newRequest(http.MethodPost, saveURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	playbook := new(Playbook)
+	resp, err := s.client.do(ctx, req, playbook)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("expected status code %d", http.StatusCreated)
+	}
+
+	return playbook, nil
+}
+
+// GetPlaybookChanges lists the changes made to the checklists during a playbook run that can
+// be merged back into its playbook.
+func (s *PlaybookRunService) GetPlaybookChanges(ctx context.Context, playbookRunID string) ([]PlaybookChange, error) {
+	changesURL := fmt.Sprintf("runs/%s/playbook-changes", playbookRunID)
+	req, err := s.client.newRequest(http.MethodGet, changesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []PlaybookChange
+	resp, err := s.client.do(ctx, req, &changes)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return changes, nil
+}
+
+// AcceptPlaybookChanges merges the given changes, as listed by GetPlaybookChanges, into the
+// playbook of a playbook run.
+func (s *PlaybookRunService) AcceptPlaybookChanges(ctx context.Context, playbookRunID string, changeIDs []string) error {
+	acceptURL := fmt.Sprintf("runs/%s/playbook-changes/accept", playbookRunID)
+	req, err := s.client.newRequest(http.MethodPost, acceptURL, AcceptPlaybookChangesOptions{ChangeIDs: changeIDs})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateCustomData replaces the custom data of a playbook run, the values of the
+// {{custom.<key>}} placeholders of its checklists.
+func (s *PlaybookRunService) UpdateCustomData(ctx context.Context, playbookRunID string, customData map[string]string) error {
+	customDataURL := fmt.Sprintf("runs/%s/custom-data", playbookRunID)
+	req, err := s.client.newRequest(http.MethodPut, customDataURL, customData)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// PreviewItemCommand returns the slash command of a checklist item with its placeholders
+// resolved, without running it.
+func (s *PlaybookRunService) PreviewItemCommand(ctx context.Context, playbookRunID string, checklistNumber, itemNumber int) (*ChecklistItemCommandPreview, error) {
+	previewURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/command-preview", playbookRunID, checklistNumber, itemNumber)
+	req, err := s.client.newRequest(http.MethodGet, previewURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := new(ChecklistItemCommandPreview)
+	resp, err := s.client.do(ctx, req, preview)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return preview, nil
+}
+
+// RunItemHTTPAction performs the HTTP action of a checklist item. An action that requires
+// confirmation fails with a 409 unless confirmed is true.
+func (s *PlaybookRunService) RunItemHTTPAction(ctx context.Context, playbookRunID string, checklistNumber, itemNumber int, confirmed bool) (*ChecklistItemHTTPActionResult, error) {
+	actionURL := fmt.Sprintf("runs/%s/checklists/%d/item/%d/http-action", playbookRunID, checklistNumber, itemNumber)
+	body := struct {
+		Confirmed bool `json:"confirmed"`
+	}{confirmed}
+	req, err := s.client.newRequest(http.MethodPost, actionURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(ChecklistItemHTTPActionResult)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return result, nil
+}
@@ -72,3 +72,29 @@ func ExamplePlaybookRunService_List() {
 		fmt.Printf("Playbook Run Name: %s\n", playbookRun.Name)
 	}
 }
+
+func ExamplePlaybookRunService_Iterate() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	_, _, err := client4.Login("test@example.com", "testtest")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	it := c.PlaybookRuns.Iterate(100, client.PlaybookRunListOptions{
+		Sort:      client.SortByCreateAt,
+		Direction: client.SortDesc,
+	})
+	for it.Next(ctx) {
+		fmt.Printf("Playbook Run Name: %s\n", it.PlaybookRun().Name)
+	}
+	if err := it.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -88,6 +88,70 @@ func (s *PlaybooksService) Create(ctx context.Context, opts PlaybookCreateOption
 	return playbook, nil
 }
 
+// Duplicate a playbook, returning the copy with only its ID.
+func (s *PlaybooksService) Duplicate(ctx context.Context, playbookID string, opts PlaybookDuplicateOptions) (*Playbook, error) {
+	duplicateURL := fmt.Sprintf("playbooks/%s/duplicate", playbookID)
+	req, err := s.client.newRequest(http.MethodPost, duplicateURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	playbook := new(Playbook)
+	resp, err := s.client.do(ctx, req, playbook)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("expected status code %d", http.StatusCreated)
+	}
+
+	return playbook, nil
+}
+
+// ImportMarkdown creates a playbook from a Markdown document, or only parses it for a dry run.
+func (s *PlaybooksService) ImportMarkdown(ctx context.Context, opts PlaybookImportMarkdownOptions) (*PlaybookImportMarkdownResult, error) {
+	req, err := s.client.newRequest(http.MethodPost, "playbooks/import-markdown", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(PlaybookImportMarkdownResult)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	expected := http.StatusCreated
+	if opts.DryRun {
+		expected = http.StatusOK
+	}
+	if resp.StatusCode != expected {
+		return nil, fmt.Errorf("expected status code %d", expected)
+	}
+
+	return result, nil
+}
+
+// Validate lists the issues with the content of a playbook, without saving it.
+func (s *PlaybooksService) Validate(ctx context.Context, playbook Playbook) (*PlaybookValidationResult, error) {
+	req, err := s.client.newRequest(http.MethodPost, "playbooks/validate", playbook)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(PlaybookValidationResult)
+	resp, err := s.client.do(ctx, req, result)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	return result, nil
+}
+
 func (s *PlaybooksService) Update(ctx context.Context, playbook Playbook) error {
 	updateURL := fmt.Sprintf("playbooks/%s", playbook.ID)
 	req, err := s.client.newRequest(http.MethodPut, updateURL, playbook)
@@ -117,3 +181,35 @@ func (s *PlaybooksService) Delete(ctx context.Context, playbookID string) error
 
 	return nil
 }
+
+// Restore an archived playbook.
+func (s *PlaybooksService) Restore(ctx context.Context, playbookID string) error {
+	restoreURL := fmt.Sprintf("playbooks/%s/restore", playbookID)
+	req, err := s.client.newRequest(http.MethodPost, restoreURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Purge permanently deletes an archived playbook. Its runs are kept.
+func (s *PlaybooksService) Purge(ctx context.Context, playbookID string) error {
+	purgeURL := fmt.Sprintf("playbooks/%s/purge", playbookID)
+	req, err := s.client.newRequest(http.MethodDelete, purgeURL, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -71,3 +71,37 @@ func ExamplePlaybooksService_List() {
 		fmt.Printf("Playbook Name: %s\n", playbook.Title)
 	}
 }
+
+func ExamplePlaybooksService_Iterate() {
+	ctx := context.Background()
+
+	client4 := model.NewAPIv4Client("http://localhost:8065")
+	_, _, err := client4.Login("test@example.com", "testtest")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	teams, _, err := client4.GetAllTeams("", 0, 1)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	if len(teams) == 0 {
+		log.Fatal("no teams for this user")
+	}
+
+	c, err := client.New(client4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	it := c.Playbooks.Iterate(teams[0].Id, 100, client.PlaybookListOptions{
+		Sort:      client.SortByTitle,
+		Direction: client.SortAsc,
+	})
+	for it.Next(ctx) {
+		fmt.Printf("Playbook Name: %s\n", it.Playbook().Title)
+	}
+	if err := it.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
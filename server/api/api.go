@@ -19,8 +19,9 @@ type Handler struct {
 	*ErrorHandler
 	pluginAPI *pluginapi.Client
 	APIRouter *mux.Router
-	root      *mux.Router
-	config    config.Service
+	// Router is the root router, for the endpoints not requiring a Mattermost user.
+	Router *mux.Router
+	config config.Service
 }
 
 // NewHandler constructs a new handler.
@@ -39,14 +40,14 @@ func NewHandler(pluginAPI *pluginapi.Client, config config.Service, log bot.Logg
 	api.NotFoundHandler = http.NotFoundHandler()
 
 	handler.APIRouter = api
-	handler.root = root
+	handler.Router = root
 	handler.config = config
 
 	return handler
 }
 
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	h.root.ServeHTTP(w, r)
+	h.Router.ServeHTTP(w, r)
 }
 
 // HandleErrorWithCode logs the internal error and sends the public facing error
@@ -126,9 +126,25 @@ func toInternalPlaybook(apiPlaybook icClient.Playbook) app.Playbook {
 		panic(err)
 	}
 
+	// The client only knows the members with their roles, not the flat list of member IDs.
+	internalPlaybook.NormalizeMembers()
+	internalPlaybook.NormalizeSharedTeams()
+
 	return internalPlaybook
 }
 
+func toAPIPlaybookMembers(internalMembers []app.PlaybookMember) []icClient.PlaybookMember {
+	var apiMembers []icClient.PlaybookMember
+
+	memberBytes, _ := json.Marshal(internalMembers)
+	err := json.Unmarshal(memberBytes, &apiMembers)
+	if err != nil {
+		panic(err)
+	}
+
+	return apiMembers
+}
+
 func toAPIChecklists(internalChecklists []app.Checklist) []icClient.Checklist {
 	var apiChecklists []icClient.Checklist
 
@@ -0,0 +1,285 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// AuditHandler serves the audit log, and records in it the actions done through the API.
+type AuditHandler struct {
+	*ErrorHandler
+	auditService       app.AuditService
+	playbookService    app.PlaybookService
+	playbookRunService app.PlaybookRunService
+	checklistLibrary   app.ChecklistLibraryService
+	pluginAPI          *pluginapi.Client
+	log                bot.Logger
+}
+
+// NewAuditHandler serves the audit log at /audit, and records the changes to playbooks, runs,
+// settings and checklist library entries done through the requests handled by router, along
+// with every request rejected for lack of permissions.
+func NewAuditHandler(router *mux.Router, auditService app.AuditService, playbookService app.PlaybookService,
+	playbookRunService app.PlaybookRunService, checklistLibrary app.ChecklistLibraryService, api *pluginapi.Client,
+	log bot.Logger) *AuditHandler {
+	handler := &AuditHandler{
+		ErrorHandler:       &ErrorHandler{log: log},
+		auditService:       auditService,
+		playbookService:    playbookService,
+		playbookRunService: playbookRunService,
+		checklistLibrary:   checklistLibrary,
+		pluginAPI:          api,
+		log:                log,
+	}
+
+	auditRouter := router.PathPrefix("/audit").Subrouter()
+	auditRouter.HandleFunc("", handler.getRecords).Methods(http.MethodGet)
+	auditRouter.HandleFunc("/export", handler.exportRecords).Methods(http.MethodGet)
+
+	router.Use(handler.recordAction)
+
+	return handler
+}
+
+func (h *AuditHandler) getRecords(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if !app.IsAdmin(userID, h.pluginAPI) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s is not an admin", userID))
+		return
+	}
+
+	options, err := parseAuditFilterOptions(r.URL)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	}
+
+	results, err := h.auditService.GetRecords(options)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, results, http.StatusOK)
+}
+
+// exportRecords writes all the records matching the filters as JSON lines.
+func (h *AuditHandler) exportRecords(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if !app.IsAdmin(userID, h.pluginAPI) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s is not an admin", userID))
+		return
+	}
+
+	options, err := parseAuditFilterOptions(r.URL)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="playbooks-audit.jsonl"`)
+	w.WriteHeader(http.StatusOK)
+
+	// The status is already sent, so a failure can only cut the export short.
+	if err = h.auditService.Export(w, options); err != nil {
+		h.log.Warnf("failed to export the audit log: %v", err)
+	}
+}
+
+// recordAction records the successful changes to playbooks, runs and settings, with the state
+// of the target before and after the request, and the requests rejected with a 403.
+func (h *AuditHandler) recordAction(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := auditRoute(r)
+		targetType, targetID := auditTarget(r, route)
+		audited := targetType != "" && r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+
+		var before interface{}
+		var teamID string
+		if audited && (targetID != "" || targetType == app.AuditTargetSettings) {
+			before, teamID = h.loadTarget(targetType, targetID)
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		record := app.AuditRecord{
+			ActorUserID: r.Header.Get("Mattermost-User-ID"),
+			Action:      r.Method + " " + route,
+			Source:      app.AuditSourceAPI,
+			TargetType:  targetType,
+			TargetID:    targetID,
+			TeamID:      teamID,
+		}
+		if strings.Contains(route, "dialog") {
+			record.Source = app.AuditSourceDialog
+		}
+
+		if recorder.statusCode == http.StatusForbidden {
+			record.Details = record.Action
+			record.Action = app.AuditActionPermissionDenied
+			h.auditService.Record(record)
+			return
+		}
+
+		if !audited || recorder.statusCode >= http.StatusMultipleChoices {
+			return
+		}
+
+		// The created playbooks and runs are only known from the response.
+		if record.TargetID == "" && targetType != app.AuditTargetSettings {
+			if location := recorder.Header().Get("Location"); location != "" {
+				record.TargetID = path.Base(location)
+			}
+		}
+
+		var after interface{}
+		if record.TargetID != "" || targetType == app.AuditTargetSettings {
+			var afterTeamID string
+			after, afterTeamID = h.loadTarget(targetType, record.TargetID)
+			if afterTeamID != "" {
+				record.TeamID = afterTeamID
+			}
+		}
+
+		changes, err := app.AuditDiff(before, after)
+		if err != nil {
+			h.log.Warnf("failed to compute the changes of action '%s' for the audit log: %v", record.Action, err)
+		}
+		record.Changes = changes
+
+		h.auditService.Record(record)
+	})
+}
+
+// loadTarget returns the current state of the target and its team, or nil if it doesn't exist.
+func (h *AuditHandler) loadTarget(targetType, targetID string) (interface{}, string) {
+	switch targetType {
+	case app.AuditTargetPlaybook:
+		playbook, err := h.playbookService.Get(targetID)
+		if err != nil {
+			return nil, ""
+		}
+		return playbook, playbook.TeamID
+	case app.AuditTargetRun:
+		playbookRun, err := h.playbookRunService.GetPlaybookRun(targetID)
+		if err != nil {
+			return nil, ""
+		}
+		return playbookRun, playbookRun.TeamID
+	case app.AuditTargetChecklistLibrary:
+		entry, err := h.checklistLibrary.Get(targetID)
+		if err != nil {
+			return nil, ""
+		}
+		return entry, entry.TeamID
+	case app.AuditTargetSettings:
+		// Only the settings editable through the API, no secrets.
+		pluginConfig := h.pluginAPI.Configuration.GetPluginConfig()
+		return map[string]interface{}{
+			"playbook_creators_user_ids": pluginConfig["PlaybookCreatorsUserIds"],
+		}, ""
+	}
+
+	return nil, ""
+}
+
+// routeVariable matches the variables of a route template, capturing their name without the
+// pattern.
+var routeVariable = regexp.MustCompile(`\{([^:}]+):[^}]*\}`)
+
+// auditRoute returns the template of the matched route without the patterns of its variables
+// and the API prefix, e.g. "/runs/{id}/status".
+func auditRoute(r *http.Request) string {
+	route := r.URL.Path
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			route = routeVariable.ReplaceAllString(template, "{$1}")
+		}
+	}
+
+	return strings.TrimPrefix(route, "/api/v0")
+}
+
+// auditTarget returns the type and ID of the target of the request, if it is a playbook, a run,
+// a checklist library entry or the settings. The ID is empty when creating one.
+func auditTarget(r *http.Request, route string) (string, string) {
+	id := mux.Vars(r)["id"]
+
+	switch {
+	case route == "/playbooks" || strings.HasPrefix(route, "/playbooks/"):
+		return app.AuditTargetPlaybook, id
+	case route == "/runs" || strings.HasPrefix(route, "/runs/"):
+		return app.AuditTargetRun, id
+	case route == "/checklist-library" || strings.HasPrefix(route, "/checklist-library/"):
+		return app.AuditTargetChecklistLibrary, id
+	case route == "/settings":
+		return app.AuditTargetSettings, ""
+	}
+
+	return "", ""
+}
+
+func parseAuditFilterOptions(u *url.URL) (app.AuditFilterOptions, error) {
+	query := u.Query()
+
+	var page, perPage int
+	var since, until int64
+	var err error
+
+	if param := query.Get("page"); param != "" {
+		if page, err = strconv.Atoi(param); err != nil {
+			return app.AuditFilterOptions{}, errors.Wrapf(err, "bad parameter 'page'")
+		}
+	}
+	if param := query.Get("per_page"); param != "" {
+		if perPage, err = strconv.Atoi(param); err != nil {
+			return app.AuditFilterOptions{}, errors.Wrapf(err, "bad parameter 'per_page'")
+		}
+	}
+	if param := query.Get("since"); param != "" {
+		if since, err = strconv.ParseInt(param, 10, 64); err != nil {
+			return app.AuditFilterOptions{}, errors.Wrapf(err, "bad parameter 'since'")
+		}
+	}
+	if param := query.Get("until"); param != "" {
+		if until, err = strconv.ParseInt(param, 10, 64); err != nil {
+			return app.AuditFilterOptions{}, errors.Wrapf(err, "bad parameter 'until'")
+		}
+	}
+
+	skipCount, err := parseBoolParam(u, "skip_count")
+	if err != nil {
+		return app.AuditFilterOptions{}, err
+	}
+
+	return app.AuditFilterOptions{
+		ActorUserID: query.Get("actor_user_id"),
+		Action:      query.Get("action"),
+		Source:      query.Get("source"),
+		TargetType:  query.Get("target_type"),
+		TargetID:    query.Get("target_id"),
+		TeamID:      query.Get("team_id"),
+		Since:       since,
+		Until:       until,
+		Page:        page,
+		PerPage:     perPage,
+		Cursor:      query.Get("cursor"),
+		SkipCount:   skipCount,
+	}.Validate()
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	icClient "github.com/mattermost/mattermost-plugin-playbooks/client"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+	mock_poster "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	mock_config "github.com/mattermost/mattermost-plugin-playbooks/server/config/mocks"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestAudit(t *testing.T) {
+	var mockCtrl *gomock.Controller
+	var handler *Handler
+	var logger *mock_poster.MockLogger
+	var configService *mock_config.MockService
+	var auditService *mock_app.MockAuditService
+	var playbookService *mock_app.MockPlaybookService
+	var playbookRunService *mock_app.MockPlaybookRunService
+	var pluginAPI *plugintest.API
+	var client *pluginapi.Client
+
+	mattermostUserID := "testuserid"
+
+	// mattermostHandler simulates the Mattermost server routing HTTP requests to a plugin.
+	mattermostHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/plugins/playbooks")
+		r.Header.Add("Mattermost-User-ID", mattermostUserID)
+
+		handler.ServeHTTP(w, r)
+	})
+
+	server := httptest.NewServer(mattermostHandler)
+	t.Cleanup(server.Close)
+
+	c, err := icClient.New(&model.Client4{URL: server.URL})
+	require.NoError(t, err)
+
+	reset := func(t *testing.T) {
+		t.Helper()
+
+		mattermostUserID = "testuserid"
+		mockCtrl = gomock.NewController(t)
+		configService = mock_config.NewMockService(mockCtrl)
+		pluginAPI = &plugintest.API{}
+		client = pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		logger = mock_poster.NewMockLogger(mockCtrl)
+		handler = NewHandler(client, configService, logger)
+		auditService = mock_app.NewMockAuditService(mockCtrl)
+		playbookService = mock_app.NewMockPlaybookService(mockCtrl)
+		playbookRunService = mock_app.NewMockPlaybookRunService(mockCtrl)
+
+		NewPlaybookHandler(handler.APIRouter, playbookService, client, logger, configService)
+		NewSettingsHandler(handler.APIRouter, client, logger, configService)
+		NewAuditHandler(handler.APIRouter, auditService, playbookService, playbookRunService, mock_app.NewMockChecklistLibraryService(mockCtrl), client, logger)
+	}
+
+	t.Run("list audit records as an admin", func(t *testing.T) {
+		reset(t)
+
+		actorID := model.NewId()
+		expected := &app.GetAuditRecordsResults{
+			TotalCount: 1,
+			PageCount:  1,
+			Items: []app.AuditRecord{
+				{
+					ID:          model.NewId(),
+					CreateAt:    1000,
+					ActorUserID: actorID,
+					Action:      "PUT /playbooks/{id}",
+					Source:      app.AuditSourceAPI,
+					TargetType:  app.AuditTargetPlaybook,
+					TargetID:    model.NewId(),
+					Changes:     []app.AuditChange{{Field: "title", Before: "Before", After: "After"}},
+				},
+			},
+		}
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+		auditService.EXPECT().
+			GetRecords(app.AuditFilterOptions{
+				ActorUserID: actorID,
+				TargetType:  app.AuditTargetPlaybook,
+				Since:       500,
+				PerPage:     10,
+			}).
+			Return(expected, nil)
+
+		results, err := c.Audit.List(context.TODO(), 0, 10, icClient.AuditListOptions{
+			ActorUserID: actorID,
+			TargetType:  icClient.AuditTargetPlaybook,
+			Since:       500,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 1, results.TotalCount)
+		require.Len(t, results.Items, 1)
+		require.Equal(t, expected.Items[0].ID, results.Items[0].ID)
+		require.Equal(t, "PUT /playbooks/{id}", results.Items[0].Action)
+		require.Equal(t, []icClient.AuditChange{{Field: "title", Before: "Before", After: "After"}}, results.Items[0].Changes)
+	})
+
+	t.Run("list audit records with a bad filter", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.Audit.List(context.TODO(), 0, 10, icClient.AuditListOptions{TargetType: "channel"})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("list audit records as a non-admin records the attempt", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+		auditService.EXPECT().Record(app.AuditRecord{
+			ActorUserID: "testuserid",
+			Action:      app.AuditActionPermissionDenied,
+			Source:      app.AuditSourceAPI,
+			Details:     "GET /audit",
+		})
+
+		_, err := c.Audit.List(context.TODO(), 0, 10, icClient.AuditListOptions{})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("export audit records", func(t *testing.T) {
+		reset(t)
+
+		records := []app.AuditRecord{
+			{ID: model.NewId(), Action: "DELETE /playbooks/{id}", Changes: []app.AuditChange{}},
+			{ID: model.NewId(), Action: "PUT /settings", Changes: []app.AuditChange{}},
+		}
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+		auditService.EXPECT().
+			Export(gomock.Any(), app.AuditFilterOptions{Source: app.AuditSourceAPI, PerPage: app.PerPageDefault}).
+			DoAndReturn(func(w io.Writer, options app.AuditFilterOptions) error {
+				encoder := json.NewEncoder(w)
+				for _, record := range records {
+					require.NoError(t, encoder.Encode(record))
+				}
+				return nil
+			})
+
+		var buffer bytes.Buffer
+		err := c.Audit.Export(context.TODO(), &buffer, icClient.AuditListOptions{Source: icClient.AuditSourceAPI})
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+		require.Len(t, lines, 2)
+		for i, line := range lines {
+			var record icClient.AuditRecord
+			require.NoError(t, json.Unmarshal([]byte(line), &record))
+			require.Equal(t, records[i].ID, record.ID)
+		}
+	})
+
+	t.Run("deleting a playbook records its changes", func(t *testing.T) {
+		reset(t)
+
+		playbookID := model.NewId()
+		before := app.Playbook{ID: playbookID, Title: "My Playbook", TeamID: "testteamid"}
+		after := before
+		after.DeleteAt = 1000
+
+		playbookService.EXPECT().Get(playbookID).Return(before, nil).Times(3)
+		playbookService.EXPECT().Get(playbookID).Return(after, nil).Times(1)
+		playbookService.EXPECT().Delete(before, "testuserid").Return(nil)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+
+		auditService.EXPECT().Record(app.AuditRecord{
+			ActorUserID: "testuserid",
+			Action:      "DELETE /playbooks/{id}",
+			Source:      app.AuditSourceAPI,
+			TargetType:  app.AuditTargetPlaybook,
+			TargetID:    playbookID,
+			TeamID:      "testteamid",
+			Changes:     []app.AuditChange{{Field: "delete_at", Before: float64(0), After: float64(1000)}},
+		})
+
+		err := c.Playbooks.Delete(context.TODO(), playbookID)
+		require.NoError(t, err)
+	})
+
+	t.Run("deleting a playbook without permissions records the attempt", func(t *testing.T) {
+		reset(t)
+
+		playbookID := model.NewId()
+		playbook := app.Playbook{ID: playbookID, Title: "My Playbook", TeamID: "testteamid"}
+
+		playbookService.EXPECT().Get(playbookID).Return(playbook, nil).Times(2)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		auditService.EXPECT().Record(app.AuditRecord{
+			ActorUserID: "testuserid",
+			Action:      app.AuditActionPermissionDenied,
+			Source:      app.AuditSourceAPI,
+			TargetType:  app.AuditTargetPlaybook,
+			TargetID:    playbookID,
+			TeamID:      "testteamid",
+			Details:     "DELETE /playbooks/{id}",
+		})
+
+		err := c.Playbooks.Delete(context.TODO(), playbookID)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("changing the settings records the playbook creators", func(t *testing.T) {
+		reset(t)
+
+		creatorID := model.NewId()
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+		configService.EXPECT().IsAtLeastE20Licensed().Return(true)
+		configService.EXPECT().GetConfiguration().AnyTimes().Return(&config.Configuration{})
+		pluginAPI.On("GetPluginConfig").Return(map[string]interface{}{"PlaybookCreatorsUserIds": []interface{}{}}).Twice()
+		pluginAPI.On("GetPluginConfig").Return(map[string]interface{}{"PlaybookCreatorsUserIds": []interface{}{creatorID}}).Once()
+		pluginAPI.On("SavePluginConfig", map[string]interface{}{"PlaybookCreatorsUserIds": []string{creatorID}}).Return(nil)
+
+		auditService.EXPECT().Record(app.AuditRecord{
+			ActorUserID: "testuserid",
+			Action:      "PUT /settings",
+			Source:      app.AuditSourceAPI,
+			TargetType:  app.AuditTargetSettings,
+			Changes: []app.AuditChange{{
+				Field:  "playbook_creators_user_ids",
+				Before: []interface{}{},
+				After:  []interface{}{creatorID},
+			}},
+		})
+
+		err := c.Settings.Update(context.TODO(), icClient.GlobalSettings{PlaybookCreatorsUserIds: []string{creatorID}})
+		require.NoError(t, err)
+		pluginAPI.AssertExpectations(t)
+	})
+}
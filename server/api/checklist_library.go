@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+// ChecklistLibraryHandler is the API handler of the checklist libraries of the teams.
+type ChecklistLibraryHandler struct {
+	*ErrorHandler
+	checklistLibrary app.ChecklistLibraryService
+	playbookService  app.PlaybookService
+	pluginAPI        *pluginapi.Client
+	log              bot.Logger
+	config           config.Service
+}
+
+// NewChecklistLibraryHandler returns a new checklist library api handler
+func NewChecklistLibraryHandler(router *mux.Router, checklistLibrary app.ChecklistLibraryService, playbookService app.PlaybookService, api *pluginapi.Client, log bot.Logger, configService config.Service) *ChecklistLibraryHandler {
+	handler := &ChecklistLibraryHandler{
+		ErrorHandler:     &ErrorHandler{log: log},
+		checklistLibrary: checklistLibrary,
+		playbookService:  playbookService,
+		pluginAPI:        api,
+		log:              log,
+		config:           configService,
+	}
+
+	libraryRouter := router.PathPrefix("/checklist-library").Subrouter()
+	libraryRouter.HandleFunc("", handler.getEntries).Methods(http.MethodGet)
+	libraryRouter.HandleFunc("", handler.createEntry).Methods(http.MethodPost)
+
+	entryRouter := libraryRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
+	entryRouter.HandleFunc("", handler.getEntry).Methods(http.MethodGet)
+	entryRouter.HandleFunc("", handler.updateEntry).Methods(http.MethodPut)
+	entryRouter.HandleFunc("", handler.deleteEntry).Methods(http.MethodDelete)
+	entryRouter.HandleFunc("/versions", handler.getVersions).Methods(http.MethodGet)
+	entryRouter.HandleFunc("/versions/{version:[0-9]+}", handler.getVersion).Methods(http.MethodGet)
+
+	return handler
+}
+
+// getEntries handles the GET /checklist-library?team_id= endpoint.
+func (h *ChecklistLibraryHandler) getEntries(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	teamID := r.URL.Query().Get("team_id")
+
+	if teamID == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "team_id is required", nil)
+		return
+	}
+
+	if err := app.ChecklistLibraryView(userID, teamID, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	entries, err := h.checklistLibrary.GetForTeam(teamID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, entries, http.StatusOK)
+}
+
+func (h *ChecklistLibraryHandler) createEntry(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var entry app.ChecklistLibraryEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode checklist library entry", err)
+		return
+	}
+
+	if entry.ID != "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Checklist library entry given already has ID", nil)
+		return
+	}
+
+	if err := validateLibraryEntry(&entry); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid checklist library entry", err)
+		return
+	}
+
+	if err := app.ChecklistLibraryModify(userID, entry.TeamID, h.config, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	id, err := h.checklistLibrary.Create(entry, userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/checklist-library/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// getViewableEntry returns the entry of the request if the user can view it, handling the
+// errors.
+func (h *ChecklistLibraryHandler) getViewableEntry(w http.ResponseWriter, r *http.Request) (app.ChecklistLibraryEntry, bool) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	entry, err := h.checklistLibrary.Get(mux.Vars(r)["id"])
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Checklist library entry not found", err)
+		return app.ChecklistLibraryEntry{}, false
+	} else if err != nil {
+		h.HandleError(w, err)
+		return app.ChecklistLibraryEntry{}, false
+	}
+
+	if err = app.ChecklistLibraryView(userID, entry.TeamID, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return app.ChecklistLibraryEntry{}, false
+	}
+
+	return entry, true
+}
+
+func (h *ChecklistLibraryHandler) getEntry(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.getViewableEntry(w, r)
+	if !ok {
+		return
+	}
+
+	ReturnJSON(w, &entry, http.StatusOK)
+}
+
+// updateEntry handles the PUT /checklist-library/{id} endpoint, saving a new version of the
+// entry that the playbooks referencing it follow, unless pinned to a version.
+func (h *ChecklistLibraryHandler) updateEntry(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var entry app.ChecklistLibraryEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode checklist library entry", err)
+		return
+	}
+
+	oldEntry, ok := h.getViewableEntry(w, r)
+	if !ok {
+		return
+	}
+
+	if oldEntry.DeleteAt != 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "the checklist library entry is archived", nil)
+		return
+	}
+
+	// Only the content can be changed.
+	entry.ID = oldEntry.ID
+	entry.TeamID = oldEntry.TeamID
+	if err := validateLibraryEntry(&entry); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid checklist library entry", err)
+		return
+	}
+
+	if err := app.ChecklistLibraryUpdate(userID, oldEntry, h.config, h.playbookService, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if err := h.checklistLibrary.Update(entry, userID); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// deleteEntry handles the DELETE /checklist-library/{id} endpoint, archiving the entry. The
+// playbooks referencing it keep its last items.
+func (h *ChecklistLibraryHandler) deleteEntry(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	entry, ok := h.getViewableEntry(w, r)
+	if !ok {
+		return
+	}
+
+	if err := app.ChecklistLibraryModify(userID, entry.TeamID, h.config, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if err := h.checklistLibrary.Delete(entry, userID); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *ChecklistLibraryHandler) getVersions(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.getViewableEntry(w, r)
+	if !ok {
+		return
+	}
+
+	versions, err := h.checklistLibrary.GetVersions(entry.ID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, versions, http.StatusOK)
+}
+
+func (h *ChecklistLibraryHandler) getVersion(w http.ResponseWriter, r *http.Request) {
+	entry, ok := h.getViewableEntry(w, r)
+	if !ok {
+		return
+	}
+
+	number, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid version", err)
+		return
+	}
+
+	version, err := h.checklistLibrary.GetVersion(entry.ID, number)
+	if errors.Is(err, app.ErrNotFound) {
+		h.HandleErrorWithCode(w, http.StatusNotFound, "Version not found", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &version, http.StatusOK)
+}
+
+// validateLibraryEntry checks the entry and strips the IDs and run state of its items.
+func validateLibraryEntry(entry *app.ChecklistLibraryEntry) error {
+	entry.Title = strings.TrimSpace(entry.Title)
+	if entry.TeamID == "" {
+		return errors.New("team_id is required")
+	}
+	if entry.Title == "" {
+		return errors.New("title is required")
+	}
+
+	checklists := app.ChecklistsForPlaybook([]app.Checklist{{Items: entry.Items}})
+	entry.Items = checklists[0].Items
+
+	// The entries are shared by the playbooks of the team, so their commands run automatically as
+	// the bot rather than as whoever edited them.
+	for i := range entry.Items {
+		entry.Items[i].AutoRunUserID = ""
+	}
+
+	return nil
+}
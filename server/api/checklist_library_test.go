@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	icClient "github.com/mattermost/mattermost-plugin-playbooks/client"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+	mock_poster "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	mock_config "github.com/mattermost/mattermost-plugin-playbooks/server/config/mocks"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestChecklistLibrary(t *testing.T) {
+	var mockCtrl *gomock.Controller
+	var handler *Handler
+	var logger *mock_poster.MockLogger
+	var configService *mock_config.MockService
+	var checklistLibrary *mock_app.MockChecklistLibraryService
+	var playbookService *mock_app.MockPlaybookService
+	var pluginAPI *plugintest.API
+	var client *pluginapi.Client
+
+	mattermostUserID := "testuserid"
+	teamID := model.NewId()
+
+	// mattermostHandler simulates the Mattermost server routing HTTP requests to a plugin.
+	mattermostHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.URL.Path = strings.TrimPrefix(r.URL.Path, "/plugins/playbooks")
+		r.Header.Add("Mattermost-User-ID", mattermostUserID)
+
+		handler.ServeHTTP(w, r)
+	})
+
+	server := httptest.NewServer(mattermostHandler)
+	t.Cleanup(server.Close)
+
+	c, err := icClient.New(&model.Client4{URL: server.URL})
+	require.NoError(t, err)
+
+	reset := func(t *testing.T) {
+		t.Helper()
+
+		mockCtrl = gomock.NewController(t)
+		configService = mock_config.NewMockService(mockCtrl)
+		pluginAPI = &plugintest.API{}
+		client = pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		logger = mock_poster.NewMockLogger(mockCtrl)
+		handler = NewHandler(client, configService, logger)
+		checklistLibrary = mock_app.NewMockChecklistLibraryService(mockCtrl)
+		playbookService = mock_app.NewMockPlaybookService(mockCtrl)
+		NewChecklistLibraryHandler(handler.APIRouter, checklistLibrary, playbookService, client, logger, configService)
+
+		configService.EXPECT().
+			GetConfiguration().
+			Return(&config.Configuration{}).
+			AnyTimes()
+	}
+
+	entry := app.ChecklistLibraryEntry{
+		ID:      model.NewId(),
+		TeamID:  teamID,
+		Title:   "Triage",
+		Items:   []app.ChecklistItem{{Title: "Page on-call"}},
+		Version: 2,
+	}
+
+	t.Run("list the library of a team", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		checklistLibrary.EXPECT().GetForTeam(teamID).Return([]app.ChecklistLibraryEntry{entry}, nil)
+
+		entries, err := c.ChecklistLibrary.List(context.TODO(), teamID)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, entry.ID, entries[0].ID)
+		require.Equal(t, "Page on-call", entries[0].Items[0].Title)
+	})
+
+	t.Run("list the library of a team the user can't view", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.ChecklistLibrary.List(context.TODO(), teamID)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("create an entry without the item state", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("GetUser", mattermostUserID).Return(&model.User{Id: mattermostUserID}, nil)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		checklistLibrary.EXPECT().
+			Create(app.ChecklistLibraryEntry{
+				TeamID: teamID,
+				Title:  "Triage",
+				Items:  []app.ChecklistItem{{Title: "Page on-call", State: app.ChecklistItemStateOpen}},
+			}, mattermostUserID).
+			Return("entryid", nil)
+
+		created, err := c.ChecklistLibrary.Create(context.TODO(), icClient.ChecklistLibraryCreateOptions{
+			TeamID: teamID,
+			Title:  " Triage ",
+			Items:  []icClient.ChecklistItem{{ID: "itemid", Title: "Page on-call", State: "closed", AssigneeID: "someone"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "entryid", created.ID)
+	})
+
+	t.Run("create an entry as a guest", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("GetUser", mattermostUserID).Return(&model.User{Id: mattermostUserID, Roles: model.SystemGuestRoleId}, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.ChecklistLibrary.Create(context.TODO(), icClient.ChecklistLibraryCreateOptions{TeamID: teamID, Title: "Triage"})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("create an entry without a title", func(t *testing.T) {
+		reset(t)
+
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.ChecklistLibrary.Create(context.TODO(), icClient.ChecklistLibraryCreateOptions{TeamID: teamID})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("update an entry keeps its team", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("GetUser", mattermostUserID).Return(&model.User{Id: mattermostUserID}, nil)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionManageTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", mattermostUserID, model.PermissionManageSystem).Return(false)
+		checklistLibrary.EXPECT().Get(entry.ID).Return(entry, nil)
+
+		// Only the playbooks following the latest version need the editor role.
+		playbookService.EXPECT().GetPlaybookIDsReferencingLibrary(entry.ID).Return([]string{"following", "pinned"}, nil)
+		playbookService.EXPECT().Get("following").Return(app.Playbook{
+			ID:         "following",
+			TeamID:     teamID,
+			Checklists: []app.Checklist{{LibraryID: entry.ID}},
+			Members:    []app.PlaybookMember{{UserID: mattermostUserID, Role: app.PlaybookRoleEditor}},
+		}, nil)
+		playbookService.EXPECT().Get("pinned").Return(app.Playbook{
+			ID:         "pinned",
+			TeamID:     teamID,
+			Checklists: []app.Checklist{{LibraryID: entry.ID, LibraryVersion: 1}},
+			Members:    []app.PlaybookMember{{UserID: "someone_else", Role: app.PlaybookRoleAdmin}},
+		}, nil)
+
+		checklistLibrary.EXPECT().
+			Update(app.ChecklistLibraryEntry{
+				ID:     entry.ID,
+				TeamID: teamID,
+				Title:  "Triage v2",
+				Items:  []app.ChecklistItem{{Title: "Page on-call", State: app.ChecklistItemStateOpen}},
+			}, mattermostUserID).
+			Return(nil)
+
+		err := c.ChecklistLibrary.Update(context.TODO(), icClient.ChecklistLibraryEntry{
+			ID:     entry.ID,
+			TeamID: model.NewId(),
+			Title:  "Triage v2",
+			Items:  []icClient.ChecklistItem{{Title: "Page on-call"}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("update an entry used by a playbook the user can't edit", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("GetUser", mattermostUserID).Return(&model.User{Id: mattermostUserID}, nil)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionManageTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", mattermostUserID, model.PermissionManageSystem).Return(false)
+		checklistLibrary.EXPECT().Get(entry.ID).Return(entry, nil)
+		playbookService.EXPECT().GetPlaybookIDsReferencingLibrary(entry.ID).Return([]string{"restricted"}, nil)
+		playbookService.EXPECT().Get("restricted").Return(app.Playbook{
+			ID:         "restricted",
+			TeamID:     teamID,
+			Checklists: []app.Checklist{{LibraryID: entry.ID}},
+			Members:    []app.PlaybookMember{{UserID: mattermostUserID, Role: app.PlaybookRoleRunner}},
+		}, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		err := c.ChecklistLibrary.Update(context.TODO(), icClient.ChecklistLibraryEntry{ID: entry.ID, Title: "Triage v2"})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update an entry as a team admin", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("GetUser", mattermostUserID).Return(&model.User{Id: mattermostUserID}, nil)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionManageTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", mattermostUserID, model.PermissionManageSystem).Return(false)
+		checklistLibrary.EXPECT().Get(entry.ID).Return(entry, nil)
+		checklistLibrary.EXPECT().Update(gomock.Any(), mattermostUserID).Return(nil)
+
+		err := c.ChecklistLibrary.Update(context.TODO(), icClient.ChecklistLibraryEntry{ID: entry.ID, Title: "Triage v2"})
+		require.NoError(t, err)
+	})
+
+	t.Run("update an archived entry", func(t *testing.T) {
+		reset(t)
+
+		archived := entry
+		archived.DeleteAt = 1000
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		checklistLibrary.EXPECT().Get(entry.ID).Return(archived, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		err := c.ChecklistLibrary.Update(context.TODO(), icClient.ChecklistLibraryEntry{ID: entry.ID, Title: "Triage v2"})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("delete an entry", func(t *testing.T) {
+		reset(t)
+
+		pluginAPI.On("GetUser", mattermostUserID).Return(&model.User{Id: mattermostUserID}, nil)
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		checklistLibrary.EXPECT().Get(entry.ID).Return(entry, nil)
+		checklistLibrary.EXPECT().Delete(entry, mattermostUserID).Return(nil)
+
+		err := c.ChecklistLibrary.Delete(context.TODO(), entry.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("get an unknown entry", func(t *testing.T) {
+		reset(t)
+
+		checklistLibrary.EXPECT().Get("unknown").Return(app.ChecklistLibraryEntry{}, errors.Wrap(app.ErrNotFound, "not found"))
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.ChecklistLibrary.Get(context.TODO(), "unknown")
+		requireErrorWithStatusCode(t, err, http.StatusNotFound)
+	})
+
+	t.Run("get the versions of an entry", func(t *testing.T) {
+		reset(t)
+
+		versions := []app.ChecklistLibraryVersion{
+			{EntryID: entry.ID, Version: 2, Title: "Triage"},
+			{EntryID: entry.ID, Version: 1, Title: "Triage"},
+		}
+		pluginAPI.On("HasPermissionToTeam", mattermostUserID, teamID, model.PermissionViewTeam).Return(true)
+		checklistLibrary.EXPECT().Get(entry.ID).Return(entry, nil).Times(2)
+		checklistLibrary.EXPECT().GetVersions(entry.ID).Return(versions, nil)
+		checklistLibrary.EXPECT().GetVersion(entry.ID, 1).Return(versions[1], nil)
+
+		actual, err := c.ChecklistLibrary.Versions(context.TODO(), entry.ID)
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+
+		version, err := c.ChecklistLibrary.Version(context.TODO(), entry.ID, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, version.Version)
+	})
+}
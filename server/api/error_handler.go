@@ -6,6 +6,9 @@ package api
 import (
 	"net/http"
 
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
 )
 
@@ -23,3 +26,24 @@ func (h *ErrorHandler) HandleError(w http.ResponseWriter, internalErr error) {
 func (h *ErrorHandler) HandleErrorWithCode(w http.ResponseWriter, code int, publicErrorMsg string, internalErr error) {
 	HandleErrorWithCode(h.log, w, code, publicErrorMsg, internalErr)
 }
+
+// rejectInvalidPlaybook responds with the issues with the content of the playbook and returns
+// true if err is an app.InvalidPlaybookError.
+func (h *ErrorHandler) rejectInvalidPlaybook(w http.ResponseWriter, err error) bool {
+	var invalidErr *app.InvalidPlaybookError
+	if !errors.As(err, &invalidErr) {
+		return false
+	}
+
+	h.log.Warnf("public error message: %v; internal details: %v", "invalid playbook", invalidErr.Issues)
+
+	result := struct {
+		Error  string                  `json:"error"`
+		Issues []app.PlaybookLintIssue `json:"issues"`
+	}{
+		Error:  "invalid playbook",
+		Issues: invalidErr.Issues,
+	}
+	ReturnJSON(w, &result, http.StatusBadRequest)
+	return true
+}
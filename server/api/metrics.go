@@ -0,0 +1,87 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
+)
+
+// MetricsHandler serves the Prometheus metrics of the plugin.
+type MetricsHandler struct {
+	*ErrorHandler
+	config  config.Service
+	metrics *metrics.Metrics
+}
+
+// NewMetricsHandler serves the metrics at /metrics on router, and records the latency of the
+// requests handled by apiRouter. Scrapers authenticate by passing the admin-configured metrics
+// token in the token query parameter, since the server doesn't forward the Authorization header
+// to plugins.
+func NewMetricsHandler(router, apiRouter *mux.Router, configService config.Service, log bot.Logger, m *metrics.Metrics) *MetricsHandler {
+	handler := &MetricsHandler{
+		ErrorHandler: &ErrorHandler{log: log},
+		config:       configService,
+		metrics:      m,
+	}
+
+	router.Handle("/metrics", handler.tokenRequired(m.Handler())).Methods(http.MethodGet)
+	apiRouter.Use(handler.observeRequest)
+
+	return handler
+}
+
+func (h *MetricsHandler) tokenRequired(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expected := h.config.GetConfiguration().MetricsToken
+		if expected == "" {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "Metrics are disabled", errors.New("metrics token is not configured"))
+			return
+		}
+
+		token := r.URL.Query().Get("token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+			h.HandleErrorWithCode(w, http.StatusUnauthorized, "Not authorized", errors.New("invalid metrics token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// observeRequest records the latency of the request, labeled by its route template so that
+// the IDs in the path don't create a new series per request.
+func (h *MetricsHandler) observeRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		route := "unknown"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		h.metrics.ObserveAPIRequest(route, r.Method, recorder.statusCode, time.Since(start))
+	})
+}
+
+// statusRecorder remembers the status code written to the wrapped ResponseWriter.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
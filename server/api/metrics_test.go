@@ -0,0 +1,91 @@
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	mock_poster "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	mock_config "github.com/mattermost/mattermost-plugin-playbooks/server/config/mocks"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+type emptyMetricsStore struct{}
+
+func (s *emptyMetricsStore) InProgressRunsByTeamAndPlaybook() ([]sqlstore.RunsCount, error) {
+	return nil, nil
+}
+
+func (s *emptyMetricsStore) OverdueStatusUpdatesByTeamAndPlaybook() ([]sqlstore.RunsCount, error) {
+	return nil, nil
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	setup := func(t *testing.T, token string) *Handler {
+		t.Helper()
+
+		mockCtrl := gomock.NewController(t)
+		configService := mock_config.NewMockService(mockCtrl)
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{MetricsToken: token}).AnyTimes()
+		logger := mock_poster.NewMockLogger(mockCtrl)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+		client := pluginapi.NewClient(&plugintest.API{}, &plugintest.Driver{})
+
+		handler := NewHandler(client, configService, logger)
+		NewMetricsHandler(handler.Router, handler.APIRouter, configService, logger, metrics.NewMetrics(&emptyMetricsStore{}))
+		handler.APIRouter.HandleFunc("/runs/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+
+		return handler
+	}
+
+	get := func(handler *Handler, url, userID string) (int, string) {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		if userID != "" {
+			request.Header.Set("Mattermost-User-ID", userID)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		body, _ := ioutil.ReadAll(recorder.Result().Body)
+		return recorder.Code, string(body)
+	}
+
+	t.Run("disabled without a token", func(t *testing.T) {
+		handler := setup(t, "")
+
+		code, _ := get(handler, "/metrics", "")
+		require.Equal(t, http.StatusForbidden, code)
+
+		code, _ = get(handler, "/metrics?token=", "")
+		require.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		handler := setup(t, "secret")
+
+		code, _ := get(handler, "/metrics?token=guess", "")
+		require.Equal(t, http.StatusUnauthorized, code)
+	})
+
+	t.Run("valid token, with the latency of API requests by route", func(t *testing.T) {
+		handler := setup(t, "secret")
+
+		code, _ := get(handler, "/api/v0/runs/abc", "userid")
+		require.Equal(t, http.StatusTeapot, code)
+
+		code, body := get(handler, "/metrics?token=secret", "")
+		require.Equal(t, http.StatusOK, code)
+		require.Contains(t, body, `playbooks_api_request_duration_seconds_count{method="GET",route="/api/v0/runs/{id}",status_code="418"} 1`)
+	})
+}
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -52,6 +53,7 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	playbookRunsRouter.HandleFunc("/dialog", handler.createPlaybookRunFromDialog).Methods(http.MethodPost)
 	playbookRunsRouter.HandleFunc("/add-to-timeline-dialog", handler.addToTimelineDialog).Methods(http.MethodPost)
 	playbookRunsRouter.HandleFunc("/owners", handler.getOwners).Methods(http.MethodGet)
+	playbookRunsRouter.HandleFunc("/search", handler.searchPlaybookRuns).Methods(http.MethodGet)
 	playbookRunsRouter.HandleFunc("/channels", handler.getChannels).Methods(http.MethodGet)
 	playbookRunsRouter.HandleFunc("/checklist-autocomplete", handler.getChecklistAutocomplete).Methods(http.MethodGet)
 	playbookRunsRouter.HandleFunc("/checklist-autocomplete-item", handler.getChecklistAutocompleteItem).Methods(http.MethodGet)
@@ -59,15 +61,14 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	playbookRunRouter := playbookRunsRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
 	playbookRunRouter.HandleFunc("", handler.getPlaybookRun).Methods(http.MethodGet)
 	playbookRunRouter.HandleFunc("/metadata", handler.getPlaybookRunMetadata).Methods(http.MethodGet)
+	playbookRunRouter.HandleFunc("/save-as-playbook", handler.saveAsPlaybook).Methods(http.MethodPost)
+	playbookRunRouter.HandleFunc("/playbook-changes", handler.getPlaybookChanges).Methods(http.MethodGet)
+	playbookRunRouter.HandleFunc("/playbook-changes/accept", handler.acceptPlaybookChanges).Methods(http.MethodPost)
+	playbookRunRouter.HandleFunc("/approval-button", handler.approvalButton).Methods(http.MethodPost)
 
 	playbookRunRouterAuthorized := playbookRunRouter.PathPrefix("").Subrouter()
 	playbookRunRouterAuthorized.Use(handler.checkEditPermissions)
 	playbookRunRouterAuthorized.HandleFunc("", handler.updatePlaybookRun).Methods(http.MethodPatch)
-	playbookRunRouterAuthorized.HandleFunc("/owner", handler.changeOwner).Methods(http.MethodPost)
-	playbookRunRouterAuthorized.HandleFunc("/status", handler.status).Methods(http.MethodPost)
-	playbookRunRouterAuthorized.HandleFunc("/finish", handler.finish).Methods(http.MethodPut)
-	playbookRunRouterAuthorized.HandleFunc("/finish-dialog", handler.finishDialog).Methods(http.MethodPost)
-	playbookRunRouterAuthorized.HandleFunc("/update-status-dialog", handler.updateStatusDialog).Methods(http.MethodPost)
 	playbookRunRouterAuthorized.HandleFunc("/reminder/button-update", handler.reminderButtonUpdate).Methods(http.MethodPost)
 	playbookRunRouterAuthorized.HandleFunc("/reminder", handler.reminderDelete).Methods(http.MethodDelete)
 	playbookRunRouterAuthorized.HandleFunc("/reminder/button-dismiss", handler.reminderButtonDismiss).Methods(http.MethodPost)
@@ -75,11 +76,34 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	playbookRunRouterAuthorized.HandleFunc("/timeline/{eventID:[A-Za-z0-9]+}", handler.removeTimelineEvent).Methods(http.MethodDelete)
 	playbookRunRouterAuthorized.HandleFunc("/check-and-send-message-on-join/{channel_id:[A-Za-z0-9]+}", handler.checkAndSendMessageOnJoin).Methods(http.MethodGet)
 	playbookRunRouterAuthorized.HandleFunc("/update-description", handler.updateDescription).Methods(http.MethodPut)
+	playbookRunRouterAuthorized.HandleFunc("/custom-data", handler.updateCustomData).Methods(http.MethodPut)
+	playbookRunRouterAuthorized.HandleFunc("/retrospective", handler.updateRetrospective).Methods(http.MethodPost)
+
+	// The actions below are controlled by the run permission policy of the playbook.
+	ownerRouter := playbookRunRouter.PathPrefix("/owner").Subrouter()
+	ownerRouter.Use(handler.checkRunPermission(app.RunActionChangeOwner))
+	ownerRouter.HandleFunc("", handler.changeOwner).Methods(http.MethodPost)
+
+	statusRouter := playbookRunRouter.PathPrefix("").Subrouter()
+	statusRouter.Use(handler.checkRunPermission(app.RunActionPostStatusUpdate))
+	statusRouter.HandleFunc("/status", handler.status).Methods(http.MethodPost)
+	statusRouter.HandleFunc("/update-status-dialog", handler.updateStatusDialog).Methods(http.MethodPost)
+	statusRouter.HandleFunc("/run-status", handler.changeRunStatus).Methods(http.MethodPost)
+
+	finishRouter := playbookRunRouter.PathPrefix("").Subrouter()
+	finishRouter.Use(handler.checkRunPermission(app.RunActionFinish))
+	finishRouter.HandleFunc("/finish", handler.finish).Methods(http.MethodPut)
+	finishRouter.HandleFunc("/finish-dialog", handler.finishDialog).Methods(http.MethodPost)
+
+	retrospectiveRouter := playbookRunRouter.PathPrefix("/retrospective").Subrouter()
+	retrospectiveRouter.Use(handler.checkRunPermission(app.RunActionPublishRetrospective))
+	retrospectiveRouter.HandleFunc("/publish", handler.publishRetrospective).Methods(http.MethodPost)
 
 	channelRouter := playbookRunsRouter.PathPrefix("/channel").Subrouter()
 	channelRouter.HandleFunc("/{channel_id:[A-Za-z0-9]+}", handler.getPlaybookRunByChannel).Methods(http.MethodGet)
 
-	checklistsRouter := playbookRunRouterAuthorized.PathPrefix("/checklists").Subrouter()
+	checklistsRouter := playbookRunRouter.PathPrefix("/checklists").Subrouter()
+	checklistsRouter.Use(handler.checkRunPermission(app.RunActionEditChecklists))
 
 	checklistRouter := checklistsRouter.PathPrefix("/{checklist:[0-9]+}").Subrouter()
 	checklistRouter.HandleFunc("/add", handler.addChecklistItem).Methods(http.MethodPut)
@@ -92,10 +116,8 @@ func NewPlaybookRunHandler(router *mux.Router, playbookRunService app.PlaybookRu
 	checklistItem.HandleFunc("/state", handler.itemSetState).Methods(http.MethodPut)
 	checklistItem.HandleFunc("/assignee", handler.itemSetAssignee).Methods(http.MethodPut)
 	checklistItem.HandleFunc("/run", handler.itemRun).Methods(http.MethodPost)
-
-	retrospectiveRouter := playbookRunRouterAuthorized.PathPrefix("/retrospective").Subrouter()
-	retrospectiveRouter.HandleFunc("", handler.updateRetrospective).Methods(http.MethodPost)
-	retrospectiveRouter.HandleFunc("/publish", handler.publishRetrospective).Methods(http.MethodPost)
+	checklistItem.HandleFunc("/command-preview", handler.itemCommandPreview).Methods(http.MethodGet)
+	checklistItem.HandleFunc("/http-action", handler.itemHTTPAction).Methods(http.MethodPost)
 
 	return handler
 }
@@ -124,6 +146,34 @@ func (h *PlaybookRunHandler) checkEditPermissions(next http.Handler) http.Handle
 	})
 }
 
+// checkRunPermission returns a middleware requiring the user to be allowed to perform action on
+// the run by the run permission policy of its playbook.
+func (h *PlaybookRunHandler) checkRunPermission(action app.RunAction) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			vars := mux.Vars(r)
+			userID := r.Header.Get("Mattermost-User-ID")
+
+			playbookRun, err := h.playbookRunService.GetPlaybookRun(vars["id"])
+			if err != nil {
+				h.HandleError(w, err)
+				return
+			}
+
+			if err := app.RunActionAccess(userID, playbookRun, action, h.playbookService, h.pluginAPI); err != nil {
+				if errors.Is(err, app.ErrNoPermissions) {
+					h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+					return
+				}
+				h.HandleError(w, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // createPlaybookRunFromPost handles the POST /runs endpoint
 func (h *PlaybookRunHandler) createPlaybookRunFromPost(w http.ResponseWriter, r *http.Request) {
 	userID := r.Header.Get("Mattermost-User-ID")
@@ -142,6 +192,7 @@ func (h *PlaybookRunHandler) createPlaybookRunFromPost(w http.ResponseWriter, r
 			Description: playbookRunCreateOptions.Description,
 			PostID:      playbookRunCreateOptions.PostID,
 			PlaybookID:  playbookRunCreateOptions.PlaybookID,
+			CustomData:  playbookRunCreateOptions.CustomData,
 		},
 		userID,
 	)
@@ -352,6 +403,10 @@ func (h *PlaybookRunHandler) createPlaybookRun(playbookRun app.PlaybookRun, user
 		return nil, errors.Wrap(app.ErrMalformedPlaybookRun, "missing name of playbook run")
 	}
 
+	if err := app.ValidateCustomData(playbookRun.CustomData); err != nil {
+		return nil, err
+	}
+
 	// Owner should have permission to the team
 	if !app.CanViewTeam(playbookRun.OwnerUserID, playbookRun.TeamID, h.pluginAPI) {
 		return nil, errors.Wrap(app.ErrPermission, "owner user does not have permissions for the team")
@@ -365,8 +420,16 @@ func (h *PlaybookRunHandler) createPlaybookRun(playbookRun app.PlaybookRun, user
 			return nil, errors.Wrapf(err, "failed to get playbook")
 		}
 
-		if len(pb.MemberIDs) != 0 && !sliceContains(pb.MemberIDs, userID) {
-			return nil, errors.New("userID is not a member of playbook")
+		if !app.HasPlaybookRole(userID, pb, app.PlaybookRoleRunner, h.pluginAPI) {
+			return nil, errors.Wrap(app.ErrPermission, "the runner role on the playbook is required to run it")
+		}
+
+		if pb.DeleteAt != 0 {
+			return nil, errors.Wrap(app.ErrMalformedPlaybookRun, "the playbook is archived")
+		}
+
+		if !pb.IsSharedWithTeam(playbookRun.TeamID) {
+			return nil, errors.Wrap(app.ErrPermission, "the playbook is not shared with the team of the run")
 		}
 
 		playbookRun.Checklists = pb.Checklists
@@ -414,6 +477,8 @@ func (h *PlaybookRunHandler) createPlaybookRun(playbookRun app.PlaybookRun, user
 			playbookRun.CategoryName = pb.CategoryName
 		}
 
+		playbookRun.RunStatuses = app.CloneRunStatuses(pb.RunStatuses)
+
 		playbookRun.RetrospectiveReminderIntervalSeconds = pb.RetrospectiveReminderIntervalSeconds
 		playbookRun.Retrospective = pb.RetrospectiveTemplate
 
@@ -471,6 +536,36 @@ func (h *PlaybookRunHandler) getPlaybookRuns(w http.ResponseWriter, r *http.Requ
 	ReturnJSON(w, results, http.StatusOK)
 }
 
+// searchPlaybookRuns handles the GET /runs/search endpoint.
+func (h *PlaybookRunHandler) searchPlaybookRuns(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	searchOptions, err := parsePlaybookRunSearchOptions(r.URL)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Bad parameter", err)
+		return
+	}
+
+	if searchOptions.TeamID != "" && !app.CanViewTeam(userID, searchOptions.TeamID, h.pluginAPI) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s does not have permission to view team %s", userID, searchOptions.TeamID))
+		return
+	}
+
+	requesterInfo, err := h.getRequesterInfo(userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	results, err := h.playbookRunService.SearchPlaybookRuns(requesterInfo, *searchOptions)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, results, http.StatusOK)
+}
+
 // getPlaybookRun handles the /runs/{id} endpoint.
 func (h *PlaybookRunHandler) getPlaybookRun(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -491,6 +586,163 @@ func (h *PlaybookRunHandler) getPlaybookRun(w http.ResponseWriter, r *http.Reque
 	ReturnJSON(w, playbookRunToGet, http.StatusOK)
 }
 
+// saveAsPlaybook handles the POST /runs/{id}/save-as-playbook endpoint, creating a playbook
+// with the checklists of the run.
+func (h *PlaybookRunHandler) saveAsPlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playbookRunID := vars["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var options client.SaveAsPlaybookOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode save as playbook options", err)
+		return
+	}
+
+	if err := app.UserCanViewPlaybookRun(userID, playbookRunID, h.playbookService, h.playbookRunService, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	playbookRun, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	// Keep the settings of the playbook of the run, if the user can see them.
+	var source *app.Playbook
+	if playbookRun.PlaybookID != "" {
+		pb, getErr := h.playbookService.Get(playbookRun.PlaybookID)
+		if getErr != nil && !errors.Is(getErr, app.ErrNotFound) {
+			h.HandleError(w, getErr)
+			return
+		}
+		if getErr == nil && app.CheckPlaybookRole(userID, pb, app.PlaybookRoleViewer, h.pluginAPI) == nil {
+			source = &pb
+		}
+	}
+
+	playbook := app.PlaybookFromRun(userID, playbookRun, source, strings.TrimSpace(options.Title), options.Restricted)
+	if err = app.CreatePlaybook(userID, playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	id, err := h.playbookService.Create(playbook, userID)
+	if h.rejectInvalidPlaybook(w, err) {
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// getRunAndPlaybook returns the run and its playbook for the playbook changes endpoints,
+// handling the errors.
+func (h *PlaybookRunHandler) getRunAndPlaybook(w http.ResponseWriter, playbookRunID, userID string) (*app.PlaybookRun, *app.Playbook, bool) {
+	if err := app.UserCanViewPlaybookRun(userID, playbookRunID, h.playbookService, h.playbookRunService, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return nil, nil, false
+	}
+
+	playbookRun, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		h.HandleError(w, err)
+		return nil, nil, false
+	}
+
+	if playbookRun.PlaybookID == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "the run has no playbook", nil)
+		return nil, nil, false
+	}
+
+	playbook, err := h.playbookService.Get(playbookRun.PlaybookID)
+	if err != nil {
+		h.HandleError(w, err)
+		return nil, nil, false
+	}
+
+	if err = app.CheckPlaybookRole(userID, playbook, app.PlaybookRoleViewer, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return nil, nil, false
+	}
+
+	return playbookRun, &playbook, true
+}
+
+// getPlaybookChanges handles the GET /runs/{id}/playbook-changes endpoint, listing the changes
+// to the checklists made during the run that can be merged back into its playbook.
+func (h *PlaybookRunHandler) getPlaybookChanges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	playbookRun, playbook, ok := h.getRunAndPlaybook(w, vars["id"], userID)
+	if !ok {
+		return
+	}
+
+	changes := app.DiffPlaybookChecklists(playbook.Checklists, playbookRun.Checklists)
+	if changes == nil {
+		changes = []app.PlaybookChange{}
+	}
+
+	ReturnJSON(w, changes, http.StatusOK)
+}
+
+// acceptPlaybookChanges handles the POST /runs/{id}/playbook-changes/accept endpoint, merging
+// the given changes into the playbook of the run.
+func (h *PlaybookRunHandler) acceptPlaybookChanges(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var options client.AcceptPlaybookChangesOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode accepted changes", err)
+		return
+	}
+
+	playbookRun, playbook, ok := h.getRunAndPlaybook(w, vars["id"], userID)
+	if !ok {
+		return
+	}
+
+	checklists, err := app.ApplyPlaybookChanges(playbook.Checklists, playbookRun.Checklists, options.ChangeIDs)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusConflict, "unable to accept the changes", err)
+		return
+	}
+
+	updated := playbook.Clone()
+	updated.Checklists = checklists
+	if err = app.PlaybookModify(userID, updated, *playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if err = checkAutoRunUsers(userID, updated.Checklists, playbook.Checklists); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	if err = h.playbookService.Update(updated, userID); h.rejectInvalidPlaybook(w, err) {
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // getPlaybookRunMetadata handles the /runs/{id}/metadata endpoint.
 func (h *PlaybookRunHandler) getPlaybookRunMetadata(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -671,8 +923,7 @@ func (h *PlaybookRunHandler) status(w http.ResponseWriter, r *http.Request) {
 	options.Reminder = options.Reminder * time.Second
 
 	err = h.playbookRunService.UpdateStatus(playbookRunID, userID, options)
-	if err != nil {
-		h.HandleError(w, err)
+	if h.handleRunStatusError(w, playbookRunID, err) {
 		return
 	}
 
@@ -680,12 +931,67 @@ func (h *PlaybookRunHandler) status(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(`{"status":"OK"}`))
 }
 
+// changeRunStatus handles the POST /runs/{id}/run-status endpoint, moving the run to one of the
+// custom statuses it can move to from its current one.
+func (h *PlaybookRunHandler) changeRunStatus(w http.ResponseWriter, r *http.Request) {
+	playbookRunID := mux.Vars(r)["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Status string `json:"status"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode the run status", err)
+		return
+	}
+
+	err := h.playbookRunService.ChangeRunStatus(playbookRunID, userID, params.Status)
+	if h.handleRunStatusError(w, playbookRunID, err) {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleRunStatusError writes the response to a failed change of the run status, if err is not
+// nil, and returns whether it did.
+func (h *PlaybookRunHandler) handleRunStatusError(w http.ResponseWriter, playbookRunID string, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, app.ErrRequiredItemsOpen):
+		h.rejectOpenRequiredItems(w, playbookRunID, err)
+	case errors.Is(err, app.ErrNoPermissions):
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+	case errors.Is(err, app.ErrMalformedPlaybookRun), errors.Is(err, app.ErrPlaybookRunNotActive):
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to change the run status", err)
+	default:
+		h.HandleError(w, err)
+	}
+	return true
+}
+
 // updateStatusD handles the POST /runs/{id}/finish endpoint, user has edit permissions
 func (h *PlaybookRunHandler) finish(w http.ResponseWriter, r *http.Request) {
 	playbookRunID := mux.Vars(r)["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	if err := h.playbookRunService.FinishPlaybookRun(playbookRunID, userID); err != nil {
+	var params struct {
+		OverrideRequiredItems bool `json:"override_required_items"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil && err != io.EOF {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode the finish parameters", err)
+		return
+	}
+
+	err := h.playbookRunService.FinishPlaybookRun(playbookRunID, userID, params.OverrideRequiredItems)
+	if errors.Is(err, app.ErrRequiredItemsOpen) {
+		h.rejectOpenRequiredItems(w, playbookRunID, err)
+		return
+	} else if errors.Is(err, app.ErrNoPermissions) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	} else if err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -694,24 +1000,60 @@ func (h *PlaybookRunHandler) finish(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write([]byte(`{"status":"OK"}`))
 }
 
+// rejectOpenRequiredItems responds with the required items of the run that are not done, which
+// prevent finishing it.
+func (h *PlaybookRunHandler) rejectOpenRequiredItems(w http.ResponseWriter, playbookRunID string, internalErr error) {
+	playbookRun, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	h.log.Warnf("public error message: %v; internal details: %v", "required items are not done", internalErr)
+
+	result := struct {
+		Error         string                 `json:"error"`
+		RequiredItems []app.OpenRequiredItem `json:"required_items"`
+	}{
+		Error:         "The run can't be finished until its required items are done.",
+		RequiredItems: app.OpenRequiredItems(playbookRun.Checklists),
+	}
+	ReturnJSON(w, &result, http.StatusConflict)
+}
+
 // updateStatusDialog handles the POST /runs/{id}/finish-dialog endpoint, called when a
 // user submits the Finish Run dialog.
 func (h *PlaybookRunHandler) finishDialog(w http.ResponseWriter, r *http.Request) {
 	playbookRunID := mux.Vars(r)["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	playbookRun, incErr := h.playbookRunService.GetPlaybookRun(playbookRunID)
-	if incErr != nil {
-		h.HandleError(w, incErr)
+	var request *model.SubmitDialogRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to decode SubmitDialogRequest", err)
 		return
 	}
 
-	if err := app.EditPlaybookRun(userID, playbookRun.ChannelID, h.pluginAPI); err != nil {
-		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", fmt.Errorf("user %s cannot post to playbook run channel %s", userID, playbookRun.ChannelID))
-		return
+	override := false
+	if request != nil {
+		override, _ = request.Submission[app.DialogFieldOverrideRequiredItemsKey].(bool)
 	}
 
-	if err := h.playbookRunService.FinishPlaybookRun(playbookRunID, userID); err != nil {
+	err := h.playbookRunService.FinishPlaybookRun(playbookRunID, userID, override)
+	if errors.Is(err, app.ErrRequiredItemsOpen) || errors.Is(err, app.ErrNoPermissions) {
+		resp := &model.SubmitDialogResponse{
+			Error: "The run can't be finished until its required tasks are done.",
+		}
+		if errors.Is(err, app.ErrRequiredItemsOpen) && app.IsAdmin(userID, h.pluginAPI) {
+			resp = &model.SubmitDialogResponse{
+				Errors: map[string]string{
+					app.DialogFieldOverrideRequiredItemsKey: "Check to finish the run without the required tasks.",
+				},
+			}
+		}
+		respBytes, _ := json.Marshal(resp)
+		_, _ = w.Write(respBytes)
+		return
+	} else if err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -757,8 +1099,22 @@ func (h *PlaybookRunHandler) updateStatusDialog(w http.ResponseWriter, r *http.R
 		}
 	}
 
+	options.RunStatus, _ = request.Submission[app.DialogFieldRunStatusKey].(string)
+
 	err = h.playbookRunService.UpdateStatus(playbookRunID, userID, options)
-	if err != nil {
+	if errors.Is(err, app.ErrRequiredItemsOpen) || errors.Is(err, app.ErrMalformedPlaybookRun) || errors.Is(err, app.ErrNoPermissions) {
+		message := "The run can't move to this status."
+		if errors.Is(err, app.ErrRequiredItemsOpen) {
+			message = "The run can't be finished until its required tasks are done."
+		} else if errors.Is(err, app.ErrNoPermissions) {
+			message = "You don't have permission to finish this run."
+		}
+		respBytes, _ := json.Marshal(&model.SubmitDialogResponse{
+			Errors: map[string]string{app.DialogFieldRunStatusKey: message},
+		})
+		_, _ = w.Write(respBytes)
+		return
+	} else if err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -783,7 +1139,13 @@ func (h *PlaybookRunHandler) reminderButtonUpdate(w http.ResponseWriter, r *http
 		return
 	}
 
-	if err = app.EditPlaybookRun(requestData.UserId, requestData.ChannelId, h.pluginAPI); err != nil {
+	playbookRun, err := h.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if err = app.RunActionAccess(requestData.UserId, playbookRun, app.RunActionPostStatusUpdate, h.playbookService, h.pluginAPI); err != nil {
 		if errors.Is(err, app.ErrNoPermissions) {
 			ReturnJSON(w, nil, http.StatusForbidden)
 			return
@@ -880,7 +1242,7 @@ func (h *PlaybookRunHandler) noRetrospectiveButton(w http.ResponseWriter, r *htt
 		return
 	}
 
-	if err = app.EditPlaybookRun(userID, playbookRunToCancelRetro.ChannelID, h.pluginAPI); err != nil {
+	if err = app.RunActionAccess(userID, playbookRunToCancelRetro, app.RunActionPublishRetrospective, h.playbookService, h.pluginAPI); err != nil {
 		if errors.Is(err, app.ErrNoPermissions) {
 			ReturnJSON(w, nil, http.StatusForbidden)
 			return
@@ -897,6 +1259,55 @@ func (h *PlaybookRunHandler) noRetrospectiveButton(w http.ResponseWriter, r *htt
 	ReturnJSON(w, nil, http.StatusOK)
 }
 
+// approvalButton handles the POST /runs/{id}/approval-button endpoint, called when an approver
+// clicks on the approve or reject button of an approval request. The approvers don't need any
+// permission on the run: the service checks they are approvers of the item.
+func (h *PlaybookRunHandler) approvalButton(w http.ResponseWriter, r *http.Request) {
+	playbookRunID := mux.Vars(r)["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var requestData *model.PostActionIntegrationRequest
+	err := json.NewDecoder(r.Body).Decode(&requestData)
+	if err != nil || requestData == nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "missing request data", nil)
+		return
+	}
+
+	itemID, _ := requestData.Context["item_id"].(string)
+	action, _ := requestData.Context["action"].(string)
+	if itemID == "" || (action != "approve" && action != "reject") {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid approval action", nil)
+		return
+	}
+
+	err = h.playbookRunService.DecideChecklistItemApproval(playbookRunID, userID, itemID, action == "approve")
+	var message string
+	switch {
+	case err == nil && action == "approve":
+		message = "You approved this item."
+	case err == nil:
+		message = "You rejected this item."
+	case errors.Is(err, app.ErrNoPermissions):
+		ReturnJSON(w, &model.PostActionIntegrationResponse{EphemeralText: "You can't approve this item."}, http.StatusOK)
+		return
+	case errors.Is(err, app.ErrMalformedPlaybookRun), errors.Is(err, app.ErrNotFound), errors.Is(err, app.ErrPlaybookRunNotActive):
+		message = "This item no longer waits for approval."
+	default:
+		h.HandleError(w, err)
+		return
+	}
+
+	post, err := h.pluginAPI.Post.GetPost(requestData.PostId)
+	if err != nil {
+		ReturnJSON(w, &model.PostActionIntegrationResponse{EphemeralText: message}, http.StatusOK)
+		return
+	}
+	post.Message += "\n\n" + message
+	post.DelProp("attachments")
+
+	ReturnJSON(w, &model.PostActionIntegrationResponse{Update: post}, http.StatusOK)
+}
+
 // removeTimelineEvent handles the DELETE /runs/{id}/timeline/{eventID} endpoint.
 // User has been authenticated to edit the playbook run.
 func (h *PlaybookRunHandler) removeTimelineEvent(w http.ResponseWriter, r *http.Request) {
@@ -960,6 +1371,33 @@ func (h *PlaybookRunHandler) updateDescription(w http.ResponseWriter, r *http.Re
 	ReturnJSON(w, nil, http.StatusOK)
 }
 
+// updateCustomData handles the PUT /runs/{id}/custom-data endpoint, replacing the values of the
+// {{custom.<key>}} placeholders of the checklists of the run.
+func (h *PlaybookRunHandler) updateCustomData(w http.ResponseWriter, r *http.Request) {
+	playbookRunID := mux.Vars(r)["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var customData map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&customData); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode custom data", err)
+		return
+	}
+
+	err := h.playbookRunService.UpdateCustomData(playbookRunID, userID, customData)
+	if errors.Is(err, app.ErrMalformedPlaybookRun) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid custom data", err)
+		return
+	} else if errors.Is(err, app.ErrNoPermissions) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 func (h *PlaybookRunHandler) getChecklistAutocompleteItem(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 	channelID := query.Get("channel_id")
@@ -1101,6 +1539,73 @@ func (h *PlaybookRunHandler) itemRun(w http.ResponseWriter, r *http.Request) {
 	ReturnJSON(w, map[string]interface{}{"trigger_id": triggerID}, http.StatusOK)
 }
 
+// itemCommandPreview handles the GET /runs/{id}/checklists/{checklist}/item/{item}/command-preview
+// endpoint, returning the slash command of the item as it would run now.
+func (h *PlaybookRunHandler) itemCommandPreview(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playbookRunID := vars["id"]
+	checklistNum, err := strconv.Atoi(vars["checklist"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse checklist", err)
+		return
+	}
+	itemNum, err := strconv.Atoi(vars["item"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	preview, err := h.playbookRunService.PreviewChecklistItemSlashCommand(playbookRunID, userID, checklistNum, itemNum)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &preview, http.StatusOK)
+}
+
+// itemHTTPAction handles the POST /runs/{id}/checklists/{checklist}/item/{item}/http-action
+// endpoint, performing the HTTP action of the item and returning the status and truncated body of
+// the response.
+func (h *PlaybookRunHandler) itemHTTPAction(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playbookRunID := vars["id"]
+	checklistNum, err := strconv.Atoi(vars["checklist"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse checklist", err)
+		return
+	}
+	itemNum, err := strconv.Atoi(vars["item"])
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to parse item", err)
+		return
+	}
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var params struct {
+		Confirmed bool `json:"confirmed"`
+	}
+	if err = json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "failed to decode the HTTP action parameters", err)
+		return
+	}
+
+	result, err := h.playbookRunService.RunChecklistItemHTTPAction(playbookRunID, userID, checklistNum, itemNum, params.Confirmed)
+	if errors.Is(err, app.ErrConfirmationRequired) {
+		h.HandleErrorWithCode(w, http.StatusConflict, "the HTTP action must be confirmed", err)
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybookRun) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to perform the HTTP action", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &result, http.StatusOK)
+}
+
 func (h *PlaybookRunHandler) addChecklistItem(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
@@ -1124,6 +1629,11 @@ func (h *PlaybookRunHandler) addChecklistItem(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if err := checkAutoRunUsers(userID, []app.Checklist{{Items: []app.ChecklistItem{checklistItem}}}, nil); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
 	if err := h.playbookRunService.AddChecklistItem(id, userID, checklistNum, checklistItem); err != nil {
 		h.HandleError(w, err)
 		return
@@ -1199,7 +1709,10 @@ func (h *PlaybookRunHandler) itemDelete(w http.ResponseWriter, r *http.Request)
 	}
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	if err := h.playbookRunService.RemoveChecklistItem(id, userID, checklistNum, itemNum); err != nil {
+	if err := h.playbookRunService.RemoveChecklistItem(id, userID, checklistNum, itemNum); errors.Is(err, app.ErrNoPermissions) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	} else if err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -1347,6 +1860,13 @@ func parsePlaybookRunsFilterOptions(u *url.URL, currentUserID string) (*app.Play
 		return nil, errors.Wrapf(err, "bad parameter 'per_page'")
 	}
 
+	cursor := u.Query().Get("cursor")
+
+	skipCount, err := parseBoolParam(u, "skip_count")
+	if err != nil {
+		return nil, err
+	}
+
 	sort := u.Query().Get("sort")
 	direction := u.Query().Get("direction")
 
@@ -1391,21 +1911,69 @@ func parsePlaybookRunsFilterOptions(u *url.URL, currentUserID string) (*app.Play
 	}
 	startedLT, _ := strconv.ParseInt(startedLTParam, 10, 64)
 
+	finishedGTEParam := u.Query().Get("finished_gte")
+	if finishedGTEParam == "" {
+		finishedGTEParam = "0"
+	}
+	finishedGTE, _ := strconv.ParseInt(finishedGTEParam, 10, 64)
+
+	finishedLTParam := u.Query().Get("finished_lt")
+	if finishedLTParam == "" {
+		finishedLTParam = "0"
+	}
+	finishedLT, _ := strconv.ParseInt(finishedLTParam, 10, 64)
+
+	// Parse playbook_ids= query string parameters as an array.
+	playbookIDs := u.Query()["playbook_ids"]
+
+	reporterID := u.Query().Get("reporter_user_id")
+	if reporterID == client.Me {
+		reporterID = currentUserID
+	}
+
+	assigneeID := u.Query().Get("assignee_id")
+	if assigneeID == client.Me {
+		assigneeID = currentUserID
+	}
+
+	statusUpdateOverdue, err := parseBoolParam(u, "status_update_overdue")
+	if err != nil {
+		return nil, err
+	}
+
+	openTasksGTEParam := u.Query().Get("open_tasks_gte")
+	if openTasksGTEParam == "" {
+		openTasksGTEParam = "0"
+	}
+	openTasksGTE, err := strconv.Atoi(openTasksGTEParam)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad parameter 'open_tasks_gte'")
+	}
+
 	options := app.PlaybookRunFilterOptions{
-		TeamID:        teamID,
-		Page:          page,
-		PerPage:       perPage,
-		Sort:          app.SortField(sort),
-		Direction:     app.SortDirection(direction),
-		Statuses:      statuses,
-		OwnerID:       ownerID,
-		SearchTerm:    searchTerm,
-		ParticipantID: participantID,
-		PlaybookID:    playbookID,
-		ActiveGTE:     activeGTE,
-		ActiveLT:      activeLT,
-		StartedGTE:    startedGTE,
-		StartedLT:     startedLT,
+		TeamID:              teamID,
+		Page:                page,
+		PerPage:             perPage,
+		Cursor:              cursor,
+		SkipCount:           skipCount,
+		Sort:                app.SortField(sort),
+		Direction:           app.SortDirection(direction),
+		Statuses:            statuses,
+		OwnerID:             ownerID,
+		SearchTerm:          searchTerm,
+		ParticipantID:       participantID,
+		PlaybookID:          playbookID,
+		ActiveGTE:           activeGTE,
+		ActiveLT:            activeLT,
+		StartedGTE:          startedGTE,
+		StartedLT:           startedLT,
+		FinishedGTE:         finishedGTE,
+		FinishedLT:          finishedLT,
+		PlaybookIDs:         playbookIDs,
+		ReporterID:          reporterID,
+		AssigneeID:          assigneeID,
+		StatusUpdateOverdue: statusUpdateOverdue,
+		OpenTasksGTE:        openTasksGTE,
 	}
 
 	options, err = options.Validate()
@@ -1416,11 +1984,51 @@ func parsePlaybookRunsFilterOptions(u *url.URL, currentUserID string) (*app.Play
 	return &options, nil
 }
 
-func sliceContains(strs []string, target string) bool {
-	for _, s := range strs {
-		if s == target {
-			return true
-		}
+// parseBoolParam parses the optional boolean query string parameter, defaulting to false.
+func parseBoolParam(u *url.URL, name string) (bool, error) {
+	param := u.Query().Get(name)
+	if param == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, errors.Wrapf(err, "bad parameter '%s'", name)
+	}
+
+	return value, nil
+}
+
+func parsePlaybookRunSearchOptions(u *url.URL) (*app.PlaybookRunSearchOptions, error) {
+	pageParam := u.Query().Get("page")
+	if pageParam == "" {
+		pageParam = "0"
 	}
-	return false
+	page, err := strconv.Atoi(pageParam)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad parameter 'page'")
+	}
+
+	perPageParam := u.Query().Get("per_page")
+	if perPageParam == "" {
+		perPageParam = "0"
+	}
+	perPage, err := strconv.Atoi(perPageParam)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad parameter 'per_page'")
+	}
+
+	options := app.PlaybookRunSearchOptions{
+		Terms:   u.Query().Get("terms"),
+		TeamID:  u.Query().Get("team_id"),
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	options, err = options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &options, nil
 }
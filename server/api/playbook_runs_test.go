@@ -499,7 +499,6 @@ func TestPlaybookRuns(t *testing.T) {
 	t.Run("create playbook run from dialog -- user is not a member of the playbook", func(t *testing.T) {
 		reset(t)
 		setDefaultExpectations(t)
-		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 
 		teamID := model.NewId()
 		withid := app.Playbook{
@@ -531,6 +530,8 @@ func TestPlaybookRuns(t *testing.T) {
 		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{}, nil)
 		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionCreatePublicChannel).Return(true)
 		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionManageTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
 		pluginAPI.On("GetPost", "privatePostID").Return(&model.Post{ChannelId: "privateChannelId"}, nil)
 		pluginAPI.On("HasPermissionToChannel", "testUserID", "privateChannelId", model.PermissionReadChannel).Return(false)
 
@@ -543,7 +544,67 @@ func TestPlaybookRuns(t *testing.T) {
 
 		resp := testrecorder.Result()
 		defer resp.Body.Close()
-		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var dialogResp model.SubmitDialogResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&dialogResp))
+		assert.Contains(t, dialogResp.Errors[app.DialogFieldNameKey], "runner role")
+	})
+
+	t.Run("create playbook run from dialog -- user is only a viewer of the playbook", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		withid := app.Playbook{
+			ID:                      "playbookid1",
+			Title:                   "My Playbook",
+			TeamID:                  teamID,
+			CreatePublicPlaybookRun: true,
+			Members:                 []app.PlaybookMember{{UserID: "testUserID", Role: app.PlaybookRoleViewer}},
+			MemberIDs:               []string{"testUserID"},
+			InviteUsersEnabled:      false,
+			InvitedUserIDs:          []string{"testInvitedUserID1", "testInvitedUserID2"},
+			InvitedGroupIDs:         []string{"testInvitedGroupID1", "testInvitedGroupID2"},
+		}
+
+		dialogRequest := model.SubmitDialogRequest{
+			TeamId: teamID,
+			UserId: "testUserID",
+			State:  "{}",
+			Submission: map[string]interface{}{
+				app.DialogFieldPlaybookIDKey: "playbookid1",
+				app.DialogFieldNameKey:       "playbookRunName",
+			},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(withid, nil).
+			Times(1)
+
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{}, nil)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionCreatePublicChannel).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionManageTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetPost", "privatePostID").Return(&model.Post{ChannelId: "privateChannelId"}, nil)
+		pluginAPI.On("HasPermissionToChannel", "testUserID", "privateChannelId", model.PermissionReadChannel).Return(false)
+
+		testrecorder := httptest.NewRecorder()
+		dialogRequestBytes, _ := json.Marshal(dialogRequest)
+		testreq, err := http.NewRequest("POST", "/api/v0/runs/dialog", bytes.NewBuffer(dialogRequestBytes))
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		require.NoError(t, err)
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var dialogResp model.SubmitDialogResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&dialogResp))
+		assert.Contains(t, dialogResp.Errors[app.DialogFieldNameKey], "runner role")
 	})
 
 	t.Run("create valid playbook run", func(t *testing.T) {
@@ -604,6 +665,122 @@ func TestPlaybookRuns(t *testing.T) {
 		assert.NotEmpty(t, resultPlaybookRun.ID)
 	})
 
+	t.Run("create playbook run in a team the playbook is not shared with", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		teamID := model.NewId()
+		testPlaybook := app.Playbook{
+			ID:            "playbookid1",
+			Title:         "My Playbook",
+			TeamID:        model.NewId(),
+			SharedTeamIDs: []string{model.NewId()},
+			MemberIDs:     []string{"testUserID"},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(testPlaybook, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+
+		resultPlaybookRun, err := c.PlaybookRuns.Create(context.TODO(), icClient.PlaybookRunCreateOptions{
+			Name:        "playbookRunName",
+			OwnerUserID: "testUserID",
+			TeamID:      teamID,
+			PlaybookID:  testPlaybook.ID,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+		assert.Nil(t, resultPlaybookRun)
+	})
+
+	t.Run("create playbook run from an archived playbook", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		teamID := model.NewId()
+		testPlaybook := app.Playbook{
+			ID:        "playbookid1",
+			Title:     "My Playbook",
+			TeamID:    teamID,
+			DeleteAt:  1234,
+			MemberIDs: []string{"testUserID"},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(testPlaybook, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+
+		resultPlaybookRun, err := c.PlaybookRuns.Create(context.TODO(), icClient.PlaybookRunCreateOptions{
+			Name:        "playbookRunName",
+			OwnerUserID: "testUserID",
+			TeamID:      teamID,
+			PlaybookID:  testPlaybook.ID,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		assert.Nil(t, resultPlaybookRun)
+	})
+
+	t.Run("create playbook run in a team the playbook is shared with", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		testPlaybook := app.Playbook{
+			ID:                      "playbookid1",
+			Title:                   "My Playbook",
+			TeamID:                  model.NewId(),
+			SharedTeamIDs:           []string{teamID},
+			Description:             "description",
+			CreatePublicPlaybookRun: true,
+			MemberIDs:               []string{"testUserID"},
+		}
+
+		testPlaybookRun := app.PlaybookRun{
+			OwnerUserID:               "testUserID",
+			TeamID:                    teamID,
+			Name:                      "playbookRunName",
+			Description:               "description",
+			PlaybookID:                testPlaybook.ID,
+			Checklists:                testPlaybook.Checklists,
+			InvitedUserIDs:            []string{},
+			InvitedGroupIDs:           []string{},
+			WebhookOnCreationURLs:     []string{},
+			WebhookOnStatusUpdateURLs: []string{},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookid1").
+			Return(testPlaybook, nil).
+			Times(1)
+
+		retI := testPlaybookRun
+		retI.ID = "playbookRunID"
+		retI.ChannelID = "channelID"
+		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{}, nil)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionCreatePublicChannel).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+		playbookRunService.EXPECT().CreatePlaybookRun(&testPlaybookRun, &testPlaybook, "testUserID", true).Return(&retI, nil)
+
+		poster.EXPECT().
+			PublishWebsocketEventToUser(gomock.Any(), gomock.Any(), gomock.Any())
+
+		resultPlaybookRun, err := c.PlaybookRuns.Create(context.TODO(), icClient.PlaybookRunCreateOptions{
+			Name:        testPlaybookRun.Name,
+			OwnerUserID: testPlaybookRun.OwnerUserID,
+			TeamID:      testPlaybookRun.TeamID,
+			PlaybookID:  testPlaybookRun.PlaybookID,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resultPlaybookRun.ID)
+	})
+
 	t.Run("create valid playbook run, invite users enabled", func(t *testing.T) {
 		reset(t)
 		setDefaultExpectations(t)
@@ -851,6 +1028,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -921,6 +1099,7 @@ func TestPlaybookRuns(t *testing.T) {
 		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
 		playbookService.EXPECT().Get(testPlaybookRun.PlaybookID).Return(testPlaybook, nil)
 		pluginAPI.On("HasPermissionToTeam", userID, teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", userID, teamID, model.PermissionManageTeam).Return(false)
 
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
 
@@ -950,6 +1129,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1024,6 +1204,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1063,6 +1244,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1102,6 +1284,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1142,6 +1325,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1153,6 +1337,7 @@ func TestPlaybookRuns(t *testing.T) {
 		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PermissionReadChannel).Return(false)
 		playbookService.EXPECT().Get(testPlaybookRun.PlaybookID).Return(testPlaybook, nil)
 		pluginAPI.On("HasPermissionToTeam", userID, teamID, model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", userID, teamID, model.PermissionManageTeam).Return(false)
 
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any())
 
@@ -1182,6 +1367,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1261,6 +1447,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1300,6 +1487,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1339,6 +1527,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 		testPlaybook := app.Playbook{
 			TeamID:    teamID,
@@ -1612,6 +1801,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -1664,6 +1854,110 @@ func TestPlaybookRuns(t *testing.T) {
 		assert.Len(t, actualList.Items, 0)
 	})
 
+	t.Run("get playbook runs with the richer filters", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		playbookIDs := []string{model.NewId(), model.NewId()}
+		reporterID := model.NewId()
+		assigneeID := model.NewId()
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PermissionViewTeam).Return(true)
+
+		result := &app.GetPlaybookRunsResults{
+			Items: []app.PlaybookRun{},
+		}
+		playbookRunService.EXPECT().GetPlaybookRuns(gomock.Any(), app.PlaybookRunFilterOptions{
+			TeamID:              teamID,
+			Page:                0,
+			PerPage:             100,
+			Sort:                app.SortByCreateAt,
+			Direction:           app.DirectionAsc,
+			FinishedGTE:         1000,
+			FinishedLT:          2000,
+			PlaybookIDs:         playbookIDs,
+			ReporterID:          reporterID,
+			AssigneeID:          assigneeID,
+			StatusUpdateOverdue: true,
+			OpenTasksGTE:        2,
+		}).Return(result, nil)
+
+		actualList, err := c.PlaybookRuns.List(context.TODO(), 0, 100, icClient.PlaybookRunListOptions{
+			TeamID:              teamID,
+			FinishedGTE:         1000,
+			FinishedLT:          2000,
+			PlaybookIDs:         playbookIDs,
+			ReporterID:          reporterID,
+			AssigneeID:          assigneeID,
+			StatusUpdateOverdue: true,
+			OpenTasksGTE:        2,
+		})
+		require.NoError(t, err)
+		assert.Len(t, actualList.Items, 0)
+	})
+
+	t.Run("iterate over playbook runs with cursors", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+		pluginAPI.On("HasPermissionToTeam", mock.Anything, mock.Anything, model.PermissionViewTeam).Return(true)
+
+		playbookRun1 := app.PlaybookRun{ID: "playbookRunID1", TeamID: teamID, Name: "playbookRunName1", CreateAt: 1}
+		playbookRun2 := app.PlaybookRun{ID: "playbookRunID2", TeamID: teamID, Name: "playbookRunName2", CreateAt: 2}
+		cursor := app.PageCursor{Sort: app.SortByCreateAt, Direction: app.DirectionAsc, Value: int64(1), ID: playbookRun1.ID}.Encode()
+
+		options := app.PlaybookRunFilterOptions{
+			TeamID:    teamID,
+			PerPage:   1,
+			SkipCount: true,
+			Sort:      app.SortByCreateAt,
+			Direction: app.DirectionAsc,
+		}
+		gomock.InOrder(
+			playbookRunService.EXPECT().GetPlaybookRuns(gomock.Any(), options).Return(&app.GetPlaybookRunsResults{
+				HasMore:    true,
+				Items:      []app.PlaybookRun{playbookRun1},
+				NextCursor: cursor,
+			}, nil),
+			playbookRunService.EXPECT().GetPlaybookRuns(gomock.Any(), func() app.PlaybookRunFilterOptions {
+				options.Cursor = cursor
+				return options
+			}()).Return(&app.GetPlaybookRunsResults{
+				Items: []app.PlaybookRun{playbookRun2},
+			}, nil),
+		)
+
+		var names []string
+		it := c.PlaybookRuns.Iterate(1, icClient.PlaybookRunListOptions{TeamID: teamID})
+		for it.Next(context.TODO()) {
+			names = append(names, it.PlaybookRun().Name)
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, []string{"playbookRunName1", "playbookRunName2"}, names)
+		require.Nil(t, it.PlaybookRun())
+	})
+
+	t.Run("get playbook runs with a cursor for another sort", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+
+		cursor := app.PageCursor{Sort: app.SortByName, Direction: app.DirectionAsc, Value: "name", ID: "playbookRunID"}.Encode()
+		_, err := c.PlaybookRuns.List(context.TODO(), 0, 10, icClient.PlaybookRunListOptions{
+			Cursor: cursor,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
 	t.Run("get in progress playbook runs", func(t *testing.T) {
 		reset(t)
 		setDefaultExpectations(t)
@@ -1680,6 +1974,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -1726,6 +2021,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -1766,6 +2062,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -1827,6 +2124,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -1888,6 +2186,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -1949,6 +2248,7 @@ func TestPlaybookRuns(t *testing.T) {
 			InvitedUserIDs:  []string{},
 			InvitedGroupIDs: []string{},
 			TimelineEvents:  []app.TimelineEvent{},
+			CustomData:      map[string]string{},
 		}
 
 		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
@@ -2109,4 +2409,686 @@ func TestPlaybookRuns(t *testing.T) {
 		err := c.PlaybookRuns.UpdateStatus(context.TODO(), "playbookRunID", "  \t   \r   \t  \r\r  ", 600)
 		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
 	})
+
+	t.Run("finish playbook run, owner only policy, not the owner", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "ownerUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			PlaybookID:  "playbookID",
+		}
+		testPlaybook := app.Playbook{
+			ID:     "playbookID",
+			TeamID: testPlaybookRun.TeamID,
+			RunPermissions: app.RunPermissionPolicy{
+				Finish: app.RunPermissionRule{Allow: app.RunPermissionAllowOwner},
+			},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PermissionReadChannel).Return(true)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookService.EXPECT().GetRunPermissions(testPlaybook.ID).Return(testPlaybook.RunPermissions, nil)
+
+		err := c.PlaybookRuns.Finish(context.TODO(), testPlaybookRun.ID)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("finish playbook run, owner only policy, the owner", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			PlaybookID:  "playbookID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookRunService.EXPECT().FinishPlaybookRun(testPlaybookRun.ID, "testUserID", false).Return(nil)
+
+		err := c.PlaybookRuns.Finish(context.TODO(), testPlaybookRun.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("finish playbook run, role policy, playbook editor outside the channel", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "ownerUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			PlaybookID:  "playbookID",
+		}
+		testPlaybook := app.Playbook{
+			ID:     "playbookID",
+			TeamID: testPlaybookRun.TeamID,
+			Members: []app.PlaybookMember{
+				{UserID: "testUserID", Role: app.PlaybookRoleEditor},
+			},
+			RunPermissions: app.RunPermissionPolicy{
+				Finish: app.RunPermissionRule{Allow: app.RunPermissionAllowRole, Role: app.PlaybookRoleEditor},
+			},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PermissionReadChannel).Return(false)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", testPlaybook.TeamID, model.PermissionViewTeam).Return(true)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookService.EXPECT().GetRunPermissions(testPlaybook.ID).Return(testPlaybook.RunPermissions, nil)
+		playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil)
+		playbookRunService.EXPECT().FinishPlaybookRun(testPlaybookRun.ID, "testUserID", false).Return(nil)
+
+		err := c.PlaybookRuns.Finish(context.TODO(), testPlaybookRun.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("finish playbook run whose playbook is gone, not a participant", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:             "playbookRunID",
+			OwnerUserID:    "ownerUserID",
+			TeamID:         model.NewId(),
+			Name:           "playbookRunName",
+			ChannelID:      "channelID",
+			PlaybookID:     "playbookID",
+			ParticipantIDs: []string{"ownerUserID"},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PermissionReadChannel).Return(true)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookService.EXPECT().GetRunPermissions("playbookID").Return(app.RunPermissionPolicy{}, errors.Wrap(app.ErrNotFound, "purged"))
+
+		err := c.PlaybookRuns.Finish(context.TODO(), testPlaybookRun.ID)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("finish playbook run with open required items", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+			Checklists: []app.Checklist{{
+				Title: "Compliance",
+				Items: []app.ChecklistItem{{Title: "Notify the regulator", Required: true}},
+			}},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		playbookRunService.EXPECT().FinishPlaybookRun(testPlaybookRun.ID, "testUserID", false).Return(app.ErrRequiredItemsOpen)
+
+		err := c.PlaybookRuns.Finish(context.TODO(), testPlaybookRun.ID)
+		requireErrorWithStatusCode(t, err, http.StatusConflict)
+	})
+
+	t.Run("finish playbook run overriding the required items", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(true)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookRunService.EXPECT().FinishPlaybookRun(testPlaybookRun.ID, "testUserID", true).Return(nil)
+
+		err := c.PlaybookRuns.FinishOverridingRequiredItems(context.TODO(), testPlaybookRun.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("update playbook run status, participants policy, not a participant", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:             "playbookRunID",
+			OwnerUserID:    "ownerUserID",
+			TeamID:         model.NewId(),
+			Name:           "playbookRunName",
+			ChannelID:      "channelID",
+			PlaybookID:     "playbookID",
+			ParticipantIDs: []string{"ownerUserID", "participantUserID"},
+		}
+		testPlaybook := app.Playbook{
+			ID:     "playbookID",
+			TeamID: testPlaybookRun.TeamID,
+			RunPermissions: app.RunPermissionPolicy{
+				PostStatusUpdate: app.RunPermissionRule{Allow: app.RunPermissionAllowParticipants},
+			},
+		}
+
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PermissionReadChannel).Return(true)
+		pluginAPI.On("HasPermissionToChannel", mock.Anything, mock.Anything, model.PermissionCreatePost).Return(true)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookService.EXPECT().GetRunPermissions(testPlaybook.ID).Return(testPlaybook.RunPermissions, nil)
+
+		err := c.PlaybookRuns.UpdateStatus(context.TODO(), testPlaybookRun.ID, "test message", 600)
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("search playbook runs", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		teamID := model.NewId()
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+
+		result := &app.PlaybookRunSearchResults{
+			TotalCount: 1,
+			PageCount:  1,
+			Items: []app.PlaybookRunSearchResult{
+				{
+					PlaybookRunID: "playbookRunID",
+					Name:          "playbookRunName",
+					TeamID:        teamID,
+					CurrentStatus: app.StatusFinished,
+					CreateAt:      1234,
+					Rank:          0.5,
+					Matches: []app.PlaybookRunSearchMatch{
+						{Source: app.SearchSourceRetrospective, Snippet: "**Redis** ran out of memory"},
+					},
+				},
+			},
+		}
+		playbookRunService.EXPECT().SearchPlaybookRuns(
+			app.RequesterInfo{UserID: "testUserID"},
+			app.PlaybookRunSearchOptions{
+				Terms:   "redis memory",
+				TeamID:  teamID,
+				Page:    0,
+				PerPage: 10,
+			},
+		).Return(result, nil)
+
+		actual, err := c.PlaybookRuns.Search(context.TODO(), " redis memory ", 0, 10, icClient.PlaybookRunSearchOptions{
+			TeamID: teamID,
+		})
+		require.NoError(t, err)
+
+		expected := &icClient.PlaybookRunSearchResults{
+			TotalCount: 1,
+			PageCount:  1,
+			Items: []icClient.PlaybookRunSearchResult{
+				{
+					PlaybookRunID: "playbookRunID",
+					Name:          "playbookRunName",
+					TeamID:        teamID,
+					CurrentStatus: app.StatusFinished,
+					CreateAt:      1234,
+					Rank:          0.5,
+					Matches: []icClient.PlaybookRunSearchMatch{
+						{Source: app.SearchSourceRetrospective, Snippet: "**Redis** ran out of memory"},
+					},
+				},
+			},
+		}
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("search playbook runs - blank terms", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.PlaybookRuns.Search(context.TODO(), "  ", 0, 10, icClient.PlaybookRunSearchOptions{})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("search playbook runs - not a team member", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		teamID := model.NewId()
+		pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(false)
+
+		_, err := c.PlaybookRuns.Search(context.TODO(), "redis", 0, 10, icClient.PlaybookRunSearchOptions{
+			TeamID: teamID,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("playbook changes", func(t *testing.T) {
+		teamID := model.NewId()
+		testPlaybook := app.Playbook{
+			ID:      "playbookID",
+			Title:   "My Playbook",
+			TeamID:  teamID,
+			Members: []app.PlaybookMember{{UserID: "testUserID", Role: app.PlaybookRoleEditor}},
+			Checklists: []app.Checklist{
+				{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page on-call"}}},
+			},
+		}
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			Name:        "Outage",
+			OwnerUserID: "testUserID",
+			TeamID:      teamID,
+			ChannelID:   "channelID",
+			PlaybookID:  testPlaybook.ID,
+			Checklists: []app.Checklist{
+				{ID: "checklistID", Title: "Triage", Items: []app.ChecklistItem{
+					{ID: "item1", Title: "Page on-call", State: app.ChecklistItemStateClosed},
+					{ID: "item2", Title: "Check dashboards"},
+				}},
+			},
+		}
+		changes := app.DiffPlaybookChecklists(testPlaybook.Checklists, testPlaybookRun.Checklists)
+		require.Len(t, changes, 1)
+
+		setExpectations := func(t *testing.T) {
+			t.Helper()
+
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+		}
+
+		t.Run("list the changes", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			setExpectations(t)
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil)
+
+			result, err := c.PlaybookRuns.GetPlaybookChanges(context.TODO(), testPlaybookRun.ID)
+			require.NoError(t, err)
+			require.Len(t, result, 1)
+			assert.Equal(t, changes[0].ID, result[0].ID)
+			assert.Equal(t, icClient.PlaybookChangeAddItem, result[0].Type)
+			assert.Equal(t, "Check dashboards", result[0].Item.Title)
+		})
+
+		t.Run("accept the changes", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			setExpectations(t)
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil).Times(2)
+
+			expected := testPlaybook
+			expected.Checklists = []app.Checklist{
+				{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page on-call"}, {Title: "Check dashboards"}}},
+			}
+			playbookService.EXPECT().Update(expected, "testUserID").Return(nil)
+
+			err := c.PlaybookRuns.AcceptPlaybookChanges(context.TODO(), testPlaybookRun.ID, []string{changes[0].ID})
+			require.NoError(t, err)
+		})
+
+		t.Run("accept a change running a command as another user", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+			foreignRun := testPlaybookRun
+			foreignRun.Checklists = []app.Checklist{testPlaybookRun.Checklists[0].Clone()}
+			foreignRun.Checklists[0].Items[1].Command = "/deploy rollback"
+			foreignRun.Checklists[0].Items[1].AutoRun = app.ChecklistItemAutoRunOnRunStart
+			foreignRun.Checklists[0].Items[1].AutoRunUserID = "otherUserID"
+			foreignChanges := app.DiffPlaybookChecklists(testPlaybook.Checklists, foreignRun.Checklists)
+			require.Len(t, foreignChanges, 1)
+
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&foreignRun, nil).Times(2)
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil).Times(2)
+
+			err := c.PlaybookRuns.AcceptPlaybookChanges(context.TODO(), testPlaybookRun.ID, []string{foreignChanges[0].ID})
+			requireErrorWithStatusCode(t, err, http.StatusForbidden)
+		})
+
+		t.Run("accept a stale change", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			setExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil)
+
+			err := c.PlaybookRuns.AcceptPlaybookChanges(context.TODO(), testPlaybookRun.ID, []string{"0123456789ab"})
+			requireErrorWithStatusCode(t, err, http.StatusConflict)
+		})
+
+		t.Run("accept the changes as a viewer", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			setExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+			viewerPlaybook := testPlaybook
+			viewerPlaybook.Members = []app.PlaybookMember{{UserID: "testUserID", Role: app.PlaybookRoleViewer}}
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(viewerPlaybook, nil).Times(2)
+			pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionManageTeam).Return(false)
+
+			err := c.PlaybookRuns.AcceptPlaybookChanges(context.TODO(), testPlaybookRun.ID, []string{changes[0].ID})
+			requireErrorWithStatusCode(t, err, http.StatusForbidden)
+		})
+
+		t.Run("list the changes of a run without playbook", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+			withoutPlaybook := testPlaybookRun
+			withoutPlaybook.PlaybookID = ""
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&withoutPlaybook, nil).Times(2)
+
+			_, err := c.PlaybookRuns.GetPlaybookChanges(context.TODO(), testPlaybookRun.ID)
+			requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		})
+
+		t.Run("save as playbook", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			setExpectations(t)
+			pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+			configService.EXPECT().GetConfiguration().AnyTimes().Return(&config.Configuration{})
+			configService.EXPECT().IsAtLeastE20Licensed().AnyTimes().Return(true)
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil)
+			playbookService.EXPECT().
+				Create(app.PlaybookFromRun("testUserID", &testPlaybookRun, &testPlaybook, "My Playbook v2", false), "testUserID").
+				Return("newPlaybookID", nil)
+
+			playbook, err := c.PlaybookRuns.SaveAsPlaybook(context.TODO(), testPlaybookRun.ID, icClient.SaveAsPlaybookOptions{Title: "My Playbook v2"})
+			require.NoError(t, err)
+			assert.Equal(t, "newPlaybookID", playbook.ID)
+		})
+
+		t.Run("save as playbook in a team the user is not in", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			pluginAPI.On("HasPermissionToTeam", "testUserID", teamID, model.PermissionViewTeam).Return(false)
+			pluginAPI.On("GetUser", "testUserID").Return(&model.User{}, nil)
+			configService.EXPECT().GetConfiguration().AnyTimes().Return(&config.Configuration{})
+			configService.EXPECT().IsAtLeastE20Licensed().AnyTimes().Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil).Times(2)
+			playbookService.EXPECT().Get(testPlaybook.ID).Return(testPlaybook, nil)
+
+			_, err := c.PlaybookRuns.SaveAsPlaybook(context.TODO(), testPlaybookRun.ID, icClient.SaveAsPlaybookOptions{})
+			requireErrorWithStatusCode(t, err, http.StatusForbidden)
+		})
+	})
+
+	t.Run("checklist variables", func(t *testing.T) {
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		t.Run("preview the command of an item", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+
+			preview := app.ChecklistItemCommandPreview{
+				Command:          `/jira create --title "{{run.name}}" {{custom.project}}`,
+				ResolvedCommand:  `/jira create --title "playbookRunName" {{custom.project}}`,
+				UnknownVariables: []string{"custom.project"},
+			}
+			playbookRunService.EXPECT().PreviewChecklistItemSlashCommand(testPlaybookRun.ID, "testUserID", 0, 1).Return(preview, nil)
+
+			actual, err := c.PlaybookRuns.PreviewItemCommand(context.TODO(), testPlaybookRun.ID, 0, 1)
+			require.NoError(t, err)
+			require.Equal(t, preview.ResolvedCommand, actual.ResolvedCommand)
+			require.Equal(t, []string{"custom.project"}, actual.UnknownVariables)
+		})
+
+		t.Run("update the custom data", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().UpdateCustomData(testPlaybookRun.ID, "testUserID", map[string]string{"project": "OPS"}).Return(nil)
+
+			err := c.PlaybookRuns.UpdateCustomData(context.TODO(), testPlaybookRun.ID, map[string]string{"project": "OPS"})
+			require.NoError(t, err)
+		})
+
+		t.Run("update the custom data with an invalid key", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().
+				UpdateCustomData(testPlaybookRun.ID, "testUserID", map[string]string{"project key": "OPS"}).
+				Return(app.ValidateCustomData(map[string]string{"project key": "OPS"}))
+
+			err := c.PlaybookRuns.UpdateCustomData(context.TODO(), testPlaybookRun.ID, map[string]string{"project key": "OPS"})
+			requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		})
+
+		t.Run("update the custom data without the permission to edit the checklists", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			pluginAPI.On("HasPermissionToChannel", "testUserID", "channelID", model.PermissionReadChannel).Return(true)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().
+				UpdateCustomData(testPlaybookRun.ID, "testUserID", map[string]string{"project": "OPS"}).
+				Return(errors.Wrap(app.ErrNoPermissions, "update the custom data"))
+
+			err := c.PlaybookRuns.UpdateCustomData(context.TODO(), testPlaybookRun.ID, map[string]string{"project": "OPS"})
+			requireErrorWithStatusCode(t, err, http.StatusForbidden)
+		})
+
+		t.Run("create a run with invalid custom data", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+			_, err := c.PlaybookRuns.Create(context.TODO(), icClient.PlaybookRunCreateOptions{
+				Name:        "playbookRunName",
+				OwnerUserID: "testUserID",
+				TeamID:      testPlaybookRun.TeamID,
+				CustomData:  map[string]string{"project.key": "OPS"},
+			})
+			requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		})
+	})
+
+	t.Run("HTTP action of an item", func(t *testing.T) {
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		t.Run("run the action", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+
+			result := app.ChecklistItemHTTPActionResult{StatusCode: http.StatusAccepted, Response: "deploying"}
+			playbookRunService.EXPECT().RunChecklistItemHTTPAction(testPlaybookRun.ID, "testUserID", 0, 1, true).Return(result, nil)
+
+			actual, err := c.PlaybookRuns.RunItemHTTPAction(context.TODO(), testPlaybookRun.ID, 0, 1, true)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusAccepted, actual.StatusCode)
+			require.Equal(t, "deploying", actual.Response)
+		})
+
+		t.Run("run an action that requires confirmation without confirming", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().
+				RunChecklistItemHTTPAction(testPlaybookRun.ID, "testUserID", 0, 1, false).
+				Return(app.ChecklistItemHTTPActionResult{}, app.ErrConfirmationRequired)
+
+			_, err := c.PlaybookRuns.RunItemHTTPAction(context.TODO(), testPlaybookRun.ID, 0, 1, false)
+			requireErrorWithStatusCode(t, err, http.StatusConflict)
+		})
+	})
+
+	t.Run("remove a required item as a non-admin", func(t *testing.T) {
+		reset(t)
+		setDefaultExpectations(t)
+
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+		pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+		playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+		playbookRunService.EXPECT().
+			RemoveChecklistItem(testPlaybookRun.ID, "testUserID", 0, 1).
+			Return(errors.Wrap(app.ErrNoPermissions, "only system admins can remove a required item"))
+
+		testrecorder := httptest.NewRecorder()
+		testreq, err := http.NewRequest(http.MethodDelete, "/api/v0/runs/"+testPlaybookRun.ID+"/checklists/0/item/1", nil)
+		require.NoError(t, err)
+		testreq.Header.Add("Mattermost-User-ID", "testUserID")
+		handler.ServeHTTP(testrecorder, testreq)
+
+		resp := testrecorder.Result()
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("change the run status", func(t *testing.T) {
+		testPlaybookRun := app.PlaybookRun{
+			ID:          "playbookRunID",
+			OwnerUserID: "testUserID",
+			TeamID:      model.NewId(),
+			Name:        "playbookRunName",
+			ChannelID:   "channelID",
+		}
+
+		t.Run("to an allowed status", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().ChangeRunStatus(testPlaybookRun.ID, "testUserID", "Mitigated").Return(nil)
+
+			err := c.PlaybookRuns.ChangeRunStatus(context.TODO(), testPlaybookRun.ID, "Mitigated")
+			require.NoError(t, err)
+		})
+
+		t.Run("to a status it can't move to", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().ChangeRunStatus(testPlaybookRun.ID, "testUserID", "Resolved").Return(app.ErrMalformedPlaybookRun)
+
+			err := c.PlaybookRuns.ChangeRunStatus(context.TODO(), testPlaybookRun.ID, "Resolved")
+			requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+		})
+
+		t.Run("to a finished status without the permission to finish the run", func(t *testing.T) {
+			reset(t)
+			setDefaultExpectations(t)
+			logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+			pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+			playbookRunService.EXPECT().GetPlaybookRun(testPlaybookRun.ID).Return(&testPlaybookRun, nil)
+			playbookRunService.EXPECT().ChangeRunStatus(testPlaybookRun.ID, "testUserID", "Resolved").Return(errors.Wrap(app.ErrNoPermissions, "finish"))
+
+			err := c.PlaybookRuns.ChangeRunStatus(context.TODO(), testPlaybookRun.ID, "Resolved")
+			requireErrorWithStatusCode(t, err, http.StatusForbidden)
+		})
+	})
+
+	t.Run("approval button", func(t *testing.T) {
+		approve := func(t *testing.T) model.PostActionIntegrationResponse {
+			request := model.PostActionIntegrationRequest{
+				UserId:  "testUserID",
+				PostId:  "postID",
+				Context: map[string]interface{}{"item_id": "itemID", "action": "approve"},
+			}
+			requestBytes, _ := json.Marshal(request)
+			testrecorder := httptest.NewRecorder()
+			testreq, err := http.NewRequest("POST", "/api/v0/runs/playbookRunID/approval-button", bytes.NewBuffer(requestBytes))
+			require.NoError(t, err)
+			testreq.Header.Add("Mattermost-User-ID", "testUserID")
+			handler.ServeHTTP(testrecorder, testreq)
+
+			resp := testrecorder.Result()
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var actionResponse model.PostActionIntegrationResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&actionResponse))
+			return actionResponse
+		}
+
+		t.Run("approve", func(t *testing.T) {
+			reset(t)
+			playbookRunService.EXPECT().DecideChecklistItemApproval("playbookRunID", "testUserID", "itemID", true).Return(nil)
+			post := &model.Post{Id: "postID", Message: "@worker asks you to approve **Run the migration**."}
+			post.AddProp("attachments", []*model.SlackAttachment{{}})
+			pluginAPI.On("GetPost", "postID").Return(post, nil)
+
+			actionResponse := approve(t)
+			require.NotNil(t, actionResponse.Update)
+			require.Equal(t, "@worker asks you to approve **Run the migration**.\n\nYou approved this item.", actionResponse.Update.Message)
+			require.Nil(t, actionResponse.Update.GetProp("attachments"))
+		})
+
+		t.Run("approve as someone else than the approvers", func(t *testing.T) {
+			reset(t)
+			playbookRunService.EXPECT().DecideChecklistItemApproval("playbookRunID", "testUserID", "itemID", true).Return(app.ErrNoPermissions)
+
+			actionResponse := approve(t)
+			require.Nil(t, actionResponse.Update)
+			require.Equal(t, "You can't approve this item.", actionResponse.EphemeralText)
+		})
+	})
 }
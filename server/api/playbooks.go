@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/mattermost/mattermost-plugin-playbooks/client"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
@@ -47,11 +48,16 @@ func NewPlaybookHandler(router *mux.Router, playbookService app.PlaybookService,
 	playbooksRouter.HandleFunc("", handler.getPlaybooks).Methods(http.MethodGet)
 	playbooksRouter.HandleFunc("/autocomplete", handler.getPlaybooksAutoComplete).Methods(http.MethodGet)
 	playbooksRouter.HandleFunc("/count", handler.getPlaybookCount).Methods(http.MethodGet)
+	playbooksRouter.HandleFunc("/import-markdown", handler.importMarkdownPlaybook).Methods(http.MethodPost)
+	playbooksRouter.HandleFunc("/validate", handler.validatePlaybook).Methods(http.MethodPost)
 
 	playbookRouter := playbooksRouter.PathPrefix("/{id:[A-Za-z0-9]+}").Subrouter()
 	playbookRouter.HandleFunc("", handler.getPlaybook).Methods(http.MethodGet)
 	playbookRouter.HandleFunc("", handler.updatePlaybook).Methods(http.MethodPut)
 	playbookRouter.HandleFunc("", handler.deletePlaybook).Methods(http.MethodDelete)
+	playbookRouter.HandleFunc("/restore", handler.restorePlaybook).Methods(http.MethodPost)
+	playbookRouter.HandleFunc("/purge", handler.purgePlaybook).Methods(http.MethodDelete)
+	playbookRouter.HandleFunc("/duplicate", handler.duplicatePlaybook).Methods(http.MethodPost)
 
 	return handler
 }
@@ -70,53 +76,27 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := app.CreatePlaybook(userID, playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
-		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+	playbook.NormalizeMembers()
+	playbook.DefaultAdmin(userID)
+	playbook.NormalizeSharedTeams()
+	if err := playbook.ValidateMembers(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid playbook members", err)
 		return
 	}
 
-	if playbook.WebhookOnCreationEnabled {
-		if len(playbook.WebhookOnCreationURLs) > 64 {
-			msg := "too many registered creation webhook urls, limit to less than 64"
-			h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-			return
-		}
-
-		for _, webhook := range playbook.WebhookOnCreationURLs {
-			url, err := url.ParseRequestURI(webhook)
-			if err != nil {
-				h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid creation webhook URL", err)
-				return
-			}
-
-			if url.Scheme != "http" && url.Scheme != "https" {
-				msg := fmt.Sprintf("protocol in creation webhook URL is %s; only HTTP and HTTPS are accepted", url.Scheme)
-				h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-				return
-			}
-		}
+	if err := playbook.RunPermissions.Validate(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid run permissions", err)
+		return
 	}
 
-	if playbook.WebhookOnStatusUpdateEnabled {
-		if len(playbook.WebhookOnStatusUpdateURLs) > 64 {
-			msg := "too many registered update webhook urls, limit to less than 64"
-			h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-			return
-		}
-
-		for _, webhook := range playbook.WebhookOnStatusUpdateURLs {
-			url, err := url.ParseRequestURI(webhook)
-			if err != nil {
-				h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid update webhook URL", err)
-				return
-			}
+	if err := app.CreatePlaybook(userID, playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
 
-			if url.Scheme != "http" && url.Scheme != "https" {
-				msg := fmt.Sprintf("protocol in update webhook URL is %s; only HTTP and HTTPS are accepted", url.Scheme)
-				h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-				return
-			}
-		}
+	if err := checkAutoRunUsers(userID, playbook.Checklists, nil); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
 	}
 
 	if playbook.CategorizeChannelEnabled {
@@ -131,7 +111,12 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 	}
 
 	id, err := h.playbookService.Create(playbook, userID)
-	if err != nil {
+	if h.rejectInvalidPlaybook(w, err) {
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to create playbook", err)
+		return
+	} else if err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -141,10 +126,53 @@ func (h *PlaybookHandler) createPlaybook(w http.ResponseWriter, r *http.Request)
 	}{
 		ID: id,
 	}
-	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", playbook.ID))
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
 	ReturnJSON(w, &result, http.StatusCreated)
 }
 
+// validatePlaybook handles the POST /playbooks/validate endpoint, listing the issues with the
+// content of a playbook and whether they would prevent saving it.
+func (h *PlaybookHandler) validatePlaybook(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var playbook app.Playbook
+	if err := json.NewDecoder(r.Body).Decode(&playbook); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode playbook", err)
+		return
+	}
+
+	if playbook.TeamID == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "team_id is required", nil)
+		return
+	}
+
+	if !app.CanViewTeam(userID, playbook.TeamID, h.pluginAPI) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf(
+			"userID %s does not have permission to validate playbooks on teamID %s",
+			userID,
+			playbook.TeamID,
+		))
+		return
+	}
+
+	cfg := h.config.GetConfiguration()
+	issues := app.LintPlaybook(playbook, cfg.BotUserID, h.pluginAPI)
+	if issues == nil {
+		issues = []app.PlaybookLintIssue{}
+	}
+
+	result := struct {
+		Valid  bool                    `json:"valid"`
+		Strict bool                    `json:"strict"`
+		Issues []app.PlaybookLintIssue `json:"issues"`
+	}{
+		Valid:  !app.PlaybookLintBlocks(issues, cfg.StrictPlaybookValidation),
+		Strict: cfg.StrictPlaybookValidation,
+		Issues: issues,
+	}
+	ReturnJSON(w, &result, http.StatusOK)
+}
+
 func (h *PlaybookHandler) getPlaybook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	playbookID := vars["id"]
@@ -175,12 +203,26 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 
 	// Force parsed playbook id to be URL parameter id
 	playbook.ID = vars["id"]
+
 	oldPlaybook, err := h.playbookService.Get(playbook.ID)
 	if err != nil {
 		h.HandleError(w, err)
 		return
 	}
 
+	playbook.KeepMemberRoles(oldPlaybook)
+	playbook.NormalizeMembers()
+	playbook.NormalizeSharedTeams()
+	if err = playbook.ValidateMemberChanges(oldPlaybook); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid playbook members", err)
+		return
+	}
+
+	if err = playbook.RunPermissions.Validate(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid run permissions", err)
+		return
+	}
+
 	if err = app.PlaybookModify(userID, playbook, oldPlaybook, h.config, h.pluginAPI, h.playbookService); err != nil {
 		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
 		return
@@ -191,38 +233,9 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if playbook.WebhookOnCreationEnabled {
-		for _, webhook := range playbook.WebhookOnCreationURLs {
-			var parsedURL *url.URL
-			parsedURL, err = url.ParseRequestURI(webhook)
-			if err != nil {
-				h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid creation webhook URL", err)
-				return
-			}
-
-			if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-				msg := fmt.Sprintf("protocol in creation webhook URL is %s; only HTTP and HTTPS are accepted", parsedURL.Scheme)
-				h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-				return
-			}
-		}
-	}
-
-	if playbook.WebhookOnStatusUpdateEnabled {
-		for _, webhook := range playbook.WebhookOnStatusUpdateURLs {
-			var parsedURL *url.URL
-			parsedURL, err = url.ParseRequestURI(webhook)
-			if err != nil {
-				h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid update webhook URL", err)
-				return
-			}
-
-			if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-				msg := fmt.Sprintf("protocol in update webhook URL is %s; only HTTP and HTTPS are accepted", parsedURL.Scheme)
-				h.HandleErrorWithCode(w, http.StatusBadRequest, msg, errors.Errorf(msg))
-				return
-			}
-		}
+	if err = checkAutoRunUsers(userID, playbook.Checklists, oldPlaybook.Checklists); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
 	}
 
 	if playbook.CategorizeChannelEnabled {
@@ -237,7 +250,12 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 	}
 
 	err = h.playbookService.Update(playbook, userID)
-	if err != nil {
+	if h.rejectInvalidPlaybook(w, err) {
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to update playbook", err)
+		return
+	} else if err != nil {
 		h.HandleError(w, err)
 		return
 	}
@@ -245,6 +263,34 @@ func (h *PlaybookHandler) updatePlaybook(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusOK)
 }
 
+// checkAutoRunUsers returns an error if the checklists run a command automatically as a user
+// other than userID, unless the previous checklists already ran that same command as that user.
+// The commands run with the permissions of the chosen user, so people can only choose themselves
+// or the bot, and changing a command someone else chose to run hands it over to the editor.
+func checkAutoRunUsers(userID string, checklists, previousChecklists []app.Checklist) error {
+	type autoRun struct{ userID, command string }
+
+	allowed := map[autoRun]bool{}
+	for _, checklist := range previousChecklists {
+		for _, item := range checklist.Items {
+			allowed[autoRun{item.AutoRunUserID, item.Command}] = true
+		}
+	}
+
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			if item.AutoRunUserID == "" || item.AutoRunUserID == userID {
+				continue
+			}
+			if !allowed[autoRun{item.AutoRunUserID, item.Command}] {
+				return errors.Errorf("userID %s can't choose userID %s to run the command of %q automatically", userID, item.AutoRunUserID, item.Title)
+			}
+		}
+	}
+
+	return nil
+}
+
 // doPlaybookModificationChecks performs permissions checks that can be resolved though modification of the input.
 // This function modifies the playbook argument.
 func doPlaybookModificationChecks(playbook *app.Playbook, userID string, pluginAPI *pluginapi.Client) error {
@@ -290,7 +336,7 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 	playbookID := vars["id"]
 	userID := r.Header.Get("Mattermost-User-ID")
 
-	if err := app.PlaybookAccess(userID, playbookID, h.playbookService, h.pluginAPI); err != nil {
+	if err := app.PlaybookRoleAccess(userID, playbookID, app.PlaybookRoleAdmin, h.playbookService, h.pluginAPI); err != nil {
 		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
 		return
 	}
@@ -310,6 +356,173 @@ func (h *PlaybookHandler) deletePlaybook(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *PlaybookHandler) restorePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playbookID := vars["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if err := app.PlaybookRoleAccess(userID, playbookID, app.PlaybookRoleAdmin, h.playbookService, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	playbookToRestore, err := h.playbookService.Get(playbookID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if playbookToRestore.DeleteAt == 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "playbook is not archived", nil)
+		return
+	}
+
+	err = h.playbookService.Restore(playbookToRestore, userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PlaybookHandler) purgePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playbookID := vars["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	if !app.IsAdmin(userID, h.pluginAPI) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.New("only admins can purge playbooks"))
+		return
+	}
+
+	playbookToPurge, err := h.playbookService.Get(playbookID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	if playbookToPurge.DeleteAt == 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "only archived playbooks can be purged", nil)
+		return
+	}
+
+	err = h.playbookService.Purge(playbookToPurge, userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *PlaybookHandler) duplicatePlaybook(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	playbookID := vars["id"]
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var options client.PlaybookDuplicateOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode duplicate options", err)
+		return
+	}
+
+	if err := app.PlaybookRoleAccess(userID, playbookID, app.PlaybookRoleViewer, h.playbookService, h.pluginAPI); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	playbook, err := h.playbookService.Get(playbookID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	duplicate := playbook.Duplicate(userID, options.TeamID, strings.TrimSpace(options.Title))
+	if err = app.CreatePlaybook(userID, duplicate, h.config, h.pluginAPI, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	id, err := h.playbookService.Create(duplicate, userID)
+	if h.rejectInvalidPlaybook(w, err) {
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	result := struct {
+		ID string `json:"id"`
+	}{
+		ID: id,
+	}
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
+// importMarkdownPlaybook handles the POST /playbooks/import-markdown endpoint, creating a
+// playbook from the checklists of a Markdown document and reporting what couldn't be imported.
+func (h *PlaybookHandler) importMarkdownPlaybook(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var options client.PlaybookImportMarkdownOptions
+	if err := json.NewDecoder(r.Body).Decode(&options); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode import options", err)
+		return
+	}
+
+	if options.TeamID == "" {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "team_id is required", nil)
+		return
+	}
+
+	playbook, issues := app.PlaybookFromMarkdown(userID, options.TeamID, strings.TrimSpace(options.Title), options.Markdown, options.Restricted)
+	if len(playbook.Checklists) == 0 {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "the document has no tasks", nil)
+		return
+	}
+	if issues == nil {
+		issues = []app.MarkdownImportIssue{}
+	}
+
+	result := struct {
+		ID         string                    `json:"id"`
+		Title      string                    `json:"title"`
+		Checklists []app.Checklist           `json:"checklists"`
+		Issues     []app.MarkdownImportIssue `json:"issues"`
+	}{
+		Title:      playbook.Title,
+		Checklists: playbook.Checklists,
+		Issues:     issues,
+	}
+
+	if options.DryRun {
+		ReturnJSON(w, &result, http.StatusOK)
+		return
+	}
+
+	if err := app.CreatePlaybook(userID, playbook, h.config, h.pluginAPI, h.playbookService); err != nil {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err)
+		return
+	}
+
+	id, err := h.playbookService.Create(playbook, userID)
+	if h.rejectInvalidPlaybook(w, err) {
+		return
+	} else if errors.Is(err, app.ErrMalformedPlaybook) {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to create playbook", err)
+		return
+	} else if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+	result.ID = id
+
+	w.Header().Add("Location", fmt.Sprintf("/api/v0/playbooks/%s", id))
+	ReturnJSON(w, &result, http.StatusCreated)
+}
+
 func (h *PlaybookHandler) getPlaybooks(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	teamID := params.Get("team_id")
@@ -470,12 +683,25 @@ func parseGetPlaybooksOptions(u *url.URL) (app.PlaybookFilterOptions, error) {
 		return app.PlaybookFilterOptions{}, errors.Errorf("bad parameter 'per_page': it should be a positive number")
 	}
 
+	skipCount, err := parseBoolParam(u, "skip_count")
+	if err != nil {
+		return app.PlaybookFilterOptions{}, err
+	}
+
+	withArchived, err := parseBoolParam(u, "with_archived")
+	if err != nil {
+		return app.PlaybookFilterOptions{}, err
+	}
+
 	return app.PlaybookFilterOptions{
-		Sort:      sortField,
-		Direction: sortDirection,
-		Page:      page,
-		PerPage:   perPage,
-	}, nil
+		Sort:         sortField,
+		Direction:    sortDirection,
+		Page:         page,
+		PerPage:      perPage,
+		Cursor:       params.Get("cursor"),
+		SkipCount:    skipCount,
+		WithArchived: withArchived,
+	}.Validate()
 }
 
 func removeDuplicates(a []string) []string {
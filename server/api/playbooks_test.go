@@ -48,6 +48,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
+		Members:             []app.PlaybookMember{},
+		MemberGroups:        []app.PlaybookMemberGroup{},
 		MemberIDs:           []string{},
 		InvitedUserIDs:      []string{},
 		InvitedGroupIDs:     []string{},
@@ -67,6 +69,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
+		Members:             []app.PlaybookMember{},
+		MemberGroups:        []app.PlaybookMemberGroup{},
 		MemberIDs:           []string{},
 		InvitedUserIDs:      []string{},
 		InvitedGroupIDs:     []string{},
@@ -87,6 +91,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
+		Members:             []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+		MemberGroups:        []app.PlaybookMemberGroup{},
 		MemberIDs:           []string{"testuserid"},
 		InvitedUserIDs:      []string{},
 		InvitedGroupIDs:     []string{},
@@ -106,6 +112,8 @@ func TestPlaybooks(t *testing.T) {
 				},
 			},
 		},
+		Members:             []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+		MemberGroups:        []app.PlaybookMemberGroup{},
 		MemberIDs:           []string{"testuserid"},
 		BroadcastChannelIDs: []string{"nonemptychannelid"},
 		InvitedUserIDs:      []string{},
@@ -206,7 +214,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:           playbooktest.Title,
 			TeamID:          playbooktest.TeamID,
 			Checklists:      toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:       playbooktest.MemberIDs,
+			Members:         toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs:  playbooktest.InvitedUserIDs,
 			InvitedGroupIDs: playbooktest.InvitedGroupIDs,
 		})
@@ -258,7 +266,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               playbooktest.Title,
 			TeamID:              playbooktest.TeamID,
 			Checklists:          toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:           playbooktest.MemberIDs,
+			Members:             toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs:      playbooktest.InvitedUserIDs,
 			InvitedGroupIDs:     playbooktest.InvitedGroupIDs,
 			BroadcastChannelIDs: playbooktest.BroadcastChannelIDs,
@@ -315,7 +323,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               playbooktest.Title,
 			TeamID:              playbooktest.TeamID,
 			Checklists:          toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:           playbooktest.MemberIDs,
+			Members:             toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs:      playbooktest.InvitedUserIDs,
 			InvitedGroupIDs:     playbooktest.InvitedGroupIDs,
 			BroadcastChannelIDs: playbooktest.BroadcastChannelIDs,
@@ -372,7 +380,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               playbooktest.Title,
 			TeamID:              playbooktest.TeamID,
 			Checklists:          toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:           playbooktest.MemberIDs,
+			Members:             toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs:      playbooktest.InvitedUserIDs,
 			InvitedGroupIDs:     playbooktest.InvitedGroupIDs,
 			BroadcastChannelIDs: playbooktest.BroadcastChannelIDs,
@@ -424,7 +432,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               playbooktest.Title,
 			TeamID:              playbooktest.TeamID,
 			Checklists:          toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:           playbooktest.MemberIDs,
+			Members:             toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs:      playbooktest.InvitedUserIDs,
 			InvitedGroupIDs:     playbooktest.InvitedGroupIDs,
 			BroadcastChannelIDs: playbooktest.BroadcastChannelIDs,
@@ -433,6 +441,52 @@ func TestPlaybooks(t *testing.T) {
 		assert.NotEmpty(t, resultPlaybook.ID)
 	})
 
+	t.Run("create playbook referencing an unknown library checklist", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().GetNumPlaybooksForTeam(playbooktest.TeamID).Return(0, nil)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		playbookService.EXPECT().
+			Create(gomock.Any(), "testuserid").
+			Return("", errors.Wrap(app.ErrMalformedPlaybook, "unknown library checklist"))
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		checklists := toAPIChecklists(playbooktest.Checklists)
+		checklists[0].LibraryID = "unknown"
+		_, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:      playbooktest.Title,
+			TeamID:     playbooktest.TeamID,
+			Checklists: checklists,
+			Members:    toAPIPlaybookMembers(playbooktest.Members),
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("create playbook running a command automatically as another user", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().GetNumPlaybooksForTeam(playbooktest.TeamID).Return(0, nil)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		checklists := toAPIChecklists(playbooktest.Checklists)
+		checklists[0].Items[0].Command = "/echo hi"
+		checklists[0].Items[0].AutoRun = icClient.ChecklistItemAutoRunOnRunStart
+		checklists[0].Items[0].AutoRunUserID = "otheruserid"
+		_, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:      playbooktest.Title,
+			TeamID:     playbooktest.TeamID,
+			Checklists: checklists,
+			Members:    toAPIPlaybookMembers(playbooktest.Members),
+		})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
 	t.Run("create playbook, unlicensed, with playbook members", func(t *testing.T) {
 		mockCtrl = gomock.NewController(t)
 		configService = mock_config.NewMockService(mockCtrl)
@@ -483,7 +537,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               withMember.Title,
 			TeamID:              withMember.TeamID,
 			Checklists:          toAPIChecklists(withMember.Checklists),
-			MemberIDs:           withMember.MemberIDs,
+			Members:             toAPIPlaybookMembers(withMember.Members),
 			InvitedUserIDs:      withMember.InvitedUserIDs,
 			InvitedGroupIDs:     withMember.InvitedGroupIDs,
 			BroadcastChannelIDs: withMember.BroadcastChannelIDs,
@@ -542,7 +596,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               withMember.Title,
 			TeamID:              withMember.TeamID,
 			Checklists:          toAPIChecklists(withMember.Checklists),
-			MemberIDs:           withMember.MemberIDs,
+			Members:             toAPIPlaybookMembers(withMember.Members),
 			InvitedUserIDs:      withMember.InvitedUserIDs,
 			InvitedGroupIDs:     withMember.InvitedGroupIDs,
 			BroadcastChannelIDs: withMember.BroadcastChannelIDs,
@@ -571,7 +625,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:               playbooktest.Title,
 			TeamID:              playbooktest.TeamID,
 			Checklists:          toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:           playbooktest.MemberIDs,
+			Members:             toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs:      playbooktest.InvitedUserIDs,
 			InvitedGroupIDs:     playbooktest.InvitedGroupIDs,
 			BroadcastChannelIDs: playbooktest.BroadcastChannelIDs,
@@ -580,6 +634,42 @@ func TestPlaybooks(t *testing.T) {
 		assert.NotEmpty(t, resultPlaybook.ID)
 	})
 
+	t.Run("create playbook with members but no admin makes the creator an admin", func(t *testing.T) {
+		reset(t)
+
+		withoutAdmin := withMember.Clone()
+		withoutAdmin.ID = ""
+		withoutAdmin.Members = []app.PlaybookMember{{UserID: "someone_else", Role: app.PlaybookRoleEditor}}
+		withoutAdmin.MemberIDs = []string{"someone_else"}
+
+		playbookService.EXPECT().
+			Create(gomock.Any(), "testuserid").
+			DoAndReturn(func(playbook app.Playbook, userID string) (string, error) {
+				require.Equal(t, []app.PlaybookMember{
+					{UserID: "someone_else", Role: app.PlaybookRoleEditor},
+					{UserID: "testuserid", Role: app.PlaybookRoleAdmin},
+				}, playbook.Members)
+				require.Equal(t, []string{"someone_else", "testuserid"}, playbook.MemberIDs)
+				return model.NewId(), nil
+			}).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		resultPlaybook, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:               withoutAdmin.Title,
+			TeamID:              withoutAdmin.TeamID,
+			Checklists:          toAPIChecklists(withoutAdmin.Checklists),
+			Members:             toAPIPlaybookMembers(withoutAdmin.Members),
+			InvitedUserIDs:      withoutAdmin.InvitedUserIDs,
+			InvitedGroupIDs:     withoutAdmin.InvitedGroupIDs,
+			BroadcastChannelIDs: withoutAdmin.BroadcastChannelIDs,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, resultPlaybook.ID)
+	})
+
 	t.Run("create playbook, as guest", func(t *testing.T) {
 		reset(t)
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
@@ -596,7 +686,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:          playbooktest.Title,
 			TeamID:         playbooktest.TeamID,
 			Checklists:     toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:      playbooktest.MemberIDs,
+			Members:        toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs: playbooktest.InvitedUserIDs,
 		})
 		requireErrorWithStatusCode(t, err, http.StatusForbidden)
@@ -652,6 +742,8 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
+			Members:            []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+			MemberGroups:       []app.PlaybookMemberGroup{},
 			MemberIDs:          []string{"testuserid"},
 			InviteUsersEnabled: true,
 			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
@@ -695,7 +787,7 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			}),
-			MemberIDs:          []string{"testuserid"},
+			Members:            []icClient.PlaybookMember{{UserID: "testuserid", Role: icClient.PlaybookRoleAdmin}},
 			InviteUsersEnabled: true,
 			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
 			InvitedGroupIDs:    []string{"testInvitedGroupID1", "testInvitedGroupID2"},
@@ -720,6 +812,8 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
+			Members:            []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+			MemberGroups:       []app.PlaybookMemberGroup{},
 			MemberIDs:          []string{"testuserid"},
 			InviteUsersEnabled: false,
 			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
@@ -763,7 +857,7 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			}),
-			MemberIDs:          []string{"testuserid"},
+			Members:            []icClient.PlaybookMember{{UserID: "testuserid", Role: icClient.PlaybookRoleAdmin}},
 			InviteUsersEnabled: false,
 			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
 			InvitedGroupIDs:    []string{"testInvitedGroupID1", "testInvitedGroupID2"},
@@ -789,6 +883,8 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
+			Members:            []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+			MemberGroups:       []app.PlaybookMemberGroup{},
 			MemberIDs:          []string{"testuserid"},
 			InviteUsersEnabled: true,
 			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
@@ -827,7 +923,7 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			}),
-			MemberIDs:          []string{"testuserid"},
+			Members:            []icClient.PlaybookMember{{UserID: "testuserid", Role: icClient.PlaybookRoleAdmin}},
 			InviteUsersEnabled: true,
 			InvitedUserIDs:     []string{"testInvitedUserID1", "testInvitedUserID2"},
 			InvitedGroupIDs:    []string{"testInvitedGroupID1", "testInvitedGroupID2"},
@@ -839,6 +935,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("get playbook", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 
 		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
 
@@ -855,12 +952,7 @@ func TestPlaybooks(t *testing.T) {
 	t.Run("get playbooks", func(t *testing.T) {
 		reset(t)
 
-		playbookResult := struct {
-			TotalCount int            `json:"total_count"`
-			PageCount  int            `json:"page_count"`
-			HasMore    bool           `json:"has_more"`
-			Items      []app.Playbook `json:"items"`
-		}{
+		playbookResult := app.GetPlaybooksResults{
 			TotalCount: 2,
 			PageCount:  1,
 			HasMore:    false,
@@ -900,12 +992,7 @@ func TestPlaybooks(t *testing.T) {
 		reset(t)
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 
-		playbookResult := struct {
-			TotalCount int            `json:"total_count"`
-			PageCount  int            `json:"page_count"`
-			HasMore    bool           `json:"has_more"`
-			Items      []app.Playbook `json:"items"`
-		}{
+		playbookResult := app.GetPlaybooksResults{
 			TotalCount: 2,
 			PageCount:  1,
 			HasMore:    false,
@@ -937,12 +1024,7 @@ func TestPlaybooks(t *testing.T) {
 	t.Run("get playbooks, member only", func(t *testing.T) {
 		reset(t)
 
-		playbookResult := struct {
-			TotalCount int            `json:"total_count"`
-			PageCount  int            `json:"page_count"`
-			HasMore    bool           `json:"has_more"`
-			Items      []app.Playbook `json:"items"`
-		}{
+		playbookResult := app.GetPlaybooksResults{
 			TotalCount: 2,
 			PageCount:  1,
 			HasMore:    false,
@@ -980,6 +1062,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("update playbook", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 
 		playbookService.EXPECT().
 			Get("playbookwithmember").
@@ -1025,6 +1108,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("update playbook but no premissions in broadcast channel, but it already exists", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 
 		playbookService.EXPECT().
 			Get("testplaybookid").
@@ -1047,6 +1131,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("update playbook with invited users and groups", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 
 		playbook := app.Playbook{
 			ID:     "testplaybookid",
@@ -1062,6 +1147,8 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
+			Members:                   []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+			MemberGroups:              []app.PlaybookMemberGroup{},
 			MemberIDs:                 []string{"testuserid"},
 			BroadcastChannelIDs:       []string{},
 			InviteUsersEnabled:        true,
@@ -1122,6 +1209,8 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
+			Members:                   []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+			MemberGroups:              []app.PlaybookMemberGroup{},
 			MemberIDs:                 []string{"testuserid"},
 			BroadcastChannelIDs:       []string{},
 			InviteUsersEnabled:        false,
@@ -1184,6 +1273,8 @@ func TestPlaybooks(t *testing.T) {
 					},
 				},
 			},
+			Members:                   []app.PlaybookMember{{UserID: "testuserid", Role: app.PlaybookRoleAdmin}},
+			MemberGroups:              []app.PlaybookMemberGroup{},
 			MemberIDs:                 []string{"testuserid"},
 			BroadcastChannelIDs:       []string{},
 			InviteUsersEnabled:        false,
@@ -1259,6 +1350,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("delete playbook no team permission", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 
 		playbookService.EXPECT().
@@ -1283,7 +1375,7 @@ func TestPlaybooks(t *testing.T) {
 			Title:          playbooktest.Title,
 			TeamID:         playbooktest.TeamID,
 			Checklists:     toAPIChecklists(playbooktest.Checklists),
-			MemberIDs:      playbooktest.MemberIDs,
+			Members:        toAPIPlaybookMembers(playbooktest.Members),
 			InvitedUserIDs: playbooktest.InvitedUserIDs,
 		})
 		requireErrorWithStatusCode(t, err, http.StatusForbidden)
@@ -1292,6 +1384,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("get playbook no team permission", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 
 		playbookService.EXPECT().
@@ -1319,6 +1412,7 @@ func TestPlaybooks(t *testing.T) {
 
 	t.Run("update playbooks no team permission", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 
 		playbookService.EXPECT().
@@ -1456,19 +1550,276 @@ func TestPlaybooks(t *testing.T) {
 		requireErrorWithStatusCode(t, err, http.StatusForbidden)
 	})
 
-	t.Run("get playbooks with members", func(t *testing.T) {
+	t.Run("update playbook by viewer", func(t *testing.T) {
 		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 
-		playbookResult := struct {
-			TotalCount int            `json:"total_count"`
-			PageCount  int            `json:"page_count"`
-			HasMore    bool           `json:"has_more"`
-			Items      []app.Playbook `json:"items"`
-		}{
-			TotalCount: 1,
+		withViewer := withMember.Clone()
+		withViewer.Members[0].Role = app.PlaybookRoleViewer
+		withViewer.Members = append(withViewer.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		withViewer.MemberIDs = append(withViewer.MemberIDs, "playbookadmin")
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withViewer, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+
+		updatedPlaybook := withViewer.Clone()
+		updatedPlaybook.Title = "New Title"
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update playbook by editor", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		withEditor := withMember.Clone()
+		withEditor.Members[0].Role = app.PlaybookRoleEditor
+		withEditor.Members = append(withEditor.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		withEditor.MemberIDs = append(withEditor.MemberIDs, "playbookadmin")
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withEditor, nil).
+			Times(2)
+
+		updatedPlaybook := withEditor.Clone()
+		updatedPlaybook.Title = "New Title"
+		updatedPlaybook.NormalizeMembers()
+
+		playbookService.EXPECT().
+			Update(updatedPlaybook, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		require.NoError(t, err)
+	})
+
+	t.Run("update playbook members by editor", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		withEditor := withMember.Clone()
+		withEditor.Members[0].Role = app.PlaybookRoleEditor
+		withEditor.Members = append(withEditor.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		withEditor.MemberIDs = append(withEditor.MemberIDs, "playbookadmin")
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withEditor, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+
+		updatedPlaybook := withEditor.Clone()
+		updatedPlaybook.Members[0].Role = app.PlaybookRoleAdmin
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update playbook with an invalid member role", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		updatedPlaybook := withMember.Clone()
+		updatedPlaybook.Members[0].Role = "owner"
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("update playbook run permissions by editor", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		withEditor := withMember.Clone()
+		withEditor.Members[0].Role = app.PlaybookRoleEditor
+		withEditor.Members = append(withEditor.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		withEditor.MemberIDs = append(withEditor.MemberIDs, "playbookadmin")
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withEditor, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+
+		updatedPlaybook := withEditor.Clone()
+		updatedPlaybook.RunPermissions.Finish = app.RunPermissionRule{Allow: app.RunPermissionAllowOwner}
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update playbook with invalid run permissions", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		updatedPlaybook := withMember.Clone()
+		updatedPlaybook.RunPermissions.EditChecklists = app.RunPermissionRule{Allow: app.RunPermissionAllowRole, Role: "owner"}
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("update playbook to share it with another team", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		updatedPlaybook := withMember
+		updatedPlaybook.SharedTeamIDs = []string{"otherteamid"}
+
+		playbookService.EXPECT().
+			Update(updatedPlaybook, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "otherteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		require.NoError(t, err)
+	})
+
+	t.Run("update playbook to share it with a team the user is not in", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "otherteamid", model.PermissionViewTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		updatedPlaybook := withMember.Clone()
+		updatedPlaybook.SharedTeamIDs = []string{"otherteamid"}
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update playbook to share it with all teams by non-admin", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		updatedPlaybook := withMember.Clone()
+		updatedPlaybook.SharedWithAllTeams = true
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("delete playbook by editor", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		withEditor := withMember.Clone()
+		withEditor.Members[0].Role = app.PlaybookRoleEditor
+		withEditor.Members = append(withEditor.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		withEditor.MemberIDs = append(withEditor.MemberIDs, "playbookadmin")
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withEditor, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+
+		err := c.Playbooks.Delete(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("delete open playbook by non-admin", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(withid, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionManageTeam).Return(false)
+
+		err := c.Playbooks.Delete(context.TODO(), "testplaybookid")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("delete open playbook by team admin", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		playbookService.EXPECT().
+			Get("testplaybookid").
+			Return(withid, nil).
+			Times(2)
+
+		playbookService.EXPECT().
+			Delete(withid, "testuserid").
+			Return(nil).
+			Times(1)
+
+		poster.EXPECT().
+			PublishWebsocketEventToTeam("playbook_deleted", map[string]interface{}{
+				"teamID": playbooktest.TeamID,
+			}, playbooktest.TeamID)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionManageTeam).Return(true)
+
+		err := c.Playbooks.Delete(context.TODO(), "testplaybookid")
+		require.NoError(t, err)
+	})
+
+	t.Run("get playbooks with archived", func(t *testing.T) {
+		reset(t)
+
+		archived := playbooktest
+		archived.DeleteAt = 1234
+		playbookResult := app.GetPlaybooksResults{
+			TotalCount: 2,
 			PageCount:  1,
 			HasMore:    false,
-			Items:      []app.Playbook{withMember},
+			Items:      []app.Playbook{playbooktest, archived},
 		}
 
 		playbookService.EXPECT().
@@ -1476,16 +1827,410 @@ func TestPlaybooks(t *testing.T) {
 				app.RequesterInfo{
 					UserID:  "testuserid",
 					TeamID:  "testteamid",
-					IsAdmin: false,
+					IsAdmin: true,
 				},
 				"testteamid",
-				gomock.Any(),
+				app.PlaybookFilterOptions{
+					Sort:         app.SortByTitle,
+					Direction:    app.DirectionAsc,
+					PerPage:      100,
+					WithArchived: true,
+				},
 			).
 			Return(playbookResult, nil).
 			Times(1)
 
 		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
-		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		actualList, err := c.Playbooks.List(context.TODO(), "testteamid", 0, 100, icClient.PlaybookListOptions{WithArchived: true})
+		require.NoError(t, err)
+		require.Len(t, actualList.Items, 2)
+		assert.Equal(t, int64(1234), actualList.Items[1].DeleteAt)
+	})
+
+	t.Run("restore playbook", func(t *testing.T) {
+		reset(t)
+
+		archived := withMember
+		archived.DeleteAt = 1234
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(archived, nil).
+			Times(2)
+
+		playbookService.EXPECT().
+			Restore(archived, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		err := c.Playbooks.Restore(context.TODO(), "playbookwithmember")
+		require.NoError(t, err)
+	})
+
+	t.Run("restore playbook that is not archived", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		err := c.Playbooks.Restore(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("restore playbook by editor", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		archived := withMember.Clone()
+		archived.Members[0].Role = app.PlaybookRoleEditor
+		archived.Members = append(archived.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		archived.MemberIDs = append(archived.MemberIDs, "playbookadmin")
+		archived.DeleteAt = 1234
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(archived, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		err := c.Playbooks.Restore(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("purge playbook", func(t *testing.T) {
+		reset(t)
+
+		archived := withMember
+		archived.DeleteAt = 1234
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(archived, nil).
+			Times(1)
+
+		playbookService.EXPECT().
+			Purge(archived, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+
+		err := c.Playbooks.Purge(context.TODO(), "playbookwithmember")
+		require.NoError(t, err)
+	})
+
+	t.Run("purge playbook that is not archived", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(true)
+
+		err := c.Playbooks.Purge(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("purge playbook by playbook admin", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		err := c.Playbooks.Purge(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("duplicate playbook", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		playbookService.EXPECT().
+			Create(withMember.Duplicate("testuserid", "", ""), "testuserid").
+			Return("duplicateid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		duplicate, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", icClient.PlaybookDuplicateOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "duplicateid", duplicate.ID)
+	})
+
+	t.Run("duplicate playbook into another team", func(t *testing.T) {
+		reset(t)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		expected := withMember.Duplicate("testuserid", "otherteamid", "Other playbook")
+		require.Equal(t, "otherteamid", expected.TeamID)
+
+		playbookService.EXPECT().
+			Create(expected, "testuserid").
+			Return("duplicateid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "otherteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		duplicate, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", icClient.PlaybookDuplicateOptions{
+			TeamID: "otherteamid",
+			Title:  "Other playbook",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "duplicateid", duplicate.ID)
+	})
+
+	t.Run("duplicate playbook into a team the user is not in", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "otherteamid", model.PermissionViewTeam).Return(false)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		_, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", icClient.PlaybookDuplicateOptions{
+			TeamID: "otherteamid",
+		})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("duplicate playbook by non-member", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		withOtherMember := withMember.Clone()
+		withOtherMember.Members = []app.PlaybookMember{{UserID: "someone_else", Role: app.PlaybookRoleAdmin}}
+		withOtherMember.MemberIDs = []string{"someone_else"}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withOtherMember, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		_, err := c.Playbooks.Duplicate(context.TODO(), "playbookwithmember", icClient.PlaybookDuplicateOptions{})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("get playbook by member of a member group", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		withGroup := withMember.Clone()
+		withGroup.Members = []app.PlaybookMember{{UserID: "someone_else", Role: app.PlaybookRoleAdmin}}
+		withGroup.MemberIDs = []string{"someone_else"}
+		withGroup.MemberGroups = []app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeGroup, ID: "testgroupid", Role: app.PlaybookRoleViewer},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withGroup, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetGroupsForUser", "testuserid").Return([]*model.Group{{Id: "testgroupid"}}, nil)
+
+		result, err := c.Playbooks.Get(context.TODO(), "playbookwithmember")
+		require.NoError(t, err)
+		assert.Equal(t, toAPIPlaybook(withGroup), *result)
+	})
+
+	t.Run("get playbook by non-member of the member groups", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		withGroups := withMember.Clone()
+		withGroups.Members = []app.PlaybookMember{{UserID: "someone_else", Role: app.PlaybookRoleAdmin}}
+		withGroups.MemberIDs = []string{"someone_else"}
+		withGroups.MemberGroups = []app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeGroup, ID: "testgroupid", Role: app.PlaybookRoleViewer},
+			{Type: app.PlaybookMemberGroupTypeChannel, ID: "testchannelid", Role: app.PlaybookRoleViewer},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withGroups, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetGroupsForUser", "testuserid").Return([]*model.Group{{Id: "othergroupid"}}, nil)
+		pluginAPI.On("GetChannelMember", "testchannelid", "testuserid").Return(nil, model.NewAppError("GetChannelMember", "not_found", nil, "", http.StatusNotFound))
+
+		_, err := c.Playbooks.Get(context.TODO(), "playbookwithmember")
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("update playbook by editor through a member channel", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		withChannel := withMember.Clone()
+		withChannel.Members = []app.PlaybookMember{{UserID: "someone_else", Role: app.PlaybookRoleAdmin}}
+		withChannel.MemberIDs = []string{"someone_else"}
+		withChannel.MemberGroups = []app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeChannel, ID: "testchannelid", Role: app.PlaybookRoleEditor},
+		}
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withChannel, nil).
+			Times(2)
+
+		updatedPlaybook := withChannel.Clone()
+		updatedPlaybook.Title = "New Title"
+
+		playbookService.EXPECT().
+			Update(updatedPlaybook, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetChannelMember", "testchannelid", "testuserid").Return(&model.ChannelMember{}, nil)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		require.NoError(t, err)
+	})
+
+	t.Run("add a member group by editor", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		withEditor := withMember.Clone()
+		withEditor.Members[0].Role = app.PlaybookRoleEditor
+		withEditor.Members = append(withEditor.Members, app.PlaybookMember{UserID: "playbookadmin", Role: app.PlaybookRoleAdmin})
+		withEditor.MemberIDs = append(withEditor.MemberIDs, "playbookadmin")
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withEditor, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+
+		updatedPlaybook := withEditor.Clone()
+		updatedPlaybook.MemberGroups = []app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeGroup, ID: "testgroupid", Role: app.PlaybookRoleViewer},
+		}
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("add a member group and channel by admin", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		updatedPlaybook := withMember.Clone()
+		updatedPlaybook.MemberGroups = []app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeGroup, ID: "testgroupid", Role: app.PlaybookRoleRunner},
+			{Type: app.PlaybookMemberGroupTypeChannel, ID: "testchannelid", Role: app.PlaybookRoleViewer},
+		}
+
+		playbookService.EXPECT().
+			Update(updatedPlaybook, "testuserid").
+			Return(nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetGroup", "testgroupid").Return(&model.Group{Id: "testgroupid"}, nil)
+		pluginAPI.On("HasPermissionToChannel", "testuserid", "testchannelid", model.PermissionReadChannel).Return(true)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		require.NoError(t, err)
+	})
+
+	t.Run("add a member channel the user can't read", func(t *testing.T) {
+		reset(t)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		updatedPlaybook := withMember.Clone()
+		updatedPlaybook.MemberGroups = []app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeChannel, ID: "testchannelid", Role: app.PlaybookRoleViewer},
+		}
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToChannel", "testuserid", "testchannelid", model.PermissionReadChannel).Return(false)
+
+		err := c.Playbooks.Update(context.TODO(), toAPIPlaybook(updatedPlaybook))
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("get playbooks with members", func(t *testing.T) {
+		reset(t)
+
+		playbookResult := app.GetPlaybooksResults{
+			TotalCount: 1,
+			PageCount:  1,
+			HasMore:    false,
+			Items:      []app.Playbook{withMember},
+		}
+
+		playbookService.EXPECT().
+			GetPlaybooksForTeam(
+				app.RequesterInfo{
+					UserID:  "testuserid",
+					TeamID:  "testteamid",
+					IsAdmin: false,
+				},
+				"testteamid",
+				gomock.Any(),
+			).
+			Return(playbookResult, nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
 		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
 
 		actualList, err := c.Playbooks.List(context.TODO(), "testteamid", 0, 100, icClient.PlaybookListOptions{})
@@ -1499,6 +2244,195 @@ func TestPlaybooks(t *testing.T) {
 		}
 		assert.Equal(t, expectedList, actualList)
 	})
+
+	markdown := "# Outage\n" +
+		"## Triage\n" +
+		"- [ ] Page on-call `/pagerduty trigger`\n" +
+		"A stray paragraph\n"
+
+	t.Run("import markdown, dry run", func(t *testing.T) {
+		reset(t)
+
+		result, err := c.Playbooks.ImportMarkdown(context.TODO(), icClient.PlaybookImportMarkdownOptions{
+			TeamID:   "testteamid",
+			Markdown: markdown,
+			DryRun:   true,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, result.ID)
+		assert.Equal(t, "Outage", result.Title)
+		assert.Equal(t, []icClient.Checklist{
+			{Title: "Triage", Items: []icClient.ChecklistItem{{Title: "Page on-call", Command: "/pagerduty trigger"}}},
+		}, result.Checklists)
+		require.Len(t, result.Issues, 1)
+		assert.Equal(t, 4, result.Issues[0].Line)
+	})
+
+	t.Run("import markdown", func(t *testing.T) {
+		reset(t)
+
+		expected, _ := app.PlaybookFromMarkdown("testuserid", "testteamid", "Database outage", markdown, false)
+		playbookService.EXPECT().
+			Create(expected, "testuserid").
+			Return("importedid", nil).
+			Times(1)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		result, err := c.Playbooks.ImportMarkdown(context.TODO(), icClient.PlaybookImportMarkdownOptions{
+			TeamID:   "testteamid",
+			Title:    "Database outage",
+			Markdown: markdown,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "importedid", result.ID)
+		assert.Equal(t, "Database outage", result.Title)
+		assert.Len(t, result.Issues, 1)
+	})
+
+	t.Run("import markdown into a team the user is not in", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(false)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+
+		_, err := c.Playbooks.ImportMarkdown(context.TODO(), icClient.PlaybookImportMarkdownOptions{
+			TeamID:   "testteamid",
+			Markdown: markdown,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("import markdown without tasks", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.Playbooks.ImportMarkdown(context.TODO(), icClient.PlaybookImportMarkdownOptions{
+			TeamID:   "testteamid",
+			Markdown: "# Outage\nNothing to do.\n",
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("import markdown without team", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		_, err := c.Playbooks.ImportMarkdown(context.TODO(), icClient.PlaybookImportMarkdownOptions{
+			Markdown: markdown,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	resetWithConfiguration := func(t *testing.T, cfg *config.Configuration) {
+		t.Helper()
+
+		mockCtrl = gomock.NewController(t)
+		configService = mock_config.NewMockService(mockCtrl)
+		pluginAPI = &plugintest.API{}
+		client = pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		logger = mock_poster.NewMockLogger(mockCtrl)
+		handler = NewHandler(client, configService, logger)
+		playbookService = mock_app.NewMockPlaybookService(mockCtrl)
+		poster = mock_poster.NewMockPoster(mockCtrl)
+
+		NewPlaybookHandler(handler.APIRouter, playbookService, client, logger, configService)
+
+		configService.EXPECT().IsAtLeastE20Licensed().AnyTimes().Return(true)
+		configService.EXPECT().GetConfiguration().AnyTimes().Return(cfg)
+	}
+
+	t.Run("validate playbook", func(t *testing.T) {
+		resetWithConfiguration(t, &config.Configuration{BotUserID: "botid"})
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionToChannel", "botid", "channelid", model.PermissionCreatePost).Return(false)
+
+		result, err := c.Playbooks.Validate(context.TODO(), icClient.Playbook{
+			Title:               "My Playbook",
+			TeamID:              "testteamid",
+			Checklists:          []icClient.Checklist{{Title: "Empty"}},
+			BroadcastChannelIDs: []string{"channelid"},
+		})
+		require.NoError(t, err)
+		assert.True(t, result.Valid)
+		assert.False(t, result.Strict)
+		require.Len(t, result.Issues, 2)
+		assert.Equal(t, icClient.PlaybookLintIssue{
+			Severity: "warning",
+			Code:     "empty_checklist",
+			Field:    "checklists[0]",
+			Message:  `the checklist "Empty" has no items`,
+		}, result.Issues[0])
+		assert.Equal(t, "broadcast_channel_not_postable", result.Issues[1].Code)
+	})
+
+	t.Run("validate playbook in a team the user is not in", func(t *testing.T) {
+		reset(t)
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(false)
+
+		_, err := c.Playbooks.Validate(context.TODO(), icClient.Playbook{TeamID: "testteamid"})
+		requireErrorWithStatusCode(t, err, http.StatusForbidden)
+	})
+
+	t.Run("create playbook with warnings in strict mode", func(t *testing.T) {
+		resetWithConfiguration(t, &config.Configuration{StrictPlaybookValidation: true})
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("GetUser", "testuserid").Return(&model.User{}, nil)
+		playbookService.EXPECT().
+			Create(gomock.Any(), "testuserid").
+			Return("", &app.InvalidPlaybookError{Issues: []app.PlaybookLintIssue{{Severity: app.PlaybookLintWarning, Code: "empty_checklist"}}})
+
+		_, err := c.Playbooks.Create(context.TODO(), icClient.PlaybookCreateOptions{
+			Title:  playbooktest.Title,
+			TeamID: playbooktest.TeamID,
+		})
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("update playbook with warnings in strict mode", func(t *testing.T) {
+		resetWithConfiguration(t, &config.Configuration{StrictPlaybookValidation: true})
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
+
+		playbookService.EXPECT().
+			Get("playbookwithmember").
+			Return(withMember, nil).
+			Times(2)
+
+		pluginAPI.On("HasPermissionToTeam", "testuserid", "testteamid", model.PermissionViewTeam).Return(true)
+		pluginAPI.On("HasPermissionTo", "testuserid", model.PermissionManageSystem).Return(false)
+
+		playbookService.EXPECT().
+			Update(gomock.Any(), "testuserid").
+			Return(&app.InvalidPlaybookError{Issues: []app.PlaybookLintIssue{{Severity: app.PlaybookLintWarning, Code: "empty_checklist"}}})
+
+		updated := toAPIPlaybook(withMember)
+		updated.Checklists = append(updated.Checklists, icClient.Checklist{Title: "Empty"})
+		err := c.Playbooks.Update(context.TODO(), updated)
+		requireErrorWithStatusCode(t, err, http.StatusBadRequest)
+	})
+}
+
+func TestCheckAutoRunUsers(t *testing.T) {
+	checklists := func(items ...app.ChecklistItem) []app.Checklist {
+		return []app.Checklist{{Title: "Checklist", Items: items}}
+	}
+	previous := checklists(app.ChecklistItem{Title: "Deploy", Command: "/deploy", AutoRunUserID: "alice"})
+
+	require.NoError(t, checkAutoRunUsers("bob", checklists(app.ChecklistItem{Command: "/echo"}), nil))
+	require.NoError(t, checkAutoRunUsers("bob", checklists(app.ChecklistItem{Command: "/echo", AutoRunUserID: "bob"}), nil))
+	require.Error(t, checkAutoRunUsers("bob", checklists(app.ChecklistItem{Command: "/echo", AutoRunUserID: "alice"}), nil))
+
+	// Alice's choice is kept as long as her command is unchanged.
+	require.NoError(t, checkAutoRunUsers("bob", previous, previous))
+	require.Error(t, checkAutoRunUsers("bob", checklists(app.ChecklistItem{Title: "Deploy", Command: "/deploy --force", AutoRunUserID: "alice"}), previous))
+	require.NoError(t, checkAutoRunUsers("bob", checklists(app.ChecklistItem{Title: "Deploy", Command: "/deploy --force", AutoRunUserID: "bob"}), previous))
 }
 
 func TestSortingPlaybooks(t *testing.T) {
@@ -1738,12 +2672,7 @@ func TestSortingPlaybooks(t *testing.T) {
 				logger.EXPECT().Warnf(gomock.Any(), gomock.Any(), gomock.Any())
 			}
 
-			playbookResult := struct {
-				TotalCount int            `json:"total_count"`
-				PageCount  int            `json:"page_count"`
-				HasMore    bool           `json:"has_more"`
-				Items      []app.Playbook `json:"items"`
-			}{
+			playbookResult := app.GetPlaybooksResults{
 				TotalCount: 3,
 				PageCount:  1,
 				HasMore:    false,
@@ -1796,6 +2725,8 @@ func TestPagingPlaybooks(t *testing.T) {
 		Title:           "A",
 		TeamID:          "testteamid",
 		Checklists:      []app.Checklist{},
+		Members:         []app.PlaybookMember{},
+		MemberGroups:    []app.PlaybookMemberGroup{},
 		MemberIDs:       []string{},
 		InvitedUserIDs:  []string{},
 		InvitedGroupIDs: []string{},
@@ -1804,6 +2735,8 @@ func TestPagingPlaybooks(t *testing.T) {
 		Title:           "B",
 		TeamID:          "testteamid",
 		Checklists:      []app.Checklist{},
+		Members:         []app.PlaybookMember{},
+		MemberGroups:    []app.PlaybookMemberGroup{},
 		MemberIDs:       []string{},
 		InvitedUserIDs:  []string{},
 		InvitedGroupIDs: []string{},
@@ -1812,6 +2745,8 @@ func TestPagingPlaybooks(t *testing.T) {
 		Title:           "C",
 		TeamID:          "testteamid",
 		Checklists:      []app.Checklist{},
+		Members:         []app.PlaybookMember{},
+		MemberGroups:    []app.PlaybookMemberGroup{},
 		MemberIDs:       []string{},
 		InvitedUserIDs:  []string{},
 		InvitedGroupIDs: []string{},
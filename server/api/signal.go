@@ -16,19 +16,19 @@ import (
 
 type SignalHandler struct {
 	*ErrorHandler
-	api                   *pluginapi.Client
-	playbookRunService    app.PlaybookRunService
-	playbookService       app.PlaybookService
-	keywordsThreadIgnorer app.KeywordsThreadIgnorer
+	api                *pluginapi.Client
+	playbookRunService app.PlaybookRunService
+	playbookService    app.PlaybookService
+	keywordsIgnorer    app.KeywordsIgnorer
 }
 
-func NewSignalHandler(router *mux.Router, api *pluginapi.Client, logger bot.Logger, playbookRunService app.PlaybookRunService, playbookService app.PlaybookService, keywordsThreadIgnorer app.KeywordsThreadIgnorer) *SignalHandler {
+func NewSignalHandler(router *mux.Router, api *pluginapi.Client, logger bot.Logger, playbookRunService app.PlaybookRunService, playbookService app.PlaybookService, keywordsIgnorer app.KeywordsIgnorer) *SignalHandler {
 	handler := &SignalHandler{
-		ErrorHandler:          &ErrorHandler{log: logger},
-		api:                   api,
-		playbookRunService:    playbookRunService,
-		playbookService:       playbookService,
-		keywordsThreadIgnorer: keywordsThreadIgnorer,
+		ErrorHandler:       &ErrorHandler{log: logger},
+		api:                api,
+		playbookRunService: playbookRunService,
+		playbookService:    playbookService,
+		keywordsIgnorer:    keywordsIgnorer,
 	}
 
 	signalRouter := router.PathPrefix("/signal").Subrouter()
@@ -36,6 +36,12 @@ func NewSignalHandler(router *mux.Router, api *pluginapi.Client, logger bot.Logg
 	keywordsRouter := signalRouter.PathPrefix("/keywords").Subrouter()
 	keywordsRouter.HandleFunc("/run-playbook", handler.playbookRun).Methods(http.MethodPost)
 	keywordsRouter.HandleFunc("/ignore-thread", handler.ignoreKeywords).Methods(http.MethodPost)
+	keywordsRouter.HandleFunc("/ignores", handler.getIgnoreRules).Methods(http.MethodGet)
+	keywordsRouter.HandleFunc("/ignores", handler.addIgnoreRule).Methods(http.MethodPost)
+	keywordsRouter.HandleFunc("/ignores", handler.removeIgnoreRule).Methods(http.MethodDelete)
+	keywordsRouter.HandleFunc("/muted-channels", handler.getMutedChannels).Methods(http.MethodGet)
+	keywordsRouter.HandleFunc("/muted-channels", handler.muteChannel).Methods(http.MethodPost)
+	keywordsRouter.HandleFunc("/muted-channels/{channel_id:[A-Za-z0-9]+}", handler.unmuteChannel).Methods(http.MethodDelete)
 
 	return handler
 }
@@ -111,15 +117,172 @@ func (h *SignalHandler) ignoreKeywords(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.keywordsThreadIgnorer.Ignore(postID, post.UserId)
+	if err = h.keywordsIgnorer.IgnoreThread(postID, post.UserId); err != nil {
+		h.returnError("unable to ignore thread", err, w)
+		return
+	}
 	if post.RootId != "" {
-		h.keywordsThreadIgnorer.Ignore(post.RootId, post.UserId)
+		if err = h.keywordsIgnorer.IgnoreThread(post.RootId, post.UserId); err != nil {
+			h.returnError("unable to ignore thread", err, w)
+			return
+		}
 	}
 
 	ReturnJSON(w, &model.PostActionIntegrationResponse{}, http.StatusOK)
 	h.api.Post.DeleteEphemeralPost(req.UserId, req.PostId)
 }
 
+// getIgnoreRules returns the keywords ignore rules of the requesting user.
+func (h *SignalHandler) getIgnoreRules(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	rules, err := h.keywordsIgnorer.GetRules(userID)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, rules, http.StatusOK)
+}
+
+// addIgnoreRule stores a keywords ignore rule for the requesting user.
+func (h *SignalHandler) addIgnoreRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	var rule app.KeywordsIgnoreRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode keywords ignore rule", err)
+		return
+	}
+
+	rule.UserID = userID
+	rule.CreateAt = 0
+	if err := rule.IsValid(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid keywords ignore rule", err)
+		return
+	}
+
+	switch rule.Scope {
+	case app.KeywordsIgnoreScopeChannel:
+		if !app.IsMemberOfChannel(userID, rule.ScopeID, h.api) {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized",
+				errors.Errorf("userID %s is not a member of channel %s", userID, rule.ScopeID))
+			return
+		}
+	case app.KeywordsIgnoreScopeThread:
+		post, err := h.api.Post.GetPost(rule.ScopeID)
+		if err != nil {
+			h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to get post", err)
+			return
+		}
+		if !app.IsMemberOfChannel(userID, post.ChannelId, h.api) {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized",
+				errors.Errorf("userID %s is not a member of channel %s", userID, post.ChannelId))
+			return
+		}
+	}
+
+	if err := h.keywordsIgnorer.Ignore(rule); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// removeIgnoreRule removes a keywords ignore rule of the requesting user, identified by the
+// scope and scope_id query parameters.
+func (h *SignalHandler) removeIgnoreRule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	query := r.URL.Query()
+
+	rule := app.KeywordsIgnoreRule{
+		UserID:  userID,
+		Scope:   query.Get("scope"),
+		ScopeID: query.Get("scope_id"),
+	}
+	if err := rule.IsValid(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid keywords ignore rule", err)
+		return
+	}
+
+	if err := h.keywordsIgnorer.Unignore(rule.UserID, rule.Scope, rule.ScopeID); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getMutedChannels returns the channels in which keywords never trigger suggestions.
+func (h *SignalHandler) getMutedChannels(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	if !app.IsAdmin(userID, h.api) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s is not an admin", userID))
+		return
+	}
+
+	rules, err := h.keywordsIgnorer.GetRules("")
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, rules, http.StatusOK)
+}
+
+// muteChannel stops keywords from triggering suggestions for everyone in a channel.
+func (h *SignalHandler) muteChannel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	if !app.IsAdmin(userID, h.api) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s is not an admin", userID))
+		return
+	}
+
+	var params struct {
+		ChannelID string `json:"channel_id"`
+		ExpireAt  int64  `json:"expire_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "unable to decode muted channel", err)
+		return
+	}
+
+	rule := app.KeywordsIgnoreRule{
+		Scope:    app.KeywordsIgnoreScopeChannel,
+		ScopeID:  params.ChannelID,
+		ExpireAt: params.ExpireAt,
+	}
+	if err := rule.IsValid(); err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "invalid muted channel", err)
+		return
+	}
+
+	if err := h.keywordsIgnorer.Ignore(rule); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// unmuteChannel lets keywords trigger suggestions in a previously muted channel.
+func (h *SignalHandler) unmuteChannel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+	if !app.IsAdmin(userID, h.api) {
+		h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", errors.Errorf("userID %s is not an admin", userID))
+		return
+	}
+
+	channelID := mux.Vars(r)["channel_id"]
+	if err := h.keywordsIgnorer.Unignore("", app.KeywordsIgnoreScopeChannel, channelID); err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (h *SignalHandler) returnError(returnMessage string, err error, w http.ResponseWriter) {
 	resp := model.PostActionIntegrationResponse{
 		EphemeralText: fmt.Sprintf("Error: %s", returnMessage),
@@ -4,12 +4,14 @@ import (
 	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
 
 	"github.com/gorilla/mux"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore"
+	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/pkg/errors"
 
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
@@ -34,6 +36,7 @@ func NewStatsHandler(router *mux.Router, api *pluginapi.Client, log bot.Logger,
 
 	statsRouter := router.PathPrefix("/stats").Subrouter()
 	statsRouter.HandleFunc("/playbook", handler.playbookStats).Methods(http.MethodGet)
+	statsRouter.HandleFunc("/operational", handler.operationalStats).Methods(http.MethodGet)
 
 	return handler
 }
@@ -101,3 +104,107 @@ func (h *StatsHandler) playbookStats(w http.ResponseWriter, r *http.Request) {
 		ActiveParticipantsPerDayTimes: activeParticipantsPerDayTimes,
 	}, http.StatusOK)
 }
+
+type OperationalStats struct {
+	TimeToFinish            sqlstore.DurationStats            `json:"time_to_finish"`
+	TimeToFirstStatusUpdate sqlstore.DurationStats            `json:"time_to_first_status_update"`
+	StatusUpdateCadence     sqlstore.StatusUpdateCadenceStats `json:"status_update_cadence"`
+	ChecklistItems          []sqlstore.ChecklistItemStats     `json:"checklist_items"`
+	RunStatuses             []sqlstore.RunStatusStats         `json:"run_statuses"`
+}
+
+func parseOperationalStatsFilters(u *url.URL) (*sqlstore.StatsFilters, error) {
+	var err error
+	filters := &sqlstore.StatsFilters{
+		TeamID:     u.Query().Get("team_id"),
+		PlaybookID: u.Query().Get("playbook_id"),
+	}
+
+	if param := u.Query().Get("started_gte"); param != "" {
+		filters.StartedGTE, err = strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad parameter 'started_gte'")
+		}
+	}
+
+	if param := u.Query().Get("started_lt"); param != "" {
+		filters.StartedLT, err = strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad parameter 'started_lt'")
+		}
+	}
+
+	return filters, nil
+}
+
+// operationalStats returns the time to finish, time to first status update, status update
+// cadence, checklist item completion times and time spent in each run status of the runs of a playbook, of a team, or of every
+// team. Stats of a playbook require access to it, stats of a whole team require being a team
+// admin, and stats across all teams require being a system admin.
+func (h *StatsHandler) operationalStats(w http.ResponseWriter, r *http.Request) {
+	userID := r.Header.Get("Mattermost-User-ID")
+
+	filters, err := parseOperationalStatsFilters(r.URL)
+	if err != nil {
+		h.HandleErrorWithCode(w, http.StatusBadRequest, "Bad filters", err)
+		return
+	}
+
+	switch {
+	case filters.PlaybookID != "":
+		if err2 := app.PlaybookAccess(userID, filters.PlaybookID, h.playbookService, h.pluginAPI); err2 != nil {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized", err2)
+			return
+		}
+	case filters.TeamID != "":
+		if !h.pluginAPI.User.HasPermissionToTeam(userID, filters.TeamID, model.PermissionManageTeam) {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized",
+				errors.Errorf("userID %s is not an admin of team %s", userID, filters.TeamID))
+			return
+		}
+	default:
+		if !app.IsAdmin(userID, h.pluginAPI) {
+			h.HandleErrorWithCode(w, http.StatusForbidden, "Not authorized",
+				errors.Errorf("userID %s is not a system admin", userID))
+			return
+		}
+	}
+
+	timeToFinish, err := h.statsStore.TimeToFinish(filters)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	timeToFirstStatusUpdate, err := h.statsStore.TimeToFirstStatusUpdate(filters)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	statusUpdateCadence, err := h.statsStore.StatusUpdateCadence(filters)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	checklistItems, err := h.statsStore.ChecklistItemCompletionTimes(filters)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	runStatuses, err := h.statsStore.RunStatusDurations(filters)
+	if err != nil {
+		h.HandleError(w, err)
+		return
+	}
+
+	ReturnJSON(w, &OperationalStats{
+		TimeToFinish:            timeToFinish,
+		TimeToFirstStatusUpdate: timeToFirstStatusUpdate,
+		StatusUpdateCadence:     statusUpdateCadence,
+		ChecklistItems:          checklistItems,
+		RunStatuses:             runStatuses,
+	}, http.StatusOK)
+}
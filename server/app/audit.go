@@ -0,0 +1,342 @@
+package app
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// AuditSourceAPI marks the actions done through the REST API.
+	AuditSourceAPI = "api"
+
+	// AuditSourceSlashCommand marks the actions done with the /playbook slash command.
+	AuditSourceSlashCommand = "slash_command"
+
+	// AuditSourceDialog marks the actions done by submitting an interactive dialog.
+	AuditSourceDialog = "dialog"
+)
+
+const (
+	// AuditTargetPlaybook is the target type of the actions on a playbook.
+	AuditTargetPlaybook = "playbook"
+
+	// AuditTargetRun is the target type of the actions on a playbook run.
+	AuditTargetRun = "run"
+
+	// AuditTargetSettings is the target type of the changes to the global settings, and to the
+	// personal and channel settings changed with the /playbook settings command.
+	AuditTargetSettings = "settings"
+
+	// AuditTargetChecklistLibrary is the target type of the actions on a checklist library entry.
+	AuditTargetChecklistLibrary = "checklist_library"
+)
+
+// AuditActionPermissionDenied is the action of the attempts rejected for lack of permissions.
+// The attempted action is in the record details.
+const AuditActionPermissionDenied = "permission_denied"
+
+// AuditRecord is an entry of the audit log: who did what to which playbook, run or setting.
+type AuditRecord struct {
+	ID string `json:"id"`
+
+	// CreateAt is the timestamp, in milliseconds since epoch, of the action.
+	CreateAt int64 `json:"create_at"`
+
+	// ActorUserID is the user who did the action.
+	ActorUserID string `json:"actor_user_id"`
+
+	// Action is the route of the API request, e.g. "PUT /playbooks/{id}", the slash command,
+	// e.g. "/playbook finish", or AuditActionPermissionDenied.
+	Action string `json:"action"`
+
+	// Source is one of AuditSourceAPI, AuditSourceSlashCommand or AuditSourceDialog.
+	Source string `json:"source"`
+
+	// TargetType is one of AuditTargetPlaybook, AuditTargetRun, AuditTargetSettings or
+	// AuditTargetChecklistLibrary, or empty for the attempts on something else.
+	TargetType string `json:"target_type"`
+
+	// TargetID is the ID of the playbook or run, empty for the settings.
+	TargetID string `json:"target_id"`
+
+	// TeamID is the team of the target, if any.
+	TeamID string `json:"team_id"`
+
+	// Details is free-form information about the action.
+	Details string `json:"details"`
+
+	// Changes are the fields of the target modified by the action, with their values before
+	// and after it.
+	Changes []AuditChange `json:"changes"`
+}
+
+// maxAuditChangeValueLength is the maximum length, in bytes of JSON, of a value recorded in an
+// audit change.
+const maxAuditChangeValueLength = 1024
+
+// AuditChange is the change of a field of the target of an audited action.
+type AuditChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+
+	// Omitted is true if the values were too long to be recorded, e.g. the checklists of a
+	// playbook. Only the name of the field is kept then.
+	Omitted bool `json:"omitted,omitempty"`
+}
+
+// AuditDiff returns the top-level JSON fields that differ between before and after, sorted by
+// name. A nil before or after stands for a created or deleted target. The values longer than
+// maxAuditChangeValueLength are left out.
+func AuditDiff(before, after interface{}) ([]AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the state before the action")
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read the state after the action")
+	}
+
+	names := make([]string, 0, len(beforeFields)+len(afterFields))
+	for name := range beforeFields {
+		names = append(names, name)
+	}
+	for name := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []AuditChange{}
+	for _, name := range names {
+		beforeValue, afterValue := beforeFields[name], afterFields[name]
+		if reflect.DeepEqual(beforeValue, afterValue) {
+			continue
+		}
+		change := AuditChange{
+			Field:  name,
+			Before: beforeValue,
+			After:  afterValue,
+		}
+		if auditValueTooLong(beforeValue) || auditValueTooLong(afterValue) {
+			change = AuditChange{Field: name, Omitted: true}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+// auditValueTooLong returns true if the JSON representation of value is longer than
+// maxAuditChangeValueLength.
+func auditValueTooLong(value interface{}) bool {
+	data, err := json.Marshal(value)
+	return err != nil || len(data) > maxAuditChangeValueLength
+}
+
+// auditFields returns the top-level fields of the JSON representation of value.
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil()) {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// AuditFilterOptions specifies the audit records to list, newest first.
+type AuditFilterOptions struct {
+	ActorUserID string `url:"actor_user_id,omitempty"`
+	Action      string `url:"action,omitempty"`
+	Source      string `url:"source,omitempty"`
+	TargetType  string `url:"target_type,omitempty"`
+	TargetID    string `url:"target_id,omitempty"`
+	TeamID      string `url:"team_id,omitempty"`
+
+	// Since and Until restrict the records to the actions done at or after Since, and before
+	// Until, in milliseconds since epoch. 0 means unbounded.
+	Since int64 `url:"since,omitempty"`
+	Until int64 `url:"until,omitempty"`
+
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+
+	// Cursor is the NextCursor of the previous page. When set, Page is ignored.
+	Cursor string `url:"cursor,omitempty"`
+
+	// SkipCount skips computing the total count, e.g. when walking through all the pages.
+	SkipCount bool `url:"skip_count,omitempty"`
+}
+
+// Validate returns a copy of the options with the defaults applied, or an error if they are
+// invalid.
+func (o AuditFilterOptions) Validate() (AuditFilterOptions, error) {
+	options := o
+
+	if options.PerPage <= 0 {
+		options.PerPage = PerPageDefault
+	}
+	if options.Page < 0 {
+		options.Page = 0
+	}
+
+	if options.ActorUserID != "" && !model.IsValidId(options.ActorUserID) {
+		return AuditFilterOptions{}, errors.New("bad parameter 'actor_user_id': must be 26 characters or blank")
+	}
+	if options.TargetID != "" && !model.IsValidId(options.TargetID) {
+		return AuditFilterOptions{}, errors.New("bad parameter 'target_id': must be 26 characters or blank")
+	}
+	if options.TeamID != "" && !model.IsValidId(options.TeamID) {
+		return AuditFilterOptions{}, errors.New("bad parameter 'team_id': must be 26 characters or blank")
+	}
+
+	switch options.Source {
+	case "", AuditSourceAPI, AuditSourceSlashCommand, AuditSourceDialog:
+	default:
+		return AuditFilterOptions{}, errors.Errorf("bad parameter 'source': unknown source '%s'", options.Source)
+	}
+
+	switch options.TargetType {
+	case "", AuditTargetPlaybook, AuditTargetRun, AuditTargetSettings, AuditTargetChecklistLibrary:
+	default:
+		return AuditFilterOptions{}, errors.Errorf("bad parameter 'target_type': unknown target type '%s'", options.TargetType)
+	}
+
+	if options.Since < 0 {
+		options.Since = 0
+	}
+	if options.Until < 0 {
+		options.Until = 0
+	}
+
+	if options.Cursor != "" {
+		if err := validatePageCursor(options.Cursor, SortByCreateAt, DirectionDesc); err != nil {
+			return AuditFilterOptions{}, err
+		}
+		options.Page = 0
+	}
+
+	return options, nil
+}
+
+// GetAuditRecordsResults is a page of audit records.
+type GetAuditRecordsResults struct {
+	TotalCount int           `json:"total_count"`
+	PageCount  int           `json:"page_count"`
+	HasMore    bool          `json:"has_more"`
+	Items      []AuditRecord `json:"items"`
+
+	// NextCursor is the cursor to get the next page with, if HasMore.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// AuditStore persists the audit log.
+type AuditStore interface {
+	// Create stores the record.
+	Create(record AuditRecord) error
+
+	// GetRecords retrieves the records matching the validated options, newest first.
+	GetRecords(options AuditFilterOptions) (*GetAuditRecordsResults, error)
+}
+
+// AuditService records the actions done on playbooks, runs and settings, and lists them back.
+type AuditService interface {
+	// Record stores the record, setting its ID and time if missing. Failing to store it never
+	// fails the audited action, so the errors are only logged.
+	Record(record AuditRecord)
+
+	// GetRecords retrieves a page of the records matching the options, newest first.
+	GetRecords(options AuditFilterOptions) (*GetAuditRecordsResults, error)
+
+	// Export writes all the records matching the options to w as JSON lines, newest first.
+	// The pagination options are ignored.
+	Export(w io.Writer, options AuditFilterOptions) error
+}
+
+type auditServiceImpl struct {
+	store AuditStore
+	log   bot.Logger
+}
+
+// NewAuditService returns an AuditService backed by the given store.
+func NewAuditService(store AuditStore, log bot.Logger) AuditService {
+	return &auditServiceImpl{
+		store: store,
+		log:   log,
+	}
+}
+
+// auditExportPerPage is the number of records read at a time when exporting.
+const auditExportPerPage = 1000
+
+func (s *auditServiceImpl) Record(record AuditRecord) {
+	if record.ID == "" {
+		record.ID = model.NewId()
+	}
+	if record.CreateAt == 0 {
+		record.CreateAt = model.GetMillis()
+	}
+	if record.Changes == nil {
+		record.Changes = []AuditChange{}
+	}
+
+	if err := s.store.Create(record); err != nil {
+		s.log.Errorf("failed to record the audit record of action '%s' by user '%s': %v", record.Action, record.ActorUserID, err)
+	}
+}
+
+func (s *auditServiceImpl) GetRecords(options AuditFilterOptions) (*GetAuditRecordsResults, error) {
+	options, err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.store.GetRecords(options)
+}
+
+func (s *auditServiceImpl) Export(w io.Writer, options AuditFilterOptions) error {
+	options.Page = 0
+	options.PerPage = auditExportPerPage
+	options.Cursor = ""
+	options.SkipCount = true
+
+	options, err := options.Validate()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for {
+		results, getErr := s.store.GetRecords(options)
+		if getErr != nil {
+			return errors.Wrap(getErr, "failed to get audit records")
+		}
+
+		for _, record := range results.Items {
+			if err = encoder.Encode(record); err != nil {
+				return errors.Wrap(err, "failed to write audit record")
+			}
+		}
+
+		if !results.HasMore || results.NextCursor == "" {
+			return nil
+		}
+		options.Cursor = results.NextCursor
+	}
+}
@@ -0,0 +1,168 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditDiff(t *testing.T) {
+	t.Run("changed fields, sorted", func(t *testing.T) {
+		before := app.Playbook{ID: "playbook_id", Title: "Before", Description: "Same", NumStages: 1}
+		after := app.Playbook{ID: "playbook_id", Title: "After", Description: "Same", NumStages: 2}
+
+		changes, err := app.AuditDiff(before, after)
+		require.NoError(t, err)
+		require.Equal(t, []app.AuditChange{
+			{Field: "num_stages", Before: float64(1), After: float64(2)},
+			{Field: "title", Before: "Before", After: "After"},
+		}, changes)
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		playbookRun := &app.PlaybookRun{ID: "run_id", Name: "Run"}
+
+		changes, err := app.AuditDiff(playbookRun, playbookRun)
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("created and deleted targets", func(t *testing.T) {
+		settings := map[string]interface{}{"playbook_creators_user_ids": []string{"user_id"}}
+
+		changes, err := app.AuditDiff(nil, settings)
+		require.NoError(t, err)
+		require.Equal(t, []app.AuditChange{
+			{Field: "playbook_creators_user_ids", Before: nil, After: []interface{}{"user_id"}},
+		}, changes)
+
+		var deleted *app.PlaybookRun
+		changes, err = app.AuditDiff(settings, deleted)
+		require.NoError(t, err)
+		require.Equal(t, []app.AuditChange{
+			{Field: "playbook_creators_user_ids", Before: []interface{}{"user_id"}, After: nil},
+		}, changes)
+	})
+
+	t.Run("long values are omitted", func(t *testing.T) {
+		before := app.Playbook{ID: "playbook_id", Title: "Before"}
+		after := app.Playbook{ID: "playbook_id", Title: "After", Description: strings.Repeat("a", 2000)}
+
+		changes, err := app.AuditDiff(before, after)
+		require.NoError(t, err)
+		require.Equal(t, []app.AuditChange{
+			{Field: "description", Omitted: true},
+			{Field: "title", Before: "Before", After: "After"},
+		}, changes)
+	})
+}
+
+func TestAuditFilterOptions_Validate(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		options, err := app.AuditFilterOptions{Page: -1, Since: -1}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, app.AuditFilterOptions{PerPage: app.PerPageDefault}, options)
+	})
+
+	t.Run("invalid ids", func(t *testing.T) {
+		_, err := app.AuditFilterOptions{ActorUserID: "actor"}.Validate()
+		require.EqualError(t, err, "bad parameter 'actor_user_id': must be 26 characters or blank")
+
+		_, err = app.AuditFilterOptions{TargetID: "target"}.Validate()
+		require.EqualError(t, err, "bad parameter 'target_id': must be 26 characters or blank")
+
+		_, err = app.AuditFilterOptions{TeamID: "team"}.Validate()
+		require.EqualError(t, err, "bad parameter 'team_id': must be 26 characters or blank")
+	})
+
+	t.Run("unknown source and target type", func(t *testing.T) {
+		_, err := app.AuditFilterOptions{Source: "webhook"}.Validate()
+		require.Error(t, err)
+
+		_, err = app.AuditFilterOptions{TargetType: "channel"}.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("cursors", func(t *testing.T) {
+		cursor := app.PageCursor{Sort: app.SortByCreateAt, Direction: app.DirectionDesc, Value: int64(1000), ID: model.NewId()}.Encode()
+
+		options, err := app.AuditFilterOptions{Page: 3, Cursor: cursor}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, 0, options.Page)
+
+		otherCursor := app.PageCursor{Sort: app.SortByCreateAt, Direction: app.DirectionAsc, Value: int64(1000), ID: model.NewId()}.Encode()
+		_, err = app.AuditFilterOptions{Cursor: otherCursor}.Validate()
+		require.Error(t, err)
+	})
+}
+
+func TestAuditService(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	store := mock_app.NewMockAuditStore(mockCtrl)
+	logger := mock_bot.NewMockLogger(mockCtrl)
+	service := app.NewAuditService(store, logger)
+
+	t.Run("record sets the id, time and changes", func(t *testing.T) {
+		store.EXPECT().
+			Create(gomock.Any()).
+			DoAndReturn(func(record app.AuditRecord) error {
+				require.True(t, model.IsValidId(record.ID))
+				require.NotZero(t, record.CreateAt)
+				require.Equal(t, []app.AuditChange{}, record.Changes)
+				require.Equal(t, "PUT /settings", record.Action)
+				return nil
+			})
+
+		service.Record(app.AuditRecord{Action: "PUT /settings"})
+	})
+
+	t.Run("record only logs the errors", func(t *testing.T) {
+		store.EXPECT().Create(gomock.Any()).Return(errors.New("database is down"))
+		logger.EXPECT().Errorf(gomock.Any(), gomock.Any())
+
+		service.Record(app.AuditRecord{Action: "PUT /settings"})
+	})
+
+	t.Run("export walks through all the pages", func(t *testing.T) {
+		first := app.AuditRecord{ID: model.NewId(), Action: "DELETE /playbooks/{id}", Changes: []app.AuditChange{}}
+		second := app.AuditRecord{ID: model.NewId(), Action: "PUT /settings", Changes: []app.AuditChange{}}
+
+		gomock.InOrder(
+			store.EXPECT().
+				GetRecords(gomock.Any()).
+				DoAndReturn(func(options app.AuditFilterOptions) (*app.GetAuditRecordsResults, error) {
+					require.Empty(t, options.Cursor)
+					require.True(t, options.SkipCount)
+					require.Equal(t, app.AuditSourceAPI, options.Source)
+					return &app.GetAuditRecordsResults{HasMore: true, Items: []app.AuditRecord{first}, NextCursor: "next"}, nil
+				}),
+			store.EXPECT().
+				GetRecords(gomock.Any()).
+				DoAndReturn(func(options app.AuditFilterOptions) (*app.GetAuditRecordsResults, error) {
+					require.Equal(t, "next", options.Cursor)
+					return &app.GetAuditRecordsResults{Items: []app.AuditRecord{second}}, nil
+				}),
+		)
+
+		var buffer bytes.Buffer
+		err := service.Export(&buffer, app.AuditFilterOptions{Source: app.AuditSourceAPI, Page: 2})
+		require.NoError(t, err)
+
+		decoder := json.NewDecoder(&buffer)
+		var record app.AuditRecord
+		require.NoError(t, decoder.Decode(&record))
+		require.Equal(t, first, record)
+		require.NoError(t, decoder.Decode(&record))
+		require.Equal(t, second, record)
+		require.False(t, decoder.More())
+	})
+}
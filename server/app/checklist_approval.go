@@ -0,0 +1,281 @@
+package app
+
+import (
+	"fmt"
+
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// ChecklistItemStatePendingApproval is the state of an item that was checked off but waits for
+// the sign-off of its approvers to be closed. It can't be set directly.
+const ChecklistItemStatePendingApproval = "pending_approval"
+
+// maxApprovalRequests is the maximum number of approvers an approval request is sent to.
+const maxApprovalRequests = 100
+
+// ChecklistItemApproval designates who must sign off a checklist item before it's closed.
+type ChecklistItemApproval struct {
+	// ApproverIDs are the identifiers of the users who can approve the item.
+	ApproverIDs []string `json:"approver_ids,omitempty"`
+
+	// ApproverGroupIDs are the identifiers of the user groups whose members can approve the item.
+	ApproverGroupIDs []string `json:"approver_group_ids,omitempty"`
+
+	// RequiredApprovals is the number of approvers who must sign off the item. 0 means 1.
+	RequiredApprovals int `json:"required_approvals"`
+}
+
+// HasApprovers returns true if the item must be approved before it's closed.
+func (a ChecklistItemApproval) HasApprovers() bool {
+	return len(a.ApproverIDs) != 0 || len(a.ApproverGroupIDs) != 0
+}
+
+// requiredApprovals returns the number of approvals the item needs.
+func (a ChecklistItemApproval) requiredApprovals() int {
+	if a.RequiredApprovals < 1 {
+		return 1
+	}
+	return a.RequiredApprovals
+}
+
+// isApproved returns true if enough approvers signed off the item.
+func (i ChecklistItem) isApproved() bool {
+	return len(i.ApprovedBy) >= i.Approval.requiredApprovals()
+}
+
+// clearApproval forgets the pending approval request of the item and its sign-offs.
+func (i *ChecklistItem) clearApproval() {
+	i.ApprovalRequestedBy = ""
+	i.ApprovalRequestedAt = 0
+	i.ApprovedBy = nil
+}
+
+// requestChecklistItemApproval puts the item, checked off by userID, on hold until its approvers
+// sign it off, and asks them to by DM.
+func (s *PlaybookRunServiceImpl) requestChecklistItemApproval(playbookRun *PlaybookRun, userID string, checklistNumber, itemNumber int) error {
+	item := playbookRun.Checklists[checklistNumber].Items[itemNumber]
+	if item.State == ChecklistItemStatePendingApproval {
+		return nil
+	}
+
+	requester, err := s.pluginAPI.User.Get(userID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to to resolve user %s", userID)
+	}
+
+	title := stripmd.Strip(item.Title)
+	post, err := s.poster.PostMessage(playbookRun.ChannelID, fmt.Sprintf("%s checked off checklist item **%s**, which now waits for approval", requester.Username, title))
+	if err != nil {
+		return errors.Wrapf(err, "failed to post modification messsage")
+	}
+
+	now := model.GetMillis()
+	item.State = ChecklistItemStatePendingApproval
+	item.StateModified = now
+	item.StateModifiedPostID = post.Id
+	item.ApprovalRequestedBy = userID
+	item.ApprovalRequestedAt = now
+	item.ApprovedBy = nil
+	playbookRun.Checklists[checklistNumber].Items[itemNumber] = item
+
+	if err = s.store.UpdatePlaybookRun(playbookRun); err != nil {
+		return errors.Wrap(err, "failed to update playbook run requesting the approval")
+	}
+
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRun.ID,
+		CreateAt:      now,
+		EventAt:       now,
+		EventType:     ApprovalRequested,
+		Summary:       fmt.Sprintf("requested the approval of **%s**", title),
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create timeline event")
+	}
+
+	runURL := getRunDetailsURL("", s.configService.GetManifest().Id, playbookRun.ID)
+	message := fmt.Sprintf("@%s asks you to approve **%s** in the run [%s](%s).", requester.Username, title, playbookRun.Name, runURL)
+	for _, approverID := range s.approvers(item) {
+		request := &model.Post{Message: message}
+		model.ParseSlackAttachment(request, s.approvalAttachments(playbookRun.ID, item.ID))
+		if err = s.poster.DM(approverID, request); err != nil {
+			s.pluginAPI.Log.Warn("failed to send the approval request", "user_id", approverID, "error", err.Error())
+		}
+	}
+
+	return s.sendPlaybookRunToClient(playbookRun.ID)
+}
+
+// approvalAttachments returns the buttons approving and rejecting an item.
+func (s *PlaybookRunServiceImpl) approvalAttachments(playbookRunID, itemID string) []*model.SlackAttachment {
+	url := fmt.Sprintf("/plugins/%s/api/v0/runs/%s/approval-button", s.configService.GetManifest().Id, playbookRunID)
+	button := func(name, action string) *model.PostAction {
+		return &model.PostAction{
+			Type: "button",
+			Name: name,
+			Integration: &model.PostActionIntegration{
+				URL:     url,
+				Context: map[string]interface{}{"item_id": itemID, "action": action},
+			},
+		}
+	}
+
+	return []*model.SlackAttachment{{
+		Actions: []*model.PostAction{button("Approve", "approve"), button("Reject", "reject")},
+	}}
+}
+
+// approvers returns the users who can approve the item other than those who did the work, the
+// user who checked it off and its assignee: the designated users and the members of the
+// designated groups, at most maxApprovalRequests.
+func (s *PlaybookRunServiceImpl) approvers(item ChecklistItem) []string {
+	approval := item.Approval
+	seen := map[string]bool{item.ApprovalRequestedBy: true, item.AssigneeID: true}
+	var approverIDs []string
+	add := func(userID string) {
+		if !seen[userID] && len(approverIDs) < maxApprovalRequests {
+			seen[userID] = true
+			approverIDs = append(approverIDs, userID)
+		}
+	}
+
+	for _, userID := range approval.ApproverIDs {
+		add(userID)
+	}
+
+	perPage := 1000
+	for _, groupID := range approval.ApproverGroupIDs {
+		for page := 0; len(approverIDs) < maxApprovalRequests; page++ {
+			users, err := s.pluginAPI.Group.GetMemberUsers(groupID, page, perPage)
+			if err != nil {
+				s.pluginAPI.Log.Warn("failed to query group", "group_id", groupID, "err", err)
+				break
+			}
+			for _, user := range users {
+				add(user.Id)
+			}
+
+			if len(users) < perPage {
+				break
+			}
+		}
+	}
+
+	return approverIDs
+}
+
+// canApprove returns true if userID is one of the designated approvers, or a member of one of the
+// designated groups.
+func (s *PlaybookRunServiceImpl) canApprove(userID string, approval ChecklistItemApproval) bool {
+	for _, approverID := range approval.ApproverIDs {
+		if approverID == userID {
+			return true
+		}
+	}
+
+	if len(approval.ApproverGroupIDs) == 0 {
+		return false
+	}
+
+	userGroups, err := s.pluginAPI.Group.ListForUser(userID)
+	if err != nil {
+		s.pluginAPI.Log.Warn("failed to list the groups of the user", "user_id", userID, "error", err.Error())
+		return false
+	}
+	for _, userGroup := range userGroups {
+		for _, groupID := range approval.ApproverGroupIDs {
+			if userGroup.Id == groupID {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// DecideChecklistItemApproval records the sign-off, or rejection, by userID of the item waiting
+// for approval. The item is closed once enough approvers signed it off, and reopened if one of
+// them rejects it. Neither the user who checked it off nor its assignee can approve it.
+func (s *PlaybookRunServiceImpl) DecideChecklistItemApproval(playbookRunID, userID, itemID string, approve bool) error {
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve playbook run")
+	}
+
+	if playbookRun.CurrentStatus == StatusFinished {
+		return ErrPlaybookRunNotActive
+	}
+
+	checklistNumber, itemNumber := -1, -1
+	for c, checklist := range playbookRun.Checklists {
+		for i, item := range checklist.Items {
+			if item.ID == itemID {
+				checklistNumber, itemNumber = c, i
+			}
+		}
+	}
+	if checklistNumber < 0 {
+		return errors.Wrapf(ErrNotFound, "checklist item %s not found", itemID)
+	}
+
+	item := playbookRun.Checklists[checklistNumber].Items[itemNumber]
+	if item.State != ChecklistItemStatePendingApproval {
+		return errors.Wrap(ErrMalformedPlaybookRun, "the item is not waiting for approval")
+	}
+	if userID == item.ApprovalRequestedBy || userID == item.AssigneeID {
+		return errors.Wrap(ErrNoPermissions, "the approval must come from someone else than who did the work")
+	}
+	if !s.canApprove(userID, item.Approval) {
+		return errors.Wrap(ErrNoPermissions, "not an approver of the item")
+	}
+	for _, approverID := range item.ApprovedBy {
+		if approverID == userID {
+			return nil
+		}
+	}
+
+	now := model.GetMillis()
+	title := stripmd.Strip(item.Title)
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRunID,
+		CreateAt:      now,
+		EventAt:       now,
+		SubjectUserID: userID,
+	}
+
+	if approve {
+		item.ApprovedBy = append(append([]string(nil), item.ApprovedBy...), userID)
+		event.EventType = ChecklistItemApproved
+		event.Summary = fmt.Sprintf("approved **%s** (%d of %d)", title, len(item.ApprovedBy), item.Approval.requiredApprovals())
+	} else {
+		item.State = ChecklistItemStateOpen
+		item.StateModified = now
+		item.clearApproval()
+		event.EventType = ChecklistItemRejected
+		event.Summary = fmt.Sprintf("rejected **%s**", title)
+
+		if _, err = s.modificationMessage(userID, playbookRun.ChannelID, "rejected checklist item **"+title+"**"); err != nil {
+			return err
+		}
+	}
+	playbookRun.Checklists[checklistNumber].Items[itemNumber] = item
+
+	if err = s.store.UpdatePlaybookRun(playbookRun); err != nil {
+		return errors.Wrap(err, "failed to update playbook run recording the approval")
+	}
+
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create timeline event")
+	}
+
+	if approve && item.isApproved() {
+		return s.modifyCheckedState(playbookRun, userID, ChecklistItemStateClosed, checklistNumber, itemNumber)
+	}
+
+	return s.sendPlaybookRunToClient(playbookRunID)
+}
@@ -0,0 +1,159 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/telemetry"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-playbooks/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestChecklistItemApproval(t *testing.T) {
+	channelID := model.NewId()
+
+	newRun := func() *app.PlaybookRun {
+		return &app.PlaybookRun{
+			ID:            model.NewId(),
+			TeamID:        model.NewId(),
+			ChannelID:     channelID,
+			OwnerUserID:   "worker",
+			CurrentStatus: app.StatusInProgress,
+			Checklists: []app.Checklist{{
+				Title: "Production",
+				Items: []app.ChecklistItem{{
+					ID:       "migration",
+					Title:    "Run the migration",
+					Approval: app.ChecklistItemApproval{ApproverIDs: []string{"dba1", "dba2", "worker"}, RequiredApprovals: 2},
+				}},
+			}},
+		}
+	}
+
+	// setup returns the service over a store holding current, recording the timeline events and
+	// the users the approval requests are sent to.
+	setup := func(t *testing.T, current **app.PlaybookRun) (app.PlaybookRunService, *[]app.TimelineEvent, *[]string) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		configService := mock_config.NewMockService(controller)
+
+		var events []app.TimelineEvent
+		var requested []string
+
+		store.EXPECT().GetPlaybookRun((*current).ID).DoAndReturn(func(string) (*app.PlaybookRun, error) {
+			return (*current).Clone(), nil
+		}).AnyTimes()
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).DoAndReturn(func(playbookRun *app.PlaybookRun) error {
+			*current = playbookRun.Clone()
+			return nil
+		}).AnyTimes()
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+			events = append(events, *event)
+			return event, nil
+		}).AnyTimes()
+
+		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "playbooks"}).AnyTimes()
+		poster.EXPECT().PostMessage(channelID, gomock.Any(), gomock.Any()).Return(&model.Post{Id: model.NewId()}, nil).AnyTimes()
+		poster.EXPECT().PublishWebsocketEventToChannel(gomock.Any(), gomock.Any(), channelID).AnyTimes()
+		poster.EXPECT().DM(gomock.Any(), gomock.Any()).DoAndReturn(func(userID string, post *model.Post) error {
+			requested = append(requested, userID)
+			return nil
+		}).AnyTimes()
+
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		pluginAPI.On("GetConfig").Return(mattermostConfig)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(true)
+		pluginAPI.On("GetUser", mock.Anything).Return(func(userID string) *model.User {
+			return &model.User{Id: userID, Username: userID}
+		}, nil)
+
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, mock_bot.NewMockLogger(controller), configService, mock_app.NewMockJobOnceScheduler(controller), &telemetry.NoopTelemetry{}, &metrics.NoopMetrics{}, pluginAPI)
+
+		return s, &events, &requested
+	}
+
+	eventTypes := func(events []app.TimelineEvent) []string {
+		var types []string
+		for _, event := range events {
+			types = append(types, string(event.EventType))
+		}
+		return types
+	}
+
+	t.Run("checking off requests the approval, closed once enough approvers sign off", func(t *testing.T) {
+		current := newRun()
+		s, events, requested := setup(t, &current)
+
+		err := s.ModifyCheckedState(current.ID, "worker", app.ChecklistItemStateClosed, 0, 0)
+		require.NoError(t, err)
+		require.Equal(t, app.ChecklistItemStatePendingApproval, current.Checklists[0].Items[0].State)
+		require.Equal(t, "worker", current.Checklists[0].Items[0].ApprovalRequestedBy)
+		require.Equal(t, []string{"dba1", "dba2"}, *requested)
+
+		err = s.DecideChecklistItemApproval(current.ID, "worker", "migration", true)
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+
+		err = s.DecideChecklistItemApproval(current.ID, "dba1", "migration", true)
+		require.NoError(t, err)
+		require.Equal(t, app.ChecklistItemStatePendingApproval, current.Checklists[0].Items[0].State)
+
+		err = s.DecideChecklistItemApproval(current.ID, "dba2", "migration", true)
+		require.NoError(t, err)
+		require.Equal(t, app.ChecklistItemStateClosed, current.Checklists[0].Items[0].State)
+		require.Equal(t, []string{"dba1", "dba2"}, current.Checklists[0].Items[0].ApprovedBy)
+
+		require.Equal(t, []string{"approval_requested", "item_approved", "item_approved", "task_state_modified"}, eventTypes(*events))
+	})
+
+	t.Run("a rejection reopens the item", func(t *testing.T) {
+		current := newRun()
+		s, events, _ := setup(t, &current)
+
+		require.NoError(t, s.ModifyCheckedState(current.ID, "worker", app.ChecklistItemStateClosed, 0, 0))
+		require.NoError(t, s.DecideChecklistItemApproval(current.ID, "dba1", "migration", false))
+
+		item := current.Checklists[0].Items[0]
+		require.Equal(t, app.ChecklistItemStateOpen, item.State)
+		require.Empty(t, item.ApprovalRequestedBy)
+		require.Equal(t, []string{"approval_requested", "item_rejected"}, eventTypes(*events))
+
+		err := s.DecideChecklistItemApproval(current.ID, "dba2", "migration", true)
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+	})
+
+	t.Run("the assignee can't sign off the item someone else checked off", func(t *testing.T) {
+		current := newRun()
+		current.Checklists[0].Items[0].AssigneeID = "dba1"
+		s, _, requested := setup(t, &current)
+
+		require.NoError(t, s.ModifyCheckedState(current.ID, "worker", app.ChecklistItemStateClosed, 0, 0))
+		require.Equal(t, []string{"dba2"}, *requested)
+
+		err := s.DecideChecklistItemApproval(current.ID, "dba1", "migration", true)
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+	})
+
+	t.Run("only the approvers can sign off", func(t *testing.T) {
+		current := newRun()
+		s, _, _ := setup(t, &current)
+
+		require.NoError(t, s.ModifyCheckedState(current.ID, "worker", app.ChecklistItemStateClosed, 0, 0))
+		err := s.DecideChecklistItemApproval(current.ID, "someone", "migration", true)
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+	})
+}
@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	stripmd "github.com/writeas/go-strip-markdown"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ChecklistItemAutoRunOnRunStart runs the command of an item when the run starts.
+	ChecklistItemAutoRunOnRunStart = "run_start"
+
+	// ChecklistItemAutoRunAfterPreviousItem runs the command of an item when the previous item of
+	// its checklist is checked off.
+	ChecklistItemAutoRunAfterPreviousItem = "previous_item"
+
+	// ChecklistItemAutoRunAtOffset runs the command of an item AutoRunOffsetSeconds after the run
+	// starts.
+	ChecklistItemAutoRunAtOffset = "offset"
+)
+
+// AutoRunPrefix is the prefix of the keys of the jobs running the commands of checklist items at
+// a time after their run starts. The key is followed by the run and item identifiers.
+const AutoRunPrefix = "autorun_"
+
+// IsValidChecklistItemAutoRun returns true if autoRun is empty or one of the
+// ChecklistItemAutoRun constants.
+func IsValidChecklistItemAutoRun(autoRun string) bool {
+	return autoRun == "" ||
+		autoRun == ChecklistItemAutoRunOnRunStart ||
+		autoRun == ChecklistItemAutoRunAfterPreviousItem ||
+		autoRun == ChecklistItemAutoRunAtOffset
+}
+
+// checklistsHaveAutoRun returns true if the command of any item runs automatically.
+func checklistsHaveAutoRun(checklists []Checklist) bool {
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			if item.AutoRun != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startChecklistAutomation runs the commands of the items of a run that just started that run
+// when it starts, and schedules those that run at a time after it.
+func (s *PlaybookRunServiceImpl) startChecklistAutomation(playbookRunID string) {
+	// The run is read back since the store gives the checklist items their identifiers.
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to get playbook run id: %s to start its automation", playbookRunID).Error())
+		return
+	}
+
+	for i, checklist := range playbookRun.Checklists {
+		for j, item := range checklist.Items {
+			switch item.AutoRun {
+			case ChecklistItemAutoRunOnRunStart:
+				s.autoRunChecklistItem(playbookRunID, i, j)
+			case ChecklistItemAutoRunAtOffset:
+				at := time.Now().Add(time.Duration(item.AutoRunOffsetSeconds) * time.Second)
+				if _, err = s.scheduler.ScheduleOnce(AutoRunPrefix+playbookRunID+"_"+item.ID, at); err != nil {
+					s.logger.Errorf(errors.Wrapf(err, "failed to schedule the command of checklist item %s", item.ID).Error())
+				}
+			}
+		}
+	}
+}
+
+// autoRunNextChecklistItem runs the command of the item following the one that was just checked
+// off, if it waits for it.
+func (s *PlaybookRunServiceImpl) autoRunNextChecklistItem(playbookRun *PlaybookRun, checklistNumber, itemNumber int) {
+	items := playbookRun.Checklists[checklistNumber].Items
+	if itemNumber+1 >= len(items) || items[itemNumber+1].AutoRun != ChecklistItemAutoRunAfterPreviousItem {
+		return
+	}
+
+	s.autoRunChecklistItem(playbookRun.ID, checklistNumber, itemNumber+1)
+}
+
+// handleChecklistItemAutoRun runs the command of a checklist item whose time after the start of
+// its run has come. The key is the run identifier followed by the item identifier.
+func (s *PlaybookRunServiceImpl) handleChecklistItemAutoRun(key string) {
+	parts := strings.SplitN(key, "_", 2)
+	if len(parts) != 2 {
+		s.logger.Errorf("invalid checklist item automation key: %s", key)
+		return
+	}
+	playbookRunID, itemID := parts[0], parts[1]
+
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "handleChecklistItemAutoRun failed to get playbook run id: %s", playbookRunID).Error())
+		return
+	}
+
+	for i, checklist := range playbookRun.Checklists {
+		for j, item := range checklist.Items {
+			// The item may have been edited in the meantime.
+			if item.ID == itemID && item.AutoRun == ChecklistItemAutoRunAtOffset {
+				s.autoRunChecklistItem(playbookRunID, i, j)
+				return
+			}
+		}
+	}
+}
+
+// autoRunChecklistItem runs the command of the specified checklist item as its chosen user or
+// the bot, and checks the item off if it succeeds. Nothing runs if the item is already checked off
+// or the run is finished. Failures are recorded in the timeline of the run.
+func (s *PlaybookRunServiceImpl) autoRunChecklistItem(playbookRunID string, checklistNumber, itemNumber int) {
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to get playbook run id: %s to run a checklist item", playbookRunID).Error())
+		return
+	}
+
+	if !IsValidChecklistItemIndex(playbookRun.Checklists, checklistNumber, itemNumber) {
+		return
+	}
+
+	item := playbookRun.Checklists[checklistNumber].Items[itemNumber]
+	if item.State == ChecklistItemStateClosed || playbookRun.CurrentStatus == StatusFinished {
+		return
+	}
+
+	userID := item.AutoRunUserID
+	if userID == "" {
+		userID = s.configService.GetConfiguration().BotUserID
+	}
+
+	// The chosen user may have lost access to the run since the playbook was saved.
+	if item.AutoRunUserID != "" && !s.hasPermissionToModifyPlaybookRun(playbookRun, userID) {
+		err = errors.Errorf("user %s does not have permission to modify playbook run", userID)
+	} else {
+		_, err = s.runChecklistItemSlashCommand(playbookRun, userID, checklistNumber, itemNumber, true)
+	}
+	if err != nil {
+		s.logger.Warnf("failed to automatically run the command of checklist item %s of playbook run %s: %v", item.ID, playbookRunID, err)
+		s.recordFailedAutoRun(playbookRun, userID, item)
+		return
+	}
+
+	// The command itself may have modified the run.
+	playbookRun, err = s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to get playbook run id: %s to check off a checklist item", playbookRunID).Error())
+		return
+	}
+
+	if !IsValidChecklistItemIndex(playbookRun.Checklists, checklistNumber, itemNumber) {
+		return
+	}
+
+	if err = s.modifyCheckedState(playbookRun, userID, ChecklistItemStateClosed, checklistNumber, itemNumber); err != nil {
+		s.logger.Errorf(errors.Wrapf(err, "failed to check off checklist item %s", item.ID).Error())
+	}
+}
+
+// recordFailedAutoRun announces in the channel and the timeline of the run that the command of a
+// checklist item failed to run automatically.
+func (s *PlaybookRunServiceImpl) recordFailedAutoRun(playbookRun *PlaybookRun, userID string, item ChecklistItem) {
+	post, err := s.poster.PostMessage(playbookRun.ChannelID, "Failed to automatically run the slash command of checklist item **%s**.", stripmd.Strip(item.Title))
+	if err != nil {
+		s.logger.Errorf(errors.Wrap(err, "failed to post the failure of an automatic slash command").Error())
+		return
+	}
+
+	eventTime := model.GetMillis()
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRun.ID,
+		CreateAt:      eventTime,
+		EventAt:       eventTime,
+		EventType:     SlashCommandFailed,
+		Summary:       fmt.Sprintf("failed to automatically run the slash command: `%s`", item.Command),
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		s.logger.Errorf(errors.Wrap(err, "failed to create timeline event").Error())
+		return
+	}
+
+	if err = s.sendPlaybookRunToClient(playbookRun.ID); err != nil {
+		s.logger.Errorf(errors.Wrap(err, "failed to send playbook run to client").Error())
+	}
+}
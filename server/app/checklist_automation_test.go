@@ -0,0 +1,178 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/telemetry"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-playbooks/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+func TestChecklistItemAutoRun(t *testing.T) {
+	botUserID := "botuserid"
+	channelID := model.NewId()
+
+	newRun := func() *app.PlaybookRun {
+		return &app.PlaybookRun{
+			ID:            model.NewId(),
+			TeamID:        model.NewId(),
+			ChannelID:     channelID,
+			OwnerUserID:   "owneruserid",
+			CurrentStatus: app.StatusInProgress,
+			Checklists: []app.Checklist{{
+				Title: "Setup",
+				Items: []app.ChecklistItem{
+					{ID: "bridge", Title: "Create bridge", Command: "/bridge create", AutoRun: app.ChecklistItemAutoRunAtOffset, AutoRunOffsetSeconds: 60},
+					{ID: "page", Title: "Page the team", Command: "/page sre", AutoRun: app.ChecklistItemAutoRunAfterPreviousItem},
+					{ID: "announce", Title: "Announce", Command: "/announce"},
+				},
+			}},
+		}
+	}
+
+	// setup returns the service over a store holding current, recording the timeline events and
+	// the commands run.
+	setup := func(t *testing.T, current **app.PlaybookRun, executeErr error) (app.PlaybookRunService, *[]app.TimelineEvent, *[]*model.CommandArgs) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		logger := mock_bot.NewMockLogger(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		var events []app.TimelineEvent
+		var commands []*model.CommandArgs
+
+		store.EXPECT().GetPlaybookRun((*current).ID).DoAndReturn(func(string) (*app.PlaybookRun, error) {
+			return (*current).Clone(), nil
+		}).AnyTimes()
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).DoAndReturn(func(playbookRun *app.PlaybookRun) error {
+			*current = playbookRun.Clone()
+			return nil
+		}).AnyTimes()
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+			events = append(events, *event)
+			return event, nil
+		}).AnyTimes()
+
+		configService.EXPECT().GetConfiguration().Return(&config.Configuration{BotUserID: botUserID}).AnyTimes()
+		poster.EXPECT().PostMessage(channelID, gomock.Any(), gomock.Any()).Return(&model.Post{Id: model.NewId()}, nil).AnyTimes()
+		poster.EXPECT().PublishWebsocketEventToChannel(gomock.Any(), gomock.Any(), channelID).AnyTimes()
+		logger.EXPECT().Warnf(gomock.Any(), gomock.Any()).AnyTimes()
+
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		pluginAPI.On("GetConfig").Return(mattermostConfig)
+		pluginAPI.On("GetUser", botUserID).Return(&model.User{Id: botUserID, Username: "playbooks"}, nil)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("ExecuteSlashCommand", mock.Anything).Return(func(args *model.CommandArgs) *model.CommandResponse {
+			commands = append(commands, args)
+			return &model.CommandResponse{}
+		}, executeErr)
+
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, &telemetry.NoopTelemetry{}, &metrics.NoopMetrics{}, pluginAPI)
+
+		return s, &events, &commands
+	}
+
+	t.Run("runs as the bot, checks the item off and runs the next one", func(t *testing.T) {
+		current := newRun()
+		s, events, commands := setup(t, &current, nil)
+
+		s.HandleReminder(app.AutoRunPrefix + current.ID + "_bridge")
+
+		require.Len(t, *commands, 2)
+		require.Equal(t, "/bridge create", (*commands)[0].Command)
+		require.Equal(t, botUserID, (*commands)[0].UserId)
+		require.Equal(t, "/page sre", (*commands)[1].Command)
+
+		items := current.Checklists[0].Items
+		require.Equal(t, app.ChecklistItemStateClosed, items[0].State)
+		require.NotZero(t, items[0].CommandLastRun)
+		require.Equal(t, app.ChecklistItemStateClosed, items[1].State)
+		require.Equal(t, app.ChecklistItemStateOpen, items[2].State)
+
+		var types []string
+		for _, event := range *events {
+			types = append(types, string(event.EventType))
+		}
+		require.Equal(t, []string{"ran_slash_command", "task_state_modified", "ran_slash_command", "task_state_modified"}, types)
+		require.Equal(t, "automatically ran the slash command: `/bridge create`", (*events)[0].Summary)
+	})
+
+	t.Run("failure is recorded and the item stays open", func(t *testing.T) {
+		current := newRun()
+		s, events, _ := setup(t, &current, errors.New("command failed"))
+
+		s.HandleReminder(app.AutoRunPrefix + current.ID + "_bridge")
+
+		require.Equal(t, app.ChecklistItemStateOpen, current.Checklists[0].Items[0].State)
+		require.Len(t, *events, 1)
+		require.Equal(t, app.SlashCommandFailed, (*events)[0].EventType)
+		require.Equal(t, botUserID, (*events)[0].SubjectUserID)
+	})
+
+	t.Run("nothing runs once the run is finished or the item is checked off", func(t *testing.T) {
+		current := newRun()
+		current.CurrentStatus = app.StatusFinished
+		s, _, commands := setup(t, &current, nil)
+
+		s.HandleReminder(app.AutoRunPrefix + current.ID + "_bridge")
+		require.Empty(t, *commands)
+
+		current.CurrentStatus = app.StatusInProgress
+		current.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+		s.HandleReminder(app.AutoRunPrefix + current.ID + "_bridge")
+		require.Empty(t, *commands)
+	})
+
+	t.Run("only the editor of a command can run it automatically as themselves", func(t *testing.T) {
+		current := newRun()
+		current.Checklists[0].Items[0].AutoRunUserID = "alice"
+		s, _, _ := setup(t, &current, nil)
+
+		require.NoError(t, s.EditChecklistItem(current.ID, "owneruserid", 0, 0, "Create bridge", "/bridge create", "described"))
+		require.Equal(t, "alice", current.Checklists[0].Items[0].AutoRunUserID)
+
+		require.NoError(t, s.EditChecklistItem(current.ID, "owneruserid", 0, 0, "Create bridge", "/bridge delete", ""))
+		require.Equal(t, "owneruserid", current.Checklists[0].Items[0].AutoRunUserID)
+
+		err := s.AddChecklistItem(current.ID, "owneruserid", 0, app.ChecklistItem{Title: "Sneaky", Command: "/sneaky", AutoRunUserID: "alice"})
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+		require.Len(t, current.Checklists[0].Items, 3)
+	})
+
+	t.Run("items added to a run can't carry an HTTP action, an approval or a state", func(t *testing.T) {
+		current := newRun()
+		s, _, _ := setup(t, &current, nil)
+
+		require.NoError(t, s.AddChecklistItem(current.ID, "owneruserid", 0, app.ChecklistItem{
+			Title:               "Sneaky",
+			Command:             "/sneaky",
+			State:               app.ChecklistItemStateClosed,
+			Required:            true,
+			HTTPAction:          app.ChecklistItemHTTPAction{URL: "https://example.com/hook"},
+			Approval:            app.ChecklistItemApproval{ApproverIDs: []string{"owneruserid"}},
+			ApprovalRequestedBy: "alice",
+			ApprovedBy:          []string{"owneruserid"},
+		}))
+
+		require.Len(t, current.Checklists[0].Items, 4)
+		require.Equal(t, app.ChecklistItem{Title: "Sneaky", Command: "/sneaky"}, current.Checklists[0].Items[3])
+	})
+}
@@ -0,0 +1,308 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/httptools"
+)
+
+// maxHTTPActionResponseLength is the maximum length of the response body kept on the item and in
+// the timeline.
+const maxHTTPActionResponseLength = 1024
+
+// httpActionSecretPrefix is the prefix of the placeholders of the secrets in the headers of an
+// HTTP action.
+const httpActionSecretPrefix = "secret."
+
+// headerValueEscaper keeps the values substituted in a header on a single line.
+var headerValueEscaper = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+var httpActionMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// ChecklistItemHTTPAction is an HTTP request that can be performed as part of a checklist item.
+type ChecklistItemHTTPAction struct {
+	// Method is the method of the request: GET, POST, PUT, PATCH or DELETE.
+	Method string `json:"method"`
+
+	// URL is the URL of the request. Its placeholders, such as {{run.id}}, are replaced by their
+	// values, escaped as a path segment.
+	URL string `json:"url"`
+
+	// Headers are the headers of the request. Their values can use the {{secret.<name>}}
+	// placeholders, replaced by the HTTP action secrets configured by a system admin.
+	Headers []ChecklistItemHTTPActionHeader `json:"headers"`
+
+	// Body is the body of the request. Its placeholders are replaced by their values, escaped as
+	// a JSON string if the Content-Type header is JSON.
+	Body string `json:"body"`
+
+	// RequireConfirmation is true if the request is only performed once confirmed.
+	RequireConfirmation bool `json:"require_confirmation"`
+}
+
+// ChecklistItemHTTPActionHeader is a header of the HTTP action of a checklist item.
+type ChecklistItemHTTPActionHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ChecklistItemHTTPActionResult is the outcome of the HTTP action of a checklist item.
+type ChecklistItemHTTPActionResult struct {
+	// StatusCode is the status code of the response, 0 if there was no response.
+	StatusCode int `json:"status_code"`
+
+	// Response is the truncated body of the response, or the error if there was no response.
+	Response string `json:"response"`
+}
+
+// HTTPActionSecret is a value that the HTTP actions of checklist items can send in their headers.
+type HTTPActionSecret struct {
+	Name string
+
+	// URLPrefix is the prefix of the URLs the secret can be sent to.
+	URLPrefix string
+
+	Value string
+}
+
+// newHTTPActionClient returns the client performing the HTTP actions: the outbound client of the
+// server, which only reaches the internal addresses allowed in AllowedUntrustedInternalConnections,
+// and doesn't follow redirects.
+func newHTTPActionClient(pluginAPI *pluginapi.Client) *http.Client {
+	client := httptools.MakeClient(pluginAPI)
+	client.CheckRedirect = refuseHTTPActionRedirect
+	return client
+}
+
+// refuseHTTPActionRedirect keeps the redirect response as the result of an HTTP action. Following
+// it would send the secrets of the headers to a URL that wasn't checked against their prefixes.
+func refuseHTTPActionRedirect(*http.Request, []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// IsValidHTTPActionMethod returns true if method is one of the methods of the HTTP actions.
+func IsValidHTTPActionMethod(method string) bool {
+	return httpActionMethods[method]
+}
+
+// ParseHTTPActionSecrets parses the HTTPActionSecrets setting: one secret per line, as its name,
+// the URL prefix it can be sent to and its value, separated by spaces. The value is the rest of
+// the line. Empty lines, lines starting with # and incomplete lines are ignored.
+func ParseHTTPActionSecrets(setting string) map[string]HTTPActionSecret {
+	secrets := make(map[string]HTTPActionSecret)
+	for _, line := range strings.Split(setting, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		name, urlPrefix, value := fields[0], fields[1], strings.TrimSpace(fields[2])
+		if value == "" {
+			continue
+		}
+		secrets[name] = HTTPActionSecret{Name: name, URLPrefix: urlPrefix, Value: value}
+	}
+
+	return secrets
+}
+
+// httpActionHasVariables returns true if the URL, headers or body of the action have any
+// placeholder.
+func httpActionHasVariables(action ChecklistItemHTTPAction) bool {
+	if HasChecklistVariables(action.URL) || HasChecklistVariables(action.Body) {
+		return true
+	}
+	for _, header := range action.Headers {
+		if HasChecklistVariables(header.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewHTTPActionRequest returns the request of the HTTP action with its placeholders replaced by
+// values. The headers can also use the secrets whose prefix the URL starts with, and any unknown
+// placeholder in a header is an error wrapping ErrMalformedPlaybookRun rather than being sent as
+// written.
+func NewHTTPActionRequest(action ChecklistItemHTTPAction, values map[string]string, secrets map[string]HTTPActionSecret) (*http.Request, error) {
+	method := strings.ToUpper(action.Method)
+	if !IsValidHTTPActionMethod(method) {
+		return nil, errors.Wrapf(ErrMalformedPlaybookRun, "invalid HTTP action method %q", action.Method)
+	}
+
+	requestURL, _ := expandChecklistVariables(action.URL, values, url.PathEscape)
+	parsedURL, err := url.ParseRequestURI(requestURL)
+	if err != nil {
+		return nil, errors.Wrapf(ErrMalformedPlaybookRun, "invalid HTTP action URL: %v", err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, errors.Wrapf(ErrMalformedPlaybookRun, "protocol in HTTP action URL is %s; only HTTP and HTTPS are accepted", parsedURL.Scheme)
+	}
+
+	headerValues := make(map[string]string, len(values)+len(secrets))
+	for name, value := range values {
+		headerValues[name] = value
+	}
+	for name, secret := range secrets {
+		if httpActionURLHasPrefix(requestURL, secret.URLPrefix) {
+			headerValues[httpActionSecretPrefix+name] = secret.Value
+		}
+	}
+
+	header := make(http.Header)
+	for _, h := range action.Headers {
+		value, unknown := expandChecklistVariables(h.Value, headerValues, headerValueEscaper.Replace)
+		if len(unknown) != 0 {
+			return nil, errors.Wrapf(ErrMalformedPlaybookRun, "unknown placeholder %s in HTTP action header %s, or secret not allowed for this URL", unknown[0], h.Name)
+		}
+		header.Add(h.Name, value)
+	}
+
+	escape := func(value string) string { return value }
+	if strings.Contains(header.Get("Content-Type"), "json") {
+		escape = jsonStringEscape
+	}
+	body, _ := expandChecklistVariables(action.Body, values, escape)
+
+	request, err := http.NewRequest(method, requestURL, strings.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrapf(ErrMalformedPlaybookRun, "invalid HTTP action: %v", err)
+	}
+	request.Header = header
+
+	return request, nil
+}
+
+// httpActionURLHasPrefix returns true if the URL starts with the prefix, at a boundary of its host
+// or path, so that https://deploy.internal doesn't match https://deploy.internal.example.com.
+func httpActionURLHasPrefix(requestURL, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(requestURL, prefix) {
+		return false
+	}
+	if strings.HasSuffix(prefix, "/") || len(requestURL) == len(prefix) {
+		return true
+	}
+	return strings.ContainsAny(requestURL[len(prefix):len(prefix)+1], "/?#")
+}
+
+// jsonStringEscape escapes the value to be used inside a JSON string.
+func jsonStringEscape(value string) string {
+	escaped, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(escaped[1 : len(escaped)-1])
+}
+
+// readHTTPActionResponse returns the body of the response, truncated to
+// maxHTTPActionResponseLength.
+func readHTTPActionResponse(body io.Reader) string {
+	data, err := ioutil.ReadAll(io.LimitReader(body, maxHTTPActionResponseLength+1))
+	if err != nil {
+		return fmt.Sprintf("failed to read the response: %v", err)
+	}
+
+	if len(data) > maxHTTPActionResponseLength {
+		return strings.ToValidUTF8(string(data[:maxHTTPActionResponseLength]), "") + "…"
+	}
+	return strings.ToValidUTF8(string(data), "")
+}
+
+// RunChecklistItemHTTPAction performs the HTTP action of the specified checklist item, and records
+// its result on the item and in the timeline. An action that requires confirmation is only
+// performed if confirmed.
+func (s *PlaybookRunServiceImpl) RunChecklistItemHTTPAction(playbookRunID, userID string, checklistNumber, itemNumber int, confirmed bool) (ChecklistItemHTTPActionResult, error) {
+	playbookRun, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return ChecklistItemHTTPActionResult{}, err
+	}
+
+	if !IsValidChecklistItemIndex(playbookRun.Checklists, checklistNumber, itemNumber) {
+		return ChecklistItemHTTPActionResult{}, errors.New("invalid checklist item indices")
+	}
+
+	item := playbookRun.Checklists[checklistNumber].Items[itemNumber]
+	if !item.HasHTTPAction() {
+		return ChecklistItemHTTPActionResult{}, errors.Wrap(ErrMalformedPlaybookRun, "no HTTP action associated with this checklist item")
+	}
+
+	if item.HTTPAction.RequireConfirmation && !confirmed {
+		return ChecklistItemHTTPActionResult{}, errors.Wrap(ErrConfirmationRequired, "the HTTP action of this checklist item must be confirmed")
+	}
+
+	var values map[string]string
+	if httpActionHasVariables(item.HTTPAction) {
+		values = s.checklistVariables(playbookRun)
+	}
+	secrets := ParseHTTPActionSecrets(s.configService.GetConfiguration().HTTPActionSecrets)
+
+	request, err := NewHTTPActionRequest(item.HTTPAction, values, secrets)
+	if err != nil {
+		return ChecklistItemHTTPActionResult{}, err
+	}
+
+	var result ChecklistItemHTTPActionResult
+	response, err := s.httpActionClient.Do(request)
+	if err != nil {
+		result.Response = err.Error()
+	} else {
+		defer response.Body.Close()
+		result.StatusCode = response.StatusCode
+		result.Response = readHTTPActionResponse(response.Body)
+	}
+
+	now := model.GetMillis()
+	item.HTTPActionLastRun = now
+	item.HTTPActionLastStatusCode = result.StatusCode
+	item.HTTPActionLastResponse = result.Response
+	playbookRun.Checklists[checklistNumber].Items[itemNumber] = item
+	if err = s.store.UpdatePlaybookRun(playbookRun); err != nil {
+		return ChecklistItemHTTPActionResult{}, errors.Wrap(err, "failed to update playbook run recording the HTTP action")
+	}
+
+	summary := fmt.Sprintf("called `%s %s`: %d %s", request.Method, request.URL, result.StatusCode, http.StatusText(result.StatusCode))
+	if result.StatusCode == 0 {
+		summary = fmt.Sprintf("called `%s %s` without response", request.Method, request.URL)
+	}
+
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRunID,
+		CreateAt:      now,
+		EventAt:       now,
+		EventType:     RanHTTPAction,
+		Summary:       summary,
+		Details:       result.Response,
+		SubjectUserID: userID,
+	}
+
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return ChecklistItemHTTPActionResult{}, errors.Wrap(err, "failed to create timeline event")
+	}
+
+	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
+		return ChecklistItemHTTPActionResult{}, errors.Wrap(err, "failed to send playbook run to client")
+	}
+
+	return result, nil
+}
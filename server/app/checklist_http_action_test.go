@@ -0,0 +1,133 @@
+package app
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHTTPActionSecrets(t *testing.T) {
+	secrets := ParseHTTPActionSecrets(`
+# deploy service
+deploy https://deploy.internal/api Bearer abc 123
+incomplete https://example.com
+
+pager https://pager.example.com token
+`)
+
+	require.Equal(t, map[string]HTTPActionSecret{
+		"deploy": {Name: "deploy", URLPrefix: "https://deploy.internal/api", Value: "Bearer abc 123"},
+		"pager":  {Name: "pager", URLPrefix: "https://pager.example.com", Value: "token"},
+	}, secrets)
+}
+
+func TestHTTPActionURLHasPrefix(t *testing.T) {
+	for name, tc := range map[string]struct {
+		url      string
+		prefix   string
+		expected bool
+	}{
+		"same URL":              {"https://deploy.internal", "https://deploy.internal", true},
+		"path below the prefix": {"https://deploy.internal/api/v1", "https://deploy.internal", true},
+		"query":                 {"https://deploy.internal?x=1", "https://deploy.internal", true},
+		"prefix ending in /":    {"https://deploy.internal/api/v1", "https://deploy.internal/api/", true},
+		"other host":            {"https://deploy.internal.example.com", "https://deploy.internal", false},
+		"other path":            {"https://deploy.internal/apis", "https://deploy.internal/api", false},
+		"empty prefix":          {"https://deploy.internal", "", false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, httpActionURLHasPrefix(tc.url, tc.prefix))
+		})
+	}
+}
+
+func TestNewHTTPActionRequest(t *testing.T) {
+	values := map[string]string{
+		"run.id":   "run id/1",
+		"run.name": `Outage "EU"`,
+	}
+	secrets := map[string]HTTPActionSecret{
+		"deploy": {Name: "deploy", URLPrefix: "https://deploy.internal", Value: "Bearer abc"},
+	}
+
+	t.Run("placeholders and secrets", func(t *testing.T) {
+		request, err := NewHTTPActionRequest(ChecklistItemHTTPAction{
+			Method: "post",
+			URL:    "https://deploy.internal/runs/{{run.id}}",
+			Headers: []ChecklistItemHTTPActionHeader{
+				{Name: "Authorization", Value: "{{secret.deploy}}"},
+				{Name: "Content-Type", Value: "application/json"},
+			},
+			Body: `{"name": "{{run.name}}"}`,
+		}, values, secrets)
+		require.NoError(t, err)
+
+		require.Equal(t, "POST", request.Method)
+		require.Equal(t, "https://deploy.internal/runs/run%20id%2F1", request.URL.String())
+		require.Equal(t, "Bearer abc", request.Header.Get("Authorization"))
+
+		body, err := ioutil.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.Equal(t, `{"name": "Outage \"EU\""}`, string(body))
+	})
+
+	t.Run("secret not allowed for the URL", func(t *testing.T) {
+		_, err := NewHTTPActionRequest(ChecklistItemHTTPAction{
+			Method:  "GET",
+			URL:     "https://deploy.internal.example.com/steal",
+			Headers: []ChecklistItemHTTPActionHeader{{Name: "Authorization", Value: "{{secret.deploy}}"}},
+		}, values, secrets)
+		require.True(t, errors.Is(err, ErrMalformedPlaybookRun))
+	})
+
+	t.Run("unknown secret", func(t *testing.T) {
+		_, err := NewHTTPActionRequest(ChecklistItemHTTPAction{
+			Method:  "GET",
+			URL:     "https://deploy.internal",
+			Headers: []ChecklistItemHTTPActionHeader{{Name: "Authorization", Value: "{{secret.other}}"}},
+		}, values, secrets)
+		require.True(t, errors.Is(err, ErrMalformedPlaybookRun))
+	})
+
+	t.Run("secrets are not expanded in the body", func(t *testing.T) {
+		request, err := NewHTTPActionRequest(ChecklistItemHTTPAction{
+			Method: "POST",
+			URL:    "https://deploy.internal",
+			Body:   "{{secret.deploy}}",
+		}, values, secrets)
+		require.NoError(t, err)
+
+		body, err := ioutil.ReadAll(request.Body)
+		require.NoError(t, err)
+		require.Equal(t, "{{secret.deploy}}", string(body))
+	})
+
+	t.Run("invalid protocol", func(t *testing.T) {
+		_, err := NewHTTPActionRequest(ChecklistItemHTTPAction{Method: "GET", URL: "file:///etc/passwd"}, values, secrets)
+		require.True(t, errors.Is(err, ErrMalformedPlaybookRun))
+	})
+}
+
+func TestRefuseHTTPActionRedirect(t *testing.T) {
+	redirected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/elsewhere" {
+			redirected = true
+			return
+		}
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := &http.Client{CheckRedirect: refuseHTTPActionRedirect}
+	response, err := client.Get(server.URL + "/action")
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	require.Equal(t, http.StatusFound, response.StatusCode)
+	require.False(t, redirected)
+}
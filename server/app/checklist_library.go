@@ -0,0 +1,323 @@
+package app
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// ChecklistLibraryEntry is a checklist of the library of a team. The checklists of playbooks
+// can reference it instead of copying its items, so that they follow its changes.
+type ChecklistLibraryEntry struct {
+	ID string `json:"id"`
+
+	// TeamID is the team of the library. Only the playbooks of this team can reference the entry.
+	TeamID string `json:"team_id"`
+
+	// Title is the title of the checklist, also used by the playbooks referencing it.
+	Title string `json:"title"`
+
+	// Description explains what the checklist is for.
+	Description string `json:"description"`
+
+	// Items are the items of the checklist, without the state of a run.
+	Items []ChecklistItem `json:"items"`
+
+	// Version is the number of the latest version of the title and items, starting at 1 and
+	// incremented by every update.
+	Version int `json:"version"`
+
+	CreateAt int64 `json:"create_at"`
+	UpdateAt int64 `json:"update_at"`
+
+	// DeleteAt is the time the entry was archived, or 0. The playbooks referencing an archived
+	// entry keep its last items.
+	DeleteAt int64 `json:"delete_at"`
+}
+
+// ChecklistLibraryVersion is a version of the title and items of a checklist library entry.
+type ChecklistLibraryVersion struct {
+	EntryID string          `json:"entry_id"`
+	Version int             `json:"version"`
+	Title   string          `json:"title"`
+	Items   []ChecklistItem `json:"items"`
+
+	// UserID is the user who created the version.
+	UserID   string `json:"user_id"`
+	CreateAt int64  `json:"create_at"`
+}
+
+// HasLibraryChecklists returns true if any of the checklists references the checklist library.
+func HasLibraryChecklists(checklists []Checklist) bool {
+	for _, checklist := range checklists {
+		if checklist.LibraryID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// UnlinkEditedLibraryChecklists stops the checklists whose title or items differ from those of
+// the library they reference, as expanded in expanded, from referencing it: they keep the edits
+// as their own content instead of having them overwritten by the library's. A reference without
+// a title or items is not an edit.
+func UnlinkEditedLibraryChecklists(checklists, expanded []Checklist) {
+	for i := range checklists {
+		checklist := &checklists[i]
+		if checklist.LibraryID == "" || (checklist.Title == "" && len(checklist.Items) == 0) {
+			continue
+		}
+
+		if i >= len(expanded) || !sameChecklistContent(*checklist, expanded[i]) {
+			checklist.LibraryID = ""
+			checklist.LibraryVersion = 0
+		}
+	}
+}
+
+// sameChecklistContent returns true if the checklists have the same title and items, ignoring
+// the state of the items and the difference between empty and missing values.
+func sameChecklistContent(a, b Checklist) bool {
+	if a.Title != b.Title || len(a.Items) != len(b.Items) {
+		return false
+	}
+
+	contents := ChecklistsForPlaybook([]Checklist{{Items: a.Items}, {Items: b.Items}})
+	return reflect.DeepEqual(contentValue(contents[0].Items), contentValue(contents[1].Items))
+}
+
+// contentValue returns the JSON value of v without its empty values.
+func contentValue(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	return pruneEmptyValues(value)
+}
+
+// pruneEmptyValues returns value without its null, false, zero, empty string, array and object
+// values, or nil if nothing is left.
+func pruneEmptyValues(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if pruned := pruneEmptyValues(field); pruned != nil {
+				v[key] = pruned
+			} else {
+				delete(v, key)
+			}
+		}
+		if len(v) == 0 {
+			return nil
+		}
+		return v
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		// The elements keep their position, so only the empty ones are normalized.
+		for i, element := range v {
+			v[i] = pruneEmptyValues(element)
+		}
+		return v
+	case string:
+		if v == "" {
+			return nil
+		}
+	case bool:
+		if !v {
+			return nil
+		}
+	case float64:
+		if v == 0 {
+			return nil
+		}
+	}
+	return value
+}
+
+// ChecklistLibraryStore persists the checklist libraries.
+type ChecklistLibraryStore interface {
+	// Create stores a new entry with its first version, returning its ID.
+	Create(entry ChecklistLibraryEntry, userID string) (string, error)
+
+	// Get retrieves an entry, archived or not. Returns ErrNotFound if not found.
+	Get(id string) (ChecklistLibraryEntry, error)
+
+	// GetForTeam retrieves the entries of the library of a team that are not archived,
+	// sorted by title.
+	GetForTeam(teamID string) ([]ChecklistLibraryEntry, error)
+
+	// Update stores the title, description and items of an entry as a new version, returning
+	// its number.
+	Update(entry ChecklistLibraryEntry, userID string) (int, error)
+
+	// Delete archives an entry.
+	Delete(id string) error
+
+	// GetVersion retrieves a version of an entry. Returns ErrNotFound if not found.
+	GetVersion(id string, version int) (ChecklistLibraryVersion, error)
+
+	// GetVersions retrieves all the versions of an entry, newest first.
+	GetVersions(id string) ([]ChecklistLibraryVersion, error)
+}
+
+// ChecklistLibraryService manages the checklist libraries of the teams, and expands the
+// checklists of the playbooks referencing them.
+type ChecklistLibraryService interface {
+	// Create creates a new entry, returning its ID.
+	Create(entry ChecklistLibraryEntry, userID string) (string, error)
+
+	// Get retrieves an entry, archived or not. Returns ErrNotFound if not found.
+	Get(id string) (ChecklistLibraryEntry, error)
+
+	// GetForTeam retrieves the entries of the library of a team that are not archived.
+	GetForTeam(teamID string) ([]ChecklistLibraryEntry, error)
+
+	// Update saves the title, description and items of an entry as a new version. The playbooks
+	// referencing the entry without pinning a version follow the change.
+	Update(entry ChecklistLibraryEntry, userID string) error
+
+	// Delete archives an entry.
+	Delete(entry ChecklistLibraryEntry, userID string) error
+
+	// GetVersion retrieves a version of an entry. Returns ErrNotFound if not found.
+	GetVersion(id string, version int) (ChecklistLibraryVersion, error)
+
+	// GetVersions retrieves all the versions of an entry, newest first.
+	GetVersions(id string) ([]ChecklistLibraryVersion, error)
+
+	// ValidateReferences returns an error wrapping ErrMalformedPlaybook if the checklists of a
+	// playbook of the team reference an entry of another team, or that doesn't exist, or a
+	// version that doesn't exist.
+	ValidateReferences(teamID string, checklists []Checklist) error
+
+	// ExpandChecklists returns the checklists with the title and items of those referencing the
+	// library of the team replaced by the ones of the entry, at the pinned version if any, or
+	// the latest one. The references to archived or unknown entries are left as they are.
+	ExpandChecklists(teamID string, checklists []Checklist) ([]Checklist, error)
+}
+
+type checklistLibraryService struct {
+	store ChecklistLibraryStore
+}
+
+// NewChecklistLibraryService returns a ChecklistLibraryService backed by the given store.
+func NewChecklistLibraryService(store ChecklistLibraryStore) ChecklistLibraryService {
+	return &checklistLibraryService{
+		store: store,
+	}
+}
+
+func (s *checklistLibraryService) Create(entry ChecklistLibraryEntry, userID string) (string, error) {
+	if entry.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+
+	entry.Version = 1
+	entry.CreateAt = model.GetMillis()
+	entry.UpdateAt = entry.CreateAt
+	entry.DeleteAt = 0
+
+	return s.store.Create(entry, userID)
+}
+
+func (s *checklistLibraryService) Get(id string) (ChecklistLibraryEntry, error) {
+	return s.store.Get(id)
+}
+
+func (s *checklistLibraryService) GetForTeam(teamID string) ([]ChecklistLibraryEntry, error) {
+	return s.store.GetForTeam(teamID)
+}
+
+func (s *checklistLibraryService) Update(entry ChecklistLibraryEntry, userID string) error {
+	if entry.ID == "" {
+		return errors.New("can't update a checklist library entry without an ID")
+	}
+
+	entry.UpdateAt = model.GetMillis()
+
+	_, err := s.store.Update(entry, userID)
+	return err
+}
+
+func (s *checklistLibraryService) Delete(entry ChecklistLibraryEntry, userID string) error {
+	if entry.ID == "" {
+		return errors.New("can't delete a checklist library entry without an ID")
+	}
+
+	return s.store.Delete(entry.ID)
+}
+
+func (s *checklistLibraryService) GetVersion(id string, version int) (ChecklistLibraryVersion, error) {
+	return s.store.GetVersion(id, version)
+}
+
+func (s *checklistLibraryService) GetVersions(id string) ([]ChecklistLibraryVersion, error) {
+	return s.store.GetVersions(id)
+}
+
+func (s *checklistLibraryService) ValidateReferences(teamID string, checklists []Checklist) error {
+	for i, checklist := range checklists {
+		if checklist.LibraryID == "" {
+			continue
+		}
+
+		entry, err := s.store.Get(checklist.LibraryID)
+		if errors.Is(err, ErrNotFound) || (err == nil && entry.TeamID != teamID) {
+			return errors.Wrapf(ErrMalformedPlaybook, "checklist %d references the unknown library checklist %s", i, checklist.LibraryID)
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to get library checklist %s", checklist.LibraryID)
+		}
+
+		if checklist.LibraryVersion < 0 || checklist.LibraryVersion > entry.Version {
+			return errors.Wrapf(ErrMalformedPlaybook, "checklist %d references the unknown version %d of the library checklist %s", i, checklist.LibraryVersion, checklist.LibraryID)
+		}
+	}
+
+	return nil
+}
+
+func (s *checklistLibraryService) ExpandChecklists(teamID string, checklists []Checklist) ([]Checklist, error) {
+	expanded := make([]Checklist, 0, len(checklists))
+	for _, checklist := range checklists {
+		if checklist.LibraryID == "" {
+			expanded = append(expanded, checklist)
+			continue
+		}
+
+		entry, err := s.store.Get(checklist.LibraryID)
+		if errors.Is(err, ErrNotFound) || (err == nil && (entry.TeamID != teamID || entry.DeleteAt != 0)) {
+			expanded = append(expanded, checklist)
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get library checklist %s", checklist.LibraryID)
+		}
+
+		title, items := entry.Title, entry.Items
+		if checklist.LibraryVersion > 0 && checklist.LibraryVersion != entry.Version {
+			version, versionErr := s.store.GetVersion(checklist.LibraryID, checklist.LibraryVersion)
+			if errors.Is(versionErr, ErrNotFound) {
+				expanded = append(expanded, checklist)
+				continue
+			} else if versionErr != nil {
+				return nil, errors.Wrapf(versionErr, "failed to get version %d of library checklist %s", checklist.LibraryVersion, checklist.LibraryID)
+			}
+			title, items = version.Title, version.Items
+		}
+
+		checklist.Title = title
+		checklist.Items = append([]ChecklistItem(nil), items...)
+		expanded = append(expanded, checklist)
+	}
+
+	return expanded, nil
+}
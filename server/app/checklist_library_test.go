@@ -0,0 +1,146 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+)
+
+func TestChecklistLibraryValidateReferences(t *testing.T) {
+	entry := app.ChecklistLibraryEntry{
+		ID:      "entry1",
+		TeamID:  "team1",
+		Title:   "Triage",
+		Items:   []app.ChecklistItem{{Title: "Page on-call"}},
+		Version: 3,
+	}
+
+	for name, tc := range map[string]struct {
+		checklist app.Checklist
+		malformed bool
+	}{
+		"latest version":           {checklist: app.Checklist{LibraryID: "entry1"}},
+		"pinned version":           {checklist: app.Checklist{LibraryID: "entry1", LibraryVersion: 2}},
+		"unknown version":          {checklist: app.Checklist{LibraryID: "entry1", LibraryVersion: 4}, malformed: true},
+		"negative version":         {checklist: app.Checklist{LibraryID: "entry1", LibraryVersion: -1}, malformed: true},
+		"unknown entry":            {checklist: app.Checklist{LibraryID: "unknown"}, malformed: true},
+		"entry of another team":    {checklist: app.Checklist{LibraryID: "entry2"}, malformed: true},
+		"no reference to validate": {checklist: app.Checklist{Title: "Own checklist"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			store := mock_app.NewMockChecklistLibraryStore(gomock.NewController(t))
+			store.EXPECT().Get("entry1").Return(entry, nil).AnyTimes()
+			store.EXPECT().Get("entry2").Return(app.ChecklistLibraryEntry{ID: "entry2", TeamID: "team2", Version: 1}, nil).AnyTimes()
+			store.EXPECT().Get("unknown").Return(app.ChecklistLibraryEntry{}, errors.Wrap(app.ErrNotFound, "not found")).AnyTimes()
+
+			err := app.NewChecklistLibraryService(store).ValidateReferences("team1", []app.Checklist{tc.checklist})
+			if tc.malformed {
+				require.True(t, errors.Is(err, app.ErrMalformedPlaybook))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("store error", func(t *testing.T) {
+		store := mock_app.NewMockChecklistLibraryStore(gomock.NewController(t))
+		store.EXPECT().Get("entry1").Return(app.ChecklistLibraryEntry{}, errors.New("store error"))
+
+		err := app.NewChecklistLibraryService(store).ValidateReferences("team1", []app.Checklist{{LibraryID: "entry1"}})
+		require.Error(t, err)
+		require.False(t, errors.Is(err, app.ErrMalformedPlaybook))
+	})
+}
+
+func TestChecklistLibraryExpandChecklists(t *testing.T) {
+	latest := app.ChecklistLibraryEntry{
+		ID:      "entry1",
+		TeamID:  "team1",
+		Title:   "Triage v2",
+		Items:   []app.ChecklistItem{{Title: "Page on-call"}, {Title: "Open incident doc"}},
+		Version: 2,
+	}
+	first := app.ChecklistLibraryVersion{
+		EntryID: "entry1",
+		Version: 1,
+		Title:   "Triage",
+		Items:   []app.ChecklistItem{{Title: "Page on-call"}},
+	}
+	stored := app.Checklist{Title: "Stored", Items: []app.ChecklistItem{{Title: "Stored item"}}}
+
+	t.Run("follows the latest version and keeps the own checklists", func(t *testing.T) {
+		store := mock_app.NewMockChecklistLibraryStore(gomock.NewController(t))
+		store.EXPECT().Get("entry1").Return(latest, nil)
+
+		reference := stored
+		reference.LibraryID = "entry1"
+		own := app.Checklist{Title: "Own", Items: []app.ChecklistItem{{Title: "Own item"}}}
+
+		expanded, err := app.NewChecklistLibraryService(store).ExpandChecklists("team1", []app.Checklist{own, reference})
+		require.NoError(t, err)
+		require.Equal(t, []app.Checklist{
+			own,
+			{Title: "Triage v2", Items: latest.Items, LibraryID: "entry1"},
+		}, expanded)
+	})
+
+	t.Run("uses the pinned version", func(t *testing.T) {
+		store := mock_app.NewMockChecklistLibraryStore(gomock.NewController(t))
+		store.EXPECT().Get("entry1").Return(latest, nil)
+		store.EXPECT().GetVersion("entry1", 1).Return(first, nil)
+
+		reference := stored
+		reference.LibraryID = "entry1"
+		reference.LibraryVersion = 1
+
+		expanded, err := app.NewChecklistLibraryService(store).ExpandChecklists("team1", []app.Checklist{reference})
+		require.NoError(t, err)
+		require.Equal(t, []app.Checklist{
+			{Title: "Triage", Items: first.Items, LibraryID: "entry1", LibraryVersion: 1},
+		}, expanded)
+	})
+
+	t.Run("keeps the stored items of archived, unknown or foreign entries", func(t *testing.T) {
+		store := mock_app.NewMockChecklistLibraryStore(gomock.NewController(t))
+		archived := latest
+		archived.DeleteAt = 1000
+		store.EXPECT().Get("entry1").Return(archived, nil)
+		store.EXPECT().Get("entry2").Return(app.ChecklistLibraryEntry{ID: "entry2", TeamID: "team2", Version: 1}, nil)
+		store.EXPECT().Get("unknown").Return(app.ChecklistLibraryEntry{}, errors.Wrap(app.ErrNotFound, "not found"))
+
+		checklists := []app.Checklist{stored, stored, stored}
+		checklists[0].LibraryID = "entry1"
+		checklists[1].LibraryID = "entry2"
+		checklists[2].LibraryID = "unknown"
+
+		expanded, err := app.NewChecklistLibraryService(store).ExpandChecklists("team1", checklists)
+		require.NoError(t, err)
+		require.Equal(t, checklists, expanded)
+	})
+}
+
+func TestUnlinkEditedLibraryChecklists(t *testing.T) {
+	expanded := []app.Checklist{
+		{Title: "Own"},
+		{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page on-call", Approval: app.ChecklistItemApproval{ApproverIDs: []string{}}}}, LibraryID: "entry1"},
+		{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page on-call"}}, LibraryID: "entry1"},
+		{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page on-call"}}, LibraryID: "entry1", LibraryVersion: 1},
+	}
+
+	checklists := []app.Checklist{
+		{Title: "Own"},
+		{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page on-call", State: app.ChecklistItemStateOpen}}, LibraryID: "entry1"},
+		{LibraryID: "entry1"},
+		{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page the on-call"}}, LibraryID: "entry1", LibraryVersion: 1},
+	}
+
+	app.UnlinkEditedLibraryChecklists(checklists, expanded)
+	require.Equal(t, "entry1", checklists[1].LibraryID)
+	require.Equal(t, "entry1", checklists[2].LibraryID)
+	require.Equal(t, app.Checklist{Title: "Triage", Items: []app.ChecklistItem{{Title: "Page the on-call"}}}, checklists[3])
+}
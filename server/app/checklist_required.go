@@ -0,0 +1,47 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DialogFieldOverrideRequiredItemsKey is the key of the checkbox of the finish run dialog that
+// finishes the run even though some required items are not done.
+const DialogFieldOverrideRequiredItemsKey = "override_required_items"
+
+// OpenRequiredItem is a required checklist item of a run that is not done yet.
+type OpenRequiredItem struct {
+	// Checklist and Item are the zero-based indices of the item.
+	Checklist int `json:"checklist"`
+	Item      int `json:"item"`
+
+	ChecklistTitle string `json:"checklist_title"`
+	Title          string `json:"title"`
+}
+
+// OpenRequiredItems returns the required items of the checklists that are not closed, in order.
+func OpenRequiredItems(checklists []Checklist) []OpenRequiredItem {
+	var open []OpenRequiredItem
+	for c, checklist := range checklists {
+		for i, item := range checklist.Items {
+			if item.Required && item.State != ChecklistItemStateClosed {
+				open = append(open, OpenRequiredItem{
+					Checklist:      c,
+					Item:           i,
+					ChecklistTitle: checklist.Title,
+					Title:          item.Title,
+				})
+			}
+		}
+	}
+	return open
+}
+
+// FormatOpenRequiredItems returns the items as a Markdown list, one line per item.
+func FormatOpenRequiredItems(items []OpenRequiredItem) string {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("- %s: %s", item.ChecklistTitle, item.Title))
+	}
+	return strings.Join(lines, "\n")
+}
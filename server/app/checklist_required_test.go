@@ -0,0 +1,35 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenRequiredItems(t *testing.T) {
+	checklists := []Checklist{
+		{
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{Title: "Page on-call", Required: true, State: ChecklistItemStateClosed},
+				{Title: "Notify the regulator", Required: true, State: ChecklistItemStateInProgress},
+				{Title: "Update the status page"},
+			},
+		},
+		{
+			Title: "Closure",
+			Items: []ChecklistItem{
+				{Title: "Archive the evidence", Required: true},
+			},
+		},
+	}
+
+	open := OpenRequiredItems(checklists)
+	require.Equal(t, []OpenRequiredItem{
+		{Checklist: 0, Item: 1, ChecklistTitle: "Triage", Title: "Notify the regulator"},
+		{Checklist: 1, Item: 0, ChecklistTitle: "Closure", Title: "Archive the evidence"},
+	}, open)
+	require.Equal(t, "- Triage: Notify the regulator\n- Closure: Archive the evidence", FormatOpenRequiredItems(open))
+
+	require.Empty(t, OpenRequiredItems(checklists[:0]))
+}
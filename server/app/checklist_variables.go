@@ -0,0 +1,195 @@
+package app
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// maxCustomDataKeys is the maximum number of keys of the custom data of a run.
+	maxCustomDataKeys = 64
+
+	// maxCustomDataKeyLength and maxCustomDataValueLength limit the size of the custom data.
+	maxCustomDataKeyLength   = 64
+	maxCustomDataValueLength = 1024
+)
+
+var (
+	checklistVariableNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z0-9_]+)*$`)
+	customDataKeyRegex         = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+	// commandValueEscaper escapes the values substituted in a slash command so that they can be
+	// used inside a double-quoted argument and don't span several lines.
+	commandValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r\n", " ", "\n", " ", "\r", " ")
+
+	// commandWordRegex matches the values that can be substituted outside double quotes in a
+	// slash command as they are: a single word that can't be taken for an option.
+	commandWordRegex = regexp.MustCompile(`^([A-Za-z0-9_.:/@+=,][A-Za-z0-9_.:/@+=,-]*)?$`)
+)
+
+// ChecklistItemCommandPreview is the slash command of a checklist item with its placeholders
+// resolved, as it would run now.
+type ChecklistItemCommandPreview struct {
+	// Command is the command as written in the item.
+	Command string `json:"command"`
+
+	// ResolvedCommand is the command with the placeholders replaced by their values.
+	ResolvedCommand string `json:"resolved_command"`
+
+	// UnknownVariables are the placeholders without a value, left as written.
+	UnknownVariables []string `json:"unknown_variables"`
+}
+
+// HasChecklistVariables returns true if the text has any placeholder to expand or escape.
+func HasChecklistVariables(text string) bool {
+	return strings.Contains(text, "{{")
+}
+
+// ExpandChecklistVariables replaces the placeholders of the text, written {{name}}, by their
+// values, returning the names of those without a value, which are left as written. A placeholder
+// can be written literally by escaping it as \{{name}}. In a slash command, the values have their
+// line breaks replaced by spaces and their backslashes and double quotes escaped, so that
+// --title "{{run.name}}" stays a single argument whatever the name of the run. Outside double
+// quotes, a value that isn't a single word is quoted as well, so that it can't add arguments or
+// options to the command.
+func ExpandChecklistVariables(text string, values map[string]string, command bool) (string, []string) {
+	if !command {
+		return expandChecklistVariables(text, values, func(value string) string { return value })
+	}
+
+	return expandPlaceholders(text, values, commandValue)
+}
+
+// commandValue escapes a value substituted in a slash command, inside double quotes or not, as
+// described in ExpandChecklistVariables.
+func commandValue(value string, quoted bool) string {
+	escaped := commandValueEscaper.Replace(value)
+	if quoted || commandWordRegex.MatchString(value) {
+		return escaped
+	}
+
+	return `"` + escaped + `"`
+}
+
+// expandChecklistVariables replaces the placeholders of the text by their values, escaped with
+// escape, as described in ExpandChecklistVariables.
+func expandChecklistVariables(text string, values map[string]string, escape func(string) string) (string, []string) {
+	return expandPlaceholders(text, values, func(value string, _ bool) string { return escape(value) })
+}
+
+// expandPlaceholders replaces the placeholders of the text by their values, escaped with escape,
+// which is told whether the placeholder is inside double quotes.
+func expandPlaceholders(text string, values map[string]string, escape func(value string, quoted bool) string) (string, []string) {
+	if !HasChecklistVariables(text) {
+		return text, nil
+	}
+
+	var result strings.Builder
+	var unknown []string
+	seen := make(map[string]bool)
+	quoted := false
+
+	for i := 0; i < len(text); {
+		if strings.HasPrefix(text[i:], `\{{`) {
+			result.WriteString("{{")
+			i += 3
+			continue
+		}
+
+		if !strings.HasPrefix(text[i:], "{{") {
+			switch {
+			case strings.HasPrefix(text[i:], `\"`):
+				result.WriteString(`\"`)
+				i += 2
+				continue
+			case text[i] == '"':
+				quoted = !quoted
+			}
+			result.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		end := strings.Index(text[i+2:], "}}")
+		if end < 0 {
+			result.WriteString(text[i:])
+			break
+		}
+
+		placeholder := text[i : i+2+end+2]
+		name := strings.TrimSpace(text[i+2 : i+2+end])
+		i += len(placeholder)
+
+		value, ok := values[name]
+		if !ok {
+			if checklistVariableNameRegex.MatchString(name) && !seen[name] {
+				seen[name] = true
+				unknown = append(unknown, name)
+			}
+			result.WriteString(placeholder)
+			continue
+		}
+
+		result.WriteString(escape(value, quoted))
+	}
+
+	return result.String(), unknown
+}
+
+// ExpandChecklistsVariables returns the checklists with the placeholders of the titles and
+// descriptions of their items replaced by their values. The commands are left as written, to be
+// expanded when they run.
+func ExpandChecklistsVariables(checklists []Checklist, values map[string]string) []Checklist {
+	expanded := make([]Checklist, 0, len(checklists))
+	for _, checklist := range checklists {
+		checklist = checklist.Clone()
+		for i := range checklist.Items {
+			checklist.Items[i].Title, _ = ExpandChecklistVariables(checklist.Items[i].Title, values, false)
+			checklist.Items[i].Description, _ = ExpandChecklistVariables(checklist.Items[i].Description, values, false)
+		}
+		expanded = append(expanded, checklist)
+	}
+
+	return expanded
+}
+
+// checklistsHaveVariables returns true if the title or description of any item has a
+// placeholder.
+func checklistsHaveVariables(checklists []Checklist) bool {
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			if HasChecklistVariables(item.Title) || HasChecklistVariables(item.Description) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateCustomData returns an error wrapping ErrMalformedPlaybookRun if the custom data of a
+// run has too many keys, or keys that can't be used as {{custom.<key>}} placeholders.
+func ValidateCustomData(data map[string]string) error {
+	if len(data) > maxCustomDataKeys {
+		return errors.Wrapf(ErrMalformedPlaybookRun, "custom data has more than %d keys", maxCustomDataKeys)
+	}
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if len(key) > maxCustomDataKeyLength || !customDataKeyRegex.MatchString(key) {
+			return errors.Wrapf(ErrMalformedPlaybookRun, "invalid custom data key %q: only letters, digits and underscores are allowed, up to %d characters", key, maxCustomDataKeyLength)
+		}
+		if len(data[key]) > maxCustomDataValueLength {
+			return errors.Wrapf(ErrMalformedPlaybookRun, "the value of custom data key %q is longer than %d characters", key, maxCustomDataValueLength)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandChecklistVariables(t *testing.T) {
+	values := map[string]string{
+		"run.name":       `Outage "EU" \ west`,
+		"channel.name":   "outage-eu",
+		"custom.ticket":  "OPS-42",
+		"run.summary":    "first line\nsecond line",
+		"custom.options": "--force",
+		"custom.empty":   "",
+	}
+
+	for name, tc := range map[string]struct {
+		text     string
+		command  bool
+		expected string
+		unknown  []string
+	}{
+		"no placeholders": {
+			text:     "Page on-call",
+			expected: "Page on-call",
+		},
+		"title": {
+			text:     "Update {{custom.ticket}} for {{ run.name }}",
+			expected: `Update OPS-42 for Outage "EU" \ west`,
+		},
+		"command escapes the values for double quotes": {
+			text:     `/jira create --title "{{run.name}}" --channel {{channel.name}}`,
+			command:  true,
+			expected: `/jira create --title "Outage \"EU\" \\ west" --channel outage-eu`,
+		},
+		"command quotes the values outside double quotes that aren't a single word": {
+			text:     `/jira assign {{custom.ticket}} {{run.name}} {{custom.options}} {{custom.empty}}`,
+			command:  true,
+			expected: `/jira assign OPS-42 "Outage \"EU\" \\ west" "--force" `,
+		},
+		"command tracks the escaped double quotes": {
+			text:     `/echo "say \"{{run.name}}\"" {{run.summary}}`,
+			command:  true,
+			expected: `/echo "say \"Outage \"EU\" \\ west\"" "first line second line"`,
+		},
+		"command values stay on one line": {
+			text:     `/echo "{{run.summary}}"`,
+			command:  true,
+			expected: `/echo "first line second line"`,
+		},
+		"title values keep their line breaks": {
+			text:     "{{run.summary}}",
+			expected: "first line\nsecond line",
+		},
+		"unknown placeholders are left as written": {
+			text:     "/grafana snapshot {{custom.dashboard}} {{owner.username}} {{custom.dashboard}}",
+			command:  true,
+			expected: "/grafana snapshot {{custom.dashboard}} {{owner.username}} {{custom.dashboard}}",
+			unknown:  []string{"custom.dashboard", "owner.username"},
+		},
+		"escaped placeholders": {
+			text:     `Write \{{custom.ticket}} in the doc, not {{custom.ticket}}`,
+			expected: "Write {{custom.ticket}} in the doc, not OPS-42",
+		},
+		"text that isn't a placeholder": {
+			text:     "Use {{ a map }} or {{ unclosed",
+			expected: "Use {{ a map }} or {{ unclosed",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, unknown := ExpandChecklistVariables(tc.text, values, tc.command)
+			require.Equal(t, tc.expected, actual)
+			require.Equal(t, tc.unknown, unknown)
+		})
+	}
+}
+
+func TestExpandChecklistsVariables(t *testing.T) {
+	checklists := []Checklist{
+		{
+			Title: "Setup",
+			Items: []ChecklistItem{
+				{Title: "Open {{custom.ticket}}", Description: "In {{channel.name}}", Command: "/jira view {{custom.ticket}}"},
+			},
+		},
+	}
+
+	expanded := ExpandChecklistsVariables(checklists, map[string]string{"custom.ticket": "OPS-42", "channel.name": "outage-eu"})
+	require.Equal(t, []Checklist{
+		{
+			Title: "Setup",
+			Items: []ChecklistItem{
+				{Title: "Open OPS-42", Description: "In outage-eu", Command: "/jira view {{custom.ticket}}"},
+			},
+		},
+	}, expanded)
+
+	// The original checklists are left untouched.
+	require.Equal(t, "Open {{custom.ticket}}", checklists[0].Items[0].Title)
+}
+
+func TestValidateCustomData(t *testing.T) {
+	require.NoError(t, ValidateCustomData(nil))
+	require.NoError(t, ValidateCustomData(map[string]string{"ticket": "OPS-42", "region_2": "eu"}))
+
+	for name, data := range map[string]map[string]string{
+		"key with a dot":   {"ticket.id": "OPS-42"},
+		"key with a space": {"ticket id": "OPS-42"},
+		"empty key":        {"": "OPS-42"},
+		"long key":         {strings.Repeat("k", maxCustomDataKeyLength+1): "OPS-42"},
+		"long value":       {"ticket": strings.Repeat("v", maxCustomDataValueLength+1)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateCustomData(data)
+			require.True(t, errors.Is(err, ErrMalformedPlaybookRun))
+		})
+	}
+
+	tooMany := make(map[string]string)
+	for i := 0; i <= maxCustomDataKeys; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+	require.True(t, errors.Is(ValidateCustomData(tooMany), ErrMalformedPlaybookRun))
+}
@@ -20,5 +20,16 @@ var ErrPlaybookRunActive = errors.New("already active")
 // ErrMalformedPlaybookRun occurs when a playbook run is not valid.
 var ErrMalformedPlaybookRun = errors.New("malformed")
 
+// ErrMalformedPlaybook occurs when a playbook is not valid.
+var ErrMalformedPlaybook = errors.New("malformed playbook")
+
+// ErrConfirmationRequired occurs when performing an action that must be confirmed without
+// confirming it.
+var ErrConfirmationRequired = errors.New("confirmation required")
+
+// ErrRequiredItemsOpen occurs when finishing a playbook run whose required checklist items are not
+// all done, without overriding them.
+var ErrRequiredItemsOpen = errors.New("required checklist items are not done")
+
 // ErrDuplicateEntry occurs when failing to insert because the entry already existed.
 var ErrDuplicateEntry = errors.New("duplicate entry")
@@ -1,10 +1,32 @@
 package app
 
 import (
+	"sort"
+	"sync"
+	"time"
+
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/pkg/errors"
 )
 
+const (
+	// KeywordsCacheInvalidateEventID is the ID of the cluster event telling the other servers
+	// to drop their keywords cache.
+	KeywordsCacheInvalidateEventID = "keywords_cache_invalidate"
+
+	// keywordsCacheMaxAge bounds how long the cache is trusted without an invalidation, so a
+	// lost cluster event can't leave a server with stale keywords forever.
+	keywordsCacheMaxAge = 10 * time.Minute
+
+	// keywordsCacheRetryInterval is how long to keep serving the previous cache after a failed
+	// reload, instead of hitting the store again for every post.
+	keywordsCacheRetryInterval = 10 * time.Second
+
+	// keywordsCachePerPage is the page size used to load the playbooks with keywords.
+	keywordsCachePerPage = 1000
+)
+
 type CachedPlaybook struct {
 	ID                string
 	Title             string
@@ -12,69 +34,294 @@ type CachedPlaybook struct {
 	SignalAnyKeywords []string
 }
 
-type KeywordsCacherImpl struct {
-	updateAt  int64
+// KeywordsMatch is a playbook triggered by a message, along with the keywords that triggered it.
+type KeywordsMatch struct {
+	Playbook *CachedPlaybook
+	Triggers []string
+}
+
+// ClusterEventPublisher broadcasts plugin events to the other servers in the cluster.
+type ClusterEventPublisher interface {
+	PublishPluginClusterEvent(ev model.PluginClusterEvent, opts model.PluginClusterEventSendOptions) error
+}
+
+type KeywordsCacher interface {
+	// Match returns the playbooks of teamID, or shared with it, with keywords occurring in
+	// message, in the order they were loaded from the store.
+	Match(teamID, message string) []KeywordsMatch
+
+	// Invalidate drops the cache on this server and tells the other servers in the cluster to
+	// do the same. Call it whenever a playbook's keywords may have changed.
+	Invalidate()
+
+	// InvalidateLocal drops the cache on this server only, in response to another server's
+	// Invalidate.
+	InvalidateLocal()
+}
+
+// teamKeywords holds the cached playbooks of a single team. It is immutable once built.
+type teamKeywords struct {
 	playbooks []*CachedPlaybook
+	matcher   *keywordsMatcher
+
+	// keywordPlaybooks maps each keyword index of matcher to the indices of the playbooks
+	// using that keyword.
+	keywordPlaybooks [][]int
+}
+
+// KeywordsCacherImpl caches the playbooks with keywords enabled, grouped by team. The cache is
+// loaded lazily and reloaded after an invalidation, it's safe for concurrent use.
+type KeywordsCacherImpl struct {
 	store     PlaybookStore
+	publisher ClusterEventPublisher
 	logger    pluginapi.LogService
-}
 
-type KeywordsCacher interface {
-	GetPlaybooks() []*CachedPlaybook
+	// loadMutex makes sure a single goroutine reloads the cache at a time.
+	loadMutex sync.Mutex
+
+	// mutex protects the fields below.
+	mutex            sync.RWMutex
+	teams            map[string]*teamKeywords
+	allTeams         *teamKeywords
+	generation       uint64
+	loadedGeneration uint64
+	loadedAt         time.Time
+	retryAt          time.Time
 }
 
 // Ensure KeywordsCacherImpl implements the KeywordsCacher interface.
 var _ KeywordsCacher = (*KeywordsCacherImpl)(nil)
 
-func NewPlaybookKeywordsCacher(store PlaybookStore, log pluginapi.LogService) KeywordsCacher {
+func NewPlaybookKeywordsCacher(store PlaybookStore, publisher ClusterEventPublisher, log pluginapi.LogService) KeywordsCacher {
 	return &KeywordsCacherImpl{
-		store:     store,
-		playbooks: []*CachedPlaybook{},
-		updateAt:  0,
-		logger:    log,
+		store:      store,
+		publisher:  publisher,
+		logger:     log,
+		teams:      map[string]*teamKeywords{},
+		generation: 1,
 	}
 }
 
-// GetPlaybooks gets cached playbooks
-func (pc *KeywordsCacherImpl) GetPlaybooks() []*CachedPlaybook {
-	if err := pc.updatePlaybooksIfNeeded(); err != nil {
-		pc.logger.Error("can't update playbooks", "err", err.Error())
+// Match returns the playbooks of teamID triggered by message.
+func (pc *KeywordsCacherImpl) Match(teamID, message string) []KeywordsMatch {
+	pc.loadIfNeeded()
+
+	pc.mutex.RLock()
+	team, ok := pc.teams[teamID]
+	if !ok {
+		// The team has no playbooks of its own, but may have some shared with all teams.
+		team = pc.allTeams
+	}
+	pc.mutex.RUnlock()
+
+	if team == nil {
+		return nil
 	}
-	// even if error occurs while updating still returning previously cached playbooks
-	return pc.playbooks
+
+	return team.match(message)
 }
 
-// updatePlaybooksIfNeeded caches playbooks
-func (pc *KeywordsCacherImpl) updatePlaybooksIfNeeded() error {
-	lastUpdatedDB, err := pc.store.GetTimeLastUpdated(true)
+// Invalidate drops the cache on every server in the cluster.
+func (pc *KeywordsCacherImpl) Invalidate() {
+	pc.InvalidateLocal()
+
+	err := pc.publisher.PublishPluginClusterEvent(
+		model.PluginClusterEvent{Id: KeywordsCacheInvalidateEventID},
+		model.PluginClusterEventSendOptions{SendType: model.PluginClusterEventSendTypeReliable},
+	)
 	if err != nil {
-		return errors.Wrap(err, "can't get time last updated")
+		pc.logger.Warn("can't publish keywords cache invalidation", "err", err.Error())
 	}
-	if lastUpdatedDB > pc.updateAt {
-		if err := pc.update(); err != nil {
-			return errors.Wrap(err, "can't update cache")
-		}
+}
+
+// InvalidateLocal drops the cache on this server, the next Match reloads it.
+func (pc *KeywordsCacherImpl) InvalidateLocal() {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	pc.generation++
+	pc.retryAt = time.Time{}
+}
+
+func (pc *KeywordsCacherImpl) needsLoad() bool {
+	pc.mutex.RLock()
+	defer pc.mutex.RUnlock()
+
+	now := time.Now()
+	if now.Before(pc.retryAt) {
+		return false
 	}
-	return nil
+
+	return pc.loadedGeneration != pc.generation || now.Sub(pc.loadedAt) > keywordsCacheMaxAge
 }
 
-func (pc *KeywordsCacherImpl) update() error {
-	playbooks, err := pc.store.GetPlaybooksWithKeywords(PlaybookFilterOptions{Page: 0, PerPage: 1000})
+// loadIfNeeded reloads the cache if it was invalidated or is too old. If the reload fails, the
+// previously cached playbooks are kept.
+func (pc *KeywordsCacherImpl) loadIfNeeded() {
+	if !pc.needsLoad() {
+		return
+	}
+
+	pc.loadMutex.Lock()
+	defer pc.loadMutex.Unlock()
+
+	// Another goroutine may have reloaded the cache while this one was waiting.
+	if !pc.needsLoad() {
+		return
+	}
+
+	pc.mutex.RLock()
+	generation := pc.generation
+	pc.mutex.RUnlock()
+
+	teams, allTeams, err := pc.load()
 	if err != nil {
-		return errors.Wrap(err, "can't get playbooks to cache")
+		pc.logger.Error("can't update playbooks", "err", err.Error())
+
+		pc.mutex.Lock()
+		pc.retryAt = time.Now().Add(keywordsCacheRetryInterval)
+		pc.mutex.Unlock()
+		return
 	}
-	pc.playbooks = make([]*CachedPlaybook, 0, len(playbooks))
-	pc.updateAt = 0
+
+	// An invalidation received during the load bumped the generation, so the next Match
+	// reloads again.
+	pc.mutex.Lock()
+	pc.teams = teams
+	pc.allTeams = allTeams
+	pc.loadedGeneration = generation
+	pc.loadedAt = time.Now()
+	pc.mutex.Unlock()
+}
+
+// load reads every playbook with keywords enabled from the store and groups them by team. A
+// playbook shared with other teams is in the group of each of them. The playbooks shared with all
+// teams are in every group, and in the returned group for the teams without playbooks of their
+// own.
+func (pc *KeywordsCacherImpl) load() (map[string]*teamKeywords, *teamKeywords, error) {
+	var playbooks []Playbook
+	for page := 0; ; page++ {
+		pagePlaybooks, err := pc.store.GetPlaybooksWithKeywords(PlaybookFilterOptions{Page: page, PerPage: keywordsCachePerPage})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "can't get playbooks to cache")
+		}
+
+		playbooks = append(playbooks, pagePlaybooks...)
+
+		if len(pagePlaybooks) < keywordsCachePerPage {
+			break
+		}
+	}
+
+	playbooksByTeam := map[string][]*CachedPlaybook{}
+	for _, playbook := range playbooks {
+		playbooksByTeam[playbook.TeamID] = nil
+		for _, teamID := range playbook.SharedTeamIDs {
+			playbooksByTeam[teamID] = nil
+		}
+	}
+
+	var allTeamsPlaybooks []*CachedPlaybook
 	for _, playbook := range playbooks {
-		pc.playbooks = append(pc.playbooks, &CachedPlaybook{
+		cached := &CachedPlaybook{
 			ID:                playbook.ID,
 			Title:             playbook.Title,
 			TeamID:            playbook.TeamID,
 			SignalAnyKeywords: playbook.SignalAnyKeywords,
-		})
-		if pc.updateAt < playbook.UpdateAt {
-			pc.updateAt = playbook.UpdateAt
+		}
+
+		if playbook.SharedWithAllTeams {
+			allTeamsPlaybooks = append(allTeamsPlaybooks, cached)
+			for teamID := range playbooksByTeam {
+				playbooksByTeam[teamID] = append(playbooksByTeam[teamID], cached)
+			}
+			continue
+		}
+
+		playbooksByTeam[playbook.TeamID] = append(playbooksByTeam[playbook.TeamID], cached)
+		for _, teamID := range playbook.SharedTeamIDs {
+			if teamID != playbook.TeamID {
+				playbooksByTeam[teamID] = append(playbooksByTeam[teamID], cached)
+			}
+		}
+	}
+
+	teams := make(map[string]*teamKeywords, len(playbooksByTeam))
+	for teamID, teamPlaybooks := range playbooksByTeam {
+		teams[teamID] = newTeamKeywords(teamPlaybooks)
+	}
+
+	var allTeams *teamKeywords
+	if len(allTeamsPlaybooks) > 0 {
+		allTeams = newTeamKeywords(allTeamsPlaybooks)
+	}
+
+	return teams, allTeams, nil
+}
+
+func newTeamKeywords(playbooks []*CachedPlaybook) *teamKeywords {
+	keywords := []string{}
+	for _, playbook := range playbooks {
+		keywords = append(keywords, playbook.SignalAnyKeywords...)
+	}
+
+	matcher := newKeywordsMatcher(keywords)
+
+	keywordIndices := make(map[string]int, len(matcher.keywords))
+	for i, keyword := range matcher.keywords {
+		keywordIndices[keyword] = i
+	}
+
+	keywordPlaybooks := make([][]int, len(matcher.keywords))
+	for i, playbook := range playbooks {
+		for _, keyword := range playbook.SignalAnyKeywords {
+			index, ok := keywordIndices[keyword]
+			if !ok {
+				continue
+			}
+
+			// A playbook may list the same keyword twice.
+			indices := keywordPlaybooks[index]
+			if len(indices) > 0 && indices[len(indices)-1] == i {
+				continue
+			}
+			keywordPlaybooks[index] = append(indices, i)
 		}
 	}
-	return nil
+
+	return &teamKeywords{
+		playbooks:        playbooks,
+		matcher:          matcher,
+		keywordPlaybooks: keywordPlaybooks,
+	}
+}
+
+func (t *teamKeywords) match(message string) []KeywordsMatch {
+	keywordIndices := t.matcher.match(message)
+	if len(keywordIndices) == 0 {
+		return nil
+	}
+
+	triggersByPlaybook := map[int][]string{}
+	for _, keywordIndex := range keywordIndices {
+		for _, playbookIndex := range t.keywordPlaybooks[keywordIndex] {
+			triggersByPlaybook[playbookIndex] = append(triggersByPlaybook[playbookIndex], t.matcher.keywords[keywordIndex])
+		}
+	}
+
+	playbookIndices := make([]int, 0, len(triggersByPlaybook))
+	for playbookIndex := range triggersByPlaybook {
+		playbookIndices = append(playbookIndices, playbookIndex)
+	}
+	sort.Ints(playbookIndices)
+
+	matches := make([]KeywordsMatch, 0, len(playbookIndices))
+	for _, playbookIndex := range playbookIndices {
+		matches = append(matches, KeywordsMatch{
+			Playbook: t.playbooks[playbookIndex],
+			Triggers: triggersByPlaybook[playbookIndex],
+		})
+	}
+
+	return matches
 }
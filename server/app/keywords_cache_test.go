@@ -0,0 +1,184 @@
+package app_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	mock_playbook "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+)
+
+func getKeywordsCacher(t testing.TB) (app.KeywordsCacher, *mock_playbook.MockPlaybookStore, *plugintest.API) {
+	controller := gomock.NewController(t)
+	pluginAPI := &plugintest.API{}
+	client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+	store := mock_playbook.NewMockPlaybookStore(controller)
+
+	return app.NewPlaybookKeywordsCacher(store, pluginAPI, client.Log), store, pluginAPI
+}
+
+func makeKeywordPlaybooks(teamID string, n int) []app.Playbook {
+	playbooks := make([]app.Playbook, 0, n)
+	for i := 0; i < n; i++ {
+		playbooks = append(playbooks, app.Playbook{
+			ID:                model.NewId(),
+			Title:             fmt.Sprintf("playbook %d", i),
+			TeamID:            teamID,
+			SignalAnyKeywords: []string{fmt.Sprintf("keyword-%d!", i)},
+		})
+	}
+	return playbooks
+}
+
+func TestKeywordsCacher(t *testing.T) {
+	t.Run("matches only the playbooks of the team", func(t *testing.T) {
+		cacher, store, _ := getKeywordsCacher(t)
+
+		teamID := model.NewId()
+		playbooks := []app.Playbook{
+			{ID: model.NewId(), TeamID: model.NewId(), SignalAnyKeywords: []string{"outage"}},
+			{ID: model.NewId(), TeamID: teamID, SignalAnyKeywords: []string{"outage", "down", "outage"}},
+			{ID: model.NewId(), TeamID: teamID, SignalAnyKeywords: []string{"incident"}},
+			{ID: model.NewId(), TeamID: teamID, SignalAnyKeywords: []string{"down"}},
+		}
+		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(playbooks, nil)
+
+		matches := cacher.Match(teamID, "the server is down, full outage")
+		require.Len(t, matches, 2)
+		require.Equal(t, playbooks[1].ID, matches[0].Playbook.ID)
+		require.ElementsMatch(t, []string{"outage", "down"}, matches[0].Triggers)
+		require.Equal(t, playbooks[3].ID, matches[1].Playbook.ID)
+		require.Equal(t, []string{"down"}, matches[1].Triggers)
+
+		require.Empty(t, cacher.Match(model.NewId(), "the server is down, full outage"))
+	})
+
+	t.Run("matches the playbooks shared with the team", func(t *testing.T) {
+		cacher, store, _ := getKeywordsCacher(t)
+
+		teamID := model.NewId()
+		otherTeamID := model.NewId()
+		playbooks := []app.Playbook{
+			{ID: model.NewId(), TeamID: otherTeamID, SignalAnyKeywords: []string{"outage"}},
+			{ID: model.NewId(), TeamID: otherTeamID, SharedTeamIDs: []string{teamID}, SignalAnyKeywords: []string{"outage"}},
+			{ID: model.NewId(), TeamID: otherTeamID, SharedWithAllTeams: true, SignalAnyKeywords: []string{"breach"}},
+			{ID: model.NewId(), TeamID: teamID, SignalAnyKeywords: []string{"down"}},
+		}
+		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(playbooks, nil)
+
+		matches := cacher.Match(teamID, "outage: the server is down after a breach")
+		require.Len(t, matches, 3)
+		require.Equal(t, playbooks[1].ID, matches[0].Playbook.ID)
+		require.Equal(t, playbooks[2].ID, matches[1].Playbook.ID)
+		require.Equal(t, playbooks[3].ID, matches[2].Playbook.ID)
+
+		require.Len(t, cacher.Match(otherTeamID, "outage after a breach"), 3)
+
+		matches = cacher.Match(model.NewId(), "outage after a breach")
+		require.Len(t, matches, 1)
+		require.Equal(t, playbooks[2].ID, matches[0].Playbook.ID)
+	})
+
+	t.Run("loads more than a single page of playbooks", func(t *testing.T) {
+		cacher, store, _ := getKeywordsCacher(t)
+
+		teamID := model.NewId()
+		playbooks := makeKeywordPlaybooks(teamID, 1500)
+
+		gomock.InOrder(
+			store.EXPECT().GetPlaybooksWithKeywords(app.PlaybookFilterOptions{Page: 0, PerPage: 1000}).Return(playbooks[:1000], nil),
+			store.EXPECT().GetPlaybooksWithKeywords(app.PlaybookFilterOptions{Page: 1, PerPage: 1000}).Return(playbooks[1000:], nil),
+		)
+
+		matches := cacher.Match(teamID, "keyword-1499! fired")
+		require.Len(t, matches, 1)
+		require.Equal(t, playbooks[1499].ID, matches[0].Playbook.ID)
+	})
+
+	t.Run("invalidation reloads the cache and notifies the cluster", func(t *testing.T) {
+		cacher, store, pluginAPI := getKeywordsCacher(t)
+
+		teamID := model.NewId()
+		playbook := app.Playbook{ID: model.NewId(), TeamID: teamID, SignalAnyKeywords: []string{"outage"}}
+		updated := playbook
+		updated.SignalAnyKeywords = []string{"incident"}
+
+		gomock.InOrder(
+			store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return([]app.Playbook{playbook}, nil),
+			store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return([]app.Playbook{updated}, nil),
+		)
+		pluginAPI.On("PublishPluginClusterEvent", model.PluginClusterEvent{Id: app.KeywordsCacheInvalidateEventID}, mock.Anything).Return(nil).Once()
+
+		require.Len(t, cacher.Match(teamID, "outage"), 1)
+		require.Len(t, cacher.Match(teamID, "outage"), 1)
+
+		cacher.Invalidate()
+		pluginAPI.AssertExpectations(t)
+
+		require.Empty(t, cacher.Match(teamID, "outage"))
+		require.Len(t, cacher.Match(teamID, "incident"), 1)
+	})
+
+	t.Run("local invalidation doesn't notify the cluster", func(t *testing.T) {
+		cacher, store, pluginAPI := getKeywordsCacher(t)
+
+		teamID := model.NewId()
+		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(makeKeywordPlaybooks(teamID, 1), nil).Times(2)
+
+		require.Len(t, cacher.Match(teamID, "keyword-0!"), 1)
+		cacher.InvalidateLocal()
+		require.Len(t, cacher.Match(teamID, "keyword-0!"), 1)
+
+		pluginAPI.AssertNotCalled(t, "PublishPluginClusterEvent", mock.Anything, mock.Anything)
+	})
+
+	t.Run("concurrent matches and invalidations", func(t *testing.T) {
+		cacher, store, _ := getKeywordsCacher(t)
+
+		teamID := model.NewId()
+		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(makeKeywordPlaybooks(teamID, 10), nil).MinTimes(1)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					if j%25 == 0 {
+						cacher.InvalidateLocal()
+					}
+					matches := cacher.Match(teamID, fmt.Sprintf("keyword-%d!", i))
+					require.Len(t, matches, 1)
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkKeywordsCacherMatch(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d playbooks", n), func(b *testing.B) {
+			cacher, store, _ := getKeywordsCacher(b)
+
+			teamID := model.NewId()
+			store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(makeKeywordPlaybooks(teamID, n), nil).AnyTimes()
+			cacher.Match(teamID, "")
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					cacher.Match(teamID, "we might have an incident, keyword-7! is firing")
+				}
+			})
+		})
+	}
+}
@@ -1,41 +1,173 @@
 package app
 
-import "sync"
+import (
+	"time"
 
-type KeywordsThreadIgnorer interface {
-	Ignore(postID, userID string)
-	IsIgnored(postID, userID string) bool
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// KeywordsIgnoreScopeThread ignores keyword suggestions in a single thread.
+	KeywordsIgnoreScopeThread = "thread"
+
+	// KeywordsIgnoreScopeChannel ignores keyword suggestions in a single channel.
+	KeywordsIgnoreScopeChannel = "channel"
+
+	// KeywordsIgnoreScopeGlobal ignores keyword suggestions everywhere.
+	KeywordsIgnoreScopeGlobal = "global"
+)
+
+// KeywordsThreadIgnoreDuration is how long a thread stays ignored after a user dismisses a
+// playbook suggestion in it.
+const KeywordsThreadIgnoreDuration = 30 * 24 * time.Hour
+
+// KeywordsIgnoreRule stops keywords from triggering playbook suggestions.
+type KeywordsIgnoreRule struct {
+	// UserID is the user the rule applies to. Rules with an empty UserID are admin-defined
+	// muted channels and apply to every user.
+	UserID string `json:"user_id"`
+
+	// Scope is one of KeywordsIgnoreScopeThread, KeywordsIgnoreScopeChannel or
+	// KeywordsIgnoreScopeGlobal.
+	Scope string `json:"scope"`
+
+	// ScopeID is the root post ID for thread rules, the channel ID for channel rules, and the
+	// empty string for global rules.
+	ScopeID string `json:"scope_id"`
+
+	// CreateAt is the timestamp, in milliseconds since epoch, of when the rule was created.
+	CreateAt int64 `json:"create_at"`
+
+	// ExpireAt is the timestamp, in milliseconds since epoch, after which the rule no longer
+	// applies. 0 if the rule never expires.
+	ExpireAt int64 `json:"expire_at"`
+}
+
+// IsValid returns an error if the rule's scope and scope ID don't match.
+func (r KeywordsIgnoreRule) IsValid() error {
+	switch r.Scope {
+	case KeywordsIgnoreScopeThread, KeywordsIgnoreScopeChannel:
+		if !model.IsValidId(r.ScopeID) {
+			return errors.Errorf("invalid scope_id '%s' for scope '%s'", r.ScopeID, r.Scope)
+		}
+		if r.UserID == "" && r.Scope != KeywordsIgnoreScopeChannel {
+			return errors.New("only channels can be muted for all users")
+		}
+	case KeywordsIgnoreScopeGlobal:
+		if r.ScopeID != "" {
+			return errors.New("scope_id must be empty for the global scope")
+		}
+		if r.UserID == "" {
+			return errors.New("only channels can be muted for all users")
+		}
+	default:
+		return errors.Errorf("unknown scope '%s'", r.Scope)
+	}
+
+	if r.ExpireAt < 0 {
+		return errors.New("expire_at must not be negative")
+	}
+
+	return nil
+}
+
+// KeywordsIgnoreStore persists the rules that stop keywords from triggering playbook suggestions.
+type KeywordsIgnoreStore interface {
+	// Upsert creates the rule, or replaces the rule with the same user, scope and scope ID.
+	Upsert(rule KeywordsIgnoreRule) error
+
+	// Delete removes the rule with the given user, scope and scope ID, if any.
+	Delete(userID, scope, scopeID string) error
+
+	// GetForUser retrieves the rules of userID that have not expired by now. An empty userID
+	// retrieves the admin-defined muted channels.
+	GetForUser(userID string, now int64) ([]KeywordsIgnoreRule, error)
+
+	// IsIgnored checks whether any rule that has not expired by now applies to a post by
+	// userID in channelID, in the thread rooted at rootID.
+	IsIgnored(userID, channelID, rootID string, now int64) (bool, error)
+
+	// DeleteExpired removes every rule that expired before now.
+	DeleteExpired(now int64) error
+}
+
+// KeywordsIgnorer decides whether keywords in a post should trigger playbook suggestions.
+type KeywordsIgnorer interface {
+	// IgnoreThread ignores keywords in the thread rooted at postID for userID,
+	// other users will still get suggestions in this thread.
+	IgnoreThread(postID, userID string) error
+
+	// Ignore stores the given rule.
+	Ignore(rule KeywordsIgnoreRule) error
+
+	// Unignore removes the rule with the given user, scope and scope ID.
+	Unignore(userID, scope, scopeID string) error
+
+	// GetRules retrieves the active rules of userID. An empty userID retrieves the
+	// admin-defined muted channels.
+	GetRules(userID string) ([]KeywordsIgnoreRule, error)
+
+	// IsIgnored checks whether a post by userID in channelID, in the thread rooted at rootID,
+	// should not trigger suggestions.
+	IsIgnored(userID, channelID, rootID string) (bool, error)
 }
 
-type keywordsThreadIgnorerImpl struct {
-	ignoredThreads map[string]map[string]bool // [postID][userID]
-	mutex          sync.RWMutex
+type keywordsIgnorerImpl struct {
+	store KeywordsIgnoreStore
 }
 
-func NewKeywordsThreadIgnorer() KeywordsThreadIgnorer {
-	return &keywordsThreadIgnorerImpl{
-		ignoredThreads: map[string]map[string]bool{},
-		mutex:          sync.RWMutex{},
+// NewKeywordsIgnorer returns a KeywordsIgnorer backed by the given store, so that rules survive
+// restarts and are shared by every server in the cluster.
+func NewKeywordsIgnorer(store KeywordsIgnoreStore) KeywordsIgnorer {
+	return &keywordsIgnorerImpl{
+		store: store,
 	}
 }
 
-// Ignores ignores thread postID for the userID,
+// IgnoreThread ignores thread postID for the userID,
 // other users will still get notifications in this thread
-func (i *keywordsThreadIgnorerImpl) Ignore(postID, userID string) {
-	i.mutex.Lock()
-	defer i.mutex.Unlock()
-	if _, ok := i.ignoredThreads[postID]; !ok {
-		i.ignoredThreads[postID] = map[string]bool{}
-	}
-	i.ignoredThreads[postID][userID] = true
+func (i *keywordsIgnorerImpl) IgnoreThread(postID, userID string) error {
+	now := model.GetMillis()
+
+	return i.Ignore(KeywordsIgnoreRule{
+		UserID:   userID,
+		Scope:    KeywordsIgnoreScopeThread,
+		ScopeID:  postID,
+		CreateAt: now,
+		ExpireAt: now + KeywordsThreadIgnoreDuration.Milliseconds(),
+	})
 }
 
-// IsIgnored checks whether this thread should be ignored for userID
-func (i *keywordsThreadIgnorerImpl) IsIgnored(postID, userID string) bool {
-	i.mutex.RLock()
-	defer i.mutex.RUnlock()
-	if _, ok := i.ignoredThreads[postID]; !ok {
-		return false
+// Ignore stores the given rule, taking the chance to clean up expired ones.
+func (i *keywordsIgnorerImpl) Ignore(rule KeywordsIgnoreRule) error {
+	if err := rule.IsValid(); err != nil {
+		return err
+	}
+
+	now := model.GetMillis()
+	if rule.CreateAt == 0 {
+		rule.CreateAt = now
+	}
+
+	if err := i.store.DeleteExpired(now); err != nil {
+		return errors.Wrap(err, "failed to delete expired keywords ignore rules")
 	}
-	return i.ignoredThreads[postID][userID]
+
+	return i.store.Upsert(rule)
+}
+
+// Unignore removes the rule with the given user, scope and scope ID.
+func (i *keywordsIgnorerImpl) Unignore(userID, scope, scopeID string) error {
+	return i.store.Delete(userID, scope, scopeID)
+}
+
+// GetRules retrieves the active rules of userID.
+func (i *keywordsIgnorerImpl) GetRules(userID string) ([]KeywordsIgnoreRule, error) {
+	return i.store.GetForUser(userID, model.GetMillis())
+}
+
+// IsIgnored checks whether this post should be ignored for userID
+func (i *keywordsIgnorerImpl) IsIgnored(userID, channelID, rootID string) (bool, error) {
+	return i.store.IsIgnored(userID, channelID, rootID, model.GetMillis())
 }
@@ -0,0 +1,128 @@
+package app
+
+// keywordsMatcher finds every keyword occurring in a message in a single pass over the message,
+// using the Aho-Corasick algorithm, so that matching doesn't get slower as keywords are added.
+// Matching is case-sensitive and byte-wise, just like strings.Contains.
+// A keywordsMatcher is immutable once built and safe for concurrent use.
+type keywordsMatcher struct {
+	nodes    []keywordsMatcherNode
+	keywords []string
+}
+
+type keywordsMatcherNode struct {
+	// next holds the trie transitions out of this node.
+	next map[byte]int32
+
+	// fail is the node for the longest proper suffix of this node's path that is also in the trie.
+	fail int32
+
+	// outputs are the indices of the keywords ending at this node, including the ones reached
+	// through fail links.
+	outputs []int32
+}
+
+// newKeywordsMatcher builds a matcher for the given keywords. Empty and duplicated keywords are
+// skipped, so the index of a keyword in the matcher isn't necessarily its index in keywords.
+func newKeywordsMatcher(keywords []string) *keywordsMatcher {
+	m := &keywordsMatcher{
+		nodes: []keywordsMatcherNode{{}},
+	}
+
+	seen := make(map[string]bool, len(keywords))
+	for _, keyword := range keywords {
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		seen[keyword] = true
+		m.insert(keyword)
+	}
+
+	m.link()
+
+	return m
+}
+
+// insert adds keyword to the trie.
+func (m *keywordsMatcher) insert(keyword string) {
+	var current int32
+	for i := 0; i < len(keyword); i++ {
+		next, ok := m.nodes[current].next[keyword[i]]
+		if !ok {
+			m.nodes = append(m.nodes, keywordsMatcherNode{})
+			next = int32(len(m.nodes) - 1)
+			if m.nodes[current].next == nil {
+				m.nodes[current].next = make(map[byte]int32)
+			}
+			m.nodes[current].next[keyword[i]] = next
+		}
+		current = next
+	}
+
+	m.nodes[current].outputs = append(m.nodes[current].outputs, int32(len(m.keywords)))
+	m.keywords = append(m.keywords, keyword)
+}
+
+// link computes the fail links and merged outputs in breadth-first order, so that a node's fail
+// target is always complete before the node itself.
+func (m *keywordsMatcher) link() {
+	queue := make([]int32, 0, len(m.nodes))
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for c, child := range m.nodes[current].next {
+			queue = append(queue, child)
+
+			fail := m.nodes[current].fail
+			for fail != 0 {
+				if _, ok := m.nodes[fail].next[c]; ok {
+					break
+				}
+				fail = m.nodes[fail].fail
+			}
+			if target, ok := m.nodes[fail].next[c]; ok && target != child {
+				fail = target
+			}
+
+			m.nodes[child].fail = fail
+			m.nodes[child].outputs = append(m.nodes[child].outputs, m.nodes[fail].outputs...)
+		}
+	}
+}
+
+// match returns the indices of the keywords occurring in message, in order of first occurrence.
+func (m *keywordsMatcher) match(message string) []int {
+	var found []int
+	var seen map[int32]bool
+
+	var current int32
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		for current != 0 {
+			if _, ok := m.nodes[current].next[c]; ok {
+				break
+			}
+			current = m.nodes[current].fail
+		}
+		if next, ok := m.nodes[current].next[c]; ok {
+			current = next
+		}
+
+		for _, output := range m.nodes[current].outputs {
+			if seen == nil {
+				seen = make(map[int32]bool)
+			}
+			if seen[output] {
+				continue
+			}
+			seen[output] = true
+			found = append(found, int(output))
+		}
+	}
+
+	return found
+}
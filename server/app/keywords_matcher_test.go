@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func matchedKeywords(m *keywordsMatcher, message string) []string {
+	keywords := []string{}
+	for _, index := range m.match(message) {
+		keywords = append(keywords, m.keywords[index])
+	}
+	return keywords
+}
+
+func TestKeywordsMatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		keywords []string
+		message  string
+		expected []string
+	}{
+		{
+			name:     "no keywords",
+			keywords: nil,
+			message:  "some message",
+			expected: []string{},
+		},
+		{
+			name:     "no match",
+			keywords: []string{"outage", "incident"},
+			message:  "some message",
+			expected: []string{},
+		},
+		{
+			name:     "single match",
+			keywords: []string{"outage", "incident"},
+			message:  "there is an outage",
+			expected: []string{"outage"},
+		},
+		{
+			name:     "matches are case-sensitive",
+			keywords: []string{"outage"},
+			message:  "OUTAGE",
+			expected: []string{},
+		},
+		{
+			name:     "keywords inside words match, like strings.Contains",
+			keywords: []string{"age"},
+			message:  "outage",
+			expected: []string{"age"},
+		},
+		{
+			name:     "overlapping keywords",
+			keywords: []string{"he", "she", "his", "hers"},
+			message:  "ushers",
+			expected: []string{"she", "he", "hers"},
+		},
+		{
+			name:     "keyword found through a fail link",
+			keywords: []string{"abcd", "bc"},
+			message:  "abce",
+			expected: []string{"bc"},
+		},
+		{
+			name:     "repeated matches are reported once",
+			keywords: []string{"down"},
+			message:  "down down down",
+			expected: []string{"down"},
+		},
+		{
+			name:     "empty and duplicated keywords are skipped",
+			keywords: []string{"", "down", "down"},
+			message:  "server down",
+			expected: []string{"down"},
+		},
+		{
+			name:     "keywords with spaces and multi-byte characters",
+			keywords: []string{" some", "sécurité"},
+			message:  "problème de sécurité, some",
+			expected: []string{"sécurité", " some"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newKeywordsMatcher(tc.keywords)
+			require.Equal(t, tc.expected, matchedKeywords(m, tc.message))
+		})
+	}
+
+	t.Run("agrees with strings.Contains", func(t *testing.T) {
+		keywords := []string{"a", "ab", "bab", "bc", "bca", "c", "caa"}
+		m := newKeywordsMatcher(keywords)
+
+		for _, message := range []string{"abccab", "bcabab", "aaaa", "cbacab", "xyz", ""} {
+			expected := []string{}
+			for _, keyword := range keywords {
+				if strings.Contains(message, keyword) {
+					expected = append(expected, keyword)
+				}
+			}
+			require.ElementsMatch(t, expected, matchedKeywords(m, message), message)
+		}
+	})
+}
+
+func benchmarkKeywords(n int) []string {
+	keywords := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		keywords = append(keywords, fmt.Sprintf("keyword-%d", i))
+	}
+	return keywords
+}
+
+const benchmarkMessage = "We're seeing elevated error rates on the checkout service since the last deploy, " +
+	"can someone from the payments team take a look? keyword-42 might be related."
+
+func BenchmarkKeywordsMatcher(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		keywords := benchmarkKeywords(n)
+
+		b.Run(fmt.Sprintf("aho-corasick/%d", n), func(b *testing.B) {
+			m := newKeywordsMatcher(keywords)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.match(benchmarkMessage)
+			}
+		})
+
+		b.Run(fmt.Sprintf("strings.Contains/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, keyword := range keywords {
+					strings.Contains(benchmarkMessage, keyword)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewKeywordsMatcher(b *testing.B) {
+	keywords := benchmarkKeywords(10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newKeywordsMatcher(keywords)
+	}
+}
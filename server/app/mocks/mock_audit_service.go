@@ -0,0 +1,76 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-playbooks/server/app (interfaces: AuditService)
+
+// Package mock_app is a generated GoMock package.
+package mock_app
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	app "github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	io "io"
+	reflect "reflect"
+)
+
+// MockAuditService is a mock of AuditService interface
+type MockAuditService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditServiceMockRecorder
+}
+
+// MockAuditServiceMockRecorder is the mock recorder for MockAuditService
+type MockAuditServiceMockRecorder struct {
+	mock *MockAuditService
+}
+
+// NewMockAuditService creates a new mock instance
+func NewMockAuditService(ctrl *gomock.Controller) *MockAuditService {
+	mock := &MockAuditService{ctrl: ctrl}
+	mock.recorder = &MockAuditServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockAuditService) EXPECT() *MockAuditServiceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method
+func (m *MockAuditService) Export(arg0 io.Writer, arg1 app.AuditFilterOptions) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export
+func (mr *MockAuditServiceMockRecorder) Export(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockAuditService)(nil).Export), arg0, arg1)
+}
+
+// GetRecords mocks base method
+func (m *MockAuditService) GetRecords(arg0 app.AuditFilterOptions) (*app.GetAuditRecordsResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecords", arg0)
+	ret0, _ := ret[0].(*app.GetAuditRecordsResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecords indicates an expected call of GetRecords
+func (mr *MockAuditServiceMockRecorder) GetRecords(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecords", reflect.TypeOf((*MockAuditService)(nil).GetRecords), arg0)
+}
+
+// Record mocks base method
+func (m *MockAuditService) Record(arg0 app.AuditRecord) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Record", arg0)
+}
+
+// Record indicates an expected call of Record
+func (mr *MockAuditServiceMockRecorder) Record(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditService)(nil).Record), arg0)
+}
@@ -0,0 +1,166 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-playbooks/server/app (interfaces: ChecklistLibraryService)
+
+// Package mock_app is a generated GoMock package.
+package mock_app
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	app "github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	reflect "reflect"
+)
+
+// MockChecklistLibraryService is a mock of ChecklistLibraryService interface
+type MockChecklistLibraryService struct {
+	ctrl     *gomock.Controller
+	recorder *MockChecklistLibraryServiceMockRecorder
+}
+
+// MockChecklistLibraryServiceMockRecorder is the mock recorder for MockChecklistLibraryService
+type MockChecklistLibraryServiceMockRecorder struct {
+	mock *MockChecklistLibraryService
+}
+
+// NewMockChecklistLibraryService creates a new mock instance
+func NewMockChecklistLibraryService(ctrl *gomock.Controller) *MockChecklistLibraryService {
+	mock := &MockChecklistLibraryService{ctrl: ctrl}
+	mock.recorder = &MockChecklistLibraryServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockChecklistLibraryService) EXPECT() *MockChecklistLibraryServiceMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockChecklistLibraryService) Create(arg0 app.ChecklistLibraryEntry, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockChecklistLibraryServiceMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockChecklistLibraryService)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockChecklistLibraryService) Delete(arg0 app.ChecklistLibraryEntry, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockChecklistLibraryServiceMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockChecklistLibraryService)(nil).Delete), arg0, arg1)
+}
+
+// ExpandChecklists mocks base method
+func (m *MockChecklistLibraryService) ExpandChecklists(arg0 string, arg1 []app.Checklist) ([]app.Checklist, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpandChecklists", arg0, arg1)
+	ret0, _ := ret[0].([]app.Checklist)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpandChecklists indicates an expected call of ExpandChecklists
+func (mr *MockChecklistLibraryServiceMockRecorder) ExpandChecklists(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpandChecklists", reflect.TypeOf((*MockChecklistLibraryService)(nil).ExpandChecklists), arg0, arg1)
+}
+
+// Get mocks base method
+func (m *MockChecklistLibraryService) Get(arg0 string) (app.ChecklistLibraryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(app.ChecklistLibraryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockChecklistLibraryServiceMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockChecklistLibraryService)(nil).Get), arg0)
+}
+
+// GetForTeam mocks base method
+func (m *MockChecklistLibraryService) GetForTeam(arg0 string) ([]app.ChecklistLibraryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForTeam", arg0)
+	ret0, _ := ret[0].([]app.ChecklistLibraryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForTeam indicates an expected call of GetForTeam
+func (mr *MockChecklistLibraryServiceMockRecorder) GetForTeam(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForTeam", reflect.TypeOf((*MockChecklistLibraryService)(nil).GetForTeam), arg0)
+}
+
+// GetVersion mocks base method
+func (m *MockChecklistLibraryService) GetVersion(arg0 string, arg1 int) (app.ChecklistLibraryVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", arg0, arg1)
+	ret0, _ := ret[0].(app.ChecklistLibraryVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion
+func (mr *MockChecklistLibraryServiceMockRecorder) GetVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockChecklistLibraryService)(nil).GetVersion), arg0, arg1)
+}
+
+// GetVersions mocks base method
+func (m *MockChecklistLibraryService) GetVersions(arg0 string) ([]app.ChecklistLibraryVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersions", arg0)
+	ret0, _ := ret[0].([]app.ChecklistLibraryVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersions indicates an expected call of GetVersions
+func (mr *MockChecklistLibraryServiceMockRecorder) GetVersions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersions", reflect.TypeOf((*MockChecklistLibraryService)(nil).GetVersions), arg0)
+}
+
+// Update mocks base method
+func (m *MockChecklistLibraryService) Update(arg0 app.ChecklistLibraryEntry, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update
+func (mr *MockChecklistLibraryServiceMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockChecklistLibraryService)(nil).Update), arg0, arg1)
+}
+
+// ValidateReferences mocks base method
+func (m *MockChecklistLibraryService) ValidateReferences(arg0 string, arg1 []app.Checklist) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateReferences", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ValidateReferences indicates an expected call of ValidateReferences
+func (mr *MockChecklistLibraryServiceMockRecorder) ValidateReferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateReferences", reflect.TypeOf((*MockChecklistLibraryService)(nil).ValidateReferences), arg0, arg1)
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/mattermost/mattermost-plugin-playbooks/server/app (interfaces: ChecklistLibraryStore)
+
+// Package mock_app is a generated GoMock package.
+package mock_app
+
+import (
+	gomock "github.com/golang/mock/gomock"
+	app "github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	reflect "reflect"
+)
+
+// MockChecklistLibraryStore is a mock of ChecklistLibraryStore interface
+type MockChecklistLibraryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockChecklistLibraryStoreMockRecorder
+}
+
+// MockChecklistLibraryStoreMockRecorder is the mock recorder for MockChecklistLibraryStore
+type MockChecklistLibraryStoreMockRecorder struct {
+	mock *MockChecklistLibraryStore
+}
+
+// NewMockChecklistLibraryStore creates a new mock instance
+func NewMockChecklistLibraryStore(ctrl *gomock.Controller) *MockChecklistLibraryStore {
+	mock := &MockChecklistLibraryStore{ctrl: ctrl}
+	mock.recorder = &MockChecklistLibraryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockChecklistLibraryStore) EXPECT() *MockChecklistLibraryStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockChecklistLibraryStore) Create(arg0 app.ChecklistLibraryEntry, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockChecklistLibraryStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockChecklistLibraryStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method
+func (m *MockChecklistLibraryStore) Delete(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete
+func (mr *MockChecklistLibraryStoreMockRecorder) Delete(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockChecklistLibraryStore)(nil).Delete), arg0)
+}
+
+// Get mocks base method
+func (m *MockChecklistLibraryStore) Get(arg0 string) (app.ChecklistLibraryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(app.ChecklistLibraryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get
+func (mr *MockChecklistLibraryStoreMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockChecklistLibraryStore)(nil).Get), arg0)
+}
+
+// GetForTeam mocks base method
+func (m *MockChecklistLibraryStore) GetForTeam(arg0 string) ([]app.ChecklistLibraryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetForTeam", arg0)
+	ret0, _ := ret[0].([]app.ChecklistLibraryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetForTeam indicates an expected call of GetForTeam
+func (mr *MockChecklistLibraryStoreMockRecorder) GetForTeam(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetForTeam", reflect.TypeOf((*MockChecklistLibraryStore)(nil).GetForTeam), arg0)
+}
+
+// GetVersion mocks base method
+func (m *MockChecklistLibraryStore) GetVersion(arg0 string, arg1 int) (app.ChecklistLibraryVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion", arg0, arg1)
+	ret0, _ := ret[0].(app.ChecklistLibraryVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion
+func (mr *MockChecklistLibraryStoreMockRecorder) GetVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockChecklistLibraryStore)(nil).GetVersion), arg0, arg1)
+}
+
+// GetVersions mocks base method
+func (m *MockChecklistLibraryStore) GetVersions(arg0 string) ([]app.ChecklistLibraryVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersions", arg0)
+	ret0, _ := ret[0].([]app.ChecklistLibraryVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersions indicates an expected call of GetVersions
+func (mr *MockChecklistLibraryStoreMockRecorder) GetVersions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersions", reflect.TypeOf((*MockChecklistLibraryStore)(nil).GetVersions), arg0)
+}
+
+// Update mocks base method
+func (m *MockChecklistLibraryStore) Update(arg0 app.ChecklistLibraryEntry, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update
+func (mr *MockChecklistLibraryStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockChecklistLibraryStore)(nil).Update), arg0, arg1)
+}
@@ -1,59 +1,106 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/mattermost/mattermost-plugin-playbooks/server/app (interfaces: KeywordsThreadIgnorer)
+// Source: github.com/mattermost/mattermost-plugin-playbooks/server/app (interfaces: KeywordsIgnorer)
 
 // Package mock_app is a generated GoMock package.
 package mock_app
 
 import (
 	gomock "github.com/golang/mock/gomock"
+	app "github.com/mattermost/mattermost-plugin-playbooks/server/app"
 	reflect "reflect"
 )
 
-// MockKeywordsThreadIgnorer is a mock of KeywordsThreadIgnorer interface
-type MockKeywordsThreadIgnorer struct {
+// MockKeywordsIgnorer is a mock of KeywordsIgnorer interface
+type MockKeywordsIgnorer struct {
 	ctrl     *gomock.Controller
-	recorder *MockKeywordsThreadIgnorerMockRecorder
+	recorder *MockKeywordsIgnorerMockRecorder
 }
 
-// MockKeywordsThreadIgnorerMockRecorder is the mock recorder for MockKeywordsThreadIgnorer
-type MockKeywordsThreadIgnorerMockRecorder struct {
-	mock *MockKeywordsThreadIgnorer
+// MockKeywordsIgnorerMockRecorder is the mock recorder for MockKeywordsIgnorer
+type MockKeywordsIgnorerMockRecorder struct {
+	mock *MockKeywordsIgnorer
 }
 
-// NewMockKeywordsThreadIgnorer creates a new mock instance
-func NewMockKeywordsThreadIgnorer(ctrl *gomock.Controller) *MockKeywordsThreadIgnorer {
-	mock := &MockKeywordsThreadIgnorer{ctrl: ctrl}
-	mock.recorder = &MockKeywordsThreadIgnorerMockRecorder{mock}
+// NewMockKeywordsIgnorer creates a new mock instance
+func NewMockKeywordsIgnorer(ctrl *gomock.Controller) *MockKeywordsIgnorer {
+	mock := &MockKeywordsIgnorer{ctrl: ctrl}
+	mock.recorder = &MockKeywordsIgnorerMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockKeywordsThreadIgnorer) EXPECT() *MockKeywordsThreadIgnorerMockRecorder {
+func (m *MockKeywordsIgnorer) EXPECT() *MockKeywordsIgnorerMockRecorder {
 	return m.recorder
 }
 
+// GetRules mocks base method
+func (m *MockKeywordsIgnorer) GetRules(arg0 string) ([]app.KeywordsIgnoreRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRules", arg0)
+	ret0, _ := ret[0].([]app.KeywordsIgnoreRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRules indicates an expected call of GetRules
+func (mr *MockKeywordsIgnorerMockRecorder) GetRules(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRules", reflect.TypeOf((*MockKeywordsIgnorer)(nil).GetRules), arg0)
+}
+
 // Ignore mocks base method
-func (m *MockKeywordsThreadIgnorer) Ignore(arg0, arg1 string) {
+func (m *MockKeywordsIgnorer) Ignore(arg0 app.KeywordsIgnoreRule) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "Ignore", arg0, arg1)
+	ret := m.ctrl.Call(m, "Ignore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // Ignore indicates an expected call of Ignore
-func (mr *MockKeywordsThreadIgnorerMockRecorder) Ignore(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockKeywordsIgnorerMockRecorder) Ignore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ignore", reflect.TypeOf((*MockKeywordsIgnorer)(nil).Ignore), arg0)
+}
+
+// IgnoreThread mocks base method
+func (m *MockKeywordsIgnorer) IgnoreThread(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IgnoreThread", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IgnoreThread indicates an expected call of IgnoreThread
+func (mr *MockKeywordsIgnorerMockRecorder) IgnoreThread(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ignore", reflect.TypeOf((*MockKeywordsThreadIgnorer)(nil).Ignore), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IgnoreThread", reflect.TypeOf((*MockKeywordsIgnorer)(nil).IgnoreThread), arg0, arg1)
 }
 
 // IsIgnored mocks base method
-func (m *MockKeywordsThreadIgnorer) IsIgnored(arg0, arg1 string) bool {
+func (m *MockKeywordsIgnorer) IsIgnored(arg0, arg1, arg2 string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IsIgnored", arg0, arg1)
+	ret := m.ctrl.Call(m, "IsIgnored", arg0, arg1, arg2)
 	ret0, _ := ret[0].(bool)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // IsIgnored indicates an expected call of IsIgnored
-func (mr *MockKeywordsThreadIgnorerMockRecorder) IsIgnored(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockKeywordsIgnorerMockRecorder) IsIgnored(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIgnored", reflect.TypeOf((*MockKeywordsIgnorer)(nil).IsIgnored), arg0, arg1, arg2)
+}
+
+// Unignore mocks base method
+func (m *MockKeywordsIgnorer) Unignore(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unignore", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unignore indicates an expected call of Unignore
+func (mr *MockKeywordsIgnorerMockRecorder) Unignore(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsIgnored", reflect.TypeOf((*MockKeywordsThreadIgnorer)(nil).IsIgnored), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unignore", reflect.TypeOf((*MockKeywordsIgnorer)(nil).Unignore), arg0, arg1, arg2)
 }
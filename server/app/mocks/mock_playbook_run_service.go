@@ -105,6 +105,20 @@ func (mr *MockPlaybookRunServiceMockRecorder) ChangeOwner(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeOwner", reflect.TypeOf((*MockPlaybookRunService)(nil).ChangeOwner), arg0, arg1, arg2)
 }
 
+// ChangeRunStatus mocks base method
+func (m *MockPlaybookRunService) ChangeRunStatus(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeRunStatus", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeRunStatus indicates an expected call of ChangeRunStatus
+func (mr *MockPlaybookRunServiceMockRecorder) ChangeRunStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRunStatus", reflect.TypeOf((*MockPlaybookRunService)(nil).ChangeRunStatus), arg0, arg1, arg2)
+}
+
 // CheckAndSendMessageOnJoin mocks base method
 func (m *MockPlaybookRunService) CheckAndSendMessageOnJoin(arg0, arg1, arg2 string) bool {
 	m.ctrl.T.Helper()
@@ -148,6 +162,20 @@ func (mr *MockPlaybookRunServiceMockRecorder) DMTodoDigestToUser(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DMTodoDigestToUser", reflect.TypeOf((*MockPlaybookRunService)(nil).DMTodoDigestToUser), arg0, arg1)
 }
 
+// DecideChecklistItemApproval mocks base method
+func (m *MockPlaybookRunService) DecideChecklistItemApproval(arg0, arg1, arg2 string, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideChecklistItemApproval", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideChecklistItemApproval indicates an expected call of DecideChecklistItemApproval
+func (mr *MockPlaybookRunServiceMockRecorder) DecideChecklistItemApproval(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideChecklistItemApproval", reflect.TypeOf((*MockPlaybookRunService)(nil).DecideChecklistItemApproval), arg0, arg1, arg2, arg3)
+}
+
 // EditChecklistItem mocks base method
 func (m *MockPlaybookRunService) EditChecklistItem(arg0, arg1 string, arg2, arg3 int, arg4, arg5, arg6 string) error {
 	m.ctrl.T.Helper()
@@ -163,17 +191,17 @@ func (mr *MockPlaybookRunServiceMockRecorder) EditChecklistItem(arg0, arg1, arg2
 }
 
 // FinishPlaybookRun mocks base method
-func (m *MockPlaybookRunService) FinishPlaybookRun(arg0, arg1 string) error {
+func (m *MockPlaybookRunService) FinishPlaybookRun(arg0, arg1 string, arg2 bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FinishPlaybookRun", arg0, arg1)
+	ret := m.ctrl.Call(m, "FinishPlaybookRun", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // FinishPlaybookRun indicates an expected call of FinishPlaybookRun
-func (mr *MockPlaybookRunServiceMockRecorder) FinishPlaybookRun(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockPlaybookRunServiceMockRecorder) FinishPlaybookRun(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPlaybookRun", reflect.TypeOf((*MockPlaybookRunService)(nil).FinishPlaybookRun), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishPlaybookRun", reflect.TypeOf((*MockPlaybookRunService)(nil).FinishPlaybookRun), arg0, arg1, arg2)
 }
 
 // GetChecklistAutocomplete mocks base method
@@ -437,17 +465,17 @@ func (mr *MockPlaybookRunServiceMockRecorder) OpenCreatePlaybookRunDialog(arg0,
 }
 
 // OpenFinishPlaybookRunDialog mocks base method
-func (m *MockPlaybookRunService) OpenFinishPlaybookRunDialog(arg0, arg1 string) error {
+func (m *MockPlaybookRunService) OpenFinishPlaybookRunDialog(arg0, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "OpenFinishPlaybookRunDialog", arg0, arg1)
+	ret := m.ctrl.Call(m, "OpenFinishPlaybookRunDialog", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // OpenFinishPlaybookRunDialog indicates an expected call of OpenFinishPlaybookRunDialog
-func (mr *MockPlaybookRunServiceMockRecorder) OpenFinishPlaybookRunDialog(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockPlaybookRunServiceMockRecorder) OpenFinishPlaybookRunDialog(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFinishPlaybookRunDialog", reflect.TypeOf((*MockPlaybookRunService)(nil).OpenFinishPlaybookRunDialog), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFinishPlaybookRunDialog", reflect.TypeOf((*MockPlaybookRunService)(nil).OpenFinishPlaybookRunDialog), arg0, arg1, arg2)
 }
 
 // OpenUpdateStatusDialog mocks base method
@@ -464,6 +492,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) OpenUpdateStatusDialog(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenUpdateStatusDialog", reflect.TypeOf((*MockPlaybookRunService)(nil).OpenUpdateStatusDialog), arg0, arg1)
 }
 
+// PreviewChecklistItemSlashCommand mocks base method
+func (m *MockPlaybookRunService) PreviewChecklistItemSlashCommand(arg0, arg1 string, arg2, arg3 int) (app.ChecklistItemCommandPreview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewChecklistItemSlashCommand", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(app.ChecklistItemCommandPreview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewChecklistItemSlashCommand indicates an expected call of PreviewChecklistItemSlashCommand
+func (mr *MockPlaybookRunServiceMockRecorder) PreviewChecklistItemSlashCommand(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewChecklistItemSlashCommand", reflect.TypeOf((*MockPlaybookRunService)(nil).PreviewChecklistItemSlashCommand), arg0, arg1, arg2, arg3)
+}
+
 // PublishRetrospective mocks base method
 func (m *MockPlaybookRunService) PublishRetrospective(arg0, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -546,6 +589,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) ResetReminderTimer(arg0 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetReminderTimer", reflect.TypeOf((*MockPlaybookRunService)(nil).ResetReminderTimer), arg0)
 }
 
+// RunChecklistItemHTTPAction mocks base method
+func (m *MockPlaybookRunService) RunChecklistItemHTTPAction(arg0, arg1 string, arg2, arg3 int, arg4 bool) (app.ChecklistItemHTTPActionResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RunChecklistItemHTTPAction", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(app.ChecklistItemHTTPActionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RunChecklistItemHTTPAction indicates an expected call of RunChecklistItemHTTPAction
+func (mr *MockPlaybookRunServiceMockRecorder) RunChecklistItemHTTPAction(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunChecklistItemHTTPAction", reflect.TypeOf((*MockPlaybookRunService)(nil).RunChecklistItemHTTPAction), arg0, arg1, arg2, arg3, arg4)
+}
+
 // RunChecklistItemSlashCommand mocks base method
 func (m *MockPlaybookRunService) RunChecklistItemSlashCommand(arg0, arg1 string, arg2, arg3 int) (string, error) {
 	m.ctrl.T.Helper()
@@ -561,6 +619,21 @@ func (mr *MockPlaybookRunServiceMockRecorder) RunChecklistItemSlashCommand(arg0,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunChecklistItemSlashCommand", reflect.TypeOf((*MockPlaybookRunService)(nil).RunChecklistItemSlashCommand), arg0, arg1, arg2, arg3)
 }
 
+// SearchPlaybookRuns mocks base method
+func (m *MockPlaybookRunService) SearchPlaybookRuns(arg0 app.RequesterInfo, arg1 app.PlaybookRunSearchOptions) (*app.PlaybookRunSearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPlaybookRuns", arg0, arg1)
+	ret0, _ := ret[0].(*app.PlaybookRunSearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPlaybookRuns indicates an expected call of SearchPlaybookRuns
+func (mr *MockPlaybookRunServiceMockRecorder) SearchPlaybookRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPlaybookRuns", reflect.TypeOf((*MockPlaybookRunService)(nil).SearchPlaybookRuns), arg0, arg1)
+}
+
 // SetAssignee mocks base method
 func (m *MockPlaybookRunService) SetAssignee(arg0, arg1, arg2 string, arg3, arg4 int) error {
 	m.ctrl.T.Helper()
@@ -603,6 +676,20 @@ func (mr *MockPlaybookRunServiceMockRecorder) ToggleCheckedState(arg0, arg1, arg
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ToggleCheckedState", reflect.TypeOf((*MockPlaybookRunService)(nil).ToggleCheckedState), arg0, arg1, arg2, arg3)
 }
 
+// UpdateCustomData mocks base method
+func (m *MockPlaybookRunService) UpdateCustomData(arg0, arg1 string, arg2 map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomData", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCustomData indicates an expected call of UpdateCustomData
+func (mr *MockPlaybookRunServiceMockRecorder) UpdateCustomData(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomData", reflect.TypeOf((*MockPlaybookRunService)(nil).UpdateCustomData), arg0, arg1, arg2)
+}
+
 // UpdateDescription mocks base method
 func (m *MockPlaybookRunService) UpdateDescription(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
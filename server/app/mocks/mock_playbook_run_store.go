@@ -270,6 +270,21 @@ func (mr *MockPlaybookRunStoreMockRecorder) NukeDB() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NukeDB", reflect.TypeOf((*MockPlaybookRunStore)(nil).NukeDB))
 }
 
+// SearchPlaybookRuns mocks base method
+func (m *MockPlaybookRunStore) SearchPlaybookRuns(arg0 app.RequesterInfo, arg1 app.PlaybookRunSearchOptions) (*app.PlaybookRunSearchResults, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchPlaybookRuns", arg0, arg1)
+	ret0, _ := ret[0].(*app.PlaybookRunSearchResults)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchPlaybookRuns indicates an expected call of SearchPlaybookRuns
+func (mr *MockPlaybookRunStoreMockRecorder) SearchPlaybookRuns(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPlaybookRuns", reflect.TypeOf((*MockPlaybookRunStore)(nil).SearchPlaybookRuns), arg0, arg1)
+}
+
 // SetBroadcastChannelIDsToRootID mocks base method
 func (m *MockPlaybookRunStore) SetBroadcastChannelIDsToRootID(arg0 string, arg1 map[string]string) error {
 	m.ctrl.T.Helper()
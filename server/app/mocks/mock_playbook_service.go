@@ -93,6 +93,21 @@ func (mr *MockPlaybookServiceMockRecorder) GetNumPlaybooksForTeam(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNumPlaybooksForTeam", reflect.TypeOf((*MockPlaybookService)(nil).GetNumPlaybooksForTeam), arg0)
 }
 
+// GetPlaybookIDsReferencingLibrary mocks base method
+func (m *MockPlaybookService) GetPlaybookIDsReferencingLibrary(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaybookIDsReferencingLibrary", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaybookIDsReferencingLibrary indicates an expected call of GetPlaybookIDsReferencingLibrary
+func (mr *MockPlaybookServiceMockRecorder) GetPlaybookIDsReferencingLibrary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybookIDsReferencingLibrary", reflect.TypeOf((*MockPlaybookService)(nil).GetPlaybookIDsReferencingLibrary), arg0)
+}
+
 // GetPlaybooks mocks base method
 func (m *MockPlaybookService) GetPlaybooks() ([]app.Playbook, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +138,21 @@ func (mr *MockPlaybookServiceMockRecorder) GetPlaybooksForTeam(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybooksForTeam", reflect.TypeOf((*MockPlaybookService)(nil).GetPlaybooksForTeam), arg0, arg1, arg2)
 }
 
+// GetRunPermissions mocks base method
+func (m *MockPlaybookService) GetRunPermissions(arg0 string) (app.RunPermissionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunPermissions", arg0)
+	ret0, _ := ret[0].(app.RunPermissionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunPermissions indicates an expected call of GetRunPermissions
+func (mr *MockPlaybookServiceMockRecorder) GetRunPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunPermissions", reflect.TypeOf((*MockPlaybookService)(nil).GetRunPermissions), arg0)
+}
+
 // GetSuggestedPlaybooks mocks base method
 func (m *MockPlaybookService) GetSuggestedPlaybooks(arg0, arg1, arg2 string) ([]*app.CachedPlaybook, []string) {
 	m.ctrl.T.Helper()
@@ -150,6 +180,34 @@ func (mr *MockPlaybookServiceMockRecorder) MessageHasBeenPosted(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MessageHasBeenPosted", reflect.TypeOf((*MockPlaybookService)(nil).MessageHasBeenPosted), arg0, arg1)
 }
 
+// Purge mocks base method
+func (m *MockPlaybookService) Purge(arg0 app.Playbook, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockPlaybookServiceMockRecorder) Purge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockPlaybookService)(nil).Purge), arg0, arg1)
+}
+
+// Restore mocks base method
+func (m *MockPlaybookService) Restore(arg0 app.Playbook, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockPlaybookServiceMockRecorder) Restore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockPlaybookService)(nil).Restore), arg0, arg1)
+}
+
 // Update mocks base method
 func (m *MockPlaybookService) Update(arg0 app.Playbook, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -107,6 +107,21 @@ func (mr *MockPlaybookStoreMockRecorder) GetPlaybookIDsForUser(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybookIDsForUser", reflect.TypeOf((*MockPlaybookStore)(nil).GetPlaybookIDsForUser), arg0, arg1)
 }
 
+// GetPlaybookIDsReferencingLibrary mocks base method
+func (m *MockPlaybookStore) GetPlaybookIDsReferencingLibrary(arg0 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlaybookIDsReferencingLibrary", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlaybookIDsReferencingLibrary indicates an expected call of GetPlaybookIDsReferencingLibrary
+func (mr *MockPlaybookStoreMockRecorder) GetPlaybookIDsReferencingLibrary(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybookIDsReferencingLibrary", reflect.TypeOf((*MockPlaybookStore)(nil).GetPlaybookIDsReferencingLibrary), arg0)
+}
+
 // GetPlaybooks mocks base method
 func (m *MockPlaybookStore) GetPlaybooks() ([]app.Playbook, error) {
 	m.ctrl.T.Helper()
@@ -152,6 +167,21 @@ func (mr *MockPlaybookStoreMockRecorder) GetPlaybooksWithKeywords(arg0 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlaybooksWithKeywords", reflect.TypeOf((*MockPlaybookStore)(nil).GetPlaybooksWithKeywords), arg0)
 }
 
+// GetRunPermissions mocks base method
+func (m *MockPlaybookStore) GetRunPermissions(arg0 string) (app.RunPermissionPolicy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRunPermissions", arg0)
+	ret0, _ := ret[0].(app.RunPermissionPolicy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRunPermissions indicates an expected call of GetRunPermissions
+func (mr *MockPlaybookStoreMockRecorder) GetRunPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRunPermissions", reflect.TypeOf((*MockPlaybookStore)(nil).GetRunPermissions), arg0)
+}
+
 // GetTimeLastUpdated mocks base method
 func (m *MockPlaybookStore) GetTimeLastUpdated(arg0 bool) (int64, error) {
 	m.ctrl.T.Helper()
@@ -167,6 +197,34 @@ func (mr *MockPlaybookStoreMockRecorder) GetTimeLastUpdated(arg0 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeLastUpdated", reflect.TypeOf((*MockPlaybookStore)(nil).GetTimeLastUpdated), arg0)
 }
 
+// Purge mocks base method
+func (m *MockPlaybookStore) Purge(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Purge", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Purge indicates an expected call of Purge
+func (mr *MockPlaybookStoreMockRecorder) Purge(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Purge", reflect.TypeOf((*MockPlaybookStore)(nil).Purge), arg0)
+}
+
+// Restore mocks base method
+func (m *MockPlaybookStore) Restore(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Restore indicates an expected call of Restore
+func (mr *MockPlaybookStoreMockRecorder) Restore(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockPlaybookStore)(nil).Restore), arg0)
+}
+
 // Update mocks base method
 func (m *MockPlaybookStore) Update(arg0 app.Playbook) error {
 	m.ctrl.T.Helper()
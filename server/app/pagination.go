@@ -0,0 +1,86 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// PageCursor marks the position of the last item of a page when listing with cursor-based
+// (keyset) pagination: the next page starts right after the item having this sort value and ID.
+// The clients handle it as an opaque token, see Encode and DecodePageCursor.
+//
+// Unlike offset paging, a cursor doesn't skip or repeat items when others are created or deleted
+// while paging, and the database doesn't need to scan the previous pages.
+type PageCursor struct {
+	// Sort and Direction are the order of the listing the cursor was created for. A cursor
+	// can't be used to continue a listing in a different order.
+	Sort      SortField     `json:"s"`
+	Direction SortDirection `json:"d"`
+
+	// Value is the value of the sort field of the last item: a string or an int64.
+	Value interface{} `json:"v"`
+
+	// ID is the ID of the last item, breaking the ties between items with the same Value.
+	ID string `json:"i"`
+}
+
+// Encode returns the opaque token for the cursor.
+func (c PageCursor) Encode() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		// Only strings and numbers are marshalled, this can't fail.
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodePageCursor returns the cursor encoded in the token by PageCursor.Encode.
+func DecodePageCursor(token string) (PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return PageCursor{}, errors.Wrap(err, "invalid cursor encoding")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var cursor PageCursor
+	if err = decoder.Decode(&cursor); err != nil {
+		return PageCursor{}, errors.Wrap(err, "invalid cursor")
+	}
+
+	switch value := cursor.Value.(type) {
+	case string:
+	case json.Number:
+		if cursor.Value, err = value.Int64(); err != nil {
+			return PageCursor{}, errors.Wrap(err, "invalid cursor value")
+		}
+	default:
+		return PageCursor{}, errors.New("invalid cursor value")
+	}
+
+	if cursor.ID == "" {
+		return PageCursor{}, errors.New("invalid cursor: missing id")
+	}
+
+	return cursor, nil
+}
+
+// validatePageCursor checks that the token is a cursor created for a listing in this order.
+func validatePageCursor(token string, sort SortField, direction SortDirection) error {
+	cursor, err := DecodePageCursor(token)
+	if err != nil {
+		return errors.Wrap(err, "bad parameter 'cursor'")
+	}
+
+	if cursor.Sort != sort || cursor.Direction != direction {
+		return errors.Errorf("bad parameter 'cursor': it was created for the sort '%s %s', not '%s %s'",
+			cursor.Sort, cursor.Direction, sort, direction)
+	}
+
+	return nil
+}
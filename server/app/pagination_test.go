@@ -0,0 +1,46 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		for _, value := range []interface{}{int64(1634567890123), "a title", int64(0)} {
+			cursor := PageCursor{Sort: SortByCreateAt, Direction: DirectionDesc, Value: value, ID: "run_id"}
+
+			decoded, err := DecodePageCursor(cursor.Encode())
+			require.NoError(t, err)
+			require.Equal(t, cursor, decoded)
+		}
+	})
+
+	t.Run("invalid tokens", func(t *testing.T) {
+		for _, token := range []string{
+			"not base64!",
+			"bm90IGpzb24",
+			PageCursor{Sort: SortByID, Value: 1.5, ID: "id"}.Encode(),
+			PageCursor{Sort: SortByID, Value: true, ID: "id"}.Encode(),
+			PageCursor{Sort: SortByID, Value: "value"}.Encode(),
+		} {
+			_, err := DecodePageCursor(token)
+			require.Error(t, err, token)
+		}
+	})
+
+	t.Run("validated against the sort", func(t *testing.T) {
+		token := PageCursor{Sort: SortByName, Direction: DirectionAsc, Value: "name", ID: "id"}.Encode()
+
+		options, err := PlaybookRunFilterOptions{Sort: "NAME", Direction: "asc", Page: 3, Cursor: token}.Validate()
+		require.NoError(t, err)
+		require.Equal(t, 0, options.Page)
+
+		_, err = PlaybookRunFilterOptions{Sort: SortByName, Direction: DirectionDesc, Cursor: token}.Validate()
+		require.Error(t, err)
+
+		_, err = PlaybookFilterOptions{Sort: SortByTitle, Cursor: token}.Validate()
+		require.Error(t, err)
+	})
+}
@@ -80,11 +80,93 @@ func EditPlaybookRun(userID, channelID string, pluginAPI *pluginapi.Client) erro
 	return ErrNoPermissions
 }
 
+// RunActionAccess returns nil if userID may perform action on playbookRun, following the run
+// permission policy of the playbook the run was started from. System admins and the owner of the
+// run are always allowed. If the playbook no longer exists, only the participants are.
+func RunActionAccess(userID string, playbookRun *PlaybookRun, action RunAction, playbookService PlaybookService, pluginAPI *pluginapi.Client) error {
+	if IsAdmin(userID, pluginAPI) || userID == playbookRun.OwnerUserID {
+		return nil
+	}
+
+	// Only the policy is loaded here: the whole playbook is needed for the role rules alone.
+	var policy RunPermissionPolicy
+	missingPlaybook := false
+	if playbookRun.PlaybookID != "" {
+		var err error
+		policy, err = playbookService.GetRunPermissions(playbookRun.PlaybookID)
+		if errors.Is(err, ErrNotFound) {
+			missingPlaybook = true
+		} else if err != nil {
+			return errors.Wrapf(err, "Unable to get playbook to determine permissions, playbook id `%s`", playbookRun.PlaybookID)
+		}
+	}
+
+	rule := policy.Rule(action)
+	if missingPlaybook {
+		rule = RunPermissionRule{Allow: RunPermissionAllowParticipants}
+	}
+	switch rule.Allow {
+	case RunPermissionAllowChannel:
+		if IsMemberOfChannel(userID, playbookRun.ChannelID, pluginAPI) {
+			return nil
+		}
+	case RunPermissionAllowParticipants:
+		for _, participantID := range playbookRun.ParticipantIDs {
+			if participantID == userID {
+				return nil
+			}
+		}
+	case RunPermissionAllowRole:
+		playbook, err := playbookService.Get(playbookRun.PlaybookID)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to get playbook to determine permissions, playbook id `%s`", playbookRun.PlaybookID)
+		}
+		if CheckPlaybookRole(userID, playbook, rule.Role, pluginAPI) == nil {
+			return nil
+		}
+	}
+
+	return errors.Wrapf(
+		ErrNoPermissions,
+		"userID %s to %s on playbook run %s; allowed: %s",
+		userID,
+		action,
+		playbookRun.ID,
+		rule.Allow,
+	)
+}
+
 // CanViewTeam returns true if the userID has permissions to view teamID
 func CanViewTeam(userID, teamID string, pluginAPI *pluginapi.Client) bool {
 	return pluginAPI.User.HasPermissionToTeam(userID, teamID, model.PermissionViewTeam)
 }
 
+// CanViewPlaybookTeams returns true if userID can view the team of the playbook, or one of the
+// teams it is shared with.
+func CanViewPlaybookTeams(userID string, playbook Playbook, pluginAPI *pluginapi.Client) bool {
+	if CanViewTeam(userID, playbook.TeamID, pluginAPI) {
+		return true
+	}
+
+	if playbook.SharedWithAllTeams {
+		teams, err := pluginAPI.Team.List(pluginapi.FilterTeamsByUser(userID))
+		if err != nil {
+			pluginAPI.Log.Warn("failed to list the teams of the user", "user_id", userID, "error", err.Error())
+			return false
+		}
+
+		return len(teams) > 0
+	}
+
+	for _, teamID := range playbook.SharedTeamIDs {
+		if CanViewTeam(userID, teamID, pluginAPI) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IsAdmin returns true if the userID is a system admin
 func IsAdmin(userID string, pluginAPI *pluginapi.Client) bool {
 	return pluginAPI.User.HasPermissionTo(userID, model.PermissionManageSystem)
@@ -175,39 +257,176 @@ func isPlaybookCreator(userID string, cfgService config.Service) error {
 	return errors.Wrap(ErrNoPermissions, "create playbooks")
 }
 
+// PlaybookAccess returns nil if userID can view the playbook: any role on it is enough.
 func PlaybookAccess(userID string, playbookID string, playbookService PlaybookService, pluginAPI *pluginapi.Client) error {
+	return PlaybookRoleAccess(userID, playbookID, PlaybookRoleViewer, playbookService, pluginAPI)
+}
+
+// PlaybookRoleAccess returns nil if userID can view the team of the playbook and has at least
+// the given role on it. System admins are always allowed.
+func PlaybookRoleAccess(userID, playbookID, role string, playbookService PlaybookService, pluginAPI *pluginapi.Client) error {
+	playbook, err := playbookService.Get(playbookID)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to get playbook to determine permissions, playbook id `%s`", playbookID)
+	}
+
+	return CheckPlaybookRole(userID, playbook, role, pluginAPI)
+}
+
+// CheckPlaybookRole is PlaybookRoleAccess for an already retrieved playbook.
+func CheckPlaybookRole(userID string, playbook Playbook, role string, pluginAPI *pluginapi.Client) error {
+	if IsAdmin(userID, pluginAPI) {
+		return nil
+	}
+
 	noAccessErr := errors.Wrapf(
 		ErrNoPermissions,
 		"userID %s to access playbook",
 		userID,
 	)
 
-	playbook, err := playbookService.Get(playbookID)
-	if err != nil {
-		return errors.Wrapf(err, "Unable to get playbook to determine permissions, playbook id `%s`", playbookID)
+	if !CanViewPlaybookTeams(userID, playbook, pluginAPI) {
+		return errors.Wrap(noAccessErr, "no playbook access; no team view permission")
 	}
 
-	if !CanViewTeam(userID, playbook.TeamID, pluginAPI) {
-		return errors.Wrap(noAccessErr, "no playbook access; no team view permission")
+	userRole := PlaybookRole(userID, playbook, pluginAPI)
+	if playbookRoleRanks[userRole] < playbookRoleRanks[role] && managesPlaybook(userID, playbook, pluginAPI) {
+		userRole = PlaybookRoleAdmin
 	}
 
-	// If the list of members is empty then the playbook is open for all.
-	if len(playbook.MemberIDs) == 0 {
-		return nil
+	if userRole == "" {
+		return errors.Wrap(noAccessErr, "no playbook access; not on list of playbook members")
 	}
 
-	for _, memberID := range playbook.MemberIDs {
-		if memberID == userID {
-			return nil
+	if playbookRoleRanks[userRole] < playbookRoleRanks[role] {
+		return errors.Wrapf(noAccessErr, "no playbook access; the %s role is required", role)
+	}
+
+	return nil
+}
+
+// HasPlaybookRole returns true if userID has at least the given role on the playbook, directly or
+// through its member groups, manages it as a team admin, or is a system admin. Unlike
+// CheckPlaybookRole, it doesn't check the team permissions.
+func HasPlaybookRole(userID string, playbook Playbook, role string, pluginAPI *pluginapi.Client) bool {
+	return playbookRoleRanks[PlaybookRole(userID, playbook, pluginAPI)] >= playbookRoleRanks[role] ||
+		managesPlaybook(userID, playbook, pluginAPI) ||
+		IsAdmin(userID, pluginAPI)
+}
+
+// managesPlaybook returns true if userID is a team admin of a playbook without an admin: an open
+// playbook, or one restricted before the roles existed, whose members all became editors. Team
+// admins have the admin role on those playbooks.
+func managesPlaybook(userID string, playbook Playbook, pluginAPI *pluginapi.Client) bool {
+	return !playbook.hasAdmin() && pluginAPI.User.HasPermissionToTeam(userID, playbook.TeamID, model.PermissionManageTeam)
+}
+
+// PlaybookRole returns the highest of the roles of userID on the playbook: as an individual
+// member, as a member of one of the user groups, or of one of the channels, granted a role on the
+// playbook. It returns the empty string if the user has no role.
+func PlaybookRole(userID string, playbook Playbook, pluginAPI *pluginapi.Client) string {
+	role := playbook.MemberRole(userID)
+	if role == PlaybookRoleAdmin {
+		return role
+	}
+
+	var userGroupIDs map[string]bool
+	for _, group := range playbook.MemberGroups {
+		if playbookRoleRanks[group.Role] <= playbookRoleRanks[role] {
+			continue
+		}
+
+		switch group.Type {
+		case PlaybookMemberGroupTypeGroup:
+			if userGroupIDs == nil {
+				userGroupIDs = make(map[string]bool)
+				userGroups, err := pluginAPI.Group.ListForUser(userID)
+				if err != nil {
+					pluginAPI.Log.Warn("failed to list the groups of the user", "user_id", userID, "error", err.Error())
+				}
+				for _, userGroup := range userGroups {
+					userGroupIDs[userGroup.Id] = true
+				}
+			}
+			if userGroupIDs[group.ID] {
+				role = group.Role
+			}
+		case PlaybookMemberGroupTypeChannel:
+			if _, err := pluginAPI.Channel.GetMember(group.ID, userID); err == nil {
+				role = group.Role
+			}
 		}
 	}
 
-	return errors.Wrap(noAccessErr, "no playbook access; not on list of playbook members")
+	return role
+}
+
+// checkMemberGroups returns an error if userID adds a member group that doesn't exist, or a
+// channel they can't read.
+func checkMemberGroups(userID string, playbook, oldPlaybook Playbook, pluginAPI *pluginapi.Client) error {
+	oldGroups := make(map[PlaybookMemberGroup]bool, len(oldPlaybook.MemberGroups))
+	for _, group := range oldPlaybook.MemberGroups {
+		oldGroups[PlaybookMemberGroup{Type: group.Type, ID: group.ID}] = true
+	}
+
+	for _, group := range playbook.MemberGroups {
+		if oldGroups[PlaybookMemberGroup{Type: group.Type, ID: group.ID}] {
+			continue
+		}
+
+		switch group.Type {
+		case PlaybookMemberGroupTypeGroup:
+			if _, err := pluginAPI.Group.Get(group.ID); err != nil {
+				return errors.Wrapf(err, "invalid member group %s", group.ID)
+			}
+		case PlaybookMemberGroupTypeChannel:
+			if !pluginAPI.User.HasPermissionToChannel(userID, group.ID, model.PermissionReadChannel) {
+				return errors.Wrapf(
+					ErrNoPermissions,
+					"userID %s does not have permission to read the member channel %s",
+					userID,
+					group.ID,
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkSharedTeams returns an error if userID shares the playbook with a team they can't view,
+// or with all teams without being a system admin.
+func checkSharedTeams(userID string, playbook, oldPlaybook Playbook, pluginAPI *pluginapi.Client) error {
+	if playbook.SharedWithAllTeams && !oldPlaybook.SharedWithAllTeams && !IsAdmin(userID, pluginAPI) {
+		return errors.Wrapf(ErrNoPermissions, "userID %s to share the playbook with all teams", userID)
+	}
+
+	oldTeamIDs := make(map[string]bool, len(oldPlaybook.SharedTeamIDs))
+	for _, teamID := range oldPlaybook.SharedTeamIDs {
+		oldTeamIDs[teamID] = true
+	}
+
+	for _, teamID := range playbook.SharedTeamIDs {
+		if oldTeamIDs[teamID] {
+			continue
+		}
+
+		if !CanViewTeam(userID, teamID, pluginAPI) {
+			return errors.Wrapf(
+				ErrNoPermissions,
+				"userID %s does not have permission to share the playbook with team %s",
+				userID,
+				teamID,
+			)
+		}
+	}
+
+	return nil
 }
 
 // checkPlaybookIsNotUsingE20Features features returns a non-nil error if the playbook is using E20 features
 func checkPlaybookIsNotUsingE20Features(playbook Playbook) error {
-	if len(playbook.MemberIDs) > 0 {
+	if len(playbook.Members) > 0 || len(playbook.MemberIDs) > 0 || len(playbook.MemberGroups) > 0 {
 		return errors.Wrap(ErrLicensedFeature, "restricting playbook editing to specific users is not available with your current subscription")
 	}
 
@@ -304,16 +523,69 @@ func CreatePlaybook(userID string, playbook Playbook, cfgService config.Service,
 		}
 	}
 
+	if err := checkSharedTeams(userID, playbook, Playbook{}, pluginAPI); err != nil {
+		return err
+	}
+
+	return checkMemberGroups(userID, playbook, Playbook{}, pluginAPI)
+}
+
+// ChecklistLibraryView returns nil if userID can view the checklist library of teamID: any
+// member of the team can.
+func ChecklistLibraryView(userID, teamID string, pluginAPI *pluginapi.Client) error {
+	if !CanViewTeam(userID, teamID, pluginAPI) {
+		return errors.Wrapf(ErrNoPermissions, "userID %s to view the checklist library of teamID %s", userID, teamID)
+	}
+
 	return nil
 }
 
+// ChecklistLibraryModify returns nil if userID can add, change and archive the checklists of the
+// library of teamID: the members of the team who can create playbooks, except guests.
+func ChecklistLibraryModify(userID, teamID string, cfgService config.Service, pluginAPI *pluginapi.Client) error {
+	if err := isPlaybookCreator(userID, cfgService); err != nil {
+		return err
+	}
+
+	if isGuest, err := IsGuest(userID, pluginAPI); err != nil {
+		return err
+	} else if isGuest {
+		return errors.Wrapf(ErrNoPermissions, "userID %s is a guest and can't modify the checklist library", userID)
+	}
+
+	return ChecklistLibraryView(userID, teamID, pluginAPI)
+}
+
 // DANGER This is not a complete check. There is more in the current handler for updatePlaybook
 // if you need to use this function, integrate that here first.
 func PlaybookModify(userID string, playbook, oldPlaybook Playbook, cfgService config.Service, pluginAPI *pluginapi.Client, playbookService PlaybookService) error {
-	if err := PlaybookAccess(userID, oldPlaybook.ID, playbookService, pluginAPI); err != nil {
+	if err := PlaybookRoleAccess(userID, oldPlaybook.ID, PlaybookRoleEditor, playbookService, pluginAPI); err != nil {
+		return err
+	}
+
+	// Only the playbook admins manage the members.
+	if !membersEqual(playbook.effectiveMembers(), oldPlaybook.effectiveMembers()) ||
+		!memberGroupsEqual(playbook.MemberGroups, oldPlaybook.MemberGroups) {
+		if err := CheckPlaybookRole(userID, oldPlaybook, PlaybookRoleAdmin, pluginAPI); err != nil {
+			return errors.Wrap(err, "change the playbook members")
+		}
+
+		if err := checkMemberGroups(userID, playbook, oldPlaybook, pluginAPI); err != nil {
+			return err
+		}
+	}
+
+	if err := checkSharedTeams(userID, playbook, oldPlaybook, pluginAPI); err != nil {
 		return err
 	}
 
+	// The run permission policy is as sensitive as the members.
+	if playbook.RunPermissions != oldPlaybook.RunPermissions {
+		if err := CheckPlaybookRole(userID, oldPlaybook, PlaybookRoleAdmin, pluginAPI); err != nil {
+			return errors.Wrap(err, "change the run permissions")
+		}
+	}
+
 	oldChannelsSet := make(map[string]bool)
 	for _, channelID := range oldPlaybook.BroadcastChannelIDs {
 		oldChannelsSet[channelID] = true
@@ -355,3 +627,49 @@ func ModifyPlaybookCreators(userID string, isAdmin bool, config config.Service)
 
 	return errors.Wrap(ErrNoPermissions, "not a playbook creator")
 }
+
+// ChecklistLibraryUpdate returns nil if userID can change the title and items of the checklist
+// library entry. The change reaches every playbook following the entry, so on top of
+// ChecklistLibraryModify it takes the editor role on all of them, unless userID is a team admin.
+func ChecklistLibraryUpdate(userID string, entry ChecklistLibraryEntry, cfgService config.Service, playbookService PlaybookService, pluginAPI *pluginapi.Client) error {
+	if err := ChecklistLibraryModify(userID, entry.TeamID, cfgService, pluginAPI); err != nil {
+		return err
+	}
+
+	if IsAdmin(userID, pluginAPI) || pluginAPI.User.HasPermissionToTeam(userID, entry.TeamID, model.PermissionManageTeam) {
+		return nil
+	}
+
+	playbookIDs, err := playbookService.GetPlaybookIDsReferencingLibrary(entry.ID)
+	if err != nil {
+		return errors.Wrapf(err, "Unable to get the playbooks referencing library checklist `%s`", entry.ID)
+	}
+
+	for _, playbookID := range playbookIDs {
+		playbook, err := playbookService.Get(playbookID)
+		if err != nil {
+			return errors.Wrapf(err, "Unable to get playbook to determine permissions, playbook id `%s`", playbookID)
+		}
+
+		if !followsLibraryEntry(playbook.Checklists, entry.ID) {
+			continue
+		}
+
+		if err := CheckPlaybookRole(userID, playbook, PlaybookRoleEditor, pluginAPI); err != nil {
+			return errors.Wrapf(err, "change library checklist %s used by playbook %s", entry.ID, playbookID)
+		}
+	}
+
+	return nil
+}
+
+// followsLibraryEntry returns true if one of the checklists follows the latest version of the
+// checklist library entry entryID.
+func followsLibraryEntry(checklists []Checklist, entryID string) bool {
+	for _, checklist := range checklists {
+		if checklist.LibraryID == entryID && checklist.LibraryVersion == 0 {
+			return true
+		}
+	}
+	return false
+}
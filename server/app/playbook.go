@@ -11,43 +11,477 @@ import (
 // Playbook represents a desired business outcome, from which playbook runs are started to solve
 // a specific instance.
 type Playbook struct {
-	ID                                   string      `json:"id"`
-	Title                                string      `json:"title"`
-	Description                          string      `json:"description"`
-	TeamID                               string      `json:"team_id"`
-	CreatePublicPlaybookRun              bool        `json:"create_public_playbook_run"`
-	CreateAt                             int64       `json:"create_at"`
-	UpdateAt                             int64       `json:"update_at"`
-	DeleteAt                             int64       `json:"delete_at"`
-	NumStages                            int64       `json:"num_stages"`
-	NumSteps                             int64       `json:"num_steps"`
-	NumRuns                              int64       `json:"num_runs"`
-	NumActions                           int64       `json:"num_actions"`
-	LastRunAt                            int64       `json:"last_run_at"`
-	Checklists                           []Checklist `json:"checklists"`
-	MemberIDs                            []string    `json:"member_ids"`
-	ReminderMessageTemplate              string      `json:"reminder_message_template"`
-	ReminderTimerDefaultSeconds          int64       `json:"reminder_timer_default_seconds"`
-	InvitedUserIDs                       []string    `json:"invited_user_ids"`
-	InvitedGroupIDs                      []string    `json:"invited_group_ids"`
-	InviteUsersEnabled                   bool        `json:"invite_users_enabled"`
-	DefaultOwnerID                       string      `json:"default_owner_id"`
-	DefaultOwnerEnabled                  bool        `json:"default_owner_enabled"`
-	BroadcastChannelIDs                  []string    `json:"broadcast_channel_ids"`
-	BroadcastEnabled                     bool        `json:"broadcast_enabled"`
-	WebhookOnCreationURLs                []string    `json:"webhook_on_creation_urls"`
-	WebhookOnCreationEnabled             bool        `json:"webhook_on_creation_enabled"`
-	MessageOnJoin                        string      `json:"message_on_join"`
-	MessageOnJoinEnabled                 bool        `json:"message_on_join_enabled"`
-	RetrospectiveReminderIntervalSeconds int64       `json:"retrospective_reminder_interval_seconds"`
-	RetrospectiveTemplate                string      `json:"retrospective_template"`
-	WebhookOnStatusUpdateURLs            []string    `json:"webhook_on_status_update_urls"`
-	WebhookOnStatusUpdateEnabled         bool        `json:"webhook_on_status_update_enabled"`
-	ExportChannelOnFinishedEnabled       bool        `json:"export_channel_on_finished_enabled"`
-	SignalAnyKeywords                    []string    `json:"signal_any_keywords"`
-	SignalAnyKeywordsEnabled             bool        `json:"signal_any_keywords_enabled"`
-	CategorizeChannelEnabled             bool        `json:"categorize_channel_enabled"`
-	CategoryName                         string      `json:"category_name"`
+	ID                                   string                `json:"id"`
+	Title                                string                `json:"title"`
+	Description                          string                `json:"description"`
+	TeamID                               string                `json:"team_id"`
+	SharedTeamIDs                        []string              `json:"shared_team_ids"`
+	SharedWithAllTeams                   bool                  `json:"shared_with_all_teams"`
+	CreatePublicPlaybookRun              bool                  `json:"create_public_playbook_run"`
+	CreateAt                             int64                 `json:"create_at"`
+	UpdateAt                             int64                 `json:"update_at"`
+	DeleteAt                             int64                 `json:"delete_at"`
+	NumStages                            int64                 `json:"num_stages"`
+	NumSteps                             int64                 `json:"num_steps"`
+	NumRuns                              int64                 `json:"num_runs"`
+	NumActions                           int64                 `json:"num_actions"`
+	LastRunAt                            int64                 `json:"last_run_at"`
+	Checklists                           []Checklist           `json:"checklists"`
+	Members                              []PlaybookMember      `json:"members"`
+	MemberGroups                         []PlaybookMemberGroup `json:"member_groups"`
+	MemberIDs                            []string              `json:"member_ids"`
+	ReminderMessageTemplate              string                `json:"reminder_message_template"`
+	ReminderTimerDefaultSeconds          int64                 `json:"reminder_timer_default_seconds"`
+	InvitedUserIDs                       []string              `json:"invited_user_ids"`
+	InvitedGroupIDs                      []string              `json:"invited_group_ids"`
+	InviteUsersEnabled                   bool                  `json:"invite_users_enabled"`
+	DefaultOwnerID                       string                `json:"default_owner_id"`
+	DefaultOwnerEnabled                  bool                  `json:"default_owner_enabled"`
+	BroadcastChannelIDs                  []string              `json:"broadcast_channel_ids"`
+	BroadcastEnabled                     bool                  `json:"broadcast_enabled"`
+	WebhookOnCreationURLs                []string              `json:"webhook_on_creation_urls"`
+	WebhookOnCreationEnabled             bool                  `json:"webhook_on_creation_enabled"`
+	MessageOnJoin                        string                `json:"message_on_join"`
+	MessageOnJoinEnabled                 bool                  `json:"message_on_join_enabled"`
+	RetrospectiveReminderIntervalSeconds int64                 `json:"retrospective_reminder_interval_seconds"`
+	RetrospectiveTemplate                string                `json:"retrospective_template"`
+	WebhookOnStatusUpdateURLs            []string              `json:"webhook_on_status_update_urls"`
+	WebhookOnStatusUpdateEnabled         bool                  `json:"webhook_on_status_update_enabled"`
+	ExportChannelOnFinishedEnabled       bool                  `json:"export_channel_on_finished_enabled"`
+	SignalAnyKeywords                    []string              `json:"signal_any_keywords"`
+	SignalAnyKeywordsEnabled             bool                  `json:"signal_any_keywords_enabled"`
+	CategorizeChannelEnabled             bool                  `json:"categorize_channel_enabled"`
+	CategoryName                         string                `json:"category_name"`
+	RunPermissions                       RunPermissionPolicy   `json:"run_permissions"`
+	RunStatuses                          []RunStatus           `json:"run_statuses,omitempty"`
+}
+
+const (
+	// PlaybookRoleViewer can view the playbook and its runs.
+	PlaybookRoleViewer = "viewer"
+
+	// PlaybookRoleRunner can also start runs from the playbook.
+	PlaybookRoleRunner = "runner"
+
+	// PlaybookRoleEditor can also edit the playbook.
+	PlaybookRoleEditor = "editor"
+
+	// PlaybookRoleAdmin can also manage the members of the playbook, and delete it.
+	PlaybookRoleAdmin = "admin"
+)
+
+// playbookRoleRanks orders the roles: each role can do everything the lower ones can.
+var playbookRoleRanks = map[string]int{
+	PlaybookRoleViewer: 1,
+	PlaybookRoleRunner: 2,
+	PlaybookRoleEditor: 3,
+	PlaybookRoleAdmin:  4,
+}
+
+// IsValidPlaybookRole returns true if role is one of the playbook roles.
+func IsValidPlaybookRole(role string) bool {
+	_, ok := playbookRoleRanks[role]
+	return ok
+}
+
+// PlaybookMember is a user with a role on a playbook.
+type PlaybookMember struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+// Types of the groups of users that can be granted a role on a playbook.
+const (
+	// PlaybookMemberGroupTypeGroup is a Mattermost user group, e.g. synced from LDAP.
+	PlaybookMemberGroupTypeGroup = "group"
+
+	// PlaybookMemberGroupTypeChannel is the set of the members of a channel.
+	PlaybookMemberGroupTypeChannel = "channel"
+)
+
+// PlaybookMemberGroup grants a role on a playbook to every user of a group or channel. The users
+// are resolved when checking the permissions, so the playbook follows the changes of the group.
+type PlaybookMemberGroup struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Role string `json:"role"`
+}
+
+// IsSharedWithTeam returns true if runs of the playbook can be started in teamID: its own team,
+// one of the teams it is shared with, or any team if it is shared with all of them.
+func (p Playbook) IsSharedWithTeam(teamID string) bool {
+	if p.TeamID == teamID || p.SharedWithAllTeams {
+		return true
+	}
+
+	for _, sharedTeamID := range p.SharedTeamIDs {
+		if sharedTeamID == teamID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NormalizeSharedTeams removes the duplicate shared teams, and the playbook's own team, from
+// SharedTeamIDs. A playbook shared with all teams doesn't need the list, so it is emptied.
+func (p *Playbook) NormalizeSharedTeams() {
+	var sharedTeamIDs []string
+	if !p.SharedWithAllTeams {
+		seen := map[string]bool{p.TeamID: true}
+		for _, teamID := range p.SharedTeamIDs {
+			if seen[teamID] {
+				continue
+			}
+			seen[teamID] = true
+			sharedTeamIDs = append(sharedTeamIDs, teamID)
+		}
+	}
+	p.SharedTeamIDs = sharedTeamIDs
+}
+
+// RunAction is an action on a run that the run permission policy of its playbook controls.
+type RunAction string
+
+const (
+	// RunActionFinish finishes the run.
+	RunActionFinish RunAction = "finish"
+
+	// RunActionChangeOwner hands the run over to another owner.
+	RunActionChangeOwner RunAction = "change_owner"
+
+	// RunActionEditChecklists changes the checklists of the run or the state of their items.
+	RunActionEditChecklists RunAction = "edit_checklists"
+
+	// RunActionPostStatusUpdate posts a status update to the run.
+	RunActionPostStatusUpdate RunAction = "post_status_update"
+
+	// RunActionPublishRetrospective publishes, or cancels, the retrospective of the run.
+	RunActionPublishRetrospective RunAction = "publish_retrospective"
+)
+
+// Who a run permission rule allows to perform an action. System admins and the owner of the run
+// are always allowed.
+const (
+	// RunPermissionAllowChannel allows every member of the run channel. It is the default.
+	RunPermissionAllowChannel = "channel"
+
+	// RunPermissionAllowParticipants allows the participants of the run.
+	RunPermissionAllowParticipants = "participants"
+
+	// RunPermissionAllowRole allows the users with at least the rule's role on the playbook,
+	// whether or not they are in the run channel.
+	RunPermissionAllowRole = "role"
+
+	// RunPermissionAllowOwner allows only the owner of the run.
+	RunPermissionAllowOwner = "owner"
+)
+
+// RunPermissionRule defines who may perform an action on the runs of a playbook.
+type RunPermissionRule struct {
+	Allow string `json:"allow"`
+	Role  string `json:"role,omitempty"`
+}
+
+// RunPermissionPolicy holds the rules for the actions on the runs of a playbook. An empty rule
+// allows every member of the run channel.
+type RunPermissionPolicy struct {
+	Finish               RunPermissionRule `json:"finish"`
+	ChangeOwner          RunPermissionRule `json:"change_owner"`
+	EditChecklists       RunPermissionRule `json:"edit_checklists"`
+	PostStatusUpdate     RunPermissionRule `json:"post_status_update"`
+	PublishRetrospective RunPermissionRule `json:"publish_retrospective"`
+}
+
+// Rule returns the rule of the policy for action.
+func (p RunPermissionPolicy) Rule(action RunAction) RunPermissionRule {
+	var rule RunPermissionRule
+	switch action {
+	case RunActionFinish:
+		rule = p.Finish
+	case RunActionChangeOwner:
+		rule = p.ChangeOwner
+	case RunActionEditChecklists:
+		rule = p.EditChecklists
+	case RunActionPostStatusUpdate:
+		rule = p.PostStatusUpdate
+	case RunActionPublishRetrospective:
+		rule = p.PublishRetrospective
+	}
+
+	if rule.Allow == "" {
+		rule.Allow = RunPermissionAllowChannel
+	}
+
+	return rule
+}
+
+// Validate returns an error if a rule allows an unknown set of users, or requires an invalid role.
+func (p RunPermissionPolicy) Validate() error {
+	actions := []RunAction{
+		RunActionFinish,
+		RunActionChangeOwner,
+		RunActionEditChecklists,
+		RunActionPostStatusUpdate,
+		RunActionPublishRetrospective,
+	}
+
+	for _, action := range actions {
+		rule := p.Rule(action)
+		switch rule.Allow {
+		case RunPermissionAllowChannel, RunPermissionAllowParticipants, RunPermissionAllowOwner:
+			if rule.Role != "" {
+				return errors.Errorf("role given for %s, which doesn't allow a role", action)
+			}
+		case RunPermissionAllowRole:
+			if !IsValidPlaybookRole(rule.Role) {
+				return errors.Errorf("invalid role '%s' for %s", rule.Role, action)
+			}
+		default:
+			return errors.Errorf("invalid allow '%s' for %s", rule.Allow, action)
+		}
+	}
+
+	return nil
+}
+
+// IsOpen returns true if the playbook has neither members nor member groups, making it open to
+// everyone on its team.
+func (p Playbook) IsOpen() bool {
+	return len(p.effectiveMembers()) == 0 && len(p.MemberGroups) == 0
+}
+
+// MemberRole returns the role of userID as an individual member of the playbook, or the empty
+// string if they are not one. Everyone has the editor role on an open playbook. The roles granted
+// through the member groups, and to the team admins managing the playbooks without an admin, are
+// resolved by PlaybookRole.
+func (p Playbook) MemberRole(userID string) string {
+	if p.IsOpen() {
+		return PlaybookRoleEditor
+	}
+
+	members := p.effectiveMembers()
+
+	for _, member := range members {
+		if member.UserID == userID {
+			return member.Role
+		}
+	}
+
+	return ""
+}
+
+// HasRole returns true if userID has at least the given role on the playbook as an individual
+// member.
+func (p Playbook) HasRole(userID, role string) bool {
+	return playbookRoleRanks[p.MemberRole(userID)] >= playbookRoleRanks[role]
+}
+
+// effectiveMembers returns Members or, if the playbook wasn't normalized, the users in MemberIDs
+// as editors.
+func (p Playbook) effectiveMembers() []PlaybookMember {
+	if len(p.Members) > 0 || len(p.MemberIDs) == 0 {
+		return p.Members
+	}
+
+	members := make([]PlaybookMember, 0, len(p.MemberIDs))
+	for _, memberID := range p.MemberIDs {
+		members = append(members, PlaybookMember{UserID: memberID, Role: PlaybookRoleEditor})
+	}
+
+	return members
+}
+
+// NormalizeMembers reconciles Members with MemberIDs, the flat list of members kept for the
+// clients that predate the roles: the users missing from a non-nil MemberIDs are removed, and
+// the users only in MemberIDs are added as editors. Members without a role become editors too.
+// MemberIDs is then set to the user IDs of Members.
+func (p *Playbook) NormalizeMembers() {
+	var memberIDs map[string]bool
+	if p.MemberIDs != nil {
+		memberIDs = make(map[string]bool, len(p.MemberIDs))
+		for _, memberID := range p.MemberIDs {
+			memberIDs[memberID] = true
+		}
+	}
+
+	members := []PlaybookMember{}
+	seen := make(map[string]bool, len(p.Members))
+	for _, member := range p.Members {
+		if seen[member.UserID] || (memberIDs != nil && !memberIDs[member.UserID]) {
+			continue
+		}
+		if member.Role == "" {
+			member.Role = PlaybookRoleEditor
+		}
+		seen[member.UserID] = true
+		members = append(members, member)
+	}
+
+	for _, memberID := range p.MemberIDs {
+		if !seen[memberID] {
+			seen[memberID] = true
+			members = append(members, PlaybookMember{UserID: memberID, Role: PlaybookRoleEditor})
+		}
+	}
+
+	p.Members = members
+	p.MemberIDs = make([]string, 0, len(members))
+	for _, member := range members {
+		p.MemberIDs = append(p.MemberIDs, member.UserID)
+	}
+
+	memberGroups := []PlaybookMemberGroup{}
+	seenGroups := make(map[PlaybookMemberGroup]bool, len(p.MemberGroups))
+	for _, group := range p.MemberGroups {
+		key := PlaybookMemberGroup{Type: group.Type, ID: group.ID}
+		if seenGroups[key] {
+			continue
+		}
+		seenGroups[key] = true
+		memberGroups = append(memberGroups, group)
+	}
+	p.MemberGroups = memberGroups
+}
+
+// KeepMemberRoles gives the members listed without a role, including the users only in
+// MemberIDs, the role they had on oldPlaybook, so that the clients unaware of the roles don't
+// demote the existing members when saving the playbook.
+func (p *Playbook) KeepMemberRoles(oldPlaybook Playbook) {
+	oldRoles := make(map[string]string, len(oldPlaybook.Members))
+	for _, member := range oldPlaybook.effectiveMembers() {
+		oldRoles[member.UserID] = member.Role
+	}
+
+	listed := make(map[string]bool, len(p.Members))
+	for i, member := range p.Members {
+		listed[member.UserID] = true
+		if member.Role == "" {
+			p.Members[i].Role = oldRoles[member.UserID]
+		}
+	}
+
+	for _, memberID := range p.MemberIDs {
+		if role, ok := oldRoles[memberID]; ok && !listed[memberID] {
+			listed[memberID] = true
+			p.Members = append(p.Members, PlaybookMember{UserID: memberID, Role: role})
+		}
+	}
+}
+
+// DefaultAdmin makes userID an admin of the playbook if it is restricted and nobody was named
+// admin, so that a new playbook can always be managed by its creator.
+func (p *Playbook) DefaultAdmin(userID string) {
+	if p.IsOpen() || p.hasAdmin() {
+		return
+	}
+
+	for i, member := range p.Members {
+		if member.UserID == userID {
+			p.Members[i].Role = PlaybookRoleAdmin
+			return
+		}
+	}
+
+	p.Members = append(p.Members, PlaybookMember{UserID: userID, Role: PlaybookRoleAdmin})
+	if p.MemberIDs != nil {
+		p.MemberIDs = append(p.MemberIDs, userID)
+	}
+}
+
+// hasAdmin returns true if a member or a member group has the admin role.
+func (p Playbook) hasAdmin() bool {
+	for _, member := range p.effectiveMembers() {
+		if member.Role == PlaybookRoleAdmin {
+			return true
+		}
+	}
+	for _, group := range p.MemberGroups {
+		if group.Role == PlaybookRoleAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateMembers returns an error if a member has no user ID or an invalid role, if a member
+// group has an unknown type, no ID or an invalid role, or if a restricted playbook has no admin.
+func (p Playbook) ValidateMembers() error {
+	return p.validateMembers(true)
+}
+
+// ValidateMemberChanges is ValidateMembers for an update of oldPlaybook. The playbooks restricted
+// before the roles existed have no admin, their members having become editors: they are managed
+// by the team admins and may stay without an admin.
+func (p Playbook) ValidateMemberChanges(oldPlaybook Playbook) error {
+	return p.validateMembers(oldPlaybook.IsOpen() || oldPlaybook.hasAdmin())
+}
+
+func (p Playbook) validateMembers(requireAdmin bool) error {
+	for _, member := range p.Members {
+		if member.UserID == "" {
+			return errors.New("missing member user id")
+		}
+		if !IsValidPlaybookRole(member.Role) {
+			return errors.Errorf("invalid role '%s' for member '%s'", member.Role, member.UserID)
+		}
+	}
+
+	for _, group := range p.MemberGroups {
+		if group.Type != PlaybookMemberGroupTypeGroup && group.Type != PlaybookMemberGroupTypeChannel {
+			return errors.Errorf("invalid member group type '%s'", group.Type)
+		}
+		if group.ID == "" {
+			return errors.Errorf("missing %s id", group.Type)
+		}
+		if !IsValidPlaybookRole(group.Role) {
+			return errors.Errorf("invalid role '%s' for %s '%s'", group.Role, group.Type, group.ID)
+		}
+	}
+
+	if requireAdmin && !p.IsOpen() && !p.hasAdmin() {
+		return errors.New("a playbook with members needs at least one admin")
+	}
+
+	return nil
+}
+
+// membersEqual returns true if both lists give the same roles to the same users.
+func membersEqual(a, b []PlaybookMember) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	roles := make(map[string]string, len(a))
+	for _, member := range a {
+		roles[member.UserID] = member.Role
+	}
+	for _, member := range b {
+		if role, ok := roles[member.UserID]; !ok || role != member.Role {
+			return false
+		}
+	}
+
+	return true
+}
+
+// memberGroupsEqual returns true if both lists give the same roles to the same groups.
+func memberGroupsEqual(a, b []PlaybookMemberGroup) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	roles := make(map[PlaybookMemberGroup]string, len(a))
+	for _, group := range a {
+		roles[PlaybookMemberGroup{Type: group.Type, ID: group.ID}] = group.Role
+	}
+	for _, group := range b {
+		if role, ok := roles[PlaybookMemberGroup{Type: group.Type, ID: group.ID}]; !ok || role != group.Role {
+			return false
+		}
+	}
+
+	return true
 }
 
 func (p Playbook) Clone() Playbook {
@@ -57,7 +491,12 @@ func (p Playbook) Clone() Playbook {
 		newChecklists = append(newChecklists, c.Clone())
 	}
 	newPlaybook.Checklists = newChecklists
+	newPlaybook.Members = append([]PlaybookMember(nil), p.Members...)
+	newPlaybook.MemberGroups = append([]PlaybookMemberGroup(nil), p.MemberGroups...)
 	newPlaybook.MemberIDs = append([]string(nil), p.MemberIDs...)
+	if len(p.SharedTeamIDs) != 0 {
+		newPlaybook.SharedTeamIDs = append([]string(nil), p.SharedTeamIDs...)
+	}
 	if len(p.InvitedUserIDs) != 0 {
 		newPlaybook.InvitedUserIDs = append([]string(nil), p.InvitedUserIDs...)
 	}
@@ -76,9 +515,79 @@ func (p Playbook) Clone() Playbook {
 	if len(p.WebhookOnStatusUpdateURLs) != 0 {
 		newPlaybook.WebhookOnStatusUpdateURLs = append([]string(nil), p.WebhookOnStatusUpdateURLs...)
 	}
+	newPlaybook.RunStatuses = CloneRunStatuses(p.RunStatuses)
 	return newPlaybook
 }
 
+// Duplicate returns a new playbook, not yet stored, copied from p by userID into teamID, or
+// into the team of p if teamID is empty. The title defaults to "Copy of" the title of p.
+// The copy is not shared with other teams. userID becomes an admin of the copy of a restricted
+// playbook, and the copy of an open playbook stays open.
+//
+// The broadcast channels, default owner, category, invited users, members and channel member
+// groups belong to the team of p, and are dropped when copying into another team. Only userID
+// and the user groups stay members.
+func (p Playbook) Duplicate(userID, teamID, title string) Playbook {
+	duplicate := p.Clone()
+	duplicate.ID = ""
+	duplicate.CreateAt = 0
+	duplicate.UpdateAt = 0
+	duplicate.DeleteAt = 0
+	duplicate.NumRuns = 0
+	duplicate.LastRunAt = 0
+	duplicate.SharedTeamIDs = nil
+	duplicate.SharedWithAllTeams = false
+
+	duplicate.Title = title
+	if duplicate.Title == "" {
+		duplicate.Title = "Copy of " + p.Title
+	}
+
+	if teamID != "" && teamID != p.TeamID {
+		duplicate.TeamID = teamID
+		duplicate.BroadcastChannelIDs = nil
+		duplicate.BroadcastEnabled = false
+		duplicate.DefaultOwnerID = ""
+		duplicate.DefaultOwnerEnabled = false
+		duplicate.CategoryName = ""
+		duplicate.CategorizeChannelEnabled = false
+		duplicate.InvitedUserIDs = nil
+		duplicate.Members = nil
+
+		var memberGroups []PlaybookMemberGroup
+		for _, group := range duplicate.MemberGroups {
+			if group.Type == PlaybookMemberGroupTypeGroup {
+				memberGroups = append(memberGroups, group)
+			}
+		}
+		duplicate.MemberGroups = memberGroups
+
+		// The checklist library is per team, so the copy keeps the items without the reference.
+		for i := range duplicate.Checklists {
+			duplicate.Checklists[i].LibraryID = ""
+			duplicate.Checklists[i].LibraryVersion = 0
+		}
+	}
+
+	reassignAutoRunUsers(duplicate.Checklists, userID)
+
+	// Make userID an admin of a copy of a restricted playbook, keeping the order of the other
+	// members. The copy of an open playbook stays open.
+	if !p.IsOpen() {
+		members := []PlaybookMember{{UserID: userID, Role: PlaybookRoleAdmin}}
+		for _, member := range duplicate.Members {
+			if member.UserID != userID {
+				members = append(members, member)
+			}
+		}
+		duplicate.Members = members
+	}
+	duplicate.MemberIDs = nil
+	duplicate.NormalizeMembers()
+
+	return duplicate
+}
+
 func (p Playbook) MarshalJSON() ([]byte, error) {
 	type Alias Playbook
 
@@ -92,9 +601,18 @@ func (p Playbook) MarshalJSON() ([]byte, error) {
 			old.Checklists[j].Items = []ChecklistItem{}
 		}
 	}
+	if old.Members == nil {
+		old.Members = []PlaybookMember{}
+	}
+	if old.MemberGroups == nil {
+		old.MemberGroups = []PlaybookMemberGroup{}
+	}
 	if old.MemberIDs == nil {
 		old.MemberIDs = []string{}
 	}
+	if old.SharedTeamIDs == nil {
+		old.SharedTeamIDs = []string{}
+	}
 	if old.InvitedUserIDs == nil {
 		old.InvitedUserIDs = []string{}
 	}
@@ -127,6 +645,13 @@ type Checklist struct {
 
 	// Items is an array of all the items in the checklist.
 	Items []ChecklistItem `json:"items"`
+
+	// LibraryID is the entry of the checklist library of the team the checklist of a playbook
+	// is taken from, if any. Its title and items are then those of the entry.
+	LibraryID string `json:"library_id,omitempty"`
+
+	// LibraryVersion pins the version of the library entry. 0 follows the latest version.
+	LibraryVersion int `json:"library_version,omitempty"`
 }
 
 func (c Checklist) Clone() Checklist {
@@ -135,6 +660,19 @@ func (c Checklist) Clone() Checklist {
 	return newChecklist
 }
 
+// reassignAutoRunUsers makes the commands of the checklists that run automatically as a user run
+// as userID instead. Only the bot or the user saving a playbook can be chosen to run commands
+// automatically, so a copy can't keep running them as someone else.
+func reassignAutoRunUsers(checklists []Checklist, userID string) {
+	for i := range checklists {
+		for j := range checklists[i].Items {
+			if checklists[i].Items[j].AutoRunUserID != "" {
+				checklists[i].Items[j].AutoRunUserID = userID
+			}
+		}
+	}
+}
+
 // ChecklistItem represents an item in a checklist.
 type ChecklistItem struct {
 	// ID is the identifier of the checklist item.
@@ -175,6 +713,59 @@ type ChecklistItem struct {
 
 	// Description is a string with the markdown content of the long description of the item.
 	Description string `json:"description"`
+
+	// Required is true if the run can't be finished until the item is checked, unless a system
+	// admin overrides it.
+	Required bool `json:"required"`
+
+	// AutoRun is when the command of the item runs without anyone clicking Run: one of the
+	// ChecklistItemAutoRun constants, the empty string if it only runs by hand.
+	AutoRun string `json:"auto_run"`
+
+	// AutoRunOffsetSeconds is the time, in seconds after the run starts, at which the command
+	// runs when AutoRun is ChecklistItemAutoRunAtOffset.
+	AutoRunOffsetSeconds int64 `json:"auto_run_offset_seconds"`
+
+	// AutoRunUserID is the identifier of the user as whom the command runs automatically. The
+	// empty string to run it as the bot.
+	AutoRunUserID string `json:"auto_run_user_id"`
+
+	// HTTPAction, if its URL is not empty, is the HTTP request that can be performed as part of
+	// this item.
+	HTTPAction ChecklistItemHTTPAction `json:"http_action"`
+
+	// HTTPActionLastRun is the timestamp, in milliseconds since epoch, of the last time the item's
+	// HTTP action was performed. 0 if it was never performed.
+	HTTPActionLastRun int64 `json:"http_action_last_run"`
+
+	// HTTPActionLastStatusCode is the status code of the response to the last HTTP action, 0 if
+	// it got no response.
+	HTTPActionLastStatusCode int `json:"http_action_last_status_code"`
+
+	// HTTPActionLastResponse is the truncated body of the response to the last HTTP action, or
+	// the error if it got no response.
+	HTTPActionLastResponse string `json:"http_action_last_response"`
+
+	// Approval, if it has approvers, requires other users to sign off the item once it's checked
+	// off before it's closed.
+	Approval ChecklistItemApproval `json:"approval"`
+
+	// ApprovalRequestedBy is the identifier of the user who checked off the item and waits for
+	// its approval. The empty string if its approval wasn't requested.
+	ApprovalRequestedBy string `json:"approval_requested_by"`
+
+	// ApprovalRequestedAt is the timestamp, in milliseconds since epoch, of the last time the
+	// item's approval was requested. 0 if it was never requested.
+	ApprovalRequestedAt int64 `json:"approval_requested_at"`
+
+	// ApprovedBy are the identifiers of the users who signed off the item since its approval was
+	// requested.
+	ApprovedBy []string `json:"approved_by,omitempty"`
+}
+
+// HasHTTPAction returns true if an HTTP request can be performed as part of the item.
+func (i ChecklistItem) HasHTTPAction() bool {
+	return i.HTTPAction.URL != ""
 }
 
 type GetPlaybooksResults struct {
@@ -182,6 +773,9 @@ type GetPlaybooksResults struct {
 	PageCount  int        `json:"page_count"`
 	HasMore    bool       `json:"has_more"`
 	Items      []Playbook `json:"items"`
+
+	// NextCursor is the cursor to get the next page with, if HasMore.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // MarshalJSON customizes the JSON marshalling for GetPlaybooksResults by rendering a nil Items as
@@ -205,10 +799,13 @@ func (r GetPlaybooksResults) MarshalJSON() ([]byte, error) {
 // PlaybookService is the playbook service for managing playbooks
 // userID is the user initiating the event.
 type PlaybookService interface {
-	// Get retrieves a playbook. Returns ErrNotFound if not found.
+	// Get retrieves a playbook. Returns ErrNotFound if not found. The checklists taken from
+	// the checklist library are expanded.
 	Get(id string) (Playbook, error)
 
-	// Create creates a new playbook
+	// Create creates a new playbook. Returns ErrMalformedPlaybook if its checklists reference
+	// the checklist library of another team, and an InvalidPlaybookError if its content has
+	// issues that prevent saving it.
 	Create(playbook Playbook, userID string) (string, error)
 
 	// GetPlaybooks retrieves all playbooks
@@ -220,15 +817,31 @@ type PlaybookService interface {
 	// GetNumPlaybooksForTeam retrieves the number of playbooks in a given team
 	GetNumPlaybooksForTeam(teamID string) (int, error)
 
+	// GetPlaybookIDsReferencingLibrary retrieves the playbooks, archived or not, whose
+	// checklists may reference the checklist library entry entryID.
+	GetPlaybookIDsReferencingLibrary(entryID string) ([]string, error)
+
+	// GetRunPermissions retrieves only the run permission policy of a playbook. Returns
+	// ErrNotFound if not found.
+	GetRunPermissions(id string) (RunPermissionPolicy, error)
+
 	// GetSuggestedPlaybooks returns suggested playbooks and triggers for the user message
 	GetSuggestedPlaybooks(teamID, userID, message string) ([]*CachedPlaybook, []string)
 
-	// Update updates a playbook
+	// Update updates a playbook. The checklists taken from the checklist library whose title or
+	// items were edited stop following the library and keep the edits. Returns an
+	// InvalidPlaybookError if its content has issues that prevent saving it.
 	Update(playbook Playbook, userID string) error
 
-	// Delete deletes a playbook
+	// Delete archives a playbook: it's hidden from the lists until restored.
 	Delete(playbook Playbook, userID string) error
 
+	// Restore brings back an archived playbook
+	Restore(playbook Playbook, userID string) error
+
+	// Purge permanently deletes an archived playbook. Its runs are kept.
+	Purge(playbook Playbook, userID string) error
+
 	// MessageHasBeenPosted suggests playbooks to the user if triggered
 	MessageHasBeenPosted(sessionID string, post *model.Post)
 }
@@ -260,11 +873,24 @@ type PlaybookStore interface {
 	// GetPlaybookIDsForUser retrieves playbooks user can access
 	GetPlaybookIDsForUser(userID, teamID string) ([]string, error)
 
+	// GetPlaybookIDsReferencingLibrary retrieves the playbooks, archived or not, whose
+	// checklists may reference the checklist library entry entryID.
+	GetPlaybookIDsReferencingLibrary(entryID string) ([]string, error)
+
+	// GetRunPermissions retrieves the run permission policy of a playbook
+	GetRunPermissions(id string) (RunPermissionPolicy, error)
+
 	// Update updates a playbook
 	Update(playbook Playbook) error
 
-	// Delete deletes a playbook
+	// Delete archives a playbook
 	Delete(id string) error
+
+	// Restore unarchives a playbook
+	Restore(id string) error
+
+	// Purge permanently deletes a playbook and its members, and detaches its runs from it
+	Purge(id string) error
 }
 
 // PlaybookTelemetry defines the methods that the Playbook service needs from the RudderTelemetry.
@@ -279,6 +905,12 @@ type PlaybookTelemetry interface {
 	// DeletePlaybook tracks the deletion of a playbook.
 	DeletePlaybook(playbook Playbook, userID string)
 
+	// RestorePlaybook tracks the restoration of an archived playbook.
+	RestorePlaybook(playbook Playbook, userID string)
+
+	// PurgePlaybook tracks the permanent deletion of an archived playbook.
+	PurgePlaybook(playbook Playbook, userID string)
+
 	// FrontendTelemetryForPlaybook tracks an event originating from the frontend
 	FrontendTelemetryForPlaybook(playbook Playbook, userID, action string)
 
@@ -310,6 +942,17 @@ type PlaybookFilterOptions struct {
 	// Pagination options.
 	Page    int
 	PerPage int
+
+	// Cursor continues the listing right after the last item of a previous page, as given by
+	// GetPlaybooksResults.NextCursor. When set, Page is ignored.
+	Cursor string
+
+	// SkipCount skips counting all the matching playbooks, leaving TotalCount and PageCount of
+	// the results at 0.
+	SkipCount bool
+
+	// WithArchived includes the archived playbooks.
+	WithArchived bool
 }
 
 // Clone duplicates the given options.
@@ -331,6 +974,7 @@ func (o PlaybookFilterOptions) Validate() (PlaybookFilterOptions, error) {
 	case SortByTitle:
 	case SortByStages:
 	case SortBySteps:
+	case SortByRuns:
 	case "": // default
 		options.Sort = SortByID
 	default:
@@ -347,5 +991,12 @@ func (o PlaybookFilterOptions) Validate() (PlaybookFilterOptions, error) {
 		return PlaybookFilterOptions{}, errors.Errorf("unsupported direction '%s'", options.Direction)
 	}
 
+	if options.Cursor != "" {
+		if err := validatePageCursor(options.Cursor, options.Sort, options.Direction); err != nil {
+			return PlaybookFilterOptions{}, err
+		}
+		options.Page = 0
+	}
+
 	return options, nil
 }
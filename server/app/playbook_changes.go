@@ -0,0 +1,330 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// PlaybookChangeAddChecklist adds a checklist of the run to the playbook.
+	PlaybookChangeAddChecklist = "add_checklist"
+
+	// PlaybookChangeAddItem adds an item added during the run to a checklist of the playbook.
+	PlaybookChangeAddItem = "add_item"
+
+	// PlaybookChangeRemoveItem removes an item removed during the run from the playbook.
+	PlaybookChangeRemoveItem = "remove_item"
+
+	// PlaybookChangeUpdateItem updates the description and command of an item of the playbook
+	// edited during the run.
+	PlaybookChangeUpdateItem = "update_item"
+)
+
+// PlaybookChange is a difference between the checklists of a run and those of its playbook,
+// that an editor of the playbook can accept to merge it back into the playbook.
+type PlaybookChange struct {
+	// ID identifies the change from its content: it's the same as long as the checklists of the
+	// run and of the playbook don't change.
+	ID string `json:"id"`
+
+	// Type is one of PlaybookChangeAddChecklist, PlaybookChangeAddItem,
+	// PlaybookChangeRemoveItem or PlaybookChangeUpdateItem.
+	Type string `json:"type"`
+
+	// ChecklistTitle is the title of the checklist added or changed.
+	ChecklistTitle string `json:"checklist_title"`
+
+	// Checklist is the checklist added, without the state of the run.
+	Checklist *Checklist `json:"checklist,omitempty"`
+
+	// Item is the item added or updated, without the state of the run.
+	Item *ChecklistItem `json:"item,omitempty"`
+
+	// Before is the item removed or updated, as in the playbook.
+	Before *ChecklistItem `json:"before,omitempty"`
+
+	checklistKey titleKey
+	itemKey      titleKey
+	runItemIndex int
+}
+
+// titleKey identifies a checklist or item by its title and the number of previous ones with
+// the same title, since neither has an ID in a playbook.
+type titleKey struct {
+	title string
+	n     int
+}
+
+func checklistKeys(checklists []Checklist) []titleKey {
+	titles := make([]string, 0, len(checklists))
+	for _, checklist := range checklists {
+		titles = append(titles, checklist.Title)
+	}
+	return titleKeys(titles)
+}
+
+func itemKeys(items []ChecklistItem) []titleKey {
+	titles := make([]string, 0, len(items))
+	for _, item := range items {
+		titles = append(titles, item.Title)
+	}
+	return titleKeys(titles)
+}
+
+func titleKeys(titles []string) []titleKey {
+	seen := make(map[string]int, len(titles))
+	keys := make([]titleKey, 0, len(titles))
+	for _, title := range titles {
+		keys = append(keys, titleKey{title: title, n: seen[title]})
+		seen[title]++
+	}
+	return keys
+}
+
+func indexOfKey(keys []titleKey, key titleKey) int {
+	for i := range keys {
+		if keys[i] == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// ChecklistsForPlaybook returns a copy of the checklists of a run without their IDs, nor the
+// state, assignees and command runs of their items, to be used in a playbook.
+func ChecklistsForPlaybook(checklists []Checklist) []Checklist {
+	var playbookChecklists []Checklist
+	for _, checklist := range checklists {
+		playbookChecklist := checklist.Clone()
+		playbookChecklist.ID = ""
+		for i, item := range playbookChecklist.Items {
+			playbookChecklist.Items[i] = ChecklistItem{
+				Title:                item.Title,
+				Command:              item.Command,
+				Description:          item.Description,
+				Required:             item.Required,
+				AutoRun:              item.AutoRun,
+				AutoRunOffsetSeconds: item.AutoRunOffsetSeconds,
+				AutoRunUserID:        item.AutoRunUserID,
+				HTTPAction:           item.HTTPAction,
+				Approval:             item.Approval,
+			}
+		}
+		playbookChecklists = append(playbookChecklists, playbookChecklist)
+	}
+	return playbookChecklists
+}
+
+// DiffPlaybookChecklists returns the changes to apply to the checklists of a playbook to get
+// those of one of its runs. The checklists and items are matched by title, in order.
+// Checklists removed during the run are ignored.
+func DiffPlaybookChecklists(playbookChecklists, runChecklists []Checklist) []PlaybookChange {
+	runChecklists = ChecklistsForPlaybook(runChecklists)
+
+	playbookKeys := checklistKeys(playbookChecklists)
+
+	var changes []PlaybookChange
+	for i, key := range checklistKeys(runChecklists) {
+		runChecklist := runChecklists[i]
+
+		playbookIndex := indexOfKey(playbookKeys, key)
+		if playbookIndex < 0 {
+			changes = append(changes, newPlaybookChange(PlaybookChange{
+				Type:           PlaybookChangeAddChecklist,
+				ChecklistTitle: runChecklist.Title,
+				Checklist:      &runChecklist,
+				checklistKey:   key,
+			}))
+			continue
+		}
+
+		changes = append(changes, diffItems(key, playbookChecklists[playbookIndex].Items, runChecklist.Items)...)
+	}
+
+	return changes
+}
+
+func diffItems(checklistKey titleKey, playbookItems, runItems []ChecklistItem) []PlaybookChange {
+	var changes []PlaybookChange
+
+	playbookKeys := itemKeys(playbookItems)
+	runKeys := itemKeys(runItems)
+
+	for i, key := range runKeys {
+		runItem := runItems[i]
+
+		playbookIndex := indexOfKey(playbookKeys, key)
+		if playbookIndex < 0 {
+			changes = append(changes, newPlaybookChange(PlaybookChange{
+				Type:           PlaybookChangeAddItem,
+				ChecklistTitle: checklistKey.title,
+				Item:           &runItem,
+				checklistKey:   checklistKey,
+				itemKey:        key,
+				runItemIndex:   i,
+			}))
+			continue
+		}
+
+		playbookItem := playbookItems[playbookIndex]
+		if playbookItem.Description != runItem.Description || playbookItem.Command != runItem.Command {
+			changes = append(changes, newPlaybookChange(PlaybookChange{
+				Type:           PlaybookChangeUpdateItem,
+				ChecklistTitle: checklistKey.title,
+				Item:           &runItem,
+				Before:         &playbookItem,
+				checklistKey:   checklistKey,
+				itemKey:        key,
+			}))
+		}
+	}
+
+	for i, key := range playbookKeys {
+		if indexOfKey(runKeys, key) < 0 {
+			playbookItem := playbookItems[i]
+			changes = append(changes, newPlaybookChange(PlaybookChange{
+				Type:           PlaybookChangeRemoveItem,
+				ChecklistTitle: checklistKey.title,
+				Before:         &playbookItem,
+				checklistKey:   checklistKey,
+				itemKey:        key,
+			}))
+		}
+	}
+
+	return changes
+}
+
+// newPlaybookChange sets the ID of the change from its content.
+func newPlaybookChange(change PlaybookChange) PlaybookChange {
+	parts := []string{
+		change.Type,
+		change.checklistKey.title,
+		fmt.Sprint(change.checklistKey.n),
+		change.itemKey.title,
+		fmt.Sprint(change.itemKey.n),
+	}
+	if change.Item != nil {
+		parts = append(parts, change.Item.Description, change.Item.Command)
+	}
+	if change.Checklist != nil {
+		for _, item := range change.Checklist.Items {
+			parts = append(parts, item.Title, item.Description, item.Command)
+		}
+	}
+
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	change.ID = hex.EncodeToString(hash[:])[:12]
+
+	return change
+}
+
+// ApplyPlaybookChanges returns the checklists of a playbook with the given changes between them
+// and the checklists of a run applied, as listed by DiffPlaybookChecklists. It fails if any of
+// the changes doesn't apply anymore.
+func ApplyPlaybookChanges(playbookChecklists, runChecklists []Checklist, changeIDs []string) ([]Checklist, error) {
+	changes := DiffPlaybookChecklists(playbookChecklists, runChecklists)
+
+	current := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		current[change.ID] = true
+	}
+
+	accepted := make(map[string]bool, len(changeIDs))
+	for _, id := range changeIDs {
+		if !current[id] {
+			return nil, errors.Errorf("change %s doesn't apply to the playbook anymore", id)
+		}
+		accepted[id] = true
+	}
+
+	runChecklists = ChecklistsForPlaybook(runChecklists)
+
+	var result []Checklist
+	for _, checklist := range playbookChecklists {
+		result = append(result, checklist.Clone())
+	}
+
+	for _, change := range changes {
+		if !accepted[change.ID] {
+			continue
+		}
+
+		if change.Type == PlaybookChangeAddChecklist {
+			result = append(result, *change.Checklist)
+			continue
+		}
+
+		checklistIndex := indexOfKey(checklistKeys(result), change.checklistKey)
+		if checklistIndex < 0 {
+			return nil, errors.Errorf("checklist %s not found in the playbook", change.ChecklistTitle)
+		}
+		items := result[checklistIndex].Items
+		keys := itemKeys(items)
+
+		switch change.Type {
+		case PlaybookChangeAddItem:
+			// Insert the item after the closest previous item of the run that is in the playbook.
+			runChecklist := runChecklists[indexOfKey(checklistKeys(runChecklists), change.checklistKey)]
+			runKeys := itemKeys(runChecklist.Items)
+			position := 0
+			for i := change.runItemIndex - 1; i >= 0; i-- {
+				if previous := indexOfKey(keys, runKeys[i]); previous >= 0 {
+					position = previous + 1
+					break
+				}
+			}
+			items = append(items[:position], append([]ChecklistItem{*change.Item}, items[position:]...)...)
+
+		case PlaybookChangeRemoveItem, PlaybookChangeUpdateItem:
+			itemIndex := indexOfKey(keys, change.itemKey)
+			if itemIndex < 0 {
+				return nil, errors.Errorf("item %s not found in the checklist %s of the playbook", change.itemKey.title, change.ChecklistTitle)
+			}
+			if change.Type == PlaybookChangeRemoveItem {
+				items = append(items[:itemIndex], items[itemIndex+1:]...)
+			} else {
+				items[itemIndex].Description = change.Item.Description
+				items[itemIndex].Command = change.Item.Command
+			}
+		}
+
+		result[checklistIndex].Items = items
+	}
+
+	return result, nil
+}
+
+// PlaybookFromRun returns a new playbook, not yet stored, with the checklists of a run, in the
+// team of the run. It's a copy of source, the playbook of the run, if given, or an empty
+// playbook otherwise. The title defaults to the name of the run. The new playbook is open, like
+// an empty or open source, unless restricted is set or the source is restricted; userID is then
+// an admin of it.
+func PlaybookFromRun(userID string, playbookRun *PlaybookRun, source *Playbook, title string, restricted bool) Playbook {
+	if title == "" {
+		title = playbookRun.Name
+	}
+
+	var playbook Playbook
+	if source != nil {
+		playbook = source.Duplicate(userID, playbookRun.TeamID, title)
+	} else {
+		playbook = Playbook{
+			Title:  title,
+			TeamID: playbookRun.TeamID,
+		}
+	}
+	if restricted && playbook.IsOpen() {
+		playbook.Members = []PlaybookMember{{UserID: userID, Role: PlaybookRoleAdmin}}
+		playbook.MemberIDs = nil
+		playbook.NormalizeMembers()
+	}
+	playbook.Checklists = ChecklistsForPlaybook(playbookRun.Checklists)
+	reassignAutoRunUsers(playbook.Checklists, userID)
+
+	return playbook
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPlaybookChecklists(t *testing.T) {
+	playbookChecklists := []Checklist{
+		{
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{Title: "Page on-call"},
+				{Title: "Open incident doc", Description: "Use the template"},
+				{Title: "Notify support"},
+			},
+		},
+	}
+
+	runChecklists := []Checklist{
+		{
+			ID:    "checklist1",
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{ID: "item1", Title: "Page on-call", State: ChecklistItemStateClosed, AssigneeID: "user1"},
+				{ID: "item2", Title: "Check dashboards"},
+				{ID: "item3", Title: "Open incident doc", Description: "Use the new template"},
+			},
+		},
+		{
+			ID:    "checklist2",
+			Title: "Follow up",
+			Items: []ChecklistItem{
+				{ID: "item4", Title: "Write retrospective", State: ChecklistItemStateClosed},
+			},
+		},
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		require.Empty(t, DiffPlaybookChecklists(playbookChecklists, ChecklistsForPlaybook(playbookChecklists)))
+	})
+
+	t.Run("lists added, updated and removed items, and added checklists", func(t *testing.T) {
+		changes := DiffPlaybookChecklists(playbookChecklists, runChecklists)
+		require.Len(t, changes, 4)
+
+		require.Equal(t, PlaybookChangeAddItem, changes[0].Type)
+		require.Equal(t, "Triage", changes[0].ChecklistTitle)
+		require.Equal(t, "Check dashboards", changes[0].Item.Title)
+		require.Empty(t, changes[0].Item.ID)
+
+		require.Equal(t, PlaybookChangeUpdateItem, changes[1].Type)
+		require.Equal(t, "Use the new template", changes[1].Item.Description)
+		require.Equal(t, "Use the template", changes[1].Before.Description)
+
+		require.Equal(t, PlaybookChangeRemoveItem, changes[2].Type)
+		require.Equal(t, "Notify support", changes[2].Before.Title)
+
+		require.Equal(t, PlaybookChangeAddChecklist, changes[3].Type)
+		require.Equal(t, "Follow up", changes[3].ChecklistTitle)
+		require.Empty(t, changes[3].Checklist.ID)
+		require.Equal(t, []ChecklistItem{{Title: "Write retrospective"}}, changes[3].Checklist.Items)
+	})
+
+	t.Run("IDs are stable", func(t *testing.T) {
+		first := DiffPlaybookChecklists(playbookChecklists, runChecklists)
+		second := DiffPlaybookChecklists(playbookChecklists, runChecklists)
+		for i := range first {
+			require.Equal(t, first[i].ID, second[i].ID)
+			require.Len(t, first[i].ID, 12)
+		}
+	})
+}
+
+func TestApplyPlaybookChanges(t *testing.T) {
+	playbookChecklists := []Checklist{
+		{
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{Title: "Page on-call"},
+				{Title: "Notify support"},
+			},
+		},
+	}
+
+	runChecklists := []Checklist{
+		{
+			ID:    "checklist1",
+			Title: "Triage",
+			Items: []ChecklistItem{
+				{ID: "item1", Title: "Page on-call", State: ChecklistItemStateClosed},
+				{ID: "item2", Title: "Check dashboards", Command: "/grafana"},
+				{ID: "item3", Title: "Notify support", Description: "In ~support"},
+			},
+		},
+	}
+
+	changes := DiffPlaybookChecklists(playbookChecklists, runChecklists)
+	require.Len(t, changes, 2)
+
+	t.Run("applies only the given changes", func(t *testing.T) {
+		result, err := ApplyPlaybookChanges(playbookChecklists, runChecklists, []string{changes[0].ID})
+		require.NoError(t, err)
+		require.Equal(t, []Checklist{
+			{
+				Title: "Triage",
+				Items: []ChecklistItem{
+					{Title: "Page on-call"},
+					{Title: "Check dashboards", Command: "/grafana"},
+					{Title: "Notify support"},
+				},
+			},
+		}, result)
+
+		// The playbook checklists are left untouched.
+		require.Len(t, playbookChecklists[0].Items, 2)
+	})
+
+	t.Run("applying all the changes matches the run", func(t *testing.T) {
+		result, err := ApplyPlaybookChanges(playbookChecklists, runChecklists, []string{changes[0].ID, changes[1].ID})
+		require.NoError(t, err)
+		require.Equal(t, ChecklistsForPlaybook(runChecklists), result)
+	})
+
+	t.Run("stale change", func(t *testing.T) {
+		_, err := ApplyPlaybookChanges(playbookChecklists, runChecklists, []string{"0123456789ab"})
+		require.Error(t, err)
+	})
+}
+
+func TestPlaybookFromRun(t *testing.T) {
+	playbookRun := &PlaybookRun{
+		Name:   "Outage",
+		TeamID: "team1",
+		Checklists: []Checklist{
+			{ID: "checklist1", Title: "Triage", Items: []ChecklistItem{{ID: "item1", Title: "Page on-call", State: ChecklistItemStateClosed}}},
+		},
+	}
+
+	t.Run("without a playbook", func(t *testing.T) {
+		playbook := PlaybookFromRun("user1", playbookRun, nil, "", false)
+		require.Equal(t, "Outage", playbook.Title)
+		require.Equal(t, "team1", playbook.TeamID)
+		require.Empty(t, playbook.ID)
+		require.True(t, playbook.IsOpen())
+		require.Equal(t, []Checklist{{Title: "Triage", Items: []ChecklistItem{{Title: "Page on-call"}}}}, playbook.Checklists)
+	})
+
+	t.Run("restricted, without a playbook", func(t *testing.T) {
+		playbook := PlaybookFromRun("user1", playbookRun, nil, "", true)
+		require.False(t, playbook.IsOpen())
+		require.Equal(t, []PlaybookMember{{UserID: "user1", Role: PlaybookRoleAdmin}}, playbook.Members)
+	})
+
+	t.Run("from the playbook of the run", func(t *testing.T) {
+		source := &Playbook{
+			ID:                      "playbook1",
+			Title:                   "Incident",
+			TeamID:                  "team1",
+			ReminderMessageTemplate: "Status?",
+			Checklists:              []Checklist{{Title: "Old"}},
+		}
+		playbook := PlaybookFromRun("user1", playbookRun, source, "Incident v2", false)
+		require.Equal(t, "Incident v2", playbook.Title)
+		require.Empty(t, playbook.ID)
+		require.Equal(t, "Status?", playbook.ReminderMessageTemplate)
+		require.Equal(t, []Checklist{{Title: "Triage", Items: []ChecklistItem{{Title: "Page on-call"}}}}, playbook.Checklists)
+	})
+}
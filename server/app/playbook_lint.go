@@ -0,0 +1,264 @@
+package app
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
+)
+
+const (
+	// PlaybookLintError is the severity of the issues that always prevent saving a playbook.
+	PlaybookLintError = "error"
+
+	// PlaybookLintWarning is the severity of the issues that only prevent saving a playbook in
+	// strict mode.
+	PlaybookLintWarning = "warning"
+)
+
+// maxPlaybookWebhooks is the maximum number of URLs of each webhook of a playbook.
+const maxPlaybookWebhooks = 64
+
+// PlaybookLintIssue is a problem with the content of a playbook.
+type PlaybookLintIssue struct {
+	// Severity is PlaybookLintError or PlaybookLintWarning.
+	Severity string `json:"severity"`
+
+	// Code identifies the kind of issue, e.g. empty_checklist.
+	Code string `json:"code"`
+
+	// Field is the path of the field with the issue, e.g. checklists[0].items[2].command.
+	Field string `json:"field"`
+
+	// Message describes the issue.
+	Message string `json:"message"`
+}
+
+// InvalidPlaybookError occurs when saving a playbook whose issues prevent it. It wraps
+// ErrMalformedPlaybook.
+type InvalidPlaybookError struct {
+	Issues []PlaybookLintIssue
+}
+
+func (e *InvalidPlaybookError) Error() string {
+	messages := make([]string, 0, len(e.Issues))
+	for _, issue := range e.Issues {
+		messages = append(messages, issue.Message)
+	}
+	return fmt.Sprintf("invalid playbook: %s", strings.Join(messages, "; "))
+}
+
+func (e *InvalidPlaybookError) Unwrap() error {
+	return ErrMalformedPlaybook
+}
+
+// LintPlaybook returns the issues with the content of a playbook: empty checklists, duplicate
+// item titles, slash commands that don't exist in its team, commands set to run automatically
+// that can't, malformed HTTP actions, approvals that can't be given, run statuses that can't be
+// reached or left, broadcast channels the bot can't post to and malformed webhook URLs. The bot
+// is not checked if botUserID is empty.
+func LintPlaybook(playbook Playbook, botUserID string, pluginAPI *pluginapi.Client) []PlaybookLintIssue {
+	var issues []PlaybookLintIssue
+	add := func(severity, code, field, message string) {
+		issues = append(issues, PlaybookLintIssue{Severity: severity, Code: code, Field: field, Message: message})
+	}
+
+	if len(playbook.Checklists) == 0 {
+		add(PlaybookLintWarning, "no_checklists", "checklists", "the playbook has no checklists")
+	}
+
+	triggers := lintCommandTriggers(playbook, pluginAPI)
+	for i, checklist := range playbook.Checklists {
+		checklistField := fmt.Sprintf("checklists[%d]", i)
+		if len(checklist.Items) == 0 {
+			add(PlaybookLintWarning, "empty_checklist", checklistField, fmt.Sprintf("the checklist %q has no items", checklist.Title))
+		}
+
+		titles := make(map[string]bool, len(checklist.Items))
+		for j, item := range checklist.Items {
+			itemField := fmt.Sprintf("%s.items[%d]", checklistField, j)
+
+			title := strings.ToLower(strings.TrimSpace(item.Title))
+			if titles[title] {
+				add(PlaybookLintWarning, "duplicate_item_title", itemField+".title", fmt.Sprintf("the checklist %q has more than one item titled %q", checklist.Title, item.Title))
+			}
+			titles[title] = true
+
+			switch {
+			case !IsValidChecklistItemAutoRun(item.AutoRun):
+				add(PlaybookLintError, "invalid_auto_run", itemField+".auto_run", fmt.Sprintf("the item %q runs automatically %q; only %q, %q and %q are accepted", item.Title, item.AutoRun, ChecklistItemAutoRunOnRunStart, ChecklistItemAutoRunAfterPreviousItem, ChecklistItemAutoRunAtOffset))
+			case item.AutoRun != "" && strings.TrimSpace(item.Command) == "":
+				add(PlaybookLintError, "auto_run_without_command", itemField+".auto_run", fmt.Sprintf("the item %q runs automatically but has no command", item.Title))
+			case item.AutoRun == ChecklistItemAutoRunAfterPreviousItem && j == 0:
+				add(PlaybookLintError, "auto_run_without_previous_item", itemField+".auto_run", fmt.Sprintf("the item %q runs after the previous item but is the first of its checklist", item.Title))
+			case item.AutoRun == ChecklistItemAutoRunAtOffset && item.AutoRunOffsetSeconds <= 0:
+				add(PlaybookLintError, "invalid_auto_run_offset", itemField+".auto_run_offset_seconds", fmt.Sprintf("the item %q runs at a time after the run starts but the offset is not positive", item.Title))
+			}
+
+			if item.HasHTTPAction() {
+				lintHTTPAction(item, itemField, add)
+			}
+
+			approval := item.Approval
+			switch {
+			case approval.RequiredApprovals < 0:
+				add(PlaybookLintError, "invalid_required_approvals", itemField+".approval.required_approvals", fmt.Sprintf("the item %q requires a negative number of approvals", item.Title))
+			case !approval.HasApprovers() && approval.RequiredApprovals > 0:
+				add(PlaybookLintWarning, "approval_without_approvers", itemField+".approval", fmt.Sprintf("the item %q requires approvals but has no approvers", item.Title))
+			case len(approval.ApproverGroupIDs) == 0 && approval.RequiredApprovals > len(approval.ApproverIDs):
+				add(PlaybookLintError, "too_many_required_approvals", itemField+".approval.required_approvals", fmt.Sprintf("the item %q requires %d approvals but has only %d approvers", item.Title, approval.RequiredApprovals, len(approval.ApproverIDs)))
+			}
+
+			if item.Command == "" {
+				continue
+			}
+			fields := strings.Fields(item.Command)
+			if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+				add(PlaybookLintWarning, "malformed_command", itemField+".command", fmt.Sprintf("the command of %q doesn't start with a slash", item.Title))
+				continue
+			}
+			if triggers != nil && !triggers[strings.TrimPrefix(fields[0], "/")] {
+				add(PlaybookLintWarning, "unknown_command", itemField+".command", fmt.Sprintf("the command %s of %q doesn't exist in the team", fields[0], item.Title))
+			}
+		}
+	}
+
+	lintRunStatuses(playbook.RunStatuses, add)
+
+	if botUserID != "" {
+		for i, channelID := range playbook.BroadcastChannelIDs {
+			if !pluginAPI.User.HasPermissionToChannel(botUserID, channelID, model.PermissionCreatePost) {
+				add(PlaybookLintWarning, "broadcast_channel_not_postable", fmt.Sprintf("broadcast_channel_ids[%d]", i), fmt.Sprintf("the bot can't post to the broadcast channel %s", channelID))
+			}
+		}
+	}
+
+	lintWebhooks := func(field, name string, urls []string, enabled bool) {
+		// Malformed URLs only prevent saving if the webhook is enabled.
+		severity := PlaybookLintWarning
+		if enabled {
+			severity = PlaybookLintError
+		}
+
+		if len(urls) > maxPlaybookWebhooks {
+			add(severity, "too_many_webhooks", field, fmt.Sprintf("too many registered %s webhook urls, limit to less than %d", name, maxPlaybookWebhooks))
+		}
+
+		for i, webhook := range urls {
+			parsedURL, err := url.ParseRequestURI(webhook)
+			if err != nil {
+				add(severity, "invalid_webhook_url", fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("invalid %s webhook URL: %v", name, err))
+				continue
+			}
+			if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+				add(severity, "invalid_webhook_url", fmt.Sprintf("%s[%d]", field, i), fmt.Sprintf("protocol in %s webhook URL is %s; only HTTP and HTTPS are accepted", name, parsedURL.Scheme))
+			}
+		}
+	}
+	lintWebhooks("webhook_on_creation_urls", "creation", playbook.WebhookOnCreationURLs, playbook.WebhookOnCreationEnabled)
+	lintWebhooks("webhook_on_status_update_urls", "update", playbook.WebhookOnStatusUpdateURLs, playbook.WebhookOnStatusUpdateEnabled)
+
+	return issues
+}
+
+// lintHTTPAction adds the issues with the HTTP action of an item: it can't have a command too, and
+// its method, URL and header names must be valid.
+func lintHTTPAction(item ChecklistItem, itemField string, add func(severity, code, field, message string)) {
+	if item.Command != "" {
+		add(PlaybookLintError, "command_and_http_action", itemField+".http_action", fmt.Sprintf("the item %q has both a command and an HTTP action", item.Title))
+	}
+
+	if !IsValidHTTPActionMethod(item.HTTPAction.Method) {
+		add(PlaybookLintError, "invalid_http_action_method", itemField+".http_action.method", fmt.Sprintf("the HTTP action of %q has the method %q; only GET, POST, PUT, PATCH and DELETE are accepted", item.Title, item.HTTPAction.Method))
+	}
+
+	parsedURL, err := url.ParseRequestURI(item.HTTPAction.URL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+		add(PlaybookLintError, "invalid_http_action_url", itemField+".http_action.url", fmt.Sprintf("the HTTP action of %q doesn't have an HTTP or HTTPS URL", item.Title))
+	}
+
+	for i, header := range item.HTTPAction.Headers {
+		if strings.TrimSpace(header.Name) == "" || strings.ContainsAny(header.Name, " :\r\n") {
+			add(PlaybookLintError, "invalid_http_action_header", fmt.Sprintf("%s.http_action.headers[%d]", itemField, i), fmt.Sprintf("the HTTP action of %q has an invalid header name %q", item.Title, header.Name))
+		}
+	}
+}
+
+// lintRunStatuses checks the names of the run statuses and that their transitions lead to
+// existing statuses.
+func lintRunStatuses(statuses []RunStatus, add func(severity, code, field, message string)) {
+	if len(statuses) > maxRunStatuses {
+		add(PlaybookLintError, "too_many_run_statuses", "run_statuses", fmt.Sprintf("the playbook has %d run statuses; the limit is %d", len(statuses), maxRunStatuses))
+	}
+
+	names := make(map[string]bool, len(statuses))
+	for _, status := range statuses {
+		names[status.Name] = true
+	}
+
+	seen := make(map[string]bool, len(statuses))
+	hasFinished := false
+	for i, status := range statuses {
+		field := fmt.Sprintf("run_statuses[%d]", i)
+		switch {
+		case !IsValidRunStatusName(status.Name):
+			add(PlaybookLintError, "invalid_run_status_name", field+".name", fmt.Sprintf("the run status name %q must start with a capital letter, have at most 64 letters, digits, spaces, dashes or underscores, and not be %s or %s", status.Name, StatusInProgress, StatusFinished))
+		case seen[status.Name]:
+			add(PlaybookLintError, "duplicate_run_status", field+".name", fmt.Sprintf("there is more than one run status named %q", status.Name))
+		}
+		seen[status.Name] = true
+		hasFinished = hasFinished || status.Finished
+
+		for j, transition := range status.Transitions {
+			if !names[transition] {
+				add(PlaybookLintError, "unknown_run_status_transition", fmt.Sprintf("%s.transitions[%d]", field, j), fmt.Sprintf("the run status %q moves to %q, which doesn't exist", status.Name, transition))
+			}
+		}
+
+		if !status.Finished && len(AllowedRunStatusTransitions(statuses, status.Name)) == 0 {
+			add(PlaybookLintWarning, "dead_end_run_status", field, fmt.Sprintf("runs can't leave the run status %q, which doesn't finish them", status.Name))
+		}
+	}
+
+	if len(statuses) > 0 && !hasFinished {
+		add(PlaybookLintWarning, "no_finished_run_status", "run_statuses", "no run status finishes the runs")
+	}
+}
+
+// lintCommandTriggers returns the triggers of the slash commands of the team of the playbook,
+// or nil if the playbook has no commands or they can't be listed.
+func lintCommandTriggers(playbook Playbook, pluginAPI *pluginapi.Client) map[string]bool {
+	hasCommands := false
+	for _, checklist := range playbook.Checklists {
+		for _, item := range checklist.Items {
+			hasCommands = hasCommands || item.Command != ""
+		}
+	}
+	if !hasCommands || playbook.TeamID == "" {
+		return nil
+	}
+
+	commands, err := pluginAPI.SlashCommand.List(playbook.TeamID)
+	if err != nil {
+		return nil
+	}
+
+	triggers := make(map[string]bool, len(commands))
+	for _, command := range commands {
+		triggers[command.Trigger] = true
+	}
+	return triggers
+}
+
+// PlaybookLintBlocks returns true if the issues prevent saving the playbook: any error, or any
+// warning in strict mode.
+func PlaybookLintBlocks(issues []PlaybookLintIssue, strict bool) bool {
+	for _, issue := range issues {
+		if issue.Severity == PlaybookLintError || strict {
+			return true
+		}
+	}
+	return false
+}
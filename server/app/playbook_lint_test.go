@@ -0,0 +1,202 @@
+package app
+
+import (
+	"testing"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintPlaybook(t *testing.T) {
+	lintCodes := func(issues []PlaybookLintIssue) []string {
+		var codes []string
+		for _, issue := range issues {
+			codes = append(codes, issue.Severity+" "+issue.Code+" "+issue.Field)
+		}
+		return codes
+	}
+
+	t.Run("clean playbook", func(t *testing.T) {
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		pluginAPI.On("ListCommands", "team1").Return([]*model.Command{{Trigger: "echo"}}, nil)
+		pluginAPI.On("HasPermissionToChannel", "bot1", "channel1", model.PermissionCreatePost).Return(true)
+
+		playbook := Playbook{
+			TeamID: "team1",
+			Checklists: []Checklist{
+				{Title: "Triage", Items: []ChecklistItem{{Title: "Page"}, {Title: "Say hi", Command: "/echo hi"}}},
+			},
+			BroadcastChannelIDs:      []string{"channel1"},
+			WebhookOnCreationEnabled: true,
+			WebhookOnCreationURLs:    []string{"https://example.com/hook"},
+		}
+		require.Empty(t, LintPlaybook(playbook, "bot1", client))
+	})
+
+	t.Run("issues", func(t *testing.T) {
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		pluginAPI.On("ListCommands", "team1").Return([]*model.Command{{Trigger: "echo"}}, nil)
+		pluginAPI.On("HasPermissionToChannel", "bot1", "channel1", model.PermissionCreatePost).Return(false)
+
+		playbook := Playbook{
+			TeamID: "team1",
+			Checklists: []Checklist{
+				{Title: "Triage", Items: []ChecklistItem{
+					{Title: "Page"},
+					{Title: "page ", Command: "echo hi"},
+					{Title: "Deploy", Command: "/deploy now"},
+				}},
+				{Title: "Empty"},
+			},
+			BroadcastChannelIDs:          []string{"channel1"},
+			WebhookOnCreationEnabled:     true,
+			WebhookOnCreationURLs:        []string{"ftp://example.com/hook"},
+			WebhookOnStatusUpdateEnabled: false,
+			WebhookOnStatusUpdateURLs:    []string{"not a url"},
+		}
+
+		issues := LintPlaybook(playbook, "bot1", client)
+		require.Equal(t, []string{
+			"warning duplicate_item_title checklists[0].items[1].title",
+			"warning malformed_command checklists[0].items[1].command",
+			"warning unknown_command checklists[0].items[2].command",
+			"warning empty_checklist checklists[1]",
+			"warning broadcast_channel_not_postable broadcast_channel_ids[0]",
+			"error invalid_webhook_url webhook_on_creation_urls[0]",
+			"warning invalid_webhook_url webhook_on_status_update_urls[0]",
+		}, lintCodes(issues))
+	})
+
+	t.Run("automatic commands", func(t *testing.T) {
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		pluginAPI.On("ListCommands", "team1").Return([]*model.Command{{Trigger: "echo"}}, nil)
+
+		playbook := Playbook{
+			TeamID: "team1",
+			Checklists: []Checklist{
+				{Title: "Setup", Items: []ChecklistItem{
+					{Title: "Bridge", Command: "/echo bridge", AutoRun: ChecklistItemAutoRunAfterPreviousItem},
+					{Title: "Page", Command: "/echo page", AutoRun: ChecklistItemAutoRunAfterPreviousItem},
+					{Title: "Remind", Command: "/echo remind", AutoRun: ChecklistItemAutoRunAtOffset},
+					{Title: "Later", Command: "/echo later", AutoRun: ChecklistItemAutoRunAtOffset, AutoRunOffsetSeconds: 600},
+					{Title: "Nothing", AutoRun: ChecklistItemAutoRunOnRunStart},
+					{Title: "Never", Command: "/echo never", AutoRun: "never"},
+				}},
+			},
+		}
+
+		issues := LintPlaybook(playbook, "", client)
+		require.Equal(t, []string{
+			"error auto_run_without_previous_item checklists[0].items[0].auto_run",
+			"error invalid_auto_run_offset checklists[0].items[2].auto_run_offset_seconds",
+			"error auto_run_without_command checklists[0].items[4].auto_run",
+			"error invalid_auto_run checklists[0].items[5].auto_run",
+		}, lintCodes(issues))
+	})
+
+	t.Run("HTTP actions", func(t *testing.T) {
+		client := pluginapi.NewClient(&plugintest.API{}, &plugintest.Driver{})
+
+		rollback := ChecklistItemHTTPAction{
+			Method:  "POST",
+			URL:     "https://deploy.internal/rollback/{{custom.service}}",
+			Headers: []ChecklistItemHTTPActionHeader{{Name: "Authorization", Value: "{{secret.DEPLOY}}"}},
+		}
+		malformed := ChecklistItemHTTPAction{
+			Method:  "TRACE",
+			URL:     "ftp://deploy.internal",
+			Headers: []ChecklistItemHTTPActionHeader{{Name: "Bad Header"}},
+		}
+
+		playbook := Playbook{
+			Checklists: []Checklist{
+				{Title: "Rollback", Items: []ChecklistItem{
+					{Title: "Roll back", HTTPAction: rollback},
+					{Title: "Malformed", HTTPAction: malformed},
+					{Title: "Both", Command: "/echo", HTTPAction: rollback},
+				}},
+			},
+		}
+
+		issues := LintPlaybook(playbook, "", client)
+		require.Equal(t, []string{
+			"error invalid_http_action_method checklists[0].items[1].http_action.method",
+			"error invalid_http_action_url checklists[0].items[1].http_action.url",
+			"error invalid_http_action_header checklists[0].items[1].http_action.headers[0]",
+			"error command_and_http_action checklists[0].items[2].http_action",
+		}, lintCodes(issues))
+	})
+
+	t.Run("approvals", func(t *testing.T) {
+		client := pluginapi.NewClient(&plugintest.API{}, &plugintest.Driver{})
+
+		playbook := Playbook{
+			Checklists: []Checklist{
+				{Title: "Production", Items: []ChecklistItem{
+					{Title: "Change the database", Approval: ChecklistItemApproval{ApproverIDs: []string{"dba1", "dba2"}, RequiredApprovals: 2}},
+					{Title: "Notify customers", Approval: ChecklistItemApproval{ApproverGroupIDs: []string{"support"}, RequiredApprovals: 3}},
+					{Title: "Negative", Approval: ChecklistItemApproval{ApproverIDs: []string{"dba1"}, RequiredApprovals: -1}},
+					{Title: "Unreachable", Approval: ChecklistItemApproval{ApproverIDs: []string{"dba1"}, RequiredApprovals: 2}},
+					{Title: "Nobody", Approval: ChecklistItemApproval{RequiredApprovals: 1}},
+				}},
+			},
+		}
+
+		issues := LintPlaybook(playbook, "", client)
+		require.Equal(t, []string{
+			"error invalid_required_approvals checklists[0].items[2].approval.required_approvals",
+			"error too_many_required_approvals checklists[0].items[3].approval.required_approvals",
+			"warning approval_without_approvers checklists[0].items[4].approval",
+		}, lintCodes(issues))
+	})
+
+	t.Run("run statuses", func(t *testing.T) {
+		client := pluginapi.NewClient(&plugintest.API{}, &plugintest.Driver{})
+
+		playbook := Playbook{
+			Checklists: []Checklist{{Title: "Triage", Items: []ChecklistItem{{Title: "Page"}}}},
+			RunStatuses: []RunStatus{
+				{Name: "Investigating", Transitions: []string{"Mitigated", "Gone"}},
+				{Name: "Mitigated"},
+				{Name: "Mitigated"},
+				{Name: "InProgress"},
+				{Name: "Resolved", Finished: true},
+			},
+		}
+		issues := LintPlaybook(playbook, "", client)
+		require.Equal(t, []string{
+			"error unknown_run_status_transition run_statuses[0].transitions[1]",
+			"error duplicate_run_status run_statuses[2].name",
+			"error invalid_run_status_name run_statuses[3].name",
+		}, lintCodes(issues))
+
+		playbook.RunStatuses = []RunStatus{{Name: "Investigating"}, {Name: "Stuck"}}
+		issues = LintPlaybook(playbook, "", client)
+		require.Equal(t, []string{
+			"warning dead_end_run_status run_statuses[1]",
+			"warning no_finished_run_status run_statuses",
+		}, lintCodes(issues))
+	})
+
+	t.Run("no checklists, without bot", func(t *testing.T) {
+		client := pluginapi.NewClient(&plugintest.API{}, &plugintest.Driver{})
+
+		issues := LintPlaybook(Playbook{TeamID: "team1", BroadcastChannelIDs: []string{"channel1"}}, "", client)
+		require.Equal(t, []string{"warning no_checklists checklists"}, lintCodes(issues))
+	})
+}
+
+func TestPlaybookLintBlocks(t *testing.T) {
+	warning := PlaybookLintIssue{Severity: PlaybookLintWarning}
+	lintError := PlaybookLintIssue{Severity: PlaybookLintError}
+
+	require.False(t, PlaybookLintBlocks(nil, true))
+	require.False(t, PlaybookLintBlocks([]PlaybookLintIssue{warning}, false))
+	require.True(t, PlaybookLintBlocks([]PlaybookLintIssue{warning}, true))
+	require.True(t, PlaybookLintBlocks([]PlaybookLintIssue{warning, lintError}, false))
+}
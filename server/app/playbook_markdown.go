@@ -0,0 +1,254 @@
+package app
+
+import (
+	"regexp"
+	"strings"
+)
+
+// defaultMarkdownChecklistTitle is the title of the checklist of the tasks before any heading.
+const defaultMarkdownChecklistTitle = "Checklist"
+
+var (
+	markdownHeadingRegex = regexp.MustCompile(`^ {0,3}(#{1,6})\s+(.*?)(\s+#+)?\s*$`)
+	markdownTaskRegex    = regexp.MustCompile(`^\s*[-*+]\s+\[[ xX]\]\s+(.*)$`)
+	markdownFenceRegex   = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownCommandRegex = regexp.MustCompile("`(/[^`]+)`")
+)
+
+// MarkdownImportIssue is a part of a Markdown document that couldn't be mapped to a checklist
+// or an item, and was left out of the playbook.
+type MarkdownImportIssue struct {
+	// Line is the line number of the issue in the document, starting at 1.
+	Line int `json:"line"`
+
+	// Text is the line of the document, as written.
+	Text string `json:"text"`
+
+	// Message explains why the line was left out.
+	Message string `json:"message"`
+}
+
+// MarkdownPlaybook is the result of parsing a Markdown document into checklists.
+type MarkdownPlaybook struct {
+	// Title is the title of the document: its first heading, when it has no tasks of its own.
+	Title string
+
+	// Checklists has a checklist per heading with tasks.
+	Checklists []Checklist
+
+	// Issues lists what couldn't be mapped.
+	Issues []MarkdownImportIssue
+}
+
+// markdownParser holds the state of ParseMarkdownPlaybook.
+type markdownParser struct {
+	result MarkdownPlaybook
+
+	// heading is the title of the current checklist, and headingLine the line of its heading,
+	// or zero for the tasks before any heading.
+	heading     string
+	headingLine int
+	items       []ChecklistItem
+
+	// inItem is true while the lines indented under the last task are its description.
+	inItem      bool
+	description []string
+
+	// fence is the opening marker of the current code block, if any, and fenceInItem whether
+	// it belongs to the description of the last task.
+	fence       string
+	fenceInItem bool
+}
+
+// ParseMarkdownPlaybook parses a Markdown document into checklists: every heading starts a
+// checklist, every task bullet (`- [ ]` or `- [x]`) is an item, nested ones included, the text
+// indented under a task is its description, and the first inline code of the form `/command` in
+// a task is its command. Anything else is reported in the issues.
+func ParseMarkdownPlaybook(markdown string) MarkdownPlaybook {
+	p := &markdownParser{}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		p.parseLine(i+1, line)
+	}
+	p.endChecklist()
+
+	return p.result
+}
+
+func (p *markdownParser) parseLine(lineNumber int, line string) {
+	line = expandLeadingTabs(line)
+	indented := strings.HasPrefix(line, " ")
+
+	if p.fence != "" {
+		if strings.HasPrefix(strings.TrimSpace(line), p.fence) {
+			p.fence = ""
+		}
+		if p.fenceInItem {
+			p.description = append(p.description, line)
+		}
+		return
+	}
+
+	if strings.TrimSpace(line) == "" {
+		if p.inItem {
+			p.description = append(p.description, "")
+		}
+		return
+	}
+
+	if p.inItem && indented && !markdownTaskRegex.MatchString(line) {
+		if match := markdownFenceRegex.FindStringSubmatch(line); match != nil {
+			p.fence = match[1]
+			p.fenceInItem = true
+		}
+		p.description = append(p.description, line)
+		return
+	}
+
+	if match := markdownHeadingRegex.FindStringSubmatch(line); match != nil {
+		p.endChecklist()
+		p.heading = strings.TrimSpace(match[2])
+		p.headingLine = lineNumber
+		return
+	}
+
+	if match := markdownTaskRegex.FindStringSubmatch(line); match != nil {
+		p.endItem()
+		p.addItem(lineNumber, line, match[1])
+		return
+	}
+
+	p.endItem()
+
+	if match := markdownFenceRegex.FindStringSubmatch(line); match != nil {
+		p.fence = match[1]
+		p.fenceInItem = false
+		p.addIssue(lineNumber, line, "code blocks are only imported in the description of a task")
+		return
+	}
+
+	p.addIssue(lineNumber, line, "only headings, task bullets (- [ ]) and the text indented under them are imported")
+}
+
+func (p *markdownParser) addItem(lineNumber int, line, text string) {
+	item := ChecklistItem{Title: strings.TrimSpace(text)}
+
+	if commands := markdownCommandRegex.FindAllStringSubmatchIndex(item.Title, -1); len(commands) > 0 {
+		item.Command = item.Title[commands[0][2]:commands[0][3]]
+		item.Title = strings.TrimSpace(strings.Join(strings.Fields(item.Title[:commands[0][0]]+item.Title[commands[0][1]:]), " "))
+		if len(commands) > 1 {
+			p.addIssue(lineNumber, line, "only the first command of a task is imported")
+		}
+	}
+	if item.Title == "" {
+		item.Title = item.Command
+	}
+
+	p.items = append(p.items, item)
+	p.inItem = true
+}
+
+// endItem sets the description of the last task from the lines indented under it.
+func (p *markdownParser) endItem() {
+	if !p.inItem {
+		return
+	}
+	p.inItem = false
+
+	lines := p.description
+	p.description = nil
+
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	// Remove the indentation of the first line from all of them.
+	indent := len(lines[0]) - len(strings.TrimLeft(lines[0], " "))
+	for i, line := range lines {
+		lineIndent := len(line) - len(strings.TrimLeft(line, " "))
+		if lineIndent > indent {
+			lineIndent = indent
+		}
+		lines[i] = line[lineIndent:]
+	}
+
+	item := &p.items[len(p.items)-1]
+	item.Description = strings.Join(lines, "\n")
+	if item.Command == "" {
+		if match := markdownCommandRegex.FindStringSubmatch(item.Description); match != nil {
+			item.Command = match[1]
+		}
+	}
+}
+
+// endChecklist adds the current checklist to the result if it has tasks.
+func (p *markdownParser) endChecklist() {
+	p.endItem()
+
+	items := p.items
+	p.items = nil
+
+	if len(items) == 0 {
+		if p.headingLine == 0 {
+			return
+		}
+		if p.result.Title == "" && len(p.result.Checklists) == 0 && len(p.result.Issues) == 0 {
+			p.result.Title = p.heading
+			return
+		}
+		p.addIssue(p.headingLine, p.heading, "the heading has no tasks, so no checklist was created")
+		return
+	}
+
+	title := p.heading
+	if title == "" {
+		title = defaultMarkdownChecklistTitle
+	}
+	p.result.Checklists = append(p.result.Checklists, Checklist{Title: title, Items: items})
+}
+
+func (p *markdownParser) addIssue(lineNumber int, line, message string) {
+	p.result.Issues = append(p.result.Issues, MarkdownImportIssue{
+		Line:    lineNumber,
+		Text:    line,
+		Message: message,
+	})
+}
+
+// expandLeadingTabs replaces the tabs of the indentation of a line by four spaces.
+func expandLeadingTabs(line string) string {
+	trimmed := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(trimmed)]
+	return strings.ReplaceAll(indent, "\t", "    ") + trimmed
+}
+
+// PlaybookFromMarkdown returns a new playbook, not yet stored, in the given team with the
+// checklists parsed from a Markdown document, and the issues found parsing it. The title
+// defaults to the title of the document, or of its first checklist. The new playbook is open,
+// unless restricted is set; userID is then its only member, and an admin.
+func PlaybookFromMarkdown(userID, teamID, title, markdown string, restricted bool) (Playbook, []MarkdownImportIssue) {
+	parsed := ParseMarkdownPlaybook(markdown)
+
+	if title == "" {
+		title = parsed.Title
+	}
+	if title == "" && len(parsed.Checklists) > 0 {
+		title = parsed.Checklists[0].Title
+	}
+
+	playbook := Playbook{
+		Title:      title,
+		TeamID:     teamID,
+		Checklists: parsed.Checklists,
+	}
+	if restricted {
+		playbook.Members = []PlaybookMember{{UserID: userID, Role: PlaybookRoleAdmin}}
+		playbook.NormalizeMembers()
+	}
+
+	return playbook, parsed.Issues
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdownPlaybook(t *testing.T) {
+	t.Run("headings, tasks, descriptions and commands", func(t *testing.T) {
+		markdown := "# Database outage\n" +
+			"\n" +
+			"## Triage\n" +
+			"\n" +
+			"- [ ] Page the on-call DBA `/pagerduty trigger dba`\n" +
+			"  Use the **database** service.\n" +
+			"\n" +
+			"  ```\n" +
+			"  SELECT 1;\n" +
+			"  ```\n" +
+			"- [x] Check the dashboards\n" +
+			"    - [ ] Replication lag\n" +
+			"\n" +
+			"## Recovery ##\n" +
+			"* [ ] Fail over\n" +
+			"\tRun `/failover start` from the bastion.\n"
+
+		parsed := ParseMarkdownPlaybook(markdown)
+		require.Empty(t, parsed.Issues)
+		require.Equal(t, "Database outage", parsed.Title)
+		require.Equal(t, []Checklist{
+			{
+				Title: "Triage",
+				Items: []ChecklistItem{
+					{
+						Title:       "Page the on-call DBA",
+						Command:     "/pagerduty trigger dba",
+						Description: "Use the **database** service.\n\n```\nSELECT 1;\n```",
+					},
+					{Title: "Check the dashboards"},
+					{Title: "Replication lag"},
+				},
+			},
+			{
+				Title: "Recovery",
+				Items: []ChecklistItem{
+					{
+						Title:       "Fail over",
+						Command:     "/failover start",
+						Description: "Run `/failover start` from the bastion.",
+					},
+				},
+			},
+		}, parsed.Checklists)
+	})
+
+	t.Run("tasks before any heading", func(t *testing.T) {
+		parsed := ParseMarkdownPlaybook("- [ ] `/echo hi`\r\n- [ ] Done\r\n")
+		require.Empty(t, parsed.Issues)
+		require.Empty(t, parsed.Title)
+		require.Equal(t, []Checklist{
+			{
+				Title: "Checklist",
+				Items: []ChecklistItem{
+					{Title: "/echo hi", Command: "/echo hi"},
+					{Title: "Done"},
+				},
+			},
+		}, parsed.Checklists)
+	})
+
+	t.Run("reports what isn't mapped", func(t *testing.T) {
+		markdown := "## Triage\n" +
+			"Some context.\n" +
+			"- [ ] Run `/one` and `/two`\n" +
+			"- A plain bullet\n" +
+			"```\n" +
+			"- [ ] not a task\n" +
+			"```\n" +
+			"## Empty\n" +
+			"## Follow up\n" +
+			"- [ ] Retrospective\n"
+
+		parsed := ParseMarkdownPlaybook(markdown)
+		require.Empty(t, parsed.Title)
+		require.Len(t, parsed.Checklists, 2)
+		require.Equal(t, ChecklistItem{Title: "Run and `/two`", Command: "/one"}, parsed.Checklists[0].Items[0])
+
+		var lines []int
+		for _, issue := range parsed.Issues {
+			lines = append(lines, issue.Line)
+		}
+		require.Equal(t, []int{2, 3, 4, 5, 8}, lines)
+		require.Equal(t, "Empty", parsed.Issues[4].Text)
+	})
+}
+
+func TestPlaybookFromMarkdown(t *testing.T) {
+	playbook, issues := PlaybookFromMarkdown("user1", "team1", "", "## Triage\n- [ ] Page\n", false)
+	require.Empty(t, issues)
+	require.Equal(t, "Triage", playbook.Title)
+	require.Equal(t, "team1", playbook.TeamID)
+	require.True(t, playbook.IsOpen())
+	require.Len(t, playbook.Checklists, 1)
+
+	playbook, _ = PlaybookFromMarkdown("user1", "team1", "Runbook", "# Outage\n- [ ] Page\n", true)
+	require.Equal(t, "Runbook", playbook.Title)
+	require.Equal(t, "Outage", playbook.Checklists[0].Title)
+	require.Equal(t, []PlaybookMember{{UserID: "user1", Role: PlaybookRoleAdmin}}, playbook.Members)
+}
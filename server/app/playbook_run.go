@@ -74,6 +74,13 @@ type PlaybookRun struct {
 	// It can be StatusInProgress ("InProgress") or StatusFinished ("Finished")
 	CurrentStatus string `json:"current_status"`
 
+	// RunStatuses are the custom statuses the run goes through, copied from its playbook when the
+	// run started. Runs without custom statuses are only InProgress or Finished.
+	RunStatuses []RunStatus `json:"run_statuses,omitempty"`
+
+	// CurrentRunStatus is the name of the custom status the run is in, empty if it has none.
+	CurrentRunStatus string `json:"current_run_status"`
+
 	// LastStatusUpdateAt is the timestamp, in milliseconds since epoch, of the time the last
 	// status update was posted.
 	LastStatusUpdateAt int64 `json:"last_status_update_at"`
@@ -150,6 +157,10 @@ type PlaybookRun struct {
 
 	// CategoryName, if not empty, is the name of the category where the run channel will live.
 	CategoryName string `json:"category_name"`
+
+	// CustomData are the values of the {{custom.<key>}} placeholders of the checklists of the
+	// playbook run.
+	CustomData map[string]string `json:"custom_data"`
 }
 
 func (i *PlaybookRun) Clone() *PlaybookRun {
@@ -167,6 +178,14 @@ func (i *PlaybookRun) Clone() *PlaybookRun {
 	newPlaybookRun.ParticipantIDs = append([]string(nil), i.ParticipantIDs...)
 	newPlaybookRun.WebhookOnCreationURLs = append([]string(nil), i.WebhookOnCreationURLs...)
 	newPlaybookRun.WebhookOnStatusUpdateURLs = append([]string(nil), i.WebhookOnStatusUpdateURLs...)
+	newPlaybookRun.RunStatuses = CloneRunStatuses(i.RunStatuses)
+
+	if i.CustomData != nil {
+		newPlaybookRun.CustomData = make(map[string]string, len(i.CustomData))
+		for key, value := range i.CustomData {
+			newPlaybookRun.CustomData[key] = value
+		}
+	}
 
 	return &newPlaybookRun
 }
@@ -196,6 +215,9 @@ func (i *PlaybookRun) MarshalJSON() ([]byte, error) {
 	if old.TimelineEvents == nil {
 		old.TimelineEvents = []TimelineEvent{}
 	}
+	if old.CustomData == nil {
+		old.CustomData = map[string]string{}
+	}
 	if old.ParticipantIDs == nil {
 		old.ParticipantIDs = []string{}
 	}
@@ -233,6 +255,9 @@ type UpdateOptions struct {
 type StatusUpdateOptions struct {
 	Message  string        `json:"message"`
 	Reminder time.Duration `json:"reminder"`
+
+	// RunStatus, if not empty, is the custom status the run moves to with the update.
+	RunStatus string `json:"run_status"`
 }
 
 // Metadata tracks ancillary metadata about a playbook run.
@@ -247,17 +272,24 @@ type Metadata struct {
 type timelineEventType string
 
 const (
-	PlaybookRunCreated     timelineEventType = "incident_created"
-	TaskStateModified      timelineEventType = "task_state_modified"
-	StatusUpdated          timelineEventType = "status_updated"
-	OwnerChanged           timelineEventType = "owner_changed"
-	AssigneeChanged        timelineEventType = "assignee_changed"
-	RanSlashCommand        timelineEventType = "ran_slash_command"
-	EventFromPost          timelineEventType = "event_from_post"
-	UserJoinedLeft         timelineEventType = "user_joined_left"
-	PublishedRetrospective timelineEventType = "published_retrospective"
-	CanceledRetrospective  timelineEventType = "canceled_retrospective"
-	RunFinished            timelineEventType = "run_finished"
+	PlaybookRunCreated      timelineEventType = "incident_created"
+	TaskStateModified       timelineEventType = "task_state_modified"
+	StatusUpdated           timelineEventType = "status_updated"
+	OwnerChanged            timelineEventType = "owner_changed"
+	AssigneeChanged         timelineEventType = "assignee_changed"
+	RanSlashCommand         timelineEventType = "ran_slash_command"
+	EventFromPost           timelineEventType = "event_from_post"
+	UserJoinedLeft          timelineEventType = "user_joined_left"
+	PublishedRetrospective  timelineEventType = "published_retrospective"
+	CanceledRetrospective   timelineEventType = "canceled_retrospective"
+	RunFinished             timelineEventType = "run_finished"
+	SlashCommandFailed      timelineEventType = "slash_command_failed"
+	RanHTTPAction           timelineEventType = "ran_http_action"
+	RequiredItemsOverridden timelineEventType = "required_items_overridden"
+	ApprovalRequested       timelineEventType = "approval_requested"
+	ChecklistItemApproved   timelineEventType = "item_approved"
+	ChecklistItemRejected   timelineEventType = "item_rejected"
+	RunStatusChanged        timelineEventType = "run_status_changed"
 )
 
 type TimelineEvent struct {
@@ -308,6 +340,9 @@ type GetPlaybookRunsResults struct {
 	PageCount  int           `json:"page_count"`
 	HasMore    bool          `json:"has_more"`
 	Items      []PlaybookRun `json:"items"`
+
+	// NextCursor is the cursor to get the next page with, if HasMore.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type SQLStatusPost struct {
@@ -390,6 +425,9 @@ type PlaybookRunService interface {
 	// GetPlaybookRuns returns filtered playbook runs and the total count before paging.
 	GetPlaybookRuns(requesterInfo RequesterInfo, options PlaybookRunFilterOptions) (*GetPlaybookRunsResults, error)
 
+	// SearchPlaybookRuns returns the playbook runs matching the search terms, most relevant first.
+	SearchPlaybookRuns(requesterInfo RequesterInfo, options PlaybookRunSearchOptions) (*PlaybookRunSearchResults, error)
+
 	// CreatePlaybookRun creates a new playbook run. userID is the user who initiated the CreatePlaybookRun.
 	CreatePlaybookRun(playbookRun *PlaybookRun, playbook *Playbook, userID string, public bool) (*PlaybookRun, error)
 
@@ -414,11 +452,18 @@ type PlaybookRunService interface {
 	// UpdateStatus updates a playbook run's status.
 	UpdateStatus(playbookRunID, userID string, options StatusUpdateOptions) error
 
-	// OpenFinishPlaybookRunDialog opens the dialog to confirm the run should be finished.
-	OpenFinishPlaybookRunDialog(playbookRunID, triggerID string) error
+	// ChangeRunStatus moves a run to one of the custom statuses it's allowed to move to from its
+	// current status, finishing it if the status is a finished one.
+	ChangeRunStatus(playbookRunID, userID, status string) error
+
+	// OpenFinishPlaybookRunDialog opens the dialog to confirm the run should be finished. The
+	// dialog lets system admins override the required items that are not done.
+	OpenFinishPlaybookRunDialog(playbookRunID, userID, triggerID string) error
 
 	// FinishPlaybookRun changes a run's state to Finished. If run is already in Finished state, the call is a noop.
-	FinishPlaybookRun(playbookRunID, userID string) error
+	// It fails with ErrRequiredItemsOpen if some required items are not done, unless
+	// overrideRequired is true and the user is a system admin, which is recorded in the timeline.
+	FinishPlaybookRun(playbookRunID, userID string, overrideRequired bool) error
 
 	// GetPlaybookRun gets a playbook run by ID. Returns error if it could not be found.
 	GetPlaybookRun(playbookRunID string) (*PlaybookRun, error)
@@ -454,6 +499,22 @@ type PlaybookRunService interface {
 	// RunChecklistItemSlashCommand executes the slash command associated with the specified checklist item.
 	RunChecklistItemSlashCommand(playbookRunID, userID string, checklistNumber, itemNumber int) (string, error)
 
+	// PreviewChecklistItemSlashCommand returns the slash command of the specified checklist item
+	// with its placeholders resolved, without running it.
+	PreviewChecklistItemSlashCommand(playbookRunID, userID string, checklistNumber, itemNumber int) (ChecklistItemCommandPreview, error)
+
+	// RunChecklistItemHTTPAction performs the HTTP action of the specified checklist item, if
+	// confirmed when it requires confirmation, and records its result.
+	RunChecklistItemHTTPAction(playbookRunID, userID string, checklistNumber, itemNumber int, confirmed bool) (ChecklistItemHTTPActionResult, error)
+
+	// DecideChecklistItemApproval records the sign-off, or rejection, by an approver of the
+	// checklist item waiting for approval, closing or reopening it accordingly.
+	DecideChecklistItemApproval(playbookRunID, userID, itemID string, approve bool) error
+
+	// UpdateCustomData replaces the custom data of the playbook run, the values of the
+	// {{custom.<key>}} placeholders of its checklists.
+	UpdateCustomData(playbookRunID, userID string, customData map[string]string) error
+
 	// AddChecklistItem adds an item to the specified checklist
 	AddChecklistItem(playbookRunID, userID string, checklistNumber int, checklistItem ChecklistItem) error
 
@@ -537,6 +598,9 @@ type PlaybookRunStore interface {
 	// GetPlaybookRuns returns filtered playbook runs and the total count before paging.
 	GetPlaybookRuns(requesterInfo RequesterInfo, options PlaybookRunFilterOptions) (*GetPlaybookRunsResults, error)
 
+	// SearchPlaybookRuns returns the playbook runs matching the search terms, most relevant first.
+	SearchPlaybookRuns(requesterInfo RequesterInfo, options PlaybookRunSearchOptions) (*PlaybookRunSearchResults, error)
+
 	// CreatePlaybookRun creates a new playbook run. If playbook run has an ID, that ID will be used.
 	CreatePlaybookRun(playbookRun *PlaybookRun) (*PlaybookRun, error)
 
@@ -659,6 +723,22 @@ type PlaybookRunTelemetry interface {
 	PublishRetrospective(playbookRun *PlaybookRun, userID string)
 }
 
+// PlaybookRunMetrics defines the methods that the PlaybookRunServiceImpl needs from the metrics
+// exposed to monitoring systems.
+type PlaybookRunMetrics interface {
+	// IncrementRunsCreated counts a run created in teamID from playbookID.
+	IncrementRunsCreated(teamID, playbookID string)
+
+	// IncrementRunsFinished counts a run of teamID and playbookID being finished.
+	IncrementRunsFinished(teamID, playbookID string)
+
+	// IncrementRemindersScheduled counts a status update or retrospective reminder being scheduled.
+	IncrementRemindersScheduled()
+
+	// IncrementWebhookFailures counts a webhook that couldn't be delivered.
+	IncrementWebhookFailures()
+}
+
 type JobOnceScheduler interface {
 	Start() error
 	SetCallback(callback func(string)) error
@@ -678,6 +758,14 @@ type PlaybookRunFilterOptions struct {
 	Page    int `url:"page,omitempty"`
 	PerPage int `url:"per_page,omitempty"`
 
+	// Cursor continues the listing right after the last item of a previous page, as given by
+	// GetPlaybookRunsResults.NextCursor. When set, Page is ignored.
+	Cursor string `url:"cursor,omitempty"`
+
+	// SkipCount skips counting all the matching playbook runs, leaving TotalCount and PageCount
+	// of the results at 0.
+	SkipCount bool `url:"skip_count,omitempty"`
+
 	// Sort sorts by this header field in json format (eg, "create_at", "end_at", "name", etc.);
 	// defaults to "create_at".
 	Sort SortField `url:"sort,omitempty"`
@@ -685,7 +773,8 @@ type PlaybookRunFilterOptions struct {
 	// Direction orders by ascending or descending, defaulting to ascending.
 	Direction SortDirection `url:"direction,omitempty"`
 
-	// Statuses filters by all statuses in the list (inclusive)
+	// Statuses filters by all statuses in the list (inclusive), InProgress, Finished or the name
+	// of a custom run status.
 	Statuses []string
 
 	// OwnerID filters by owner's Mattermost user ID. Defaults to blank (no filter).
@@ -718,6 +807,32 @@ type PlaybookRunFilterOptions struct {
 	// StartedLT filters playbook runs that were started before the unix time given (in millis).
 	// A value of 0 means the filter is ignored (which is the default).
 	StartedLT int64 `url:"started_lt,omitempty"`
+
+	// FinishedGTE filters playbook runs that were finished after (or equal) to the unix time given (in millis).
+	// A value of 0 means the filter is ignored (which is the default).
+	FinishedGTE int64 `url:"finished_gte,omitempty"`
+
+	// FinishedLT filters playbook runs that were finished before the unix time given (in millis).
+	// A value of 0 means the filter is ignored (which is the default).
+	FinishedLT int64 `url:"finished_lt,omitempty"`
+
+	// PlaybookIDs filters playbook runs that are derived from any of these playbook ids.
+	// Defaults to empty (no filter).
+	PlaybookIDs []string `url:"playbook_ids,omitempty"`
+
+	// ReporterID filters by reporter's Mattermost user ID. Defaults to blank (no filter).
+	ReporterID string `url:"reporter_user_id,omitempty"`
+
+	// AssigneeID filters playbook runs that have at least one checklist item assigned to this user.
+	// Defaults to blank (no filter).
+	AssigneeID string `url:"assignee_id,omitempty"`
+
+	// StatusUpdateOverdue filters playbook runs in progress whose status update is overdue.
+	StatusUpdateOverdue bool `url:"status_update_overdue,omitempty"`
+
+	// OpenTasksGTE filters playbook runs that have at least this number of checklist items that
+	// are not closed yet. A value of 0 means the filter is ignored (which is the default).
+	OpenTasksGTE int `url:"open_tasks_gte,omitempty"`
 }
 
 // Clone duplicates the given options.
@@ -726,6 +841,9 @@ func (o *PlaybookRunFilterOptions) Clone() PlaybookRunFilterOptions {
 	if len(o.Statuses) > 0 {
 		newPlaybookRunFilterOptions.Statuses = append([]string{}, o.Statuses...)
 	}
+	if len(o.PlaybookIDs) > 0 {
+		newPlaybookRunFilterOptions.PlaybookIDs = append([]string{}, o.PlaybookIDs...)
+	}
 
 	return newPlaybookRunFilterOptions
 }
@@ -780,6 +898,20 @@ func (o PlaybookRunFilterOptions) Validate() (PlaybookRunFilterOptions, error) {
 		return PlaybookRunFilterOptions{}, errors.New("bad parameter 'playbook_id': must be 26 characters or blank")
 	}
 
+	for _, playbookID := range options.PlaybookIDs {
+		if !model.IsValidId(playbookID) {
+			return PlaybookRunFilterOptions{}, errors.New("bad parameter in 'playbook_ids': must be 26 characters")
+		}
+	}
+
+	if options.ReporterID != "" && !model.IsValidId(options.ReporterID) {
+		return PlaybookRunFilterOptions{}, errors.New("bad parameter 'reporter_user_id': must be 26 characters or blank")
+	}
+
+	if options.AssigneeID != "" && !model.IsValidId(options.AssigneeID) {
+		return PlaybookRunFilterOptions{}, errors.New("bad parameter 'assignee_id': must be 26 characters or blank")
+	}
+
 	if options.ActiveGTE < 0 {
 		options.ActiveGTE = 0
 	}
@@ -792,16 +924,32 @@ func (o PlaybookRunFilterOptions) Validate() (PlaybookRunFilterOptions, error) {
 	if options.StartedLT < 0 {
 		options.StartedLT = 0
 	}
+	if options.FinishedGTE < 0 {
+		options.FinishedGTE = 0
+	}
+	if options.FinishedLT < 0 {
+		options.FinishedLT = 0
+	}
+	if options.OpenTasksGTE < 0 {
+		options.OpenTasksGTE = 0
+	}
 
 	for _, s := range options.Statuses {
 		if !validStatus(s) {
-			return PlaybookRunFilterOptions{}, errors.New("bad parameter in 'statuses': must be InProgress or Finished")
+			return PlaybookRunFilterOptions{}, errors.New("bad parameter in 'statuses': must be InProgress, Finished or the name of a run status")
+		}
+	}
+
+	if options.Cursor != "" {
+		if err := validatePageCursor(options.Cursor, options.Sort, options.Direction); err != nil {
+			return PlaybookRunFilterOptions{}, err
 		}
+		options.Page = 0
 	}
 
 	return options, nil
 }
 
 func validStatus(status string) bool {
-	return status == "" || status == StatusInProgress || status == StatusFinished
+	return status == "" || status == StatusInProgress || status == StatusFinished || IsValidRunStatusName(status)
 }
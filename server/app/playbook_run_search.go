@@ -0,0 +1,123 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// The sources of a playbook run indexed for search.
+const (
+	SearchSourceName          = "name"
+	SearchSourceDescription   = "description"
+	SearchSourceStatusUpdate  = "status_update"
+	SearchSourceTimelineEvent = "timeline_event"
+	SearchSourceChecklistItem = "checklist_item"
+	SearchSourceRetrospective = "retrospective"
+)
+
+const (
+	// SearchPerPageDefault is the number of results returned when no page size is given.
+	SearchPerPageDefault = 20
+
+	// SearchPerPageMax is the maximum number of results returned per page.
+	SearchPerPageMax = 100
+
+	// SearchTermsMaxLength is the maximum length, in characters, of the search terms.
+	SearchTermsMaxLength = 256
+)
+
+// PlaybookRunSearchOptions specifies the parameters when searching playbook runs.
+type PlaybookRunSearchOptions struct {
+	// Terms are the words to look for, in natural language.
+	Terms string `url:"terms"`
+
+	// TeamID limits the search to this team. Defaults to all the teams of the user.
+	TeamID string `url:"team_id,omitempty"`
+
+	// Pagination options.
+	Page    int `url:"page,omitempty"`
+	PerPage int `url:"per_page,omitempty"`
+}
+
+// Validate returns a new, validated search options or returns an error if invalid.
+func (o PlaybookRunSearchOptions) Validate() (PlaybookRunSearchOptions, error) {
+	options := o
+
+	options.Terms = strings.TrimSpace(options.Terms)
+	if options.Terms == "" {
+		return PlaybookRunSearchOptions{}, errors.New("bad parameter 'terms': must not be blank")
+	}
+	if utf8.RuneCountInString(options.Terms) > SearchTermsMaxLength {
+		return PlaybookRunSearchOptions{}, errors.Errorf("bad parameter 'terms': must be at most %d characters", SearchTermsMaxLength)
+	}
+
+	if options.TeamID != "" && !model.IsValidId(options.TeamID) {
+		return PlaybookRunSearchOptions{}, errors.New("bad parameter 'team_id': must be 26 characters or blank")
+	}
+
+	if options.Page < 0 {
+		options.Page = 0
+	}
+	if options.PerPage <= 0 {
+		options.PerPage = SearchPerPageDefault
+	}
+	if options.PerPage > SearchPerPageMax {
+		options.PerPage = SearchPerPageMax
+	}
+
+	return options, nil
+}
+
+// PlaybookRunSearchMatch is a piece of a playbook run matching the search terms.
+type PlaybookRunSearchMatch struct {
+	// Source is where the match was found: one of the SearchSource constants.
+	Source string `json:"source"`
+
+	// SourceID identifies the matching status post, timeline event or checklist item. It is
+	// empty for the sources that are unique to the run.
+	SourceID string `json:"source_id"`
+
+	// Snippet is an excerpt of the matching text, with the matching words surrounded by **.
+	Snippet string `json:"snippet"`
+}
+
+// PlaybookRunSearchResult is a playbook run matching the search terms.
+type PlaybookRunSearchResult struct {
+	PlaybookRunID string `json:"playbook_run_id"`
+	Name          string `json:"name"`
+	TeamID        string `json:"team_id"`
+	CurrentStatus string `json:"current_status"`
+	CreateAt      int64  `json:"create_at"`
+
+	// Rank is the relevance of the run. Higher is more relevant.
+	Rank float64 `json:"rank"`
+
+	// Matches are the best matching pieces of the run, most relevant first.
+	Matches []PlaybookRunSearchMatch `json:"matches"`
+}
+
+// PlaybookRunSearchResults collects the results of the SearchPlaybookRuns call: the matching
+// playbook runs, most relevant first, and the TotalCount of them before paging was applied.
+type PlaybookRunSearchResults struct {
+	TotalCount int                       `json:"total_count"`
+	PageCount  int                       `json:"page_count"`
+	HasMore    bool                      `json:"has_more"`
+	Items      []PlaybookRunSearchResult `json:"items"`
+}
+
+func (r PlaybookRunSearchResults) MarshalJSON() ([]byte, error) {
+	type Alias PlaybookRunSearchResults
+
+	old := Alias(r)
+
+	// replace nils with empty slices for the frontend
+	if old.Items == nil {
+		old.Items = []PlaybookRunSearchResult{}
+	}
+
+	return json.Marshal(old)
+}
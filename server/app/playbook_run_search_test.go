@@ -0,0 +1,69 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaybookRunSearchOptions_Validate(t *testing.T) {
+	t.Run("trims the terms and applies the paging defaults", func(t *testing.T) {
+		options := PlaybookRunSearchOptions{
+			Terms:  "  redis memory \n",
+			TeamID: model.NewId(),
+			Page:   -1,
+		}
+
+		validOptions, err := options.Validate()
+		require.NoError(t, err)
+		require.Equal(t, "redis memory", validOptions.Terms)
+		require.Equal(t, options.TeamID, validOptions.TeamID)
+		require.Equal(t, 0, validOptions.Page)
+		require.Equal(t, SearchPerPageDefault, validOptions.PerPage)
+	})
+
+	t.Run("caps PerPage", func(t *testing.T) {
+		options := PlaybookRunSearchOptions{
+			Terms:   "redis",
+			PerPage: SearchPerPageMax + 1,
+		}
+
+		validOptions, err := options.Validate()
+		require.NoError(t, err)
+		require.Equal(t, SearchPerPageMax, validOptions.PerPage)
+	})
+
+	t.Run("blank terms", func(t *testing.T) {
+		options := PlaybookRunSearchOptions{
+			Terms: " \t ",
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("terms too long", func(t *testing.T) {
+		options := PlaybookRunSearchOptions{
+			Terms: strings.Repeat("é", SearchTermsMaxLength+1),
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+
+		options.Terms = strings.Repeat("é", SearchTermsMaxLength)
+		_, err = options.Validate()
+		require.NoError(t, err)
+	})
+
+	t.Run("invalid team id", func(t *testing.T) {
+		options := PlaybookRunSearchOptions{
+			Terms:  "redis",
+			TeamID: "invalid",
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+}
@@ -31,15 +31,20 @@ const (
 
 // PlaybookRunServiceImpl holds the information needed by the PlaybookRunService's methods to complete their functions.
 type PlaybookRunServiceImpl struct {
-	pluginAPI     *pluginapi.Client
-	httpClient    *http.Client
-	configService config.Service
-	store         PlaybookRunStore
-	poster        bot.Poster
-	logger        bot.Logger
-	scheduler     JobOnceScheduler
-	telemetry     PlaybookRunTelemetry
-	api           plugin.API
+	pluginAPI  *pluginapi.Client
+	httpClient *http.Client
+
+	// httpActionClient performs the HTTP actions of the checklist items.
+	httpActionClient *http.Client
+	configService    config.Service
+	store            PlaybookRunStore
+	playbookService  PlaybookService
+	poster           bot.Poster
+	logger           bot.Logger
+	scheduler        JobOnceScheduler
+	telemetry        PlaybookRunTelemetry
+	metrics          PlaybookRunMetrics
+	api              plugin.API
 }
 
 var allNonSpaceNonWordRegex = regexp.MustCompile(`[^\w\s]`)
@@ -78,18 +83,21 @@ const DialogFieldItemDescriptionKey = "description"
 const DialogFieldItemCommandKey = "command"
 
 // NewPlaybookRunService creates a new PlaybookRunServiceImpl.
-func NewPlaybookRunService(pluginAPI *pluginapi.Client, store PlaybookRunStore, poster bot.Poster, logger bot.Logger,
-	configService config.Service, scheduler JobOnceScheduler, telemetry PlaybookRunTelemetry, api plugin.API) *PlaybookRunServiceImpl {
+func NewPlaybookRunService(pluginAPI *pluginapi.Client, store PlaybookRunStore, playbookService PlaybookService, poster bot.Poster, logger bot.Logger,
+	configService config.Service, scheduler JobOnceScheduler, telemetry PlaybookRunTelemetry, metrics PlaybookRunMetrics, api plugin.API) *PlaybookRunServiceImpl {
 	return &PlaybookRunServiceImpl{
-		pluginAPI:     pluginAPI,
-		store:         store,
-		poster:        poster,
-		logger:        logger,
-		configService: configService,
-		scheduler:     scheduler,
-		telemetry:     telemetry,
-		httpClient:    httptools.MakeClient(pluginAPI),
-		api:           api,
+		pluginAPI:        pluginAPI,
+		store:            store,
+		playbookService:  playbookService,
+		poster:           poster,
+		logger:           logger,
+		configService:    configService,
+		scheduler:        scheduler,
+		telemetry:        telemetry,
+		metrics:          metrics,
+		httpClient:       httptools.MakeClient(pluginAPI),
+		httpActionClient: newHTTPActionClient(pluginAPI),
+		api:              api,
 	}
 }
 
@@ -107,6 +115,16 @@ func (s *PlaybookRunServiceImpl) GetPlaybookRuns(requesterInfo RequesterInfo, op
 	}, nil
 }
 
+// SearchPlaybookRuns returns the playbook runs matching the search terms, most relevant first.
+func (s *PlaybookRunServiceImpl) SearchPlaybookRuns(requesterInfo RequesterInfo, options PlaybookRunSearchOptions) (*PlaybookRunSearchResults, error) {
+	results, err := s.store.SearchPlaybookRuns(requesterInfo, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't search playbook runs in the store")
+	}
+
+	return results, nil
+}
+
 func (s *PlaybookRunServiceImpl) broadcastPlaybookRunCreation(playbookTitle, playbookID, broadcastChannelID string, playbookRun *PlaybookRun, owner *model.User) error {
 	if err := IsChannelActiveInTeam(broadcastChannelID, playbookRun.TeamID, s.pluginAPI); err != nil {
 		return errors.Wrap(err, "announcement channel is not active")
@@ -246,6 +264,10 @@ func (s *PlaybookRunServiceImpl) CreatePlaybookRun(playbookRun *PlaybookRun, pb
 	playbookRun.CreateAt = now
 	playbookRun.LastStatusUpdateAt = now
 	playbookRun.CurrentStatus = StatusInProgress
+	playbookRun.CurrentRunStatus = ""
+	if len(playbookRun.RunStatuses) > 0 {
+		playbookRun.CurrentRunStatus = playbookRun.RunStatuses[0].Name
+	}
 
 	// Start with a blank playbook with one empty checklist if one isn't provided
 	if playbookRun.PlaybookID == "" {
@@ -257,12 +279,19 @@ func (s *PlaybookRunServiceImpl) CreatePlaybookRun(playbookRun *PlaybookRun, pb
 		}
 	}
 
+	// The titles and descriptions of the items are expanded once, when the run starts, while
+	// the commands are expanded every time they run.
+	if checklistsHaveVariables(playbookRun.Checklists) {
+		playbookRun.Checklists = ExpandChecklistsVariables(playbookRun.Checklists, s.checklistVariables(playbookRun))
+	}
+
 	playbookRun, err = s.store.CreatePlaybookRun(playbookRun)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create playbook run")
 	}
 
 	s.telemetry.CreatePlaybookRun(playbookRun, userID, public)
+	s.metrics.IncrementRunsCreated(playbookRun.TeamID, playbookRun.PlaybookID)
 
 	// Add users to channel after creating playbook run so that all automations trigger.
 	err = s.addPlaybookRunUsers(playbookRun, channel)
@@ -394,6 +423,10 @@ func (s *PlaybookRunServiceImpl) CreatePlaybookRun(playbookRun *PlaybookRun, pb
 		s.sendWebhooksOnCreation(*playbookRun)
 	}
 
+	if checklistsHaveAutoRun(playbookRun.Checklists) {
+		s.startChecklistAutomation(playbookRun.ID)
+	}
+
 	if playbookRun.PostID == "" {
 		return playbookRun, nil
 	}
@@ -455,7 +488,7 @@ func (s *PlaybookRunServiceImpl) OpenUpdateStatusDialog(playbookRunID, triggerID
 		message = currentPlaybookRun.ReminderMessageTemplate
 	}
 
-	dialog, err := s.newUpdatePlaybookRunDialog(currentPlaybookRun.Description, message, currentPlaybookRun.BroadcastChannelIDs, currentPlaybookRun.PreviousReminder)
+	dialog, err := s.newUpdatePlaybookRunDialog(currentPlaybookRun, message)
 	if err != nil {
 		return errors.Wrap(err, "failed to create update status dialog")
 	}
@@ -736,6 +769,12 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 		return errors.Wrap(err, "failed to retrieve playbook run")
 	}
 
+	if options.RunStatus != "" {
+		if err = s.checkRunStatusChange(playbookRunToModify, userID, options.RunStatus); err != nil {
+			return err
+		}
+	}
+
 	originalPost, err := s.buildStatusUpdatePost(options.Message, playbookRunID, userID)
 	if err != nil {
 		return err
@@ -802,6 +841,12 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 
 	s.telemetry.UpdateStatus(playbookRunToModify, userID)
 
+	if options.RunStatus != "" && options.RunStatus != playbookRunToModify.CurrentRunStatus {
+		if err = s.ChangeRunStatus(playbookRunID, userID, options.RunStatus); err != nil {
+			return errors.Wrap(err, "failed to change the run status")
+		}
+	}
+
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
 	}
@@ -813,7 +858,7 @@ func (s *PlaybookRunServiceImpl) UpdateStatus(playbookRunID, userID string, opti
 	return nil
 }
 
-func (s *PlaybookRunServiceImpl) OpenFinishPlaybookRunDialog(playbookRunID, triggerID string) error {
+func (s *PlaybookRunServiceImpl) OpenFinishPlaybookRunDialog(playbookRunID, userID, triggerID string) error {
 	currentPlaybookRun, err := s.store.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve playbook run")
@@ -832,7 +877,7 @@ func (s *PlaybookRunServiceImpl) OpenFinishPlaybookRunDialog(playbookRunID, trig
 		URL: fmt.Sprintf("/plugins/%s/api/v0/runs/%s/finish-dialog",
 			s.configService.GetManifest().Id,
 			playbookRunID),
-		Dialog:    *s.newFinishPlaybookRunDialog(numOutstanding),
+		Dialog:    *s.newFinishPlaybookRunDialog(numOutstanding, OpenRequiredItems(currentPlaybookRun.Checklists), IsAdmin(userID, s.pluginAPI)),
 		TriggerId: triggerID,
 	}
 
@@ -844,7 +889,7 @@ func (s *PlaybookRunServiceImpl) OpenFinishPlaybookRunDialog(playbookRunID, trig
 }
 
 // FinishPlaybookRun changes a run's state to Finished. If run is already in Finished state, the call is a noop.
-func (s *PlaybookRunServiceImpl) FinishPlaybookRun(playbookRunID, userID string) error {
+func (s *PlaybookRunServiceImpl) FinishPlaybookRun(playbookRunID, userID string, overrideRequired bool) error {
 	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
 	if err != nil {
 		return errors.Wrap(err, "failed to retrieve playbook run")
@@ -854,17 +899,55 @@ func (s *PlaybookRunServiceImpl) FinishPlaybookRun(playbookRunID, userID string)
 		return nil
 	}
 
+	openRequired := OpenRequiredItems(playbookRunToModify.Checklists)
+	if len(openRequired) > 0 {
+		if !overrideRequired {
+			return errors.Wrapf(ErrRequiredItemsOpen, "%d required items are not done", len(openRequired))
+		}
+		if !IsAdmin(userID, s.pluginAPI) {
+			return errors.Wrap(ErrNoPermissions, "only system admins can override the required items")
+		}
+	}
+
+	// Runs with custom statuses end up in the first finished one, unless already in one.
+	if status, ok := firstFinishedRunStatus(playbookRunToModify.RunStatuses); ok && !isFinishedRunStatus(playbookRunToModify.RunStatuses, playbookRunToModify.CurrentRunStatus) {
+		if err = s.recordRunStatusChange(playbookRunToModify, userID, status); err != nil {
+			return err
+		}
+	}
+
 	endAt := model.GetMillis()
 	if err = s.store.FinishPlaybookRun(playbookRunID, endAt); err != nil {
 		return err
 	}
 
+	// The override is recorded as soon as the run is finished, whatever happens next.
+	var overrideEvent *TimelineEvent
+	if len(openRequired) > 0 {
+		overrideEvent = &TimelineEvent{
+			PlaybookRunID: playbookRunID,
+			CreateAt:      endAt,
+			EventAt:       endAt,
+			EventType:     RequiredItemsOverridden,
+			Summary:       fmt.Sprintf("overrode %d required items that were not done", len(openRequired)),
+			Details:       FormatOpenRequiredItems(openRequired),
+			SubjectUserID: userID,
+		}
+
+		if _, err = s.store.CreateTimelineEvent(overrideEvent); err != nil {
+			return errors.Wrap(err, "failed to create timeline event")
+		}
+	}
+
 	user, err := s.pluginAPI.User.Get(userID)
 	if err != nil {
 		return errors.Wrapf(err, "failed to to resolve user %s", userID)
 	}
 
 	message := fmt.Sprintf("@%s marked this run as finished.", user.Username)
+	if len(openRequired) > 0 {
+		message = fmt.Sprintf("@%s marked this run as finished, overriding the required items that are not done:\n%s", user.Username, FormatOpenRequiredItems(openRequired))
+	}
 	postID := ""
 	post, err := s.poster.PostMessage(playbookRunToModify.ChannelID, message)
 	if err != nil {
@@ -873,6 +956,13 @@ func (s *PlaybookRunServiceImpl) FinishPlaybookRun(playbookRunID, userID string)
 		postID = post.Id
 	}
 
+	if overrideEvent != nil && postID != "" {
+		overrideEvent.PostID = postID
+		if err = s.store.UpdateTimelineEvent(overrideEvent); err != nil {
+			s.pluginAPI.Log.Warn("failed to link the required items override to its post", "PlaybookRunID", playbookRunID, "error", err.Error())
+		}
+	}
+
 	for _, broadcastChannelID := range playbookRunToModify.BroadcastChannelIDs {
 		if err = s.broadcastPlaybookRunFinish(message, broadcastChannelID, playbookRunToModify, user); err != nil {
 			s.pluginAPI.Log.Warn("failed to broadcast the status update to channel")
@@ -935,6 +1025,7 @@ func (s *PlaybookRunServiceImpl) FinishPlaybookRun(playbookRunID, userID string)
 	}
 
 	s.telemetry.FinishPlaybookRun(playbookRunToModify, userID)
+	s.metrics.IncrementRunsFinished(playbookRunToModify.TeamID, playbookRunToModify.PlaybookID)
 
 	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
 		return err
@@ -1146,11 +1237,22 @@ func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newSt
 		return errors.New("invalid checklist item indicies")
 	}
 
+	return s.modifyCheckedState(playbookRunToModify, userID, newState, checklistNumber, itemNumber)
+}
+
+// modifyCheckedState checks or unchecks the specified checklist item as userID, who is not
+// checked to be allowed to, and runs the command of the next item if it waits for this one.
+func (s *PlaybookRunServiceImpl) modifyCheckedState(playbookRunToModify *PlaybookRun, userID, newState string, checklistNumber, itemNumber int) error {
+	playbookRunID := playbookRunToModify.ID
 	itemToCheck := playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
 	if newState == itemToCheck.State {
 		return nil
 	}
 
+	if newState == ChecklistItemStateClosed && itemToCheck.Approval.HasApprovers() && !itemToCheck.isApproved() {
+		return s.requestChecklistItemApproval(playbookRunToModify, userID, checklistNumber, itemNumber)
+	}
+
 	// Send modification message before the actual modification because we need the postID
 	// from the notification message.
 	mainChannelID := playbookRunToModify.ChannelID
@@ -1166,6 +1268,9 @@ func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newSt
 	itemToCheck.State = newState
 	itemToCheck.StateModified = model.GetMillis()
 	itemToCheck.StateModifiedPostID = post.Id
+	if newState != ChecklistItemStateClosed {
+		itemToCheck.clearApproval()
+	}
 	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber] = itemToCheck
 
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
@@ -1192,6 +1297,10 @@ func (s *PlaybookRunServiceImpl) ModifyCheckedState(playbookRunID, userID, newSt
 		return errors.Wrap(err, "failed to send playbook run to client")
 	}
 
+	if newState == ChecklistItemStateClosed {
+		s.autoRunNextChecklistItem(playbookRunToModify, checklistNumber, itemNumber)
+	}
+
 	return nil
 }
 
@@ -1305,24 +1414,41 @@ func (s *PlaybookRunServiceImpl) RunChecklistItemSlashCommand(playbookRunID, use
 		return "", errors.New("invalid checklist item indices")
 	}
 
+	return s.runChecklistItemSlashCommand(playbookRun, userID, checklistNumber, itemNumber, false)
+}
+
+// runChecklistItemSlashCommand runs the slash command of the specified checklist item as userID,
+// who is not checked to be allowed to. When automatic, the failures are only returned, since
+// there is nobody to show them to.
+func (s *PlaybookRunServiceImpl) runChecklistItemSlashCommand(playbookRun *PlaybookRun, userID string, checklistNumber, itemNumber int, automatic bool) (string, error) {
+	playbookRunID := playbookRun.ID
 	itemToRun := playbookRun.Checklists[checklistNumber].Items[itemNumber]
 	if strings.TrimSpace(itemToRun.Command) == "" {
 		return "", errors.New("no slash command associated with this checklist item")
 	}
 
+	command := itemToRun.Command
+	if HasChecklistVariables(command) {
+		command, _ = ExpandChecklistVariables(command, s.checklistVariables(playbookRun), true)
+	}
+
 	cmdResponse, err := s.pluginAPI.SlashCommand.Execute(&model.CommandArgs{
-		Command:   itemToRun.Command,
+		Command:   command,
 		UserId:    userID,
 		TeamId:    playbookRun.TeamID,
 		ChannelId: playbookRun.ChannelID,
 	})
 	if err == pluginapi.ErrNotFound {
-		trigger := strings.Fields(itemToRun.Command)[0]
-		s.poster.EphemeralPost(userID, playbookRun.ChannelID, &model.Post{Message: fmt.Sprintf("Failed to find slash command **%s**", trigger)})
+		trigger := strings.Fields(command)[0]
+		if !automatic {
+			s.poster.EphemeralPost(userID, playbookRun.ChannelID, &model.Post{Message: fmt.Sprintf("Failed to find slash command **%s**", trigger)})
+		}
 
 		return "", errors.Wrap(err, "failed to find slash command")
 	} else if err != nil {
-		s.poster.EphemeralPost(userID, playbookRun.ChannelID, &model.Post{Message: fmt.Sprintf("Failed to execute slash command **%s**", itemToRun.Command)})
+		if !automatic {
+			s.poster.EphemeralPost(userID, playbookRun.ChannelID, &model.Post{Message: fmt.Sprintf("Failed to execute slash command **%s**", command)})
+		}
 
 		return "", errors.Wrap(err, "failed to run slash command")
 	}
@@ -1335,13 +1461,18 @@ func (s *PlaybookRunServiceImpl) RunChecklistItemSlashCommand(playbookRunID, use
 
 	s.telemetry.RunTaskSlashCommand(playbookRunID, userID, itemToRun)
 
+	summary := fmt.Sprintf("ran the slash command: `%s`", command)
+	if automatic {
+		summary = fmt.Sprintf("automatically ran the slash command: `%s`", command)
+	}
+
 	eventTime := model.GetMillis()
 	event := &TimelineEvent{
 		PlaybookRunID: playbookRunID,
 		CreateAt:      eventTime,
 		EventAt:       eventTime,
 		EventType:     RanSlashCommand,
-		Summary:       fmt.Sprintf("ran the slash command: `%s`", itemToRun.Command),
+		Summary:       summary,
 		SubjectUserID: userID,
 	}
 
@@ -1356,6 +1487,110 @@ func (s *PlaybookRunServiceImpl) RunChecklistItemSlashCommand(playbookRunID, use
 	return cmdResponse.TriggerId, nil
 }
 
+// PreviewChecklistItemSlashCommand returns the slash command of the specified checklist item with
+// its placeholders resolved, without running it.
+func (s *PlaybookRunServiceImpl) PreviewChecklistItemSlashCommand(playbookRunID, userID string, checklistNumber, itemNumber int) (ChecklistItemCommandPreview, error) {
+	playbookRun, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
+	if err != nil {
+		return ChecklistItemCommandPreview{}, err
+	}
+
+	if !IsValidChecklistItemIndex(playbookRun.Checklists, checklistNumber, itemNumber) {
+		return ChecklistItemCommandPreview{}, errors.New("invalid checklist item indices")
+	}
+
+	command := playbookRun.Checklists[checklistNumber].Items[itemNumber].Command
+	preview := ChecklistItemCommandPreview{
+		Command:          command,
+		ResolvedCommand:  command,
+		UnknownVariables: []string{},
+	}
+	if HasChecklistVariables(command) {
+		var unknown []string
+		preview.ResolvedCommand, unknown = ExpandChecklistVariables(command, s.checklistVariables(playbookRun), true)
+		preview.UnknownVariables = append(preview.UnknownVariables, unknown...)
+	}
+
+	return preview, nil
+}
+
+// UpdateCustomData replaces the custom data of the playbook run.
+func (s *PlaybookRunServiceImpl) UpdateCustomData(playbookRunID, userID string, customData map[string]string) error {
+	if err := ValidateCustomData(customData); err != nil {
+		return err
+	}
+
+	playbookRunToModify, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve playbook run")
+	}
+
+	// The custom data ends up in the commands of the checklists, so changing it takes the same
+	// permission as editing them.
+	if err = RunActionAccess(userID, playbookRunToModify, RunActionEditChecklists, s.playbookService, s.pluginAPI); err != nil {
+		return errors.Wrap(err, "update the custom data")
+	}
+
+	playbookRunToModify.CustomData = customData
+	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
+		return errors.Wrapf(err, "failed to update playbook run")
+	}
+
+	if err = s.sendPlaybookRunToClient(playbookRunID); err != nil {
+		return errors.Wrap(err, "failed to send playbook run to client")
+	}
+
+	return nil
+}
+
+// checklistVariables returns the values of the placeholders of the checklists of the playbook
+// run: its fields, owner, channel and team, its custom data and the current time. The values
+// that can't be retrieved are left out, so their placeholders stay as written.
+func (s *PlaybookRunServiceImpl) checklistVariables(playbookRun *PlaybookRun) map[string]string {
+	now := time.Now().UTC()
+	values := map[string]string{
+		"run.id":          playbookRun.ID,
+		"run.name":        playbookRun.Name,
+		"run.description": playbookRun.Description,
+		"run.status":      playbookRun.CurrentStatus,
+		"owner.id":        playbookRun.OwnerUserID,
+		"channel.id":      playbookRun.ChannelID,
+		"team.id":         playbookRun.TeamID,
+		"now":             now.Format(time.RFC3339),
+		"now.date":        now.Format("2006-01-02"),
+		"now.unix":        strconv.FormatInt(now.Unix(), 10),
+	}
+
+	if siteURL := s.pluginAPI.Configuration.GetConfig().ServiceSettings.SiteURL; siteURL != nil && *siteURL != "" {
+		values["run.url"] = getRunDetailsURL(*siteURL, s.configService.GetManifest().Id, playbookRun.ID)
+	}
+
+	if owner, err := s.pluginAPI.User.Get(playbookRun.OwnerUserID); err == nil {
+		values["owner.username"] = owner.Username
+	}
+
+	if playbookRun.ChannelID != "" {
+		if channel, err := s.pluginAPI.Channel.Get(playbookRun.ChannelID); err == nil {
+			values["channel.name"] = channel.Name
+			values["channel.display_name"] = channel.DisplayName
+			if playbookRun.Name == "" {
+				values["run.name"] = channel.DisplayName
+			}
+		}
+	}
+
+	if team, err := s.pluginAPI.Team.Get(playbookRun.TeamID); err == nil {
+		values["team.name"] = team.Name
+		values["team.display_name"] = team.DisplayName
+	}
+
+	for key, value := range playbookRun.CustomData {
+		values["custom."+key] = value
+	}
+
+	return values
+}
+
 // AddChecklistItem adds an item to the specified checklist
 func (s *PlaybookRunServiceImpl) AddChecklistItem(playbookRunID, userID string, checklistNumber int, checklistItem ChecklistItem) error {
 	playbookRunToModify, err := s.checklistParamsVerify(playbookRunID, userID, checklistNumber)
@@ -1363,6 +1598,13 @@ func (s *PlaybookRunServiceImpl) AddChecklistItem(playbookRunID, userID string,
 		return err
 	}
 
+	// The command runs with the permissions of the chosen user, so people can only choose
+	// themselves or the bot.
+	if checklistItem.AutoRunUserID != "" && checklistItem.AutoRunUserID != userID {
+		return errors.Wrapf(ErrNoPermissions, "userID %s can't choose userID %s to run the command automatically", userID, checklistItem.AutoRunUserID)
+	}
+
+	checklistItem = newRunChecklistItem(checklistItem)
 	playbookRunToModify.Checklists[checklistNumber].Items = append(playbookRunToModify.Checklists[checklistNumber].Items, checklistItem)
 
 	if err = s.store.UpdatePlaybookRun(playbookRunToModify); err != nil {
@@ -1375,6 +1617,22 @@ func (s *PlaybookRunServiceImpl) AddChecklistItem(playbookRunID, userID string,
 	return nil
 }
 
+// newRunChecklistItem returns the fields of item that anyone editing the checklists of a run may
+// set. The HTTP action, the approval and the required flag only come from the playbook, edited by
+// its editors, and the state of the item only changes by checking it off.
+func newRunChecklistItem(item ChecklistItem) ChecklistItem {
+	return ChecklistItem{
+		ID:                   item.ID,
+		Title:                item.Title,
+		Description:          item.Description,
+		Command:              item.Command,
+		AssigneeID:           item.AssigneeID,
+		AutoRun:              item.AutoRun,
+		AutoRunOffsetSeconds: item.AutoRunOffsetSeconds,
+		AutoRunUserID:        item.AutoRunUserID,
+	}
+}
+
 // RemoveChecklistItem removes the item at the given index from the given checklist
 func (s *PlaybookRunServiceImpl) RemoveChecklistItem(playbookRunID, userID string, checklistNumber, itemNumber int) error {
 	playbookRunToModify, err := s.checklistItemParamsVerify(playbookRunID, userID, checklistNumber, itemNumber)
@@ -1383,6 +1641,10 @@ func (s *PlaybookRunServiceImpl) RemoveChecklistItem(playbookRunID, userID strin
 	}
 
 	checklistItem := playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]
+	if checklistItem.Required && !IsAdmin(userID, s.pluginAPI) {
+		return errors.Wrap(ErrNoPermissions, "only system admins can remove a required item")
+	}
+
 	playbookRunToModify.Checklists[checklistNumber].Items = append(
 		playbookRunToModify.Checklists[checklistNumber].Items[:itemNumber],
 		playbookRunToModify.Checklists[checklistNumber].Items[itemNumber+1:]...,
@@ -1405,6 +1667,11 @@ func (s *PlaybookRunServiceImpl) EditChecklistItem(playbookRunID, userID string,
 		return err
 	}
 
+	// A rewritten command no longer runs automatically as the user who chose the previous one.
+	if item := &playbookRunToModify.Checklists[checklistNumber].Items[itemNumber]; item.AutoRunUserID != "" && item.Command != newCommand {
+		item.AutoRunUserID = userID
+	}
+
 	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber].Title = newTitle
 	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber].Command = newCommand
 	playbookRunToModify.Checklists[checklistNumber].Items[itemNumber].Description = newDescription
@@ -1858,8 +2125,8 @@ func (s *PlaybookRunServiceImpl) UserHasLeftChannel(userID, channelID, actorID s
 }
 
 func (s *PlaybookRunServiceImpl) hasPermissionToModifyPlaybookRun(playbookRun *PlaybookRun, userID string) bool {
-	// PlaybookRun main channel membership is required to modify playbook run
-	return s.pluginAPI.User.HasPermissionToChannel(userID, playbookRun.ChannelID, model.PermissionReadChannel)
+	// The run permission policy of the playbook decides who may edit the checklists
+	return RunActionAccess(userID, playbookRun, RunActionEditChecklists, s.playbookService, s.pluginAPI) == nil
 }
 
 func (s *PlaybookRunServiceImpl) createPlaybookRunChannel(playbookRun *PlaybookRun, header string, public bool) (*model.Channel, error) {
@@ -1932,7 +2199,7 @@ func (s *PlaybookRunServiceImpl) addPlaybookRunUsers(playbookRun *PlaybookRun, c
 	return nil
 }
 
-func (s *PlaybookRunServiceImpl) newFinishPlaybookRunDialog(outstanding int) *model.Dialog {
+func (s *PlaybookRunServiceImpl) newFinishPlaybookRunDialog(outstanding int, openRequired []OpenRequiredItem, isAdmin bool) *model.Dialog {
 	message := "Are you sure you want to finish the run?"
 	if outstanding == 1 {
 		message = "There is **1 outstanding task**. Are you sure you want to finish the run?"
@@ -1940,9 +2207,27 @@ func (s *PlaybookRunServiceImpl) newFinishPlaybookRunDialog(outstanding int) *mo
 		message = "There are **" + strconv.Itoa(outstanding) + " outstanding tasks**. Are you sure you want to finish the run?"
 	}
 
+	var elements []model.DialogElement
+	if len(openRequired) > 0 {
+		message = "These required tasks are not done:\n" + FormatOpenRequiredItems(openRequired)
+		if isAdmin {
+			message += "\n\nAs a system admin, you can finish the run anyway. The override is recorded in the timeline."
+			elements = append(elements, model.DialogElement{
+				DisplayName: "Override required tasks",
+				Name:        DialogFieldOverrideRequiredItemsKey,
+				Type:        "bool",
+				Placeholder: "Finish the run without the required tasks",
+				Optional:    true,
+			})
+		} else {
+			message += "\n\nThe run can't be finished until they are done."
+		}
+	}
+
 	return &model.Dialog{
 		Title:            "Confirm finish run",
 		IntroductionText: message,
+		Elements:         elements,
 		SubmitLabel:      "Finish run",
 		NotifyOnCancel:   false,
 	}
@@ -2013,7 +2298,10 @@ func (s *PlaybookRunServiceImpl) newPlaybookRunDialog(teamID, ownerID, postID, c
 	}, nil
 }
 
-func (s *PlaybookRunServiceImpl) newUpdatePlaybookRunDialog(description, message string, broadcastChannelIDs []string, reminderTimer time.Duration) (*model.Dialog, error) {
+func (s *PlaybookRunServiceImpl) newUpdatePlaybookRunDialog(playbookRun *PlaybookRun, message string) (*model.Dialog, error) {
+	broadcastChannelIDs := playbookRun.BroadcastChannelIDs
+	reminderTimer := playbookRun.PreviousReminder
+
 	introductionText := "Provide an update to the stakeholders."
 
 	if len(broadcastChannelIDs) > 0 {
@@ -2061,27 +2349,55 @@ func (s *PlaybookRunServiceImpl) newUpdatePlaybookRunDialog(description, message
 		}
 	}
 
+	elements := []model.DialogElement{
+		{
+			DisplayName: "Change since last update",
+			Name:        DialogFieldMessageKey,
+			Type:        "textarea",
+			Default:     message,
+		},
+		{
+			DisplayName: "Reminder for next update",
+			Name:        DialogFieldReminderInSecondsKey,
+			Type:        "select",
+			Options:     reminderOptions,
+			Optional:    true,
+			Default:     fmt.Sprintf("%d", reminderTimer/time.Second),
+		},
+	}
+
+	// Runs with custom statuses can move to the next ones with the update.
+	if len(playbookRun.RunStatuses) > 0 {
+		var runStatusOptions []*model.PostActionOptions
+		if playbookRun.CurrentRunStatus != "" {
+			runStatusOptions = append(runStatusOptions, &model.PostActionOptions{
+				Text:  playbookRun.CurrentRunStatus,
+				Value: playbookRun.CurrentRunStatus,
+			})
+		}
+		for _, status := range AllowedRunStatusTransitions(playbookRun.RunStatuses, playbookRun.CurrentRunStatus) {
+			runStatusOptions = append(runStatusOptions, &model.PostActionOptions{
+				Text:  status,
+				Value: status,
+			})
+		}
+
+		elements = append(elements, model.DialogElement{
+			DisplayName: "Run status",
+			Name:        DialogFieldRunStatusKey,
+			Type:        "select",
+			Options:     runStatusOptions,
+			Optional:    true,
+			Default:     playbookRun.CurrentRunStatus,
+		})
+	}
+
 	return &model.Dialog{
 		Title:            "Status update",
 		IntroductionText: introductionText,
-		Elements: []model.DialogElement{
-			{
-				DisplayName: "Change since last update",
-				Name:        DialogFieldMessageKey,
-				Type:        "textarea",
-				Default:     message,
-			},
-			{
-				DisplayName: "Reminder for next update",
-				Name:        DialogFieldReminderInSecondsKey,
-				Type:        "select",
-				Options:     reminderOptions,
-				Optional:    true,
-				Default:     fmt.Sprintf("%d", reminderTimer/time.Second),
-			},
-		},
-		SubmitLabel:    "Update status",
-		NotifyOnCancel: false,
+		Elements:         elements,
+		SubmitLabel:      "Update status",
+		NotifyOnCancel:   false,
 	}, nil
 }
 
@@ -2374,6 +2690,7 @@ func triggerWebhooks(s *PlaybookRunServiceImpl, webhooks []string, body []byte)
 
 			if err != nil {
 				s.pluginAPI.Log.Warn("failed to create a POST request to webhook URL", "webhook URL", url, "error", err.Error())
+				s.metrics.IncrementWebhookFailures()
 				return
 			}
 
@@ -2382,6 +2699,7 @@ func triggerWebhooks(s *PlaybookRunServiceImpl, webhooks []string, body []byte)
 			resp, err := s.httpClient.Do(req)
 			if err != nil {
 				s.pluginAPI.Log.Warn("failed to send a POST request to webhook URL", "webhook URL", url, "error", err.Error())
+				s.metrics.IncrementWebhookFailures()
 				return
 			}
 
@@ -2390,6 +2708,7 @@ func triggerWebhooks(s *PlaybookRunServiceImpl, webhooks []string, body []byte)
 			if resp.StatusCode < 200 || resp.StatusCode > 299 {
 				err := errors.Errorf("response code is %d; expected a status code in the 2xx range", resp.StatusCode)
 				s.pluginAPI.Log.Warn("failed to finish a POST request to webhook URL", "webhook URL", url, "error", err.Error())
+				s.metrics.IncrementWebhookFailures()
 			}
 		}()
 	}
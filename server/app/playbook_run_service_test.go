@@ -12,9 +12,11 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/telemetry"
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -51,7 +53,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 		mattermostConfig.SetDefaults()
 		pluginAPI.On("GetConfig").Return(mattermostConfig)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		_, err := s.CreatePlaybookRun(playbookRun, nil, "testUserID", true)
 		require.Equal(t, err, app.ErrChannelDisplayNameInvalid)
@@ -81,7 +83,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 		pluginAPI.On("GetConfig").Return(mattermostConfig)
 		pluginAPI.On("CreateChannel", mock.Anything).Return(nil, &model.AppError{Id: "model.channel.is_valid.2_or_more.app_error"})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		_, err := s.CreatePlaybookRun(playbookRun, nil, "testUserID", true)
 		require.Equal(t, err, app.ErrChannelDisplayNameInvalid)
@@ -138,7 +140,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 			Return(&model.Post{Id: "testPostId"}, nil)
 		store.EXPECT().SetBroadcastChannelIDsToRootID(playbookRunWithID.ID, map[string]string{"channel_id": "testPostId"}).Return(nil)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		_, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
 		require.NoError(t, err)
@@ -169,7 +171,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 		pluginAPI.On("GetConfig").Return(mattermostConfig)
 		pluginAPI.On("CreateChannel", mock.Anything).Return(nil, &model.AppError{Id: "store.sql_channel.save_channel.exists.app_error"})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		_, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
 		require.EqualError(t, err, "failed to create channel: : , ")
@@ -219,7 +221,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 			Return(&model.Post{Id: "testPostId"}, nil)
 		store.EXPECT().SetBroadcastChannelIDsToRootID(playbookRunWithID.ID, map[string]string{"channel_id": "testPostId"}).Return(nil)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		_, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
 		require.NoError(t, err)
@@ -270,7 +272,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 			Return(&model.Post{Id: "testPostId"}, nil)
 		store.EXPECT().SetBroadcastChannelIDsToRootID(playbookRunWithID.ID, map[string]string{"channel_id": "testPostId"}).Return(nil)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		_, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
 		pluginAPI.AssertExpectations(t)
@@ -350,7 +352,7 @@ func TestCreatePlaybookRun(t *testing.T) {
 		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "ad-1"}, nil)
 		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{Id: "channel_id", Name: "channel-name"}, nil)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		createdPlaybookRun, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
 		require.NoError(t, err)
@@ -473,7 +475,7 @@ func TestUpdateStatus(t *testing.T) {
 			},
 		})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		err := s.UpdateStatus(playbookRun.ID, "user_id", statusUpdateOptions)
 		require.NoError(t, err)
@@ -584,7 +586,7 @@ func TestUpdateStatusWebhookFailure(t *testing.T) {
 	pluginAPI.On("LogWarn", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	poster.EXPECT().PostMessage(homeChannelID, gomock.Any()).Return(nil, nil)
 
-	s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+	s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 	err := s.UpdateStatus(playbookRun.ID, "user_id", statusUpdateOptions)
 	require.NoError(t, err)
@@ -713,7 +715,7 @@ func TestOpenCreatePlaybookRunDialog(t *testing.T) {
 			telemetryService := &telemetry.NoopTelemetry{}
 			scheduler := mock_app.NewMockJobOnceScheduler(controller)
 			tt.prepMocks(t, store, poster, api, configService)
-			service := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, api)
+			service := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, api)
 
 			err := service.OpenCreatePlaybookRunDialog(tt.args.teamID, tt.args.ownerID, tt.args.triggerID, tt.args.postID, tt.args.clientID, tt.args.playbooks, tt.args.isMobileApp)
 			if (err != nil) != tt.wantErr {
@@ -765,7 +767,7 @@ func TestUserHasJoinedChannel(t *testing.T) {
 			).Return(sidebarCategories, nil)
 			pluginAPI.On("GetConfig").Return(&model.Config{})
 
-			s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+			s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 			userID := "user_id"
 			channelID := "channel_id"
@@ -825,7 +827,7 @@ func TestUserHasJoinedChannel(t *testing.T) {
 		).Return(newSidebarCategory, nil)
 		pluginAPI.On("GetConfig").Return(&model.Config{})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		userID := "user_id"
 		channelID := "channel_id"
@@ -874,7 +876,7 @@ func TestUserHasJoinedChannel(t *testing.T) {
 			).Return(sidebarCategories, nil)
 			pluginAPI.On("GetConfig").Return(&model.Config{})
 
-			s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+			s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 			userID := "user_id"
 			channelID := "channel_id"
@@ -934,7 +936,7 @@ func TestUserHasJoinedChannel(t *testing.T) {
 		).Return(newSidebarCategory, nil)
 		pluginAPI.On("GetConfig").Return(&model.Config{})
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		userID := "user_id"
 		channelID := "channel_id"
@@ -1021,7 +1023,7 @@ func TestMultipleWebhooks(t *testing.T) {
 		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "ad-1"}, nil)
 		pluginAPI.On("GetChannel", mock.Anything).Return(&model.Channel{Id: "channel_id", Name: "channel-name"}, nil)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		createdPlaybookRun, err := s.CreatePlaybookRun(playbookRun, nil, "user_id", true)
 		require.NoError(t, err)
@@ -1148,7 +1150,7 @@ func TestMultipleWebhooks(t *testing.T) {
 		pluginAPI.On("GetTeam", teamID).Return(&model.Team{Id: teamID, Name: "team_name"}, nil)
 		pluginAPI.On("GetUser", "user_id").Return(&model.User{}, nil)
 
-		s := app.NewPlaybookRunService(client, store, poster, logger, configService, scheduler, telemetryService, pluginAPI)
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, logger, configService, scheduler, telemetryService, &metrics.NoopMetrics{}, pluginAPI)
 
 		err := s.UpdateStatus(playbookRun.ID, "user_id", statusUpdateOptions)
 		require.NoError(t, err)
@@ -1170,3 +1172,99 @@ func TestMultipleWebhooks(t *testing.T) {
 		}
 	})
 }
+
+func TestFinishPlaybookRunWithRequiredItems(t *testing.T) {
+	playbookRun := &app.PlaybookRun{
+		ID:        "playbookRunID",
+		ChannelID: "channelID",
+		Checklists: []app.Checklist{{
+			Title: "Compliance",
+			Items: []app.ChecklistItem{
+				{Title: "Notify the regulator", Required: true},
+				{Title: "Update the status page"},
+			},
+		}},
+	}
+
+	setup := func(t *testing.T) (*app.PlaybookRunServiceImpl, *plugintest.API, *mock_app.MockPlaybookRunStore) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		pluginAPI.On("GetConfig").Return(mattermostConfig)
+
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), mock_bot.NewMockPoster(controller), mock_bot.NewMockLogger(controller), mock_config.NewMockService(controller), mock_app.NewMockJobOnceScheduler(controller), &telemetry.NoopTelemetry{}, &metrics.NoopMetrics{}, pluginAPI)
+		return s, pluginAPI, store
+	}
+
+	t.Run("open required items block finishing", func(t *testing.T) {
+		s, _, _ := setup(t)
+
+		err := s.FinishPlaybookRun(playbookRun.ID, "testUserID", false)
+		require.True(t, errors.Is(err, app.ErrRequiredItemsOpen))
+	})
+
+	t.Run("only system admins can override them", func(t *testing.T) {
+		s, pluginAPI, _ := setup(t)
+		pluginAPI.On("HasPermissionTo", "testUserID", model.PermissionManageSystem).Return(false)
+
+		err := s.FinishPlaybookRun(playbookRun.ID, "testUserID", true)
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+	})
+
+	t.Run("the override is recorded as soon as the run is finished", func(t *testing.T) {
+		s, pluginAPI, store := setup(t)
+		pluginAPI.On("HasPermissionTo", "adminUserID", model.PermissionManageSystem).Return(true)
+		pluginAPI.On("GetUser", "adminUserID").Return(nil, &model.AppError{Message: "user not found"})
+		store.EXPECT().FinishPlaybookRun(playbookRun.ID, gomock.Any()).Return(nil)
+		store.EXPECT().
+			CreateTimelineEvent(gomock.Any()).
+			DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+				require.Equal(t, app.RequiredItemsOverridden, event.EventType)
+				require.Equal(t, "adminUserID", event.SubjectUserID)
+				return event, nil
+			})
+
+		err := s.FinishPlaybookRun(playbookRun.ID, "adminUserID", true)
+		require.Error(t, err)
+	})
+}
+
+func TestUpdateCustomData(t *testing.T) {
+	playbookRun := &app.PlaybookRun{
+		ID:             "playbookRunID",
+		ChannelID:      "channelID",
+		PlaybookID:     "playbookID",
+		OwnerUserID:    "ownerUserID",
+		ParticipantIDs: []string{"ownerUserID", "participantUserID"},
+	}
+
+	setup := func(t *testing.T) (*app.PlaybookRunServiceImpl, *plugintest.API) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		store.EXPECT().GetPlaybookRun(playbookRun.ID).Return(playbookRun, nil)
+		playbookService := mock_app.NewMockPlaybookService(controller)
+		playbookService.EXPECT().GetRunPermissions(playbookRun.PlaybookID).Return(app.RunPermissionPolicy{
+			EditChecklists: app.RunPermissionRule{Allow: app.RunPermissionAllowParticipants},
+		}, nil)
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		pluginAPI.On("GetConfig").Return(mattermostConfig)
+
+		s := app.NewPlaybookRunService(client, store, playbookService, mock_bot.NewMockPoster(controller), mock_bot.NewMockLogger(controller), mock_config.NewMockService(controller), mock_app.NewMockJobOnceScheduler(controller), &telemetry.NoopTelemetry{}, &metrics.NoopMetrics{}, pluginAPI)
+		return s, pluginAPI
+	}
+
+	t.Run("the run permission policy decides who may change it", func(t *testing.T) {
+		s, pluginAPI := setup(t)
+		pluginAPI.On("HasPermissionTo", "channelMemberID", model.PermissionManageSystem).Return(false)
+
+		err := s.UpdateCustomData(playbookRun.ID, "channelMemberID", map[string]string{"project": "OPS"})
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+	})
+}
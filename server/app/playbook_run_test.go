@@ -27,6 +27,7 @@ func TestPlaybookRunFilterOptions_Clone(t *testing.T) {
 		ParticipantID: "participant_id",
 		SearchTerm:    "search_term",
 		PlaybookID:    "playbook_id",
+		PlaybookIDs:   []string{"playbook_id_1", "playbook_id_2"},
 	}
 	marshalledOptions, err := json.Marshal(options)
 	require.NoError(t, err)
@@ -42,6 +43,7 @@ func TestPlaybookRunFilterOptions_Clone(t *testing.T) {
 	clone.ParticipantID = "participant_id_clone"
 	clone.SearchTerm = "search_term_clone"
 	clone.PlaybookID = "playbook_id_clone"
+	clone.PlaybookIDs[0] = "playbook_id_clone"
 
 	var unmarshalledOptions PlaybookRunFilterOptions
 	err = json.Unmarshal(marshalledOptions, &unmarshalledOptions)
@@ -167,6 +169,51 @@ func TestPlaybookRunFilterOptions_Validate(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("invalid playbook ids", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			TeamID:      model.NewId(),
+			PlaybookIDs: []string{model.NewId(), "invalid"},
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid reporter id", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			TeamID:     model.NewId(),
+			ReporterID: "invalid",
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("invalid assignee id", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			TeamID:     model.NewId(),
+			AssigneeID: "invalid",
+		}
+
+		_, err := options.Validate()
+		require.Error(t, err)
+	})
+
+	t.Run("negative finished times and open tasks", func(t *testing.T) {
+		options := PlaybookRunFilterOptions{
+			TeamID:       model.NewId(),
+			FinishedGTE:  -1,
+			FinishedLT:   -1,
+			OpenTasksGTE: -1,
+		}
+
+		validOptions, err := options.Validate()
+		require.NoError(t, err)
+		require.Zero(t, validOptions.FinishedGTE)
+		require.Zero(t, validOptions.FinishedLT)
+		require.Zero(t, validOptions.OpenTasksGTE)
+	})
+
 	t.Run("invalid statuses", func(t *testing.T) {
 		options := PlaybookRunFilterOptions{
 			TeamID:        model.NewId(),
@@ -187,16 +234,23 @@ func TestPlaybookRunFilterOptions_Validate(t *testing.T) {
 
 	t.Run("valid status", func(t *testing.T) {
 		options := PlaybookRunFilterOptions{
-			TeamID:        model.NewId(),
-			Page:          1,
-			PerPage:       10,
-			Sort:          SortByID,
-			Direction:     DirectionAsc,
-			Statuses:      []string{"InProgress", "Finished"},
-			OwnerID:       model.NewId(),
-			ParticipantID: model.NewId(),
-			SearchTerm:    "search_term",
-			PlaybookID:    model.NewId(),
+			TeamID:              model.NewId(),
+			Page:                1,
+			PerPage:             10,
+			Sort:                SortByID,
+			Direction:           DirectionAsc,
+			Statuses:            []string{"InProgress", "Finished", "Mitigated"},
+			OwnerID:             model.NewId(),
+			ParticipantID:       model.NewId(),
+			SearchTerm:          "search_term",
+			PlaybookID:          model.NewId(),
+			PlaybookIDs:         []string{model.NewId(), model.NewId()},
+			ReporterID:          model.NewId(),
+			AssigneeID:          model.NewId(),
+			StatusUpdateOverdue: true,
+			OpenTasksGTE:        3,
+			FinishedGTE:         1000,
+			FinishedLT:          2000,
 		}
 
 		validOptions, err := options.Validate()
@@ -13,34 +13,85 @@ import (
 )
 
 const (
-	playbookCreatedWSEvent = "playbook_created"
-	playbookDeletedWSEvent = "playbook_deleted"
+	playbookCreatedWSEvent  = "playbook_created"
+	playbookDeletedWSEvent  = "playbook_deleted"
+	playbookRestoredWSEvent = "playbook_restored"
 )
 
 type playbookService struct {
-	store                 PlaybookStore
-	poster                bot.Poster
-	keywordsCacher        KeywordsCacher
-	keywordsThreadIgnorer KeywordsThreadIgnorer
-	telemetry             PlaybookTelemetry
-	api                   *pluginapi.Client
-	configService         config.Service
+	store           PlaybookStore
+	poster          bot.Poster
+	keywordsCacher  KeywordsCacher
+	keywordsIgnorer KeywordsIgnorer
+	telemetry       PlaybookTelemetry
+	api             *pluginapi.Client
+	configService   config.Service
+
+	checklistLibrary ChecklistLibraryService
 }
 
 // NewPlaybookService returns a new playbook service
-func NewPlaybookService(store PlaybookStore, poster bot.Poster, telemetry PlaybookTelemetry, api *pluginapi.Client, configService config.Service, keywordsThreadIgnorer KeywordsThreadIgnorer) PlaybookService {
+func NewPlaybookService(store PlaybookStore, poster bot.Poster, telemetry PlaybookTelemetry, api *pluginapi.Client, configService config.Service, keywordsCacher KeywordsCacher, keywordsIgnorer KeywordsIgnorer, checklistLibrary ChecklistLibraryService) PlaybookService {
 	return &playbookService{
-		store:                 store,
-		poster:                poster,
-		keywordsCacher:        NewPlaybookKeywordsCacher(store, api.Log),
-		keywordsThreadIgnorer: keywordsThreadIgnorer,
-		telemetry:             telemetry,
-		api:                   api,
-		configService:         configService,
+		store:           store,
+		poster:          poster,
+		keywordsCacher:  keywordsCacher,
+		keywordsIgnorer: keywordsIgnorer,
+		telemetry:       telemetry,
+		api:             api,
+		configService:   configService,
+
+		checklistLibrary: checklistLibrary,
 	}
 }
 
+// expandLibraryChecklists validates the references of the playbook to the checklist library of
+// its team, and refreshes the title and items of the referencing checklists. The checklists
+// edited since their expansion stop referencing the library and keep their edits.
+func (s *playbookService) expandLibraryChecklists(playbook *Playbook) error {
+	if !HasLibraryChecklists(playbook.Checklists) {
+		return nil
+	}
+
+	if err := s.checklistLibrary.ValidateReferences(playbook.TeamID, playbook.Checklists); err != nil {
+		return err
+	}
+
+	checklists, err := s.checklistLibrary.ExpandChecklists(playbook.TeamID, playbook.Checklists)
+	if err != nil {
+		return err
+	}
+
+	UnlinkEditedLibraryChecklists(playbook.Checklists, checklists)
+	for i, checklist := range playbook.Checklists {
+		if checklist.LibraryID != "" {
+			playbook.Checklists[i] = checklists[i]
+		}
+	}
+
+	return nil
+}
+
+// validate returns an InvalidPlaybookError if the issues with the content of the playbook
+// prevent saving it.
+func (s *playbookService) validate(playbook Playbook) error {
+	cfg := s.configService.GetConfiguration()
+	issues := LintPlaybook(playbook, cfg.BotUserID, s.api)
+	if PlaybookLintBlocks(issues, cfg.StrictPlaybookValidation) {
+		return &InvalidPlaybookError{Issues: issues}
+	}
+	return nil
+}
+
 func (s *playbookService) Create(playbook Playbook, userID string) (string, error) {
+	if err := s.expandLibraryChecklists(&playbook); err != nil {
+		return "", err
+	}
+
+	if err := s.validate(playbook); err != nil {
+		return "", err
+	}
+
 	playbook.CreateAt = model.GetMillis()
 	playbook.UpdateAt = playbook.CreateAt
 
@@ -50,6 +101,8 @@ func (s *playbookService) Create(playbook Playbook, userID string) (string, erro
 	}
 	playbook.ID = newID
 
+	s.keywordsCacher.Invalidate()
+
 	s.telemetry.CreatePlaybook(playbook, userID)
 
 	s.poster.PublishWebsocketEventToTeam(playbookCreatedWSEvent, map[string]interface{}{
@@ -60,7 +113,20 @@ func (s *playbookService) Create(playbook Playbook, userID string) (string, erro
 }
 
 func (s *playbookService) Get(id string) (Playbook, error) {
-	return s.store.Get(id)
+	playbook, err := s.store.Get(id)
+	if err != nil {
+		return Playbook{}, err
+	}
+
+	// The checklists taken from the library follow its changes.
+	if HasLibraryChecklists(playbook.Checklists) {
+		playbook.Checklists, err = s.checklistLibrary.ExpandChecklists(playbook.TeamID, playbook.Checklists)
+		if err != nil {
+			return Playbook{}, errors.Wrapf(err, "failed to expand the library checklists of playbook %s", id)
+		}
+	}
+
+	return playbook, nil
 }
 
 func (s *playbookService) GetPlaybooks() ([]Playbook, error) {
@@ -75,13 +141,31 @@ func (s *playbookService) GetNumPlaybooksForTeam(teamID string) (int, error) {
 	return s.store.GetNumPlaybooksForTeam(teamID)
 }
 
+func (s *playbookService) GetPlaybookIDsReferencingLibrary(entryID string) ([]string, error) {
+	return s.store.GetPlaybookIDsReferencingLibrary(entryID)
+}
+
+func (s *playbookService) GetRunPermissions(id string) (RunPermissionPolicy, error) {
+	return s.store.GetRunPermissions(id)
+}
+
 func (s *playbookService) Update(playbook Playbook, userID string) error {
+	if err := s.expandLibraryChecklists(&playbook); err != nil {
+		return err
+	}
+
+	if err := s.validate(playbook); err != nil {
+		return err
+	}
+
 	playbook.UpdateAt = model.GetMillis()
 
 	if err := s.store.Update(playbook); err != nil {
 		return err
 	}
 
+	s.keywordsCacher.Invalidate()
+
 	s.telemetry.UpdatePlaybook(playbook, userID)
 
 	return nil
@@ -96,6 +180,8 @@ func (s *playbookService) Delete(playbook Playbook, userID string) error {
 		return err
 	}
 
+	s.keywordsCacher.Invalidate()
+
 	s.telemetry.DeletePlaybook(playbook, userID)
 
 	s.poster.PublishWebsocketEventToTeam(playbookDeletedWSEvent, map[string]interface{}{
@@ -105,8 +191,46 @@ func (s *playbookService) Delete(playbook Playbook, userID string) error {
 	return nil
 }
 
+func (s *playbookService) Restore(playbook Playbook, userID string) error {
+	if playbook.ID == "" {
+		return errors.New("can't restore a playbook without an ID")
+	}
+
+	if err := s.store.Restore(playbook.ID); err != nil {
+		return err
+	}
+
+	s.keywordsCacher.Invalidate()
+
+	s.telemetry.RestorePlaybook(playbook, userID)
+
+	s.poster.PublishWebsocketEventToTeam(playbookRestoredWSEvent, map[string]interface{}{
+		"teamID": playbook.TeamID,
+	}, playbook.TeamID)
+
+	return nil
+}
+
+func (s *playbookService) Purge(playbook Playbook, userID string) error {
+	if playbook.ID == "" {
+		return errors.New("can't purge a playbook without an ID")
+	}
+
+	if playbook.DeleteAt == 0 {
+		return errors.New("can't purge a playbook that is not archived")
+	}
+
+	if err := s.store.Purge(playbook.ID); err != nil {
+		return err
+	}
+
+	s.telemetry.PurgePlaybook(playbook, userID)
+
+	return nil
+}
+
 func (s *playbookService) MessageHasBeenPosted(sessionID string, post *model.Post) {
-	if post.IsSystemMessage() || s.keywordsThreadIgnorer.IsIgnored(post.RootId, post.UserId) {
+	if post.IsSystemMessage() {
 		return
 	}
 
@@ -122,6 +246,16 @@ func (s *playbookService) MessageHasBeenPosted(sessionID string, post *model.Pos
 		return
 	}
 
+	// Only consult the ignore rules once a keyword matched, to keep the store out of the hot path.
+	ignored, err := s.keywordsIgnorer.IsIgnored(post.UserId, post.ChannelId, post.RootId)
+	if err != nil {
+		s.api.Log.Error("can't check keywords ignore rules", "err", err.Error())
+		return
+	}
+	if ignored {
+		return
+	}
+
 	session, err := s.api.Session.Get(sessionID)
 	if err != nil {
 		s.api.Log.Error("can't get session", "sessionID", sessionID, "err", err.Error())
@@ -231,30 +365,8 @@ func (s *playbookService) getPlaybookSuggestionsSlackAttachment(playbooks []*Cac
 	return attachment
 }
 
-type cachedPlaybookTriggers struct {
-	playbook *CachedPlaybook
-	triggers []string
-}
-
 func (s *playbookService) GetSuggestedPlaybooks(teamID, userID, message string) ([]*CachedPlaybook, []string) {
-	triggeredPlaybooks := []cachedPlaybookTriggers{}
-
-	playbooks := s.keywordsCacher.GetPlaybooks()
-	for i := range playbooks {
-		if playbooks[i].TeamID != teamID {
-			continue
-		}
-
-		triggers := getPlaybookTriggersForAMessage(playbooks[i], message)
-		if len(triggers) == 0 {
-			continue
-		}
-
-		triggeredPlaybooks = append(triggeredPlaybooks, cachedPlaybookTriggers{
-			playbook: playbooks[i],
-			triggers: triggers,
-		})
-	}
+	triggeredPlaybooks := s.keywordsCacher.Match(teamID, message)
 
 	// return early if no triggered playbooks
 	if len(triggeredPlaybooks) == 0 {
@@ -265,7 +377,7 @@ func (s *playbookService) GetSuggestedPlaybooks(teamID, userID, message string)
 }
 
 // filters out playbooks user has no access to and returns playbooks with
-func (s *playbookService) getPlaybooksAndTriggersByAccess(triggeredPlaybooks []cachedPlaybookTriggers, userID, teamID string) ([]*CachedPlaybook, []string) {
+func (s *playbookService) getPlaybooksAndTriggersByAccess(triggeredPlaybooks []KeywordsMatch, userID, teamID string) ([]*CachedPlaybook, []string) {
 	resultPlaybooks := []*CachedPlaybook{}
 	resultTriggers := []string{}
 
@@ -278,25 +390,15 @@ func (s *playbookService) getPlaybooksAndTriggersByAccess(triggeredPlaybooks []c
 	playbookIDsMap := sliceToMap(playbookIDs)
 
 	for i := range triggeredPlaybooks {
-		if ok := playbookIDsMap[triggeredPlaybooks[i].playbook.ID]; ok {
-			resultPlaybooks = append(resultPlaybooks, triggeredPlaybooks[i].playbook)
-			resultTriggers = append(resultTriggers, triggeredPlaybooks[i].triggers...)
+		if ok := playbookIDsMap[triggeredPlaybooks[i].Playbook.ID]; ok {
+			resultPlaybooks = append(resultPlaybooks, triggeredPlaybooks[i].Playbook)
+			resultTriggers = append(resultTriggers, triggeredPlaybooks[i].Triggers...)
 		}
 	}
 
 	return resultPlaybooks, removeDuplicates(resultTriggers)
 }
 
-func getPlaybookTriggersForAMessage(playbook *CachedPlaybook, message string) []string {
-	triggers := []string{}
-	for _, keyword := range playbook.SignalAnyKeywords {
-		if strings.Contains(message, keyword) {
-			triggers = append(triggers, keyword)
-		}
-	}
-	return removeDuplicates(triggers)
-}
-
 func sliceToMap(strs []string) map[string]bool {
 	res := make(map[string]bool, len(strs))
 	for _, s := range strs {
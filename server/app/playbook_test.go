@@ -81,6 +81,299 @@ func TestPlaybook_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestPlaybook_MemberRole(t *testing.T) {
+	playbook := Playbook{
+		Members: []PlaybookMember{
+			{UserID: "viewer", Role: PlaybookRoleViewer},
+			{UserID: "runner", Role: PlaybookRoleRunner},
+			{UserID: "editor", Role: PlaybookRoleEditor},
+			{UserID: "admin", Role: PlaybookRoleAdmin},
+		},
+	}
+
+	t.Run("members", func(t *testing.T) {
+		require.Equal(t, PlaybookRoleRunner, playbook.MemberRole("runner"))
+		require.Equal(t, "", playbook.MemberRole("stranger"))
+
+		require.True(t, playbook.HasRole("runner", PlaybookRoleViewer))
+		require.True(t, playbook.HasRole("runner", PlaybookRoleRunner))
+		require.False(t, playbook.HasRole("runner", PlaybookRoleEditor))
+		require.True(t, playbook.HasRole("admin", PlaybookRoleEditor))
+		require.False(t, playbook.HasRole("stranger", PlaybookRoleViewer))
+	})
+
+	t.Run("without members, everyone is an editor", func(t *testing.T) {
+		require.Equal(t, PlaybookRoleEditor, Playbook{}.MemberRole("stranger"))
+		require.True(t, Playbook{}.HasRole("stranger", PlaybookRoleEditor))
+		require.False(t, Playbook{}.HasRole("stranger", PlaybookRoleAdmin))
+	})
+
+	t.Run("member ids only, members are editors", func(t *testing.T) {
+		legacy := Playbook{MemberIDs: []string{"bob"}}
+		require.Equal(t, PlaybookRoleEditor, legacy.MemberRole("bob"))
+		require.Equal(t, "", legacy.MemberRole("stranger"))
+	})
+}
+
+func TestPlaybook_NormalizeMembers(t *testing.T) {
+	tests := []struct {
+		name            string
+		playbook        Playbook
+		expectedMembers []PlaybookMember
+	}{
+		{
+			name:            "no members",
+			playbook:        Playbook{},
+			expectedMembers: []PlaybookMember{},
+		},
+		{
+			name:            "member ids only become editors",
+			playbook:        Playbook{MemberIDs: []string{"bob", "divyani"}},
+			expectedMembers: []PlaybookMember{{"bob", PlaybookRoleEditor}, {"divyani", PlaybookRoleEditor}},
+		},
+		{
+			name: "members without member ids keep their roles, duplicates and empty roles are fixed",
+			playbook: Playbook{Members: []PlaybookMember{
+				{"bob", PlaybookRoleViewer},
+				{"divyani", ""},
+				{"bob", PlaybookRoleAdmin},
+			}},
+			expectedMembers: []PlaybookMember{{"bob", PlaybookRoleViewer}, {"divyani", PlaybookRoleEditor}},
+		},
+		{
+			name: "member ids add and remove members",
+			playbook: Playbook{
+				Members:   []PlaybookMember{{"bob", PlaybookRoleAdmin}, {"divyani", PlaybookRoleRunner}},
+				MemberIDs: []string{"bob", "alice"},
+			},
+			expectedMembers: []PlaybookMember{{"bob", PlaybookRoleAdmin}, {"alice", PlaybookRoleEditor}},
+		},
+		{
+			name: "empty member ids remove all the members",
+			playbook: Playbook{
+				Members:   []PlaybookMember{{"bob", PlaybookRoleAdmin}},
+				MemberIDs: []string{},
+			},
+			expectedMembers: []PlaybookMember{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			playbook := tt.playbook
+			playbook.NormalizeMembers()
+			require.Equal(t, tt.expectedMembers, playbook.Members)
+
+			expectedMemberIDs := []string{}
+			for _, member := range tt.expectedMembers {
+				expectedMemberIDs = append(expectedMemberIDs, member.UserID)
+			}
+			require.Equal(t, expectedMemberIDs, playbook.MemberIDs)
+		})
+	}
+}
+
+func TestPlaybook_NormalizeMemberGroups(t *testing.T) {
+	playbook := Playbook{MemberGroups: []PlaybookMemberGroup{
+		{PlaybookMemberGroupTypeGroup, "sre", PlaybookRoleRunner},
+		{PlaybookMemberGroupTypeChannel, "sre", PlaybookRoleViewer},
+		{PlaybookMemberGroupTypeGroup, "sre", PlaybookRoleAdmin},
+	}}
+	playbook.NormalizeMembers()
+	require.Equal(t, []PlaybookMemberGroup{
+		{PlaybookMemberGroupTypeGroup, "sre", PlaybookRoleRunner},
+		{PlaybookMemberGroupTypeChannel, "sre", PlaybookRoleViewer},
+	}, playbook.MemberGroups)
+	require.False(t, playbook.IsOpen())
+
+	empty := Playbook{}
+	empty.NormalizeMembers()
+	require.Equal(t, []PlaybookMemberGroup{}, empty.MemberGroups)
+	require.True(t, empty.IsOpen())
+}
+
+func TestPlaybook_DefaultAdmin(t *testing.T) {
+	open := Playbook{}
+	open.DefaultAdmin("creator")
+	require.True(t, open.IsOpen())
+
+	member := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleEditor}, {"creator", PlaybookRoleRunner}}, MemberIDs: []string{"bob", "creator"}}
+	member.DefaultAdmin("creator")
+	require.Equal(t, []PlaybookMember{{"bob", PlaybookRoleEditor}, {"creator", PlaybookRoleAdmin}}, member.Members)
+
+	notMember := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleEditor}}, MemberIDs: []string{"bob"}}
+	notMember.DefaultAdmin("creator")
+	require.Equal(t, []PlaybookMember{{"bob", PlaybookRoleEditor}, {"creator", PlaybookRoleAdmin}}, notMember.Members)
+	require.Equal(t, []string{"bob", "creator"}, notMember.MemberIDs)
+
+	withAdmin := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleAdmin}}}
+	withAdmin.DefaultAdmin("creator")
+	require.Equal(t, []PlaybookMember{{"bob", PlaybookRoleAdmin}}, withAdmin.Members)
+}
+
+func TestPlaybook_KeepMemberRoles(t *testing.T) {
+	oldPlaybook := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleAdmin}, {"alice", PlaybookRoleViewer}}}
+
+	legacy := Playbook{MemberIDs: []string{"bob", "carol"}}
+	legacy.KeepMemberRoles(oldPlaybook)
+	legacy.NormalizeMembers()
+	require.Equal(t, []PlaybookMember{{"bob", PlaybookRoleAdmin}, {"carol", PlaybookRoleEditor}}, legacy.Members)
+
+	withRoles := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleRunner}, {"alice", ""}}}
+	withRoles.KeepMemberRoles(oldPlaybook)
+	withRoles.NormalizeMembers()
+	require.Equal(t, []PlaybookMember{{"bob", PlaybookRoleRunner}, {"alice", PlaybookRoleViewer}}, withRoles.Members)
+}
+
+func TestPlaybook_ValidateMembers(t *testing.T) {
+	require.NoError(t, Playbook{}.ValidateMembers())
+	require.NoError(t, Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleRunner}, {"alice", PlaybookRoleAdmin}}}.ValidateMembers())
+	require.Error(t, Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleRunner}}}.ValidateMembers())
+	require.Error(t, Playbook{Members: []PlaybookMember{{"", PlaybookRoleAdmin}}}.ValidateMembers())
+	require.Error(t, Playbook{Members: []PlaybookMember{{"bob", "owner"}}}.ValidateMembers())
+
+	require.NoError(t, Playbook{MemberGroups: []PlaybookMemberGroup{{PlaybookMemberGroupTypeChannel, "sre", PlaybookRoleAdmin}}}.ValidateMembers())
+	require.Error(t, Playbook{MemberGroups: []PlaybookMemberGroup{{PlaybookMemberGroupTypeChannel, "sre", PlaybookRoleViewer}}}.ValidateMembers())
+	require.Error(t, Playbook{MemberGroups: []PlaybookMemberGroup{{"team", "sre", PlaybookRoleViewer}}}.ValidateMembers())
+	require.Error(t, Playbook{MemberGroups: []PlaybookMemberGroup{{PlaybookMemberGroupTypeGroup, "", PlaybookRoleViewer}}}.ValidateMembers())
+	require.Error(t, Playbook{MemberGroups: []PlaybookMemberGroup{{PlaybookMemberGroupTypeGroup, "sre", ""}}}.ValidateMembers())
+}
+
+func TestPlaybook_ValidateMemberChanges(t *testing.T) {
+	editors := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleEditor}}}
+	withAdmin := Playbook{Members: []PlaybookMember{{"bob", PlaybookRoleAdmin}}}
+
+	require.NoError(t, editors.ValidateMemberChanges(editors))
+	require.Error(t, editors.ValidateMemberChanges(withAdmin))
+	require.Error(t, editors.ValidateMemberChanges(Playbook{}))
+	require.NoError(t, withAdmin.ValidateMemberChanges(Playbook{}))
+	require.Error(t, Playbook{Members: []PlaybookMember{{"bob", "owner"}}}.ValidateMemberChanges(editors))
+}
+
+func TestPlaybook_NormalizeSharedTeams(t *testing.T) {
+	playbook := Playbook{TeamID: "home", SharedTeamIDs: []string{"sre", "home", "ops", "sre"}}
+	playbook.NormalizeSharedTeams()
+	require.Equal(t, []string{"sre", "ops"}, playbook.SharedTeamIDs)
+
+	homeOnly := Playbook{TeamID: "home", SharedTeamIDs: []string{"home"}}
+	homeOnly.NormalizeSharedTeams()
+	require.Nil(t, homeOnly.SharedTeamIDs)
+
+	allTeams := Playbook{TeamID: "home", SharedTeamIDs: []string{"sre"}, SharedWithAllTeams: true}
+	allTeams.NormalizeSharedTeams()
+	require.Nil(t, allTeams.SharedTeamIDs)
+}
+
+func TestPlaybook_IsSharedWithTeam(t *testing.T) {
+	playbook := Playbook{TeamID: "home", SharedTeamIDs: []string{"sre"}}
+	require.True(t, playbook.IsSharedWithTeam("home"))
+	require.True(t, playbook.IsSharedWithTeam("sre"))
+	require.False(t, playbook.IsSharedWithTeam("ops"))
+
+	playbook.SharedWithAllTeams = true
+	require.True(t, playbook.IsSharedWithTeam("ops"))
+}
+
+func TestPlaybook_Duplicate(t *testing.T) {
+	playbook := Playbook{
+		ID:                       "playbookid",
+		Title:                    "Incident",
+		TeamID:                   "home",
+		SharedTeamIDs:            []string{"sre"},
+		CreateAt:                 100,
+		NumRuns:                  3,
+		Checklists:               []Checklist{{Title: "Triage", Items: []ChecklistItem{{Title: "Page"}}}},
+		Members:                  []PlaybookMember{{"bob", PlaybookRoleAdmin}, {"alice", PlaybookRoleViewer}},
+		MemberIDs:                []string{"bob", "alice"},
+		MemberGroups:             []PlaybookMemberGroup{{PlaybookMemberGroupTypeGroup, "sre", PlaybookRoleRunner}, {PlaybookMemberGroupTypeChannel, "ops", PlaybookRoleViewer}},
+		BroadcastChannelIDs:      []string{"broadcast"},
+		BroadcastEnabled:         true,
+		DefaultOwnerID:           "bob",
+		DefaultOwnerEnabled:      true,
+		CategoryName:             "Incidents",
+		CategorizeChannelEnabled: true,
+		InvitedUserIDs:           []string{"bob"},
+		InvitedGroupIDs:          []string{"oncall"},
+		InviteUsersEnabled:       true,
+	}
+
+	t.Run("in the same team", func(t *testing.T) {
+		duplicate := playbook.Duplicate("alice", "", "")
+
+		require.Empty(t, duplicate.ID)
+		require.Zero(t, duplicate.CreateAt)
+		require.Zero(t, duplicate.NumRuns)
+		require.Equal(t, "Copy of Incident", duplicate.Title)
+		require.Equal(t, "home", duplicate.TeamID)
+		require.Nil(t, duplicate.SharedTeamIDs)
+		require.Equal(t, playbook.Checklists, duplicate.Checklists)
+		require.Equal(t, []PlaybookMember{{"alice", PlaybookRoleAdmin}, {"bob", PlaybookRoleAdmin}}, duplicate.Members)
+		require.Equal(t, []string{"alice", "bob"}, duplicate.MemberIDs)
+		require.Equal(t, playbook.MemberGroups, duplicate.MemberGroups)
+		require.Equal(t, []string{"broadcast"}, duplicate.BroadcastChannelIDs)
+		require.Equal(t, "bob", duplicate.DefaultOwnerID)
+		require.Equal(t, "Incidents", duplicate.CategoryName)
+		require.Equal(t, []string{"bob"}, duplicate.InvitedUserIDs)
+
+		duplicate.Checklists[0].Items[0].Title = "Changed"
+		require.Equal(t, "Page", playbook.Checklists[0].Items[0].Title)
+	})
+
+	t.Run("into another team", func(t *testing.T) {
+		duplicate := playbook.Duplicate("alice", "other", "Other incident")
+
+		require.Equal(t, "Other incident", duplicate.Title)
+		require.Equal(t, "other", duplicate.TeamID)
+		require.Equal(t, playbook.Checklists, duplicate.Checklists)
+		require.Equal(t, []PlaybookMember{{"alice", PlaybookRoleAdmin}}, duplicate.Members)
+		require.Equal(t, []PlaybookMemberGroup{{PlaybookMemberGroupTypeGroup, "sre", PlaybookRoleRunner}}, duplicate.MemberGroups)
+		require.Nil(t, duplicate.BroadcastChannelIDs)
+		require.False(t, duplicate.BroadcastEnabled)
+		require.Empty(t, duplicate.DefaultOwnerID)
+		require.False(t, duplicate.DefaultOwnerEnabled)
+		require.Empty(t, duplicate.CategoryName)
+		require.False(t, duplicate.CategorizeChannelEnabled)
+		require.Nil(t, duplicate.InvitedUserIDs)
+		require.Equal(t, []string{"oncall"}, duplicate.InvitedGroupIDs)
+	})
+
+	t.Run("an open playbook", func(t *testing.T) {
+		open := Playbook{Title: "Open", TeamID: "home"}
+
+		duplicate := open.Duplicate("alice", "", "")
+
+		require.True(t, duplicate.IsOpen())
+		require.Empty(t, duplicate.Members)
+		require.Empty(t, duplicate.MemberIDs)
+	})
+}
+
+func TestRunPermissionPolicy_Rule(t *testing.T) {
+	policy := RunPermissionPolicy{
+		Finish:         RunPermissionRule{Allow: RunPermissionAllowOwner},
+		EditChecklists: RunPermissionRule{Allow: RunPermissionAllowRole, Role: PlaybookRoleRunner},
+	}
+
+	require.Equal(t, RunPermissionRule{Allow: RunPermissionAllowOwner}, policy.Rule(RunActionFinish))
+	require.Equal(t, RunPermissionRule{Allow: RunPermissionAllowRole, Role: PlaybookRoleRunner}, policy.Rule(RunActionEditChecklists))
+	require.Equal(t, RunPermissionRule{Allow: RunPermissionAllowChannel}, policy.Rule(RunActionPostStatusUpdate))
+}
+
+func TestRunPermissionPolicy_Validate(t *testing.T) {
+	require.NoError(t, RunPermissionPolicy{}.Validate())
+	require.NoError(t, RunPermissionPolicy{
+		Finish:               RunPermissionRule{Allow: RunPermissionAllowOwner},
+		ChangeOwner:          RunPermissionRule{Allow: RunPermissionAllowParticipants},
+		EditChecklists:       RunPermissionRule{Allow: RunPermissionAllowRole, Role: PlaybookRoleEditor},
+		PostStatusUpdate:     RunPermissionRule{Allow: RunPermissionAllowChannel},
+		PublishRetrospective: RunPermissionRule{},
+	}.Validate())
+
+	require.Error(t, RunPermissionPolicy{Finish: RunPermissionRule{Allow: "everyone"}}.Validate())
+	require.Error(t, RunPermissionPolicy{ChangeOwner: RunPermissionRule{Allow: RunPermissionAllowRole}}.Validate())
+	require.Error(t, RunPermissionPolicy{ChangeOwner: RunPermissionRule{Allow: RunPermissionAllowRole, Role: "owner"}}.Validate())
+	require.Error(t, RunPermissionPolicy{PostStatusUpdate: RunPermissionRule{Allow: RunPermissionAllowOwner, Role: PlaybookRoleAdmin}}.Validate())
+}
+
 func TestPlaybookFilterOptions_Clone(t *testing.T) {
 	options := PlaybookFilterOptions{
 		Page:      1,
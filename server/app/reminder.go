@@ -18,6 +18,8 @@ const RetrospectivePrefix = "retro_"
 func (s *PlaybookRunServiceImpl) HandleReminder(key string) {
 	if strings.HasPrefix(key, RetrospectivePrefix) {
 		s.handleReminderToFillRetro(strings.TrimPrefix(key, RetrospectivePrefix))
+	} else if strings.HasPrefix(key, AutoRunPrefix) {
+		s.handleChecklistItemAutoRun(strings.TrimPrefix(key, AutoRunPrefix))
 	} else {
 		s.handleStatusUpdateReminder(key)
 	}
@@ -120,6 +122,7 @@ func (s *PlaybookRunServiceImpl) SetReminder(playbookRunID string, fromNow time.
 	if _, err := s.scheduler.ScheduleOnce(playbookRunID, time.Now().Add(fromNow)); err != nil {
 		return errors.Wrap(err, "unable to schedule reminder")
 	}
+	s.metrics.IncrementRemindersScheduled()
 
 	return nil
 }
@@ -0,0 +1,211 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// DialogFieldRunStatusKey is the key for the run status select field used in UpdatePlaybookRunDialog
+const DialogFieldRunStatusKey = "run_status"
+
+// maxRunStatuses is the maximum number of run statuses of a playbook.
+const maxRunStatuses = 32
+
+// runStatusNameRegexp matches the names of the run statuses: a capital letter followed by up to
+// 63 letters, digits, spaces, dashes or underscores, e.g. Investigating.
+var runStatusNameRegexp = regexp.MustCompile(`^[A-Z][A-Za-z0-9 _-]{0,63}$`)
+
+// RunStatus is a stage the runs of a playbook go through, e.g. Investigating, Identified,
+// Mitigated and Resolved. Runs start in the first status of their playbook.
+type RunStatus struct {
+	// Name identifies the status. It can be used to filter runs, like InProgress and Finished.
+	Name string `json:"name"`
+
+	// Transitions are the names of the statuses a run can move to from this one. If empty, a run
+	// can only move to the next status in order.
+	Transitions []string `json:"transitions,omitempty"`
+
+	// Finished is true if moving a run to this status finishes it.
+	Finished bool `json:"finished"`
+}
+
+// RunStatusChange is the detail of a RunStatusChanged timeline event.
+type RunStatusChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// IsValidRunStatusName returns true if name can be the name of a run status. InProgress and
+// Finished are reserved.
+func IsValidRunStatusName(name string) bool {
+	return name != StatusInProgress && name != StatusFinished && runStatusNameRegexp.MatchString(name)
+}
+
+// CloneRunStatuses returns a deep copy of statuses.
+func CloneRunStatuses(statuses []RunStatus) []RunStatus {
+	if statuses == nil {
+		return nil
+	}
+
+	newStatuses := make([]RunStatus, 0, len(statuses))
+	for _, status := range statuses {
+		status.Transitions = append([]string(nil), status.Transitions...)
+		newStatuses = append(newStatuses, status)
+	}
+	return newStatuses
+}
+
+// findRunStatus returns the index of the status named name, -1 if there is none.
+func findRunStatus(statuses []RunStatus, name string) int {
+	for i, status := range statuses {
+		if status.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// AllowedRunStatusTransitions returns the names of the statuses a run in the status current can
+// move to. A run in an unknown status can only move to the first one.
+func AllowedRunStatusTransitions(statuses []RunStatus, current string) []string {
+	i := findRunStatus(statuses, current)
+	switch {
+	case len(statuses) == 0:
+		return nil
+	case i < 0:
+		return []string{statuses[0].Name}
+	case len(statuses[i].Transitions) > 0:
+		return statuses[i].Transitions
+	case i+1 < len(statuses):
+		return []string{statuses[i+1].Name}
+	}
+	return nil
+}
+
+// CanChangeRunStatus returns true if a run can move from the status from to the status to.
+func CanChangeRunStatus(statuses []RunStatus, from, to string) bool {
+	if findRunStatus(statuses, to) < 0 {
+		return false
+	}
+	for _, name := range AllowedRunStatusTransitions(statuses, from) {
+		if name == to {
+			return true
+		}
+	}
+	return false
+}
+
+// firstFinishedRunStatus returns the name of the first status finishing the runs, if any.
+func firstFinishedRunStatus(statuses []RunStatus) (string, bool) {
+	for _, status := range statuses {
+		if status.Finished {
+			return status.Name, true
+		}
+	}
+	return "", false
+}
+
+// isFinishedRunStatus returns true if the status named name finishes the runs.
+func isFinishedRunStatus(statuses []RunStatus, name string) bool {
+	i := findRunStatus(statuses, name)
+	return i >= 0 && statuses[i].Finished
+}
+
+// ChangeRunStatus moves the run to status, if allowed from its current status, finishing it if
+// status is a finished one.
+func (s *PlaybookRunServiceImpl) ChangeRunStatus(playbookRunID, userID, status string) error {
+	playbookRun, err := s.store.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return errors.Wrapf(err, "failed to retrieve playbook run")
+	}
+
+	if err = s.checkRunStatusChange(playbookRun, userID, status); err != nil {
+		return err
+	}
+	if status == playbookRun.CurrentRunStatus {
+		return nil
+	}
+
+	if err = s.recordRunStatusChange(playbookRun, userID, status); err != nil {
+		return err
+	}
+
+	if isFinishedRunStatus(playbookRun.RunStatuses, status) {
+		return s.FinishPlaybookRun(playbookRunID, userID, false)
+	}
+
+	return s.sendPlaybookRunToClient(playbookRunID)
+}
+
+// checkRunStatusChange returns an error if userID can't move the run to status. Moving it to a
+// finished status finishes it, so it takes the same permission and required items as finishing.
+func (s *PlaybookRunServiceImpl) checkRunStatusChange(playbookRun *PlaybookRun, userID, status string) error {
+	if playbookRun.CurrentStatus == StatusFinished {
+		return ErrPlaybookRunNotActive
+	}
+	if status == playbookRun.CurrentRunStatus {
+		return nil
+	}
+	if !CanChangeRunStatus(playbookRun.RunStatuses, playbookRun.CurrentRunStatus, status) {
+		return errors.Wrapf(ErrMalformedPlaybookRun, "the run can't move from status %q to status %q", playbookRun.CurrentRunStatus, status)
+	}
+
+	if !isFinishedRunStatus(playbookRun.RunStatuses, status) {
+		return nil
+	}
+
+	if err := RunActionAccess(userID, playbookRun, RunActionFinish, s.playbookService, s.pluginAPI); err != nil {
+		return errors.Wrapf(err, "move the run to the finished status %q", status)
+	}
+
+	// Check before moving the run to a finished status that it can actually be finished.
+	if openRequired := OpenRequiredItems(playbookRun.Checklists); len(openRequired) > 0 {
+		return errors.Wrapf(ErrRequiredItemsOpen, "%d required items are not done", len(openRequired))
+	}
+
+	return nil
+}
+
+// recordRunStatusChange moves the run to status, announcing it in the channel and the timeline.
+func (s *PlaybookRunServiceImpl) recordRunStatusChange(playbookRun *PlaybookRun, userID, status string) error {
+	change := RunStatusChange{From: playbookRun.CurrentRunStatus, To: status}
+	playbookRun.CurrentRunStatus = status
+	if err := s.store.UpdatePlaybookRun(playbookRun); err != nil {
+		return errors.Wrap(err, "failed to update playbook run status")
+	}
+
+	message := fmt.Sprintf("changed the status of this run to **%s**", status)
+	if change.From != "" {
+		message = fmt.Sprintf("changed the status of this run from **%s** to **%s**", change.From, status)
+	}
+	post, err := s.modificationMessage(userID, playbookRun.ChannelID, message)
+	if err != nil {
+		return err
+	}
+
+	details, err := json.Marshal(change)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal the run status change")
+	}
+
+	now := model.GetMillis()
+	event := &TimelineEvent{
+		PlaybookRunID: playbookRun.ID,
+		CreateAt:      now,
+		EventAt:       now,
+		EventType:     RunStatusChanged,
+		Summary:       fmt.Sprintf("changed the status to **%s**", status),
+		Details:       string(details),
+		PostID:        post.Id,
+		SubjectUserID: userID,
+	}
+	if _, err = s.store.CreateTimelineEvent(event); err != nil {
+		return errors.Wrap(err, "failed to create timeline event")
+	}
+
+	return nil
+}
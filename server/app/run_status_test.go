@@ -0,0 +1,170 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/telemetry"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
+	"github.com/mattermost/mattermost-server/v6/plugin/plugintest/mock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	mock_app "github.com/mattermost/mattermost-plugin-playbooks/server/app/mocks"
+	mock_bot "github.com/mattermost/mattermost-plugin-playbooks/server/bot/mocks"
+	mock_config "github.com/mattermost/mattermost-plugin-playbooks/server/config/mocks"
+
+	pluginapi "github.com/mattermost/mattermost-plugin-api"
+)
+
+var incidentRunStatuses = []app.RunStatus{
+	{Name: "Investigating", Transitions: []string{"Identified", "Mitigated"}},
+	{Name: "Identified"},
+	{Name: "Mitigated", Transitions: []string{"Investigating", "Resolved"}},
+	{Name: "Resolved", Finished: true},
+}
+
+func TestIsValidRunStatusName(t *testing.T) {
+	for _, name := range []string{"Investigating", "Waiting on customer", "Phase-2", "P1_Triage"} {
+		require.True(t, app.IsValidRunStatusName(name), name)
+	}
+	for _, name := range []string{"", "active", "InProgress", "Finished", " Padded", "Bad/Slash"} {
+		require.False(t, app.IsValidRunStatusName(name), name)
+	}
+}
+
+func TestRunStatusTransitions(t *testing.T) {
+	require.Equal(t, []string{"Identified", "Mitigated"}, app.AllowedRunStatusTransitions(incidentRunStatuses, "Investigating"))
+	require.Equal(t, []string{"Mitigated"}, app.AllowedRunStatusTransitions(incidentRunStatuses, "Identified"))
+	require.Empty(t, app.AllowedRunStatusTransitions(incidentRunStatuses, "Resolved"))
+	require.Equal(t, []string{"Investigating"}, app.AllowedRunStatusTransitions(incidentRunStatuses, ""))
+	require.Empty(t, app.AllowedRunStatusTransitions(nil, ""))
+
+	require.True(t, app.CanChangeRunStatus(incidentRunStatuses, "Mitigated", "Investigating"))
+	require.False(t, app.CanChangeRunStatus(incidentRunStatuses, "Investigating", "Resolved"))
+	require.False(t, app.CanChangeRunStatus(incidentRunStatuses, "Investigating", "Unknown"))
+}
+
+func TestChangeRunStatus(t *testing.T) {
+	channelID := model.NewId()
+
+	newRun := func() *app.PlaybookRun {
+		return &app.PlaybookRun{
+			ID:               model.NewId(),
+			TeamID:           model.NewId(),
+			ChannelID:        channelID,
+			CurrentStatus:    app.StatusInProgress,
+			RunStatuses:      app.CloneRunStatuses(incidentRunStatuses),
+			CurrentRunStatus: "Investigating",
+			Checklists: []app.Checklist{{
+				Title: "Compliance",
+				Items: []app.ChecklistItem{{Title: "Notify the regulator", Required: true}},
+			}},
+		}
+	}
+
+	// setup returns the service over a store holding current, recording the timeline events.
+	setup := func(t *testing.T, current **app.PlaybookRun) (app.PlaybookRunService, *[]app.TimelineEvent) {
+		controller := gomock.NewController(t)
+		pluginAPI := &plugintest.API{}
+		client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+		store := mock_app.NewMockPlaybookRunStore(controller)
+		poster := mock_bot.NewMockPoster(controller)
+		configService := mock_config.NewMockService(controller)
+		scheduler := mock_app.NewMockJobOnceScheduler(controller)
+
+		var events []app.TimelineEvent
+
+		store.EXPECT().GetPlaybookRun((*current).ID).DoAndReturn(func(string) (*app.PlaybookRun, error) {
+			return (*current).Clone(), nil
+		}).AnyTimes()
+		store.EXPECT().UpdatePlaybookRun(gomock.Any()).DoAndReturn(func(playbookRun *app.PlaybookRun) error {
+			*current = playbookRun.Clone()
+			return nil
+		}).AnyTimes()
+		store.EXPECT().FinishPlaybookRun((*current).ID, gomock.Any()).DoAndReturn(func(playbookRunID string, endAt int64) error {
+			(*current).CurrentStatus = app.StatusFinished
+			(*current).EndAt = endAt
+			return nil
+		}).AnyTimes()
+		store.EXPECT().CreateTimelineEvent(gomock.Any()).DoAndReturn(func(event *app.TimelineEvent) (*app.TimelineEvent, error) {
+			events = append(events, *event)
+			return event, nil
+		}).AnyTimes()
+
+		configService.EXPECT().IsAtLeastE10Licensed().Return(false).AnyTimes()
+		scheduler.EXPECT().Cancel(gomock.Any()).AnyTimes()
+		poster.EXPECT().PostMessage(channelID, gomock.Any(), gomock.Any()).Return(&model.Post{Id: model.NewId()}, nil).AnyTimes()
+		poster.EXPECT().PublishWebsocketEventToChannel(gomock.Any(), gomock.Any(), channelID).AnyTimes()
+
+		mattermostConfig := &model.Config{}
+		mattermostConfig.SetDefaults()
+		pluginAPI.On("GetConfig").Return(mattermostConfig)
+		pluginAPI.On("GetUser", mock.Anything).Return(func(userID string) *model.User {
+			return &model.User{Id: userID, Username: userID}
+		}, nil)
+		pluginAPI.On("HasPermissionTo", mock.Anything, model.PermissionManageSystem).Return(false)
+		pluginAPI.On("HasPermissionToChannel", "user", channelID, model.PermissionReadChannel).Return(true)
+		pluginAPI.On("HasPermissionToChannel", "outsider", channelID, model.PermissionReadChannel).Return(false)
+
+		s := app.NewPlaybookRunService(client, store, mock_app.NewMockPlaybookService(controller), poster, mock_bot.NewMockLogger(controller), configService, scheduler, &telemetry.NoopTelemetry{}, &metrics.NoopMetrics{}, pluginAPI)
+
+		return s, &events
+	}
+
+	t.Run("the run moves through the allowed statuses", func(t *testing.T) {
+		current := newRun()
+		s, events := setup(t, &current)
+
+		err := s.ChangeRunStatus(current.ID, "user", "Resolved")
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybookRun))
+
+		require.NoError(t, s.ChangeRunStatus(current.ID, "user", "Mitigated"))
+		require.Equal(t, "Mitigated", current.CurrentRunStatus)
+		require.Equal(t, app.StatusInProgress, current.CurrentStatus)
+
+		require.Len(t, *events, 1)
+		require.Equal(t, app.RunStatusChanged, (*events)[0].EventType)
+		require.JSONEq(t, `{"from": "Investigating", "to": "Mitigated"}`, (*events)[0].Details)
+	})
+
+	t.Run("a finished status finishes the run once the required items are done", func(t *testing.T) {
+		current := newRun()
+		current.CurrentRunStatus = "Mitigated"
+		s, events := setup(t, &current)
+
+		err := s.ChangeRunStatus(current.ID, "user", "Resolved")
+		require.True(t, errors.Is(err, app.ErrRequiredItemsOpen))
+		require.Equal(t, "Mitigated", current.CurrentRunStatus)
+		require.Empty(t, *events)
+
+		current.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+		err = s.ChangeRunStatus(current.ID, "outsider", "Resolved")
+		require.True(t, errors.Is(err, app.ErrNoPermissions))
+		require.Equal(t, "Mitigated", current.CurrentRunStatus)
+
+		require.NoError(t, s.ChangeRunStatus(current.ID, "user", "Resolved"))
+		require.Equal(t, "Resolved", current.CurrentRunStatus)
+		require.Equal(t, app.StatusFinished, current.CurrentStatus)
+
+		require.Len(t, *events, 2)
+		require.Equal(t, app.RunStatusChanged, (*events)[0].EventType)
+		require.Equal(t, app.RunFinished, (*events)[1].EventType)
+
+		err = s.ChangeRunStatus(current.ID, "user", "Investigating")
+		require.True(t, errors.Is(err, app.ErrPlaybookRunNotActive))
+	})
+
+	t.Run("finishing the run moves it to the first finished status", func(t *testing.T) {
+		current := newRun()
+		current.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+		s, _ := setup(t, &current)
+
+		require.NoError(t, s.FinishPlaybookRun(current.ID, "user", false))
+		require.Equal(t, "Resolved", current.CurrentRunStatus)
+		require.Equal(t, app.StatusFinished, current.CurrentStatus)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/golang/mock/gomock"
 	pluginapi "github.com/mattermost/mattermost-plugin-api"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/telemetry"
 	"github.com/mattermost/mattermost-server/v6/model"
 	"github.com/mattermost/mattermost-server/v6/plugin/plugintest"
@@ -20,21 +21,9 @@ import (
 )
 
 func TestGetSuggestedPlaybooks(t *testing.T) {
-	t.Run("can't get time last updated", func(t *testing.T) {
-		s, store, pluginAPI, _ := getMockPlaybookService(t)
-
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(0), errors.New("store error"))
-		pluginAPI.On("LogError", "can't update playbooks", "err", mock.Anything)
-
-		playbooks, triggers := s.GetSuggestedPlaybooks("teamID", "userID", "message")
-		require.Len(t, playbooks, 0)
-		require.Len(t, triggers, 0)
-	})
-
 	t.Run("can't get playbooks with keywords", func(t *testing.T) {
 		s, store, pluginAPI, _ := getMockPlaybookService(t)
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(nil, errors.New("store error"))
 		pluginAPI.On("LogError", "can't update playbooks", "err", mock.Anything)
 
@@ -48,7 +37,6 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 
 		teamID := model.NewId()
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -84,7 +72,6 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 
 		teamID := model.NewId()
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -110,7 +97,6 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 
 		teamID := model.NewId()
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -147,7 +133,6 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 
 		teamID := model.NewId()
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -190,8 +175,6 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 
 		teamID := model.NewId()
 
-		firstCall := store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1100), nil).After(firstCall)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -239,13 +222,11 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 		require.Equal(t, triggers, []string{"some"})
 	})
 
-	t.Run("same call should trigger the GetPlaybooksWithKeywords", func(t *testing.T) {
-		s, store, _, _ := getMockPlaybookService(t)
+	t.Run("updating a playbook should trigger the GetPlaybooksWithKeywords", func(t *testing.T) {
+		s, store, pluginAPI, _ := getMockPlaybookService(t)
 
 		teamID := model.NewId()
 
-		firstCall := store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1200), nil).After(firstCall)
 		playbook1 := app.Playbook{
 			ID:                model.NewId(),
 			Title:             "playbook 1",
@@ -293,6 +274,11 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 		})
 		require.Equal(t, triggers, []string{"some"})
 
+		store.EXPECT().Update(gomock.Any()).Return(nil)
+		pluginAPI.On("PublishPluginClusterEvent", model.PluginClusterEvent{Id: app.KeywordsCacheInvalidateEventID}, mock.Anything).Return(nil)
+		err := s.Update(playbook4, userID)
+		require.NoError(t, err)
+
 		cachedPlaybooks, triggers = s.GetSuggestedPlaybooks(teamID, userID, "some message")
 		require.Len(t, cachedPlaybooks, 2)
 		require.Equal(t, cachedPlaybooks[0], &app.CachedPlaybook{
@@ -312,24 +298,12 @@ func TestGetSuggestedPlaybooks(t *testing.T) {
 }
 
 func TestMessageHasBeenPosted(t *testing.T) {
-	t.Run("message is ignored", func(t *testing.T) {
-		s, _, _, keywordsIgnorer := getMockPlaybookService(t)
-
-		sessionID := model.NewId()
-		post := &model.Post{UserId: model.NewId(), Message: "message", RootId: ""}
-
-		keywordsIgnorer.EXPECT().IsIgnored(post.RootId, post.UserId).Return(true)
-		s.MessageHasBeenPosted(sessionID, post)
-	})
-
 	t.Run("can't get channel", func(t *testing.T) {
-		s, _, pluginAPI, keywordsIgnorer := getMockPlaybookService(t)
+		s, _, pluginAPI, _ := getMockPlaybookService(t)
 
 		sessionID := model.NewId()
 		post := &model.Post{UserId: model.NewId(), Message: "message", RootId: "", ChannelId: model.NewId()}
 
-		keywordsIgnorer.EXPECT().IsIgnored(post.RootId, post.UserId).Return(false)
-
 		pluginAPI.On("GetChannel", post.ChannelId).Return(nil, &model.AppError{Id: "someID"})
 		pluginAPI.On("LogError", "can't get channel", "err", mock.Anything)
 
@@ -337,17 +311,14 @@ func TestMessageHasBeenPosted(t *testing.T) {
 	})
 
 	t.Run("no suggestions", func(t *testing.T) {
-		s, store, pluginAPI, keywordsIgnorer := getMockPlaybookService(t)
+		s, store, pluginAPI, _ := getMockPlaybookService(t)
 
 		sessionID := model.NewId()
 		post := &model.Post{UserId: model.NewId(), Message: "some message", RootId: "", ChannelId: model.NewId()}
 
-		keywordsIgnorer.EXPECT().IsIgnored(post.RootId, post.UserId).Return(false)
-
 		teamID := model.NewId()
 		pluginAPI.On("GetChannel", post.ChannelId).Return(&model.Channel{TeamId: teamID}, nil)
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -383,12 +354,9 @@ func TestMessageHasBeenPosted(t *testing.T) {
 		userID := model.NewId()
 		post := &model.Post{UserId: userID, Message: "some message", RootId: "", ChannelId: model.NewId()}
 
-		keywordsIgnorer.EXPECT().IsIgnored(post.RootId, post.UserId).Return(false)
-
 		teamID := model.NewId()
 		pluginAPI.On("GetChannel", post.ChannelId).Return(&model.Channel{TeamId: teamID}, nil)
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -414,12 +382,40 @@ func TestMessageHasBeenPosted(t *testing.T) {
 		}
 		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(playbooks, nil)
 		store.EXPECT().GetPlaybookIDsForUser(userID, teamID).Return([]string{playbooks[1].ID, playbooks[2].ID}, nil)
+		keywordsIgnorer.EXPECT().IsIgnored(post.UserId, post.ChannelId, post.RootId).Return(false, nil)
 		pluginAPI.On("GetSession", sessionID).Return(nil, &model.AppError{Id: "someID"})
 		pluginAPI.On("LogError", "can't get session", "sessionID", sessionID, "err", mock.Anything)
 
 		s.MessageHasBeenPosted(sessionID, post)
 	})
 
+	t.Run("message is ignored", func(t *testing.T) {
+		s, store, pluginAPI, keywordsIgnorer := getMockPlaybookService(t)
+
+		sessionID := model.NewId()
+		userID := model.NewId()
+		post := &model.Post{UserId: userID, Message: "some message", RootId: model.NewId(), ChannelId: model.NewId()}
+
+		teamID := model.NewId()
+		pluginAPI.On("GetChannel", post.ChannelId).Return(&model.Channel{TeamId: teamID}, nil)
+
+		playbooks := []app.Playbook{
+			{
+				ID:                model.NewId(),
+				Title:             "playbook 1",
+				UpdateAt:          900,
+				TeamID:            teamID,
+				SignalAnyKeywords: []string{"some", "other"},
+			},
+		}
+		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(playbooks, nil)
+		store.EXPECT().GetPlaybookIDsForUser(userID, teamID).Return([]string{playbooks[0].ID}, nil)
+		keywordsIgnorer.EXPECT().IsIgnored(post.UserId, post.ChannelId, post.RootId).Return(true, nil)
+
+		s.MessageHasBeenPosted(sessionID, post)
+		pluginAPI.AssertNotCalled(t, "GetSession", mock.Anything)
+	})
+
 	t.Run("suggest a playbook", func(t *testing.T) {
 		controller := gomock.NewController(t)
 		pluginAPI := &plugintest.API{}
@@ -428,20 +424,19 @@ func TestMessageHasBeenPosted(t *testing.T) {
 		poster := mock_bot.NewMockPoster(controller)
 		telemetryService := &telemetry.NoopTelemetry{}
 		configService := mock_config.NewMockService(controller)
-		keywordsIgnorer := mock_playbook.NewMockKeywordsThreadIgnorer(controller)
-		s := app.NewPlaybookService(store, poster, telemetryService, client, configService, keywordsIgnorer)
+		keywordsIgnorer := mock_playbook.NewMockKeywordsIgnorer(controller)
+		keywordsCacher := app.NewPlaybookKeywordsCacher(store, pluginAPI, client.Log)
+		checklistLibrary := mock_playbook.NewMockChecklistLibraryService(controller)
+		s := app.NewPlaybookService(store, poster, telemetryService, client, configService, keywordsCacher, keywordsIgnorer, checklistLibrary)
 
 		sessionID := model.NewId()
 		userID := model.NewId()
 		channelID := model.NewId()
 		post := &model.Post{UserId: userID, Message: "some message", RootId: "", ChannelId: channelID}
 
-		keywordsIgnorer.EXPECT().IsIgnored(post.RootId, post.UserId).Return(false)
-
 		teamID := model.NewId()
 		pluginAPI.On("GetChannel", channelID).Return(&model.Channel{TeamId: teamID}, nil)
 
-		store.EXPECT().GetTimeLastUpdated(true).Return(int64(1000), nil)
 		playbooks := []app.Playbook{
 			{
 				ID:                model.NewId(),
@@ -467,6 +462,7 @@ func TestMessageHasBeenPosted(t *testing.T) {
 		}
 		store.EXPECT().GetPlaybooksWithKeywords(gomock.Any()).Return(playbooks, nil)
 		store.EXPECT().GetPlaybookIDsForUser(userID, teamID).Return([]string{playbooks[1].ID, playbooks[2].ID}, nil)
+		keywordsIgnorer.EXPECT().IsIgnored(post.UserId, post.ChannelId, post.RootId).Return(false, nil)
 		pluginAPI.On("GetSession", sessionID).Return(&model.Session{}, nil)
 
 		configService.EXPECT().GetManifest().Return(&model.Manifest{Id: "id"}).AnyTimes()
@@ -479,7 +475,42 @@ func TestMessageHasBeenPosted(t *testing.T) {
 	})
 }
 
-func getMockPlaybookService(t *testing.T) (app.PlaybookService, *mock_playbook.MockPlaybookStore, *plugintest.API, *mock_playbook.MockKeywordsThreadIgnorer) {
+func TestPlaybookServiceValidation(t *testing.T) {
+	controller := gomock.NewController(t)
+	pluginAPI := &plugintest.API{}
+	client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
+	store := mock_playbook.NewMockPlaybookStore(controller)
+	poster := mock_bot.NewMockPoster(controller)
+	configService := mock_config.NewMockService(controller)
+	keywordsIgnorer := mock_playbook.NewMockKeywordsIgnorer(controller)
+	keywordsCacher := app.NewPlaybookKeywordsCacher(store, pluginAPI, client.Log)
+	checklistLibrary := mock_playbook.NewMockChecklistLibraryService(controller)
+	s := app.NewPlaybookService(store, poster, &telemetry.NoopTelemetry{}, client, configService, keywordsCacher, keywordsIgnorer, checklistLibrary)
+
+	configService.EXPECT().GetConfiguration().Return(&config.Configuration{StrictPlaybookValidation: true}).AnyTimes()
+	playbook := app.Playbook{
+		ID:         model.NewId(),
+		Title:      "playbook",
+		TeamID:     model.NewId(),
+		Checklists: []app.Checklist{{Title: "Empty"}},
+	}
+
+	t.Run("create a playbook with warnings in strict mode", func(t *testing.T) {
+		_, err := s.Create(playbook, "userid")
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybook))
+
+		var invalidErr *app.InvalidPlaybookError
+		require.True(t, errors.As(err, &invalidErr))
+		require.Equal(t, "empty_checklist", invalidErr.Issues[0].Code)
+	})
+
+	t.Run("update a playbook with warnings in strict mode", func(t *testing.T) {
+		err := s.Update(playbook, "userid")
+		require.True(t, errors.Is(err, app.ErrMalformedPlaybook))
+	})
+}
+
+func getMockPlaybookService(t *testing.T) (app.PlaybookService, *mock_playbook.MockPlaybookStore, *plugintest.API, *mock_playbook.MockKeywordsIgnorer) {
 	controller := gomock.NewController(t)
 	pluginAPI := &plugintest.API{}
 	client := pluginapi.NewClient(pluginAPI, &plugintest.Driver{})
@@ -487,6 +518,9 @@ func getMockPlaybookService(t *testing.T) (app.PlaybookService, *mock_playbook.M
 	poster := mock_bot.NewMockPoster(controller)
 	telemetryService := &telemetry.NoopTelemetry{}
 	configService := mock_config.NewMockService(controller)
-	keywordsIgnorer := mock_playbook.NewMockKeywordsThreadIgnorer(controller)
-	return app.NewPlaybookService(store, poster, telemetryService, client, configService, keywordsIgnorer), store, pluginAPI, keywordsIgnorer
+	keywordsIgnorer := mock_playbook.NewMockKeywordsIgnorer(controller)
+	keywordsCacher := app.NewPlaybookKeywordsCacher(store, pluginAPI, client.Log)
+	checklistLibrary := mock_playbook.NewMockChecklistLibraryService(controller)
+	configService.EXPECT().GetConfiguration().Return(&config.Configuration{}).AnyTimes()
+	return app.NewPlaybookService(store, poster, telemetryService, client, configService, keywordsCacher, keywordsIgnorer, checklistLibrary), store, pluginAPI, keywordsIgnorer
 }
@@ -30,7 +30,12 @@ const helpText = "###### Mattermost Playbooks Plugin - Slash Command Help\n" +
 	"* `/playbook info` - Show a summary of the current playbook run. \n" +
 	"* `/playbook timeline` - Show the timeline for the current playbook run. \n" +
 	"* `/playbook todo` - Get a list of your assigned tasks. \n" +
+	"* `/playbook duplicate [playbook ID] [title]` - Copy a playbook into this team. \n" +
+	"* `/playbook save-as-playbook [title]` - Create a playbook with the checklists of the current playbook run. \n" +
+	"* `/playbook merge-into-playbook [change IDs/all]` - List or merge the checklist changes of the current playbook run into its playbook. \n" +
+	"* `/playbook import-markdown [title]` - Create a playbook in this team from the Markdown checklists written on the next lines. \n" +
 	"* `/playbook settings digest [on/off]` - turn daily digest on/off. \n" +
+	"* `/playbook settings keywords [ignore/unignore] [channel/all] [duration]` - stop or resume playbook suggestions. \n" +
 	"\n" +
 	"Learn more [in our documentation](https://mattermost.com/pl/default-incident-response-app-documentation). \n" +
 	""
@@ -38,6 +43,9 @@ const helpText = "###### Mattermost Playbooks Plugin - Slash Command Help\n" +
 const confirmPrompt = "CONFIRM"
 const maxPlaybookRunsToList = 10
 
+// maxIgnoreDays bounds how long a keyword ignore can last, keeping the expiry well within range.
+const maxIgnoreDays = 3650
+
 // Register is a function that allows the runner to register commands with the mattermost server.
 type Register func(*model.Command) error
 
@@ -52,7 +60,7 @@ func getCommand(addTestCommands bool) *model.Command {
 		DisplayName:      "Playbook",
 		Description:      "Playbooks",
 		AutoComplete:     true,
-		AutoCompleteDesc: "Available commands: run, finish, update, check, list, owner, info, todo, settings",
+		AutoCompleteDesc: "Available commands: run, finish, update, check, list, owner, info, todo, duplicate, save-as-playbook, merge-into-playbook, import-markdown, settings",
 		AutoCompleteHint: "[command]",
 		AutocompleteData: getAutocompleteData(addTestCommands),
 	}
@@ -112,7 +120,24 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 	todo := model.NewAutocompleteData("todo", "", "Get a list of your assigned tasks")
 	command.AddCommand(todo)
 
-	settings := model.NewAutocompleteData("settings", "digest [on/off]", "Change personal playbook settings")
+	duplicate := model.NewAutocompleteData("duplicate", "[playbook ID] [title]", "Copy a playbook into this team")
+	duplicate.AddDynamicListArgument("List of playbooks is loading", "api/v0/playbooks/autocomplete", true)
+	duplicate.AddTextArgument("Title of the copy", "[title]", "")
+	command.AddCommand(duplicate)
+
+	saveAsPlaybook := model.NewAutocompleteData("save-as-playbook", "[title]", "Create a playbook with the checklists of the current playbook run")
+	saveAsPlaybook.AddTextArgument("Title of the playbook", "[title]", "")
+	command.AddCommand(saveAsPlaybook)
+
+	mergeIntoPlaybook := model.NewAutocompleteData("merge-into-playbook", "[change IDs/all]", "List or merge the checklist changes of the current playbook run into its playbook")
+	mergeIntoPlaybook.AddTextArgument("The changes to merge, or all of them", "[change IDs/all]", "")
+	command.AddCommand(mergeIntoPlaybook)
+
+	importMarkdown := model.NewAutocompleteData("import-markdown", "[title]", "Create a playbook from the Markdown checklists written on the next lines")
+	importMarkdown.AddTextArgument("Title of the playbook", "[title]", "")
+	command.AddCommand(importMarkdown)
+
+	settings := model.NewAutocompleteData("settings", "[digest/keywords]", "Change personal playbook settings")
 	display := model.NewAutocompleteData(" ", "Display current settings", "")
 	settings.AddCommand(display)
 
@@ -126,6 +151,33 @@ func getAutocompleteData(addTestCommands bool) *model.AutocompleteData {
 	}}
 	digest.AddStaticListArgument("", true, digestValue)
 	settings.AddCommand(digest)
+
+	keywords := model.NewAutocompleteData("keywords", "[ignore/unignore/mute/unmute] [channel/all] [duration]", "Stop or resume playbook suggestions triggered by keywords")
+	keywordsIgnore := model.NewAutocompleteData("ignore", "[channel/all] [duration]", "Stop playbook suggestions for you")
+	keywordsIgnore.AddStaticListArgument("", true, []model.AutocompleteListItem{{
+		HelpText: "Only in this channel",
+		Item:     "channel",
+	}, {
+		HelpText: "In every channel",
+		Item:     "all",
+	}})
+	keywordsIgnore.AddTextArgument("How long to ignore suggestions for, e.g. 12h or 7d. Forever if omitted.", "[duration]", "")
+	keywords.AddCommand(keywordsIgnore)
+	keywordsUnignore := model.NewAutocompleteData("unignore", "[channel/all]", "Resume playbook suggestions for you")
+	keywordsUnignore.AddStaticListArgument("", true, []model.AutocompleteListItem{{
+		HelpText: "In this channel",
+		Item:     "channel",
+	}, {
+		HelpText: "In every channel",
+		Item:     "all",
+	}})
+	keywords.AddCommand(keywordsUnignore)
+	keywordsMute := model.NewAutocompleteData("mute", "[duration]", "Stop playbook suggestions in this channel for everyone (system admins only)")
+	keywordsMute.AddTextArgument("How long to mute the channel for, e.g. 12h or 7d. Forever if omitted.", "[duration]", "")
+	keywords.AddCommand(keywordsMute)
+	keywordsUnmute := model.NewAutocompleteData("unmute", "", "Resume playbook suggestions in this channel for everyone (system admins only)")
+	keywords.AddCommand(keywordsUnmute)
+	settings.AddCommand(keywords)
 	command.AddCommand(settings)
 
 	if addTestCommands {
@@ -169,13 +221,16 @@ type Runner struct {
 	configService      config.Service
 	userInfoStore      app.UserInfoStore
 	userInfoTelemetry  app.UserInfoTelemetry
+	keywordsIgnorer    app.KeywordsIgnorer
+	auditService       app.AuditService
 }
 
 // NewCommandRunner creates a command runner.
 func NewCommandRunner(ctx *plugin.Context, args *model.CommandArgs, api *pluginapi.Client,
 	logger bot.Logger, poster bot.Poster, playbookRunService app.PlaybookRunService,
 	playbookService app.PlaybookService, configService config.Service,
-	userInfoStore app.UserInfoStore, userInfoTelemetry app.UserInfoTelemetry) *Runner {
+	userInfoStore app.UserInfoStore, userInfoTelemetry app.UserInfoTelemetry,
+	keywordsIgnorer app.KeywordsIgnorer, auditService app.AuditService) *Runner {
 	return &Runner{
 		context:            ctx,
 		args:               args,
@@ -187,6 +242,8 @@ func NewCommandRunner(ctx *plugin.Context, args *model.CommandArgs, api *plugina
 		configService:      configService,
 		userInfoStore:      userInfoStore,
 		userInfoTelemetry:  userInfoTelemetry,
+		keywordsIgnorer:    keywordsIgnorer,
+		auditService:       auditService,
 	}
 }
 
@@ -489,6 +546,10 @@ func (r *Runner) actionChangeOwner(args []string) {
 		return
 	}
 
+	if !r.checkRunPermission(currentPlaybookRun, app.RunActionChangeOwner) {
+		return
+	}
+
 	if currentPlaybookRun.OwnerUserID == targetOwnerUser.Id {
 		r.postCommandResponse(fmt.Sprintf("User @%s is already owner of this playbook run.", targetOwnerUsername))
 		return
@@ -652,6 +713,32 @@ func (r *Runner) actionInfo() {
 	r.poster.EphemeralPost(r.args.UserId, r.args.ChannelId, post)
 }
 
+// runActionDescriptions describes the actions controlled by the run permission policy, for the
+// command responses.
+var runActionDescriptions = map[app.RunAction]string{
+	app.RunActionFinish:               "finish",
+	app.RunActionChangeOwner:          "change the owner of",
+	app.RunActionEditChecklists:       "edit the checklists of",
+	app.RunActionPostStatusUpdate:     "post a status update to",
+	app.RunActionPublishRetrospective: "publish the retrospective of",
+}
+
+// checkRunPermission returns true if the user may perform action on playbookRun, following the
+// run permission policy of its playbook. Otherwise it tells the user why, and returns false.
+func (r *Runner) checkRunPermission(playbookRun *app.PlaybookRun, action app.RunAction) bool {
+	err := app.RunActionAccess(r.args.UserId, playbookRun, action, r.playbookService, r.pluginAPI)
+	if errors.Is(err, app.ErrNoPermissions) {
+		r.recordPermissionDenied(app.AuditTargetRun, playbookRun.ID, playbookRun.TeamID)
+		r.postCommandResponse(fmt.Sprintf("You do not have permission to %s this playbook run.", runActionDescriptions[action]))
+		return false
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions: %v", err)
+		return false
+	}
+
+	return true
+}
+
 func (r *Runner) actionFinish() {
 	playbookRunID, err := r.playbookRunService.GetPlaybookRunIDForChannel(r.args.ChannelId)
 	if err != nil {
@@ -663,16 +750,22 @@ func (r *Runner) actionFinish() {
 		return
 	}
 
-	if err = app.EditPlaybookRun(r.args.UserId, r.args.ChannelId, r.pluginAPI); err != nil {
-		if errors.Is(err, app.ErrNoPermissions) {
-			r.postCommandResponse(fmt.Sprintf("userID `%s` is not an admin or channel member", r.args.UserId))
-			return
-		}
+	playbookRun, err := r.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
 		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
 		return
 	}
 
-	err = r.playbookRunService.OpenFinishPlaybookRunDialog(playbookRunID, r.args.TriggerId)
+	if !r.checkRunPermission(playbookRun, app.RunActionFinish) {
+		return
+	}
+
+	if openRequired := app.OpenRequiredItems(playbookRun.Checklists); len(openRequired) > 0 && !app.IsAdmin(r.args.UserId, r.pluginAPI) {
+		r.postCommandResponse("The run can't be finished until these required tasks are done:\n" + app.FormatOpenRequiredItems(openRequired))
+		return
+	}
+
+	err = r.playbookRunService.OpenFinishPlaybookRunDialog(playbookRunID, r.args.UserId, r.args.TriggerId)
 	if err != nil {
 		r.warnUserAndLogErrorf("Error finishing the playbook run: %v", err)
 		return
@@ -690,15 +783,16 @@ func (r *Runner) actionUpdate() {
 		return
 	}
 
-	if err = app.EditPlaybookRun(r.args.UserId, r.args.ChannelId, r.pluginAPI); err != nil {
-		if errors.Is(err, app.ErrNoPermissions) {
-			r.postCommandResponse(fmt.Sprintf("userID `%s` is not an admin or channel member", r.args.UserId))
-			return
-		}
+	playbookRun, err := r.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
 		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
 		return
 	}
 
+	if !r.checkRunPermission(playbookRun, app.RunActionPostStatusUpdate) {
+		return
+	}
+
 	err = r.playbookRunService.OpenUpdateStatusDialog(playbookRunID, r.args.TriggerId)
 	switch {
 	case errors.Is(err, app.ErrPlaybookRunNotActive):
@@ -818,7 +912,8 @@ func (r *Runner) summaryMessage(event app.TimelineEvent) string {
 		return "@" + username + " " + event.Summary
 	case app.AssigneeChanged:
 		return "@" + username + " " + event.Summary
-	case app.RanSlashCommand:
+	case app.RanSlashCommand, app.SlashCommandFailed, app.RanHTTPAction, app.RequiredItemsOverridden,
+		app.ApprovalRequested, app.ChecklistItemApproved, app.ChecklistItemRejected, app.RunStatusChanged:
 		return "@" + username + " " + event.Summary
 	case app.PublishedRetrospective:
 		return "@" + username + " published retrospective"
@@ -846,17 +941,307 @@ func (r *Runner) actionTodo() {
 	}
 }
 
+func (r *Runner) actionDuplicate(args []string) {
+	if len(args) == 0 {
+		r.postCommandResponse("Usage: `/playbook duplicate [playbook ID] [title]`")
+		return
+	}
+
+	playbookID := args[0]
+	title := strings.Join(args[1:], " ")
+
+	if err := app.PlaybookRoleAccess(r.args.UserId, playbookID, app.PlaybookRoleViewer, r.playbookService, r.pluginAPI); err != nil {
+		if errors.Is(err, app.ErrNoPermissions) {
+			r.recordPermissionDenied(app.AuditTargetPlaybook, playbookID, "")
+		}
+		if errors.Is(err, app.ErrNotFound) || errors.Is(err, app.ErrNoPermissions) {
+			r.postCommandResponse("Playbook not found for id: " + playbookID)
+			return
+		}
+		r.warnUserAndLogErrorf("Error: %v", err)
+		return
+	}
+
+	playbook, err := r.playbookService.Get(playbookID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error: %v", err)
+		return
+	}
+
+	duplicate := playbook.Duplicate(r.args.UserId, r.args.TeamId, title)
+	if err = app.CreatePlaybook(r.args.UserId, duplicate, r.configService, r.pluginAPI, r.playbookService); errors.Is(err, app.ErrNoPermissions) {
+		r.recordPermissionDenied(app.AuditTargetPlaybook, "", duplicate.TeamID)
+		r.postCommandResponse("You don't have permission to create this playbook in this team.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions to create the playbook: %v", err)
+		return
+	}
+
+	id, err := r.playbookService.Create(duplicate, r.args.UserId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error: %v", err)
+		return
+	}
+	duplicate.ID = id
+
+	r.recordAudit(app.AuditRecord{
+		TargetType: app.AuditTargetPlaybook,
+		TargetID:   id,
+		TeamID:     duplicate.TeamID,
+		Changes:    r.auditChanges(nil, duplicate),
+	})
+
+	r.postCommandResponse(fmt.Sprintf("Playbook **%s** was copied to [%s](/playbooks/playbooks/%s).",
+		playbook.Title, duplicate.Title, id))
+}
+
+// channelPlaybookRun returns the playbook run of the channel, or false after telling the user
+// if there is none.
+func (r *Runner) channelPlaybookRun() (*app.PlaybookRun, bool) {
+	playbookRunID, err := r.playbookRunService.GetPlaybookRunIDForChannel(r.args.ChannelId)
+	if err != nil {
+		if errors.Is(err, app.ErrNotFound) {
+			r.postCommandResponse("This command only works when run from a playbook run channel.")
+			return nil, false
+		}
+		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
+		return nil, false
+	}
+
+	playbookRun, err := r.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving playbook run: %v", err)
+		return nil, false
+	}
+
+	return playbookRun, true
+}
+
+func (r *Runner) actionSaveAsPlaybook(args []string) {
+	playbookRun, ok := r.channelPlaybookRun()
+	if !ok {
+		return
+	}
+
+	// Keep the settings of the playbook of the run, if the user can see them.
+	var source *app.Playbook
+	if playbookRun.PlaybookID != "" {
+		pb, err := r.playbookService.Get(playbookRun.PlaybookID)
+		if err != nil && !errors.Is(err, app.ErrNotFound) {
+			r.warnUserAndLogErrorf("Error retrieving playbook: %v", err)
+			return
+		}
+		if err == nil && app.CheckPlaybookRole(r.args.UserId, pb, app.PlaybookRoleViewer, r.pluginAPI) == nil {
+			source = &pb
+		}
+	}
+
+	playbook := app.PlaybookFromRun(r.args.UserId, playbookRun, source, strings.Join(args, " "), false)
+	if err := app.CreatePlaybook(r.args.UserId, playbook, r.configService, r.pluginAPI, r.playbookService); errors.Is(err, app.ErrNoPermissions) {
+		r.recordPermissionDenied(app.AuditTargetPlaybook, "", playbook.TeamID)
+		r.postCommandResponse("You don't have permission to create playbooks in this team.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions to create the playbook: %v", err)
+		return
+	}
+
+	id, err := r.playbookService.Create(playbook, r.args.UserId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error: %v", err)
+		return
+	}
+	playbook.ID = id
+
+	r.recordAudit(app.AuditRecord{
+		TargetType: app.AuditTargetPlaybook,
+		TargetID:   id,
+		TeamID:     playbook.TeamID,
+		Changes:    r.auditChanges(nil, playbook),
+	})
+
+	r.postCommandResponse(fmt.Sprintf("The checklists of this run were saved as the playbook [%s](/playbooks/playbooks/%s).", playbook.Title, id))
+}
+
+func (r *Runner) actionMergeIntoPlaybook(args []string) {
+	playbookRun, ok := r.channelPlaybookRun()
+	if !ok {
+		return
+	}
+
+	if playbookRun.PlaybookID == "" {
+		r.postCommandResponse("This playbook run has no playbook. Use `/playbook save-as-playbook` to create one.")
+		return
+	}
+
+	playbook, err := r.playbookService.Get(playbookRun.PlaybookID)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error retrieving playbook: %v", err)
+		return
+	}
+
+	if err = app.CheckPlaybookRole(r.args.UserId, playbook, app.PlaybookRoleViewer, r.pluginAPI); err != nil {
+		r.recordPermissionDenied(app.AuditTargetPlaybook, playbook.ID, playbook.TeamID)
+		r.postCommandResponse("You don't have permission to view the playbook of this run.")
+		return
+	}
+
+	changes := app.DiffPlaybookChecklists(playbook.Checklists, playbookRun.Checklists)
+	if len(changes) == 0 {
+		r.postCommandResponse(fmt.Sprintf("The checklists of this run are the same as those of the playbook **%s**.", playbook.Title))
+		return
+	}
+
+	if len(args) == 0 {
+		msg := fmt.Sprintf("Changes to the checklists of the playbook **%s**:\n", playbook.Title)
+		for _, change := range changes {
+			msg += fmt.Sprintf("- `%s` %s\n", change.ID, describePlaybookChange(change))
+		}
+		msg += "\nMerge them with `/playbook merge-into-playbook [change IDs/all]`."
+		r.postCommandResponse(msg)
+		return
+	}
+
+	changeIDs := args
+	if len(args) == 1 && args[0] == "all" {
+		changeIDs = nil
+		for _, change := range changes {
+			changeIDs = append(changeIDs, change.ID)
+		}
+	}
+
+	checklists, err := app.ApplyPlaybookChanges(playbook.Checklists, playbookRun.Checklists, changeIDs)
+	if err != nil {
+		r.postCommandResponse(fmt.Sprintf("Unable to merge the changes: %v. List them again with `/playbook merge-into-playbook`.", err))
+		return
+	}
+
+	updated := playbook.Clone()
+	updated.Checklists = checklists
+	if err = app.PlaybookModify(r.args.UserId, updated, playbook, r.configService, r.pluginAPI, r.playbookService); errors.Is(err, app.ErrNoPermissions) {
+		r.recordPermissionDenied(app.AuditTargetPlaybook, playbook.ID, playbook.TeamID)
+		r.postCommandResponse("You don't have permission to edit the playbook of this run.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions to edit the playbook: %v", err)
+		return
+	}
+
+	if err = r.playbookService.Update(updated, r.args.UserId); err != nil {
+		r.warnUserAndLogErrorf("Error updating playbook: %v", err)
+		return
+	}
+
+	r.recordAudit(app.AuditRecord{
+		TargetType: app.AuditTargetPlaybook,
+		TargetID:   playbook.ID,
+		TeamID:     playbook.TeamID,
+		Changes:    r.auditChanges(playbook, updated),
+	})
+
+	r.postCommandResponse(fmt.Sprintf("Merged %d changes into the playbook [%s](/playbooks/playbooks/%s).", len(changeIDs), playbook.Title, playbook.ID))
+}
+
+// actionImportMarkdown creates a playbook from the Markdown written after the first line of the
+// command, which holds the optional title.
+func (r *Runner) actionImportMarkdown() {
+	text := r.args.Command
+	text = text[strings.Index(text, "import-markdown")+len("import-markdown"):]
+
+	title, markdown := text, ""
+	if newline := strings.Index(text, "\n"); newline >= 0 {
+		title, markdown = text[:newline], text[newline+1:]
+	}
+
+	if strings.TrimSpace(markdown) == "" {
+		r.postCommandResponse("Usage: `/playbook import-markdown [title]`, followed by the Markdown checklists on the next lines.")
+		return
+	}
+
+	playbook, issues := app.PlaybookFromMarkdown(r.args.UserId, r.args.TeamId, strings.TrimSpace(title), markdown, false)
+	if len(playbook.Checklists) == 0 {
+		r.postCommandResponse("No tasks were found. Write headings for the checklists, and task bullets (`- [ ]`) for their items.")
+		return
+	}
+
+	if err := app.CreatePlaybook(r.args.UserId, playbook, r.configService, r.pluginAPI, r.playbookService); errors.Is(err, app.ErrNoPermissions) {
+		r.recordPermissionDenied(app.AuditTargetPlaybook, "", playbook.TeamID)
+		r.postCommandResponse("You don't have permission to create playbooks in this team.")
+		return
+	} else if err != nil {
+		r.warnUserAndLogErrorf("Error checking permissions to create the playbook: %v", err)
+		return
+	}
+
+	id, err := r.playbookService.Create(playbook, r.args.UserId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error: %v", err)
+		return
+	}
+	playbook.ID = id
+
+	r.recordAudit(app.AuditRecord{
+		TargetType: app.AuditTargetPlaybook,
+		TargetID:   id,
+		TeamID:     playbook.TeamID,
+		Changes:    r.auditChanges(nil, playbook),
+	})
+
+	msg := fmt.Sprintf("Created the playbook [%s](/playbooks/playbooks/%s) with %d checklists.", playbook.Title, id, len(playbook.Checklists))
+	if len(issues) > 0 {
+		msg += "\n\nThese lines were not imported:\n"
+		for _, issue := range issues {
+			msg += fmt.Sprintf("- Line %d: %s\n", issue.Line, issue.Message)
+		}
+	}
+	r.postCommandResponse(msg)
+}
+
+func describePlaybookChange(change app.PlaybookChange) string {
+	switch change.Type {
+	case app.PlaybookChangeAddChecklist:
+		return fmt.Sprintf("add the checklist **%s** with %d items", change.ChecklistTitle, len(change.Checklist.Items))
+	case app.PlaybookChangeAddItem:
+		return fmt.Sprintf("add **%s** to **%s**", change.Item.Title, change.ChecklistTitle)
+	case app.PlaybookChangeRemoveItem:
+		return fmt.Sprintf("remove **%s** from **%s**", change.Before.Title, change.ChecklistTitle)
+	case app.PlaybookChangeUpdateItem:
+		return fmt.Sprintf("update the description or command of **%s** in **%s**", change.Item.Title, change.ChecklistTitle)
+	}
+	return change.Type
+}
+
 func (r *Runner) actionSettings(args []string) {
 	settingsHelpText := "###### Playbooks Personal Settings - Slash Command Help\n" +
 		"* `/playbook settings` - display current settings. \n" +
 		"* `/playbook settings digest on` - turn daily digest on. \n" +
-		"* `/playbook settings digest off` - turn daily digest off."
+		"* `/playbook settings digest off` - turn daily digest off. \n" +
+		"* `/playbook settings keywords ignore channel [duration]` - stop playbook suggestions in this channel. \n" +
+		"* `/playbook settings keywords ignore all [duration]` - stop playbook suggestions everywhere. \n" +
+		"* `/playbook settings keywords unignore [channel/all]` - resume playbook suggestions. \n" +
+		"* `/playbook settings keywords mute [duration]` - stop playbook suggestions in this channel for everyone (system admins only). \n" +
+		"* `/playbook settings keywords unmute` - resume playbook suggestions in this channel for everyone (system admins only)."
 
 	if len(args) == 0 {
 		r.displayCurrentSettings()
 		return
 	}
 
+	if args[0] == "keywords" {
+		if err := r.keywordsSettings(args[1:]); err != nil {
+			r.postCommandResponse(fmt.Sprintf("Unable to change keywords settings: %s.\n\n%s", err.Error(), settingsHelpText))
+			return
+		}
+		r.recordAudit(app.AuditRecord{
+			TargetType: app.AuditTargetSettings,
+			TeamID:     r.args.TeamId,
+			Details:    fmt.Sprintf("%s, in channel %s", strings.Join(args, " "), r.args.ChannelId),
+		})
+		r.displayCurrentSettings()
+		return
+	}
+
 	if len(args) != 2 || args[0] != "digest" || (args[1] != "on" && args[1] != "off") {
 		r.postCommandResponse(settingsHelpText)
 		return
@@ -886,10 +1271,129 @@ func (r *Runner) actionSettings(args []string) {
 	}
 
 	r.userInfoTelemetry.ChangeDigestSettings(r.args.UserId, oldInfo.DigestNotificationSettings, info.DigestNotificationSettings)
+	r.recordAudit(app.AuditRecord{
+		TargetType: app.AuditTargetSettings,
+		TeamID:     r.args.TeamId,
+		Details:    strings.Join(args, " "),
+		Changes:    r.auditChanges(oldInfo, info),
+	})
 
 	r.displayCurrentSettings()
 }
 
+// keywordsSettings changes the keywords ignore rules. It returns an error to show the user if the
+// arguments are invalid or the rules can't be updated.
+func (r *Runner) keywordsSettings(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing keywords setting")
+	}
+
+	var expireAt int64
+	durationArg := func(i int) error {
+		if len(args) <= i {
+			return nil
+		}
+		duration, err := parseIgnoreDuration(args[i])
+		if err != nil {
+			return err
+		}
+		expireAt = model.GetMillis() + duration.Milliseconds()
+		return nil
+	}
+
+	scopeArg := func() (string, string, error) {
+		if len(args) < 2 {
+			return "", "", errors.New("please specify `channel` or `all`")
+		}
+		switch args[1] {
+		case "channel":
+			return app.KeywordsIgnoreScopeChannel, r.args.ChannelId, nil
+		case "all":
+			return app.KeywordsIgnoreScopeGlobal, "", nil
+		}
+		return "", "", errors.Errorf("unknown scope `%s`, please specify `channel` or `all`", args[1])
+	}
+
+	var err error
+	switch args[0] {
+	case "ignore":
+		scope, scopeID, scopeErr := scopeArg()
+		if scopeErr != nil {
+			return scopeErr
+		}
+		if err = durationArg(2); err != nil {
+			return err
+		}
+		err = r.keywordsIgnorer.Ignore(app.KeywordsIgnoreRule{
+			UserID:   r.args.UserId,
+			Scope:    scope,
+			ScopeID:  scopeID,
+			ExpireAt: expireAt,
+		})
+	case "unignore":
+		scope, scopeID, scopeErr := scopeArg()
+		if scopeErr != nil {
+			return scopeErr
+		}
+		err = r.keywordsIgnorer.Unignore(r.args.UserId, scope, scopeID)
+	case "mute", "unmute":
+		if !app.IsAdmin(r.args.UserId, r.pluginAPI) {
+			r.recordPermissionDenied(app.AuditTargetSettings, "", r.args.TeamId)
+			return errors.New("muting channels is restricted to system administrators")
+		}
+		if args[0] == "unmute" {
+			err = r.keywordsIgnorer.Unignore("", app.KeywordsIgnoreScopeChannel, r.args.ChannelId)
+			break
+		}
+		if err = durationArg(1); err != nil {
+			return err
+		}
+		err = r.keywordsIgnorer.Ignore(app.KeywordsIgnoreRule{
+			Scope:    app.KeywordsIgnoreScopeChannel,
+			ScopeID:  r.args.ChannelId,
+			ExpireAt: expireAt,
+		})
+	default:
+		return errors.Errorf("unknown keywords setting `%s`", args[0])
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "failed to update the keywords ignore rules")
+	}
+
+	return nil
+}
+
+// parseIgnoreDuration parses a positive duration, accepting a number of days (e.g. 7d) on top of
+// the units understood by time.ParseDuration, up to maxIgnoreDays.
+func parseIgnoreDuration(value string) (time.Duration, error) {
+	var duration time.Duration
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, errors.Errorf("invalid duration `%s`", value)
+		}
+		if days > maxIgnoreDays {
+			return 0, errors.Errorf("invalid duration `%s`, it can't be longer than %d days", value, maxIgnoreDays)
+		}
+		duration = time.Duration(days) * 24 * time.Hour
+	} else {
+		var err error
+		if duration, err = time.ParseDuration(value); err != nil {
+			return 0, errors.Errorf("invalid duration `%s`", value)
+		}
+		if duration > maxIgnoreDays*24*time.Hour {
+			return 0, errors.Errorf("invalid duration `%s`, it can't be longer than %d days", value, maxIgnoreDays)
+		}
+	}
+
+	if duration <= 0 {
+		return 0, errors.Errorf("invalid duration `%s`, it must be positive", value)
+	}
+
+	return duration, nil
+}
+
 func (r *Runner) displayCurrentSettings() {
 	info, err := r.userInfoStore.Get(r.args.UserId)
 	if err != nil {
@@ -903,7 +1407,41 @@ func (r *Runner) displayCurrentSettings() {
 	if info.DisableDailyDigest {
 		dailyDigestSetting = "Daily digest: off"
 	}
-	r.postCommandResponse(fmt.Sprintf("###### Playbooks Personal Settings\n- %s", dailyDigestSetting))
+
+	rules, err := r.keywordsIgnorer.GetRules(r.args.UserId)
+	if err != nil {
+		r.warnUserAndLogErrorf("Error getting keywords ignore rules: %v", err)
+		return
+	}
+
+	keywordsSetting := "Playbook suggestions: on"
+	var ignoredChannels []string
+	for _, rule := range rules {
+		switch rule.Scope {
+		case app.KeywordsIgnoreScopeGlobal:
+			keywordsSetting = "Playbook suggestions: off" + untilString(rule.ExpireAt)
+		case app.KeywordsIgnoreScopeChannel:
+			channelName := rule.ScopeID
+			if channel, channelErr := r.pluginAPI.Channel.Get(rule.ScopeID); channelErr == nil {
+				channelName = "~" + channel.Name
+			}
+			ignoredChannels = append(ignoredChannels, channelName+untilString(rule.ExpireAt))
+		}
+	}
+	if len(ignoredChannels) > 0 {
+		keywordsSetting += fmt.Sprintf("\n- Playbook suggestions ignored in: %s", strings.Join(ignoredChannels, ", "))
+	}
+
+	r.postCommandResponse(fmt.Sprintf("###### Playbooks Personal Settings\n- %s\n- %s", dailyDigestSetting, keywordsSetting))
+}
+
+// untilString describes when a keywords ignore rule expires.
+func untilString(expireAt int64) string {
+	if expireAt == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" (until %s)", timeutils.GetTimeForMillis(expireAt).UTC().Format("Jan 2, 2006 15:04 MST"))
 }
 
 func (r *Runner) actionTestSelf(args []string) {
@@ -914,6 +1452,7 @@ func (r *Runner) actionTestSelf(args []string) {
 	}
 
 	if !r.pluginAPI.User.HasPermissionTo(r.args.UserId, model.PermissionManageSystem) {
+		r.recordPermissionDenied("", "", "")
 		r.postCommandResponse("Running the self-test is restricted to system administrators.")
 		return
 	}
@@ -1141,6 +1680,7 @@ func (r *Runner) actionTest(args []string) {
 	}
 
 	if !r.pluginAPI.User.HasPermissionTo(r.args.UserId, model.PermissionManageSystem) {
+		r.recordPermissionDenied("", "", "")
 		r.postCommandResponse("Running the test command is restricted to system administrators.")
 		return
 	}
@@ -1797,7 +2337,7 @@ func (r *Runner) generateTestData(numActivePlaybookRuns, numEndedPlaybookRuns in
 	}
 
 	for i := 0; i < numEndedPlaybookRuns; i++ {
-		err := r.playbookRunService.FinishPlaybookRun(playbookRuns[i].ID, r.args.UserId)
+		err := r.playbookRunService.FinishPlaybookRun(playbookRuns[i].ID, r.args.UserId, false)
 		if err != nil {
 			r.warnUserAndLogErrorf("Error ending the playbook run: %v", err)
 			return
@@ -1815,6 +2355,7 @@ func (r *Runner) actionNukeDB(args []string) {
 	}
 
 	if !r.pluginAPI.User.HasPermissionTo(r.args.UserId, model.PermissionManageSystem) {
+		r.recordPermissionDenied("", "", "")
 		r.postCommandResponse("Nuking the database is restricted to system administrators.")
 		return
 	}
@@ -1853,6 +2394,12 @@ func (r *Runner) Execute() error {
 		return nil
 	}
 
+	var recordAudit func()
+	switch cmd {
+	case "finish", "update", "check", "checkadd", "checkremove", "owner", "add", "timeline":
+		recordAudit = r.auditChannelRun()
+	}
+
 	switch cmd {
 	case "run":
 		r.actionRun(parameters)
@@ -1880,6 +2427,14 @@ func (r *Runner) Execute() error {
 		r.actionTimeline()
 	case "todo":
 		r.actionTodo()
+	case "duplicate":
+		r.actionDuplicate(parameters)
+	case "save-as-playbook":
+		r.actionSaveAsPlaybook(parameters)
+	case "merge-into-playbook":
+		r.actionMergeIntoPlaybook(parameters)
+	case "import-markdown":
+		r.actionImportMarkdown()
 	case "settings":
 		r.actionSettings(parameters)
 	case "nuke-db":
@@ -1890,5 +2445,85 @@ func (r *Runner) Execute() error {
 		r.postCommandResponse(helpText)
 	}
 
+	if recordAudit != nil {
+		recordAudit()
+	}
+
 	return nil
 }
+
+// auditChannelRun loads the playbook run of the channel before a command modifying it, and
+// returns the function recording the changes done by the command, if any, in the audit log.
+func (r *Runner) auditChannelRun() func() {
+	playbookRunID, err := r.playbookRunService.GetPlaybookRunIDForChannel(r.args.ChannelId)
+	if err != nil {
+		return nil
+	}
+
+	before, err := r.playbookRunService.GetPlaybookRun(playbookRunID)
+	if err != nil {
+		return nil
+	}
+
+	return func() {
+		after, getErr := r.playbookRunService.GetPlaybookRun(playbookRunID)
+		if getErr != nil {
+			r.logger.Warnf("failed to get playbook run '%s' for the audit log: %v", playbookRunID, getErr)
+			return
+		}
+
+		changes := r.auditChanges(before, after)
+		if len(changes) == 0 {
+			return
+		}
+
+		r.recordAudit(app.AuditRecord{
+			TargetType: app.AuditTargetRun,
+			TargetID:   playbookRunID,
+			TeamID:     after.TeamID,
+			Changes:    changes,
+		})
+	}
+}
+
+// auditAction returns the command run, without its arguments, e.g. "/playbook finish".
+func (r *Runner) auditAction() string {
+	fields := strings.Fields(r.args.Command)
+	if len(fields) > 2 {
+		fields = fields[:2]
+	}
+	return strings.Join(fields, " ")
+}
+
+// auditChanges returns the changes between before and after to record in the audit log,
+// logging instead of failing if they can't be computed.
+func (r *Runner) auditChanges(before, after interface{}) []app.AuditChange {
+	changes, err := app.AuditDiff(before, after)
+	if err != nil {
+		r.logger.Warnf("failed to compute the changes of command '%s' for the audit log: %v", r.auditAction(), err)
+	}
+	return changes
+}
+
+// recordAudit records an action of the command in the audit log. The actor and source are
+// filled in, and the action defaults to the command run.
+func (r *Runner) recordAudit(record app.AuditRecord) {
+	record.ActorUserID = r.args.UserId
+	record.Source = app.AuditSourceSlashCommand
+	if record.Action == "" {
+		record.Action = r.auditAction()
+	}
+	r.auditService.Record(record)
+}
+
+// recordPermissionDenied records in the audit log that the command was rejected for lack of
+// permissions on the given target, if any.
+func (r *Runner) recordPermissionDenied(targetType, targetID, teamID string) {
+	r.recordAudit(app.AuditRecord{
+		Action:     app.AuditActionPermissionDenied,
+		TargetType: targetType,
+		TargetID:   targetID,
+		TeamID:     teamID,
+		Details:    r.auditAction(),
+	})
+}
@@ -18,6 +18,26 @@ type Configuration struct {
 	// EnableExperimentalFeatures determines if experimental features are enabled.
 	EnableExperimentalFeatures bool
 
+	// MetricsToken is the token required to scrape the metrics endpoint. The endpoint is
+	// disabled when it's empty.
+	MetricsToken string
+
+	// TelemetryFilePath is the file the telemetry events are appended to, as JSON lines.
+	// The file sink is disabled when it's empty.
+	TelemetryFilePath string
+
+	// TelemetryCollectorURL is the URL the telemetry events are posted to. The HTTP sink
+	// is disabled when it's empty.
+	TelemetryCollectorURL string
+
+	// StrictPlaybookValidation rejects the playbooks with validation warnings on create and
+	// update, not only those with errors.
+	StrictPlaybookValidation bool
+
+	// HTTPActionSecrets are the secrets the HTTP actions of checklist items can send in their
+	// headers, one per line as its name, the URL prefix it can be sent to and its value.
+	HTTPActionSecrets string
+
 	// ** The following are NOT stored on the server
 	// AdminUserIDs contains a list of user IDs that are allowed
 	// to administer plugin functions, even if not Mattermost sysadmins.
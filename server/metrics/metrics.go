@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "playbooks"
+
+// Store provides the gauges computed from the database every time the metrics are scraped.
+type Store interface {
+	InProgressRunsByTeamAndPlaybook() ([]sqlstore.RunsCount, error)
+	OverdueStatusUpdatesByTeamAndPlaybook() ([]sqlstore.RunsCount, error)
+}
+
+// Metrics holds the metrics of the plugin, exposed in the Prometheus format.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	runsCreated        *prometheus.CounterVec
+	runsFinished       *prometheus.CounterVec
+	remindersScheduled prometheus.Counter
+	webhookFailures    prometheus.Counter
+	apiRequestDuration *prometheus.HistogramVec
+}
+
+// Ensure Metrics implements the app.PlaybookRunMetrics interface.
+var _ app.PlaybookRunMetrics = (*Metrics)(nil)
+
+// NewMetrics creates the metrics of the plugin, reading the gauges from store.
+func NewMetrics(store Store) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+
+		runsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "runs_created_total",
+			Help:      "Number of playbook runs created.",
+		}, []string{"team_id", "playbook_id"}),
+
+		runsFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "runs_finished_total",
+			Help:      "Number of playbook runs finished.",
+		}, []string{"team_id", "playbook_id"}),
+
+		remindersScheduled: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reminders_scheduled_total",
+			Help:      "Number of status update and retrospective reminder jobs scheduled.",
+		}),
+
+		webhookFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "webhook_failures_total",
+			Help:      "Number of outgoing webhooks that couldn't be delivered.",
+		}),
+
+		apiRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "api_request_duration_seconds",
+			Help:      "Latency of the API requests, by route.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status_code"}),
+	}
+
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{Namespace: namespace}),
+		newStoreCollector(store),
+		m.runsCreated,
+		m.runsFinished,
+		m.remindersScheduled,
+		m.webhookFailures,
+		m.apiRequestDuration,
+	)
+
+	return m
+}
+
+// Handler returns the handler serving the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// IncrementRunsCreated counts a run created in teamID from playbookID.
+func (m *Metrics) IncrementRunsCreated(teamID, playbookID string) {
+	m.runsCreated.WithLabelValues(teamID, playbookID).Inc()
+}
+
+// IncrementRunsFinished counts a run of teamID and playbookID being finished.
+func (m *Metrics) IncrementRunsFinished(teamID, playbookID string) {
+	m.runsFinished.WithLabelValues(teamID, playbookID).Inc()
+}
+
+// IncrementRemindersScheduled counts a reminder job being scheduled.
+func (m *Metrics) IncrementRemindersScheduled() {
+	m.remindersScheduled.Inc()
+}
+
+// IncrementWebhookFailures counts a webhook that couldn't be delivered.
+func (m *Metrics) IncrementWebhookFailures() {
+	m.webhookFailures.Inc()
+}
+
+// ObserveAPIRequest records the latency of an API request to route.
+func (m *Metrics) ObserveAPIRequest(route, method string, statusCode int, elapsed time.Duration) {
+	m.apiRequestDuration.WithLabelValues(route, method, strconv.Itoa(statusCode)).Observe(elapsed.Seconds())
+}
+
+// storeCollector reads the gauges from the store at scrape time, so that they're consistent
+// across the servers of a cluster.
+type storeCollector struct {
+	store                Store
+	runsInProgress       *prometheus.Desc
+	overdueStatusUpdates *prometheus.Desc
+}
+
+func newStoreCollector(store Store) *storeCollector {
+	return &storeCollector{
+		store: store,
+		runsInProgress: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "runs_in_progress"),
+			"Number of playbook runs in progress.",
+			[]string{"team_id", "playbook_id"}, nil,
+		),
+		overdueStatusUpdates: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "runs_status_update_overdue"),
+			"Number of playbook runs in progress with an overdue status update.",
+			[]string{"team_id", "playbook_id"}, nil,
+		),
+	}
+}
+
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.runsInProgress
+	ch <- c.overdueStatusUpdates
+}
+
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	collectRunsCounts(ch, c.runsInProgress, c.store.InProgressRunsByTeamAndPlaybook)
+	collectRunsCounts(ch, c.overdueStatusUpdates, c.store.OverdueStatusUpdatesByTeamAndPlaybook)
+}
+
+func collectRunsCounts(ch chan<- prometheus.Metric, desc *prometheus.Desc, getCounts func() ([]sqlstore.RunsCount, error)) {
+	counts, err := getCounts()
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(desc, err)
+		return
+	}
+
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(count.Count), count.TeamID, count.PlaybookID)
+	}
+}
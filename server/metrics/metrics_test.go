@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore"
+)
+
+type fakeStore struct {
+	runsInProgress []sqlstore.RunsCount
+	overdue        []sqlstore.RunsCount
+	err            error
+}
+
+func (s *fakeStore) InProgressRunsByTeamAndPlaybook() ([]sqlstore.RunsCount, error) {
+	return s.runsInProgress, s.err
+}
+
+func (s *fakeStore) OverdueStatusUpdatesByTeamAndPlaybook() ([]sqlstore.RunsCount, error) {
+	return s.overdue, s.err
+}
+
+func scrape(t *testing.T, m *Metrics) (int, string) {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := ioutil.ReadAll(recorder.Result().Body)
+	require.NoError(t, err)
+
+	return recorder.Code, string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	t.Run("exposes counters and store gauges", func(t *testing.T) {
+		m := NewMetrics(&fakeStore{
+			runsInProgress: []sqlstore.RunsCount{
+				{TeamID: "team1", PlaybookID: "playbook1", Count: 3},
+				{TeamID: "team2", PlaybookID: "playbook2", Count: 1},
+			},
+			overdue: []sqlstore.RunsCount{
+				{TeamID: "team1", PlaybookID: "playbook1", Count: 2},
+			},
+		})
+
+		m.IncrementRunsCreated("team1", "playbook1")
+		m.IncrementRunsCreated("team1", "playbook1")
+		m.IncrementRunsFinished("team1", "playbook1")
+		m.IncrementRemindersScheduled()
+		m.IncrementWebhookFailures()
+		m.ObserveAPIRequest("/api/v0/runs/{id}", http.MethodGet, http.StatusOK, 20*time.Millisecond)
+
+		code, body := scrape(t, m)
+		require.Equal(t, http.StatusOK, code)
+		require.Contains(t, body, `playbooks_runs_in_progress{playbook_id="playbook1",team_id="team1"} 3`)
+		require.Contains(t, body, `playbooks_runs_in_progress{playbook_id="playbook2",team_id="team2"} 1`)
+		require.Contains(t, body, `playbooks_runs_status_update_overdue{playbook_id="playbook1",team_id="team1"} 2`)
+		require.Contains(t, body, `playbooks_runs_created_total{playbook_id="playbook1",team_id="team1"} 2`)
+		require.Contains(t, body, `playbooks_runs_finished_total{playbook_id="playbook1",team_id="team1"} 1`)
+		require.Contains(t, body, `playbooks_reminders_scheduled_total 1`)
+		require.Contains(t, body, `playbooks_webhook_failures_total 1`)
+		require.Contains(t, body, `playbooks_api_request_duration_seconds_count{method="GET",route="/api/v0/runs/{id}",status_code="200"} 1`)
+	})
+
+	t.Run("store errors fail the scrape", func(t *testing.T) {
+		m := NewMetrics(&fakeStore{err: errors.New("store error")})
+
+		code, _ := scrape(t, m)
+		require.Equal(t, http.StatusInternalServerError, code)
+	})
+}
@@ -0,0 +1,20 @@
+package metrics
+
+// NoopMetrics satisfies the app.PlaybookRunMetrics interface with no-op implementations.
+type NoopMetrics struct{}
+
+// IncrementRunsCreated does nothing
+func (m *NoopMetrics) IncrementRunsCreated(teamID, playbookID string) {
+}
+
+// IncrementRunsFinished does nothing
+func (m *NoopMetrics) IncrementRunsFinished(teamID, playbookID string) {
+}
+
+// IncrementRemindersScheduled does nothing
+func (m *NoopMetrics) IncrementRemindersScheduled() {
+}
+
+// IncrementWebhookFailures does nothing
+func (m *NoopMetrics) IncrementWebhookFailures() {
+}
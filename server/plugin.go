@@ -8,6 +8,8 @@ import (
 	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/command"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/config"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/httptools"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/metrics"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/sqlstore"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/telemetry"
 	"github.com/mattermost/mattermost-server/v6/model"
@@ -27,14 +29,12 @@ var (
 	rudderWriteKey     string
 )
 
-type TelemetryClient interface {
-	app.PlaybookRunTelemetry
-	app.PlaybookTelemetry
-	bot.Telemetry
-	app.UserInfoTelemetry
-	Enable() error
-	Disable() error
-}
+// Names under which the telemetry sinks are registered.
+const (
+	telemetrySinkRudder    = "rudder"
+	telemetrySinkFile      = "file"
+	telemetrySinkCollector = "collector"
+)
 
 // Plugin implements the interface expected by the Mattermost server to communicate between the
 // server and plugin processes.
@@ -48,7 +48,11 @@ type Plugin struct {
 	bot                *bot.Bot
 	pluginAPI          *pluginapi.Client
 	userInfoStore      app.UserInfoStore
-	telemetryClient    TelemetryClient
+	keywordsCacher     app.KeywordsCacher
+	keywordsIgnorer    app.KeywordsIgnorer
+	auditService       app.AuditService
+	telemetryClient    *telemetry.Telemetry
+	metrics            *metrics.Metrics
 }
 
 // ServeHTTP routes incoming HTTP requests to the plugin's REST API.
@@ -83,30 +87,38 @@ func (p *Plugin) OnActivate() error {
 		return errors.Wrapf(err, "failed save bot to config")
 	}
 
+	p.telemetryClient = telemetry.New(manifest.Version, pluginAPIClient.System.GetServerVersion())
+
+	var rudderSink *telemetry.RudderSink
 	if rudderDataplaneURL == "" || rudderWriteKey == "" {
 		pluginAPIClient.Log.Warn("Rudder credentials are not set. Disabling analytics.")
-		p.telemetryClient = &telemetry.NoopTelemetry{}
 	} else {
 		diagnosticID := pluginAPIClient.System.GetDiagnosticID()
-		serverVersion := pluginAPIClient.System.GetServerVersion()
-		p.telemetryClient, err = telemetry.NewRudder(rudderDataplaneURL, rudderWriteKey, diagnosticID, manifest.Version, serverVersion)
+		rudderSink, err = telemetry.NewRudderSink(rudderDataplaneURL, rudderWriteKey, diagnosticID)
 		if err != nil {
 			return errors.Wrapf(err, "failed init telemetry client")
 		}
+		if err = p.telemetryClient.SetSink(telemetrySinkRudder, rudderSink); err != nil {
+			return errors.Wrapf(err, "failed init telemetry client")
+		}
 	}
 
 	toggleTelemetry := func() {
+		if rudderSink == nil {
+			return
+		}
+
 		diagnosticsFlag := pluginAPIClient.Configuration.GetConfig().LogSettings.EnableDiagnostics
 		telemetryEnabled := diagnosticsFlag != nil && *diagnosticsFlag
 
 		if telemetryEnabled {
-			if err = p.telemetryClient.Enable(); err != nil {
+			if err = rudderSink.Enable(); err != nil {
 				pluginAPIClient.Log.Warn("Telemetry could not be enabled", "Error", err)
 			}
 			return
 		}
 
-		if err = p.telemetryClient.Disable(); err != nil {
+		if err = rudderSink.Disable(); err != nil {
 			pluginAPIClient.Log.Error("Telemetry could not be disabled", "Error", err)
 		}
 	}
@@ -114,6 +126,49 @@ func (p *Plugin) OnActivate() error {
 	toggleTelemetry()
 	p.config.RegisterConfigChangeListener(toggleTelemetry)
 
+	telemetryHTTPClient := httptools.MakeClient(pluginAPIClient)
+	var telemetryFilePath, telemetryCollectorURL string
+	configureTelemetrySinks := func() {
+		configuration := p.config.GetConfiguration()
+
+		if configuration.TelemetryFilePath != telemetryFilePath {
+			telemetryFilePath = configuration.TelemetryFilePath
+
+			var sink telemetry.Sink
+			if telemetryFilePath != "" {
+				fileSink, sinkErr := telemetry.NewFileSink(telemetryFilePath)
+				if sinkErr != nil {
+					pluginAPIClient.Log.Error("Telemetry file sink could not be created", "Error", sinkErr)
+				} else {
+					sink = fileSink
+				}
+			}
+			if sinkErr := p.telemetryClient.SetSink(telemetrySinkFile, sink); sinkErr != nil {
+				pluginAPIClient.Log.Warn("Telemetry file sink could not be closed", "Error", sinkErr)
+			}
+		}
+
+		if configuration.TelemetryCollectorURL != telemetryCollectorURL {
+			telemetryCollectorURL = configuration.TelemetryCollectorURL
+
+			var sink telemetry.Sink
+			if telemetryCollectorURL != "" {
+				httpSink, sinkErr := telemetry.NewHTTPSink(telemetryCollectorURL, telemetryHTTPClient)
+				if sinkErr != nil {
+					pluginAPIClient.Log.Error("Telemetry collector sink could not be created", "Error", sinkErr)
+				} else {
+					sink = httpSink
+				}
+			}
+			if sinkErr := p.telemetryClient.SetSink(telemetrySinkCollector, sink); sinkErr != nil {
+				pluginAPIClient.Log.Warn("Telemetry collector sink could not be closed", "Error", sinkErr)
+			}
+		}
+	}
+
+	configureTelemetrySinks()
+	p.config.RegisterConfigChangeListener(configureTelemetrySinks)
+
 	apiClient := sqlstore.NewClient(pluginAPIClient)
 	p.bot = bot.New(pluginAPIClient, p.config.GetConfiguration().BotUserID, p.config, p.telemetryClient)
 	sqlStore, err := sqlstore.New(apiClient, p.bot)
@@ -137,19 +192,29 @@ func (p *Plugin) OnActivate() error {
 	playbookStore := sqlstore.NewPlaybookStore(apiClient, p.bot, sqlStore)
 	statsStore := sqlstore.NewStatsStore(apiClient, p.bot, sqlStore)
 	p.userInfoStore = sqlstore.NewUserInfoStore(sqlStore)
+	p.keywordsIgnorer = app.NewKeywordsIgnorer(sqlstore.NewKeywordsIgnoreStore(sqlStore))
+	p.auditService = app.NewAuditService(sqlstore.NewAuditStore(sqlStore), p.bot)
 
 	p.handler = api.NewHandler(pluginAPIClient, p.config, p.bot)
+	p.metrics = metrics.NewMetrics(statsStore)
+	api.NewMetricsHandler(p.handler.Router, p.handler.APIRouter, p.config, p.bot, p.metrics)
 
 	scheduler := cluster.GetJobOnceScheduler(p.API)
 
+	p.keywordsCacher = app.NewPlaybookKeywordsCacher(playbookStore, p.API, pluginAPIClient.Log)
+	checklistLibrary := app.NewChecklistLibraryService(sqlstore.NewChecklistLibraryStore(sqlStore))
+	p.playbookService = app.NewPlaybookService(playbookStore, p.bot, p.telemetryClient, pluginAPIClient, p.config, p.keywordsCacher, p.keywordsIgnorer, checklistLibrary)
+
 	p.playbookRunService = app.NewPlaybookRunService(
 		pluginAPIClient,
 		playbookRunStore,
+		p.playbookService,
 		p.bot,
 		p.bot,
 		p.config,
 		scheduler,
 		p.telemetryClient,
+		p.metrics,
 		p.API,
 	)
 
@@ -160,10 +225,6 @@ func (p *Plugin) OnActivate() error {
 		pluginAPIClient.Log.Error("JobOnceScheduler could not start", "error", err.Error())
 	}
 
-	keywordsThreadIgnorer := app.NewKeywordsThreadIgnorer()
-
-	p.playbookService = app.NewPlaybookService(playbookStore, p.bot, p.telemetryClient, pluginAPIClient, p.config, keywordsThreadIgnorer)
-
 	api.NewPlaybookHandler(
 		p.handler.APIRouter,
 		p.playbookService,
@@ -183,8 +244,10 @@ func (p *Plugin) OnActivate() error {
 	api.NewStatsHandler(p.handler.APIRouter, pluginAPIClient, p.bot, statsStore, p.playbookService)
 	api.NewBotHandler(p.handler.APIRouter, pluginAPIClient, p.bot, p.bot, p.config, p.playbookRunService, p.userInfoStore)
 	api.NewTelemetryHandler(p.handler.APIRouter, p.playbookRunService, pluginAPIClient, p.bot, p.telemetryClient, p.playbookService, p.telemetryClient, p.telemetryClient)
-	api.NewSignalHandler(p.handler.APIRouter, pluginAPIClient, p.bot, p.playbookRunService, p.playbookService, keywordsThreadIgnorer)
+	api.NewSignalHandler(p.handler.APIRouter, pluginAPIClient, p.bot, p.playbookRunService, p.playbookService, p.keywordsIgnorer)
 	api.NewSettingsHandler(p.handler.APIRouter, pluginAPIClient, p.bot, p.config)
+	api.NewChecklistLibraryHandler(p.handler.APIRouter, checklistLibrary, p.playbookService, pluginAPIClient, p.bot, p.config)
+	api.NewAuditHandler(p.handler.APIRouter, p.auditService, p.playbookService, p.playbookRunService, checklistLibrary, pluginAPIClient, p.bot)
 
 	isTestingEnabled := false
 	flag := p.API.GetConfig().ServiceSettings.EnableTesting
@@ -205,6 +268,15 @@ func (p *Plugin) OnActivate() error {
 	return nil
 }
 
+// OnDeactivate flushes and closes the telemetry sinks.
+func (p *Plugin) OnDeactivate() error {
+	if p.telemetryClient == nil {
+		return nil
+	}
+
+	return p.telemetryClient.Close()
+}
+
 // OnConfigurationChange handles any change in the configuration.
 func (p *Plugin) OnConfigurationChange() error {
 	if p.config == nil {
@@ -217,7 +289,7 @@ func (p *Plugin) OnConfigurationChange() error {
 // ExecuteCommand executes a command that has been previously registered via the RegisterCommand.
 func (p *Plugin) ExecuteCommand(c *plugin.Context, args *model.CommandArgs) (*model.CommandResponse, *model.AppError) {
 	runner := command.NewCommandRunner(c, args, pluginapi.NewClient(p.API, p.Driver), p.bot, p.bot,
-		p.playbookRunService, p.playbookService, p.config, p.userInfoStore, p.telemetryClient)
+		p.playbookRunService, p.playbookService, p.config, p.userInfoStore, p.telemetryClient, p.keywordsIgnorer, p.auditService)
 
 	if err := runner.Execute(); err != nil {
 		return nil, model.NewAppError("Playbooks.ExecuteCommand", "Unable to execute command.", nil, err.Error(), http.StatusInternalServerError)
@@ -245,3 +317,11 @@ func (p *Plugin) UserHasLeftChannel(c *plugin.Context, channelMember *model.Chan
 func (p *Plugin) MessageHasBeenPosted(c *plugin.Context, post *model.Post) {
 	p.playbookService.MessageHasBeenPosted(c.SessionId, post)
 }
+
+// OnPluginClusterEvent handles the events published by the other servers in the cluster.
+func (p *Plugin) OnPluginClusterEvent(c *plugin.Context, ev model.PluginClusterEvent) {
+	switch ev.Id {
+	case app.KeywordsCacheInvalidateEventID:
+		p.keywordsCacher.InvalidateLocal()
+	}
+}
@@ -0,0 +1,163 @@
+package sqlstore
+
+import (
+	"encoding/json"
+	"math"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/pkg/errors"
+)
+
+type sqlAuditRecord struct {
+	app.AuditRecord
+	ChangesJSON json.RawMessage
+}
+
+// auditStore is a sql store for the audit log. Use NewAuditStore to create it.
+type auditStore struct {
+	store        *SQLStore
+	recordSelect sq.SelectBuilder
+}
+
+// Ensure auditStore implements the app.AuditStore interface.
+var _ app.AuditStore = (*auditStore)(nil)
+
+// NewAuditStore creates a new store for the audit log.
+func NewAuditStore(sqlStore *SQLStore) app.AuditStore {
+	recordSelect := sqlStore.builder.
+		Select("a.ID", "a.CreateAt", "a.ActorUserID", "a.Action", "a.Source", "a.TargetType",
+			"a.TargetID", "a.TeamID", "a.Details", "a.ChangesJSON").
+		From("IR_AuditLog AS a")
+
+	return &auditStore{
+		store:        sqlStore,
+		recordSelect: recordSelect,
+	}
+}
+
+// Create stores the record.
+func (s *auditStore) Create(record app.AuditRecord) error {
+	if record.ID == "" {
+		return errors.New("ID should not be empty")
+	}
+
+	changesJSON, err := json.Marshal(record.Changes)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal changes of audit record '%s'", record.ID)
+	}
+
+	_, err = s.store.execBuilder(s.store.db, sq.
+		Insert("IR_AuditLog").
+		SetMap(map[string]interface{}{
+			"ID":          record.ID,
+			"CreateAt":    record.CreateAt,
+			"ActorUserID": record.ActorUserID,
+			"Action":      record.Action,
+			"Source":      record.Source,
+			"TargetType":  record.TargetType,
+			"TargetID":    record.TargetID,
+			"TeamID":      record.TeamID,
+			"Details":     record.Details,
+			"ChangesJSON": changesJSON,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to store audit record '%s'", record.ID)
+	}
+
+	return nil
+}
+
+// GetRecords retrieves the records matching the validated options, newest first.
+func (s *auditStore) GetRecords(options app.AuditFilterOptions) (*app.GetAuditRecordsResults, error) {
+	filters := sq.And{}
+	if options.ActorUserID != "" {
+		filters = append(filters, sq.Eq{"a.ActorUserID": options.ActorUserID})
+	}
+	if options.Action != "" {
+		filters = append(filters, sq.Eq{"a.Action": options.Action})
+	}
+	if options.Source != "" {
+		filters = append(filters, sq.Eq{"a.Source": options.Source})
+	}
+	if options.TargetType != "" {
+		filters = append(filters, sq.Eq{"a.TargetType": options.TargetType})
+	}
+	if options.TargetID != "" {
+		filters = append(filters, sq.Eq{"a.TargetID": options.TargetID})
+	}
+	if options.TeamID != "" {
+		filters = append(filters, sq.Eq{"a.TeamID": options.TeamID})
+	}
+	if options.Since > 0 {
+		filters = append(filters, sq.GtOrEq{"a.CreateAt": options.Since})
+	}
+	if options.Until > 0 {
+		filters = append(filters, sq.Lt{"a.CreateAt": options.Until})
+	}
+
+	page := pagination{
+		Page:      options.Page,
+		PerPage:   options.PerPage,
+		Cursor:    options.Cursor,
+		SkipCount: options.SkipCount,
+		Sort:      app.SortByCreateAt,
+		Direction: app.DirectionDesc,
+		Column:    "a.CreateAt",
+		IDColumn:  "a.ID",
+	}
+
+	queryForResults, err := page.apply(s.recordSelect.Where(filters))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply pagination options")
+	}
+
+	var rawRecords []sqlAuditRecord
+	if err = s.store.selectBuilder(s.store.db, &rawRecords, queryForResults); err != nil {
+		return nil, errors.Wrap(err, "failed to query for audit records")
+	}
+
+	var total, pageCount int
+	if !options.SkipCount {
+		queryForTotal := s.store.builder.
+			Select("COUNT(*)").
+			From("IR_AuditLog AS a").
+			Where(filters)
+		if err = s.store.getBuilder(s.store.db, &total, queryForTotal); err != nil {
+			return nil, errors.Wrap(err, "failed to get total count")
+		}
+		if options.PerPage > 0 {
+			pageCount = int(math.Ceil(float64(total) / float64(options.PerPage)))
+		}
+	}
+
+	hasMore := options.Page+1 < pageCount
+	if page.fetchesExtra() {
+		var numItems int
+		numItems, hasMore = page.trimExtra(len(rawRecords))
+		rawRecords = rawRecords[:numItems]
+	}
+
+	records := make([]app.AuditRecord, 0, len(rawRecords))
+	for _, rawRecord := range rawRecords {
+		record := rawRecord.AuditRecord
+		if err = json.Unmarshal(rawRecord.ChangesJSON, &record.Changes); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal changes of audit record '%s'", record.ID)
+		}
+		records = append(records, record)
+	}
+
+	var nextCursor string
+	if hasMore && len(records) > 0 {
+		last := records[len(records)-1]
+		nextCursor = page.nextCursor(last.CreateAt, last.ID)
+	}
+
+	return &app.GetAuditRecordsResults{
+		TotalCount: total,
+		PageCount:  pageCount,
+		HasMore:    hasMore,
+		Items:      records,
+		NextCursor: nextCursor,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditStore(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		store := setupAuditStore(t, db)
+
+		actorID := model.NewId()
+		playbookID := model.NewId()
+		runID := model.NewId()
+		teamID := model.NewId()
+
+		records := []app.AuditRecord{
+			{
+				ID:          model.NewId(),
+				CreateAt:    1000,
+				ActorUserID: actorID,
+				Action:      "POST /playbooks",
+				Source:      app.AuditSourceAPI,
+				TargetType:  app.AuditTargetPlaybook,
+				TargetID:    playbookID,
+				TeamID:      teamID,
+				Changes:     []app.AuditChange{{Field: "title", Before: nil, After: "Playbook"}},
+			},
+			{
+				ID:          model.NewId(),
+				CreateAt:    2000,
+				ActorUserID: actorID,
+				Action:      "/playbook finish",
+				Source:      app.AuditSourceSlashCommand,
+				TargetType:  app.AuditTargetRun,
+				TargetID:    runID,
+				TeamID:      teamID,
+				Changes:     []app.AuditChange{{Field: "current_status", Before: "InProgress", After: "Finished"}},
+			},
+			{
+				ID:          model.NewId(),
+				CreateAt:    3000,
+				ActorUserID: model.NewId(),
+				Action:      app.AuditActionPermissionDenied,
+				Source:      app.AuditSourceAPI,
+				TargetType:  app.AuditTargetPlaybook,
+				TargetID:    playbookID,
+				TeamID:      teamID,
+				Details:     "DELETE /playbooks/{id}",
+				Changes:     []app.AuditChange{},
+			},
+		}
+		for _, record := range records {
+			require.NoError(t, store.Create(record))
+		}
+
+		t.Run("newest first", func(t *testing.T) {
+			results, err := store.GetRecords(app.AuditFilterOptions{TeamID: teamID, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, 3, results.TotalCount)
+			require.Equal(t, 1, results.PageCount)
+			require.False(t, results.HasMore)
+			require.Equal(t, []app.AuditRecord{records[2], records[1], records[0]}, results.Items)
+		})
+
+		t.Run("filters", func(t *testing.T) {
+			results, err := store.GetRecords(app.AuditFilterOptions{ActorUserID: actorID, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []app.AuditRecord{records[1], records[0]}, results.Items)
+
+			results, err = store.GetRecords(app.AuditFilterOptions{TargetID: playbookID, Action: app.AuditActionPermissionDenied, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []app.AuditRecord{records[2]}, results.Items)
+
+			results, err = store.GetRecords(app.AuditFilterOptions{TeamID: teamID, Source: app.AuditSourceSlashCommand, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []app.AuditRecord{records[1]}, results.Items)
+
+			results, err = store.GetRecords(app.AuditFilterOptions{TeamID: teamID, Since: 2000, Until: 3000, PerPage: 10})
+			require.NoError(t, err)
+			require.Equal(t, []app.AuditRecord{records[1]}, results.Items)
+		})
+
+		t.Run("cursor", func(t *testing.T) {
+			options := app.AuditFilterOptions{TeamID: teamID, PerPage: 2, SkipCount: true}
+
+			results, err := store.GetRecords(options)
+			require.NoError(t, err)
+			require.Equal(t, 0, results.TotalCount)
+			require.True(t, results.HasMore)
+			require.Equal(t, []app.AuditRecord{records[2], records[1]}, results.Items)
+			require.NotEmpty(t, results.NextCursor)
+
+			options.Cursor = results.NextCursor
+			results, err = store.GetRecords(options)
+			require.NoError(t, err)
+			require.False(t, results.HasMore)
+			require.Equal(t, []app.AuditRecord{records[0]}, results.Items)
+			require.Empty(t, results.NextCursor)
+		})
+	}
+}
+
+func setupAuditStore(t *testing.T, db *sqlx.DB) app.AuditStore {
+	sqlStore := setupSQLStoreForUserInfo(t, db)
+
+	return NewAuditStore(sqlStore)
+}
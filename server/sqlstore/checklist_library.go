@@ -0,0 +1,298 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+type sqlChecklistLibraryEntry struct {
+	app.ChecklistLibraryEntry
+	ItemsJSON json.RawMessage
+}
+
+type sqlChecklistLibraryVersion struct {
+	app.ChecklistLibraryVersion
+	ItemsJSON json.RawMessage
+}
+
+// checklistLibraryStore is a sql store for the checklist libraries. Use NewChecklistLibraryStore
+// to create it.
+type checklistLibraryStore struct {
+	store         *SQLStore
+	entrySelect   sq.SelectBuilder
+	versionSelect sq.SelectBuilder
+}
+
+// Ensure checklistLibraryStore implements the app.ChecklistLibraryStore interface.
+var _ app.ChecklistLibraryStore = (*checklistLibraryStore)(nil)
+
+// NewChecklistLibraryStore creates a new store for the checklist libraries.
+func NewChecklistLibraryStore(sqlStore *SQLStore) app.ChecklistLibraryStore {
+	entrySelect := sqlStore.builder.
+		Select("l.ID", "l.TeamID", "l.Title", "l.Description", "l.Version", "l.ItemsJSON",
+			"l.CreateAt", "l.UpdateAt", "l.DeleteAt").
+		From("IR_ChecklistLibrary AS l")
+
+	versionSelect := sqlStore.builder.
+		Select("v.EntryID", "v.Version", "v.Title", "v.ItemsJSON", "v.UserID", "v.CreateAt").
+		From("IR_ChecklistLibraryVersion AS v")
+
+	return &checklistLibraryStore{
+		store:         sqlStore,
+		entrySelect:   entrySelect,
+		versionSelect: versionSelect,
+	}
+}
+
+// Create stores a new entry with its first version, returning its ID.
+func (s *checklistLibraryStore) Create(entry app.ChecklistLibraryEntry, userID string) (string, error) {
+	if entry.ID != "" {
+		return "", errors.New("ID should be empty")
+	}
+	entry.ID = model.NewId()
+
+	itemsJSON, err := marshalLibraryItems(entry.Items)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return "", errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	_, err = s.store.execBuilder(tx, sq.
+		Insert("IR_ChecklistLibrary").
+		SetMap(map[string]interface{}{
+			"ID":          entry.ID,
+			"TeamID":      entry.TeamID,
+			"Title":       entry.Title,
+			"Description": entry.Description,
+			"Version":     entry.Version,
+			"ItemsJSON":   itemsJSON,
+			"CreateAt":    entry.CreateAt,
+			"UpdateAt":    entry.UpdateAt,
+			"DeleteAt":    0,
+		}))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to store checklist library entry '%s'", entry.ID)
+	}
+
+	if err = s.insertVersion(tx, entry.ID, entry.Version, entry.Title, itemsJSON, userID, entry.CreateAt); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", errors.Wrap(err, "could not commit transaction")
+	}
+
+	return entry.ID, nil
+}
+
+// Get retrieves an entry, archived or not. Returns ErrNotFound if not found.
+func (s *checklistLibraryStore) Get(id string) (app.ChecklistLibraryEntry, error) {
+	if id == "" {
+		return app.ChecklistLibraryEntry{}, errors.New("ID cannot be empty")
+	}
+
+	var rawEntry sqlChecklistLibraryEntry
+	err := s.store.getBuilder(s.store.db, &rawEntry, s.entrySelect.Where(sq.Eq{"l.ID": id}))
+	if err == sql.ErrNoRows {
+		return app.ChecklistLibraryEntry{}, errors.Wrapf(app.ErrNotFound, "checklist library entry does not exist for id '%s'", id)
+	} else if err != nil {
+		return app.ChecklistLibraryEntry{}, errors.Wrapf(err, "failed to get checklist library entry by id '%s'", id)
+	}
+
+	return toChecklistLibraryEntry(rawEntry)
+}
+
+// GetForTeam retrieves the entries of the library of a team that are not archived, sorted by
+// title.
+func (s *checklistLibraryStore) GetForTeam(teamID string) ([]app.ChecklistLibraryEntry, error) {
+	var rawEntries []sqlChecklistLibraryEntry
+	err := s.store.selectBuilder(s.store.db, &rawEntries, s.entrySelect.
+		Where(sq.Eq{"l.TeamID": teamID, "l.DeleteAt": 0}).
+		OrderBy("l.Title ASC", "l.ID ASC"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the checklist library of team '%s'", teamID)
+	}
+
+	entries := make([]app.ChecklistLibraryEntry, 0, len(rawEntries))
+	for _, rawEntry := range rawEntries {
+		entry, err := toChecklistLibraryEntry(rawEntry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Update stores the title, description and items of an entry as a new version, returning its
+// number.
+func (s *checklistLibraryStore) Update(entry app.ChecklistLibraryEntry, userID string) (int, error) {
+	if entry.ID == "" {
+		return 0, errors.New("ID cannot be empty")
+	}
+
+	itemsJSON, err := marshalLibraryItems(entry.Items)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	// Incrementing the version first locks the entry until the new version is stored.
+	result, err := s.store.execBuilder(tx, sq.
+		Update("IR_ChecklistLibrary").
+		SetMap(map[string]interface{}{
+			"Title":       entry.Title,
+			"Description": entry.Description,
+			"ItemsJSON":   itemsJSON,
+			"Version":     sq.Expr("Version + 1"),
+			"UpdateAt":    entry.UpdateAt,
+		}).
+		Where(sq.Eq{"ID": entry.ID, "DeleteAt": 0}))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to update checklist library entry '%s'", entry.ID)
+	}
+	if rows, rowsErr := result.RowsAffected(); rowsErr == nil && rows == 0 {
+		return 0, errors.Wrapf(app.ErrNotFound, "checklist library entry does not exist for id '%s'", entry.ID)
+	}
+
+	var version int
+	err = s.store.getBuilder(tx, &version, s.store.builder.
+		Select("Version").
+		From("IR_ChecklistLibrary").
+		Where(sq.Eq{"ID": entry.ID}))
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get the version of checklist library entry '%s'", entry.ID)
+	}
+
+	if err = s.insertVersion(tx, entry.ID, version, entry.Title, itemsJSON, userID, entry.UpdateAt); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "could not commit transaction")
+	}
+
+	return version, nil
+}
+
+// Delete archives an entry.
+func (s *checklistLibraryStore) Delete(id string) error {
+	if id == "" {
+		return errors.New("ID cannot be empty")
+	}
+
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Update("IR_ChecklistLibrary").
+		Set("DeleteAt", model.GetMillis()).
+		Where(sq.Eq{"ID": id}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete checklist library entry with id '%s'", id)
+	}
+
+	return nil
+}
+
+// GetVersion retrieves a version of an entry. Returns ErrNotFound if not found.
+func (s *checklistLibraryStore) GetVersion(id string, version int) (app.ChecklistLibraryVersion, error) {
+	var rawVersion sqlChecklistLibraryVersion
+	err := s.store.getBuilder(s.store.db, &rawVersion, s.versionSelect.
+		Where(sq.Eq{"v.EntryID": id, "v.Version": version}))
+	if err == sql.ErrNoRows {
+		return app.ChecklistLibraryVersion{}, errors.Wrapf(app.ErrNotFound, "version %d of checklist library entry '%s' does not exist", version, id)
+	} else if err != nil {
+		return app.ChecklistLibraryVersion{}, errors.Wrapf(err, "failed to get version %d of checklist library entry '%s'", version, id)
+	}
+
+	return toChecklistLibraryVersion(rawVersion)
+}
+
+// GetVersions retrieves all the versions of an entry, newest first.
+func (s *checklistLibraryStore) GetVersions(id string) ([]app.ChecklistLibraryVersion, error) {
+	var rawVersions []sqlChecklistLibraryVersion
+	err := s.store.selectBuilder(s.store.db, &rawVersions, s.versionSelect.
+		Where(sq.Eq{"v.EntryID": id}).
+		OrderBy("v.Version DESC"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the versions of checklist library entry '%s'", id)
+	}
+
+	versions := make([]app.ChecklistLibraryVersion, 0, len(rawVersions))
+	for _, rawVersion := range rawVersions {
+		version, err := toChecklistLibraryVersion(rawVersion)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, nil
+}
+
+func (s *checklistLibraryStore) insertVersion(e execer, id string, version int, title string, itemsJSON json.RawMessage, userID string, createAt int64) error {
+	_, err := s.store.execBuilder(e, sq.
+		Insert("IR_ChecklistLibraryVersion").
+		SetMap(map[string]interface{}{
+			"EntryID":   id,
+			"Version":   version,
+			"Title":     title,
+			"ItemsJSON": itemsJSON,
+			"UserID":    userID,
+			"CreateAt":  createAt,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to store version %d of checklist library entry '%s'", version, id)
+	}
+
+	return nil
+}
+
+func marshalLibraryItems(items []app.ChecklistItem) (json.RawMessage, error) {
+	if items == nil {
+		items = []app.ChecklistItem{}
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal checklist library items")
+	}
+
+	return itemsJSON, nil
+}
+
+func toChecklistLibraryEntry(rawEntry sqlChecklistLibraryEntry) (app.ChecklistLibraryEntry, error) {
+	entry := rawEntry.ChecklistLibraryEntry
+	if len(rawEntry.ItemsJSON) > 0 {
+		if err := json.Unmarshal(rawEntry.ItemsJSON, &entry.Items); err != nil {
+			return app.ChecklistLibraryEntry{}, errors.Wrapf(err, "failed to unmarshal items of checklist library entry '%s'", entry.ID)
+		}
+	}
+
+	return entry, nil
+}
+
+func toChecklistLibraryVersion(rawVersion sqlChecklistLibraryVersion) (app.ChecklistLibraryVersion, error) {
+	version := rawVersion.ChecklistLibraryVersion
+	if len(rawVersion.ItemsJSON) > 0 {
+		if err := json.Unmarshal(rawVersion.ItemsJSON, &version.Items); err != nil {
+			return app.ChecklistLibraryVersion{}, errors.Wrapf(err, "failed to unmarshal items of version %d of checklist library entry '%s'", version.Version, version.EntryID)
+		}
+	}
+
+	return version, nil
+}
@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecklistLibraryStore(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		store := setupChecklistLibraryStore(t, db)
+
+		userID := model.NewId()
+
+		t.Run("create, update and get versions", func(t *testing.T) {
+			teamID := model.NewId()
+
+			entry := app.ChecklistLibraryEntry{
+				TeamID:      teamID,
+				Title:       "Triage",
+				Description: "The first steps",
+				Items:       []app.ChecklistItem{{Title: "Page on-call"}},
+				Version:     1,
+				CreateAt:    1000,
+				UpdateAt:    1000,
+			}
+			id, err := store.Create(entry, userID)
+			require.NoError(t, err)
+			entry.ID = id
+
+			actual, err := store.Get(id)
+			require.NoError(t, err)
+			require.Equal(t, entry, actual)
+
+			entry.Title = "Triage v2"
+			entry.Items = append(entry.Items, app.ChecklistItem{Title: "Open incident doc"})
+			entry.UpdateAt = 2000
+			version, err := store.Update(entry, userID)
+			require.NoError(t, err)
+			require.Equal(t, 2, version)
+			entry.Version = 2
+
+			actual, err = store.Get(id)
+			require.NoError(t, err)
+			require.Equal(t, entry, actual)
+
+			first, err := store.GetVersion(id, 1)
+			require.NoError(t, err)
+			require.Equal(t, app.ChecklistLibraryVersion{
+				EntryID:  id,
+				Version:  1,
+				Title:    "Triage",
+				Items:    []app.ChecklistItem{{Title: "Page on-call"}},
+				UserID:   userID,
+				CreateAt: 1000,
+			}, first)
+
+			versions, err := store.GetVersions(id)
+			require.NoError(t, err)
+			require.Len(t, versions, 2)
+			require.Equal(t, 2, versions[0].Version)
+			require.Equal(t, entry.Items, versions[0].Items)
+
+			_, err = store.GetVersion(id, 3)
+			require.True(t, errors.Is(err, app.ErrNotFound))
+		})
+
+		t.Run("archived entries are kept but not listed or updated", func(t *testing.T) {
+			teamID := model.NewId()
+
+			id, err := store.Create(app.ChecklistLibraryEntry{TeamID: teamID, Title: "B", Version: 1}, userID)
+			require.NoError(t, err)
+			otherID, err := store.Create(app.ChecklistLibraryEntry{TeamID: teamID, Title: "A", Version: 1}, userID)
+			require.NoError(t, err)
+			_, err = store.Create(app.ChecklistLibraryEntry{TeamID: model.NewId(), Title: "C", Version: 1}, userID)
+			require.NoError(t, err)
+
+			entries, err := store.GetForTeam(teamID)
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			require.Equal(t, otherID, entries[0].ID)
+			require.Equal(t, id, entries[1].ID)
+
+			err = store.Delete(id)
+			require.NoError(t, err)
+
+			entries, err = store.GetForTeam(teamID)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, otherID, entries[0].ID)
+
+			archived, err := store.Get(id)
+			require.NoError(t, err)
+			require.NotZero(t, archived.DeleteAt)
+
+			_, err = store.Update(archived, userID)
+			require.True(t, errors.Is(err, app.ErrNotFound))
+		})
+
+		t.Run("get unknown entry", func(t *testing.T) {
+			_, err := store.Get(model.NewId())
+			require.True(t, errors.Is(err, app.ErrNotFound))
+		})
+	}
+}
+
+func setupChecklistLibraryStore(t *testing.T, db *sqlx.DB) app.ChecklistLibraryStore {
+	sqlStore := setupSQLStoreForUserInfo(t, db)
+
+	return NewChecklistLibraryStore(sqlStore)
+}
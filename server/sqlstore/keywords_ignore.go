@@ -0,0 +1,138 @@
+package sqlstore
+
+import (
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+// keywordsIgnoreStore is a sql store for keywords ignore rules. Use NewKeywordsIgnoreStore to create it.
+type keywordsIgnoreStore struct {
+	store      *SQLStore
+	ruleSelect sq.SelectBuilder
+}
+
+// Ensure keywordsIgnoreStore implements the app.KeywordsIgnoreStore interface.
+var _ app.KeywordsIgnoreStore = (*keywordsIgnoreStore)(nil)
+
+// NewKeywordsIgnoreStore creates a new store for keywords ignore rules.
+func NewKeywordsIgnoreStore(sqlStore *SQLStore) app.KeywordsIgnoreStore {
+	ruleSelect := sqlStore.builder.
+		Select("UserID", "Scope", "ScopeID", "CreateAt", "ExpireAt").
+		From("IR_KeywordsIgnore")
+
+	return &keywordsIgnoreStore{
+		store:      sqlStore,
+		ruleSelect: ruleSelect,
+	}
+}
+
+// notExpired matches the rules that still apply at now.
+func notExpired(now int64) sq.Or {
+	return sq.Or{
+		sq.Eq{"ExpireAt": 0},
+		sq.Gt{"ExpireAt": now},
+	}
+}
+
+// Upsert creates the rule, or replaces the rule with the same user, scope and scope ID.
+func (s *keywordsIgnoreStore) Upsert(rule app.KeywordsIgnoreRule) error {
+	insert := sq.Insert("IR_KeywordsIgnore").
+		Columns("UserID", "Scope", "ScopeID", "CreateAt", "ExpireAt").
+		Values(rule.UserID, rule.Scope, rule.ScopeID, rule.CreateAt, rule.ExpireAt)
+
+	var err error
+	if s.store.db.DriverName() == model.DatabaseDriverMysql {
+		_, err = s.store.execBuilder(s.store.db,
+			insert.Suffix("ON DUPLICATE KEY UPDATE CreateAt = ?, ExpireAt = ?", rule.CreateAt, rule.ExpireAt))
+	} else {
+		_, err = s.store.execBuilder(s.store.db,
+			insert.Suffix("ON CONFLICT (UserID, Scope, ScopeID) DO UPDATE SET CreateAt = ?, ExpireAt = ?", rule.CreateAt, rule.ExpireAt))
+	}
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to upsert keywords ignore rule for user '%s' with scope '%s'", rule.UserID, rule.Scope)
+	}
+
+	return nil
+}
+
+// Delete removes the rule with the given user, scope and scope ID, if any.
+func (s *keywordsIgnoreStore) Delete(userID, scope, scopeID string) error {
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Delete("IR_KeywordsIgnore").
+		Where(sq.Eq{
+			"UserID":  userID,
+			"Scope":   scope,
+			"ScopeID": scopeID,
+		}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete keywords ignore rule for user '%s' with scope '%s'", userID, scope)
+	}
+
+	return nil
+}
+
+// GetForUser retrieves the rules of userID that have not expired by now.
+func (s *keywordsIgnoreStore) GetForUser(userID string, now int64) ([]app.KeywordsIgnoreRule, error) {
+	var rules []app.KeywordsIgnoreRule
+	err := s.store.selectBuilder(s.store.db, &rules, s.ruleSelect.
+		Where(sq.Eq{"UserID": userID}).
+		Where(notExpired(now)).
+		OrderBy("CreateAt ASC"))
+	if err == sql.ErrNoRows {
+		return []app.KeywordsIgnoreRule{}, nil
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get keywords ignore rules for user '%s'", userID)
+	}
+
+	if rules == nil {
+		rules = []app.KeywordsIgnoreRule{}
+	}
+
+	return rules, nil
+}
+
+// IsIgnored checks whether any rule that has not expired by now applies to a post by userID in
+// channelID, in the thread rooted at rootID.
+func (s *keywordsIgnoreStore) IsIgnored(userID, channelID, rootID string, now int64) (bool, error) {
+	userScopes := sq.Or{
+		sq.Eq{"Scope": app.KeywordsIgnoreScopeGlobal},
+		sq.Eq{"Scope": app.KeywordsIgnoreScopeChannel, "ScopeID": channelID},
+	}
+	if rootID != "" {
+		userScopes = append(userScopes, sq.Eq{"Scope": app.KeywordsIgnoreScopeThread, "ScopeID": rootID})
+	}
+
+	query := s.store.builder.
+		Select("COUNT(*)").
+		From("IR_KeywordsIgnore").
+		Where(notExpired(now)).
+		Where(sq.Or{
+			sq.And{sq.Eq{"UserID": userID}, userScopes},
+			sq.Eq{"UserID": "", "Scope": app.KeywordsIgnoreScopeChannel, "ScopeID": channelID},
+		})
+
+	var count int
+	if err := s.store.getBuilder(s.store.db, &count, query); err != nil {
+		return false, errors.Wrapf(err, "failed to check keywords ignore rules for user '%s'", userID)
+	}
+
+	return count > 0, nil
+}
+
+// DeleteExpired removes every rule that expired before now.
+func (s *keywordsIgnoreStore) DeleteExpired(now int64) error {
+	_, err := s.store.execBuilder(s.store.db, sq.
+		Delete("IR_KeywordsIgnore").
+		Where(sq.Gt{"ExpireAt": 0}).
+		Where(sq.LtOrEq{"ExpireAt": now}))
+	if err != nil {
+		return errors.Wrap(err, "failed to delete expired keywords ignore rules")
+	}
+
+	return nil
+}
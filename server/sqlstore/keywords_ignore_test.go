@@ -0,0 +1,132 @@
+package sqlstore
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeywordsIgnoreStore(t *testing.T) {
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		store := setupKeywordsIgnoreStore(t, db)
+
+		now := model.GetMillis()
+
+		t.Run("thread rules only apply to their thread", func(t *testing.T) {
+			userID := model.NewId()
+			channelID := model.NewId()
+			rootID := model.NewId()
+
+			err := store.Upsert(app.KeywordsIgnoreRule{UserID: userID, Scope: app.KeywordsIgnoreScopeThread, ScopeID: rootID, CreateAt: now})
+			require.NoError(t, err)
+
+			ignored, err := store.IsIgnored(userID, channelID, rootID, now)
+			require.NoError(t, err)
+			require.True(t, ignored)
+
+			ignored, err = store.IsIgnored(userID, channelID, "", now)
+			require.NoError(t, err)
+			require.False(t, ignored)
+
+			ignored, err = store.IsIgnored(model.NewId(), channelID, rootID, now)
+			require.NoError(t, err)
+			require.False(t, ignored)
+		})
+
+		t.Run("channel and global rules", func(t *testing.T) {
+			userID := model.NewId()
+			channelID := model.NewId()
+
+			err := store.Upsert(app.KeywordsIgnoreRule{UserID: userID, Scope: app.KeywordsIgnoreScopeChannel, ScopeID: channelID, CreateAt: now})
+			require.NoError(t, err)
+
+			ignored, err := store.IsIgnored(userID, channelID, "", now)
+			require.NoError(t, err)
+			require.True(t, ignored)
+
+			ignored, err = store.IsIgnored(userID, model.NewId(), "", now)
+			require.NoError(t, err)
+			require.False(t, ignored)
+
+			err = store.Upsert(app.KeywordsIgnoreRule{UserID: userID, Scope: app.KeywordsIgnoreScopeGlobal, CreateAt: now})
+			require.NoError(t, err)
+
+			ignored, err = store.IsIgnored(userID, model.NewId(), "", now)
+			require.NoError(t, err)
+			require.True(t, ignored)
+
+			rules, err := store.GetForUser(userID, now)
+			require.NoError(t, err)
+			require.Len(t, rules, 2)
+
+			err = store.Delete(userID, app.KeywordsIgnoreScopeGlobal, "")
+			require.NoError(t, err)
+
+			rules, err = store.GetForUser(userID, now)
+			require.NoError(t, err)
+			require.Len(t, rules, 1)
+			require.Equal(t, app.KeywordsIgnoreScopeChannel, rules[0].Scope)
+		})
+
+		t.Run("muted channels apply to everyone", func(t *testing.T) {
+			channelID := model.NewId()
+
+			err := store.Upsert(app.KeywordsIgnoreRule{Scope: app.KeywordsIgnoreScopeChannel, ScopeID: channelID, CreateAt: now})
+			require.NoError(t, err)
+
+			ignored, err := store.IsIgnored(model.NewId(), channelID, "", now)
+			require.NoError(t, err)
+			require.True(t, ignored)
+		})
+
+		t.Run("expired rules are ignored and deleted", func(t *testing.T) {
+			userID := model.NewId()
+			channelID := model.NewId()
+
+			err := store.Upsert(app.KeywordsIgnoreRule{UserID: userID, Scope: app.KeywordsIgnoreScopeChannel, ScopeID: channelID, CreateAt: now - 2000, ExpireAt: now - 1000})
+			require.NoError(t, err)
+
+			ignored, err := store.IsIgnored(userID, channelID, "", now)
+			require.NoError(t, err)
+			require.False(t, ignored)
+
+			ignored, err = store.IsIgnored(userID, channelID, "", now-1500)
+			require.NoError(t, err)
+			require.True(t, ignored)
+
+			err = store.DeleteExpired(now)
+			require.NoError(t, err)
+
+			ignored, err = store.IsIgnored(userID, channelID, "", now-1500)
+			require.NoError(t, err)
+			require.False(t, ignored)
+		})
+
+		t.Run("upsert replaces the expiry", func(t *testing.T) {
+			userID := model.NewId()
+			channelID := model.NewId()
+
+			rule := app.KeywordsIgnoreRule{UserID: userID, Scope: app.KeywordsIgnoreScopeChannel, ScopeID: channelID, CreateAt: now, ExpireAt: now + 1000}
+			err := store.Upsert(rule)
+			require.NoError(t, err)
+
+			rule.ExpireAt = 0
+			err = store.Upsert(rule)
+			require.NoError(t, err)
+
+			rules, err := store.GetForUser(userID, now+2000)
+			require.NoError(t, err)
+			require.Equal(t, []app.KeywordsIgnoreRule{rule}, rules)
+		})
+	}
+}
+
+func setupKeywordsIgnoreStore(t *testing.T, db *sqlx.DB) app.KeywordsIgnoreStore {
+	sqlStore := setupSQLStoreForUserInfo(t, db)
+
+	return NewKeywordsIgnoreStore(sqlStore)
+}
@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"encoding/json"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/blang/semver"
@@ -1356,4 +1357,471 @@ var migrations = []Migration{
 			return dropIndexIfExists(e, sqlStore, "IR_ViewedChannel", "IR_ViewedChannel_ChannelID_UserID")
 		},
 	},
+	{
+		fromVersion: semver.MustParse("0.36.0"),
+		toVersion:   semver.MustParse("0.37.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_KeywordsIgnore
+					(
+						UserID   VARCHAR(26) NOT NULL,
+						Scope    VARCHAR(32) NOT NULL,
+						ScopeID  VARCHAR(26) NOT NULL,
+						CreateAt BIGINT      NOT NULL,
+						ExpireAt BIGINT      NOT NULL DEFAULT 0,
+						PRIMARY KEY (UserID, Scope, ScopeID),
+						INDEX IR_KeywordsIgnore_ExpireAt (ExpireAt)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_KeywordsIgnore")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_KeywordsIgnore
+					(
+						UserID   TEXT   NOT NULL,
+						Scope    TEXT   NOT NULL,
+						ScopeID  TEXT   NOT NULL,
+						CreateAt BIGINT NOT NULL,
+						ExpireAt BIGINT NOT NULL DEFAULT 0,
+						PRIMARY KEY (UserID, Scope, ScopeID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_KeywordsIgnore")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_KeywordsIgnore_ExpireAt", "IR_KeywordsIgnore", "ExpireAt")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_KeywordsIgnore_ExpireAt")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.37.0"),
+		toVersion:   semver.MustParse("0.38.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_SearchDocument
+					(
+						IncidentID VARCHAR(26) NOT NULL,
+						Source     VARCHAR(32) NOT NULL,
+						SourceID   VARCHAR(32) NOT NULL,
+						Content    MEDIUMTEXT  NOT NULL,
+						UpdateAt   BIGINT      NOT NULL,
+						PRIMARY KEY (IncidentID, Source, SourceID),
+						FULLTEXT INDEX IR_SearchDocument_Content (Content)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_SearchDocument")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_SearchDocument
+					(
+						IncidentID TEXT   NOT NULL,
+						Source     TEXT   NOT NULL,
+						SourceID   TEXT   NOT NULL,
+						Content    TEXT   NOT NULL,
+						UpdateAt   BIGINT NOT NULL,
+						PRIMARY KEY (IncidentID, Source, SourceID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_SearchDocument")
+				}
+
+				if _, err := e.Exec(`
+					DO
+					$$
+					BEGIN
+						IF to_regclass('IR_SearchDocument_Content') IS NULL THEN
+							CREATE INDEX IR_SearchDocument_Content ON IR_SearchDocument USING GIN (to_tsvector('` + searchTextConfig + `', Content));
+						END IF;
+					END
+					$$;
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_SearchDocument_Content")
+				}
+			}
+
+			var playbookRunIDs []string
+			if err := sqlStore.selectBuilder(e, &playbookRunIDs, sqlStore.builder.Select("ID").From("IR_Incident")); err != nil {
+				return errors.Wrapf(err, "failed getting the playbook runs to index")
+			}
+
+			for _, playbookRunID := range playbookRunIDs {
+				if err := sqlStore.indexPlaybookRunForSearch(e, playbookRunID); err != nil {
+					return errors.Wrapf(err, "failed indexing playbook run %s", playbookRunID)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.38.0"),
+		toVersion:   semver.MustParse("0.39.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if err := addColumnToMySQLTable(e, "IR_Incident", "ConcatenatedAssigneeIDs", "TEXT"); err != nil {
+					return errors.Wrapf(err, "failed adding column ConcatenatedAssigneeIDs to table IR_Incident")
+				}
+				if _, err := e.Exec("UPDATE IR_Incident SET ConcatenatedAssigneeIDs = '' WHERE ConcatenatedAssigneeIDs IS NULL"); err != nil {
+					return errors.Wrapf(err, "failed setting default value in column ConcatenatedAssigneeIDs of table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "OpenItemCount", "INT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column OpenItemCount to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Incident", "ConcatenatedAssigneeIDs", "TEXT DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column ConcatenatedAssigneeIDs to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "OpenItemCount", "INT NOT NULL DEFAULT 0"); err != nil {
+					return errors.Wrapf(err, "failed adding column OpenItemCount to table IR_Incident")
+				}
+			}
+
+			getPlaybookRunsQuery := sqlStore.builder.
+				Select("ID", "ChecklistsJSON").
+				From("IR_Incident")
+
+			var playbookRuns []struct {
+				ID             string
+				ChecklistsJSON json.RawMessage
+			}
+			if err := sqlStore.selectBuilder(e, &playbookRuns, getPlaybookRunsQuery); err != nil {
+				return errors.Wrapf(err, "failed getting playbook runs to update their assignees and open items")
+			}
+
+			for _, playbookRun := range playbookRuns {
+				var checklists []app.Checklist
+				if err := json.Unmarshal(playbookRun.ChecklistsJSON, &checklists); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal checklists json for playbook run id: '%s'", playbookRun.ID)
+				}
+
+				assigneeIDs, openItemCount := checklistsAssigneesAndOpenItems(checklists)
+
+				playbookRunUpdate := sqlStore.builder.
+					Update("IR_Incident").
+					Set("ConcatenatedAssigneeIDs", strings.Join(assigneeIDs, ",")).
+					Set("OpenItemCount", openItemCount).
+					Where(sq.Eq{"ID": playbookRun.ID})
+
+				if _, err := sqlStore.execBuilder(e, playbookRunUpdate); err != nil {
+					return errors.Wrapf(err, "failed updating the assignees and open items of playbook run '%s'", playbookRun.ID)
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.39.0"),
+		toVersion:   semver.MustParse("0.40.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_AuditLog
+					(
+						ID          VARCHAR(26)  NOT NULL,
+						CreateAt    BIGINT       NOT NULL,
+						ActorUserID VARCHAR(26)  NOT NULL DEFAULT '',
+						Action      VARCHAR(256) NOT NULL DEFAULT '',
+						Source      VARCHAR(32)  NOT NULL DEFAULT '',
+						TargetType  VARCHAR(32)  NOT NULL DEFAULT '',
+						TargetID    VARCHAR(26)  NOT NULL DEFAULT '',
+						TeamID      VARCHAR(26)  NOT NULL DEFAULT '',
+						Details     TEXT,
+						ChangesJSON JSON,
+						PRIMARY KEY (ID),
+						INDEX IR_AuditLog_CreateAt (CreateAt),
+						INDEX IR_AuditLog_ActorUserID (ActorUserID),
+						INDEX IR_AuditLog_TargetID (TargetID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_AuditLog")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_AuditLog
+					(
+						ID          TEXT PRIMARY KEY,
+						CreateAt    BIGINT NOT NULL,
+						ActorUserID TEXT   NOT NULL DEFAULT '',
+						Action      TEXT   NOT NULL DEFAULT '',
+						Source      TEXT   NOT NULL DEFAULT '',
+						TargetType  TEXT   NOT NULL DEFAULT '',
+						TargetID    TEXT   NOT NULL DEFAULT '',
+						TeamID      TEXT   NOT NULL DEFAULT '',
+						Details     TEXT   NOT NULL DEFAULT '',
+						ChangesJSON JSON
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_AuditLog")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_AuditLog_CreateAt", "IR_AuditLog", "CreateAt")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_AuditLog_CreateAt")
+				}
+				if _, err := e.Exec(createPGIndex("IR_AuditLog_ActorUserID", "IR_AuditLog", "ActorUserID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_AuditLog_ActorUserID")
+				}
+				if _, err := e.Exec(createPGIndex("IR_AuditLog_TargetID", "IR_AuditLog", "TargetID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_AuditLog_TargetID")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.40.0"),
+		toVersion:   semver.MustParse("0.41.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			// The existing members could edit the playbook, so they become editors.
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if err := addColumnToMySQLTable(e, "IR_PlaybookMember", "Role", "VARCHAR(32) NOT NULL DEFAULT 'editor'"); err != nil {
+					return errors.Wrapf(err, "failed adding column Role to table IR_PlaybookMember")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_PlaybookMember", "Role", "TEXT NOT NULL DEFAULT 'editor'"); err != nil {
+					return errors.Wrapf(err, "failed adding column Role to table IR_PlaybookMember")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.41.0"),
+		toVersion:   semver.MustParse("0.42.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookMemberGroup
+					(
+						PlaybookID VARCHAR(26) NOT NULL REFERENCES IR_Playbook(ID),
+						Type       VARCHAR(32) NOT NULL,
+						GroupID    VARCHAR(26) NOT NULL,
+						Role       VARCHAR(32) NOT NULL,
+						PRIMARY KEY (PlaybookID, Type, GroupID),
+						INDEX IR_PlaybookMemberGroup_GroupID (GroupID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookMemberGroup")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookMemberGroup
+					(
+						PlaybookID TEXT NOT NULL REFERENCES IR_Playbook(ID),
+						Type       TEXT NOT NULL,
+						GroupID    TEXT NOT NULL,
+						Role       TEXT NOT NULL,
+						PRIMARY KEY (PlaybookID, Type, GroupID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookMemberGroup")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_PlaybookMemberGroup_GroupID", "IR_PlaybookMemberGroup", "GroupID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_PlaybookMemberGroup_GroupID")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.42.0"),
+		toVersion:   semver.MustParse("0.43.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			// A missing policy lets every member of the run channel act on the run, as before.
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "RunPermissionsJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column RunPermissionsJSON to table IR_Playbook")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "RunPermissionsJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column RunPermissionsJSON to table IR_Playbook")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.43.0"),
+		toVersion:   semver.MustParse("0.44.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "SharedWithAllTeams", "BOOLEAN DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column SharedWithAllTeams to table IR_Playbook")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookTeam
+					(
+						PlaybookID VARCHAR(26) NOT NULL REFERENCES IR_Playbook(ID),
+						TeamID     VARCHAR(26) NOT NULL,
+						PRIMARY KEY (PlaybookID, TeamID),
+						INDEX IR_PlaybookTeam_TeamID (TeamID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookTeam")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "SharedWithAllTeams", "BOOLEAN DEFAULT FALSE"); err != nil {
+					return errors.Wrapf(err, "failed adding column SharedWithAllTeams to table IR_Playbook")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_PlaybookTeam
+					(
+						PlaybookID TEXT NOT NULL REFERENCES IR_Playbook(ID),
+						TeamID     TEXT NOT NULL,
+						PRIMARY KEY (PlaybookID, TeamID)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_PlaybookTeam")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_PlaybookTeam_TeamID", "IR_PlaybookTeam", "TeamID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_PlaybookTeam_TeamID")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.44.0"),
+		toVersion:   semver.MustParse("0.45.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_ChecklistLibrary
+					(
+						ID          VARCHAR(26)  NOT NULL,
+						TeamID      VARCHAR(26)  NOT NULL,
+						Title       VARCHAR(512) NOT NULL DEFAULT '',
+						Description TEXT,
+						Version     INT          NOT NULL DEFAULT 1,
+						ItemsJSON   JSON,
+						CreateAt    BIGINT       NOT NULL,
+						UpdateAt    BIGINT       NOT NULL DEFAULT 0,
+						DeleteAt    BIGINT       NOT NULL DEFAULT 0,
+						PRIMARY KEY (ID),
+						INDEX IR_ChecklistLibrary_TeamID (TeamID)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_ChecklistLibrary")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_ChecklistLibraryVersion
+					(
+						EntryID   VARCHAR(26)  NOT NULL REFERENCES IR_ChecklistLibrary(ID),
+						Version   INT          NOT NULL,
+						Title     VARCHAR(512) NOT NULL DEFAULT '',
+						ItemsJSON JSON,
+						UserID    VARCHAR(26)  NOT NULL DEFAULT '',
+						CreateAt  BIGINT       NOT NULL,
+						PRIMARY KEY (EntryID, Version)
+					)
+				` + MySQLCharset); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_ChecklistLibraryVersion")
+				}
+			} else {
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_ChecklistLibrary
+					(
+						ID          TEXT PRIMARY KEY,
+						TeamID      TEXT    NOT NULL,
+						Title       TEXT    NOT NULL DEFAULT '',
+						Description TEXT    NOT NULL DEFAULT '',
+						Version     INTEGER NOT NULL DEFAULT 1,
+						ItemsJSON   JSON,
+						CreateAt    BIGINT  NOT NULL,
+						UpdateAt    BIGINT  NOT NULL DEFAULT 0,
+						DeleteAt    BIGINT  NOT NULL DEFAULT 0
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_ChecklistLibrary")
+				}
+
+				if _, err := e.Exec(createPGIndex("IR_ChecklistLibrary_TeamID", "IR_ChecklistLibrary", "TeamID")); err != nil {
+					return errors.Wrapf(err, "failed creating index IR_ChecklistLibrary_TeamID")
+				}
+
+				if _, err := e.Exec(`
+					CREATE TABLE IF NOT EXISTS IR_ChecklistLibraryVersion
+					(
+						EntryID   TEXT    NOT NULL REFERENCES IR_ChecklistLibrary(ID),
+						Version   INTEGER NOT NULL,
+						Title     TEXT    NOT NULL DEFAULT '',
+						ItemsJSON JSON,
+						UserID    TEXT    NOT NULL DEFAULT '',
+						CreateAt  BIGINT  NOT NULL,
+						PRIMARY KEY (EntryID, Version)
+					)
+				`); err != nil {
+					return errors.Wrapf(err, "failed creating table IR_ChecklistLibraryVersion")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.45.0"),
+		toVersion:   semver.MustParse("0.46.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if err := addColumnToMySQLTable(e, "IR_Incident", "CustomDataJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column CustomDataJSON to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Incident", "CustomDataJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column CustomDataJSON to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		fromVersion: semver.MustParse("0.46.0"),
+		toVersion:   semver.MustParse("0.47.0"),
+		migrationFunc: func(e sqlx.Ext, sqlStore *SQLStore) error {
+			// Existing runs and playbooks have no run statuses, and runs keep being only
+			// InProgress or Finished.
+			if e.DriverName() == model.DatabaseDriverMysql {
+				if err := addColumnToMySQLTable(e, "IR_Playbook", "RunStatusesJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column RunStatusesJSON to table IR_Playbook")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "RunStatusesJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column RunStatusesJSON to table IR_Incident")
+				}
+				if err := addColumnToMySQLTable(e, "IR_Incident", "CurrentRunStatus", "VARCHAR(64) NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column CurrentRunStatus to table IR_Incident")
+				}
+			} else {
+				if err := addColumnToPGTable(e, "IR_Playbook", "RunStatusesJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column RunStatusesJSON to table IR_Playbook")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "RunStatusesJSON", "JSON"); err != nil {
+					return errors.Wrapf(err, "failed adding column RunStatusesJSON to table IR_Incident")
+				}
+				if err := addColumnToPGTable(e, "IR_Incident", "CurrentRunStatus", "TEXT NOT NULL DEFAULT ''"); err != nil {
+					return errors.Wrapf(err, "failed adding column CurrentRunStatus to table IR_Incident")
+				}
+			}
+
+			return nil
+		},
+	},
 }
@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+)
+
+// pagination describes the page of a listing to fetch, either by offset (Page) or after a cursor.
+type pagination struct {
+	Page      int
+	PerPage   int
+	Cursor    string
+	SkipCount bool
+
+	// Sort and Direction are the validated order of the listing.
+	Sort      app.SortField
+	Direction app.SortDirection
+
+	// Column is the expression of the sort field, IDColumn the one of the item id. When
+	// Aggregate is true, Column is an aggregate and is filtered with HAVING.
+	Column    string
+	IDColumn  string
+	Aggregate bool
+}
+
+// fetchesExtra reports whether one extra item is fetched to know if there are more pages, as
+// the total count isn't available, or doesn't tell where a cursor is.
+func (p pagination) fetchesExtra() bool {
+	return p.Cursor != "" || p.SkipCount
+}
+
+// apply orders the query, breaking the ties on the id, and limits it to the page.
+func (p pagination) apply(builder sq.SelectBuilder) (sq.SelectBuilder, error) {
+	direction := string(p.Direction)
+	if p.Direction == "" {
+		direction = string(app.DirectionAsc)
+	}
+
+	builder = builder.OrderByClause(fmt.Sprintf("%s %s", p.Column, direction))
+	if p.Column != p.IDColumn {
+		builder = builder.OrderByClause(fmt.Sprintf("%s %s", p.IDColumn, direction))
+	}
+
+	perPage := p.PerPage
+	if perPage < 0 {
+		perPage = 0
+	}
+	if p.fetchesExtra() {
+		builder = builder.Limit(uint64(perPage + 1))
+	} else {
+		builder = builder.Limit(uint64(perPage))
+	}
+
+	if p.Cursor == "" {
+		page := p.Page
+		if page < 0 {
+			page = 0
+		}
+
+		return builder.Offset(uint64(page * perPage)), nil
+	}
+
+	cursor, err := app.DecodePageCursor(p.Cursor)
+	if err != nil {
+		return sq.SelectBuilder{}, err
+	}
+
+	operator := ">"
+	if direction == string(app.DirectionDesc) {
+		operator = "<"
+	}
+
+	var afterCursor sq.Sqlizer
+	if p.Column == p.IDColumn {
+		afterCursor = sq.Expr(fmt.Sprintf("%s %s ?", p.IDColumn, operator), cursor.ID)
+	} else {
+		afterCursor = sq.Expr(
+			fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", p.Column, operator, p.Column, p.IDColumn, operator),
+			cursor.Value, cursor.Value, cursor.ID,
+		)
+	}
+
+	if p.Aggregate {
+		return builder.Having(afterCursor), nil
+	}
+
+	return builder.Where(afterCursor), nil
+}
+
+// trimExtra returns the number of items in the page, out of the numItems fetched with the extra
+// one, and whether there are more pages.
+func (p pagination) trimExtra(numItems int) (int, bool) {
+	if numItems > p.PerPage {
+		return p.PerPage, true
+	}
+
+	return numItems, false
+}
+
+// nextCursor returns the cursor of the page following the last item.
+func (p pagination) nextCursor(value interface{}, id string) string {
+	direction := p.Direction
+	if direction == "" {
+		direction = app.DirectionAsc
+	}
+
+	return app.PageCursor{
+		Sort:      p.Sort,
+		Direction: direction,
+		Value:     value,
+		ID:        id,
+	}.Encode()
+}
@@ -0,0 +1,81 @@
+package sqlstore
+
+import (
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginationApply(t *testing.T) {
+	builder := sq.StatementBuilder.PlaceholderFormat(sq.Question).Select("*").From("IR_Incident AS i")
+
+	t.Run("offset", func(t *testing.T) {
+		page := pagination{Page: 2, PerPage: 10, Sort: app.SortByCreateAt, Direction: app.DirectionDesc, Column: "i.CreateAt", IDColumn: "i.ID"}
+
+		query, err := page.apply(builder)
+		require.NoError(t, err)
+
+		sql, args, err := query.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM IR_Incident AS i ORDER BY i.CreateAt DESC, i.ID DESC LIMIT 10 OFFSET 20", sql)
+		require.Empty(t, args)
+	})
+
+	t.Run("offset without count fetches one more", func(t *testing.T) {
+		page := pagination{PerPage: 10, SkipCount: true, Sort: app.SortByID, Column: "i.ID", IDColumn: "i.ID"}
+
+		query, err := page.apply(builder)
+		require.NoError(t, err)
+
+		sql, _, err := query.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM IR_Incident AS i ORDER BY i.ID ASC LIMIT 11 OFFSET 0", sql)
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		cursor := app.PageCursor{Sort: app.SortByCreateAt, Direction: app.DirectionDesc, Value: int64(1234), ID: "run_id"}
+		page := pagination{PerPage: 10, Cursor: cursor.Encode(), Sort: app.SortByCreateAt, Direction: app.DirectionDesc, Column: "i.CreateAt", IDColumn: "i.ID"}
+
+		query, err := page.apply(builder)
+		require.NoError(t, err)
+
+		sql, args, err := query.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM IR_Incident AS i WHERE (i.CreateAt < ? OR (i.CreateAt = ? AND i.ID < ?)) ORDER BY i.CreateAt DESC, i.ID DESC LIMIT 11", sql)
+		require.Equal(t, []interface{}{int64(1234), int64(1234), "run_id"}, args)
+	})
+
+	t.Run("cursor on the id of an aggregate query", func(t *testing.T) {
+		cursor := app.PageCursor{Sort: app.SortByRuns, Direction: app.DirectionAsc, Value: int64(3), ID: "playbook_id"}
+		page := pagination{PerPage: 5, Cursor: cursor.Encode(), Sort: app.SortByRuns, Column: "COUNT(i.ID)", IDColumn: "p.ID", Aggregate: true}
+
+		query, err := page.apply(builder.GroupBy("p.ID"))
+		require.NoError(t, err)
+
+		sql, args, err := query.ToSql()
+		require.NoError(t, err)
+		require.Equal(t, "SELECT * FROM IR_Incident AS i GROUP BY p.ID HAVING (COUNT(i.ID) > ? OR (COUNT(i.ID) = ? AND p.ID > ?)) ORDER BY COUNT(i.ID) ASC, p.ID ASC LIMIT 6", sql)
+		require.Equal(t, []interface{}{int64(3), int64(3), "playbook_id"}, args)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		page := pagination{PerPage: 10, Cursor: "invalid", Column: "i.ID", IDColumn: "i.ID"}
+
+		_, err := page.apply(builder)
+		require.Error(t, err)
+	})
+}
+
+func TestPaginationTrimExtra(t *testing.T) {
+	page := pagination{PerPage: 2, SkipCount: true}
+
+	numItems, hasMore := page.trimExtra(3)
+	require.Equal(t, 2, numItems)
+	require.True(t, hasMore)
+
+	numItems, hasMore = page.trimExtra(2)
+	require.Equal(t, 2, numItems)
+	require.False(t, hasMore)
+}
@@ -17,6 +17,8 @@ import (
 type sqlPlaybook struct {
 	app.Playbook
 	ChecklistsJSON                        json.RawMessage
+	RunPermissionsJSON                    json.RawMessage
+	RunStatusesJSON                       json.RawMessage
 	ConcatenatedInvitedUserIDs            string
 	ConcatenatedInvitedGroupIDs           string
 	ConcatenatedSignalAnyKeywords         string
@@ -27,12 +29,14 @@ type sqlPlaybook struct {
 
 // playbookStore is a sql store for playbooks. Use NewPlaybookStore to create it.
 type playbookStore struct {
-	pluginAPI       PluginAPIClient
-	log             bot.Logger
-	store           *SQLStore
-	queryBuilder    sq.StatementBuilderType
-	playbookSelect  sq.SelectBuilder
-	memberIDsSelect sq.SelectBuilder
+	pluginAPI          PluginAPIClient
+	log                bot.Logger
+	store              *SQLStore
+	queryBuilder       sq.StatementBuilderType
+	playbookSelect     sq.SelectBuilder
+	memberIDsSelect    sq.SelectBuilder
+	memberGroupsSelect sq.SelectBuilder
+	sharedTeamsSelect  sq.SelectBuilder
 }
 
 // Ensure playbookStore implements the playbook.Store interface.
@@ -41,57 +45,79 @@ var _ app.PlaybookStore = (*playbookStore)(nil)
 type playbookMembers []struct {
 	PlaybookID string
 	MemberID   string
+	Role       string
 }
 
-func applyPlaybookFilterOptionsSort(builder sq.SelectBuilder, options app.PlaybookFilterOptions) (sq.SelectBuilder, error) {
-	var sort string
-	switch options.Sort {
+type playbookMemberGroups []struct {
+	PlaybookID string
+	Type       string
+	GroupID    string
+	Role       string
+}
+
+type playbookSharedTeams []struct {
+	PlaybookID string
+	TeamID     string
+}
+
+func playbooksPagination(options app.PlaybookFilterOptions) (pagination, error) {
+	sort := options.Sort
+	column := "p.ID"
+	aggregate := false
+	switch sort {
 	case app.SortByID:
-		sort = "ID"
 	case app.SortByTitle:
-		sort = "Title"
+		column = "p.Title"
 	case app.SortByStages:
-		sort = "NumStages"
+		column = "p.NumStages"
 	case app.SortBySteps:
-		sort = "NumSteps"
+		column = "p.NumSteps"
 	case app.SortByRuns:
-		sort = "NumRuns"
+		column = "COUNT(i.ID)"
+		aggregate = true
 	case "":
 		// Default to a stable sort if none explicitly provided.
-		sort = "ID"
+		sort = app.SortByID
 	default:
-		return sq.SelectBuilder{}, errors.Errorf("unsupported sort parameter '%s'", options.Sort)
+		return pagination{}, errors.Errorf("unsupported sort parameter '%s'", options.Sort)
 	}
 
-	var direction string
 	switch options.Direction {
 	case app.DirectionAsc:
-		direction = "ASC"
 	case app.DirectionDesc:
-		direction = "DESC"
 	case "":
 		// Default to an ascending sort if none explicitly provided.
-		direction = "ASC"
 	default:
-		return sq.SelectBuilder{}, errors.Errorf("unsupported direction parameter '%s'", options.Direction)
+		return pagination{}, errors.Errorf("unsupported direction parameter '%s'", options.Direction)
 	}
 
-	builder = builder.OrderByClause(fmt.Sprintf("%s %s", sort, direction))
+	return pagination{
+		Page:      options.Page,
+		PerPage:   options.PerPage,
+		Cursor:    options.Cursor,
+		SkipCount: options.SkipCount,
+		Sort:      sort,
+		Direction: options.Direction,
+		Column:    column,
+		IDColumn:  "p.ID",
+		Aggregate: aggregate,
+	}, nil
+}
 
-	page := options.Page
-	perPage := options.PerPage
-	if page < 0 {
-		page = 0
-	}
-	if perPage < 0 {
-		perPage = 0
+// playbookSortValue returns the value of the sort field of the playbook, for its cursor.
+func playbookSortValue(playbook app.Playbook, sort app.SortField) interface{} {
+	switch sort {
+	case app.SortByTitle:
+		return playbook.Title
+	case app.SortByStages:
+		return playbook.NumStages
+	case app.SortBySteps:
+		return playbook.NumSteps
+	case app.SortByRuns:
+		return playbook.NumRuns
+	default:
+		return playbook.ID
 	}
-
-	builder = builder.
-		Offset(uint64(page * perPage)).
-		Limit(uint64(perPage))
-
-	return builder, nil
 }
 
 // NewPlaybookStore creates a new store for playbook service.
@@ -102,6 +128,7 @@ func NewPlaybookStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLSt
 			"Title",
 			"Description",
 			"TeamID",
+			"SharedWithAllTeams",
 			"CreatePublicIncident AS CreatePublicPlaybookRun",
 			"CreateAt",
 			"UpdateAt",
@@ -141,21 +168,35 @@ func NewPlaybookStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLSt
 			"SignalAnyKeywordsEnabled",
 			"CategorizeChannelEnabled",
 			"COALESCE(CategoryName, '') CategoryName",
+			"RunPermissionsJSON",
+			"RunStatusesJSON",
 		).
 		From("IR_Playbook")
 
 	memberIDsSelect := sqlStore.builder.
-		Select("PlaybookID", "MemberID").
+		Select("PlaybookID", "MemberID", "Role").
 		From("IR_PlaybookMember").
 		OrderBy("MemberID ASC") // Entirely for consistancy for the tests
 
+	memberGroupsSelect := sqlStore.builder.
+		Select("PlaybookID", "Type", "GroupID", "Role").
+		From("IR_PlaybookMemberGroup").
+		OrderBy("Type ASC", "GroupID ASC")
+
+	sharedTeamsSelect := sqlStore.builder.
+		Select("PlaybookID", "TeamID").
+		From("IR_PlaybookTeam").
+		OrderBy("TeamID ASC")
+
 	newStore := &playbookStore{
-		pluginAPI:       pluginAPI,
-		log:             log,
-		store:           sqlStore,
-		queryBuilder:    sqlStore.builder,
-		playbookSelect:  playbookSelect,
-		memberIDsSelect: memberIDsSelect,
+		pluginAPI:          pluginAPI,
+		log:                log,
+		store:              sqlStore,
+		queryBuilder:       sqlStore.builder,
+		playbookSelect:     playbookSelect,
+		memberIDsSelect:    memberIDsSelect,
+		memberGroupsSelect: memberGroupsSelect,
+		sharedTeamsSelect:  sharedTeamsSelect,
 	}
 	return newStore
 }
@@ -185,6 +226,7 @@ func (p *playbookStore) Create(playbook app.Playbook) (id string, err error) {
 			"Title":                                 rawPlaybook.Title,
 			"Description":                           rawPlaybook.Description,
 			"TeamID":                                rawPlaybook.TeamID,
+			"SharedWithAllTeams":                    rawPlaybook.SharedWithAllTeams,
 			"CreatePublicIncident":                  rawPlaybook.CreatePublicPlaybookRun,
 			"CreateAt":                              rawPlaybook.CreateAt,
 			"UpdateAt":                              rawPlaybook.UpdateAt,
@@ -214,6 +256,8 @@ func (p *playbookStore) Create(playbook app.Playbook) (id string, err error) {
 			"SignalAnyKeywordsEnabled":              rawPlaybook.SignalAnyKeywordsEnabled,
 			"CategorizeChannelEnabled":              rawPlaybook.CategorizeChannelEnabled,
 			"CategoryName":                          rawPlaybook.CategoryName,
+			"RunPermissionsJSON":                    rawPlaybook.RunPermissionsJSON,
+			"RunStatusesJSON":                       rawPlaybook.RunStatusesJSON,
 		}))
 	if err != nil {
 		return "", errors.Wrap(err, "failed to store new playbook")
@@ -223,6 +267,10 @@ func (p *playbookStore) Create(playbook app.Playbook) (id string, err error) {
 		return "", errors.Wrap(err, "failed to replace playbook members")
 	}
 
+	if err = p.replacePlaybookSharedTeams(tx, rawPlaybook.Playbook); err != nil {
+		return "", errors.Wrap(err, "failed to replace playbook shared teams")
+	}
+
 	if err = tx.Commit(); err != nil {
 		return "", errors.Wrap(err, "could not commit transaction")
 	}
@@ -265,13 +313,32 @@ func (p *playbookStore) Get(id string) (app.Playbook, error) {
 		return app.Playbook{}, errors.Wrapf(err, "failed to get memberIDs for playbook with id '%s'", id)
 	}
 
+	var memberGroups playbookMemberGroups
+	err = p.store.selectBuilder(tx, &memberGroups, p.memberGroupsSelect.Where(sq.Eq{"PlaybookID": id}))
+	if err != nil && err != sql.ErrNoRows {
+		return app.Playbook{}, errors.Wrapf(err, "failed to get member groups for playbook with id '%s'", id)
+	}
+
+	var sharedTeams playbookSharedTeams
+	err = p.store.selectBuilder(tx, &sharedTeams, p.sharedTeamsSelect.Where(sq.Eq{"PlaybookID": id}))
+	if err != nil && err != sql.ErrNoRows {
+		return app.Playbook{}, errors.Wrapf(err, "failed to get shared teams for playbook with id '%s'", id)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return app.Playbook{}, errors.Wrap(err, "could not commit transaction")
 	}
 
 	for _, m := range memberIDs {
+		playbook.Members = append(playbook.Members, app.PlaybookMember{UserID: m.MemberID, Role: m.Role})
 		playbook.MemberIDs = append(playbook.MemberIDs, m.MemberID)
 	}
+	for _, g := range memberGroups {
+		playbook.MemberGroups = append(playbook.MemberGroups, app.PlaybookMemberGroup{Type: g.Type, ID: g.GroupID, Role: g.Role})
+	}
+	for _, t := range sharedTeams {
+		playbook.SharedTeamIDs = append(playbook.SharedTeamIDs, t.TeamID)
+	}
 
 	return playbook, nil
 }
@@ -291,6 +358,7 @@ func (p *playbookStore) GetPlaybooks() ([]app.Playbook, error) {
 			"p.Title",
 			"p.Description",
 			"p.TeamID",
+			"p.SharedWithAllTeams",
 			"p.CreatePublicIncident AS CreatePublicPlaybookRun",
 			"p.CreateAt",
 			"p.DeleteAt",
@@ -327,11 +395,24 @@ func (p *playbookStore) GetPlaybooks() ([]app.Playbook, error) {
 		return nil, errors.Wrapf(err, "failed to get memberIDs")
 	}
 
+	var memberGroups playbookMemberGroups
+	err = p.store.selectBuilder(tx, &memberGroups, p.memberGroupsSelect)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get member groups")
+	}
+
+	var sharedTeams playbookSharedTeams
+	err = p.store.selectBuilder(tx, &sharedTeams, p.sharedTeamsSelect)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get shared teams")
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, errors.Wrap(err, "could not commit transaction")
 	}
 
-	addMembersToPlaybooks(memberIDs, playbooks)
+	addMembersToPlaybooks(memberIDs, memberGroups, playbooks)
+	addSharedTeamsToPlaybooks(sharedTeams, playbooks)
 
 	return playbooks, nil
 }
@@ -339,16 +420,14 @@ func (p *playbookStore) GetPlaybooks() ([]app.Playbook, error) {
 // GetPlaybooksForTeam retrieves all playbooks on the specified team given the provided options.
 func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, teamID string, opts app.PlaybookFilterOptions) (app.GetPlaybooksResults, error) {
 	// Check that you are a playbook member or there are no restrictions.
-	permissionsAndFilter := sq.Expr(`(
-			EXISTS(SELECT 1
-					FROM IR_PlaybookMember as pm
-					WHERE pm.PlaybookID = p.ID
-					AND pm.MemberID = ?)
-			OR NOT EXISTS(SELECT 1
-					FROM IR_PlaybookMember as pm
-					WHERE pm.PlaybookID = p.ID)
-		)`, requesterInfo.UserID)
-	teamLimitExpr := buildTeamLimitExpr(requesterInfo.UserID, teamID, "p")
+	permissionsAndFilter := playbookAccessExpr("p.ID", requesterInfo.UserID)
+	teamLimitExpr := playbookTeamLimitExpr(requesterInfo.UserID, teamID)
+
+	// Archived playbooks are hidden unless asked for.
+	archivedExpr := sq.And{}
+	if !opts.WithArchived {
+		archivedExpr = append(archivedExpr, sq.Eq{"p.DeleteAt": 0})
+	}
 
 	queryForResults := p.store.builder.
 		Select(
@@ -356,6 +435,7 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, tea
 			"p.Title",
 			"p.Description",
 			"p.TeamID",
+			"p.SharedWithAllTeams",
 			"p.CreatePublicIncident AS CreatePublicPlaybookRun",
 			"p.CreateAt",
 			"p.DeleteAt",
@@ -378,14 +458,18 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, tea
 		From("IR_Playbook AS p").
 		LeftJoin("IR_Incident AS i ON p.ID = i.PlaybookID").
 		GroupBy("p.ID").
-		Where(sq.Eq{"p.DeleteAt": 0}).
+		Where(archivedExpr).
 		Where(permissionsAndFilter).
 		Where(teamLimitExpr)
 
-	queryForResults, err := applyPlaybookFilterOptionsSort(queryForResults, opts)
+	page, err := playbooksPagination(opts)
 	if err != nil {
 		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to apply sort options")
 	}
+	queryForResults, err = page.apply(queryForResults)
+	if err != nil {
+		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to apply pagination options")
+	}
 
 	var playbooks []app.Playbook
 	err = p.store.selectBuilder(p.store.db, &playbooks, queryForResults)
@@ -395,29 +479,47 @@ func (p *playbookStore) GetPlaybooksForTeam(requesterInfo app.RequesterInfo, tea
 		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to get playbooks")
 	}
 
-	queryForTotal := p.store.builder.
-		Select("COUNT(*)").
-		From("IR_Playbook AS p").
-		Where(sq.Eq{"DeleteAt": 0}).
-		Where(permissionsAndFilter).
-		Where(teamLimitExpr)
+	var total, pageCount int
+	if !opts.SkipCount {
+		queryForTotal := p.store.builder.
+			Select("COUNT(*)").
+			From("IR_Playbook AS p").
+			Where(archivedExpr).
+			Where(permissionsAndFilter).
+			Where(teamLimitExpr)
+
+		if err = p.store.getBuilder(p.store.db, &total, queryForTotal); err != nil {
+			return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to get total count")
+		}
 
-	var total int
-	if err = p.store.getBuilder(p.store.db, &total, queryForTotal); err != nil {
-		return app.GetPlaybooksResults{}, errors.Wrap(err, "failed to get total count")
+		if opts.PerPage > 0 {
+			pageCount = int(math.Ceil(float64(total) / float64(opts.PerPage)))
+		}
 	}
 
-	pageCount := 0
-	if opts.PerPage > 0 {
-		pageCount = int(math.Ceil(float64(total) / float64(opts.PerPage)))
-	}
 	hasMore := opts.Page+1 < pageCount
+	if page.fetchesExtra() {
+		var numItems int
+		numItems, hasMore = page.trimExtra(len(playbooks))
+		playbooks = playbooks[:numItems]
+	}
+
+	if err = p.loadSharedTeams(playbooks); err != nil {
+		return app.GetPlaybooksResults{}, err
+	}
+
+	var nextCursor string
+	if hasMore && len(playbooks) > 0 {
+		last := playbooks[len(playbooks)-1]
+		nextCursor = page.nextCursor(playbookSortValue(last, page.Sort), last.ID)
+	}
 
 	return app.GetPlaybooksResults{
 		TotalCount: total,
 		PageCount:  pageCount,
 		HasMore:    hasMore,
 		Items:      playbooks,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -441,10 +543,11 @@ func (p *playbookStore) GetNumPlaybooksForTeam(teamID string) (int, error) {
 // GetPlaybooksWithKeywords retrieves all playbooks with keywords enabled
 func (p *playbookStore) GetPlaybooksWithKeywords(opts app.PlaybookFilterOptions) ([]app.Playbook, error) {
 	queryForResults := p.store.builder.
-		Select("ID", "Title", "UpdateAt", "TeamID", "ConcatenatedSignalAnyKeywords").
+		Select("ID", "Title", "UpdateAt", "TeamID", "SharedWithAllTeams", "ConcatenatedSignalAnyKeywords").
 		From("IR_Playbook AS p").
 		Where(sq.Eq{"DeleteAt": 0}).
 		Where(sq.Eq{"SignalAnyKeywordsEnabled": true}).
+		OrderBy("ID").
 		Offset(uint64(opts.Page * opts.PerPage)).
 		Limit(uint64(opts.PerPage))
 
@@ -464,6 +567,11 @@ func (p *playbookStore) GetPlaybooksWithKeywords(opts app.PlaybookFilterOptions)
 		}
 		playbooks = append(playbooks, out)
 	}
+
+	if err = p.loadSharedTeams(playbooks); err != nil {
+		return nil, err
+	}
+
 	return playbooks, nil
 }
 
@@ -493,21 +601,13 @@ func (p *playbookStore) GetTimeLastUpdated(onlyPlaybooksWithKeywordsEnabled bool
 // Notice that method is not checking weather or not user is member of a team
 func (p *playbookStore) GetPlaybookIDsForUser(userID string, teamID string) ([]string, error) {
 	// Check that you are a playbook member or there are no restrictions.
-	permissionsAndFilter := sq.Expr(`(
-		EXISTS(SELECT 1
-				FROM IR_PlaybookMember as pm
-				WHERE pm.PlaybookID = p.ID
-				AND pm.MemberID = ?)
-		OR NOT EXISTS(SELECT 1
-				FROM IR_PlaybookMember as pm
-				WHERE pm.PlaybookID = p.ID)
-	)`, userID)
+	permissionsAndFilter := playbookAccessExpr("p.ID", userID)
 
 	queryForResults := p.store.builder.
 		Select("ID").
 		From("IR_Playbook AS p").
 		Where(sq.Eq{"DeleteAt": 0}).
-		Where(sq.Eq{"TeamID": teamID}).
+		Where(playbookTeamLimitExpr(userID, teamID)).
 		Where(permissionsAndFilter)
 
 	var playbookIDs []string
@@ -519,6 +619,56 @@ func (p *playbookStore) GetPlaybookIDsForUser(userID string, teamID string) ([]s
 	return playbookIDs, nil
 }
 
+// GetPlaybookIDsReferencingLibrary retrieves the playbooks, archived or not, whose checklists
+// contain the ID of the checklist library entry entryID.
+func (p *playbookStore) GetPlaybookIDsReferencingLibrary(entryID string) ([]string, error) {
+	query := p.store.builder.
+		Select("ID").
+		From("IR_Playbook")
+
+	if p.store.db.DriverName() == model.DatabaseDriverMysql {
+		query = query.Where(sq.Like{"ChecklistsJSON": fmt.Sprintf("%%\"%s\"%%", entryID)})
+	} else {
+		query = query.Where(sq.Like{"ChecklistsJSON::text": fmt.Sprintf("%%\"%s\"%%", entryID)})
+	}
+
+	var playbookIDs []string
+	err := p.store.selectBuilder(p.store.db, &playbookIDs, query)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, errors.Wrapf(err, "failed to get the playbooks referencing library checklist %s", entryID)
+	}
+	return playbookIDs, nil
+}
+
+// GetRunPermissions retrieves the run permission policy of a playbook, without loading the rest
+// of it.
+func (p *playbookStore) GetRunPermissions(id string) (app.RunPermissionPolicy, error) {
+	if id == "" {
+		return app.RunPermissionPolicy{}, errors.New("ID cannot be empty")
+	}
+
+	var runPermissionsJSON []json.RawMessage
+	query := p.store.builder.
+		Select("RunPermissionsJSON").
+		From("IR_Playbook").
+		Where(sq.Eq{"ID": id})
+	if err := p.store.selectBuilder(p.store.db, &runPermissionsJSON, query); err != nil {
+		return app.RunPermissionPolicy{}, errors.Wrapf(err, "failed to get the run permissions of playbook with id '%s'", id)
+	}
+	if len(runPermissionsJSON) == 0 {
+		return app.RunPermissionPolicy{}, errors.Wrapf(app.ErrNotFound, "playbook does not exist for id '%s'", id)
+	}
+
+	var policy app.RunPermissionPolicy
+	if len(runPermissionsJSON[0]) > 0 {
+		if err := json.Unmarshal(runPermissionsJSON[0], &policy); err != nil {
+			return app.RunPermissionPolicy{}, errors.Wrapf(err, "failed to unmarshal run permissions json for playbook id: '%s'", id)
+		}
+	}
+
+	return policy, nil
+}
+
 // Update updates a playbook
 func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 	if playbook.ID == "" {
@@ -542,6 +692,7 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 			"Title":                                 rawPlaybook.Title,
 			"Description":                           rawPlaybook.Description,
 			"TeamID":                                rawPlaybook.TeamID,
+			"SharedWithAllTeams":                    rawPlaybook.SharedWithAllTeams,
 			"CreatePublicIncident":                  rawPlaybook.CreatePublicPlaybookRun,
 			"UpdateAt":                              rawPlaybook.UpdateAt,
 			"DeleteAt":                              rawPlaybook.DeleteAt,
@@ -570,6 +721,8 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 			"SignalAnyKeywordsEnabled":              rawPlaybook.SignalAnyKeywordsEnabled,
 			"CategorizeChannelEnabled":              rawPlaybook.CategorizeChannelEnabled,
 			"CategoryName":                          rawPlaybook.CategoryName,
+			"RunPermissionsJSON":                    rawPlaybook.RunPermissionsJSON,
+			"RunStatusesJSON":                       rawPlaybook.RunStatusesJSON,
 		}).
 		Where(sq.Eq{"ID": rawPlaybook.ID}))
 
@@ -581,6 +734,10 @@ func (p *playbookStore) Update(playbook app.Playbook) (err error) {
 		return errors.Wrapf(err, "failed to replace playbook members for playbook with id '%s'", rawPlaybook.ID)
 	}
 
+	if err = p.replacePlaybookSharedTeams(tx, rawPlaybook.Playbook); err != nil {
+		return errors.Wrapf(err, "failed to replace playbook shared teams for playbook with id '%s'", rawPlaybook.ID)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return errors.Wrap(err, "could not commit transaction")
 	}
@@ -606,8 +763,170 @@ func (p *playbookStore) Delete(id string) error {
 	return nil
 }
 
-// replacePlaybookMembers replaces the members of a playbook
+// Restore unarchives a playbook.
+func (p *playbookStore) Restore(id string) error {
+	if id == "" {
+		return errors.New("ID cannot be empty")
+	}
+
+	_, err := p.store.execBuilder(p.store.db, sq.
+		Update("IR_Playbook").
+		Set("DeleteAt", 0).
+		Where(sq.Eq{"ID": id}))
+
+	if err != nil {
+		return errors.Wrapf(err, "failed to restore playbook with id '%s'", id)
+	}
+
+	return nil
+}
+
+// Purge permanently deletes a playbook with its members and shared teams. Its runs are kept,
+// without a playbook.
+func (p *playbookStore) Purge(id string) error {
+	if id == "" {
+		return errors.New("ID cannot be empty")
+	}
+
+	tx, err := p.store.db.Beginx()
+	if err != nil {
+		return errors.Wrap(err, "could not begin transaction")
+	}
+	defer p.store.finalizeTransaction(tx)
+
+	for _, table := range []string{"IR_PlaybookMember", "IR_PlaybookMemberGroup", "IR_PlaybookTeam"} {
+		if _, err = p.store.execBuilder(tx, sq.Delete(table).Where(sq.Eq{"PlaybookID": id})); err != nil {
+			return errors.Wrapf(err, "failed to delete from %s for playbook with id '%s'", table, id)
+		}
+	}
+
+	if _, err = p.store.execBuilder(tx, sq.Update("IR_Incident").Set("PlaybookID", "").Where(sq.Eq{"PlaybookID": id})); err != nil {
+		return errors.Wrapf(err, "failed to detach the runs of playbook with id '%s'", id)
+	}
+
+	if _, err = p.store.execBuilder(tx, sq.Delete("IR_Playbook").Where(sq.Eq{"ID": id})); err != nil {
+		return errors.Wrapf(err, "failed to purge playbook with id '%s'", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return errors.Wrap(err, "could not commit transaction")
+	}
+
+	return nil
+}
+
+// playbookAccessExpr matches the playbooks, identified by playbookIDColumn, that userID can
+// access: the open playbooks, and the ones they are a member of, directly or through a member
+// group or channel. The groups and channels are resolved here, so the access follows their
+// changes.
+func playbookAccessExpr(playbookIDColumn, userID string) sq.Sqlizer {
+	return sq.Expr(fmt.Sprintf(`(
+		(
+			NOT EXISTS(SELECT 1
+					FROM IR_PlaybookMember as pm
+					WHERE pm.PlaybookID = %[1]s)
+			AND NOT EXISTS(SELECT 1
+					FROM IR_PlaybookMemberGroup as pg
+					WHERE pg.PlaybookID = %[1]s)
+		)
+		OR EXISTS(SELECT 1
+				FROM IR_PlaybookMember as pm
+				WHERE pm.PlaybookID = %[1]s
+				AND pm.MemberID = ?)
+		OR EXISTS(SELECT 1
+				FROM IR_PlaybookMemberGroup as pg
+				JOIN GroupMembers as gm ON gm.GroupId = pg.GroupID AND gm.DeleteAt = 0
+				JOIN UserGroups as ug ON ug.Id = gm.GroupId AND ug.DeleteAt = 0
+				WHERE pg.PlaybookID = %[1]s
+				AND pg.Type = 'group'
+				AND gm.UserId = ?)
+		OR EXISTS(SELECT 1
+				FROM IR_PlaybookMemberGroup as pg
+				JOIN ChannelMembers as cm ON cm.ChannelId = pg.GroupID
+				WHERE pg.PlaybookID = %[1]s
+				AND pg.Type = 'channel'
+				AND cm.UserId = ?)
+	)`, playbookIDColumn), userID, userID, userID)
+}
+
+// playbookTeamLimitExpr matches the playbooks of the IR_Playbook table aliased p that can be run
+// in teamID: the playbooks of the team, and those shared with it or with all teams. Without a
+// teamID, it matches the playbooks that can be run in any of the teams of userID.
+func playbookTeamLimitExpr(userID, teamID string) sq.Sqlizer {
+	if teamID != "" {
+		return sq.Or{
+			sq.Eq{"p.TeamID": teamID},
+			sq.Eq{"p.SharedWithAllTeams": true},
+			sq.Expr(`EXISTS(SELECT 1
+					FROM IR_PlaybookTeam as pt
+					WHERE pt.PlaybookID = p.ID
+					AND pt.TeamID = ?)`, teamID),
+		}
+	}
+
+	return sq.Or{
+		buildTeamLimitExpr(userID, teamID, "p"),
+		sq.Eq{"p.SharedWithAllTeams": true},
+		sq.Expr(`EXISTS(SELECT 1
+				FROM IR_PlaybookTeam as pt
+				JOIN TeamMembers as tm ON tm.TeamId = pt.TeamID AND tm.DeleteAt = 0
+				WHERE pt.PlaybookID = p.ID
+				AND tm.UserId = ?)`, userID),
+	}
+}
+
+// loadSharedTeams fills the shared teams of playbooks.
+func (p *playbookStore) loadSharedTeams(playbooks []app.Playbook) error {
+	if len(playbooks) == 0 {
+		return nil
+	}
+
+	playbookIDs := make([]string, 0, len(playbooks))
+	for _, playbook := range playbooks {
+		playbookIDs = append(playbookIDs, playbook.ID)
+	}
+
+	var sharedTeams playbookSharedTeams
+	err := p.store.selectBuilder(p.store.db, &sharedTeams, p.sharedTeamsSelect.Where(sq.Eq{"PlaybookID": playbookIDs}))
+	if err != nil && err != sql.ErrNoRows {
+		return errors.Wrap(err, "failed to get shared teams")
+	}
+
+	addSharedTeamsToPlaybooks(sharedTeams, playbooks)
+
+	return nil
+}
+
+// replacePlaybookSharedTeams replaces the teams a playbook is shared with
+func (p *playbookStore) replacePlaybookSharedTeams(q queryExecer, playbook app.Playbook) error {
+	playbook.NormalizeSharedTeams()
+
+	delBuilder := sq.Delete("IR_PlaybookTeam").
+		Where(sq.Eq{"PlaybookID": playbook.ID})
+	if _, err := p.store.execBuilder(q, delBuilder); err != nil {
+		return err
+	}
+
+	if len(playbook.SharedTeamIDs) == 0 {
+		return nil
+	}
+
+	insertBuilder := sq.Insert("IR_PlaybookTeam").
+		Columns("PlaybookID", "TeamID")
+	for _, teamID := range playbook.SharedTeamIDs {
+		insertBuilder = insertBuilder.Values(playbook.ID, teamID)
+	}
+	if _, err := p.store.execBuilder(q, insertBuilder); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// replacePlaybookMembers replaces the members of a playbook, and their roles
 func (p *playbookStore) replacePlaybookMembers(q queryExecer, playbook app.Playbook) error {
+	playbook.NormalizeMembers()
+
 	// Delete existing members who are not in the new playbook.MemberIDs list
 	delBuilder := sq.Delete("IR_PlaybookMember").
 		Where(sq.Eq{"PlaybookID": playbook.ID}).
@@ -616,46 +935,101 @@ func (p *playbookStore) replacePlaybookMembers(q queryExecer, playbook app.Playb
 		return err
 	}
 
-	if len(playbook.MemberIDs) == 0 {
+	if err := p.replacePlaybookMemberGroups(q, playbook); err != nil {
+		return err
+	}
+
+	if len(playbook.Members) == 0 {
 		return nil
 	}
 
 	insertExpr := `
-INSERT INTO IR_PlaybookMember(PlaybookID, MemberID)
-    SELECT ?, ?
+INSERT INTO IR_PlaybookMember(PlaybookID, MemberID, Role)
+    SELECT ?, ?, ?
     WHERE NOT EXISTS (
         SELECT 1 FROM IR_PlaybookMember
             WHERE PlaybookID = ? AND MemberID = ?
     );`
 	if p.store.db.DriverName() == model.DatabaseDriverMysql {
 		insertExpr = `
-INSERT INTO IR_PlaybookMember(PlaybookID, MemberID)
-    SELECT ?, ? FROM DUAL
+INSERT INTO IR_PlaybookMember(PlaybookID, MemberID, Role)
+    SELECT ?, ?, ? FROM DUAL
     WHERE NOT EXISTS (
         SELECT 1 FROM IR_PlaybookMember
             WHERE PlaybookID = ? AND MemberID = ?
     );`
 	}
 
-	for _, m := range playbook.MemberIDs {
+	for _, m := range playbook.Members {
 		rawInsert := sq.Expr(insertExpr,
-			playbook.ID, m, playbook.ID, m)
+			playbook.ID, m.UserID, m.Role, playbook.ID, m.UserID)
 
 		if _, err := p.store.execBuilder(q, rawInsert); err != nil {
 			return err
 		}
+
+		// The member may already exist with another role.
+		updateRole := sq.Update("IR_PlaybookMember").
+			Set("Role", m.Role).
+			Where(sq.Eq{"PlaybookID": playbook.ID, "MemberID": m.UserID}).
+			Where(sq.NotEq{"Role": m.Role})
+		if _, err := p.store.execBuilder(q, updateRole); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func addMembersToPlaybooks(memberIDs playbookMembers, playbook []app.Playbook) {
-	pToM := make(map[string][]string)
+// replacePlaybookMemberGroups replaces the member groups of a playbook, and their roles
+func (p *playbookStore) replacePlaybookMemberGroups(q queryExecer, playbook app.Playbook) error {
+	delBuilder := sq.Delete("IR_PlaybookMemberGroup").
+		Where(sq.Eq{"PlaybookID": playbook.ID})
+	if _, err := p.store.execBuilder(q, delBuilder); err != nil {
+		return err
+	}
+
+	if len(playbook.MemberGroups) == 0 {
+		return nil
+	}
+
+	insertBuilder := sq.Insert("IR_PlaybookMemberGroup").
+		Columns("PlaybookID", "Type", "GroupID", "Role")
+	for _, g := range playbook.MemberGroups {
+		insertBuilder = insertBuilder.Values(playbook.ID, g.Type, g.ID, g.Role)
+	}
+	if _, err := p.store.execBuilder(q, insertBuilder); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func addMembersToPlaybooks(memberIDs playbookMembers, memberGroups playbookMemberGroups, playbook []app.Playbook) {
+	pToM := make(map[string][]app.PlaybookMember)
 	for _, m := range memberIDs {
-		pToM[m.PlaybookID] = append(pToM[m.PlaybookID], m.MemberID)
+		pToM[m.PlaybookID] = append(pToM[m.PlaybookID], app.PlaybookMember{UserID: m.MemberID, Role: m.Role})
+	}
+	pToG := make(map[string][]app.PlaybookMemberGroup)
+	for _, g := range memberGroups {
+		pToG[g.PlaybookID] = append(pToG[g.PlaybookID], app.PlaybookMemberGroup{Type: g.Type, ID: g.GroupID, Role: g.Role})
 	}
 	for i, p := range playbook {
-		playbook[i].MemberIDs = pToM[p.ID]
+		playbook[i].Members = pToM[p.ID]
+		playbook[i].MemberGroups = pToG[p.ID]
+		for _, m := range pToM[p.ID] {
+			playbook[i].MemberIDs = append(playbook[i].MemberIDs, m.UserID)
+		}
+	}
+}
+
+func addSharedTeamsToPlaybooks(sharedTeams playbookSharedTeams, playbooks []app.Playbook) {
+	pToT := make(map[string][]string)
+	for _, t := range sharedTeams {
+		pToT[t.PlaybookID] = append(pToT[t.PlaybookID], t.TeamID)
+	}
+	for i, p := range playbooks {
+		playbooks[i].SharedTeamIDs = pToT[p.ID]
 	}
 }
 
@@ -674,9 +1048,24 @@ func toSQLPlaybook(playbook app.Playbook) (*sqlPlaybook, error) {
 		return nil, errors.Wrapf(err, "failed to marshal checklist json for playbook id: '%s'", playbook.ID)
 	}
 
+	runPermissionsJSON, err := json.Marshal(playbook.RunPermissions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal run permissions json for playbook id: '%s'", playbook.ID)
+	}
+
+	var runStatusesJSON json.RawMessage
+	if len(playbook.RunStatuses) > 0 {
+		runStatusesJSON, err = json.Marshal(playbook.RunStatuses)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal run statuses json for playbook id: '%s'", playbook.ID)
+		}
+	}
+
 	return &sqlPlaybook{
 		Playbook:                              playbook,
 		ChecklistsJSON:                        checklistsJSON,
+		RunPermissionsJSON:                    runPermissionsJSON,
+		RunStatusesJSON:                       runStatusesJSON,
 		ConcatenatedInvitedUserIDs:            strings.Join(playbook.InvitedUserIDs, ","),
 		ConcatenatedInvitedGroupIDs:           strings.Join(playbook.InvitedGroupIDs, ","),
 		ConcatenatedSignalAnyKeywords:         strings.Join(playbook.SignalAnyKeywords, ","),
@@ -694,6 +1083,20 @@ func toPlaybook(rawPlaybook sqlPlaybook) (app.Playbook, error) {
 		}
 	}
 
+	p.RunPermissions = app.RunPermissionPolicy{}
+	if len(rawPlaybook.RunPermissionsJSON) > 0 {
+		if err := json.Unmarshal(rawPlaybook.RunPermissionsJSON, &p.RunPermissions); err != nil {
+			return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal run permissions json for playbook id: '%s'", p.ID)
+		}
+	}
+
+	p.RunStatuses = nil
+	if len(rawPlaybook.RunStatusesJSON) > 0 {
+		if err := json.Unmarshal(rawPlaybook.RunStatusesJSON, &p.RunStatuses); err != nil {
+			return app.Playbook{}, errors.Wrapf(err, "failed to unmarshal run statuses json for playbook id: '%s'", p.ID)
+		}
+	}
+
 	p.InvitedUserIDs = []string(nil)
 	if rawPlaybook.ConcatenatedInvitedUserIDs != "" {
 		p.InvitedUserIDs = strings.Split(rawPlaybook.ConcatenatedInvitedUserIDs, ",")
@@ -34,6 +34,10 @@ type sqlPlaybookRun struct {
 	ConcatenatedBroadcastChannelIDs       string
 	ConcatenatedWebhookOnCreationURLs     string
 	ConcatenatedWebhookOnStatusUpdateURLs string
+	ConcatenatedAssigneeIDs               string
+	OpenItemCount                         int
+	CustomDataJSON                        json.RawMessage
+	RunStatusesJSON                       json.RawMessage
 }
 
 // playbookRunStore holds the information needed to fulfill the methods in the store interface.
@@ -55,61 +59,75 @@ type playbookRunStatusPosts []struct {
 	app.StatusPost
 }
 
-func applyPlaybookRunFilterOptionsSort(builder sq.SelectBuilder, options app.PlaybookRunFilterOptions) (sq.SelectBuilder, error) {
-	var sort string
-	switch options.Sort {
+func playbookRunsPagination(options app.PlaybookRunFilterOptions) (pagination, error) {
+	sort := options.Sort
+	var column string
+	switch sort {
 	case app.SortByCreateAt:
-		sort = "CreateAt"
+		column = "i.CreateAt"
 	case app.SortByID:
-		sort = "ID"
+		column = "i.ID"
 	case app.SortByName:
-		sort = "Name"
+		column = "c.DisplayName"
 	case app.SortByOwnerUserID:
-		sort = "OwnerUserID"
+		column = "i.CommanderUserID"
 	case app.SortByTeamID:
-		sort = "TeamID"
+		column = "i.TeamID"
 	case app.SortByEndAt:
-		sort = "EndAt"
+		column = "i.EndAt"
 	case app.SortByStatus:
-		sort = "CurrentStatus"
+		column = "i.CurrentStatus"
 	case app.SortByLastStatusUpdateAt:
-		sort = "LastStatusUpdateAt"
+		column = "i.LastStatusUpdateAt"
 	case "":
 		// Default to a stable sort if none explicitly provided.
-		sort = "ID"
+		sort = app.SortByID
+		column = "i.ID"
 	default:
-		return sq.SelectBuilder{}, errors.Errorf("unsupported sort parameter '%s'", options.Sort)
+		return pagination{}, errors.Errorf("unsupported sort parameter '%s'", options.Sort)
 	}
 
-	var direction string
 	switch options.Direction {
 	case app.DirectionAsc:
-		direction = "ASC"
 	case app.DirectionDesc:
-		direction = "DESC"
 	case "":
 		// Default to an ascending sort if none explicitly provided.
-		direction = "ASC"
 	default:
-		return sq.SelectBuilder{}, errors.Errorf("unsupported direction parameter '%s'", options.Direction)
-	}
-
-	builder = builder.OrderByClause(fmt.Sprintf("%s %s", sort, direction))
+		return pagination{}, errors.Errorf("unsupported direction parameter '%s'", options.Direction)
+	}
+
+	return pagination{
+		Page:      options.Page,
+		PerPage:   options.PerPage,
+		Cursor:    options.Cursor,
+		SkipCount: options.SkipCount,
+		Sort:      sort,
+		Direction: options.Direction,
+		Column:    column,
+		IDColumn:  "i.ID",
+	}, nil
+}
 
-	page := options.Page
-	perPage := options.PerPage
-	if page < 0 {
-		page = 0
-	}
-	if perPage < 0 {
-		perPage = 0
+// playbookRunSortValue returns the value of the sort field of the playbook run, for its cursor.
+func playbookRunSortValue(playbookRun app.PlaybookRun, sort app.SortField) interface{} {
+	switch sort {
+	case app.SortByCreateAt:
+		return playbookRun.CreateAt
+	case app.SortByName:
+		return playbookRun.Name
+	case app.SortByOwnerUserID:
+		return playbookRun.OwnerUserID
+	case app.SortByTeamID:
+		return playbookRun.TeamID
+	case app.SortByEndAt:
+		return playbookRun.EndAt
+	case app.SortByStatus:
+		return playbookRun.CurrentStatus
+	case app.SortByLastStatusUpdateAt:
+		return playbookRun.LastStatusUpdateAt
+	default:
+		return playbookRun.ID
 	}
-
-	builder = builder.
-		Offset(uint64(page * perPage)).
-		Limit(uint64(perPage))
-
-	return builder, nil
 }
 
 // NewPlaybookRunStore creates a new store for playbook run ServiceImpl.
@@ -145,7 +163,7 @@ func NewPlaybookRunStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQ
 			"COALESCE(ReminderMessageTemplate, '') ReminderMessageTemplate", "ReminderTimerDefaultSeconds", "ConcatenatedInvitedUserIDs", "ConcatenatedInvitedGroupIDs", "DefaultCommanderID AS DefaultOwnerID",
 			"ConcatenatedBroadcastChannelIDs", "ConcatenatedWebhookOnCreationURLs", "Retrospective", "MessageOnJoin", "RetrospectivePublishedAt", "RetrospectiveReminderIntervalSeconds",
 			"RetrospectiveWasCanceled", "ConcatenatedWebhookOnStatusUpdateURLs", "ExportChannelOnFinishedEnabled",
-			"COALESCE(CategoryName, '') CategoryName").
+			"COALESCE(CategoryName, '') CategoryName", "i.CustomDataJSON", "i.RunStatusesJSON", "i.CurrentRunStatus").
 		Column(participantsCol).
 		From("IR_Incident AS i").
 		Join("Channels AS c ON (c.Id = i.ChannelId)")
@@ -210,8 +228,13 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 		Where(teamLimitExpr)
 
 	if len(options.Statuses) != 0 {
-		queryForResults = queryForResults.Where(sq.Eq{"i.CurrentStatus": options.Statuses})
-		queryForTotal = queryForTotal.Where(sq.Eq{"i.CurrentStatus": options.Statuses})
+		// The statuses are either InProgress and Finished, or the names of custom run statuses.
+		statusesExpr := sq.Or{
+			sq.Eq{"i.CurrentStatus": options.Statuses},
+			sq.Eq{"i.CurrentRunStatus": options.Statuses},
+		}
+		queryForResults = queryForResults.Where(statusesExpr)
+		queryForTotal = queryForTotal.Where(statusesExpr)
 	}
 
 	if options.OwnerID != "" {
@@ -237,6 +260,37 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 		queryForTotal = queryForTotal.Where(sq.Eq{"i.PlaybookID": options.PlaybookID})
 	}
 
+	if len(options.PlaybookIDs) != 0 {
+		queryForResults = queryForResults.Where(sq.Eq{"i.PlaybookID": options.PlaybookIDs})
+		queryForTotal = queryForTotal.Where(sq.Eq{"i.PlaybookID": options.PlaybookIDs})
+	}
+
+	if options.ReporterID != "" {
+		queryForResults = queryForResults.Where(sq.Eq{"i.ReporterUserID": options.ReporterID})
+		queryForTotal = queryForTotal.Where(sq.Eq{"i.ReporterUserID": options.ReporterID})
+	}
+
+	if options.AssigneeID != "" {
+		// The ids have a fixed length, so matching a substring can't match part of another id.
+		assigneeClause := sq.Like{"i.ConcatenatedAssigneeIDs": fmt.Sprint("%", options.AssigneeID, "%")}
+		queryForResults = queryForResults.Where(assigneeClause)
+		queryForTotal = queryForTotal.Where(assigneeClause)
+	}
+
+	if options.StatusUpdateOverdue {
+		overdueClause := sq.And{
+			sq.Eq{"i.CurrentStatus": app.StatusInProgress},
+			statusUpdateOverdueExpr(s.store.db.DriverName()),
+		}
+		queryForResults = queryForResults.Where(overdueClause)
+		queryForTotal = queryForTotal.Where(overdueClause)
+	}
+
+	if options.OpenTasksGTE > 0 {
+		queryForResults = queryForResults.Where(sq.GtOrEq{"i.OpenItemCount": options.OpenTasksGTE})
+		queryForTotal = queryForTotal.Where(sq.GtOrEq{"i.OpenItemCount": options.OpenTasksGTE})
+	}
+
 	// TODO: do we need to sanitize (replace any '%'s in the search term)?
 	if options.SearchTerm != "" {
 		column := "c.DisplayName"
@@ -259,10 +313,17 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 	queryForResults = queryStartedBetweenTimes(queryForResults, options.StartedGTE, options.StartedLT)
 	queryForTotal = queryStartedBetweenTimes(queryForTotal, options.StartedGTE, options.StartedLT)
 
-	queryForResults, err := applyPlaybookRunFilterOptionsSort(queryForResults, options)
+	queryForResults = queryFinishedBetweenTimes(queryForResults, options.FinishedGTE, options.FinishedLT)
+	queryForTotal = queryFinishedBetweenTimes(queryForTotal, options.FinishedGTE, options.FinishedLT)
+
+	page, err := playbookRunsPagination(options)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to apply sort options")
 	}
+	queryForResults, err = page.apply(queryForResults)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply pagination options")
+	}
 
 	tx, err := s.store.db.Beginx()
 	if err != nil {
@@ -275,15 +336,22 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 		return nil, errors.Wrap(err, "failed to query for playbook runs")
 	}
 
-	var total int
-	if err = s.store.getBuilder(tx, &total, queryForTotal); err != nil {
-		return nil, errors.Wrap(err, "failed to get total count")
-	}
-	pageCount := 0
-	if options.PerPage > 0 {
-		pageCount = int(math.Ceil(float64(total) / float64(options.PerPage)))
+	var total, pageCount int
+	if !options.SkipCount {
+		if err = s.store.getBuilder(tx, &total, queryForTotal); err != nil {
+			return nil, errors.Wrap(err, "failed to get total count")
+		}
+		if options.PerPage > 0 {
+			pageCount = int(math.Ceil(float64(total) / float64(options.PerPage)))
+		}
 	}
+
 	hasMore := options.Page+1 < pageCount
+	if page.fetchesExtra() {
+		var numItems int
+		numItems, hasMore = page.trimExtra(len(rawPlaybookRuns))
+		rawPlaybookRuns = rawPlaybookRuns[:numItems]
+	}
 
 	playbookRuns := make([]app.PlaybookRun, 0, len(rawPlaybookRuns))
 	playbookRunIDs := make([]string, 0, len(rawPlaybookRuns))
@@ -320,11 +388,18 @@ func (s *playbookRunStore) GetPlaybookRuns(requesterInfo app.RequesterInfo, opti
 	addStatusPostsToPlaybookRuns(statusPosts, playbookRuns)
 	addTimelineEventsToPlaybookRuns(timelineEvents, playbookRuns)
 
+	var nextCursor string
+	if hasMore && len(playbookRuns) > 0 {
+		last := playbookRuns[len(playbookRuns)-1]
+		nextCursor = page.nextCursor(playbookRunSortValue(last, page.Sort), last.ID)
+	}
+
 	return &app.GetPlaybookRunsResults{
 		TotalCount: total,
 		PageCount:  pageCount,
 		HasMore:    hasMore,
 		Items:      playbookRuns,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -379,6 +454,11 @@ func (s *playbookRunStore) CreatePlaybookRun(playbookRun *app.PlaybookRun) (*app
 			"ConcatenatedWebhookOnStatusUpdateURLs": rawPlaybookRun.ConcatenatedWebhookOnStatusUpdateURLs,
 			"ExportChannelOnFinishedEnabled":        rawPlaybookRun.ExportChannelOnFinishedEnabled,
 			"CategoryName":                          rawPlaybookRun.CategoryName,
+			"ConcatenatedAssigneeIDs":               rawPlaybookRun.ConcatenatedAssigneeIDs,
+			"OpenItemCount":                         rawPlaybookRun.OpenItemCount,
+			"CustomDataJSON":                        rawPlaybookRun.CustomDataJSON,
+			"RunStatusesJSON":                       rawPlaybookRun.RunStatusesJSON,
+			"CurrentRunStatus":                      rawPlaybookRun.CurrentRunStatus,
 			// Preserved for backwards compatibility with v1.2
 			"ActiveStage":      0,
 			"ActiveStageTitle": "",
@@ -390,6 +470,8 @@ func (s *playbookRunStore) CreatePlaybookRun(playbookRun *app.PlaybookRun) (*app
 		return nil, errors.Wrapf(err, "failed to store new playbook run")
 	}
 
+	s.indexForSearch(playbookRun.ID)
+
 	return playbookRun, nil
 }
 
@@ -407,6 +489,13 @@ func (s *playbookRunStore) UpdatePlaybookRun(playbookRun *app.PlaybookRun) error
 		return err
 	}
 
+	// Only the changes to the indexed texts need the run to be indexed again.
+	var previous searchableRun
+	previousErr := s.store.getBuilder(s.store.db, &previous, s.store.builder.
+		Select("ID", "COALESCE(Description, '') AS Description", "COALESCE(Retrospective, '') AS Retrospective", "ChecklistsJSON").
+		From("IR_Incident").
+		Where(sq.Eq{"ID": rawPlaybookRun.ID}))
+
 	// When adding a PlaybookRun column #3: add to this SetMap (if it is a column that can be updated)
 	_, err = s.store.execBuilder(s.store.db, sq.
 		Update("IR_Incident").
@@ -430,6 +519,11 @@ func (s *playbookRunStore) UpdatePlaybookRun(playbookRun *app.PlaybookRun) error
 			"RetrospectiveWasCanceled":              rawPlaybookRun.RetrospectiveWasCanceled,
 			"ConcatenatedWebhookOnStatusUpdateURLs": rawPlaybookRun.ConcatenatedWebhookOnStatusUpdateURLs,
 			"ExportChannelOnFinishedEnabled":        rawPlaybookRun.ExportChannelOnFinishedEnabled,
+			"ConcatenatedAssigneeIDs":               rawPlaybookRun.ConcatenatedAssigneeIDs,
+			"OpenItemCount":                         rawPlaybookRun.OpenItemCount,
+			"CustomDataJSON":                        rawPlaybookRun.CustomDataJSON,
+			"RunStatusesJSON":                       rawPlaybookRun.RunStatusesJSON,
+			"CurrentRunStatus":                      rawPlaybookRun.CurrentRunStatus,
 		}).
 		Where(sq.Eq{"ID": rawPlaybookRun.ID}))
 
@@ -437,6 +531,10 @@ func (s *playbookRunStore) UpdatePlaybookRun(playbookRun *app.PlaybookRun) error
 		return errors.Wrapf(err, "failed to update playbook run with id '%s'", rawPlaybookRun.ID)
 	}
 
+	if previousErr != nil || searchableRunChanged(previous, playbookRun) {
+		s.indexForSearch(rawPlaybookRun.ID)
+	}
+
 	return nil
 }
 
@@ -460,6 +558,8 @@ func (s *playbookRunStore) UpdateStatus(statusPost *app.SQLStatusPost) error {
 		return errors.Wrap(err, "failed to add new status post")
 	}
 
+	s.indexForSearch(statusPost.PlaybookRunID)
+
 	return nil
 }
 
@@ -515,6 +615,10 @@ func (s *playbookRunStore) CreateTimelineEvent(event *app.TimelineEvent) (*app.T
 		return nil, errors.Wrap(err, "failed to insert timeline event")
 	}
 
+	if strings.TrimSpace(event.Summary) != "" || strings.TrimSpace(event.Details) != "" {
+		s.indexForSearch(event.PlaybookRunID)
+	}
+
 	return event, nil
 }
 
@@ -535,6 +639,18 @@ func (s *playbookRunStore) UpdateTimelineEvent(event *app.TimelineEvent) error {
 		eventType = legacyEventTypeCommanderChanged
 	}
 
+	// Only the changes to the indexed texts need the run to be indexed again.
+	var previous struct {
+		IncidentID string
+		Summary    string
+		Details    string
+		DeleteAt   int64
+	}
+	previousErr := s.store.getBuilder(s.store.db, &previous, s.store.builder.
+		Select("IncidentID", "COALESCE(Summary, '') AS Summary", "COALESCE(Details, '') AS Details", "DeleteAt").
+		From("IR_TimelineEvent").
+		Where(sq.Eq{"ID": event.ID}))
+
 	_, err := s.store.execBuilder(s.store.db, sq.
 		Update("IR_TimelineEvent").
 		SetMap(map[string]interface{}{
@@ -555,6 +671,14 @@ func (s *playbookRunStore) UpdateTimelineEvent(event *app.TimelineEvent) error {
 		return errors.Wrap(err, "failed to update timeline event")
 	}
 
+	if previousErr != nil ||
+		previous.IncidentID != event.PlaybookRunID ||
+		previous.Summary != event.Summary ||
+		previous.Details != event.Details ||
+		(previous.DeleteAt == 0) != (event.DeleteAt == 0) {
+		s.indexForSearch(event.PlaybookRunID)
+	}
+
 	return nil
 }
 
@@ -710,7 +834,7 @@ func (s *playbookRunStore) NukeDB() (err error) {
 	}
 	defer s.store.finalizeTransaction(tx)
 
-	if _, err := tx.Exec("DROP TABLE IF EXISTS IR_PlaybookMember,  IR_StatusPosts, IR_TimelineEvent, IR_Incident, IR_Playbook, IR_System"); err != nil {
+	if _, err := tx.Exec("DROP TABLE IF EXISTS IR_PlaybookMember, IR_PlaybookMemberGroup, IR_PlaybookTeam, IR_StatusPosts, IR_TimelineEvent, IR_SearchDocument, IR_Incident, IR_Playbook, IR_System"); err != nil {
 		return errors.Wrap(err, "could not delete all IR tables")
 	}
 
@@ -851,26 +975,18 @@ func (s *playbookRunStore) buildPermissionsExpr(info app.RequesterInfo) sq.Sqliz
 	}
 
 	// 1. Is the user a channel member? If so, they have permission to view the run.
-	// 2. Is the playbook open to everyone on the team, or is the user a member of the playbook?
-	//    If so, they have permission to view the run.
-	return sq.Expr(`
-        ((
+	// 2. Is the playbook open to everyone on the team, or is the user a member of the playbook,
+	//    directly or through a member group? If so, they have permission to view the run.
+	return sq.Or{
+		sq.Expr(`
 			EXISTS (
-                    SELECT 1
-						FROM ChannelMembers as cm
-						WHERE cm.ChannelId = i.ChannelId
-						  AND cm.UserId = ?)
-			) OR (
-				  NOT EXISTS(
-						SELECT 1
-							FROM IR_PlaybookMember
-							WHERE PlaybookID = i.PlaybookID)
-				  OR EXISTS(
-						SELECT 1
-							FROM IR_PlaybookMember
-							WHERE PlaybookID = i.PlaybookID
-							  AND MemberID = ?)
-		))`, info.UserID, info.UserID)
+				SELECT 1
+					FROM ChannelMembers as cm
+					WHERE cm.ChannelId = i.ChannelId
+					  AND cm.UserId = ?)
+		`, info.UserID),
+		playbookAccessExpr("i.PlaybookID", info.UserID),
+	}
 }
 
 func buildTeamLimitExpr(userID, teamID, tableName string) sq.Sqlizer {
@@ -923,6 +1039,20 @@ func (s *playbookRunStore) toPlaybookRun(rawPlaybookRun sqlPlaybookRun) (*app.Pl
 		playbookRun.WebhookOnStatusUpdateURLs = strings.Split(rawPlaybookRun.ConcatenatedWebhookOnStatusUpdateURLs, ",")
 	}
 
+	playbookRun.CustomData = nil
+	if len(rawPlaybookRun.CustomDataJSON) > 0 {
+		if err := json.Unmarshal(rawPlaybookRun.CustomDataJSON, &playbookRun.CustomData); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal custom data json for playbook run id: %s", rawPlaybookRun.ID)
+		}
+	}
+
+	playbookRun.RunStatuses = nil
+	if len(rawPlaybookRun.RunStatusesJSON) > 0 {
+		if err := json.Unmarshal(rawPlaybookRun.RunStatusesJSON, &playbookRun.RunStatuses); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal run statuses json for playbook run id: %s", rawPlaybookRun.ID)
+		}
+	}
+
 	return &playbookRun, nil
 }
 
@@ -1022,15 +1152,9 @@ func (s *playbookRunStore) GetOverdueUpdateRuns(userID string) ([]app.RunLink, e
 		Join("Channels AS c ON (i.ChannelId = c.Id)").
 		Where(sq.Eq{"i.CommanderUserID": userID}).
 		Where(sq.Eq{"i.CurrentStatus": app.StatusInProgress}).
-		Where(sq.NotEq{"i.PreviousReminder": 0}).
+		Where(statusUpdateOverdueExpr(s.store.db.DriverName())).
 		OrderBy("ChannelDisplayName")
 
-	if s.store.db.DriverName() == model.DatabaseDriverMysql {
-		query = query.Where(sq.Expr("(i.PreviousReminder / 1e6 + i.LastStatusUpdateAt) <= FLOOR(UNIX_TIMESTAMP() * 1000)"))
-	} else {
-		query = query.Where(sq.Expr("(i.PreviousReminder / 1e6 + i.LastStatusUpdateAt) <= FLOOR(EXTRACT (EPOCH FROM now())::float*1000)"))
-	}
-
 	var ret []app.RunLink
 	if err := s.store.selectBuilder(s.store.db, &ret, query); err != nil {
 		return nil, errors.Wrap(err, "failed to query for active runs")
@@ -1046,9 +1170,31 @@ func toSQLPlaybookRun(playbookRun app.PlaybookRun) (*sqlPlaybookRun, error) {
 		return nil, errors.Wrapf(err, "failed to marshal checklist json for playbook run id '%s'", playbookRun.ID)
 	}
 
+	assigneeIDs, openItemCount := checklistsAssigneesAndOpenItems(newChecklists)
+
+	var customDataJSON json.RawMessage
+	if len(playbookRun.CustomData) > 0 {
+		customDataJSON, err = json.Marshal(playbookRun.CustomData)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal custom data json for playbook run id '%s'", playbookRun.ID)
+		}
+	}
+
+	var runStatusesJSON json.RawMessage
+	if len(playbookRun.RunStatuses) > 0 {
+		runStatusesJSON, err = json.Marshal(playbookRun.RunStatuses)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to marshal run statuses json for playbook run id '%s'", playbookRun.ID)
+		}
+	}
+
 	return &sqlPlaybookRun{
 		PlaybookRun:                           playbookRun,
 		ChecklistsJSON:                        checklistsJSON,
+		ConcatenatedAssigneeIDs:               strings.Join(assigneeIDs, ","),
+		OpenItemCount:                         openItemCount,
+		CustomDataJSON:                        customDataJSON,
+		RunStatusesJSON:                       runStatusesJSON,
 		ConcatenatedInvitedUserIDs:            strings.Join(playbookRun.InvitedUserIDs, ","),
 		ConcatenatedInvitedGroupIDs:           strings.Join(playbookRun.InvitedGroupIDs, ","),
 		ConcatenatedBroadcastChannelIDs:       strings.Join(playbookRun.BroadcastChannelIDs, ","),
@@ -1057,6 +1203,27 @@ func toSQLPlaybookRun(playbookRun app.PlaybookRun) (*sqlPlaybookRun, error) {
 	}, nil
 }
 
+// checklistsAssigneesAndOpenItems returns the distinct assignees of the checklist items, in order
+// of appearance, and the number of items that are not closed yet.
+func checklistsAssigneesAndOpenItems(checklists []app.Checklist) ([]string, int) {
+	var assigneeIDs []string
+	seen := make(map[string]bool)
+	openItemCount := 0
+	for _, checklist := range checklists {
+		for _, item := range checklist.Items {
+			if item.AssigneeID != "" && !seen[item.AssigneeID] {
+				seen[item.AssigneeID] = true
+				assigneeIDs = append(assigneeIDs, item.AssigneeID)
+			}
+			if item.State != app.ChecklistItemStateClosed {
+				openItemCount++
+			}
+		}
+	}
+
+	return assigneeIDs, openItemCount
+}
+
 // populateChecklistIDs returns a cloned slice with ids entered for checklists and checklist items.
 func populateChecklistIDs(checklists []app.Checklist) []app.Checklist {
 	if len(checklists) == 0 {
@@ -1161,3 +1328,35 @@ func queryStarted(query sq.SelectBuilder, start int64, end int64) sq.SelectBuild
 			sq.Lt{"i.CreateAt": end},
 		})
 }
+
+// queryFinishedBetweenTimes will modify the query only if one (or both) of start and end are non-zero.
+// If both are non-zero, return the playbook runs finished between those two times.
+// If start is zero, return the playbook runs finished before the end time.
+// If end is zero, return the playbook runs finished after the start time.
+func queryFinishedBetweenTimes(query sq.SelectBuilder, start int64, end int64) sq.SelectBuilder {
+	if start > 0 {
+		query = query.Where(sq.GtOrEq{"i.EndAt": start})
+	}
+	if end > 0 {
+		query = query.Where(sq.And{
+			sq.Gt{"i.EndAt": 0},
+			sq.Lt{"i.EndAt": end},
+		})
+	}
+
+	return query
+}
+
+// statusUpdateOverdueExpr matches the playbook runs with a reminder set whose deadline for the next
+// status update has passed.
+func statusUpdateOverdueExpr(driverName string) sq.Sqlizer {
+	now := "FLOOR(EXTRACT (EPOCH FROM now())::float*1000)"
+	if driverName == model.DatabaseDriverMysql {
+		now = "FLOOR(UNIX_TIMESTAMP() * 1000)"
+	}
+
+	return sq.And{
+		sq.NotEq{"i.PreviousReminder": 0},
+		sq.Expr("(i.PreviousReminder / 1e6 + i.LastStatusUpdateAt) <= " + now),
+	}
+}
@@ -0,0 +1,531 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// searchTextConfig is the Postgres text search configuration used to index and query
+	// the search documents. It must match the one in the IR_SearchDocument index.
+	searchTextConfig = "english"
+
+	// searchDocumentMaxLength is the maximum length, in bytes, of an indexed document. Longer
+	// texts are truncated, keeping well below the 1MB limit of a Postgres tsvector.
+	searchDocumentMaxLength = 64 * 1024
+
+	// searchSourceIDMaxLength is the length of the SourceID column of IR_SearchDocument.
+	searchSourceIDMaxLength = 32
+
+	// searchMatchesPerRun is the maximum number of matches returned for each run.
+	searchMatchesPerRun = 3
+
+	// searchSnippetLength is the approximate length, in characters, of the snippets built
+	// for MySQL, which has no equivalent of ts_headline.
+	searchSnippetLength = 160
+
+	searchHighlightStart = "**"
+	searchHighlightStop  = "**"
+)
+
+// searchDocument is a piece of text of a playbook run indexed for search.
+type searchDocument struct {
+	IncidentID string
+	Source     string
+	SourceID   string
+	Content    string
+}
+
+type searchableRun struct {
+	ID             string
+	Name           string
+	Description    string
+	Retrospective  string
+	ChecklistsJSON json.RawMessage
+}
+
+type searchableText struct {
+	ID      string
+	Content string
+}
+
+type searchRankedRun struct {
+	IncidentID string
+	Score      float64
+}
+
+type searchMatch struct {
+	IncidentID string
+	Source     string
+	SourceID   string
+	Content    string
+	Snippet    string
+	Score      float64
+}
+
+type searchRunInfo struct {
+	ID            string
+	Name          string
+	TeamID        string
+	CurrentStatus string
+	CreateAt      int64
+}
+
+// playbookRunSearchDocuments returns the documents to index for the given playbook run, its
+// status posts and its timeline events. Empty texts are skipped.
+func playbookRunSearchDocuments(run searchableRun, checklists []app.Checklist, statusPosts, timelineEvents []searchableText) []searchDocument {
+	var documents []searchDocument
+	add := func(source, sourceID string, texts ...string) {
+		var nonEmpty []string
+		for _, text := range texts {
+			if text = strings.TrimSpace(text); text != "" {
+				nonEmpty = append(nonEmpty, text)
+			}
+		}
+		if len(nonEmpty) == 0 {
+			return
+		}
+
+		documents = append(documents, searchDocument{
+			IncidentID: run.ID,
+			Source:     source,
+			SourceID:   sourceID,
+			Content:    truncateSearchDocument(strings.Join(nonEmpty, "\n")),
+		})
+	}
+
+	add(app.SearchSourceName, "", run.Name)
+	add(app.SearchSourceDescription, "", run.Description)
+	add(app.SearchSourceRetrospective, "", run.Retrospective)
+
+	for _, statusPost := range statusPosts {
+		add(app.SearchSourceStatusUpdate, statusPost.ID, statusPost.Content)
+	}
+
+	for _, timelineEvent := range timelineEvents {
+		add(app.SearchSourceTimelineEvent, timelineEvent.ID, timelineEvent.Content)
+	}
+
+	itemIDs := make(map[string]bool)
+	for i, checklist := range checklists {
+		for j, item := range checklist.Items {
+			// Items created by older versions have no ID, and the IDs of the items are up to
+			// the clients, so fall back to their position unless their ID is a unique key.
+			itemID := item.ID
+			if itemID == "" || len(itemID) > searchSourceIDMaxLength || itemIDs[itemID] {
+				itemID = fmt.Sprintf("%d-%d", i, j)
+			}
+			itemIDs[itemID] = true
+			add(app.SearchSourceChecklistItem, itemID, item.Title, item.Description)
+		}
+	}
+
+	return documents
+}
+
+// searchableRunChanged returns true if the description, retrospective or checklist items of
+// playbookRun, as indexed for search, differ from those of previous. Previous checklists that
+// can't be read count as changed.
+func searchableRunChanged(previous searchableRun, playbookRun *app.PlaybookRun) bool {
+	var previousChecklists []app.Checklist
+	if len(previous.ChecklistsJSON) > 0 {
+		if err := json.Unmarshal(previous.ChecklistsJSON, &previousChecklists); err != nil {
+			return true
+		}
+	}
+
+	previous.ID = playbookRun.ID
+	previous.Name = ""
+	current := searchableRun{
+		ID:            playbookRun.ID,
+		Description:   playbookRun.Description,
+		Retrospective: playbookRun.Retrospective,
+	}
+
+	return !reflect.DeepEqual(
+		playbookRunSearchDocuments(previous, previousChecklists, nil, nil),
+		playbookRunSearchDocuments(current, playbookRun.Checklists, nil, nil),
+	)
+}
+
+// truncateSearchDocument truncates content to searchDocumentMaxLength bytes, without
+// splitting a multi-byte character.
+func truncateSearchDocument(content string) string {
+	if len(content) <= searchDocumentMaxLength {
+		return content
+	}
+
+	end := searchDocumentMaxLength
+	for end > 0 && !utf8.RuneStart(content[end]) {
+		end--
+	}
+
+	return content[:end]
+}
+
+// indexPlaybookRunForSearch replaces the search documents of the given playbook run with
+// documents built from its current contents.
+func (sqlStore *SQLStore) indexPlaybookRunForSearch(e sqlx.Ext, playbookRunID string) error {
+	var run searchableRun
+	err := sqlStore.getBuilder(e, &run, sqlStore.builder.
+		Select("i.ID", "c.DisplayName AS Name", "COALESCE(i.Description, '') AS Description", "COALESCE(i.Retrospective, '') AS Retrospective", "i.ChecklistsJSON").
+		From("IR_Incident AS i").
+		Join("Channels AS c ON (c.Id = i.ChannelId)").
+		Where(sq.Eq{"i.ID": playbookRunID}))
+	if err == sql.ErrNoRows {
+		// The channel of the run was deleted, leaving nothing to search.
+		run.ID = playbookRunID
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get playbook run with id '%s' to index", playbookRunID)
+	}
+
+	var checklists []app.Checklist
+	if len(run.ChecklistsJSON) > 0 {
+		if err = json.Unmarshal(run.ChecklistsJSON, &checklists); err != nil {
+			return errors.Wrapf(err, "failed to unmarshal checklists json for playbook run id: %s", playbookRunID)
+		}
+	}
+
+	var statusPosts []searchableText
+	err = sqlStore.selectBuilder(e, &statusPosts, sqlStore.builder.
+		Select("p.Id AS ID", "COALESCE(p.Message, '') AS Content").
+		From("IR_StatusPosts AS sp").
+		Join("Posts AS p ON sp.PostID = p.Id").
+		Where(sq.Eq{"sp.IncidentID": playbookRunID}).
+		Where(sq.Eq{"p.DeleteAt": 0}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get status posts of playbook run with id '%s' to index", playbookRunID)
+	}
+
+	var rawTimelineEvents []struct {
+		ID      string
+		Summary string
+		Details string
+	}
+	err = sqlStore.selectBuilder(e, &rawTimelineEvents, sqlStore.builder.
+		Select("te.ID", "COALESCE(te.Summary, '') AS Summary", "COALESCE(te.Details, '') AS Details").
+		From("IR_TimelineEvent AS te").
+		Where(sq.Eq{"te.IncidentID": playbookRunID}).
+		Where(sq.Eq{"te.DeleteAt": 0}))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get timeline events of playbook run with id '%s' to index", playbookRunID)
+	}
+
+	timelineEvents := make([]searchableText, 0, len(rawTimelineEvents))
+	for _, event := range rawTimelineEvents {
+		timelineEvents = append(timelineEvents, searchableText{
+			ID:      event.ID,
+			Content: event.Summary + "\n" + event.Details,
+		})
+	}
+
+	documents := playbookRunSearchDocuments(run, checklists, statusPosts, timelineEvents)
+
+	if _, err = sqlStore.execBuilder(e, sqlStore.builder.
+		Delete("IR_SearchDocument").
+		Where(sq.Eq{"IncidentID": playbookRunID})); err != nil {
+		return errors.Wrapf(err, "failed to delete search documents of playbook run with id '%s'", playbookRunID)
+	}
+
+	if len(documents) == 0 {
+		return nil
+	}
+
+	insert := sqlStore.builder.
+		Insert("IR_SearchDocument").
+		Columns("IncidentID", "Source", "SourceID", "Content", "UpdateAt")
+	now := model.GetMillis()
+	for _, document := range documents {
+		insert = insert.Values(document.IncidentID, document.Source, document.SourceID, document.Content, now)
+	}
+
+	if _, err = sqlStore.execBuilder(e, insert); err != nil {
+		return errors.Wrapf(err, "failed to insert search documents of playbook run with id '%s'", playbookRunID)
+	}
+
+	return nil
+}
+
+// indexForSearch indexes the given playbook run, logging instead of failing: a stale search
+// index must not prevent the run from being updated.
+func (s *playbookRunStore) indexForSearch(playbookRunID string) {
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		s.log.Warnf("failed to begin transaction to index playbook run %s for search: %v", playbookRunID, err)
+		return
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	if err = s.store.indexPlaybookRunForSearch(tx, playbookRunID); err != nil {
+		s.log.Warnf("failed to index playbook run %s for search: %v", playbookRunID, err)
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		s.log.Warnf("failed to commit the search index of playbook run %s: %v", playbookRunID, err)
+	}
+}
+
+// searchMatchExpr returns the expression matching the search documents against any of the
+// terms, and the expression of their rank, using the native full-text search of the database.
+func (s *playbookRunStore) searchMatchExpr(terms []string) (sq.Sqlizer, sq.Sqlizer) {
+	if s.store.db.DriverName() == model.DatabaseDriverMysql {
+		query := strings.Join(terms, " ")
+		return sq.Expr("MATCH(d.Content) AGAINST (? IN NATURAL LANGUAGE MODE)", query),
+			sq.Expr("MATCH(d.Content) AGAINST (? IN NATURAL LANGUAGE MODE)", query)
+	}
+
+	// The terms only contain letters and numbers, so they are safe to use in to_tsquery.
+	query := strings.Join(terms, " | ")
+	return sq.Expr("to_tsvector('"+searchTextConfig+"', d.Content) @@ to_tsquery('"+searchTextConfig+"', ?)", query),
+		sq.Expr("ts_rank(to_tsvector('"+searchTextConfig+"', d.Content), to_tsquery('"+searchTextConfig+"', ?))", query)
+}
+
+// SearchPlaybookRuns returns the playbook runs matching the search terms, most relevant first.
+func (s *playbookRunStore) SearchPlaybookRuns(requesterInfo app.RequesterInfo, options app.PlaybookRunSearchOptions) (*app.PlaybookRunSearchResults, error) {
+	results := &app.PlaybookRunSearchResults{
+		Items: []app.PlaybookRunSearchResult{},
+	}
+
+	terms := searchTerms(options.Terms)
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	matchExpr, rankExpr := s.searchMatchExpr(terms)
+	rankSQL, rankArgs, err := rankExpr.ToSql()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to build rank expression")
+	}
+
+	matchingDocuments := s.store.builder.
+		Select().
+		From("IR_SearchDocument AS d").
+		Join("IR_Incident AS i ON (i.ID = d.IncidentID)").
+		Join("Channels AS c ON (c.Id = i.ChannelId)").
+		Where(matchExpr).
+		Where(s.buildPermissionsExpr(requesterInfo)).
+		Where(buildTeamLimitExpr(requesterInfo.UserID, options.TeamID, "i"))
+
+	queryForRuns := matchingDocuments.
+		Column("d.IncidentID").
+		Column(sq.Alias(sq.Expr("SUM("+rankSQL+")", rankArgs...), "Score")).
+		GroupBy("d.IncidentID").
+		OrderBy("Score DESC", "d.IncidentID").
+		Offset(uint64(options.Page * options.PerPage)).
+		Limit(uint64(options.PerPage))
+
+	queryForTotal := matchingDocuments.Column("COUNT(DISTINCT d.IncidentID)")
+
+	tx, err := s.store.db.Beginx()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not begin transaction")
+	}
+	defer s.store.finalizeTransaction(tx)
+
+	var rankedRuns []searchRankedRun
+	if err = s.store.selectBuilder(tx, &rankedRuns, queryForRuns); err != nil {
+		return nil, errors.Wrap(err, "failed to search playbook runs")
+	}
+
+	var total int
+	if err = s.store.getBuilder(tx, &total, queryForTotal); err != nil {
+		return nil, errors.Wrap(err, "failed to get total count")
+	}
+
+	results.TotalCount = total
+	results.PageCount = int(math.Ceil(float64(total) / float64(options.PerPage)))
+	results.HasMore = options.Page+1 < results.PageCount
+
+	if len(rankedRuns) == 0 {
+		return results, nil
+	}
+
+	playbookRunIDs := make([]string, 0, len(rankedRuns))
+	for _, rankedRun := range rankedRuns {
+		playbookRunIDs = append(playbookRunIDs, rankedRun.IncidentID)
+	}
+
+	snippetExpr := sq.Expr("''")
+	if s.store.db.DriverName() == model.DatabaseDriverPostgres {
+		snippetExpr = sq.Expr(fmt.Sprintf(
+			"ts_headline('%s', d.Content, to_tsquery('%s', ?), 'StartSel=%s, StopSel=%s, MaxWords=30, MinWords=10, MaxFragments=2')",
+			searchTextConfig, searchTextConfig, searchHighlightStart, searchHighlightStop,
+		), strings.Join(terms, " | "))
+	}
+
+	var matches []searchMatch
+	err = s.store.selectBuilder(tx, &matches, s.store.builder.
+		Select("d.IncidentID", "d.Source", "d.SourceID", "d.Content").
+		Column(sq.Alias(snippetExpr, "Snippet")).
+		Column(sq.Alias(rankExpr, "Score")).
+		From("IR_SearchDocument AS d").
+		Where(matchExpr).
+		Where(sq.Eq{"d.IncidentID": playbookRunIDs}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the matches of the playbook runs")
+	}
+
+	var runInfos []searchRunInfo
+	err = s.store.selectBuilder(tx, &runInfos, s.store.builder.
+		Select("i.ID", "c.DisplayName AS Name", "i.TeamID", "i.CurrentStatus", "i.CreateAt").
+		From("IR_Incident AS i").
+		Join("Channels AS c ON (c.Id = i.ChannelId)").
+		Where(sq.Eq{"i.ID": playbookRunIDs}))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the matching playbook runs")
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "could not commit transaction")
+	}
+
+	results.Items = buildPlaybookRunSearchResults(rankedRuns, runInfos, matches, terms)
+
+	return results, nil
+}
+
+// buildPlaybookRunSearchResults assembles the search results in the order of rankedRuns,
+// keeping the best searchMatchesPerRun matches of each run. Matches without a snippet get
+// one highlighting the given terms.
+func buildPlaybookRunSearchResults(rankedRuns []searchRankedRun, runInfos []searchRunInfo, matches []searchMatch, terms []string) []app.PlaybookRunSearchResult {
+	infoByID := make(map[string]searchRunInfo, len(runInfos))
+	for _, info := range runInfos {
+		infoByID[info.ID] = info
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	matchesByID := make(map[string][]app.PlaybookRunSearchMatch, len(rankedRuns))
+	for _, match := range matches {
+		if len(matchesByID[match.IncidentID]) >= searchMatchesPerRun {
+			continue
+		}
+
+		snippet := match.Snippet
+		if snippet == "" {
+			snippet = highlightSnippet(match.Content, terms, searchSnippetLength)
+		}
+
+		matchesByID[match.IncidentID] = append(matchesByID[match.IncidentID], app.PlaybookRunSearchMatch{
+			Source:   match.Source,
+			SourceID: match.SourceID,
+			Snippet:  snippet,
+		})
+	}
+
+	results := make([]app.PlaybookRunSearchResult, 0, len(rankedRuns))
+	for _, rankedRun := range rankedRuns {
+		info, ok := infoByID[rankedRun.IncidentID]
+		if !ok {
+			// The run was deleted while searching.
+			continue
+		}
+
+		runMatches := matchesByID[rankedRun.IncidentID]
+		if runMatches == nil {
+			runMatches = []app.PlaybookRunSearchMatch{}
+		}
+
+		results = append(results, app.PlaybookRunSearchResult{
+			PlaybookRunID: info.ID,
+			Name:          info.Name,
+			TeamID:        info.TeamID,
+			CurrentStatus: info.CurrentStatus,
+			CreateAt:      info.CreateAt,
+			Rank:          rankedRun.Score,
+			Matches:       runMatches,
+		})
+	}
+
+	return results
+}
+
+// searchWordRegexp matches the words of a text, as split by searchTerms.
+var searchWordRegexp = regexp.MustCompile(`[\pL\pN]+`)
+
+// searchTerms splits the search terms into lowercase words.
+func searchTerms(terms string) []string {
+	return searchWordRegexp.FindAllString(strings.ToLower(terms), -1)
+}
+
+// highlightSnippet returns an excerpt of about length characters of content around the first
+// word matching any of the terms, surrounding every matching word with the highlight markers.
+func highlightSnippet(content string, terms []string, length int) string {
+	content = strings.Join(strings.Fields(content), " ")
+
+	isTerm := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		isTerm[term] = true
+	}
+	matchesTerm := func(word string) bool {
+		return isTerm[strings.ToLower(word)]
+	}
+
+	runes := []rune(content)
+	start := 0
+	for _, location := range searchWordRegexp.FindAllStringIndex(content, -1) {
+		if matchesTerm(content[location[0]:location[1]]) {
+			// Start the excerpt a few words before the first match.
+			matchStart := utf8.RuneCountInString(content[:location[0]])
+			start = matchStart - length/3
+			if start < 0 {
+				start = 0
+			}
+			for start > 0 && start < matchStart && runes[start-1] != ' ' {
+				start++
+			}
+			break
+		}
+	}
+
+	excerpt := truncateRunes(content, start, length)
+
+	var builder strings.Builder
+	if start > 0 {
+		builder.WriteString("...")
+	}
+	last := 0
+	for _, location := range searchWordRegexp.FindAllStringIndex(excerpt, -1) {
+		word := excerpt[location[0]:location[1]]
+		if !matchesTerm(word) {
+			continue
+		}
+		builder.WriteString(excerpt[last:location[0]])
+		builder.WriteString(searchHighlightStart + word + searchHighlightStop)
+		last = location[1]
+	}
+	builder.WriteString(excerpt[last:])
+
+	return builder.String()
+}
+
+// truncateRunes returns at most length runes of s from start, followed by an ellipsis if s
+// continues.
+func truncateRunes(s string, start, length int) string {
+	runes := []rune(s)
+	if start >= len(runes) {
+		return ""
+	}
+
+	end := start + length
+	if end >= len(runes) {
+		return string(runes[start:])
+	}
+
+	return string(runes[start:end]) + "..."
+}
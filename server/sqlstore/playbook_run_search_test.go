@@ -0,0 +1,302 @@
+package sqlstore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+	"github.com/mattermost/mattermost-server/v6/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchPlaybookRuns(t *testing.T) {
+	teamID := model.NewId()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		setupTeamMembersTable(t, db)
+
+		redisRun := NewBuilder(t).
+			WithTeamID(teamID).
+			WithName("Cache outage").
+			WithDescription("The cache cluster stopped answering").
+			WithChecklists([]int{2}).
+			ToPlaybookRun()
+		databaseRun := NewBuilder(t).
+			WithTeamID(teamID).
+			WithName("Database failover").
+			WithDescription("Primary database was unreachable").
+			ToPlaybookRun()
+		otherTeamRun := NewBuilder(t).
+			WithTeamID(model.NewId()).
+			WithName("Redis upgrade").
+			ToPlaybookRun()
+
+		for _, playbookRun := range []*app.PlaybookRun{redisRun, databaseRun, otherTeamRun} {
+			created, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+			require.NoError(t, err)
+			*playbookRun = *created
+			createPlaybookRunChannel(t, store, playbookRun)
+		}
+
+		// The channels are created after the runs, so update the run to index its name.
+		redisRun.Retrospective = "Redis ran out of memory because eviction was disabled."
+		require.NoError(t, playbookRunStore.UpdatePlaybookRun(redisRun))
+		require.NoError(t, playbookRunStore.UpdatePlaybookRun(databaseRun))
+		require.NoError(t, playbookRunStore.UpdatePlaybookRun(otherTeamRun))
+
+		_, err := playbookRunStore.CreateTimelineEvent(&app.TimelineEvent{
+			PlaybookRunID: databaseRun.ID,
+			EventType:     app.EventFromPost,
+			EventAt:       model.GetMillis(),
+			Summary:       "Redis connections spiked",
+			Details:       "Clients retried against redis in a loop",
+		})
+		require.NoError(t, err)
+
+		admin := app.RequesterInfo{UserID: model.NewId(), IsAdmin: true}
+
+		t.Run(driverName+" - ranks the matching runs and highlights the snippets", func(t *testing.T) {
+			results, err := playbookRunStore.SearchPlaybookRuns(admin, app.PlaybookRunSearchOptions{
+				Terms:   "redis memory",
+				TeamID:  teamID,
+				PerPage: 10,
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, 2, results.TotalCount)
+			require.Equal(t, 1, results.PageCount)
+			require.False(t, results.HasMore)
+			require.Len(t, results.Items, 2)
+
+			require.GreaterOrEqual(t, results.Items[0].Rank, results.Items[1].Rank)
+			resultsByID := map[string]app.PlaybookRunSearchResult{}
+			for _, result := range results.Items {
+				resultsByID[result.PlaybookRunID] = result
+			}
+
+			redisResult, ok := resultsByID[redisRun.ID]
+			require.True(t, ok)
+			require.Equal(t, "Cache outage", redisResult.Name)
+			require.Equal(t, app.SearchSourceRetrospective, redisResult.Matches[0].Source)
+			require.Contains(t, strings.ToLower(redisResult.Matches[0].Snippet), "**redis**")
+
+			databaseResult, ok := resultsByID[databaseRun.ID]
+			require.True(t, ok)
+			require.Equal(t, app.SearchSourceTimelineEvent, databaseResult.Matches[0].Source)
+		})
+
+		t.Run(driverName+" - pages the results", func(t *testing.T) {
+			results, err := playbookRunStore.SearchPlaybookRuns(admin, app.PlaybookRunSearchOptions{
+				Terms:   "redis",
+				TeamID:  teamID,
+				Page:    1,
+				PerPage: 1,
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, 2, results.TotalCount)
+			require.Equal(t, 2, results.PageCount)
+			require.False(t, results.HasMore)
+			require.Len(t, results.Items, 1)
+		})
+
+		t.Run(driverName+" - no match", func(t *testing.T) {
+			results, err := playbookRunStore.SearchPlaybookRuns(admin, app.PlaybookRunSearchOptions{
+				Terms:   "kubernetes",
+				TeamID:  teamID,
+				PerPage: 10,
+			})
+			require.NoError(t, err)
+
+			require.Zero(t, results.TotalCount)
+			require.Empty(t, results.Items)
+		})
+
+		t.Run(driverName+" - respects the permissions", func(t *testing.T) {
+			guest := app.RequesterInfo{UserID: model.NewId(), IsGuest: true}
+			results, err := playbookRunStore.SearchPlaybookRuns(guest, app.PlaybookRunSearchOptions{
+				Terms:   "redis",
+				TeamID:  teamID,
+				PerPage: 10,
+			})
+			require.NoError(t, err)
+
+			require.Zero(t, results.TotalCount)
+		})
+	}
+}
+
+func TestPlaybookRunSearchDocuments(t *testing.T) {
+	run := searchableRun{
+		ID:            "run_id",
+		Name:          "Outage",
+		Description:   "  ",
+		Retrospective: "Lessons learned",
+	}
+	checklists := []app.Checklist{
+		{
+			Items: []app.ChecklistItem{
+				{ID: "item_id", Title: "Restart", Description: "the cache"},
+				{Title: "Page on-call"},
+			},
+		},
+		{
+			Items: []app.ChecklistItem{
+				{ID: "item_id", Title: "Restart again"},
+				{ID: strings.Repeat("x", 33), Title: "Check the logs"},
+			},
+		},
+	}
+	statusPosts := []searchableText{{ID: "post_id", Content: "All good"}, {ID: "empty_post_id", Content: ""}}
+	timelineEvents := []searchableText{{ID: "event_id", Content: "Owner changed\n"}}
+
+	documents := playbookRunSearchDocuments(run, checklists, statusPosts, timelineEvents)
+
+	assert.Equal(t, []searchDocument{
+		{IncidentID: "run_id", Source: app.SearchSourceName, Content: "Outage"},
+		{IncidentID: "run_id", Source: app.SearchSourceRetrospective, Content: "Lessons learned"},
+		{IncidentID: "run_id", Source: app.SearchSourceStatusUpdate, SourceID: "post_id", Content: "All good"},
+		{IncidentID: "run_id", Source: app.SearchSourceTimelineEvent, SourceID: "event_id", Content: "Owner changed"},
+		{IncidentID: "run_id", Source: app.SearchSourceChecklistItem, SourceID: "item_id", Content: "Restart\nthe cache"},
+		{IncidentID: "run_id", Source: app.SearchSourceChecklistItem, SourceID: "0-1", Content: "Page on-call"},
+		{IncidentID: "run_id", Source: app.SearchSourceChecklistItem, SourceID: "1-0", Content: "Restart again"},
+		{IncidentID: "run_id", Source: app.SearchSourceChecklistItem, SourceID: "1-1", Content: "Check the logs"},
+	}, documents)
+}
+
+func TestSearchableRunChanged(t *testing.T) {
+	previous := searchableRun{
+		ID:             "run_id",
+		Description:    "Outage",
+		ChecklistsJSON: []byte(`[{"title":"Triage","items":[{"id":"item_id","title":"Restart","state":""}]}]`),
+	}
+	run := &app.PlaybookRun{
+		ID:          "run_id",
+		Description: "Outage",
+		Checklists:  []app.Checklist{{Title: "Triage", Items: []app.ChecklistItem{{ID: "item_id", Title: "Restart"}}}},
+	}
+
+	assert.False(t, searchableRunChanged(previous, run))
+
+	run.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+	run.OwnerUserID = "owner_id"
+	assert.False(t, searchableRunChanged(previous, run))
+
+	run.Checklists[0].Items[0].Title = "Restart the cache"
+	assert.True(t, searchableRunChanged(previous, run))
+
+	run.Checklists[0].Items[0].Title = "Restart"
+	run.Retrospective = "Lessons learned"
+	assert.True(t, searchableRunChanged(previous, run))
+
+	previous.ChecklistsJSON = []byte("not json")
+	run.Retrospective = ""
+	assert.True(t, searchableRunChanged(previous, run))
+}
+
+func TestTruncateSearchDocument(t *testing.T) {
+	short := "short"
+	assert.Equal(t, short, truncateSearchDocument(short))
+
+	long := strings.Repeat("a", searchDocumentMaxLength-1) + "é"
+	truncated := truncateSearchDocument(long)
+	assert.Equal(t, searchDocumentMaxLength-1, len(truncated))
+	assert.Equal(t, strings.Repeat("a", searchDocumentMaxLength-1), truncated)
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Content  string
+		Terms    []string
+		Length   int
+		Expected string
+	}{
+		{
+			Name:     "whole words only, case insensitive",
+			Content:  "Redis ran out of memory; redistribute the REDIS keys",
+			Terms:    []string{"redis"},
+			Length:   100,
+			Expected: "**Redis** ran out of memory; redistribute the **REDIS** keys",
+		},
+		{
+			Name:     "several terms, collapsing whitespace",
+			Content:  "Redis\n\nran   out of memory",
+			Terms:    []string{"redis", "memory"},
+			Length:   100,
+			Expected: "**Redis** ran out of **memory**",
+		},
+		{
+			Name:     "excerpt around the first match",
+			Content:  "one two three four five six seven eight nine redis ten eleven twelve",
+			Terms:    []string{"redis"},
+			Length:   21,
+			Expected: "...nine **redis** ten eleven...",
+		},
+		{
+			Name:     "no match keeps the beginning",
+			Content:  "one two three four five",
+			Terms:    []string{"redis"},
+			Length:   7,
+			Expected: "one two...",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			assert.Equal(t, testCase.Expected, highlightSnippet(testCase.Content, testCase.Terms, testCase.Length))
+		})
+	}
+}
+
+func TestBuildPlaybookRunSearchResults(t *testing.T) {
+	rankedRuns := []searchRankedRun{
+		{IncidentID: "run_2", Score: 0.9},
+		{IncidentID: "run_1", Score: 0.5},
+		{IncidentID: "deleted_run", Score: 0.1},
+	}
+	runInfos := []searchRunInfo{
+		{ID: "run_1", Name: "Run 1", TeamID: "team_id", CurrentStatus: app.StatusFinished, CreateAt: 1},
+		{ID: "run_2", Name: "Run 2", TeamID: "team_id", CurrentStatus: app.StatusInProgress, CreateAt: 2},
+	}
+	matches := []searchMatch{
+		{IncidentID: "run_1", Source: app.SearchSourceName, Content: "Redis", Score: 0.5},
+		{IncidentID: "run_2", Source: app.SearchSourceStatusUpdate, SourceID: "a", Snippet: "**redis** a", Score: 0.1},
+		{IncidentID: "run_2", Source: app.SearchSourceStatusUpdate, SourceID: "b", Snippet: "**redis** b", Score: 0.4},
+		{IncidentID: "run_2", Source: app.SearchSourceStatusUpdate, SourceID: "c", Snippet: "**redis** c", Score: 0.3},
+		{IncidentID: "run_2", Source: app.SearchSourceStatusUpdate, SourceID: "d", Snippet: "**redis** d", Score: 0.2},
+	}
+
+	results := buildPlaybookRunSearchResults(rankedRuns, runInfos, matches, []string{"redis"})
+
+	assert.Equal(t, []app.PlaybookRunSearchResult{
+		{
+			PlaybookRunID: "run_2",
+			Name:          "Run 2",
+			TeamID:        "team_id",
+			CurrentStatus: app.StatusInProgress,
+			CreateAt:      2,
+			Rank:          0.9,
+			Matches: []app.PlaybookRunSearchMatch{
+				{Source: app.SearchSourceStatusUpdate, SourceID: "b", Snippet: "**redis** b"},
+				{Source: app.SearchSourceStatusUpdate, SourceID: "c", Snippet: "**redis** c"},
+				{Source: app.SearchSourceStatusUpdate, SourceID: "d", Snippet: "**redis** d"},
+			},
+		},
+		{
+			PlaybookRunID: "run_1",
+			Name:          "Run 1",
+			TeamID:        "team_id",
+			CurrentStatus: app.StatusFinished,
+			CreateAt:      1,
+			Rank:          0.5,
+			Matches: []app.PlaybookRunSearchMatch{
+				{Source: app.SearchSourceName, Snippet: "**Redis**"},
+			},
+		},
+	}, results)
+}
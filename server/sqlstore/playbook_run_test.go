@@ -1036,6 +1036,213 @@ func TestGetPlaybookRuns(t *testing.T) {
 	}
 }
 
+func TestGetPlaybookRunsRicherFilters(t *testing.T) {
+	teamID := model.NewId()
+	assigneeID := model.NewId()
+	reporterID := model.NewId()
+	playbookID1 := model.NewId()
+	playbookID2 := model.NewId()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+
+		assignedRun := NewBuilder(t).
+			WithTeamID(teamID).
+			WithName("assigned").
+			WithPlaybookID(playbookID1).
+			WithChecklists([]int{3}).
+			ToPlaybookRun()
+		assignedRun.Checklists[0].Items[0].AssigneeID = assigneeID
+		assignedRun.Checklists[0].Items[1].State = app.ChecklistItemStateClosed
+
+		overdueRun := NewBuilder(t).
+			WithTeamID(teamID).
+			WithName("overdue").
+			WithPlaybookID(playbookID2).
+			WithUpdateOverdueBy(2 * time.Minute).
+			ToPlaybookRun()
+		overdueRun.ReporterUserID = reporterID
+
+		finishedRun := NewBuilder(t).
+			WithTeamID(teamID).
+			WithName("finished").
+			WithCreateAt(1000).
+			WithCurrentStatus(app.StatusFinished).
+			WithUpdateOverdueBy(2 * time.Minute).
+			ToPlaybookRun()
+
+		for _, playbookRun := range []*app.PlaybookRun{assignedRun, overdueRun, finishedRun} {
+			created, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+			require.NoError(t, err)
+			*playbookRun = *created
+			createPlaybookRunChannel(t, store, playbookRun)
+		}
+
+		admin := app.RequesterInfo{UserID: model.NewId(), IsAdmin: true}
+
+		runNames := func(t *testing.T, options app.PlaybookRunFilterOptions) []string {
+			t.Helper()
+
+			options.TeamID = teamID
+			options.Sort = app.SortByName
+			options.PerPage = 10
+			result, err := playbookRunStore.GetPlaybookRuns(admin, options)
+			require.NoError(t, err)
+			require.Equal(t, len(result.Items), result.TotalCount)
+
+			names := []string{}
+			for _, playbookRun := range result.Items {
+				names = append(names, playbookRun.Name)
+			}
+			return names
+		}
+
+		t.Run(driverName+" - assignee", func(t *testing.T) {
+			require.Equal(t, []string{"assigned"}, runNames(t, app.PlaybookRunFilterOptions{AssigneeID: assigneeID}))
+			require.Empty(t, runNames(t, app.PlaybookRunFilterOptions{AssigneeID: model.NewId()}))
+		})
+
+		t.Run(driverName+" - status update overdue", func(t *testing.T) {
+			require.Equal(t, []string{"overdue"}, runNames(t, app.PlaybookRunFilterOptions{StatusUpdateOverdue: true}))
+		})
+
+		t.Run(driverName+" - open tasks", func(t *testing.T) {
+			require.Equal(t, []string{"assigned"}, runNames(t, app.PlaybookRunFilterOptions{OpenTasksGTE: 2}))
+			require.Empty(t, runNames(t, app.PlaybookRunFilterOptions{OpenTasksGTE: 3}))
+
+			assignedRun.Checklists[0].Items[1].State = app.ChecklistItemStateOpen
+			require.NoError(t, playbookRunStore.UpdatePlaybookRun(assignedRun))
+			require.Equal(t, []string{"assigned"}, runNames(t, app.PlaybookRunFilterOptions{OpenTasksGTE: 3}))
+		})
+
+		t.Run(driverName+" - playbook ids", func(t *testing.T) {
+			require.Equal(t, []string{"assigned", "overdue"}, runNames(t, app.PlaybookRunFilterOptions{PlaybookIDs: []string{playbookID1, playbookID2}}))
+			require.Equal(t, []string{"overdue"}, runNames(t, app.PlaybookRunFilterOptions{PlaybookIDs: []string{playbookID2}}))
+		})
+
+		t.Run(driverName+" - finished between", func(t *testing.T) {
+			require.Equal(t, []string{"finished"}, runNames(t, app.PlaybookRunFilterOptions{FinishedGTE: 1000, FinishedLT: 2000}))
+			require.Equal(t, []string{"finished"}, runNames(t, app.PlaybookRunFilterOptions{FinishedLT: 2000}))
+			require.Empty(t, runNames(t, app.PlaybookRunFilterOptions{FinishedGTE: 2000}))
+		})
+
+		t.Run(driverName+" - reporter", func(t *testing.T) {
+			require.Equal(t, []string{"overdue"}, runNames(t, app.PlaybookRunFilterOptions{ReporterID: reporterID}))
+		})
+	}
+}
+
+func TestGetPlaybookRunsWithCursor(t *testing.T) {
+	teamID := model.NewId()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		_, store := setupSQLStore(t, db)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+
+		createRun := func(t *testing.T, name string, createAt int64) {
+			t.Helper()
+
+			playbookRun := NewBuilder(t).
+				WithTeamID(teamID).
+				WithName(name).
+				WithCreateAt(createAt).
+				ToPlaybookRun()
+			created, err := playbookRunStore.CreatePlaybookRun(playbookRun)
+			require.NoError(t, err)
+			createPlaybookRunChannel(t, store, created)
+		}
+
+		// Two runs share the same CreateAt to check the ties are broken by ID.
+		createRun(t, "run 1", 1000)
+		createRun(t, "run 2", 2000)
+		createRun(t, "run 3", 2000)
+		createRun(t, "run 4", 3000)
+		createRun(t, "run 5", 4000)
+
+		admin := app.RequesterInfo{UserID: model.NewId(), IsAdmin: true}
+
+		t.Run(driverName+" - walks all the pages without duplicates", func(t *testing.T) {
+			options := app.PlaybookRunFilterOptions{
+				TeamID:    teamID,
+				Sort:      app.SortByCreateAt,
+				Direction: app.DirectionDesc,
+				PerPage:   2,
+				SkipCount: true,
+			}
+
+			var ids []string
+			for i := 0; ; i++ {
+				result, err := playbookRunStore.GetPlaybookRuns(admin, options)
+				require.NoError(t, err)
+				require.Zero(t, result.TotalCount)
+				require.Zero(t, result.PageCount)
+
+				for _, playbookRun := range result.Items {
+					ids = append(ids, playbookRun.ID)
+				}
+
+				if i == 0 {
+					// Runs created while paging don't shift the next pages.
+					createRun(t, "run 6", 5000)
+				}
+
+				if !result.HasMore {
+					require.Empty(t, result.NextCursor)
+					break
+				}
+				require.NotEmpty(t, result.NextCursor)
+				options.Cursor = result.NextCursor
+			}
+
+			require.Len(t, ids, 5)
+
+			all, err := playbookRunStore.GetPlaybookRuns(admin, app.PlaybookRunFilterOptions{
+				TeamID:    teamID,
+				Sort:      app.SortByCreateAt,
+				Direction: app.DirectionDesc,
+				PerPage:   10,
+			})
+			require.NoError(t, err)
+			require.Equal(t, 6, all.TotalCount)
+			require.Equal(t, "run 6", all.Items[0].Name)
+
+			var expected []string
+			for _, playbookRun := range all.Items[1:] {
+				expected = append(expected, playbookRun.ID)
+			}
+			require.Equal(t, expected, ids)
+		})
+	}
+}
+
+func TestChecklistsAssigneesAndOpenItems(t *testing.T) {
+	checklists := []app.Checklist{
+		{
+			Items: []app.ChecklistItem{
+				{AssigneeID: "user_2", State: app.ChecklistItemStateOpen},
+				{AssigneeID: "user_1", State: app.ChecklistItemStateClosed},
+			},
+		},
+		{
+			Items: []app.ChecklistItem{
+				{AssigneeID: "user_2", State: app.ChecklistItemStateInProgress},
+				{State: app.ChecklistItemStateOpen},
+			},
+		},
+	}
+
+	assigneeIDs, openItemCount := checklistsAssigneesAndOpenItems(checklists)
+	require.Equal(t, []string{"user_2", "user_1"}, assigneeIDs)
+	require.Equal(t, 3, openItemCount)
+
+	assigneeIDs, openItemCount = checklistsAssigneesAndOpenItems(nil)
+	require.Empty(t, assigneeIDs)
+	require.Zero(t, openItemCount)
+}
+
 func TestCreateAndGetPlaybookRun(t *testing.T) {
 	for _, driverName := range driverNames {
 		db := setupTestDB(t, driverName)
@@ -1250,6 +1457,15 @@ func TestUpdatePlaybookRun(t *testing.T) {
 				},
 				ExpectedErr: nil,
 			},
+			{
+				Name:        "new custom data",
+				PlaybookRun: NewBuilder(t).ToPlaybookRun(),
+				Update: func(old app.PlaybookRun) *app.PlaybookRun {
+					old.CustomData = map[string]string{"ticket": "OPS-42", "region": "eu"}
+					return &old
+				},
+				ExpectedErr: nil,
+			},
 		}
 
 		for _, testCase := range validPlaybookRuns {
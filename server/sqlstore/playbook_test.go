@@ -981,6 +981,7 @@ func TestGetPlaybooksForTeam(t *testing.T) {
 				// remove the checklists and members from the expected playbooks--we don't return them in getPlaybooks
 				for i := range testCase.expected.Items {
 					testCase.expected.Items[i].Checklists = nil
+					testCase.expected.Items[i].Members = nil
 					testCase.expected.Items[i].MemberIDs = nil
 				}
 
@@ -1122,6 +1123,7 @@ func TestUpdatePlaybook(t *testing.T) {
 					WithMembers([]userInfo{jon, andrew}).ToPlaybook(),
 				update: func(old app.Playbook) app.Playbook {
 					old.MemberIDs = []string{andrew.ID}
+					old.Members = editors(old.MemberIDs)
 					return old
 				},
 				expectedErr: nil,
@@ -1133,6 +1135,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				update: func(old app.Playbook) app.Playbook {
 					old.MemberIDs = []string{matt.ID, bill.ID, alice.ID, jen.ID}
 					sort.Strings(old.MemberIDs)
+					old.Members = editors(old.MemberIDs)
 					return old
 				},
 				expectedErr: nil,
@@ -1144,6 +1147,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				update: func(old app.Playbook) app.Playbook {
 					old.MemberIDs = []string{jon.ID, andrew.ID, bob.ID, alice.ID}
 					sort.Strings(old.MemberIDs)
+					old.Members = editors(old.MemberIDs)
 					return old
 				},
 				expectedErr: nil,
@@ -1154,6 +1158,7 @@ func TestUpdatePlaybook(t *testing.T) {
 				update: func(old app.Playbook) app.Playbook {
 					old.MemberIDs = []string{alice.ID, jen.ID}
 					sort.Strings(old.MemberIDs)
+					old.Members = editors(old.MemberIDs)
 					return old
 				},
 				expectedErr: nil,
@@ -1172,6 +1177,80 @@ func TestUpdatePlaybook(t *testing.T) {
 					ToPlaybook(),
 				update: func(old app.Playbook) app.Playbook {
 					old.MemberIDs = nil
+					old.Members = nil
+					return old
+				},
+				expectedErr: nil,
+			},
+			{
+				name: "Playbook run with a member group, change it and add a member channel",
+				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
+					WithMembers([]userInfo{jon}).
+					WithMemberGroups([]app.PlaybookMemberGroup{
+						{Type: app.PlaybookMemberGroupTypeGroup, ID: "groupid00000000000000000000", Role: app.PlaybookRoleViewer},
+					}).ToPlaybook(),
+				update: func(old app.Playbook) app.Playbook {
+					old.MemberGroups = []app.PlaybookMemberGroup{
+						{Type: app.PlaybookMemberGroupTypeChannel, ID: "channelid000000000000000000", Role: app.PlaybookRoleRunner},
+						{Type: app.PlaybookMemberGroupTypeGroup, ID: "groupid00000000000000000000", Role: app.PlaybookRoleEditor},
+					}
+					return old
+				},
+				expectedErr: nil,
+			},
+			{
+				name: "Playbook run with a run permission policy, change it",
+				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
+					WithRunPermissions(app.RunPermissionPolicy{
+						Finish: app.RunPermissionRule{Allow: app.RunPermissionAllowOwner},
+					}).ToPlaybook(),
+				update: func(old app.Playbook) app.Playbook {
+					old.RunPermissions = app.RunPermissionPolicy{
+						EditChecklists:   app.RunPermissionRule{Allow: app.RunPermissionAllowRole, Role: app.PlaybookRoleRunner},
+						PostStatusUpdate: app.RunPermissionRule{Allow: app.RunPermissionAllowParticipants},
+					}
+					return old
+				},
+				expectedErr: nil,
+			},
+			{
+				name: "Playbook run shared with a team, share it with another team",
+				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
+					WithSharedTeams([]string{"teamid200000000000000000000"}).ToPlaybook(),
+				update: func(old app.Playbook) app.Playbook {
+					old.SharedTeamIDs = []string{"teamid200000000000000000000", "teamid300000000000000000000"}
+					return old
+				},
+				expectedErr: nil,
+			},
+			{
+				name: "Playbook run shared with a team, share it with all teams",
+				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
+					WithSharedTeams([]string{"teamid200000000000000000000"}).ToPlaybook(),
+				update: func(old app.Playbook) app.Playbook {
+					old.SharedTeamIDs = nil
+					old.SharedWithAllTeams = true
+					return old
+				},
+				expectedErr: nil,
+			},
+			{
+				name: "Playbook run with 3 members, change their roles",
+				playbook: NewPBBuilder().WithChecklists([]int{1, 2}).
+					WithMemberRoles(map[userInfo]string{
+						jon:    app.PlaybookRoleViewer,
+						andrew: app.PlaybookRoleEditor,
+						bob:    app.PlaybookRoleAdmin,
+					}).ToPlaybook(),
+				update: func(old app.Playbook) app.Playbook {
+					for i := range old.Members {
+						switch old.Members[i].UserID {
+						case jon.ID:
+							old.Members[i].Role = app.PlaybookRoleRunner
+						case bob.ID:
+							old.Members[i].Role = app.PlaybookRoleEditor
+						}
+					}
 					return old
 				},
 				expectedErr: nil,
@@ -1256,6 +1335,146 @@ func TestDeletePlaybook(t *testing.T) {
 	}
 }
 
+func TestRestorePlaybook(t *testing.T) {
+	team1id := model.NewId()
+
+	andrew := userInfo{
+		ID:   model.NewId(),
+		Name: "Andrew",
+	}
+
+	pb02 := NewPBBuilder().
+		WithTitle("playbook 2").
+		WithTeamID(team1id).
+		WithCreateAt(600).
+		WithChecklists([]int{1, 4}).
+		WithMembers([]userInfo{andrew}).
+		ToPlaybook()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		t.Run(driverName+" - id empty", func(t *testing.T) {
+			err := playbookStore.Restore("")
+			require.EqualError(t, err, "ID cannot be empty")
+		})
+
+		t.Run(driverName+" - archive, list and restore playbook", func(t *testing.T) {
+			id, err := playbookStore.Create(pb02)
+			require.NoError(t, err)
+
+			err = playbookStore.Delete(id)
+			require.NoError(t, err)
+
+			requesterInfo := app.RequesterInfo{UserID: andrew.ID, TeamID: team1id}
+			options := app.PlaybookFilterOptions{Sort: app.SortByTitle, PerPage: 1000}
+
+			result, err := playbookStore.GetPlaybooksForTeam(requesterInfo, team1id, options)
+			require.NoError(t, err)
+			require.Empty(t, result.Items)
+
+			options.WithArchived = true
+			result, err = playbookStore.GetPlaybooksForTeam(requesterInfo, team1id, options)
+			require.NoError(t, err)
+			require.Len(t, result.Items, 1)
+			require.Equal(t, id, result.Items[0].ID)
+			require.NotZero(t, result.Items[0].DeleteAt)
+
+			err = playbookStore.Restore(id)
+			require.NoError(t, err)
+
+			actual, err := playbookStore.Get(id)
+			require.NoError(t, err)
+			require.Zero(t, actual.DeleteAt)
+		})
+	}
+}
+
+func TestPurgePlaybook(t *testing.T) {
+	team1id := model.NewId()
+
+	andrew := userInfo{
+		ID:   model.NewId(),
+		Name: "Andrew",
+	}
+
+	pb02 := NewPBBuilder().
+		WithTitle("playbook 2").
+		WithTeamID(team1id).
+		WithChecklists([]int{1, 4}).
+		WithMembers([]userInfo{andrew}).
+		WithSharedTeams([]string{model.NewId()}).
+		ToPlaybook()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		t.Run(driverName+" - id empty", func(t *testing.T) {
+			err := playbookStore.Purge("")
+			require.EqualError(t, err, "ID cannot be empty")
+		})
+
+		t.Run(driverName+" - create, archive and purge playbook", func(t *testing.T) {
+			id, err := playbookStore.Create(pb02)
+			require.NoError(t, err)
+
+			playbookRunStore := setupPlaybookRunStore(t, db)
+			run, err := playbookRunStore.CreatePlaybookRun(NewBuilder(t).WithPlaybookID(id).ToPlaybookRun())
+			require.NoError(t, err)
+
+			err = playbookStore.Delete(id)
+			require.NoError(t, err)
+
+			err = playbookStore.Purge(id)
+			require.NoError(t, err)
+
+			_, err = playbookStore.Get(id)
+			require.Error(t, err)
+
+			var numMembers int
+			err = db.Get(&numMembers, db.Rebind("SELECT COUNT(*) FROM IR_PlaybookMember WHERE PlaybookID = ?"), id)
+			require.NoError(t, err)
+			require.Zero(t, numMembers)
+
+			run, err = playbookRunStore.GetPlaybookRun(run.ID)
+			require.NoError(t, err)
+			require.Empty(t, run.PlaybookID)
+		})
+	}
+}
+
+func TestGetRunPermissions(t *testing.T) {
+	policy := app.RunPermissionPolicy{
+		Finish: app.RunPermissionRule{Allow: app.RunPermissionAllowOwner},
+	}
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookStore := setupPlaybookStore(t, db)
+
+		t.Run(driverName+" - id empty", func(t *testing.T) {
+			_, err := playbookStore.GetRunPermissions("")
+			require.EqualError(t, err, "ID cannot be empty")
+		})
+
+		t.Run(driverName+" - playbook does not exist", func(t *testing.T) {
+			_, err := playbookStore.GetRunPermissions(model.NewId())
+			require.True(t, errors.Is(err, app.ErrNotFound))
+		})
+
+		t.Run(driverName+" - get the policy of a playbook", func(t *testing.T) {
+			id, err := playbookStore.Create(NewPBBuilder().WithTitle("policy").WithRunPermissions(policy).ToPlaybook())
+			require.NoError(t, err)
+
+			actual, err := playbookStore.GetRunPermissions(id)
+			require.NoError(t, err)
+			require.Equal(t, policy, actual)
+		})
+	}
+}
+
 func TestGetPlaybooksForKeywords(t *testing.T) {
 	team1id := model.NewId()
 	team2id := model.NewId()
@@ -1516,7 +1735,25 @@ func TestGetPlaybookIDsForUser(t *testing.T) {
 		WithMembers([]userInfo{}).
 		ToPlaybook()
 
-	pb := []app.Playbook{pb01, pb02, pb03, pb04, pb05, pb06, pb07, pb08, pb09}
+	groupID := model.NewId()
+	pb10 := NewPBBuilder().
+		WithTeamID(team1id).
+		WithMembers([]userInfo{matt}).
+		WithMemberGroups([]app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeGroup, ID: groupID, Role: app.PlaybookRoleViewer},
+		}).
+		ToPlaybook()
+
+	channelID := model.NewId()
+	pb11 := NewPBBuilder().
+		WithTeamID(team1id).
+		WithMembers([]userInfo{matt}).
+		WithMemberGroups([]app.PlaybookMemberGroup{
+			{Type: app.PlaybookMemberGroupTypeChannel, ID: channelID, Role: app.PlaybookRoleRunner},
+		}).
+		ToPlaybook()
+
+	pb := []app.Playbook{pb01, pb02, pb03, pb04, pb05, pb06, pb07, pb08, pb09, pb10, pb11}
 
 	for _, driverName := range driverNames {
 		db := setupTestDB(t, driverName)
@@ -1532,6 +1769,8 @@ func TestGetPlaybookIDsForUser(t *testing.T) {
 		setupUsersTable(t, db)
 		setupTeamMembersTable(t, db)
 		addUsers(t, store, users)
+		addUsersToGroup(t, store, []userInfo{lucia}, groupID)
+		addUsersToChannels(t, store, []userInfo{desmond}, []string{channelID})
 
 		t.Helper()
 
@@ -1563,10 +1802,10 @@ func TestGetPlaybookIDsForUser(t *testing.T) {
 				expectedErr: nil,
 			},
 			{
-				name:        "team1 from lucia",
+				name:        "team1 from lucia - through a member group",
 				teamID:      team1id,
 				userID:      lucia.ID,
-				expected:    []string{pb[2].ID},
+				expected:    []string{pb[2].ID, pb[9].ID},
 				expectedErr: nil,
 			},
 			{
@@ -1597,6 +1836,13 @@ func TestGetPlaybookIDsForUser(t *testing.T) {
 				expected:    []string{pb[7].ID, pb[8].ID},
 				expectedErr: nil,
 			},
+			{
+				name:        "team1 from Desmond - through a member channel",
+				teamID:      team1id,
+				userID:      desmond.ID,
+				expected:    []string{pb[10].ID},
+				expectedErr: nil,
+			},
 			{
 				name:        "none found",
 				teamID:      "not-existing",
@@ -1743,6 +1989,48 @@ func (p *PlaybookBuilder) WithMembers(members []userInfo) *PlaybookBuilder {
 		p.MemberIDs[i] = member.ID
 	}
 	sort.Strings(p.MemberIDs)
+	p.Members = editors(p.MemberIDs)
+
+	return p
+}
+
+func (p *PlaybookBuilder) WithMemberRoles(members map[userInfo]string) *PlaybookBuilder {
+	p.WithMembers(nil)
+	for member, role := range members {
+		p.Members = append(p.Members, app.PlaybookMember{UserID: member.ID, Role: role})
+	}
+	sort.Slice(p.Members, func(i, j int) bool { return p.Members[i].UserID < p.Members[j].UserID })
+	for _, member := range p.Members {
+		p.MemberIDs = append(p.MemberIDs, member.UserID)
+	}
+
+	return p
+}
+
+// editors returns the sorted memberIDs as editors of a playbook, or nil if there are none.
+func editors(memberIDs []string) []app.PlaybookMember {
+	var members []app.PlaybookMember
+	for _, memberID := range memberIDs {
+		members = append(members, app.PlaybookMember{UserID: memberID, Role: app.PlaybookRoleEditor})
+	}
+
+	return members
+}
+
+func (p *PlaybookBuilder) WithMemberGroups(memberGroups []app.PlaybookMemberGroup) *PlaybookBuilder {
+	p.MemberGroups = memberGroups
+
+	return p
+}
+
+func (p *PlaybookBuilder) WithRunPermissions(policy app.RunPermissionPolicy) *PlaybookBuilder {
+	p.RunPermissions = policy
+
+	return p
+}
+
+func (p *PlaybookBuilder) WithSharedTeams(teamIDs []string) *PlaybookBuilder {
+	p.SharedTeamIDs = teamIDs
 
 	return p
 }
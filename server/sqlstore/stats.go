@@ -1,13 +1,16 @@
 package sqlstore
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/pkg/errors"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
 	"github.com/mattermost/mattermost-plugin-playbooks/server/bot"
 	"github.com/mattermost/mattermost-server/v6/model"
 )
@@ -29,6 +32,11 @@ func NewStatsStore(pluginAPI PluginAPIClient, log bot.Logger, sqlStore *SQLStore
 type StatsFilters struct {
 	TeamID     string
 	PlaybookID string
+
+	// StartedGTE and StartedLT, in milliseconds since epoch, restrict the stats to the runs
+	// started in [StartedGTE, StartedLT). Zero means unbounded.
+	StartedGTE int64
+	StartedLT  int64
 }
 
 func applyFilters(query sq.SelectBuilder, filters *StatsFilters) sq.SelectBuilder {
@@ -40,6 +48,12 @@ func applyFilters(query sq.SelectBuilder, filters *StatsFilters) sq.SelectBuilde
 	if filters.PlaybookID != "" {
 		ret = ret.Where(sq.Eq{"i.PlaybookID": filters.PlaybookID})
 	}
+	if filters.StartedGTE != 0 {
+		ret = ret.Where(sq.GtOrEq{"i.CreateAt": filters.StartedGTE})
+	}
+	if filters.StartedLT != 0 {
+		ret = ret.Where(sq.Lt{"i.CreateAt": filters.StartedLT})
+	}
 
 	return ret
 }
@@ -106,6 +120,47 @@ func (s *StatsStore) RunsFinishedBetweenDays(filters *StatsFilters, startDay, en
 	return total
 }
 
+// RunsCount is a number of runs of a playbook in a team.
+type RunsCount struct {
+	TeamID     string
+	PlaybookID string
+	Count      int
+}
+
+// InProgressRunsByTeamAndPlaybook returns the number of runs in progress, by team and playbook.
+func (s *StatsStore) InProgressRunsByTeamAndPlaybook() ([]RunsCount, error) {
+	query := s.store.builder.
+		Select("i.TeamID", "i.PlaybookID", "COUNT(i.ID) AS Count").
+		From("IR_Incident as i").
+		Where("i.EndAt = 0").
+		GroupBy("i.TeamID", "i.PlaybookID")
+
+	var counts []RunsCount
+	if err := s.store.selectBuilder(s.store.db, &counts, query); err != nil {
+		return nil, errors.Wrap(err, "failed to count runs in progress")
+	}
+
+	return counts, nil
+}
+
+// OverdueStatusUpdatesByTeamAndPlaybook returns the number of runs in progress with an overdue
+// status update, by team and playbook.
+func (s *StatsStore) OverdueStatusUpdatesByTeamAndPlaybook() ([]RunsCount, error) {
+	query := s.store.builder.
+		Select("i.TeamID", "i.PlaybookID", "COUNT(i.ID) AS Count").
+		From("IR_Incident as i").
+		Where("i.EndAt = 0").
+		Where(statusUpdateOverdueExpr(s.store.db.DriverName())).
+		GroupBy("i.TeamID", "i.PlaybookID")
+
+	var counts []RunsCount
+	if err := s.store.selectBuilder(s.store.db, &counts, query); err != nil {
+		return nil, errors.Wrap(err, "failed to count runs with overdue status updates")
+	}
+
+	return counts, nil
+}
+
 // Not efficient. One query per day.
 func (s *StatsStore) MovingWindowQueryActive(query sq.SelectBuilder, numDays int) ([]int, error) {
 	now := model.GetMillis()
@@ -292,6 +347,434 @@ func (s *StatsStore) ActiveParticipantsPerDayLastXDays(x int, filters *StatsFilt
 	return counts, daysAsTimes
 }
 
+// DurationStats summarizes a set of durations, in milliseconds.
+type DurationStats struct {
+	Count int   `json:"count"`
+	Mean  int64 `json:"mean"`
+	P50   int64 `json:"p50"`
+	P90   int64 `json:"p90"`
+	P95   int64 `json:"p95"`
+	Max   int64 `json:"max"`
+}
+
+// StatusUpdateCadenceStats describes how well runs kept up with their status update timer.
+type StatusUpdateCadenceStats struct {
+	// RunsCount is the number of runs with a status update timer.
+	RunsCount int `json:"runs_count"`
+
+	// ExpectedUpdates is the number of status updates that were due, OnTimeUpdates is how many of
+	// them were posted before the timer expired.
+	ExpectedUpdates int `json:"expected_updates"`
+	OnTimeUpdates   int `json:"on_time_updates"`
+
+	// Adherence is OnTimeUpdates / ExpectedUpdates, 1 if no update was due.
+	Adherence float64 `json:"adherence"`
+
+	// Intervals summarizes the time between consecutive status updates, the first one being
+	// measured from the start of the run.
+	Intervals DurationStats `json:"intervals"`
+}
+
+// ChecklistItemStats summarizes the time it took to check an item, measured from the start of
+// the run. Items are identified by their checklist and item titles.
+type ChecklistItemStats struct {
+	ChecklistTitle string `json:"checklist_title"`
+	ItemTitle      string `json:"item_title"`
+
+	// RunsCount is the number of runs including this item.
+	RunsCount int `json:"runs_count"`
+
+	CompletionTime DurationStats `json:"completion_time"`
+}
+
+// RunStatusStats summarizes the time the runs spent in a custom run status, the durations of
+// several stints in the same run being added up.
+type RunStatusStats struct {
+	Status string `json:"status"`
+
+	// RunsCount is the number of runs that spent time in this status.
+	RunsCount int `json:"runs_count"`
+
+	Duration DurationStats `json:"duration"`
+}
+
+// runStatusChanges holds what's needed to compute the time a run spent in each status.
+type runStatusChanges struct {
+	CreateAt    int64
+	EndAt       int64
+	RunStatuses []app.RunStatus
+	Changes     []runStatusChange
+}
+
+// runStatusChange is the move of a run to the status To at EventAt.
+type runStatusChange struct {
+	EventAt int64
+	To      string
+}
+
+// runStatusUpdates holds what's needed to compute the status update cadence of a run.
+type runStatusUpdates struct {
+	ID                          string
+	CreateAt                    int64
+	EndAt                       int64
+	ReminderTimerDefaultSeconds int64
+	UpdatesAt                   []int64
+}
+
+// runChecklists holds what's needed to compute the completion times of a run's items.
+type runChecklists struct {
+	CreateAt   int64
+	Checklists []app.Checklist
+}
+
+// TimeToFinish returns the time it took to finish the runs, ignoring the runs still in progress.
+func (s *StatsStore) TimeToFinish(filters *StatsFilters) (DurationStats, error) {
+	query := s.store.builder.
+		Select("i.EndAt - i.CreateAt").
+		From("IR_Incident as i").
+		Where("i.EndAt > 0")
+	query = applyFilters(query, filters)
+
+	var durations []int64
+	if err := s.store.selectBuilder(s.store.db, &durations, query); err != nil {
+		return DurationStats{}, errors.Wrap(err, "failed to get time to finish")
+	}
+
+	return newDurationStats(durations), nil
+}
+
+// TimeToFirstStatusUpdate returns the time between the start of the runs and their first status
+// update, ignoring the runs without any status update.
+func (s *StatsStore) TimeToFirstStatusUpdate(filters *StatsFilters) (DurationStats, error) {
+	query := s.store.builder.
+		Select("MIN(te.EventAt) - i.CreateAt").
+		From("IR_Incident as i").
+		Join("IR_TimelineEvent as te ON te.IncidentID = i.ID").
+		Where(sq.Eq{"te.EventType": string(app.StatusUpdated)}).
+		Where(sq.Eq{"te.DeleteAt": 0}).
+		GroupBy("i.ID", "i.CreateAt")
+	query = applyFilters(query, filters)
+
+	var durations []int64
+	if err := s.store.selectBuilder(s.store.db, &durations, query); err != nil {
+		return DurationStats{}, errors.Wrap(err, "failed to get time to first status update")
+	}
+
+	return newDurationStats(durations), nil
+}
+
+// StatusUpdateCadence compares the status updates of the runs with their ReminderTimerDefaultSeconds,
+// ignoring the runs without a status update timer.
+func (s *StatsStore) StatusUpdateCadence(filters *StatsFilters) (StatusUpdateCadenceStats, error) {
+	runsQuery := s.store.builder.
+		Select("i.ID", "i.CreateAt", "i.EndAt", "i.ReminderTimerDefaultSeconds").
+		From("IR_Incident as i").
+		Where("i.ReminderTimerDefaultSeconds > 0")
+	runsQuery = applyFilters(runsQuery, filters)
+
+	var runs []runStatusUpdates
+	if err := s.store.selectBuilder(s.store.db, &runs, runsQuery); err != nil {
+		return StatusUpdateCadenceStats{}, errors.Wrap(err, "failed to get runs with a status update timer")
+	}
+
+	updatesQuery := s.store.builder.
+		Select("te.IncidentID", "te.EventAt").
+		From("IR_TimelineEvent as te").
+		Join("IR_Incident as i ON i.ID = te.IncidentID").
+		Where(sq.Eq{"te.EventType": string(app.StatusUpdated)}).
+		Where(sq.Eq{"te.DeleteAt": 0}).
+		Where("i.ReminderTimerDefaultSeconds > 0").
+		OrderBy("te.EventAt")
+	updatesQuery = applyFilters(updatesQuery, filters)
+
+	var updates []struct {
+		IncidentID string
+		EventAt    int64
+	}
+	if err := s.store.selectBuilder(s.store.db, &updates, updatesQuery); err != nil {
+		return StatusUpdateCadenceStats{}, errors.Wrap(err, "failed to get status updates")
+	}
+
+	runIndices := make(map[string]int, len(runs))
+	for i, run := range runs {
+		runIndices[run.ID] = i
+	}
+	for _, update := range updates {
+		if i, ok := runIndices[update.IncidentID]; ok {
+			runs[i].UpdatesAt = append(runs[i].UpdatesAt, update.EventAt)
+		}
+	}
+
+	return computeStatusUpdateCadence(runs, model.GetMillis()), nil
+}
+
+// ChecklistItemCompletionTimes returns the time it took to check each checklist item, in order of
+// first appearance in the runs.
+func (s *StatsStore) ChecklistItemCompletionTimes(filters *StatsFilters) ([]ChecklistItemStats, error) {
+	query := s.store.builder.
+		Select("i.CreateAt", "i.ChecklistsJSON").
+		From("IR_Incident as i").
+		OrderBy("i.CreateAt")
+	query = applyFilters(query, filters)
+
+	var rawRuns []struct {
+		CreateAt       int64
+		ChecklistsJSON json.RawMessage
+	}
+	if err := s.store.selectBuilder(s.store.db, &rawRuns, query); err != nil {
+		return nil, errors.Wrap(err, "failed to get checklists")
+	}
+
+	runs := make([]runChecklists, 0, len(rawRuns))
+	for _, rawRun := range rawRuns {
+		var checklists []app.Checklist
+		if err := json.Unmarshal(rawRun.ChecklistsJSON, &checklists); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal checklists json")
+		}
+
+		runs = append(runs, runChecklists{
+			CreateAt:   rawRun.CreateAt,
+			Checklists: checklists,
+		})
+	}
+
+	return computeChecklistItemStats(runs), nil
+}
+
+// RunStatusDurations returns the time the runs with custom statuses spent in each of them, in
+// order of first appearance in the runs.
+func (s *StatsStore) RunStatusDurations(filters *StatsFilters) ([]RunStatusStats, error) {
+	runsQuery := s.store.builder.
+		Select("i.ID", "i.CreateAt", "i.EndAt", "i.RunStatusesJSON").
+		From("IR_Incident as i").
+		Where("i.RunStatusesJSON IS NOT NULL").
+		OrderBy("i.CreateAt")
+	runsQuery = applyFilters(runsQuery, filters)
+
+	var rawRuns []struct {
+		ID              string
+		CreateAt        int64
+		EndAt           int64
+		RunStatusesJSON json.RawMessage
+	}
+	if err := s.store.selectBuilder(s.store.db, &rawRuns, runsQuery); err != nil {
+		return nil, errors.Wrap(err, "failed to get runs with run statuses")
+	}
+
+	changesQuery := s.store.builder.
+		Select("te.IncidentID", "te.EventAt", "te.Details").
+		From("IR_TimelineEvent as te").
+		Join("IR_Incident as i ON i.ID = te.IncidentID").
+		Where(sq.Eq{"te.EventType": string(app.RunStatusChanged)}).
+		Where(sq.Eq{"te.DeleteAt": 0}).
+		Where("i.RunStatusesJSON IS NOT NULL").
+		OrderBy("te.EventAt")
+	changesQuery = applyFilters(changesQuery, filters)
+
+	var rawChanges []struct {
+		IncidentID string
+		EventAt    int64
+		Details    string
+	}
+	if err := s.store.selectBuilder(s.store.db, &rawChanges, changesQuery); err != nil {
+		return nil, errors.Wrap(err, "failed to get run status changes")
+	}
+
+	runs := make([]runStatusChanges, 0, len(rawRuns))
+	runIndices := make(map[string]int, len(rawRuns))
+	for _, rawRun := range rawRuns {
+		var runStatuses []app.RunStatus
+		if err := json.Unmarshal(rawRun.RunStatusesJSON, &runStatuses); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal run statuses json")
+		}
+
+		runIndices[rawRun.ID] = len(runs)
+		runs = append(runs, runStatusChanges{
+			CreateAt:    rawRun.CreateAt,
+			EndAt:       rawRun.EndAt,
+			RunStatuses: runStatuses,
+		})
+	}
+	for _, rawChange := range rawChanges {
+		i, ok := runIndices[rawChange.IncidentID]
+		if !ok {
+			continue
+		}
+
+		var change app.RunStatusChange
+		if err := json.Unmarshal([]byte(rawChange.Details), &change); err != nil {
+			s.log.Warnf("failed to unmarshal run status change of run %s: %v", rawChange.IncidentID, err)
+			continue
+		}
+		runs[i].Changes = append(runs[i].Changes, runStatusChange{EventAt: rawChange.EventAt, To: change.To})
+	}
+
+	return computeRunStatusStats(runs), nil
+}
+
+// computeRunStatusStats measures the time each run spent in each status, starting in its first
+// status when created. The status a run is in when finished, or still is in, is not counted.
+func computeRunStatusStats(runs []runStatusChanges) []RunStatusStats {
+	var statuses []string
+	durations := map[string][]int64{}
+	for _, run := range runs {
+		if len(run.RunStatuses) == 0 {
+			continue
+		}
+
+		runDurations := map[string]int64{}
+		current := run.RunStatuses[0].Name
+		since := run.CreateAt
+		for _, change := range run.Changes {
+			if run.EndAt > 0 && change.EventAt > run.EndAt {
+				break
+			}
+			statuses = appendNew(statuses, current)
+			runDurations[current] += change.EventAt - since
+			current = change.To
+			since = change.EventAt
+		}
+
+		for status, duration := range runDurations {
+			durations[status] = append(durations[status], duration)
+		}
+	}
+
+	stats := make([]RunStatusStats, 0, len(statuses))
+	for _, status := range statuses {
+		stats = append(stats, RunStatusStats{
+			Status:    status,
+			RunsCount: len(durations[status]),
+			Duration:  newDurationStats(durations[status]),
+		})
+	}
+
+	return stats
+}
+
+// appendNew appends value to values unless it's already there.
+func appendNew(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// computeStatusUpdateCadence counts every status update as due once the run's timer expired since
+// the previous one, or since the start of the run. The update due after the last one is only
+// counted once it's overdue, as of now.
+func computeStatusUpdateCadence(runs []runStatusUpdates, now int64) StatusUpdateCadenceStats {
+	stats := StatusUpdateCadenceStats{
+		RunsCount: len(runs),
+	}
+
+	var intervals []int64
+	for _, run := range runs {
+		timer := run.ReminderTimerDefaultSeconds * 1000
+		last := run.CreateAt
+		for _, updateAt := range run.UpdatesAt {
+			interval := updateAt - last
+			intervals = append(intervals, interval)
+
+			stats.ExpectedUpdates++
+			if interval <= timer {
+				stats.OnTimeUpdates++
+			}
+			last = updateAt
+		}
+
+		end := run.EndAt
+		if end == 0 {
+			end = now
+		}
+		if end-last > timer {
+			stats.ExpectedUpdates++
+		}
+	}
+
+	stats.Adherence = 1
+	if stats.ExpectedUpdates > 0 {
+		stats.Adherence = float64(stats.OnTimeUpdates) / float64(stats.ExpectedUpdates)
+	}
+	stats.Intervals = newDurationStats(intervals)
+
+	return stats
+}
+
+func computeChecklistItemStats(runs []runChecklists) []ChecklistItemStats {
+	type itemKey struct {
+		checklistTitle string
+		itemTitle      string
+	}
+
+	var keys []itemKey
+	runsCount := map[itemKey]int{}
+	durations := map[itemKey][]int64{}
+	for _, run := range runs {
+		for _, checklist := range run.Checklists {
+			for _, item := range checklist.Items {
+				key := itemKey{checklistTitle: checklist.Title, itemTitle: item.Title}
+				if _, ok := runsCount[key]; !ok {
+					keys = append(keys, key)
+				}
+				runsCount[key]++
+
+				if item.State != app.ChecklistItemStateClosed || item.StateModified < run.CreateAt {
+					continue
+				}
+				durations[key] = append(durations[key], item.StateModified-run.CreateAt)
+			}
+		}
+	}
+
+	stats := make([]ChecklistItemStats, 0, len(keys))
+	for _, key := range keys {
+		stats = append(stats, ChecklistItemStats{
+			ChecklistTitle: key.checklistTitle,
+			ItemTitle:      key.itemTitle,
+			RunsCount:      runsCount[key],
+			CompletionTime: newDurationStats(durations[key]),
+		})
+	}
+
+	return stats
+}
+
+// newDurationStats computes the mean and the nearest-rank percentiles of durations.
+func newDurationStats(durations []int64) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+
+	sorted := make([]int64, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var sum int64
+	for _, d := range sorted {
+		sum += d
+	}
+
+	percentile := func(p int) int64 {
+		rank := (p*len(sorted) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+
+	return DurationStats{
+		Count: len(sorted),
+		Mean:  sum / int64(len(sorted)),
+		P50:   percentile(50),
+		P90:   percentile(90),
+		P95:   percentile(95),
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
 func (s *StatsStore) performQueryForXCols(q sq.SelectBuilder, x int) ([]int, error) {
 	sqlString, args, err := q.ToSql()
 	if err != nil {
@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/jmoiron/sqlx"
@@ -269,3 +270,313 @@ func TestTotalInProgressPlaybookRuns(t *testing.T) {
 		})*/
 	}
 }
+
+func TestOperationalStats(t *testing.T) {
+	team1id := model.NewId()
+	team2id := model.NewId()
+	playbook1id := model.NewId()
+	playbook2id := model.NewId()
+
+	minute := int64(60000)
+	now := model.GetMillis()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		statsStore := setupStatsStore(t, db)
+
+		_, store := setupSQLStore(t, db)
+		setupChannelsTable(t, db)
+		setupChannelMembersTable(t, db)
+
+		// run01 was finished after 60 minutes, with status updates 10 and 40 minutes in.
+		run01 := NewBuilder(t).
+			WithName("run 1").
+			WithTeamID(team1id).
+			WithPlaybookID(playbook1id).
+			WithCreateAt(now - 120*minute).
+			WithChecklists([]int{2}).
+			ToPlaybookRun()
+		run01.EndAt = run01.CreateAt + 60*minute
+		run01.ReminderTimerDefaultSeconds = 15 * 60
+		run01.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+		run01.Checklists[0].Items[0].StateModified = run01.CreateAt + 5*minute
+
+		// run02 was finished after 20 minutes, with a status update 5 minutes in.
+		run02 := NewBuilder(t).
+			WithName("run 2").
+			WithTeamID(team1id).
+			WithPlaybookID(playbook1id).
+			WithCreateAt(now - 60*minute).
+			WithChecklists([]int{2}).
+			ToPlaybookRun()
+		run02.EndAt = run02.CreateAt + 20*minute
+		run02.ReminderTimerDefaultSeconds = 15 * 60
+		run02.Checklists[0].Items[0].State = app.ChecklistItemStateClosed
+		run02.Checklists[0].Items[0].StateModified = run02.CreateAt + 15*minute
+
+		// run03 is still in progress, in another team and playbook, without a status update timer.
+		run03 := NewBuilder(t).
+			WithName("run 3").
+			WithTeamID(team2id).
+			WithPlaybookID(playbook2id).
+			WithCreateAt(now - 30*minute).
+			ToPlaybookRun()
+
+		for _, run := range []*app.PlaybookRun{run01, run02, run03} {
+			createPlaybookRunChannel(t, store, run)
+
+			created, err := playbookRunStore.CreatePlaybookRun(run)
+			require.NoError(t, err)
+			run.ID = created.ID
+		}
+
+		for _, update := range []struct {
+			run *app.PlaybookRun
+			at  int64
+		}{
+			{run01, run01.CreateAt + 10*minute},
+			{run01, run01.CreateAt + 40*minute},
+			{run02, run02.CreateAt + 5*minute},
+			{run03, run03.CreateAt + 1*minute},
+		} {
+			_, err := playbookRunStore.CreateTimelineEvent(&app.TimelineEvent{
+				PlaybookRunID: update.run.ID,
+				CreateAt:      update.at,
+				EventAt:       update.at,
+				EventType:     app.StatusUpdated,
+			})
+			require.NoError(t, err)
+		}
+
+		t.Run(driverName+" time to finish", func(t *testing.T) {
+			stats, err := statsStore.TimeToFinish(&StatsFilters{TeamID: team1id})
+			require.NoError(t, err)
+			assert.Equal(t, DurationStats{Count: 2, Mean: 40 * minute, P50: 20 * minute, P90: 60 * minute, P95: 60 * minute, Max: 60 * minute}, stats)
+
+			stats, err = statsStore.TimeToFinish(&StatsFilters{PlaybookID: playbook2id})
+			require.NoError(t, err)
+			assert.Equal(t, DurationStats{}, stats)
+		})
+
+		t.Run(driverName+" time to first status update", func(t *testing.T) {
+			stats, err := statsStore.TimeToFirstStatusUpdate(&StatsFilters{PlaybookID: playbook1id})
+			require.NoError(t, err)
+			assert.Equal(t, 2, stats.Count)
+			assert.Equal(t, 5*minute, stats.P50)
+			assert.Equal(t, 10*minute, stats.Max)
+		})
+
+		t.Run(driverName+" time to first status update, by date range", func(t *testing.T) {
+			stats, err := statsStore.TimeToFirstStatusUpdate(&StatsFilters{StartedGTE: run02.CreateAt})
+			require.NoError(t, err)
+			assert.Equal(t, 2, stats.Count)
+			assert.Equal(t, 1*minute, stats.P50)
+			assert.Equal(t, 5*minute, stats.Max)
+
+			stats, err = statsStore.TimeToFirstStatusUpdate(&StatsFilters{StartedLT: run02.CreateAt})
+			require.NoError(t, err)
+			assert.Equal(t, 1, stats.Count)
+			assert.Equal(t, 10*minute, stats.Max)
+		})
+
+		t.Run(driverName+" status update cadence", func(t *testing.T) {
+			stats, err := statsStore.StatusUpdateCadence(&StatsFilters{})
+			require.NoError(t, err)
+
+			// run01: 10 and 30 minutes between updates, then 20 minutes until it finished.
+			// run02: 5 minutes to the first update, then 15 minutes until it finished.
+			assert.Equal(t, 2, stats.RunsCount)
+			assert.Equal(t, 4, stats.ExpectedUpdates)
+			assert.Equal(t, 2, stats.OnTimeUpdates)
+			assert.Equal(t, 0.5, stats.Adherence)
+			assert.Equal(t, 3, stats.Intervals.Count)
+		})
+
+		t.Run(driverName+" checklist item completion times", func(t *testing.T) {
+			stats, err := statsStore.ChecklistItemCompletionTimes(&StatsFilters{PlaybookID: playbook1id})
+			require.NoError(t, err)
+			require.Len(t, stats, 2)
+
+			assert.Equal(t, "Checklist 0", stats[0].ChecklistTitle)
+			assert.Equal(t, "Checklist 0 - item 0", stats[0].ItemTitle)
+			assert.Equal(t, 2, stats[0].RunsCount)
+			assert.Equal(t, DurationStats{Count: 2, Mean: 10 * minute, P50: 5 * minute, P90: 15 * minute, P95: 15 * minute, Max: 15 * minute}, stats[0].CompletionTime)
+
+			assert.Equal(t, "Checklist 0 - item 1", stats[1].ItemTitle)
+			assert.Equal(t, 2, stats[1].RunsCount)
+			assert.Equal(t, DurationStats{}, stats[1].CompletionTime)
+		})
+	}
+}
+
+func TestNewDurationStats(t *testing.T) {
+	assert.Equal(t, DurationStats{}, newDurationStats(nil))
+	assert.Equal(t, DurationStats{Count: 1, Mean: 7, P50: 7, P90: 7, P95: 7, Max: 7}, newDurationStats([]int64{7}))
+
+	durations := []int64{}
+	for i := int64(100); i > 0; i-- {
+		durations = append(durations, i)
+	}
+	assert.Equal(t, DurationStats{Count: 100, Mean: 50, P50: 50, P90: 90, P95: 95, Max: 100}, newDurationStats(durations))
+	assert.Equal(t, int64(100), durations[0], "input must not be sorted in place")
+}
+
+func TestComputeStatusUpdateCadence(t *testing.T) {
+	minute := int64(60000)
+
+	t.Run("no runs", func(t *testing.T) {
+		stats := computeStatusUpdateCadence(nil, 0)
+		assert.Equal(t, StatusUpdateCadenceStats{Adherence: 1}, stats)
+	})
+
+	t.Run("in-progress run not yet overdue", func(t *testing.T) {
+		stats := computeStatusUpdateCadence([]runStatusUpdates{{
+			CreateAt:                    0,
+			ReminderTimerDefaultSeconds: 10 * 60,
+			UpdatesAt:                   []int64{5 * minute},
+		}}, 12*minute)
+		assert.Equal(t, 1, stats.ExpectedUpdates)
+		assert.Equal(t, 1, stats.OnTimeUpdates)
+		assert.Equal(t, 1.0, stats.Adherence)
+	})
+
+	t.Run("in-progress run overdue", func(t *testing.T) {
+		stats := computeStatusUpdateCadence([]runStatusUpdates{{
+			CreateAt:                    0,
+			ReminderTimerDefaultSeconds: 10 * 60,
+			UpdatesAt:                   []int64{5 * minute, 20 * minute},
+		}}, 31*minute)
+		assert.Equal(t, 3, stats.ExpectedUpdates)
+		assert.Equal(t, 1, stats.OnTimeUpdates)
+		assert.Equal(t, DurationStats{Count: 2, Mean: 10 * minute, P50: 5 * minute, P90: 15 * minute, P95: 15 * minute, Max: 15 * minute}, stats.Intervals)
+	})
+
+	t.Run("finished run ignores the time since it ended", func(t *testing.T) {
+		stats := computeStatusUpdateCadence([]runStatusUpdates{{
+			CreateAt:                    0,
+			EndAt:                       8 * minute,
+			ReminderTimerDefaultSeconds: 10 * 60,
+		}}, 100*minute)
+		assert.Equal(t, 0, stats.ExpectedUpdates)
+		assert.Equal(t, 1.0, stats.Adherence)
+	})
+}
+
+func TestComputeChecklistItemStats(t *testing.T) {
+	runs := []runChecklists{
+		{
+			CreateAt: 1000,
+			Checklists: []app.Checklist{{
+				Title: "Triage",
+				Items: []app.ChecklistItem{
+					{Title: "Page on-call", State: app.ChecklistItemStateClosed, StateModified: 1100},
+					{Title: "Open bridge", State: app.ChecklistItemStateInProgress, StateModified: 1200},
+				},
+			}},
+		},
+		{
+			CreateAt: 2000,
+			Checklists: []app.Checklist{{
+				Title: "Triage",
+				Items: []app.ChecklistItem{
+					{Title: "Page on-call", State: app.ChecklistItemStateClosed, StateModified: 2300},
+				},
+			}},
+		},
+	}
+
+	stats := computeChecklistItemStats(runs)
+	require.Len(t, stats, 2)
+	assert.Equal(t, ChecklistItemStats{
+		ChecklistTitle: "Triage",
+		ItemTitle:      "Page on-call",
+		RunsCount:      2,
+		CompletionTime: DurationStats{Count: 2, Mean: 200, P50: 100, P90: 300, P95: 300, Max: 300},
+	}, stats[0])
+	assert.Equal(t, ChecklistItemStats{
+		ChecklistTitle: "Triage",
+		ItemTitle:      "Open bridge",
+		RunsCount:      1,
+	}, stats[1])
+}
+
+func TestRunsCountsByTeamAndPlaybook(t *testing.T) {
+	teamID := model.NewId()
+	playbookID := model.NewId()
+
+	for _, driverName := range driverNames {
+		db := setupTestDB(t, driverName)
+		playbookRunStore := setupPlaybookRunStore(t, db)
+		statsStore := setupStatsStore(t, db)
+
+		_, store := setupSQLStore(t, db)
+		setupChannelsTable(t, db)
+		setupChannelMembersTable(t, db)
+
+		runs := []*app.PlaybookRun{
+			NewBuilder(t).WithTeamID(teamID).WithPlaybookID(playbookID).WithUpdateOverdueBy(10 * time.Minute).ToPlaybookRun(),
+			NewBuilder(t).WithTeamID(teamID).WithPlaybookID(playbookID).WithUpdateOverdueBy(-10 * time.Minute).ToPlaybookRun(),
+			NewBuilder(t).WithTeamID(teamID).WithPlaybookID(playbookID).WithCurrentStatus(app.StatusFinished).ToPlaybookRun(),
+		}
+		for _, run := range runs {
+			createPlaybookRunChannel(t, store, run)
+
+			_, err := playbookRunStore.CreatePlaybookRun(run)
+			require.NoError(t, err)
+		}
+
+		t.Run(driverName+" runs in progress", func(t *testing.T) {
+			counts, err := statsStore.InProgressRunsByTeamAndPlaybook()
+			require.NoError(t, err)
+			require.Equal(t, []RunsCount{{TeamID: teamID, PlaybookID: playbookID, Count: 2}}, counts)
+		})
+
+		t.Run(driverName+" overdue status updates", func(t *testing.T) {
+			counts, err := statsStore.OverdueStatusUpdatesByTeamAndPlaybook()
+			require.NoError(t, err)
+			require.Equal(t, []RunsCount{{TeamID: teamID, PlaybookID: playbookID, Count: 1}}, counts)
+		})
+	}
+}
+
+func TestComputeRunStatusStats(t *testing.T) {
+	statuses := []app.RunStatus{{Name: "Investigating"}, {Name: "Mitigated"}, {Name: "Resolved", Finished: true}}
+	runs := []runStatusChanges{
+		{
+			CreateAt:    1000,
+			EndAt:       1600,
+			RunStatuses: statuses,
+			Changes: []runStatusChange{
+				{EventAt: 1100, To: "Mitigated"},
+				{EventAt: 1200, To: "Investigating"},
+				{EventAt: 1400, To: "Mitigated"},
+				{EventAt: 1600, To: "Resolved"},
+			},
+		},
+		{
+			// Still in progress: the time spent in Mitigated so far is not counted.
+			CreateAt:    2000,
+			RunStatuses: statuses,
+			Changes:     []runStatusChange{{EventAt: 2500, To: "Mitigated"}},
+		},
+		{
+			// Started before the playbook had run statuses.
+			CreateAt: 3000,
+			EndAt:    4000,
+		},
+	}
+
+	assert.Equal(t, []RunStatusStats{
+		{
+			Status:    "Investigating",
+			RunsCount: 2,
+			Duration:  DurationStats{Count: 2, Mean: 400, P50: 300, P90: 500, P95: 500, Max: 500},
+		},
+		{
+			Status:    "Mitigated",
+			RunsCount: 1,
+			Duration:  DurationStats{Count: 1, Mean: 300, P50: 300, P90: 300, P95: 300, Max: 300},
+		},
+	}, computeRunStatusStats(runs))
+}
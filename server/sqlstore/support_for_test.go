@@ -67,6 +67,7 @@ func setupSQLStore(t *testing.T, db *sqlx.DB) (bot.Logger, *SQLStore) {
 	setupPostsTable(t, db)
 	setupBotsTable(t, db)
 	setupChannelMembersTable(t, db)
+	setupGroupsTables(t, db)
 	setupKVStoreTable(t, db)
 
 	if currentSchemaVersion.LT(LatestVersion()) {
@@ -279,6 +280,70 @@ func setupChannelMembersTable(t *testing.T, db *sqlx.DB) {
 	require.NoError(t, err)
 }
 
+func setupGroupsTables(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	// Statements copied from mattermost-server/scripts/mattermost-postgresql-6.0.0.sql
+	if db.DriverName() == model.DatabaseDriverPostgres {
+		_, err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS public.usergroups (
+				id character varying(26) NOT NULL,
+				name character varying(64),
+				displayname character varying(128),
+				description character varying(1024),
+				source character varying(64),
+				remoteid character varying(48),
+				createat bigint,
+				updateat bigint,
+				deleteat bigint,
+				allowreference boolean,
+				PRIMARY KEY (id)
+			);
+
+			CREATE TABLE IF NOT EXISTS public.groupmembers (
+				groupid character varying(26) NOT NULL,
+				userid character varying(26) NOT NULL,
+				createat bigint,
+				deleteat bigint NOT NULL,
+				PRIMARY KEY (groupid, userid)
+			);
+		`)
+		require.NoError(t, err)
+
+		return
+	}
+
+	// Statements copied from mattermost-server/scripts/mattermost-mysql-6.0.0.sql
+	_, err := db.Exec(`
+			CREATE TABLE IF NOT EXISTS UserGroups (
+			  Id varchar(26) NOT NULL,
+			  Name varchar(64) DEFAULT NULL,
+			  DisplayName varchar(128) DEFAULT NULL,
+			  Description text,
+			  Source varchar(64) DEFAULT NULL,
+			  RemoteId varchar(48) DEFAULT NULL,
+			  CreateAt bigint(20) DEFAULT NULL,
+			  UpdateAt bigint(20) DEFAULT NULL,
+			  DeleteAt bigint(20) DEFAULT NULL,
+			  AllowReference tinyint(1) DEFAULT NULL,
+			  PRIMARY KEY (Id)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+		`)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`
+			CREATE TABLE IF NOT EXISTS GroupMembers (
+			  GroupId varchar(26) NOT NULL,
+			  UserId varchar(26) NOT NULL,
+			  CreateAt bigint(20) DEFAULT NULL,
+			  DeleteAt bigint(20) NOT NULL,
+			  PRIMARY KEY (GroupId,UserId),
+			  KEY idx_groupmembers_create_at (CreateAt)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+		`)
+	require.NoError(t, err)
+}
+
 func setupChannelsTable(t *testing.T, db *sqlx.DB) {
 	t.Helper()
 
@@ -588,6 +653,32 @@ func addUsersToChannels(t *testing.T, store *SQLStore, users []userInfo, channel
 	require.NoError(t, err)
 }
 
+// addUsersToGroup creates the group if needed, and adds the users to it.
+func addUsersToGroup(t *testing.T, store *SQLStore, users []userInfo, groupID string) {
+	t.Helper()
+
+	insertGroup := store.builder.
+		Insert("UserGroups").
+		Columns("Id", "Name", "DisplayName", "Source", "CreateAt", "UpdateAt", "DeleteAt", "AllowReference").
+		Values(groupID, groupID, groupID, model.GroupSourceLdap, 0, 0, 0, false)
+	if store.db.DriverName() == model.DatabaseDriverMysql {
+		insertGroup = insertGroup.Options("IGNORE")
+	} else {
+		insertGroup = insertGroup.Suffix("ON CONFLICT DO NOTHING")
+	}
+
+	_, err := store.execBuilder(store.db, insertGroup)
+	require.NoError(t, err)
+
+	insertBuilder := store.builder.Insert("GroupMembers").Columns("GroupId", "UserId", "CreateAt", "DeleteAt")
+	for _, u := range users {
+		insertBuilder = insertBuilder.Values(groupID, u.ID, 0, 0)
+	}
+
+	_, err = store.execBuilder(store.db, insertBuilder)
+	require.NoError(t, err)
+}
+
 func createChannels(t testing.TB, store *SQLStore, channels []model.Channel) {
 	t.Helper()
 
@@ -79,6 +79,14 @@ func (t *NoopTelemetry) UpdatePlaybook(app.Playbook, string) {
 func (t *NoopTelemetry) DeletePlaybook(app.Playbook, string) {
 }
 
+// RestorePlaybook does nothing.
+func (t *NoopTelemetry) RestorePlaybook(app.Playbook, string) {
+}
+
+// PurgePlaybook does nothing.
+func (t *NoopTelemetry) PurgePlaybook(app.Playbook, string) {
+}
+
 // ChangeOwner does nothing
 func (t *NoopTelemetry) ChangeOwner(*app.PlaybookRun, string) {
 }
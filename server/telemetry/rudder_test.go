@@ -22,11 +22,13 @@ var (
 	dummyUserID        = "dummy_user_id"
 )
 
-func TestNewRudder(t *testing.T) {
-	r, err := NewRudder("dummy_key", "dummy_url", diagnosticID, pluginVersion, serverVersion)
-	require.Equal(t, r.diagnosticID, diagnosticID)
-	require.Equal(t, r.serverVersion, serverVersion)
+func TestNewRudderSink(t *testing.T) {
+	r, err := NewRudderSink("dummy_key", "dummy_url", diagnosticID)
 	require.NoError(t, err)
+	require.Equal(t, r.diagnosticID, diagnosticID)
+
+	_, err = NewRudderSink("dummy_key", "dummy_url", "")
+	require.Error(t, err)
 }
 
 type rudderPayload struct {
@@ -47,7 +49,7 @@ type rudderPayload struct {
 	}
 }
 
-func setupRudder(t *testing.T, data chan<- rudderPayload) (*RudderTelemetry, *httptest.Server) {
+func setupRudder(t *testing.T, data chan<- rudderPayload) (*Telemetry, *RudderSink, *httptest.Server) {
 	t.Helper()
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -69,15 +71,18 @@ func setupRudder(t *testing.T, data chan<- rudderPayload) (*RudderTelemetry, *ht
 	})
 	require.NoError(t, err)
 
-	return &RudderTelemetry{
-		client:        client,
-		diagnosticID:  diagnosticID,
-		pluginVersion: pluginVersion,
-		serverVersion: serverVersion,
-		writeKey:      writeKey,
-		dataPlaneURL:  server.URL,
-		enabled:       true,
-	}, server
+	sink := &RudderSink{
+		client:       client,
+		diagnosticID: diagnosticID,
+		writeKey:     writeKey,
+		dataPlaneURL: server.URL,
+		enabled:      true,
+	}
+
+	telemetry := New(pluginVersion, serverVersion)
+	require.NoError(t, telemetry.SetSink("rudder", sink))
+
+	return telemetry, sink, server
 }
 
 var dummyPlaybookRun = &app.PlaybookRun{
@@ -203,7 +208,7 @@ func assertPayload(t *testing.T, actual rudderPayload, expectedEvent string, exp
 
 func TestRudderTelemetry(t *testing.T) {
 	data := make(chan rudderPayload)
-	rudderClient, rudderServer := setupRudder(t, data)
+	rudderClient, _, rudderServer := setupRudder(t, data)
 	defer rudderServer.Close()
 
 	for name, tc := range map[string]struct {
@@ -249,10 +254,10 @@ func TestRudderTelemetry(t *testing.T) {
 func TestDisableTelemetry(t *testing.T) {
 	t.Run("disable client", func(t *testing.T) {
 		data := make(chan rudderPayload)
-		rudderClient, rudderServer := setupRudder(t, data)
+		rudderClient, rudderSink, rudderServer := setupRudder(t, data)
 		defer rudderServer.Close()
 
-		err := rudderClient.Disable()
+		err := rudderSink.Disable()
 		require.NoError(t, err)
 
 		rudderClient.CreatePlaybookRun(dummyPlaybookRun, dummyUserID, true)
@@ -267,13 +272,13 @@ func TestDisableTelemetry(t *testing.T) {
 
 	t.Run("disable client is idempotent", func(t *testing.T) {
 		data := make(chan rudderPayload)
-		rudderClient, rudderServer := setupRudder(t, data)
+		rudderClient, rudderSink, rudderServer := setupRudder(t, data)
 		defer rudderServer.Close()
 
-		err := rudderClient.Disable()
+		err := rudderSink.Disable()
 		require.NoError(t, err)
 
-		err = rudderClient.Disable()
+		err = rudderSink.Disable()
 		require.NoError(t, err)
 
 		rudderClient.CreatePlaybookRun(dummyPlaybookRun, dummyUserID, true)
@@ -288,14 +293,14 @@ func TestDisableTelemetry(t *testing.T) {
 
 	t.Run("re-disable client", func(t *testing.T) {
 		data := make(chan rudderPayload)
-		rudderClient, rudderServer := setupRudder(t, data)
+		rudderClient, rudderSink, rudderServer := setupRudder(t, data)
 		defer rudderServer.Close()
 
 		// Make sure it's enabled before disabling
-		err := rudderClient.Enable()
+		err := rudderSink.Enable()
 		require.NoError(t, err)
 
-		err = rudderClient.Disable()
+		err = rudderSink.Disable()
 		require.NoError(t, err)
 
 		rudderClient.CreatePlaybookRun(dummyPlaybookRun, dummyUserID, true)
@@ -318,14 +323,14 @@ func TestDisableTelemetry(t *testing.T) {
 		}
 
 		data := make(chan rudderPayload)
-		rudderClient, rudderServer := setupRudder(t, data)
+		rudderClient, rudderSink, rudderServer := setupRudder(t, data)
 		defer rudderServer.Close()
 
 		// Make sure it's disabled before enabling
-		err := rudderClient.Disable()
+		err := rudderSink.Disable()
 		require.NoError(t, err)
 
-		err = rudderClient.Enable()
+		err = rudderSink.Enable()
 		require.NoError(t, err)
 
 		rudderClient.CreatePlaybookRun(dummyPlaybookRun, dummyUserID, true)
@@ -0,0 +1,141 @@
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// httpSinkBufferSize is the number of events the HTTPSink holds while the collector is
+// slow or unreachable. Further events are dropped.
+const httpSinkBufferSize = 1000
+
+// NoopSink is a Sink discarding every event.
+type NoopSink struct{}
+
+// Track does nothing.
+func (s *NoopSink) Track(Event) {}
+
+// Close does nothing, returning always nil.
+func (s *NoopSink) Close() error {
+	return nil
+}
+
+// FileSink is a Sink appending every event as a JSON line to a file.
+type FileSink struct {
+	mutex   sync.Mutex
+	file    io.WriteCloser
+	encoder *json.Encoder
+}
+
+// NewFileSink opens the file at path, creating it if needed, and returns a FileSink
+// appending the events to it.
+func NewFileSink(path string) (*FileSink, error) {
+	if path == "" {
+		return nil, errors.New("path should not be empty")
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open telemetry file %s", path)
+	}
+
+	return &FileSink{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Track appends the event to the file. Write errors are ignored, as they are for the
+// other sinks.
+func (s *FileSink) Track(event Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_ = s.encoder.Encode(event)
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.file.Close()
+}
+
+// HTTPSink is a Sink posting every event as JSON to a collector. The events are posted in
+// the background, one at a time, so a slow collector never blocks the tracking code.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+	events chan Event
+	done   chan struct{}
+}
+
+// NewHTTPSink returns an HTTPSink posting the events to url with client, and starts
+// posting them.
+func NewHTTPSink(url string, client *http.Client) (*HTTPSink, error) {
+	if url == "" {
+		return nil, errors.New("url should not be empty")
+	}
+
+	s := &HTTPSink{
+		url:    url,
+		client: client,
+		events: make(chan Event, httpSinkBufferSize),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+
+	return s, nil
+}
+
+// Track queues the event, dropping it if the queue is full.
+func (s *HTTPSink) Track(event Event) {
+	select {
+	case s.events <- event:
+	default:
+	}
+}
+
+// Close waits for the queued events to be posted.
+func (s *HTTPSink) Close() error {
+	close(s.events)
+	<-s.done
+
+	return nil
+}
+
+func (s *HTTPSink) run() {
+	defer close(s.done)
+
+	for event := range s.events {
+		_ = s.post(event)
+	}
+}
+
+func (s *HTTPSink) post(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal event")
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to post event")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("collector responded with status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
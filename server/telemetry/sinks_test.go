@@ -0,0 +1,199 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+
+	"github.com/stretchr/testify/require"
+)
+
+var dummySinkPlaybook = app.Playbook{
+	ID:     "playbook_id",
+	Title:  "title",
+	TeamID: "team_id",
+}
+
+type recordingSink struct {
+	mutex  sync.Mutex
+	events []Event
+	closed bool
+}
+
+func (s *recordingSink) Track(event Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+}
+
+func (s *recordingSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.closed = true
+	return nil
+}
+
+// callbackSink calls onClose when closed.
+type callbackSink struct {
+	recordingSink
+	onClose func()
+}
+
+func (s *callbackSink) Close() error {
+	s.onClose()
+	return s.recordingSink.Close()
+}
+
+func TestTelemetrySinks(t *testing.T) {
+	t.Run("fans out to every sink", func(t *testing.T) {
+		telemetry := New(pluginVersion, serverVersion)
+		first, second := &recordingSink{}, &recordingSink{}
+		require.NoError(t, telemetry.SetSink("first", first))
+		require.NoError(t, telemetry.SetSink("second", second))
+		require.Equal(t, []string{"first", "second"}, telemetry.SinkNames())
+
+		telemetry.CreatePlaybookRun(dummyPlaybookRun, dummyUserID, true)
+
+		for _, sink := range []*recordingSink{first, second} {
+			require.Len(t, sink.events, 1)
+			event := sink.events[0]
+			require.Equal(t, eventPlaybookRun, event.Event)
+			require.Equal(t, actionCreate, event.Action)
+			require.NotZero(t, event.Timestamp)
+			require.Equal(t, dummyUserID, event.UserID)
+			require.Equal(t, dummyPlaybookRun.TeamID, event.TeamID)
+			require.Equal(t, dummyPlaybookRun.PlaybookID, event.PlaybookID)
+			require.Equal(t, dummyPlaybookRun.ID, event.PlaybookRunID)
+			require.Equal(t, dummyPlaybookRun.Name, event.Title)
+			require.Equal(t, pluginVersion, event.PluginVersion)
+			require.Equal(t, serverVersion, event.ServerVersion)
+			require.Equal(t, true, event.Properties["Public"])
+			require.NotContains(t, event.Properties, "Action")
+		}
+	})
+
+	t.Run("replacing and removing a sink closes it", func(t *testing.T) {
+		telemetry := New(pluginVersion, serverVersion)
+		first, second := &recordingSink{}, &recordingSink{}
+		require.NoError(t, telemetry.SetSink("sink", first))
+		require.NoError(t, telemetry.SetSink("sink", second))
+		require.True(t, first.closed)
+		require.False(t, second.closed)
+
+		telemetry.StartTrial(dummyUserID, "action")
+		require.Empty(t, first.events)
+		require.Len(t, second.events, 1)
+
+		require.NoError(t, telemetry.SetSink("sink", nil))
+		require.True(t, second.closed)
+		require.Empty(t, telemetry.SinkNames())
+	})
+
+	t.Run("close closes every sink", func(t *testing.T) {
+		telemetry := New(pluginVersion, serverVersion)
+		sink := &recordingSink{}
+		require.NoError(t, telemetry.SetSink("sink", sink))
+
+		require.NoError(t, telemetry.Close())
+		require.True(t, sink.closed)
+		require.Empty(t, telemetry.SinkNames())
+	})
+	t.Run("sinks are closed without holding the lock", func(t *testing.T) {
+		telemetry := New(pluginVersion, serverVersion)
+		tracked := &recordingSink{}
+		require.NoError(t, telemetry.SetSink("tracked", tracked))
+
+		// Tracking while a sink is being closed would deadlock if the lock were still held.
+		closing := &callbackSink{onClose: func() { telemetry.StartTrial(dummyUserID, "action") }}
+		require.NoError(t, telemetry.SetSink("closing", closing))
+		require.NoError(t, telemetry.SetSink("closing", nil))
+		require.Len(t, tracked.events, 1)
+
+		require.NoError(t, telemetry.SetSink("closing", closing))
+		require.NoError(t, telemetry.Close())
+		require.True(t, closing.closed)
+	})
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	telemetry := New(pluginVersion, serverVersion)
+	require.NoError(t, telemetry.SetSink("file", sink))
+	telemetry.AddTask(dummyPlaybookRunID, dummyUserID, dummyTask)
+	telemetry.FinishPlaybookRun(dummyPlaybookRun, dummyUserID)
+	require.NoError(t, telemetry.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+
+	require.Len(t, events, 2)
+	require.Equal(t, eventTasks, events[0].Event)
+	require.Equal(t, actionAddTask, events[0].Action)
+	require.Equal(t, dummyPlaybookRunID, events[0].PlaybookRunID)
+	require.Equal(t, dummyTask.Title, events[0].Title)
+	require.Equal(t, eventPlaybookRun, events[1].Event)
+	require.Equal(t, actionEnd, events[1].Action)
+
+	_, err = NewFileSink("")
+	require.Error(t, err)
+}
+
+func TestHTTPSink(t *testing.T) {
+	received := make(chan Event, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var event Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	sink, err := NewHTTPSink(server.URL, server.Client())
+	require.NoError(t, err)
+
+	telemetry := New(pluginVersion, serverVersion)
+	require.NoError(t, telemetry.SetSink("collector", sink))
+	telemetry.NotifyAdmins(dummyUserID, "action")
+	telemetry.UpdatePlaybook(dummySinkPlaybook, dummyUserID)
+
+	// Close waits for the queued events to be posted.
+	require.NoError(t, telemetry.Close())
+	require.Len(t, received, 2)
+
+	event := <-received
+	require.Equal(t, eventNotifyAdmins, event.Event)
+	require.Equal(t, "action", event.Action)
+	require.Equal(t, dummyUserID, event.UserID)
+
+	event = <-received
+	require.Equal(t, eventPlaybook, event.Event)
+	require.Equal(t, actionUpdate, event.Action)
+	require.Equal(t, dummySinkPlaybook.ID, event.PlaybookID)
+	require.Equal(t, dummySinkPlaybook.Title, event.Title)
+
+	_, err = NewHTTPSink("", server.Client())
+	require.Error(t, err)
+}
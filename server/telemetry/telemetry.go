@@ -0,0 +1,510 @@
+package telemetry
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mattermost/mattermost-plugin-playbooks/server/app"
+
+	"github.com/pkg/errors"
+)
+
+// Unique strings that identify each of the tracked events
+const (
+	eventPlaybookRun               = "incident"
+	actionCreate                   = "create"
+	actionEnd                      = "end"
+	actionRestart                  = "restart"
+	actionChangeOwner              = "change_commander"
+	actionUpdateStatus             = "update_status"
+	actionAddTimelineEventFromPost = "add_timeline_event_from_post"
+	actionUpdateRetrospective      = "update_retrospective"
+	actionPublishRetrospective     = "publish_retrospective"
+	actionRemoveTimelineEvent      = "remove_timeline_event"
+
+	eventTasks                = "tasks"
+	actionAddTask             = "add_task"
+	actionRemoveTask          = "remove_task"
+	actionRenameTask          = "rename_task"
+	actionModifyTaskState     = "modify_task_state"
+	actionMoveTask            = "move_task"
+	actionSetAssigneeForTask  = "set_assignee_for_task"
+	actionRunTaskSlashCommand = "run_task_slash_command"
+
+	eventPlaybook = "playbook"
+	actionUpdate  = "update"
+	actionDelete  = "delete"
+	actionRestore = "restore"
+	actionPurge   = "purge"
+
+	eventFrontend = "frontend"
+
+	eventNotifyAdmins = "notify_admins"
+
+	eventStartTrial = "start_trial"
+
+	// telemetryKeyPlaybookRunID records the legacy name used to identify a playbook run via telemetry.
+	telemetryKeyPlaybookRunID = "IncidentID"
+
+	eventSettings = "settings"
+	actionDigest  = "digest"
+)
+
+// Event is a tracked event, as handed to every sink.
+type Event struct {
+	// Event is the category of the event, e.g. "incident", "tasks" or "playbook".
+	Event string `json:"event"`
+
+	// Action is what happened within the category, e.g. "create" or "update_status".
+	Action string `json:"action"`
+
+	// Timestamp is the time the event was tracked, in milliseconds.
+	Timestamp int64 `json:"timestamp"`
+
+	// UserID is the user that triggered the event.
+	UserID string `json:"user_id,omitempty"`
+
+	// TeamID is the team of the playbook or playbook run involved, if any.
+	TeamID string `json:"team_id,omitempty"`
+
+	// PlaybookID is the playbook involved, if any.
+	PlaybookID string `json:"playbook_id,omitempty"`
+
+	// PlaybookRunID is the playbook run involved, if any.
+	PlaybookRunID string `json:"playbook_run_id,omitempty"`
+
+	// Title is the title of the playbook, playbook run or checklist item involved, if any.
+	// It is never sent to Rudder.
+	Title string `json:"title,omitempty"`
+
+	PluginVersion string `json:"plugin_version"`
+	ServerVersion string `json:"server_version"`
+
+	// Properties are the details specific to the event. The map is shared by all the sinks,
+	// so they must not modify it.
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Sink is a backend receiving the tracked events.
+type Sink interface {
+	// Track records the event. It is called synchronously from the tracking code, so
+	// sinks doing I/O should not block for long.
+	Track(event Event)
+
+	// Close flushes any pending event and releases the resources held by the sink. Track
+	// is never called after Close.
+	Close() error
+}
+
+// Telemetry implements the telemetry interfaces of the plugin, fanning out every tracked
+// event to the registered sinks.
+type Telemetry struct {
+	pluginVersion string
+	serverVersion string
+
+	mutex sync.RWMutex
+	sinks map[string]Sink
+}
+
+// New builds a Telemetry with no sinks. The versions of the plugin and the server are
+// added to every event tracked.
+func New(pluginVersion, serverVersion string) *Telemetry {
+	return &Telemetry{
+		pluginVersion: pluginVersion,
+		serverVersion: serverVersion,
+		sinks:         make(map[string]Sink),
+	}
+}
+
+// SetSink registers sink under name, closing the sink previously registered under the same
+// name, if any. A nil sink just removes the previous one. The previous sink is closed after the
+// lock is released, so a slow flush doesn't block the events tracked meanwhile.
+func (t *Telemetry) SetSink(name string, sink Sink) error {
+	t.mutex.Lock()
+	previous, ok := t.sinks[name]
+	if sink == nil {
+		delete(t.sinks, name)
+	} else {
+		t.sinks[name] = sink
+	}
+	t.mutex.Unlock()
+
+	if !ok || previous == sink {
+		return nil
+	}
+
+	if err := previous.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close the telemetry sink %s", name)
+	}
+
+	return nil
+}
+
+// SinkNames returns the names of the registered sinks, sorted.
+func (t *Telemetry) SinkNames() []string {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	names := make([]string, 0, len(t.sinks))
+	for name := range t.sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Close closes and removes all the registered sinks. They are closed after the lock is released.
+func (t *Telemetry) Close() error {
+	t.mutex.Lock()
+	sinks := t.sinks
+	t.sinks = make(map[string]Sink)
+	t.mutex.Unlock()
+
+	var result error
+	for name, sink := range sinks {
+		if err := sink.Close(); err != nil && result == nil {
+			result = errors.Wrapf(err, "failed to close the telemetry sink %s", name)
+		}
+	}
+
+	return result
+}
+
+func (t *Telemetry) track(event Event) {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if len(t.sinks) == 0 {
+		return
+	}
+
+	event.Timestamp = time.Now().UnixNano() / int64(time.Millisecond)
+	event.PluginVersion = t.pluginVersion
+	event.ServerVersion = t.serverVersion
+
+	for _, sink := range t.sinks {
+		sink.Track(event)
+	}
+}
+
+func (t *Telemetry) trackPlaybookRun(event, action string, playbookRun *app.PlaybookRun, userID string, properties map[string]interface{}) {
+	t.track(Event{
+		Event:         event,
+		Action:        action,
+		UserID:        userID,
+		TeamID:        playbookRun.TeamID,
+		PlaybookID:    playbookRun.PlaybookID,
+		PlaybookRunID: playbookRun.ID,
+		Title:         playbookRun.Name,
+		Properties:    properties,
+	})
+}
+
+func (t *Telemetry) trackTask(action, playbookRunID, userID string, task app.ChecklistItem, properties map[string]interface{}) {
+	t.track(Event{
+		Event:         eventTasks,
+		Action:        action,
+		UserID:        userID,
+		PlaybookRunID: playbookRunID,
+		Title:         task.Title,
+		Properties:    properties,
+	})
+}
+
+func (t *Telemetry) trackPlaybook(event, action string, playbook app.Playbook, userID string, properties map[string]interface{}) {
+	t.track(Event{
+		Event:      event,
+		Action:     action,
+		UserID:     userID,
+		TeamID:     playbook.TeamID,
+		PlaybookID: playbook.ID,
+		Title:      playbook.Title,
+		Properties: properties,
+	})
+}
+
+func (t *Telemetry) trackUser(event, action, userID string, properties map[string]interface{}) {
+	t.track(Event{
+		Event:      event,
+		Action:     action,
+		UserID:     userID,
+		Properties: properties,
+	})
+}
+
+func playbookRunProperties(playbookRun *app.PlaybookRun, userID string) map[string]interface{} {
+	totalChecklistItems := 0
+	for _, checklist := range playbookRun.Checklists {
+		totalChecklistItems += len(checklist.Items)
+	}
+
+	return map[string]interface{}{
+		"UserActualID":            userID,
+		telemetryKeyPlaybookRunID: playbookRun.ID,
+		"HasDescription":          playbookRun.Description != "",
+		"CommanderUserID":         playbookRun.OwnerUserID,
+		"ReporterUserID":          playbookRun.ReporterUserID,
+		"TeamID":                  playbookRun.TeamID,
+		"ChannelID":               playbookRun.ChannelID,
+		"CreateAt":                playbookRun.CreateAt,
+		"EndAt":                   playbookRun.EndAt,
+		"DeleteAt":                playbookRun.DeleteAt, //nolint
+		"PostID":                  playbookRun.PostID,
+		"PlaybookID":              playbookRun.PlaybookID,
+		"NumChecklists":           len(playbookRun.Checklists),
+		"TotalChecklistItems":     totalChecklistItems,
+		"NumStatusPosts":          len(playbookRun.StatusPosts),
+		"CurrentStatus":           playbookRun.CurrentStatus,
+		"PreviousReminder":        playbookRun.PreviousReminder,
+		"NumTimelineEvents":       len(playbookRun.TimelineEvents),
+	}
+}
+
+// CreatePlaybookRun tracks the creation of the playbook run passed.
+func (t *Telemetry) CreatePlaybookRun(playbookRun *app.PlaybookRun, userID string, public bool) {
+	properties := playbookRunProperties(playbookRun, userID)
+	properties["Public"] = public
+	t.trackPlaybookRun(eventPlaybookRun, actionCreate, playbookRun, userID, properties)
+}
+
+// FinishPlaybookRun tracks the end of the playbook run passed.
+func (t *Telemetry) FinishPlaybookRun(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventPlaybookRun, actionEnd, playbookRun, userID, properties)
+}
+
+// RestartPlaybookRun tracks the restart of the playbook run.
+func (t *Telemetry) RestartPlaybookRun(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventPlaybookRun, actionRestart, playbookRun, userID, properties)
+}
+
+// ChangeOwner tracks changes in owner
+func (t *Telemetry) ChangeOwner(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventPlaybookRun, actionChangeOwner, playbookRun, userID, properties)
+}
+
+func (t *Telemetry) UpdateStatus(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	properties["ReminderTimerSeconds"] = int(playbookRun.PreviousReminder)
+	t.trackPlaybookRun(eventPlaybookRun, actionUpdateStatus, playbookRun, userID, properties)
+}
+
+func (t *Telemetry) FrontendTelemetryForPlaybookRun(playbookRun *app.PlaybookRun, userID, action string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventFrontend, action, playbookRun, userID, properties)
+}
+
+// AddPostToTimeline tracks userID creating a timeline event from a post.
+func (t *Telemetry) AddPostToTimeline(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventPlaybookRun, actionAddTimelineEventFromPost, playbookRun, userID, properties)
+}
+
+// RemoveTimelineEvent tracks userID removing a timeline event.
+func (t *Telemetry) RemoveTimelineEvent(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventPlaybookRun, actionRemoveTimelineEvent, playbookRun, userID, properties)
+}
+
+func taskProperties(playbookRunID, userID string, task app.ChecklistItem) map[string]interface{} {
+	return map[string]interface{}{
+		telemetryKeyPlaybookRunID: playbookRunID,
+		"UserActualID":            userID,
+		"TaskID":                  task.ID,
+		"State":                   task.State,
+		"AssigneeID":              task.AssigneeID,
+		"HasCommand":              task.Command != "",
+		"CommandLastRun":          task.CommandLastRun,
+		"HasDescription":          task.Description != "",
+	}
+}
+
+// AddTask tracks the creation of a new checklist item by the user
+// identified by userID in the given playbook run.
+func (t *Telemetry) AddTask(playbookRunID, userID string, task app.ChecklistItem) {
+	properties := taskProperties(playbookRunID, userID, task)
+	t.trackTask(actionAddTask, playbookRunID, userID, task, properties)
+}
+
+// RemoveTask tracks the removal of a checklist item by the user
+// identified by userID in the given playbook run.
+func (t *Telemetry) RemoveTask(playbookRunID, userID string, task app.ChecklistItem) {
+	properties := taskProperties(playbookRunID, userID, task)
+	t.trackTask(actionRemoveTask, playbookRunID, userID, task, properties)
+}
+
+// RenameTask tracks the update of a checklist item by the user
+// identified by userID in the given playbook run.
+func (t *Telemetry) RenameTask(playbookRunID, userID string, task app.ChecklistItem) {
+	properties := taskProperties(playbookRunID, userID, task)
+	t.trackTask(actionRenameTask, playbookRunID, userID, task, properties)
+}
+
+// ModifyCheckedState tracks the checking and unchecking of items by the user
+// identified by userID in the given playbook run.
+func (t *Telemetry) ModifyCheckedState(playbookRunID, userID string, task app.ChecklistItem, wasOwner bool) {
+	properties := taskProperties(playbookRunID, userID, task)
+	properties["NewState"] = task.State
+	properties["WasCommander"] = wasOwner
+	properties["WasAssignee"] = task.AssigneeID == userID
+	t.trackTask(actionModifyTaskState, playbookRunID, userID, task, properties)
+}
+
+// SetAssignee tracks the changing of an assignee on an item by the user
+// identified by userID in the given playbook run.
+func (t *Telemetry) SetAssignee(playbookRunID, userID string, task app.ChecklistItem) {
+	properties := taskProperties(playbookRunID, userID, task)
+	t.trackTask(actionSetAssigneeForTask, playbookRunID, userID, task, properties)
+}
+
+// MoveTask tracks the movement of checklist items by the user
+// identified by userID in the given playbook run.
+func (t *Telemetry) MoveTask(playbookRunID, userID string, task app.ChecklistItem) {
+	properties := taskProperties(playbookRunID, userID, task)
+	t.trackTask(actionMoveTask, playbookRunID, userID, task, properties)
+}
+
+// RunTaskSlashCommand tracks the execution of a slash command on a checklist item.
+func (t *Telemetry) RunTaskSlashCommand(playbookRunID, userID string, task app.ChecklistItem) {
+	properties := taskProperties(playbookRunID, userID, task)
+	t.trackTask(actionRunTaskSlashCommand, playbookRunID, userID, task, properties)
+}
+
+func (t *Telemetry) UpdateRetrospective(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventTasks, actionUpdateRetrospective, playbookRun, userID, properties)
+}
+
+func (t *Telemetry) PublishRetrospective(playbookRun *app.PlaybookRun, userID string) {
+	properties := playbookRunProperties(playbookRun, userID)
+	t.trackPlaybookRun(eventTasks, actionPublishRetrospective, playbookRun, userID, properties)
+}
+
+func playbookProperties(playbook app.Playbook, userID string) map[string]interface{} {
+	totalChecklistItems := 0
+	totalChecklistItemsWithCommands := 0
+	for _, checklist := range playbook.Checklists {
+		totalChecklistItems += len(checklist.Items)
+		for _, item := range checklist.Items {
+			if item.Command != "" {
+				totalChecklistItemsWithCommands++
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"UserActualID":                userID,
+		"PlaybookID":                  playbook.ID,
+		"HasDescription":              playbook.Description != "",
+		"TeamID":                      playbook.TeamID,
+		"IsPublic":                    playbook.CreatePublicPlaybookRun,
+		"CreateAt":                    playbook.CreateAt,
+		"DeleteAt":                    playbook.DeleteAt,
+		"NumChecklists":               len(playbook.Checklists),
+		"TotalChecklistItems":         totalChecklistItems,
+		"NumSlashCommands":            totalChecklistItemsWithCommands,
+		"NumMembers":                  len(playbook.MemberIDs),
+		"UsesReminderMessageTemplate": playbook.ReminderMessageTemplate != "",
+		"ReminderTimerDefaultSeconds": playbook.ReminderTimerDefaultSeconds,
+		"NumInvitedUserIDs":           len(playbook.InvitedUserIDs),
+		"NumInvitedGroupIDs":          len(playbook.InvitedGroupIDs),
+		"InviteUsersEnabled":          playbook.InviteUsersEnabled,
+		"DefaultCommanderID":          playbook.DefaultOwnerID,
+		"DefaultCommanderEnabled":     playbook.DefaultOwnerEnabled,
+		"BroadcastChannelIDs":         playbook.BroadcastChannelIDs,
+		"BroadcastEnabled":            playbook.BroadcastEnabled,
+		"NumWebhookOnCreationURLs":    len(playbook.WebhookOnCreationURLs),
+		"WebhookOnCreationEnabled":    playbook.WebhookOnCreationEnabled,
+		"SignalAnyKeywordsEnabled":    playbook.SignalAnyKeywordsEnabled,
+		"NumSignalAnyKeywords":        len(playbook.SignalAnyKeywords),
+	}
+}
+
+func playbookTemplateProperties(templateName string, userID string) map[string]interface{} {
+	return map[string]interface{}{
+		"UserActualID": userID,
+		"TemplateName": templateName,
+	}
+}
+
+// CreatePlaybook tracks the creation of a playbook.
+func (t *Telemetry) CreatePlaybook(playbook app.Playbook, userID string) {
+	properties := playbookProperties(playbook, userID)
+	t.trackPlaybook(eventPlaybook, actionCreate, playbook, userID, properties)
+}
+
+// UpdatePlaybook tracks the update of a playbook.
+func (t *Telemetry) UpdatePlaybook(playbook app.Playbook, userID string) {
+	properties := playbookProperties(playbook, userID)
+	t.trackPlaybook(eventPlaybook, actionUpdate, playbook, userID, properties)
+}
+
+// DeletePlaybook tracks the deletion of a playbook.
+func (t *Telemetry) DeletePlaybook(playbook app.Playbook, userID string) {
+	properties := playbookProperties(playbook, userID)
+	t.trackPlaybook(eventPlaybook, actionDelete, playbook, userID, properties)
+}
+
+// RestorePlaybook tracks the restoration of an archived playbook.
+func (t *Telemetry) RestorePlaybook(playbook app.Playbook, userID string) {
+	properties := playbookProperties(playbook, userID)
+	t.trackPlaybook(eventPlaybook, actionRestore, playbook, userID, properties)
+}
+
+// PurgePlaybook tracks the permanent deletion of an archived playbook.
+func (t *Telemetry) PurgePlaybook(playbook app.Playbook, userID string) {
+	properties := playbookProperties(playbook, userID)
+	t.trackPlaybook(eventPlaybook, actionPurge, playbook, userID, properties)
+}
+
+// FrontendTelemetryForPlaybook tracks an event originating from the frontend
+func (t *Telemetry) FrontendTelemetryForPlaybook(playbook app.Playbook, userID, action string) {
+	properties := playbookProperties(playbook, userID)
+	t.trackPlaybook(eventFrontend, action, playbook, userID, properties)
+}
+
+// FrontendTelemetryForPlaybookTemplate tracks a playbook template event originating from the frontend
+func (t *Telemetry) FrontendTelemetryForPlaybookTemplate(templateName string, userID, action string) {
+	properties := playbookTemplateProperties(templateName, userID)
+	t.track(Event{
+		Event:      eventFrontend,
+		Action:     action,
+		UserID:     userID,
+		Title:      templateName,
+		Properties: properties,
+	})
+}
+
+func commonProperties(userID string) map[string]interface{} {
+	return map[string]interface{}{
+		"UserActualID": userID,
+	}
+}
+
+func (t *Telemetry) StartTrial(userID string, action string) {
+	properties := commonProperties(userID)
+	t.trackUser(eventStartTrial, action, userID, properties)
+}
+
+func (t *Telemetry) NotifyAdmins(userID string, action string) {
+	properties := commonProperties(userID)
+	t.trackUser(eventNotifyAdmins, action, userID, properties)
+}
+
+func digestSettingsProperties(userID string) map[string]interface{} {
+	return map[string]interface{}{
+		"UserActualID": userID,
+	}
+}
+
+// ChangeDigestSettings tracks when a user changes one of the digest settings
+func (t *Telemetry) ChangeDigestSettings(userID string, old app.DigestNotificationSettings, new app.DigestNotificationSettings) {
+	properties := digestSettingsProperties(userID)
+	properties["OldDisableDailyDigest"] = old.DisableDailyDigest
+	properties["NewDisableDailyDigest"] = new.DisableDailyDigest
+	t.trackUser(eventSettings, actionDigest, userID, properties)
+}